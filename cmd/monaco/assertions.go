@@ -0,0 +1,51 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// evaluateAssertions evaluates the assertions of all projects after they were deployed to the environment of
+// client. All assertions are evaluated, even if some of them fail
+func evaluateAssertions(client rest.DynatraceClient, projects []project.Project, apis map[string]api.Api) error {
+
+	failed := 0
+
+	for _, project := range projects {
+		for _, assertion := range project.GetAssertions() {
+
+			err := assertion.Evaluate(client, apis)
+			if err != nil {
+				util.Log.Error("\tAssertion %s of project %s failed: %s", assertion.Name, project.GetId(), err)
+				failed++
+			} else {
+				util.Log.Info("\tAssertion %s of project %s passed", assertion.Name, project.GetId())
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d assertions failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/selector"
+)
+
+// autoTagApi is the id of the api auto-tags are deployed to
+const autoTagApi = "auto-tag"
+
+type autoTagPayload struct {
+	Rules []struct {
+		Type       string `json:"type"`
+		Conditions []struct {
+			Key struct {
+				Attribute string `json:"attribute"`
+			} `json:"key"`
+			ComparisonInfo struct {
+				Operator string      `json:"operator"`
+				Value    interface{} `json:"value"`
+			} `json:"comparisonInfo"`
+		} `json:"conditions"`
+	} `json:"rules"`
+	EntitySelectorBasedRules []struct {
+		EntitySelector string `json:"entitySelector"`
+	} `json:"entitySelectorBasedRules"`
+}
+
+// validateAutoTag checks the rules of an auto-tag. The api accepts malformed entity selectors and conditions
+// without a value, which silently tag no entities at all
+func validateAutoTag(jsonString string, fileName string) error {
+
+	var payload autoTagPayload
+	err := json.Unmarshal([]byte(jsonString), &payload)
+	if err != nil {
+		return fmt.Errorf("%s is not a valid auto-tag: %s", fileName, err)
+	}
+
+	for i, rule := range payload.Rules {
+
+		if rule.Type == "" {
+			return fmt.Errorf("rule %d of auto-tag %s has no type", i+1, fileName)
+		}
+
+		for j, condition := range rule.Conditions {
+
+			if condition.Key.Attribute == "" {
+				return fmt.Errorf("condition %d of rule %d of auto-tag %s has no attribute", j+1, i+1, fileName)
+			}
+
+			operator := condition.ComparisonInfo.Operator
+			if operator == "" {
+				return fmt.Errorf("condition %d of rule %d of auto-tag %s has no operator", j+1, i+1, fileName)
+			}
+
+			if operator != "EXISTS" && condition.ComparisonInfo.Value == nil {
+				return fmt.Errorf("condition %d of rule %d of auto-tag %s compares %s with operator %s, but has no value",
+					j+1, i+1, fileName, condition.Key.Attribute, operator)
+			}
+		}
+	}
+
+	for i, rule := range payload.EntitySelectorBasedRules {
+		err := selector.Validate(rule.EntitySelector)
+		if err != nil {
+			return fmt.Errorf("entity selector based rule %d of auto-tag %s is invalid: %s", i+1, fileName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateAutoTag(t *testing.T) {
+
+	valid := `{
+		"name": "team",
+		"rules": [{"type": "SERVICE", "conditions": [
+			{"key": {"attribute": "SERVICE_NAME"}, "comparisonInfo": {"operator": "CONTAINS", "value": "payment"}},
+			{"key": {"attribute": "SERVICE_TAGS"}, "comparisonInfo": {"operator": "EXISTS"}}
+		]}],
+		"entitySelectorBasedRules": [{"entitySelector": "type(HOST),tag(payment)"}]
+	}`
+
+	assert.NilError(t, validateAutoTag(valid, "tag.json"))
+}
+
+func TestValidateAutoTagFailsOnInvalidRules(t *testing.T) {
+
+	tests := []struct {
+		payload string
+		err     string
+	}{
+		{`{"rules": [{"conditions": []}]}`, "rule 1 of auto-tag tag.json has no type"},
+		{`{"rules": [{"type": "HOST", "conditions": [{"comparisonInfo": {"operator": "EQUALS", "value": "a"}}]}]}`, "has no attribute"},
+		{`{"rules": [{"type": "HOST", "conditions": [{"key": {"attribute": "HOST_NAME"}, "comparisonInfo": {}}]}]}`, "has no operator"},
+		{`{"rules": [{"type": "HOST", "conditions": [{"key": {"attribute": "HOST_NAME"}, "comparisonInfo": {"operator": "EQUALS"}}]}]}`, "compares HOST_NAME with operator EQUALS, but has no value"},
+		{`{"entitySelectorBasedRules": [{"entitySelector": "type(HOST"}]}`, "entity selector based rule 1 of auto-tag tag.json is invalid"},
+	}
+
+	for _, test := range tests {
+		assert.ErrorContains(t, validateAutoTag(test.payload, "tag.json"), test.err)
+	}
+}
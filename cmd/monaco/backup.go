@@ -0,0 +1,78 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// defaultBackupFolder is the folder configs are backed up to before they are deleted
+const defaultBackupFolder = ".backups"
+
+// backupConfigs downloads the configs of the plan into a timestamped snapshot archive within the backup folder,
+// which can be deployed again with the restore command. Configs of apis which can not be downloaded are not
+// backed up. Returns the path of the archive
+func backupConfigs(plan deletionPlan, backupFolder string) (archive string, err error) {
+
+	workingDir, err := ioutil.TempDir("", "monaco-backup")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(workingDir)
+
+	apis := make(map[string]api.Api)
+	values := make(map[string][]api.Value)
+	var apiIds []string
+
+	for _, target := range plan.targets {
+		id := target.Api.GetId()
+		if _, found := apis[id]; !found {
+			apis[id] = target.Api
+			apiIds = append(apiIds, id)
+		}
+		values[id] = append(values[id], api.Value{Id: target.Id, Name: target.Name})
+	}
+
+	for _, id := range apiIds {
+		if !download.IsSupported(id) {
+			util.Log.Warn("\tNo backup of %d configs of %s created, download is not supported for this api", len(values[id]), id)
+			continue
+		}
+
+		_, err = download.DownloadValues(apis[id], values[id], plan.client, filepath.Join(workingDir, snapshotProject))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	err = os.MkdirAll(backupFolder, 0777)
+	if err != nil {
+		return "", err
+	}
+
+	// backups are named after the real time, a frozen clock would overwrite the backups of earlier runs
+	archive = filepath.Join(backupFolder, "backup-"+plan.environment.GetId()+"-"+time.Now().Format("20060102-150405")+".zip")
+
+	return archive, util.ZipFolder(workingDir, archive)
+}
@@ -0,0 +1,88 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+// readingClient serves the given payloads by id
+type readingClient struct {
+	rest.DynatraceClient
+	payloads map[string]string
+}
+
+func (c readingClient) ReadById(a api.Api, id string) ([]byte, error) {
+	return []byte(c.payloads[id]), nil
+}
+
+func TestBackupConfigsWritesRestorableSnapshot(t *testing.T) {
+
+	plan := deletionPlan{
+		environment: testDuplicatesEnvironment,
+		client:      readingClient{payloads: map[string]string{"1": `{"id": "1", "name": "overview", "tiles": []}`}},
+		targets: []delete.Target{
+			{Api: testDashboardApi, Id: "1", Name: "overview"},
+			{Api: api.NewApi("extension", "/api/config/v1/extensions"), Id: "custom.python.ext", Name: "custom.python.ext"},
+		},
+	}
+
+	backupFolder := filepath.Join(t.TempDir(), "backups")
+
+	archive, err := backupConfigs(plan, backupFolder)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasPrefix(filepath.Base(archive), "backup-dev-"))
+
+	restored := t.TempDir()
+	err = util.UnzipToFolder(archive, restored)
+	assert.NilError(t, err)
+
+	template, err := ioutil.ReadFile(filepath.Join(restored, snapshotProject, "dashboard", "overview.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"name\": \"{{ .name }}\",\n  \"tiles\": []\n}\n")
+
+	_, err = ioutil.ReadFile(filepath.Join(restored, snapshotProject, "dashboard", "dashboard.yaml"))
+	assert.NilError(t, err)
+}
+
+func TestBackupConfigsIsNamedAfterTheRealTimeIfTheClockIsFrozen(t *testing.T) {
+
+	util.FreezeTime(time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC))
+	defer util.FreezeTime(time.Time{})
+
+	plan := deletionPlan{
+		environment: testDuplicatesEnvironment,
+		client:      readingClient{payloads: map[string]string{"1": `{"id": "1", "name": "overview", "tiles": []}`}},
+		targets:     []delete.Target{{Api: testDashboardApi, Id: "1", Name: "overview"}},
+	}
+
+	archive, err := backupConfigs(plan, filepath.Join(t.TempDir(), "backups"))
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(filepath.Base(archive), "20010203"), archive)
+}
@@ -0,0 +1,256 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+)
+
+const (
+	benchProject  = "bench"
+	benchTokenEnv = "MONACO_BENCH_TOKEN"
+)
+
+// defaultBenchApis are deployed by the benchmark if no apis are given
+const defaultBenchApis = "alerting-profile,management-zone,request-attributes"
+
+// unsupportedBenchApis are validated or uploaded differently, so their synthetic configs cannot be deployed
+var unsupportedBenchApis = map[string]bool{
+	autoTagApi:           true,
+	notificationApi:      true,
+	extensionApi:         true,
+	settingsApi:          true,
+	"synthetic-location": true,
+	"synthetic-monitor":  true,
+}
+
+// benchResult holds the throughput of a single round of the benchmark
+type benchResult struct {
+	round    string
+	configs  int
+	requests int
+	duration time.Duration
+}
+
+// runBench executes the bench command, which deploys a synthetic project against a fake server twice, once creating
+// and once updating all objects, and reports the throughput of both rounds. Returns 0 on success and -1 on errors
+func runBench(args []string, fileReader util.FileReader) int {
+
+	var apiList string
+	var configsPerApi int
+	var latency time.Duration
+	var verbose bool
+
+	flagSet := flag.NewFlagSet("bench", flag.ExitOnError)
+
+	apisUsage := "Comma separated list of apis the synthetic project contains configs of."
+	flagSet.StringVar(&apiList, "apis", defaultBenchApis, apisUsage)
+
+	configsUsage := "Number of configs per api in the synthetic project."
+	flagSet.IntVar(&configsPerApi, "configs", 100, configsUsage)
+
+	latencyUsage := "Latency the fake server adds to every response, e.g. 20ms, to simulate the round trip to Dynatrace."
+	flagSet.DurationVar(&latency, "latency", 0, latencyUsage)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+" (shorthand)")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	if configsPerApi < 1 {
+		util.Log.Error("Invalid --configs: at least one config per api is required")
+		return -1
+	}
+
+	apis, err := selectBenchApis(splitList(apiList))
+	if err != nil {
+		util.Log.Error("Invalid --apis: %s", err)
+		return -1
+	}
+
+	// project paths are resolved relative to the working dir, so the synthetic project is written there
+	folder, err := ioutil.TempDir(".", "monaco-bench-")
+	if err != nil {
+		util.Log.Error("Creating bench folder failed: %s", err)
+		return -1
+	}
+	defer os.RemoveAll(folder)
+
+	err = writeBenchProject(folder, apis, configsPerApi)
+	if err != nil {
+		util.Log.Error("Writing synthetic project failed: %s", err)
+		return -1
+	}
+
+	results, err := bench(folder, apis, latency, fileReader)
+	if err != nil {
+		util.Log.Error("Benchmark failed: %s", err)
+		return -1
+	}
+
+	util.Log.Info("Benchmark of monaco v%s, %d configs of %d apis, %s latency:", version.MonitoringAsCode, configsPerApi*len(apis), len(apis), latency)
+	for _, result := range results {
+		util.Log.Info("\t%s", result)
+	}
+
+	return 0
+}
+
+func (r benchResult) String() string {
+
+	seconds := r.duration.Seconds()
+	if seconds == 0 {
+		seconds = time.Nanosecond.Seconds()
+	}
+
+	return fmt.Sprintf("%-7s %d configs in %s (%.1f configs/s, %d requests, %.1f requests/s)",
+		r.round+":", r.configs, r.duration.Round(time.Millisecond), float64(r.configs)/seconds, r.requests, float64(r.requests)/seconds)
+}
+
+// selectBenchApis returns the apis with the given ids, failing for unknown and unsupported apis
+func selectBenchApis(ids []string) (map[string]api.Api, error) {
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no apis given")
+	}
+
+	available := createApis()
+	apis := make(map[string]api.Api, len(ids))
+
+	for _, id := range ids {
+		a, found := available[id]
+		if !found {
+			return nil, fmt.Errorf("unknown api %s", id)
+		}
+		if unsupportedBenchApis[id] {
+			return nil, fmt.Errorf("api %s is not supported by the benchmark", id)
+		}
+		apis[id] = a
+	}
+
+	return apis, nil
+}
+
+// writeBenchProject writes a project into folder which holds the given number of configs for each api. The configs
+// of an api share a single template only containing the name
+func writeBenchProject(folder string, apis map[string]api.Api, configsPerApi int) error {
+
+	for id := range apis {
+
+		apiFolder := filepath.Join(folder, benchProject, id)
+		err := os.MkdirAll(apiFolder, 0777)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(filepath.Join(apiFolder, "bench.json"), []byte("{\n  \"name\": \"{{ .name }}\"\n}\n"), 0664)
+		if err != nil {
+			return err
+		}
+
+		var configs, properties strings.Builder
+		configs.WriteString("config:\n")
+
+		for i := 1; i <= configsPerApi; i++ {
+			configs.WriteString(fmt.Sprintf("  - bench-%d: \"bench.json\"\n", i))
+			properties.WriteString(fmt.Sprintf("\nbench-%d:\n  - name: \"monaco-bench-%s-%d\"\n", i, id, i))
+		}
+
+		err = ioutil.WriteFile(filepath.Join(apiFolder, "bench.yaml"), []byte(configs.String()+properties.String()), 0664)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bench deploys the project in folder against a fake server of the given apis. The first round creates all
+// objects, the second round updates them
+func bench(folder string, apis map[string]api.Api, latency time.Duration, fileReader util.FileReader) ([]benchResult, error) {
+
+	projects, err := project.LoadProjectsToDeploy(benchProject, apis, folder, fileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs int
+	for _, p := range projects {
+		configs += len(p.GetConfigs())
+	}
+
+	server := fake.NewServer(apis, latency)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	if err != nil {
+		return nil, err
+	}
+
+	env := environment.NewEnvironment("bench", "bench", "", server.URL, benchTokenEnv)
+	options := executionOptions{
+		path:          folder,
+		policies:      policy.NoPolicies(),
+		clientOptions: rest.DefaultClientOptions(),
+	}
+
+	var results []benchResult
+	for _, round := range []string{"create", "update"} {
+
+		requests := server.Requests()
+		started := time.Now()
+
+		err = execute(env, projects, options)
+		if err != nil {
+			return nil, fmt.Errorf("%s round failed: %s", round, err)
+		}
+
+		results = append(results, benchResult{
+			round:    round,
+			configs:  configs,
+			requests: server.Requests() - requests,
+			duration: time.Since(started),
+		})
+	}
+
+	return results, nil
+}
@@ -0,0 +1,63 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestBenchCreatesAndUpdatesAllConfigs(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"alerting-profile", "management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-bench-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	err = writeBenchProject(folder, apis, 3)
+	assert.NilError(t, err)
+
+	results, err := bench(folder, apis, 0, util.NewFileReader())
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(results), 2)
+	assert.Equal(t, results[0].round, "create")
+	assert.Equal(t, results[0].configs, 6)
+	assert.Equal(t, results[1].round, "update")
+	assert.Equal(t, results[1].configs, 6)
+	assert.Assert(t, results[1].requests > 0)
+}
+
+func TestSelectBenchApisFailsOnUnknownAndUnsupportedApis(t *testing.T) {
+
+	_, err := selectBenchApis([]string{"unknown"})
+	assert.ErrorContains(t, err, "unknown api unknown")
+
+	_, err = selectBenchApis([]string{"settings"})
+	assert.ErrorContains(t, err, "not supported")
+
+	_, err = selectBenchApis(nil)
+	assert.ErrorContains(t, err, "no apis given")
+}
@@ -0,0 +1,147 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// capabilities probes which apis and settings schemas an environment offers. Older Managed versions lack some of
+// them, so their configs are skipped with a warning instead of failing the whole deployment with 404.
+// A nil capabilities supports everything
+type capabilities struct {
+	client    rest.DynatraceClient
+	supported map[string]bool
+}
+
+func newCapabilities(client rest.DynatraceClient) *capabilities {
+	return &capabilities{
+		client:    client,
+		supported: make(map[string]bool),
+	}
+}
+
+// supports probes whether the environment offers the api of the config, or for settings configs its schema.
+// Each api and schema is only probed once, a warning is logged the first time an unsupported one is found
+func (c *capabilities) supports(config config.Config, environment environment.Environment, dict map[string]api.DynatraceEntity) (bool, error) {
+
+	if c == nil {
+		return true, nil
+	}
+
+	feature, probe, err := c.probeFor(config, environment, dict)
+	if err != nil {
+		return false, err
+	}
+
+	_, probed := c.supported[feature]
+	supported, err := c.probe(feature, probe)
+	if err != nil {
+		return false, err
+	}
+
+	if !supported && !probed {
+		util.Log.Warn("\t\t\t%s is not available on environment %s, skipping its configs", feature, environment.GetId())
+	}
+
+	return supported, nil
+}
+
+// offersSchema probes whether the environment offers the settings schema, e.g. the successor of a classic api.
+// Each schema is only probed once
+func (c *capabilities) offersSchema(schemaId string) (bool, error) {
+
+	if c == nil {
+		return true, nil
+	}
+
+	settingsClient, ok := c.client.(rest.SettingsClient)
+	if !ok {
+		return false, nil
+	}
+
+	return c.probe("settings schema "+schemaId, func() error {
+		_, err := settingsClient.GetSchema(schemaId)
+		return err
+	})
+}
+
+// probe sends the probe of the feature, unless it was probed before. Features are not supported if the probe
+// fails with 404
+func (c *capabilities) probe(feature string, probe func() error) (bool, error) {
+
+	supported, found := c.supported[feature]
+	if found {
+		return supported, nil
+	}
+
+	err := probe()
+	if err != nil && !rest.IsNotFound(err) {
+		return false, err
+	}
+
+	supported = err == nil
+	c.supported[feature] = supported
+	return supported, nil
+}
+
+// probeFor returns the name of the api or schema the config is deployed to and a request which fails with 404
+// if the environment does not offer it
+func (c *capabilities) probeFor(config config.Config, environment environment.Environment, dict map[string]api.DynatraceEntity) (string, func() error, error) {
+
+	a := config.GetApi()
+
+	if settingsClient, ok := c.client.(rest.SettingsClient); ok && a.GetId() == settingsApi {
+		properties, err := readSettingsProperties(config, environment, dict)
+		if err != nil {
+			return "", nil, err
+		}
+
+		return "settings schema " + properties.schemaId, func() error {
+			_, err := settingsClient.GetSchema(properties.schemaId)
+			return err
+		}, nil
+	}
+
+	return "api " + a.GetId(), func() error {
+		_, err := c.client.List(a)
+		return err
+	}, nil
+}
+
+// probeVersion returns the environment with the version of its cluster, which selects the template variants of
+// configs. The version declared in the environments file is kept, if the cluster version cannot be read
+func probeVersion(client rest.DynatraceClient, env environment.Environment) environment.Environment {
+
+	versionClient, ok := client.(rest.ClusterVersionClient)
+	if !ok {
+		return env
+	}
+
+	version, err := versionClient.GetClusterVersion()
+	if err != nil {
+		util.Log.Debug("\tcould not read the cluster version of environment %s: %s", env.GetId(), err)
+		return env
+	}
+
+	util.Log.Debug("\tenvironment %s runs on version %s", env.GetId(), version)
+	return environment.WithVersion(env, version)
+}
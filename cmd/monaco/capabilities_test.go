@@ -0,0 +1,134 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+// testCapabilitiesClient answers requests for the given apis and schemas with 404 and counts the probes
+type testCapabilitiesClient struct {
+	rest.DynatraceClient
+	missingApis    map[string]bool
+	missingSchemas map[string]bool
+	probes         int
+}
+
+func (c *testCapabilitiesClient) List(a api.Api) ([]api.Value, error) {
+	c.probes++
+	if c.missingApis[a.GetId()] {
+		return nil, rest.ResponseError{StatusCode: 404}
+	}
+	return nil, nil
+}
+
+func (c *testCapabilitiesClient) ListSettings(schemaId string) ([]rest.SettingsObject, error) {
+	return nil, nil
+}
+
+func (c *testCapabilitiesClient) GetSchema(schemaId string) (rest.SettingsSchema, error) {
+	c.probes++
+	if c.missingSchemas[schemaId] {
+		return rest.SettingsSchema{}, rest.ResponseError{StatusCode: 404}
+	}
+	return rest.SettingsSchema{SchemaId: schemaId}, nil
+}
+
+func (c *testCapabilitiesClient) UpsertSettings(object rest.SettingsObject) (string, error) {
+	return "", nil
+}
+
+func newTestApiConfig(mockCtrl *gomock.Controller, apiId string) config.Config {
+
+	apiConfig := config.NewMockConfig(mockCtrl)
+	apiConfig.EXPECT().GetApi().Return(api.NewApi(apiId, "/api/config/v1/"+apiId)).AnyTimes()
+
+	return apiConfig
+}
+
+func TestCapabilitiesSkipApisMissingOnEnvironment(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testCapabilitiesClient{missingApis: map[string]bool{"synthetic-monitor": true}}
+	available := newCapabilities(client)
+
+	for i := 0; i < 2; i++ {
+		supported, err := available.supports(newTestApiConfig(mockCtrl, "synthetic-monitor"), testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+		assert.NilError(t, err)
+		assert.Equal(t, false, supported)
+	}
+
+	supported, err := available.supports(newTestApiConfig(mockCtrl, "management-zone"), testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, true, supported)
+
+	assert.Equal(t, 2, client.probes)
+}
+
+func TestCapabilitiesProbeSettingsSchemas(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	settingsConfig := newTestSettingsConfig(mockCtrl, "")
+	settingsConfig.(*config.MockConfig).EXPECT().GetApi().Return(api.NewApi(settingsApi, "/api/v2/settings/objects")).AnyTimes()
+
+	client := &testCapabilitiesClient{missingSchemas: map[string]bool{"builtin:test": true}}
+
+	supported, err := newCapabilities(client).supports(settingsConfig, testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, false, supported)
+}
+
+func TestCapabilitiesReportOtherErrors(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &failingCapabilitiesClient{}
+
+	_, err := newCapabilities(client).supports(newTestApiConfig(mockCtrl, "alerting-profile"), testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+	assert.Assert(t, err != nil)
+}
+
+func TestNilCapabilitiesSupportEverything(t *testing.T) {
+
+	var available *capabilities
+
+	supported, err := available.supports(nil, testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, true, supported)
+}
+
+// failingCapabilitiesClient fails all requests with 500
+type failingCapabilitiesClient struct {
+	rest.DynatraceClient
+}
+
+func (c *failingCapabilitiesClient) List(a api.Api) ([]api.Value, error) {
+	return nil, rest.ResponseError{StatusCode: 500}
+}
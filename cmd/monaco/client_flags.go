@@ -0,0 +1,93 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// defaultNameCacheTtl is short, as the cache is meant to be shared by the commands of a single pipeline run
+const defaultNameCacheTtl = 15 * time.Minute
+
+// clientFlags holds the flags configuring the requests a command sends to the environments
+type clientFlags struct {
+	httpCacheFolder string
+	requestTimeout  time.Duration
+	nameCacheFile   string
+	nameCacheTtl    time.Duration
+}
+
+// addClientFlags adds the flags configuring the requests sent to the environments. Every command talking to
+// environments uses them, so they send their requests the same way deployments do
+func addClientFlags(flagSet *flag.FlagSet, flags *clientFlags) {
+
+	httpCacheUsage := "Folder responses of the environments are kept in across runs, they are only transferred again if their ETag changed."
+	flagSet.StringVar(&flags.httpCacheFolder, "http-cache", "", httpCacheUsage)
+
+	requestTimeoutUsage := "Maximum duration of a request to the config apis, e.g. 30s. Requests are not limited by default. Does not apply to extension uploads."
+	flagSet.DurationVar(&flags.requestTimeout, "request-timeout", 0, requestTimeoutUsage)
+
+	nameCacheUsage := "Json file in which the names and ids of listed configs are kept, so that consecutive runs do not list them again. Created if it does not exist."
+	flagSet.StringVar(&flags.nameCacheFile, "name-cache", "", nameCacheUsage)
+
+	nameCacheTtlUsage := "Time cached names and ids are used for, e.g. 15m."
+	flagSet.DurationVar(&flags.nameCacheTtl, "name-cache-ttl", defaultNameCacheTtl, nameCacheTtlUsage)
+}
+
+// apply returns the options with the http cache, request timeout and name cache of the flags
+func (f clientFlags) apply(options rest.ClientOptions, fileReader util.FileReader) (rest.ClientOptions, error) {
+
+	if f.httpCacheFolder != "" {
+		httpCache, err := rest.NewHttpCache(f.httpCacheFolder)
+		if err != nil {
+			return rest.ClientOptions{}, fmt.Errorf("setup of http cache failed: %w", err)
+		}
+		options.HttpCache = httpCache
+	}
+	options.RequestTimeout = f.requestTimeout
+
+	if f.nameCacheFile != "" {
+		nameCache, err := rest.LoadNameCache(f.nameCacheFile, f.nameCacheTtl, fileReader)
+		if err != nil {
+			return rest.ClientOptions{}, fmt.Errorf("loading of name cache failed: %w", err)
+		}
+		options.NameCache = nameCache
+	}
+
+	return options, nil
+}
+
+// saveNameCache writes the names and ids listed by the command to the name cache, if one is used. Returns false if
+// the cache could not be written
+func (f clientFlags) saveNameCache(options rest.ClientOptions) bool {
+
+	if options.NameCache == nil {
+		return true
+	}
+
+	err := options.NameCache.Save(f.nameCacheFile)
+	if err != nil {
+		util.Log.Error("Saving name cache to %s failed: %s", f.nameCacheFile, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestClientFlagsAreAppliedToTheOptions(t *testing.T) {
+
+	folder, err := ioutil.TempDir("", "monaco-client-flags")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	flags := clientFlags{
+		httpCacheFolder: filepath.Join(folder, "http-cache"),
+		requestTimeout:  30 * time.Second,
+		nameCacheFile:   filepath.Join(folder, "names.json"),
+		nameCacheTtl:    defaultNameCacheTtl,
+	}
+
+	options, err := flags.apply(rest.DefaultClientOptions(), util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, options.RequestTimeout, 30*time.Second)
+	assert.Assert(t, options.HttpCache != nil)
+	assert.Assert(t, options.NameCache != nil)
+	assert.Equal(t, options.ConflictRetries, rest.DefaultClientOptions().ConflictRetries)
+
+	assert.Assert(t, flags.saveNameCache(options))
+	_, err = os.Stat(flags.nameCacheFile)
+	assert.NilError(t, err)
+}
+
+func TestClientFlagsKeepTheDefaultsIfNotGiven(t *testing.T) {
+
+	options, err := clientFlags{}.apply(rest.DefaultClientOptions(), util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Assert(t, options.HttpCache == nil)
+	assert.Assert(t, options.NameCache == nil)
+	assert.Equal(t, options.RequestTimeout, time.Duration(0))
+
+	assert.Assert(t, clientFlags{}.saveNameCache(options))
+}
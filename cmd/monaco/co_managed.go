@@ -0,0 +1,119 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// coManagedWindow is how far back the audit log is searched for changes, which is its retention time
+const coManagedWindow = 30 * 24 * time.Hour
+
+// coManagedDetector finds objects changed by other tools or users, e.g. Terraform or the UI, since monaco changed them
+// last, as deploying their configs overwrites these changes. Changes are read from the audit log of the environment,
+// changes made with the token of the environment count as made by monaco. A nil detector finds nothing
+type coManagedDetector struct {
+	client rest.DynatraceClient
+	token  string
+
+	// changes holds the audit log entries by object id, oldest first
+	changes map[string][]rest.AuditLogEntry
+}
+
+// newCoManagedDetector reads the audit log of the environment. Returns a nil detector if the client can not read
+// audit logs
+func newCoManagedDetector(client rest.DynatraceClient, environment environment.Environment, now time.Time) (*coManagedDetector, error) {
+
+	auditLog, ok := client.(rest.AuditLogClient)
+	if !ok {
+		util.Log.Debug("\tClient of environment %s can not read the audit log, co-managed configs are not detected", environment.GetId())
+		return nil, nil
+	}
+
+	token, err := environment.GetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := auditLog.ListConfigAuditLogs(now.Add(-coManagedWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string][]rest.AuditLogEntry)
+	for _, entry := range entries {
+		changes[entry.ObjectId()] = append(changes[entry.ObjectId()], entry)
+	}
+
+	return &coManagedDetector{
+		client:  client,
+		token:   rest.PublicTokenIdentifier(token),
+		changes: changes,
+	}, nil
+}
+
+// check returns the changes of other sources made to the object of the config since monaco changed it last, and
+// warns about them. Detection is best effort, objects which can not be looked up are not reported
+func (d *coManagedDetector) check(config config.Config, environment environment.Environment, dict map[string]api.DynatraceEntity) []rest.AuditLogEntry {
+
+	if d == nil || config.GetApi().GetId() == settingsApi || config.GetApi().GetId() == extensionApi {
+		return nil
+	}
+
+	name, err := config.GetObjectNameForEnvironment(environment, dict)
+	if err != nil {
+		return nil
+	}
+
+	exists, id, err := d.client.ExistsByName(config.GetApi(), name)
+	if err != nil {
+		util.Log.Debug("\t\t\tCould not check whether %s is co-managed: %s", config.GetFilePath(), err)
+		return nil
+	}
+	if !exists {
+		return nil
+	}
+
+	foreign := d.foreignChanges(id)
+	if len(foreign) > 0 {
+		last := foreign[len(foreign)-1]
+		util.Log.Warn("\t\t\t%s is co-managed, %d changes by other sources will be overwritten, last by %s at %s",
+			config.GetFilePath(), len(foreign), last.Source(), last.Time().Format(time.RFC3339))
+	}
+
+	return foreign
+}
+
+// foreignChanges returns the changes of the object made after the last change made with monaco's token
+func (d *coManagedDetector) foreignChanges(id string) []rest.AuditLogEntry {
+
+	changes := d.changes[id]
+
+	for i := len(changes) - 1; i >= 0; i-- {
+		if changes[i].User == d.token {
+			return changes[i+1:]
+		}
+	}
+
+	return changes
+}
@@ -0,0 +1,97 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+// auditedClient adds a fixed audit log to a client
+type auditedClient struct {
+	rest.DynatraceClient
+	entries []rest.AuditLogEntry
+}
+
+func (c auditedClient) ListConfigAuditLogs(from time.Time) ([]rest.AuditLogEntry, error) {
+	return c.entries, nil
+}
+
+func TestCoManagedDetectorReportsChangesSinceMonacosLastChange(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+	zoneApi := apis["management-zone"]
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "dt0c01.MONACO.secret")
+	assert.NilError(t, err)
+	env := environment.NewEnvironment("dev", "dev", "", server.URL, benchTokenEnv)
+
+	client, err := createClient(env, rest.DefaultClientOptions())
+	assert.NilError(t, err)
+
+	overwritten, err := client.UpsertByName(zoneApi, "overwritten", []byte(`{"name": "overwritten"}`))
+	assert.NilError(t, err)
+	redeployed, err := client.UpsertByName(zoneApi, "redeployed", []byte(`{"name": "redeployed"}`))
+	assert.NilError(t, err)
+
+	entries := []rest.AuditLogEntry{
+		{EntityId: "MANAGEMENT_ZONE: " + overwritten.Id, User: "dt0c01.MONACO", UserType: "PUBLIC_TOKEN_IDENTIFIER", Timestamp: 1000},
+		{EntityId: "MANAGEMENT_ZONE: " + overwritten.Id, User: "jane", UserType: "USER_NAME", UserOrigin: "webui", Timestamp: 2000},
+		{EntityId: "MANAGEMENT_ZONE: " + overwritten.Id, User: "dt0c01.TERRAFORM", UserType: "PUBLIC_TOKEN_IDENTIFIER", Timestamp: 3000},
+		{EntityId: "MANAGEMENT_ZONE: " + redeployed.Id, User: "jane", UserType: "USER_NAME", Timestamp: 1000},
+		{EntityId: "MANAGEMENT_ZONE: " + redeployed.Id, User: "dt0c01.MONACO", UserType: "PUBLIC_TOKEN_IDENTIFIER", Timestamp: 2000},
+	}
+
+	detector, err := newCoManagedDetector(auditedClient{client, entries}, env, time.Now())
+	assert.NilError(t, err)
+
+	configFor := func(name string) config.Config {
+		return config.GetMockConfig("zone", "project", nil, map[string]map[string]string{"zone": {"name": name}}, zoneApi, "zone.json")
+	}
+	dict := map[string]api.DynatraceEntity{}
+
+	foreign := detector.check(configFor("overwritten"), env, dict)
+	assert.Equal(t, len(foreign), 2)
+	assert.Equal(t, foreign[1].Source(), "token dt0c01.TERRAFORM")
+
+	assert.Equal(t, len(detector.check(configFor("redeployed"), env, dict)), 0)
+	assert.Equal(t, len(detector.check(configFor("not deployed yet"), env, dict)), 0)
+}
+
+func TestCoManagedDetectorRequiresAuditLogClient(t *testing.T) {
+
+	env := environment.NewEnvironment("dev", "dev", "", "https://dev.example.com", benchTokenEnv)
+
+	detector, err := newCoManagedDetector(rest.NewDryRunClient(nil, rest.NameMatching{}), env, time.Now())
+	assert.NilError(t, err)
+	assert.Assert(t, detector == nil)
+	assert.Equal(t, len(detector.check(nil, env, nil)), 0)
+}
@@ -0,0 +1,214 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/compare"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// runCompare executes the compare command, which lists configs only present in one of two environments
+// and field level differences of configs with the same name.
+// Returns 0 if both environments are equal, 1 if they differ and -1 on errors
+func runCompare(args []string, fileReader util.FileReader) (statusCode int) {
+
+	var environmentsFile string
+	var environmentIds stringListFlag
+	var verbose bool
+	var fieldOverridesFile string
+	var outputFormat string
+	var clientFlags clientFlags
+
+	shorthand := " (shorthand)"
+
+	flagSet := flag.NewFlagSet("compare", flag.ExitOnError)
+
+	environmentsUsage := "Mandatory yaml file containing the environments to compare."
+	flagSet.StringVar(&environmentsFile, "environments", "", environmentsUsage)
+	flagSet.StringVar(&environmentsFile, "e", "", environmentsUsage+shorthand)
+
+	specificEnvironmentUsage := "Environment (from list) to compare. Has to be passed exactly twice."
+	flagSet.Var(&environmentIds, "specific-environment", specificEnvironmentUsage)
+	flagSet.Var(&environmentIds, "se", specificEnvironmentUsage+shorthand)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+shorthand)
+
+	flagSet.StringVar(&fieldOverridesFile, "field-overrides", "", fieldOverridesUsage)
+
+	addClientFlags(flagSet, &clientFlags)
+	addOutputFormatFlag(flagSet, &outputFormat)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if environmentsFile == "" || len(environmentIds) != 2 {
+		println("Please provide environments yaml with -e/--environments and two environments with -se/--specific-environment!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+
+	setupLoggingForOutput(verbose, outputFormat)
+
+	err = validateOutputFormat(outputFormat)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	fieldOverrides, err := api.LoadFieldOverrides(fieldOverridesFile, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of field overrides failed: %s", err)
+		return -1
+	}
+
+	environments, errorList := environment.LoadEnvironmentList("", environmentsFile, fileReader)
+	for _, err := range errorList {
+		util.Log.Error("Loading of environments failed: %s", err)
+		statusCode = -1
+	}
+	if statusCode != 0 {
+		return statusCode
+	}
+
+	clientOptions, err := clientFlags.apply(rest.DefaultClientOptions(), fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+	defer func() {
+		if !clientFlags.saveNameCache(clientOptions) {
+			statusCode = -1
+		}
+	}()
+
+	clients := make([]rest.DynatraceClient, 0, 2)
+	for _, id := range environmentIds {
+		client, err := createClientForEnvironment(environments, id, clientOptions)
+		if err != nil {
+			util.Log.Error("%s", err)
+			return -1
+		}
+		clients = append(clients, client)
+	}
+
+	util.Log.Info("Comparing environment %s with %s...", environmentIds[0], environmentIds[1])
+
+	results, err := compare.CompareEnvironments(createApis(), clients[0], clients[1], fieldOverrides)
+	if err != nil {
+		util.Log.Error("Comparison failed: %s", err)
+		return -1
+	}
+
+	if outputFormat != outputTable {
+		comparison := newEnvironmentComparison(results, environmentIds[0], environmentIds[1])
+		err = writeOutput(os.Stdout, outputFormat, comparison)
+		if err != nil {
+			util.Log.Error("Writing comparison failed: %s", err)
+			return -1
+		}
+		if !comparison.Equal {
+			return 1
+		}
+		return 0
+	}
+
+	if printComparisonResults(results, environmentIds[0], environmentIds[1]) {
+		return 1
+	}
+
+	util.Log.Info("Environments %s and %s are equal", environmentIds[0], environmentIds[1])
+	return 0
+}
+
+// printComparisonResults logs all differences and returns true if any difference was found
+func printComparisonResults(results []compare.ApiResult, first string, second string) (foundDifferences bool) {
+
+	for _, result := range results {
+
+		if !result.HasDifferences() {
+			continue
+		}
+		foundDifferences = true
+
+		util.Log.Info("%s:", result.Api)
+
+		for _, name := range result.OnlyInFirst {
+			util.Log.Info("\tonly in %s: %s", first, name)
+		}
+		for _, name := range result.OnlyInSecond {
+			util.Log.Info("\tonly in %s: %s", second, name)
+		}
+		for _, name := range result.DifferentConfigs() {
+			util.Log.Info("\tdifferent: %s", name)
+			for _, difference := range result.Differences[name] {
+				util.Log.Info("\t\t%s: %s (%s) != %s (%s)", difference.Path, valueOrMissing(difference.First), first, valueOrMissing(difference.Second), second)
+			}
+		}
+	}
+
+	return foundDifferences
+}
+
+// environmentComparison is the machine readable output of the compare command, listing the apis with differences
+type environmentComparison struct {
+	First  string              `json:"first"`
+	Second string              `json:"second"`
+	Equal  bool                `json:"equal"`
+	Apis   []compare.ApiResult `json:"apis"`
+}
+
+func newEnvironmentComparison(results []compare.ApiResult, first string, second string) environmentComparison {
+
+	comparison := environmentComparison{First: first, Second: second, Apis: make([]compare.ApiResult, 0)}
+	for _, result := range results {
+		if result.HasDifferences() {
+			comparison.Apis = append(comparison.Apis, result)
+		}
+	}
+	comparison.Equal = len(comparison.Apis) == 0
+
+	return comparison
+}
+
+func valueOrMissing(value string) string {
+	if value == "" {
+		return "<missing>"
+	}
+	return value
+}
+
+func createClientForEnvironment(environments map[string]environment.Environment, id string, options rest.ClientOptions) (rest.DynatraceClient, error) {
+
+	env, found := environments[id]
+	if !found {
+		return nil, fmt.Errorf("environment %s not found", id)
+	}
+
+	return createClient(env, options)
+}
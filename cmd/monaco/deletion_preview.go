@@ -0,0 +1,92 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// deployedObjects returns the names of the objects the configs of the projects are deployed to in the environment,
+// by api. Configs skipped in the environment or not matching the ownership filter are left out
+func deployedObjects(projects []project.Project, environment environment.Environment, ownership ownershipFilter) map[string][]string {
+
+	deployed := make(map[string][]string)
+
+	for _, project := range projects {
+		for _, config := range project.GetConfigs() {
+
+			if config.IsSkipDeployment(environment) || !ownership.matchesConfig(config, environment) {
+				continue
+			}
+
+			name, err := config.GetObjectNameForEnvironment(environment, map[string]api.DynatraceEntity{})
+			if err != nil {
+				util.Log.Debug("\tName of config %s could not be resolved for the deletion preview: %s", config.GetFilePath(), err)
+				continue
+			}
+
+			apiId := config.GetApi().GetId()
+			deployed[apiId] = append(deployed[apiId], name)
+		}
+	}
+
+	return deployed
+}
+
+// logDeletionTargets shows the targets which are going to be deleted from the environment. Targets of objects
+// deployed by the same run are highlighted, as they are deleted right after their deployment
+func logDeletionTargets(environmentId string, targets []delete.Target, deployed map[string][]string) {
+
+	if len(targets) == 0 {
+		util.Log.Info("No configs to delete from environment %s", environmentId)
+		return
+	}
+
+	util.Log.Info("The following %d configs will be deleted from environment %s:", len(targets), environmentId)
+
+	redeployed := 0
+	for _, target := range targets {
+		util.Log.Info("\t- %s", describeDeletionTarget(target, deployed))
+		if contains(deployed[target.Api.GetId()], target.Name) {
+			redeployed++
+		}
+	}
+
+	if redeployed > 0 {
+		util.Log.Warn("\t%d of these configs are deployed by this run and deleted right after their deployment", redeployed)
+	}
+}
+
+func describeDeletionTarget(target delete.Target, deployed map[string][]string) string {
+
+	if target.Id == "" {
+		return fmt.Sprintf("%s '%s' (created by this deployment)", target.Api.GetId(), target.Name)
+	}
+
+	description := fmt.Sprintf("%s '%s' (%s)", target.Api.GetId(), target.Name, target.Id)
+	if contains(deployed[target.Api.GetId()], target.Name) {
+		description += ", deployed by this run"
+	}
+
+	return description
+}
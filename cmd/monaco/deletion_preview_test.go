@@ -0,0 +1,51 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func TestDeployedObjectsContainsNamesOfConfigsByApi(t *testing.T) {
+
+	projects := loadProtectedTestProject(t)
+	production := environment.NewEnvironment("production", "production", "", "https://production", "TOKEN")
+
+	deployed := deployedObjects(projects, production, ownershipFilter{})
+
+	assert.Equal(t, len(deployed), 1)
+	names := deployed["dashboard"]
+	sort.Strings(names)
+	assert.DeepEqual(t, names, []string{"SRE Overview", "Team Overview"})
+}
+
+func TestDescribeDeletionTargetHighlightsDeployedObjects(t *testing.T) {
+
+	dashboards := createApis()["dashboard"]
+	deployed := map[string][]string{"dashboard": {"SRE Overview"}}
+
+	assert.Equal(t, describeDeletionTarget(delete.Target{Api: dashboards, Id: "1", Name: "Team Overview"}, deployed), "dashboard 'Team Overview' (1)")
+	assert.Equal(t, describeDeletionTarget(delete.Target{Api: dashboards, Id: "2", Name: "SRE Overview"}, deployed), "dashboard 'SRE Overview' (2), deployed by this run")
+	assert.Equal(t, describeDeletionTarget(delete.Target{Api: dashboards, Name: "SRE Overview"}, deployed), "dashboard 'SRE Overview' (created by this deployment)")
+}
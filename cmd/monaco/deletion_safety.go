@@ -0,0 +1,81 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// confirmHighImpactDeletions asks once for all environments whether the targets of high impact apis are deleted.
+// If monaco does not run interactively or the deletion is not confirmed, these targets are removed from the plans.
+// Returns the plans and the number of withheld targets, which fail the run
+func confirmHighImpactDeletions(plans []deletionPlan, levels delete.SafetyLevels, prompter *prompter) ([]deletionPlan, int) {
+
+	highImpact := 0
+	for _, plan := range plans {
+		highImpact += len(levels.HighImpactTargets(plan.targets))
+	}
+	if highImpact == 0 {
+		return plans, 0
+	}
+
+	if prompter.interactive {
+		confirmed, err := prompter.confirm(fmt.Sprintf("Delete %d configs of high impact apis? Type yes to confirm: ", highImpact))
+		if err != nil {
+			util.Log.Warn("\tConfirmation of high impact deletions failed: %s", err)
+		}
+		if confirmed {
+			return plans, 0
+		}
+	}
+
+	kept := make([]deletionPlan, 0, len(plans))
+	for _, plan := range plans {
+
+		targets := make([]delete.Target, 0, len(plan.targets))
+		for _, target := range plan.targets {
+			if levels.Level(target.Api.GetId()) == delete.HighImpact {
+				util.Log.Warn("\tSkipping deletion of high impact config %s '%s' (%s) from %s, pass --allow-high-impact-deletions to delete it",
+					target.Api.GetId(), target.Name, target.Id, plan.environment.GetId())
+				continue
+			}
+			targets = append(targets, target)
+		}
+
+		plan.targets = targets
+		kept = append(kept, plan)
+	}
+
+	return kept, highImpact
+}
+
+// confirm asks the question and returns whether it was answered with yes
+func (p *prompter) confirm(question string) (bool, error) {
+
+	fmt.Fprint(p.out, question)
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && line == "" {
+		return false, err
+	}
+
+	return strings.EqualFold(strings.TrimSpace(line), "yes"), nil
+}
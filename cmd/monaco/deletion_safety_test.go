@@ -0,0 +1,72 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func highImpactTestPlans() []deletionPlan {
+
+	apis := api.NewApis()
+	return []deletionPlan{
+		{
+			environment: environment.NewEnvironment("dev", "dev", "", "https://dev.example.com", "DEV"),
+			targets: []delete.Target{
+				{Api: apis["dashboard"], Id: "1", Name: "dashboard"},
+				{Api: apis["management-zone"], Id: "2", Name: "zone"},
+			},
+		},
+	}
+}
+
+func TestConfirmedHighImpactDeletionsAreKept(t *testing.T) {
+
+	prompter, out, _ := newTestPrompter("yes\n", true)
+
+	plans, withheld := confirmHighImpactDeletions(highImpactTestPlans(), delete.DefaultSafetyLevels(), prompter)
+	assert.Equal(t, len(plans[0].targets), 2)
+	assert.Equal(t, withheld, 0)
+	assert.Assert(t, out.Len() > 0)
+}
+
+func TestDeclinedHighImpactDeletionsAreSkipped(t *testing.T) {
+
+	prompter, _, _ := newTestPrompter("no\n", true)
+
+	plans, withheld := confirmHighImpactDeletions(highImpactTestPlans(), delete.DefaultSafetyLevels(), prompter)
+	assert.Equal(t, len(plans[0].targets), 1)
+	assert.Equal(t, plans[0].targets[0].Id, "1")
+	assert.Equal(t, withheld, 1)
+}
+
+func TestHighImpactDeletionsAreSkippedWhenNotInteractive(t *testing.T) {
+
+	prompter, out, _ := newTestPrompter("yes\n", false)
+
+	plans, withheld := confirmHighImpactDeletions(highImpactTestPlans(), delete.DefaultSafetyLevels(), prompter)
+	assert.Equal(t, len(plans[0].targets), 1)
+	assert.Equal(t, out.Len(), 0)
+	assert.Equal(t, withheld, 1)
+}
@@ -0,0 +1,40 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// reportDeprecations warns about the deprecated endpoints used for the environment and adds them to the summary.
+// Returns an error if deprecations are not tolerated
+func reportDeprecations(environment environment.Environment, deprecations []rest.Deprecation, options executionOptions) error {
+
+	for _, deprecation := range deprecations {
+		util.Log.Warn("\t%s: %s", environment.GetId(), deprecation)
+		options.summary.deprecated(environment.GetId(), deprecation.String())
+	}
+
+	if options.strictDeprecations && len(deprecations) > 0 {
+		return fmt.Errorf("%d deprecated endpoints used, which --strict-deprecations does not allow", len(deprecations))
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+func TestReportDeprecations(t *testing.T) {
+
+	env := environment.NewEnvironment("dev", "dev", "", "https://url/to/dev/environment", "DEV")
+	deprecations := []rest.Deprecation{{Endpoint: "management-zone", Deprecated: "true"}}
+
+	summary := newRunSummary(false, time.Now())
+	err := reportDeprecations(env, deprecations, executionOptions{summary: summary})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, summary.environments["dev"].Deprecations, []string{"management-zone is deprecated"})
+
+	err = reportDeprecations(env, deprecations, executionOptions{strictDeprecations: true})
+	assert.ErrorContains(t, err, "1 deprecated endpoints used")
+
+	err = reportDeprecations(env, nil, executionOptions{strictDeprecations: true})
+	assert.NilError(t, err)
+}
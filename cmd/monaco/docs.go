@@ -0,0 +1,381 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// docsSite is the inventory of projects and environments the docs command renders
+type docsSite struct {
+	Environments []docsEnvironment
+	Projects     []docsProject
+}
+
+type docsEnvironment struct {
+	Id    string
+	Name  string
+	Group string
+	Url   string
+	Tags  string
+}
+
+type docsProject struct {
+	Id           string
+	Anchor       string
+	Dependencies []string
+	Variables    []project.Variable
+	Assertions   []string
+	Configs      []docsConfig
+}
+
+type docsConfig struct {
+	Id        string
+	Api       string
+	Name      string
+	Owner     string
+	Labels    string
+	Template  string
+	DependsOn []string
+	SkippedIn []string
+}
+
+// runDocs executes the docs command, which renders an inventory of all projects, their configs, variables and
+// dependencies, and the environments they are deployed to as markdown or html. Returns 0 on success and -1 on errors
+func runDocs(args []string, fileReader util.FileReader) int {
+
+	var environmentsFile, projectFlag, outputFolder, format string
+	var verbose bool
+
+	flagSet := flag.NewFlagSet("docs", flag.ExitOnError)
+
+	environmentsUsage := "Yaml file containing the environments, which are listed with the configs skipped in them."
+	flagSet.StringVar(&environmentsFile, "environments", "", environmentsUsage)
+	flagSet.StringVar(&environmentsFile, "e", "", environmentsUsage+" (shorthand)")
+
+	projectUsage := "Project to document, together with the projects it depends on. Defaults to all projects."
+	flagSet.StringVar(&projectFlag, "project", "", projectUsage)
+	flagSet.StringVar(&projectFlag, "p", "", projectUsage+" (shorthand)")
+
+	outputFolderUsage := "Folder the documentation is written to."
+	flagSet.StringVar(&outputFolder, "output-folder", "docs", outputFolderUsage)
+
+	formatUsage := "Format of the documentation: markdown or html."
+	flagSet.StringVar(&format, "format", "markdown", formatUsage)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+" (shorthand)")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	if format != "markdown" && format != "html" {
+		util.Log.Error("Invalid --format %s, expected markdown or html", format)
+		return -1
+	}
+
+	environments := make(map[string]environment.Environment)
+	if environmentsFile != "" {
+		var errorList []error
+		environments, errorList = environment.LoadEnvironmentList("", environmentsFile, fileReader)
+		for _, err := range errorList {
+			util.Log.Error("Loading of environments failed: %s", err)
+		}
+		if len(errorList) > 0 {
+			return -1
+		}
+	}
+
+	path := ""
+	if flagSet.NArg() > 0 {
+		path = readPath(flagSet.Args(), fileReader)
+	}
+
+	projects, err := project.LoadProjectsToDeploy(projectFlag, createApis(), path, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of projects failed: %s", err)
+		return -1
+	}
+
+	file, err := writeDocs(buildDocsSite(projects, environments, path), outputFolder, format)
+	if err != nil {
+		util.Log.Error("Writing documentation failed: %s", err)
+		return -1
+	}
+
+	util.Log.Info("Documentation of %d projects written to %s", len(projects), file)
+	return 0
+}
+
+// buildDocsSite collects the inventory of the projects and environments. Projects are listed in the order they
+// are deployed in, environments by id
+func buildDocsSite(projects []project.Project, environments map[string]environment.Environment, path string) docsSite {
+
+	site := docsSite{}
+
+	for _, id := range sortedEnvironmentIds(environments) {
+		env := environments[id]
+		site.Environments = append(site.Environments, docsEnvironment{
+			Id:    env.GetId(),
+			Name:  env.GetName(),
+			Group: env.GetGroup(),
+			Url:   env.GetEnvironmentUrl(),
+			Tags:  strings.Join(env.GetTags(), ", "),
+		})
+	}
+
+	all := make([]config.Config, 0)
+	for _, p := range projects {
+		all = append(all, p.GetConfigs()...)
+	}
+
+	for _, p := range projects {
+		id := docsProjectId(p.GetId(), path)
+		documented := docsProject{
+			Id:           id,
+			Anchor:       docsAnchor(id),
+			Dependencies: projectDependencies(p, projects, path),
+			Variables:    p.GetVariables(),
+		}
+
+		for _, assertion := range p.GetAssertions() {
+			documented.Assertions = append(documented.Assertions, fmt.Sprintf("%s (%s %s)", assertion.Name, assertion.Api, assertion.Object))
+		}
+
+		for _, c := range p.GetConfigs() {
+			documented.Configs = append(documented.Configs, documentConfig(c, all, environments, path))
+		}
+
+		site.Projects = append(site.Projects, documented)
+	}
+
+	return site
+}
+
+// docsProjectId returns the id of the project relative to the project folder
+func docsProjectId(id string, path string) string {
+	return filepath.ToSlash(strings.TrimPrefix(id, path))
+}
+
+// projectDependencies returns the ids of the projects the project depends on, sorted
+func projectDependencies(p project.Project, projects []project.Project, path string) []string {
+
+	dependencies := make([]string, 0)
+	for _, other := range projects {
+		if other.GetId() != p.GetId() && p.HasDependencyOn(other) {
+			dependencies = append(dependencies, docsProjectId(other.GetId(), path))
+		}
+	}
+
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+func documentConfig(c config.Config, all []config.Config, environments map[string]environment.Environment, path string) docsConfig {
+
+	properties := c.GetProperties()[c.GetId()]
+
+	documented := docsConfig{
+		Id:       c.GetId(),
+		Api:      c.GetApi().GetId(),
+		Name:     properties["name"],
+		Owner:    properties["owner"],
+		Labels:   properties["labels"],
+		Template: filepath.ToSlash(strings.TrimPrefix(c.GetFilePath(), path)),
+	}
+
+	for _, other := range all {
+		if other != c && c.HasDependencyOn(other) {
+			documented.DependsOn = append(documented.DependsOn, configReference(other, path))
+		}
+	}
+	sort.Strings(documented.DependsOn)
+
+	for _, id := range sortedEnvironmentIds(environments) {
+		if c.IsSkipDeployment(environments[id]) {
+			documented.SkippedIn = append(documented.SkippedIn, id)
+		}
+	}
+
+	return documented
+}
+
+// docsAnchor derives the anchor linking to a project from its id
+func docsAnchor(id string) string {
+	return "project-" + strings.Trim(strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, filepath.ToSlash(id)), "-")
+}
+
+// writeDocs renders the site into index.md or index.html within the folder and returns the written file
+func writeDocs(site docsSite, folder string, format string) (string, error) {
+
+	var content bytes.Buffer
+	var file string
+
+	if format == "html" {
+		file = filepath.Join(folder, "index.html")
+		templ := htmltemplate.Must(htmltemplate.New("docs").Funcs(htmltemplate.FuncMap{"join": strings.Join}).Parse(htmlDocsTemplate))
+		if err := templ.Execute(&content, site); err != nil {
+			return "", err
+		}
+	} else {
+		file = filepath.Join(folder, "index.md")
+		templ := template.Must(template.New("docs").Funcs(template.FuncMap{"join": strings.Join, "cell": markdownCell}).Parse(markdownDocsTemplate))
+		if err := templ.Execute(&content, site); err != nil {
+			return "", err
+		}
+	}
+
+	err := os.MkdirAll(folder, 0755)
+	if err != nil {
+		return "", err
+	}
+
+	return file, ioutil.WriteFile(file, content.Bytes(), 0644)
+}
+
+// markdownCell escapes a value for a markdown table cell
+func markdownCell(value string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(value, "|", `\|`), "\n", " ")
+}
+
+const markdownDocsTemplate = `# Monitoring as Code
+{{- if .Environments}}
+
+## Environments
+
+| Environment | Name | Group | Url | Tags |
+|-------------|------|-------|-----|------|
+{{- range .Environments}}
+| {{cell .Id}} | {{cell .Name}} | {{cell .Group}} | {{cell .Url}} | {{cell .Tags}} |
+{{- end}}
+{{- end}}
+
+## Projects
+{{range .Projects}}
+- [{{.Id}}](#{{.Anchor}}) ({{len .Configs}} configs)
+{{- end}}
+{{- range .Projects}}
+
+<a id="{{.Anchor}}"></a>
+### {{.Id}}
+{{- if .Dependencies}}
+
+Depends on: {{join .Dependencies ", "}}
+{{- end}}
+{{- if .Variables}}
+
+| Variable | Description |
+|----------|-------------|
+{{- range .Variables}}
+| {{cell .Name}} | {{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- if .Assertions}}
+
+Assertions: {{join .Assertions ", "}}
+{{- end}}
+
+| Config | Api | Name | Owner | Labels | Template | Depends on | Skipped in |
+|--------|-----|------|-------|--------|----------|------------|------------|
+{{- range .Configs}}
+| {{cell .Id}} | {{cell .Api}} | {{cell .Name}} | {{cell .Owner}} | {{cell .Labels}} | {{cell .Template}} | {{cell (join .DependsOn ", ")}} | {{cell (join .SkippedIn ", ")}} |
+{{- end}}
+{{- end}}
+`
+
+const htmlDocsTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Monitoring as Code</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin: 1em 0; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>Monitoring as Code</h1>
+{{- if .Environments}}
+<h2>Environments</h2>
+<table>
+<tr><th>Environment</th><th>Name</th><th>Group</th><th>Url</th><th>Tags</th></tr>
+{{- range .Environments}}
+<tr><td>{{.Id}}</td><td>{{.Name}}</td><td>{{.Group}}</td><td>{{.Url}}</td><td>{{.Tags}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Projects</h2>
+<ul>
+{{- range .Projects}}
+<li><a href="#{{.Anchor}}">{{.Id}}</a> ({{len .Configs}} configs)</li>
+{{- end}}
+</ul>
+{{- range .Projects}}
+<h3 id="{{.Anchor}}">{{.Id}}</h3>
+{{- if .Dependencies}}
+<p>Depends on: {{join .Dependencies ", "}}</p>
+{{- end}}
+{{- if .Variables}}
+<table>
+<tr><th>Variable</th><th>Description</th></tr>
+{{- range .Variables}}
+<tr><td>{{.Name}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Assertions}}
+<p>Assertions: {{join .Assertions ", "}}</p>
+{{- end}}
+<table>
+<tr><th>Config</th><th>Api</th><th>Name</th><th>Owner</th><th>Labels</th><th>Template</th><th>Depends on</th><th>Skipped in</th></tr>
+{{- range .Configs}}
+<tr><td>{{.Id}}</td><td>{{.Api}}</td><td>{{.Name}}</td><td>{{.Owner}}</td><td>{{.Labels}}</td><td>{{.Template}}</td><td>{{join .DependsOn ", "}}</td><td>{{join .SkippedIn ", "}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+</body>
+</html>
+`
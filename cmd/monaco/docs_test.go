@@ -0,0 +1,95 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func loadDocsTestSite(t *testing.T) docsSite {
+
+	path := util.ReplacePathSeparators("test-resources/integration-multi-project/")
+
+	projects, err := project.LoadProjectsToDeploy("", createApis(), path, util.NewFileReader())
+	assert.NilError(t, err)
+
+	environments, errorList := environment.LoadEnvironmentList("", util.ReplacePathSeparators("test-resources/test-environments.yaml"), util.NewFileReader())
+	assert.Equal(t, len(errorList), 0)
+
+	return buildDocsSite(projects, environments, path)
+}
+
+func TestBuildDocsSiteListsProjectsConfigsAndDependencies(t *testing.T) {
+
+	site := loadDocsTestSite(t)
+
+	assert.Assert(t, len(site.Environments) > 0)
+
+	var movies docsProject
+	for _, p := range site.Projects {
+		if strings.HasSuffix(p.Id, "the-hitchhikers-guide-to-the-galaxy") {
+			movies = p
+		}
+	}
+	assert.Assert(t, len(movies.Configs) > 0)
+	assert.Assert(t, len(movies.Dependencies) > 0, "the project references configs of other projects")
+
+	dependsOnOthers := false
+	for _, c := range movies.Configs {
+		assert.Assert(t, c.Api != "" && c.Template != "")
+		dependsOnOthers = dependsOnOthers || len(c.DependsOn) > 0
+	}
+	assert.Assert(t, dependsOnOthers)
+}
+
+func TestWriteDocs(t *testing.T) {
+
+	site := loadDocsTestSite(t)
+	folder := t.TempDir()
+
+	file, err := writeDocs(site, folder, "markdown")
+	assert.NilError(t, err)
+	content, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(file, "index.md"))
+	assert.Assert(t, strings.Contains(string(content), "## Projects"))
+	assert.Assert(t, strings.Contains(string(content), `<a id="`+site.Projects[0].Anchor+`"></a>`))
+
+	file, err = writeDocs(site, folder, "html")
+	assert.NilError(t, err)
+	content, err = ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.HasSuffix(file, "index.html"))
+	assert.Assert(t, strings.Contains(string(content), `<h3 id="`+site.Projects[0].Anchor+`">`))
+}
+
+func TestDocsAnchor(t *testing.T) {
+	assert.Equal(t, docsAnchor("movies/science fiction/star-trek"), "project-movies-science-fiction-star-trek")
+}
+
+func TestMarkdownCell(t *testing.T) {
+	assert.Equal(t, markdownCell("a|b\nc"), `a\|b c`)
+}
@@ -0,0 +1,173 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// runDownload executes the download command, which downloads the configs of an environment into a monaco project.
+// Either all supported configs or the objects given by --ids are downloaded. With --review, the project is not
+// written, but its differences to the environment. Returns 0 on success, 1 if a review found differences and -1 on errors
+func runDownload(args []string, fileReader util.FileReader) (statusCode int) {
+
+	var environmentsFile, specificEnvironment, projectName, outputFolder, objectIds, fieldOverridesFile, ownerRulesFile, reviewFile, provenance, timestamp string
+	var splitDashboardTiles int
+	var verbose, pin bool
+	var clientFlags clientFlags
+
+	flagSet := flag.NewFlagSet("download", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Environment (from list) to download from.")
+
+	projectUsage := "Mandatory name of the project the downloaded configs are written to."
+	flagSet.StringVar(&projectName, "project", "", projectUsage)
+	flagSet.StringVar(&projectName, "p", "", projectUsage+" (shorthand)")
+
+	outputFolderUsage := "Folder the project is written to. Defaults to the current working dir."
+	flagSet.StringVar(&outputFolder, "output-folder", ".", outputFolderUsage)
+
+	idsUsage := "Comma separated list of objects to download, given as api:id (e.g. dashboard:abc-123). Downloads all configs if empty."
+	flagSet.StringVar(&objectIds, "ids", "", idsUsage)
+
+	splitDashboardsUsage := "Write the tiles of dashboards with more tiles than the given number to separate files. Dashboards are not split if 0."
+	flagSet.IntVar(&splitDashboardTiles, "split-dashboards", 0, splitDashboardsUsage)
+
+	flagSet.StringVar(&fieldOverridesFile, "field-overrides", "", fieldOverridesUsage)
+
+	ownerRulesUsage := "Yaml file with rules stripping, replacing or moving the owners of downloaded dashboards into the dashboardOwner parameter."
+	flagSet.StringVar(&ownerRulesFile, "owner-rules", "", ownerRulesUsage)
+
+	pinUsage := "Pin every downloaded config to the object it was downloaded from, so that deployments fail if the object changed in the environment since."
+	flagSet.BoolVar(&pin, "pin", false, pinUsage)
+
+	reviewUsage := "Json file the differences between the environment and the project are written to, instead of overwriting the project."
+	flagSet.StringVar(&reviewFile, "review", "", reviewUsage)
+
+	provenanceUsage := "Record the environment, id and time every config is downloaded from, as field of its template (field) or in a <config>.provenance.json file next to it (sidecar)."
+	flagSet.StringVar(&provenance, "provenance", "", provenanceUsage)
+
+	addClientFlags(flagSet, &clientFlags)
+	addTimestampFlag(flagSet, &timestamp)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if projectName == "" {
+		println("Please provide the project name with -p/--project!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+
+	err = download.ValidateProvenanceMode(provenance)
+	if err != nil {
+		util.Log.Error("Invalid --provenance: %s", err)
+		return -1
+	}
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return -1
+	}
+
+	err = freezeTime(timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	fieldOverrides, err := api.LoadFieldOverrides(fieldOverridesFile, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of field overrides failed: %s", err)
+		return -1
+	}
+
+	ownerRules, err := download.LoadOwnerRules(ownerRulesFile, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of owner rules failed: %s", err)
+		return -1
+	}
+
+	clientOptions, err := clientFlags.apply(rest.DefaultClientOptions(), fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+	defer func() {
+		if !clientFlags.saveNameCache(clientOptions) {
+			statusCode = -1
+		}
+	}()
+
+	client, err := createClientForEnvironment(map[string]environment.Environment{env.GetId(): env}, env.GetId(), clientOptions)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	var ids map[string][]string
+	if objectIds != "" {
+		ids, err = download.ParseObjectIds(objectIds)
+		if err != nil {
+			util.Log.Error("Invalid --ids: %s", err)
+			return -1
+		}
+	}
+
+	options := download.Options{SplitDashboardTiles: splitDashboardTiles, OwnerRules: ownerRules, Pin: pin, FieldOverrides: fieldOverrides}
+
+	// the provenance of downloaded configs would always differ from the project, it is not reviewed
+	options.Environment = env.GetId()
+	if reviewFile == "" {
+		options.Provenance = provenance
+		options.Timestamp = util.Now()
+	}
+
+	downloadTo := func(projectFolder string) (int, error) {
+		if ids == nil {
+			return download.DownloadConfigs(createApis(), client, projectFolder, options)
+		}
+		return download.DownloadObjects(createApis(), client, projectFolder, ids, options)
+	}
+
+	if reviewFile != "" {
+		util.Log.Info("Reviewing differences between environment %s and project %s...", env.GetId(), filepath.Join(outputFolder, projectName))
+		return reviewDrift(client, env, outputFolder, projectName, downloadTo, ids != nil, reviewFile, fieldOverrides)
+	}
+
+	projectFolder := filepath.Join(outputFolder, projectName)
+	util.Log.Info("Downloading configs of environment %s into project %s...", env.GetId(), projectFolder)
+
+	count, err := downloadTo(projectFolder)
+	if err != nil {
+		util.Log.Error("Download from %s failed: %s", env.GetId(), err)
+		return -1
+	}
+
+	util.Log.Info("Downloaded %d configs into %s", count, projectFolder)
+	return 0
+}
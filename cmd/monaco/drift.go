@@ -0,0 +1,243 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/compare"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// driftReview is the report written by download --review, which lists the configs differing between an environment
+// and the project in the repository, so that teams can decide whether to accept the drift or to deploy again
+type driftReview struct {
+	Environment string        `json:"environment"`
+	Project     string        `json:"project"`
+	Configs     []configDrift `json:"configs"`
+}
+
+// configDrift is a config which differs between the environment and the repository. Configs are matched by api and
+// name, the status is one of changed, only-in-environment and only-in-repository
+type configDrift struct {
+	Api         string            `json:"api"`
+	Name        string            `json:"name"`
+	Status      string            `json:"status"`
+	Config      string            `json:"config,omitempty"`
+	Differences []driftDifference `json:"differences,omitempty"`
+}
+
+// driftDifference is a field of a config differing between the repository and the environment. A missing field is
+// represented by an empty value
+type driftDifference struct {
+	Path        string `json:"path"`
+	Repository  string `json:"repository"`
+	Environment string `json:"environment"`
+}
+
+// reviewDrift downloads the configs of the environment into a temporary folder, instead of the project folder, and
+// writes the differences to the project in the repository into the review file. Only downloaded configs are reviewed
+// if onlyDownloaded is set, e.g. when specific objects are downloaded. References of both sides are resolved to the
+// ids of the objects in the environment, as downloaded payloads contain these ids. Returns 0 without differences, 1
+// with differences and -1 on errors
+func reviewDrift(client rest.DynatraceClient, env environment.Environment, outputFolder string, projectName string,
+	downloadTo func(projectFolder string) (int, error), onlyDownloaded bool, reviewFile string, fields api.FieldOverrides) int {
+
+	folder, err := ioutil.TempDir(os.TempDir(), "monaco-review-")
+	if err != nil {
+		util.Log.Error("Creating folder for the download failed: %s", err)
+		return -1
+	}
+	defer os.RemoveAll(folder)
+
+	_, err = downloadTo(filepath.Join(folder, projectName))
+	if err != nil {
+		util.Log.Error("Download from %s failed: %s", env.GetId(), err)
+		return -1
+	}
+
+	downloaded, err := renderProject(client, folder, projectName, env)
+	if err != nil {
+		util.Log.Error("Rendering downloaded configs failed: %s", err)
+		return -1
+	}
+
+	repository := make([]renderedConfig, 0)
+	if _, err := os.Stat(filepath.Join(outputFolder, projectName)); err == nil {
+		repository, err = renderProject(client, outputFolder, projectName, env)
+		if err != nil {
+			util.Log.Error("Rendering configs of project %s failed: %s", projectName, err)
+			return -1
+		}
+	}
+
+	review := driftReview{
+		Environment: env.GetId(),
+		Project:     projectName,
+		Configs:     diffDrift(repository, downloaded, onlyDownloaded, fields),
+	}
+
+	content, err := json.MarshalIndent(review, "", "  ")
+	if err != nil {
+		util.Log.Error("Writing review failed: %s", err)
+		return -1
+	}
+
+	err = ioutil.WriteFile(reviewFile, content, 0664)
+	if err != nil {
+		util.Log.Error("Writing review to %s failed: %s", reviewFile, err)
+		return -1
+	}
+
+	for _, drift := range review.Configs {
+		util.Log.Info("\t%s %s: %s", drift.Api, drift.Name, drift.Status)
+	}
+	util.Log.Info("%d configs of %s differ from project %s, the review was written to %s", len(review.Configs), env.GetId(), projectName, reviewFile)
+
+	if len(review.Configs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// renderProject renders the configs of the project within folder, which the download supports, for the environment.
+// References render as the id of the referenced object in the environment, found by its name
+func renderProject(client rest.DynatraceClient, folder string, projectName string, env environment.Environment) ([]renderedConfig, error) {
+
+	path := filepath.Clean(folder) + string(os.PathSeparator)
+	apis := createApis()
+
+	projects, err := project.LoadProjectsToDeploy(projectName, apis, path, util.NewFileReader())
+	if err != nil {
+		return nil, err
+	}
+
+	idOf := func(apiId string, name string, coordinates string) string {
+		if a, found := apis[apiId]; found {
+			if exists, id, err := client.ExistsByName(a, name); err == nil && exists {
+				return id
+			}
+		}
+		// objects which do not exist in the environment, or whose name is not unique, can not be resolved
+		return "<" + coordinates + ">"
+	}
+
+	configs, err := renderConfigListWithIds(projects, env, path, idOf)
+	if err != nil {
+		return nil, err
+	}
+
+	// dependencies of the project are rendered for references to them, but not reviewed
+	prefix := filepath.ToSlash(projectName) + "/"
+
+	rendered := make([]renderedConfig, 0, len(configs))
+	for _, config := range configs {
+		if strings.HasPrefix(config.coordinates, prefix) && download.IsSupported(config.api) {
+			rendered = append(rendered, config)
+		}
+	}
+
+	return rendered, nil
+}
+
+// diffDrift matches the configs of the repository and the environment by api and name and returns the configs which
+// differ, sorted by api and name
+func diffDrift(repository []renderedConfig, environment []renderedConfig, onlyDownloaded bool, fields api.FieldOverrides) []configDrift {
+
+	key := func(config renderedConfig) string {
+		return config.api + "/" + config.name
+	}
+
+	inRepository := make(map[string]renderedConfig, len(repository))
+	for _, config := range repository {
+		inRepository[key(config)] = config
+	}
+
+	inEnvironment := make(map[string]bool, len(environment))
+	drifts := make([]configDrift, 0)
+
+	for _, downloaded := range environment {
+		inEnvironment[key(downloaded)] = true
+
+		existing, found := inRepository[key(downloaded)]
+		if !found {
+			drifts = append(drifts, configDrift{Api: downloaded.api, Name: downloaded.name, Status: "only-in-environment"})
+			continue
+		}
+
+		differences := diffPayloads(existing, downloaded, fields)
+		if len(differences) > 0 {
+			drifts = append(drifts, configDrift{
+				Api:         downloaded.api,
+				Name:        downloaded.name,
+				Status:      "changed",
+				Config:      existing.coordinates,
+				Differences: differences,
+			})
+		}
+	}
+
+	if !onlyDownloaded {
+		for _, existing := range repository {
+			if !inEnvironment[key(existing)] {
+				drifts = append(drifts, configDrift{Api: existing.api, Name: existing.name, Status: "only-in-repository", Config: existing.coordinates})
+			}
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		if drifts[i].Api != drifts[j].Api {
+			return drifts[i].Api < drifts[j].Api
+		}
+		return drifts[i].Name < drifts[j].Name
+	})
+
+	return drifts
+}
+
+// diffPayloads compares the payloads like render-diff, payloads which are not valid json are compared as a whole.
+// Server managed fields are stripped from the repository's payload, like before it is uploaded
+func diffPayloads(existing renderedConfig, downloaded renderedConfig, fields api.FieldOverrides) []driftDifference {
+
+	if existing.payload == downloaded.payload {
+		return nil
+	}
+
+	payload, _, _ := stripServerManagedFields(existing.api, existing.payload, fields)
+
+	differences, err := compare.DiffPayloads(existing.api, []byte(payload), []byte(downloaded.payload), fields)
+	if err != nil {
+		return []driftDifference{{Repository: existing.payload, Environment: downloaded.payload}}
+	}
+
+	result := make([]driftDifference, 0, len(differences))
+	for _, difference := range differences {
+		result = append(result, driftDifference{Path: difference.Path, Repository: difference.First, Environment: difference.Second})
+	}
+
+	return result
+}
@@ -0,0 +1,144 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func TestDiffDriftMatchesConfigsByApiAndName(t *testing.T) {
+
+	repository := []renderedConfig{
+		{coordinates: "proj/alerting-profile/profile", api: "alerting-profile", name: "Profile", payload: `{"displayName": "Profile", "rules": []}`},
+		{coordinates: "proj/management-zone/zone", api: "management-zone", name: "Zone", payload: `{"name": "Zone"}`},
+		{coordinates: "proj/auto-tag/tag", api: "auto-tag", name: "Tag", payload: `{"name": "Tag"}`},
+	}
+	downloaded := []renderedConfig{
+		{coordinates: "proj/alerting-profile/Profile", api: "alerting-profile", name: "Profile", payload: `{"displayName": "Profile", "rules": [1]}`},
+		{coordinates: "proj/auto-tag/Tag", api: "auto-tag", name: "Tag", payload: `{"name":"Tag"}`},
+		{coordinates: "proj/dashboard/Overview", api: "dashboard", name: "Overview", payload: `{}`},
+	}
+
+	assert.DeepEqual(t, diffDrift(repository, downloaded, false, nil), []configDrift{
+		{Api: "alerting-profile", Name: "Profile", Status: "changed", Config: "proj/alerting-profile/profile", Differences: []driftDifference{
+			{Path: "rules[0]", Repository: "", Environment: "1"},
+		}},
+		{Api: "dashboard", Name: "Overview", Status: "only-in-environment"},
+		{Api: "management-zone", Name: "Zone", Status: "only-in-repository", Config: "proj/management-zone/zone"},
+	})
+
+	assert.Equal(t, len(diffDrift(repository, downloaded, true, nil)), 2)
+}
+
+func TestReviewDriftWritesReviewInsteadOfProject(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-drift-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	writeDriftTestConfig(t, filepath.Join(folder, "proj"), "management-zone", "zone", "Zone", `{"name": "{{ .name }}", "rules": []}`)
+
+	downloadTo := func(projectFolder string) (int, error) {
+		writeDriftTestConfig(t, projectFolder, "management-zone", "Zone", "Zone", `{"name": "{{ .name }}", "rules": [], "description": "changed"}`)
+		return 1, nil
+	}
+
+	reviewFile := filepath.Join(folder, "review.json")
+	env := environment.NewEnvironment("prod", "prod", "", "https://prod.example.com", "TOKEN")
+
+	status := reviewDrift(&recordingClient{}, env, folder, "proj", downloadTo, false, reviewFile, nil)
+	assert.Equal(t, status, 1)
+
+	content, err := ioutil.ReadFile(reviewFile)
+	assert.NilError(t, err)
+
+	var review driftReview
+	err = json.Unmarshal(content, &review)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, review, driftReview{Environment: "prod", Project: "proj", Configs: []configDrift{
+		{Api: "management-zone", Name: "Zone", Status: "changed", Config: "proj/management-zone/zone", Differences: []driftDifference{
+			{Path: "description", Environment: `"changed"`},
+		}},
+	}})
+
+	// the project in the repository is left as it is
+	_, err = os.Stat(filepath.Join(folder, "proj", "management-zone", "Zone.json"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestReviewDriftResolvesReferencesToIdsOfTheEnvironment(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-drift-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	writeDriftTestConfig(t, filepath.Join(folder, "proj"), "management-zone", "zone", "Zone", `{"name": "{{ .name }}"}`)
+	writeDriftTestConfig(t, filepath.Join(folder, "proj"), "alerting-profile", "profile", "Profile", `{"displayName": "{{ .name }}", "managementZoneId": "{{ .zoneId }}"}`)
+
+	yaml := "config:\n  - profile: \"profile.json\"\n\nprofile:\n  - name: \"Profile\"\n  - zoneId: \"proj/management-zone/zone.id\"\n"
+	err = ioutil.WriteFile(filepath.Join(folder, "proj", "alerting-profile", "alerting-profile.yaml"), []byte(yaml), 0664)
+	assert.NilError(t, err)
+
+	downloadTo := func(projectFolder string) (int, error) {
+		writeDriftTestConfig(t, projectFolder, "management-zone", "Zone", "Zone", `{"name": "{{ .name }}"}`)
+		writeDriftTestConfig(t, projectFolder, "alerting-profile", "Profile", "Profile", `{"displayName": "{{ .name }}", "managementZoneId": "4711"}`)
+		return 2, nil
+	}
+
+	client := &recordingClient{values: []api.Value{{Id: "4711", Name: "Zone"}}}
+	env := environment.NewEnvironment("prod", "prod", "", "https://prod.example.com", "TOKEN")
+
+	status := reviewDrift(client, env, folder, "proj", downloadTo, false, filepath.Join(folder, "review.json"), nil)
+	assert.Equal(t, status, 0, "the reference of the profile is the id of the zone in the environment")
+}
+
+func TestReviewDriftFailsIfDownloadFails(t *testing.T) {
+
+	downloadTo := func(projectFolder string) (int, error) {
+		return 0, errors.New("unauthorized")
+	}
+
+	env := environment.NewEnvironment("prod", "prod", "", "https://prod.example.com", "TOKEN")
+
+	assert.Equal(t, reviewDrift(&recordingClient{}, env, ".", "proj", downloadTo, false, "review.json", nil), -1)
+}
+
+func writeDriftTestConfig(t *testing.T, projectFolder string, apiId string, id string, name string, template string) {
+
+	apiFolder := filepath.Join(projectFolder, apiId)
+	err := os.MkdirAll(apiFolder, 0777)
+	assert.NilError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(apiFolder, id+".json"), []byte(template), 0664)
+	assert.NilError(t, err)
+
+	yaml := "config:\n  - " + id + ": \"" + id + ".json\"\n\n" + id + ":\n  - name: \"" + name + "\"\n"
+	err = ioutil.WriteFile(filepath.Join(apiFolder, apiId+".yaml"), []byte(yaml), 0664)
+	assert.NilError(t, err)
+}
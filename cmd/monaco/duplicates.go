@@ -0,0 +1,91 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// duplicateNameStrategy defines how a config is deployed if multiple objects with its name already exist
+type duplicateNameStrategy string
+
+const (
+	// updateFirstDuplicate updates the first object listed with the name of the config, like monaco always did
+	updateFirstDuplicate duplicateNameStrategy = "update-first"
+
+	// failOnDuplicateNames fails the deployment of the config
+	failOnDuplicateNames duplicateNameStrategy = "fail"
+
+	// updateAllDuplicates updates every object with the name of the config
+	updateAllDuplicates duplicateNameStrategy = "update-all"
+
+	// matchDuplicatesByIdFromState updates the object the config was deployed to before, as recorded in the state file
+	matchDuplicatesByIdFromState duplicateNameStrategy = "match-by-id-from-state"
+)
+
+func parseDuplicateNameStrategy(value string) (duplicateNameStrategy, error) {
+
+	switch strategy := duplicateNameStrategy(value); strategy {
+	case updateFirstDuplicate, failOnDuplicateNames, updateAllDuplicates, matchDuplicatesByIdFromState:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate name strategy %s, use one of %s, %s, %s or %s", value,
+			updateFirstDuplicate, failOnDuplicateNames, updateAllDuplicates, matchDuplicatesByIdFromState)
+	}
+}
+
+// resolveDuplicates selects the ids of the objects a config is deployed to, if its name is used by several existing
+// objects, according to the given strategy
+func resolveDuplicates(a api.Api, duplicates rest.DuplicateNameError, environment environment.Environment,
+	strategy duplicateNameStrategy, deployState *state.State) ([]string, error) {
+
+	switch strategy {
+	case updateFirstDuplicate:
+		util.Log.Warn("\t\t\t%s, updating %s. Use --duplicate-names=%s to fail instead", duplicates, duplicates.Ids[0], failOnDuplicateNames)
+		return duplicates.Ids[:1], nil
+
+	case updateAllDuplicates:
+		return duplicates.Ids, nil
+
+	case matchDuplicatesByIdFromState:
+		if deployState != nil {
+			id, found := deployState.Get(environment.GetId(), a.GetId(), duplicates.Name)
+			if found && contains(duplicates.Ids, id) {
+				return []string{id}, nil
+			}
+		}
+		return nil, fmt.Errorf("%s, none of them is recorded in the state file", duplicates)
+
+	default:
+		return nil, fmt.Errorf("%s, rename them or choose another strategy with --duplicate-names", duplicates)
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"gotest.tools/assert"
+)
+
+var testDashboardApi = api.NewApi("dashboard", "/api/config/v1/dashboards")
+
+var testDuplicates = rest.DuplicateNameError{Api: "dashboard", Name: "overview", Ids: []string{"1", "2"}}
+
+var testDuplicatesEnvironment = environment.NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV")
+
+func TestResolveDuplicatesUpdatesFirst(t *testing.T) {
+
+	ids, err := resolveDuplicates(testDashboardApi, testDuplicates, testDuplicatesEnvironment, updateFirstDuplicate, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, []string{"1"})
+}
+
+func TestResolveDuplicatesFails(t *testing.T) {
+
+	_, err := resolveDuplicates(testDashboardApi, testDuplicates, testDuplicatesEnvironment, failOnDuplicateNames, nil)
+	assert.ErrorContains(t, err, "2 configs of dashboard are named 'overview'")
+}
+
+func TestResolveDuplicatesUpdatesAll(t *testing.T) {
+
+	ids, err := resolveDuplicates(testDashboardApi, testDuplicates, testDuplicatesEnvironment, updateAllDuplicates, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, []string{"1", "2"})
+}
+
+func TestResolveDuplicatesMatchesIdFromState(t *testing.T) {
+
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "2")
+
+	ids, err := resolveDuplicates(testDashboardApi, testDuplicates, testDuplicatesEnvironment, matchDuplicatesByIdFromState, deployState)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, []string{"2"})
+}
+
+func TestResolveDuplicatesFailsIfStateDoesNotMatch(t *testing.T) {
+
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "3")
+
+	_, err := resolveDuplicates(testDashboardApi, testDuplicates, testDuplicatesEnvironment, matchDuplicatesByIdFromState, deployState)
+	assert.ErrorContains(t, err, "none of them is recorded in the state file")
+}
+
+func TestParseDuplicateNameStrategy(t *testing.T) {
+
+	strategy, err := parseDuplicateNameStrategy("update-all")
+	assert.NilError(t, err)
+	assert.Equal(t, updateAllDuplicates, strategy)
+
+	_, err = parseDuplicateNameStrategy("pick-any")
+	assert.ErrorContains(t, err, "unknown duplicate name strategy pick-any")
+}
@@ -0,0 +1,410 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// configExplanation shows how a single config would be deployed to an environment
+type configExplanation struct {
+	Config     string
+	Variables  []explainedVariable
+	References []explainedReference
+
+	// Remote is the id of the object the config updates, or empty if the object is created
+	Remote string
+
+	// Reads are the requests the deployment reads existing objects with, e.g. GET https://.../managementZones
+	Reads []string
+
+	// Operations are the writes the deployment sends, e.g. PUT https://.../managementZones/1234
+	Operations []string
+	Payload    string
+}
+
+// explainedVariable is the value of a variable of the config and the location it is taken from
+type explainedVariable struct {
+	Name   string
+	Value  string
+	Source string
+}
+
+// explainedReference is a variable referencing another config and the value it resolves to
+type explainedReference struct {
+	Name      string
+	Reference string
+	Value     string
+}
+
+// runExplain executes the explain command, which shows how a single config is deployed to an environment: its
+// variables and their sources, its resolved references, the object it matches and the requests deploying it would send,
+// reads and writes, together with its rendered payload. The configs it depends on are deployed to a dry run client
+// first, so nothing is changed in the environment. Returns 0 on success and -1 on errors
+func runExplain(args []string, fileReader util.FileReader) int {
+
+	explanation, env, ok := explainFromArgs("explain", args, fileReader)
+	if !ok {
+		return -1
+	}
+
+	logExplanation(explanation, env)
+	return 0
+}
+
+// explainFromArgs explains the config given by the arguments of the command, e.g. explain or convert, for the
+// environment given by the flags. The flags selecting the objects configs are deployed to are the ones of deploy
+func explainFromArgs(command string, args []string, fileReader util.FileReader) (configExplanation, environment.Environment, bool) {
+
+	var environmentsFile, specificEnvironment string
+	var verbose bool
+	var flags deployFlags
+
+	flagSet := flag.NewFlagSet(command, flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Mandatory environment (from list) to "+command+" the config for.")
+	addTargetFlags(flagSet, &flags)
+	addClientFlags(flagSet, &flags.clientFlags)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return configExplanation{}, nil, false
+	}
+
+	if flagSet.NArg() == 0 {
+		println("Please provide the coordinates of the config to " + command + ", e.g. project/management-zone/zone!")
+		flagSet.Usage()
+		return configExplanation{}, nil, false
+	}
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return configExplanation{}, nil, false
+	}
+
+	options, err := targetOptions(flags, fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return configExplanation{}, nil, false
+	}
+
+	options.clientOptions, err = flags.apply(options.clientOptions, fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return configExplanation{}, nil, false
+	}
+
+	if flagSet.NArg() > 1 {
+		options.path = readPath(flagSet.Args(), fileReader)
+	}
+
+	projects, err := project.LoadProjectsToDeploy("", createApis(), options.path, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of projects failed: %s", err)
+		return configExplanation{}, nil, false
+	}
+
+	client, err := createClientForEnvironment(map[string]environment.Environment{env.GetId(): env}, env.GetId(), options.clientOptions)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return configExplanation{}, nil, false
+	}
+
+	explanation, err := explainConfig(client, projects, env, flagSet.Arg(0), options)
+	if err != nil {
+		util.Log.Error("Explaining %s failed: %s", flagSet.Arg(0), err)
+		return configExplanation{}, nil, false
+	}
+
+	if !flags.saveNameCache(options.clientOptions) {
+		return configExplanation{}, nil, false
+	}
+
+	return explanation, env, true
+}
+
+// explainConfig deploys the config with the given coordinates and the configs it depends on to a dry run client reading
+// from client, and returns how the config would be deployed. The options select the objects configs are deployed to,
+// like the ones of the deployment do, e.g. the name matching, ownership markers or the state
+func explainConfig(client rest.DynatraceClient, projects []project.Project, env environment.Environment, coordinates string,
+	options executionOptions) (configExplanation, error) {
+
+	path := options.path
+	coordinates = strings.Trim(filepath.ToSlash(coordinates), "/")
+
+	configs := make([]config.Config, 0)
+	for _, p := range projects {
+		configs = append(configs, p.GetConfigs()...)
+	}
+
+	required, err := requiredConfigs(configs, coordinates, path)
+	if err != nil {
+		return configExplanation{}, err
+	}
+
+	target := required[len(required)-1]
+	if target.IsSkipDeployment(env) {
+		return configExplanation{}, fmt.Errorf("%s is not deployed to environment %s", coordinates, env.GetId())
+	}
+
+	prefetchLists(client, required, env)
+
+	reads := &readRecorder{DynatraceClient: client, environment: env}
+	dryRun := rest.NewDryRunClient(reads, options.clientOptions.NameMatching)
+	dict := make(map[string]api.DynatraceEntity)
+
+	for _, c := range required[:len(required)-1] {
+		if c.IsSkipDeployment(env) {
+			continue
+		}
+		entity, err := uploadConfig(dryRun, c, dict, env, options)
+		if err != nil {
+			return configExplanation{}, fmt.Errorf("config %s it depends on can not be deployed: %s", configCoordinates(c, path), err)
+		}
+		dict[configReference(c, path)] = entity
+	}
+
+	explanation := configExplanation{
+		Config:     coordinates,
+		Variables:  make([]explainedVariable, 0),
+		References: make([]explainedReference, 0),
+		Reads:      make([]string, 0),
+		Operations: make([]string, 0),
+	}
+
+	references := make(map[string]bool)
+	for _, reference := range target.GetUnresolvedReferences(map[string]api.DynatraceEntity{}) {
+		references[reference.Value] = true
+	}
+
+	for _, name := range configVariables(target, env) {
+		for _, source := range target.ExplainProperty(env, name) {
+			if !source.Defined {
+				continue
+			}
+			if source.Level == config.ConfigLevel && references[source.Value] {
+				value, err := target.GetPropertyForEnvironment(env, name, dict)
+				if err != nil {
+					return explanation, err
+				}
+				explanation.References = append(explanation.References, explainedReference{Name: name, Reference: source.Value, Value: value})
+			} else {
+				explanation.Variables = append(explanation.Variables, explainedVariable{Name: name, Value: source.Value, Source: source.Level + " " + source.Location})
+			}
+			break
+		}
+	}
+
+	explanation.Payload, err = target.GetConfigForEnvironment(env, dict)
+	if err != nil {
+		return explanation, err
+	}
+
+	before, readBefore := len(dryRun.Operations()), len(reads.recorded())
+	_, err = uploadConfig(dryRun, target, dict, env, options)
+	if err != nil {
+		return explanation, err
+	}
+
+	explanation.Reads = append(explanation.Reads, reads.recorded()[readBefore:]...)
+
+	// the payload actually sent is shown, e.g. without server managed fields
+	for _, operation := range dryRun.Operations()[before:] {
+		explanation.Operations = append(explanation.Operations, describeOperation(target.GetApi(), env, operation))
+		if operation.Type == rest.OperationUpdate && explanation.Remote == "" {
+			explanation.Remote = operation.Id
+		}
+		if len(operation.Payload) > 0 {
+			explanation.Payload = string(operation.Payload)
+		}
+	}
+
+	return explanation, nil
+}
+
+// requiredConfigs returns the configs the config with the given coordinates depends on, directly or indirectly, in the
+// order they are deployed, followed by the config itself
+func requiredConfigs(configs []config.Config, coordinates string, path string) ([]config.Config, error) {
+
+	index := -1
+	for i, c := range configs {
+		if configCoordinates(c, path) == coordinates {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("config %s not found", coordinates)
+	}
+
+	required := []config.Config{configs[index]}
+	for i := index - 1; i >= 0; i-- {
+		for _, dependent := range required {
+			if dependent.HasDependencyOn(configs[i]) {
+				required = append(required, configs[i])
+				break
+			}
+		}
+	}
+
+	// the configs were collected against the deployment order
+	for i, j := 0, len(required)-1; i < j; i, j = i+1, j-1 {
+		required[i], required[j] = required[j], required[i]
+	}
+
+	return required, nil
+}
+
+// configVariables returns the sorted names of the variables the config defines for the environment
+func configVariables(c config.Config, env environment.Environment) []string {
+
+	names := make(map[string]bool)
+	for section, properties := range c.GetProperties() {
+		if section != c.GetId() && section != c.GetId()+"."+env.GetGroup() && section != c.GetId()+"."+env.GetId() {
+			continue
+		}
+		for key := range properties {
+			key = strings.TrimSuffix(key, ".group."+env.GetGroup())
+			key = strings.TrimSuffix(key, "."+env.GetId())
+			names[key] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// readRecorder passes reads to the client and records the requests they are sent with, e.g. GET https://.../managementZones.
+// Every request is recorded once, as the client lists every api only once
+type readRecorder struct {
+	rest.DynatraceClient
+	environment environment.Environment
+
+	lock  sync.Mutex
+	reads []string
+	seen  map[string]bool
+}
+
+func (r *readRecorder) List(a api.Api) ([]api.Value, error) {
+	r.record("GET " + a.GetUrl(r.environment))
+	return r.DynatraceClient.List(a)
+}
+
+func (r *readRecorder) ReadById(a api.Api, id string) ([]byte, error) {
+	r.record("GET " + a.GetUrl(r.environment) + "/" + id)
+	return r.DynatraceClient.ReadById(a, id)
+}
+
+func (r *readRecorder) ExistsByName(a api.Api, name string) (bool, string, error) {
+	r.record("GET " + a.GetUrl(r.environment))
+	return r.DynatraceClient.ExistsByName(a, name)
+}
+
+func (r *readRecorder) record(read string) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.seen == nil {
+		r.seen = make(map[string]bool)
+	}
+	if !r.seen[read] {
+		r.seen[read] = true
+		r.reads = append(r.reads, read)
+	}
+}
+
+// recorded returns the recorded reads in the order they were sent
+func (r *readRecorder) recorded() []string {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]string{}, r.reads...)
+}
+
+// describeOperation returns the request sending the write, e.g. PUT https://.../managementZones/1234
+func describeOperation(a api.Api, env environment.Environment, operation rest.Operation) string {
+
+	url := a.GetUrl(env)
+
+	switch operation.Type {
+	case rest.OperationCreate:
+		return fmt.Sprintf("POST %s (%s)", url, operation.Name)
+	case rest.OperationDelete:
+		return fmt.Sprintf("DELETE %s/%s (%s)", url, operation.Id, operation.Name)
+	default:
+		return fmt.Sprintf("PUT %s/%s (%s)", url, operation.Id, operation.Name)
+	}
+}
+
+func logExplanation(explanation configExplanation, env environment.Environment) {
+
+	util.Log.Info("%s in environment %s:", explanation.Config, env.GetId())
+
+	util.Log.Info("\tVariables:")
+	for _, variable := range explanation.Variables {
+		util.Log.Info("\t\t%s: %q (%s)", variable.Name, variable.Value, variable.Source)
+	}
+
+	if len(explanation.References) > 0 {
+		util.Log.Info("\tReferences:")
+		for _, reference := range explanation.References {
+			util.Log.Info("\t\t%s: %s resolves to %q", reference.Name, reference.Reference, reference.Value)
+		}
+	}
+
+	if explanation.Remote != "" {
+		util.Log.Info("\tMatched object: %s", explanation.Remote)
+	} else {
+		util.Log.Info("\tMatched object: none, the object is created")
+	}
+
+	util.Log.Info("\tReads:")
+	if len(explanation.Reads) == 0 {
+		util.Log.Info("\t\tnone")
+	}
+	for _, read := range explanation.Reads {
+		util.Log.Info("\t\t%s", read)
+	}
+
+	util.Log.Info("\tRequests:")
+	if len(explanation.Operations) == 0 {
+		util.Log.Info("\t\tnone")
+	}
+	for _, operation := range explanation.Operations {
+		util.Log.Info("\t\t%s", operation)
+	}
+
+	util.Log.Info("\tPayload:\n%s", explanation.Payload)
+}
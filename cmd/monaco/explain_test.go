@@ -0,0 +1,108 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func writeExplainProject(t *testing.T, folder string) {
+
+	files := map[string]string{
+		"explain/management-zone/zone.json":     `{"name": "{{ .name }}", "rules": []}`,
+		"explain/management-zone/zone.yaml":     "config:\n  - zone: \"zone.json\"\n\nzone:\n  - name: \"zone\"\n",
+		"explain/alerting-profile/profile.json": `{"displayName": "{{ .name }}", "managementZoneId": "{{ .zoneId }}", "rules": []}`,
+		"explain/alerting-profile/profile.yaml": "config:\n  - profile: \"profile.json\"\n\nprofile:\n  - name: \"profile\"\n  - zoneId: \"explain/management-zone/zone.id\"\n\nprofile.explaining:\n  - name: \"explained profile\"\n",
+	}
+
+	for file, content := range files {
+		path := filepath.Join(folder, filepath.FromSlash(file))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0777))
+		assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0664))
+	}
+}
+
+func TestExplainConfigResolvesReferencesWithoutDeploying(t *testing.T) {
+
+	apis := api.NewApis()
+	explainApis := map[string]api.Api{"management-zone": apis["management-zone"], "alerting-profile": apis["alerting-profile"]}
+
+	folder, err := ioutil.TempDir(".", "monaco-explain-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+	writeExplainProject(t, folder)
+
+	path := filepath.Clean(folder) + string(os.PathSeparator)
+	projects, err := project.LoadProjectsToDeploy("explain", explainApis, path, util.NewFileReader())
+	assert.NilError(t, err)
+
+	server := fake.NewServer(explainApis, 0)
+	defer server.Close()
+
+	assert.NilError(t, os.Setenv(benchTokenEnv, "explain"))
+	env := environment.NewEnvironment("explaining", "explaining", "", server.URL, benchTokenEnv)
+
+	client, err := createClient(env, rest.DefaultClientOptions())
+	assert.NilError(t, err)
+	zone, err := client.UpsertByName(apis["management-zone"], "zone", []byte(`{"name": "zone", "rules": []}`))
+	assert.NilError(t, err)
+
+	explanation, err := explainConfig(client, projects, env, "explain/alerting-profile/profile", executionOptions{path: path})
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, explanation.Variables, []explainedVariable{{Name: "name", Value: "explained profile", Source: "config profile.explaining: name"}})
+	assert.DeepEqual(t, explanation.References, []explainedReference{{Name: "zoneId", Reference: "explain/management-zone/zone.id", Value: zone.Id}})
+	assert.Equal(t, explanation.Remote, "")
+	assert.DeepEqual(t, explanation.Reads, []string{"GET " + server.URL + "/api/config/v1/alertingProfiles"})
+	assert.DeepEqual(t, explanation.Operations, []string{"POST " + server.URL + "/api/config/v1/alertingProfiles (explained profile)"})
+	assert.Assert(t, strings.Contains(explanation.Payload, zone.Id), explanation.Payload)
+
+	assert.Equal(t, server.Objects("management-zone"), 1)
+	assert.Equal(t, server.Objects("alerting-profile"), 0)
+
+	explanation, err = explainConfig(client, projects, env, "explain/management-zone/zone", executionOptions{path: path})
+	assert.NilError(t, err)
+	assert.Equal(t, explanation.Remote, zone.Id)
+	assert.DeepEqual(t, explanation.Operations, []string{"PUT " + server.URL + "/api/config/v1/managementZones/" + zone.Id + " (zone)"})
+
+	other, err := client.UpsertByName(apis["management-zone"], "other", []byte(`{"name": "other", "rules": []}`))
+	assert.NilError(t, err)
+	recorded := state.NewState()
+	recorded.Set(env.GetId(), "management-zone", "zone", other.Id)
+
+	explanation, err = explainConfig(client, projects, env, "explain/management-zone/zone", executionOptions{path: path, state: recorded})
+	assert.NilError(t, err)
+	assert.Equal(t, explanation.Remote, other.Id)
+
+	_, err = explainConfig(client, projects, env, "explain/management-zone/missing", executionOptions{path: path})
+	assert.ErrorContains(t, err, "config explain/management-zone/missing not found")
+}
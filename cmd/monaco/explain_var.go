@@ -0,0 +1,131 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// variableExplanation lists the locations a variable of a config is looked up at, and which one provides the value
+type variableExplanation struct {
+	Config  string
+	Sources []config.PropertySource
+}
+
+// runExplainVar executes the explain-var command, which shows where the value of a variable of the configs comes
+// from for an environment. Returns 0 on success and -1 on errors
+func runExplainVar(args []string, fileReader util.FileReader) int {
+
+	var environmentsFile, specificEnvironment, projectFlag, variable, configFlag string
+	var verbose bool
+
+	flagSet := flag.NewFlagSet("explain-var", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Mandatory environment (from list) to resolve the variable for.")
+
+	projectUsage := "Project whose configs are explained, together with the projects it depends on. Defaults to all projects."
+	flagSet.StringVar(&projectFlag, "project", "", projectUsage)
+	flagSet.StringVar(&projectFlag, "p", "", projectUsage+" (shorthand)")
+
+	flagSet.StringVar(&variable, "var", "", "Mandatory name of the variable to explain.")
+	flagSet.StringVar(&configFlag, "config", "", "Only explain the config with the given coordinates, e.g. project/management-zone/zone. Defaults to all configs.")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return -1
+	}
+
+	if variable == "" {
+		println("Please provide the variable to explain with --var!")
+		flagSet.Usage()
+		return -1
+	}
+
+	path := readPath(flagSet.Args(), fileReader)
+	projects, err := project.LoadProjectsToDeploy(projectFlag, createApis(), path, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of projects failed: %s", err)
+		return -1
+	}
+
+	explanations := explainVariable(projects, env, variable, configFlag, path)
+	if len(explanations) == 0 {
+		util.Log.Warn("No config defines %s for environment %s", variable, env.GetId())
+		return 0
+	}
+
+	for _, explanation := range explanations {
+		util.Log.Info("%s:", explanation.Config)
+		used := false
+		for _, source := range explanation.Sources {
+			switch {
+			case !source.Defined:
+				util.Log.Info("\t%-8s %s: not defined", source.Level, source.Location)
+			case !used:
+				used = true
+				util.Log.Info("\t%-8s %s: %q (used)", source.Level, source.Location, source.Value)
+			default:
+				util.Log.Info("\t%-8s %s: %q (overridden)", source.Level, source.Location, source.Value)
+			}
+		}
+	}
+	return 0
+}
+
+// explainVariable returns the lookup chain of the variable for every config, which defines it at any level. Configs
+// are identified by their coordinates relative to the projects folder, configFilter restricts them to a single one
+func explainVariable(projects []project.Project, env environment.Environment, variable string, configFilter string, path string) []variableExplanation {
+
+	explanations := make([]variableExplanation, 0)
+
+	for _, p := range projects {
+		for _, c := range p.GetConfigs() {
+			coordinates := configCoordinates(c, path)
+			if configFilter != "" && coordinates != strings.Trim(filepath.ToSlash(configFilter), "/") {
+				continue
+			}
+
+			sources := c.ExplainProperty(env, variable)
+			for _, source := range sources {
+				if source.Defined {
+					explanations = append(explanations, variableExplanation{Config: coordinates, Sources: sources})
+					break
+				}
+			}
+		}
+	}
+
+	return explanations
+}
+
+// configCoordinates returns the full qualified id of the config relative to the projects folder, separated by slashes
+func configCoordinates(c config.Config, path string) string {
+	id := strings.TrimPrefix(c.GetFullQualifiedId(), filepath.Clean(path))
+	return strings.Trim(filepath.ToSlash(id), "/")
+}
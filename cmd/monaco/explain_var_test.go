@@ -0,0 +1,89 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func writeExplainVarProject(t *testing.T) string {
+
+	folder, err := ioutil.TempDir(".", "monaco-explain-var-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	zones := filepath.Join(folder, "team", "management-zone")
+	assert.NilError(t, os.MkdirAll(zones, 0755))
+
+	files := map[string]string{
+		filepath.Join(folder, "defaults.yaml"):        "global:\n  hostGroup: \"HOST_GROUP-GLOBAL\"\n",
+		filepath.Join(folder, "team", "project.yaml"): "defaults:\n  contact: \"team\"\n",
+		filepath.Join(zones, "zone.json"):             `{"name": "{{ .name }}", "hostGroup": "{{ .hostGroup }}", "tier": "{{ .tier | default "gold" }}"}`,
+		filepath.Join(zones, "zone.yaml"):             "config:\n  - zone-a: \"zone.json\"\n  - zone-b: \"zone.json\"\n\nzone-a:\n  - name: \"Zone A\"\n  - hostGroup: \"HOST_GROUP-A\"\n\nzone-b:\n  - name: \"Zone B\"\n",
+	}
+	for name, content := range files {
+		assert.NilError(t, ioutil.WriteFile(name, []byte(content), 0644))
+	}
+
+	return folder
+}
+
+func TestExplainVariableShowsTheSourceOfTheValue(t *testing.T) {
+
+	folder := writeExplainVarProject(t)
+
+	projects, err := project.LoadProjectsToDeploy("team", createApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("dev", "dev", "development", "https://url/to/dev/environment", "DEV")
+
+	explanations := explainVariable(projects, env, "hostGroup", "", folder)
+	assert.Equal(t, len(explanations), 2)
+
+	used := make(map[string]config.PropertySource)
+	for _, explanation := range explanations {
+		for _, source := range explanation.Sources {
+			if source.Defined {
+				used[explanation.Config] = source
+				break
+			}
+		}
+	}
+	assert.Equal(t, used["team/management-zone/zone-a"].Level, config.ConfigLevel)
+	assert.Equal(t, used["team/management-zone/zone-a"].Value, "HOST_GROUP-A")
+	assert.Equal(t, used["team/management-zone/zone-b"].Level, config.GlobalLevel)
+	assert.Equal(t, used["team/management-zone/zone-b"].Value, "HOST_GROUP-GLOBAL")
+
+	explanations = explainVariable(projects, env, "tier", "team/management-zone/zone-b", folder)
+	assert.Equal(t, len(explanations), 1)
+	last := explanations[0].Sources[len(explanations[0].Sources)-1]
+	assert.Equal(t, last.Level, config.TemplateLevel)
+	assert.Equal(t, last.Value, "gold")
+
+	assert.Equal(t, len(explainVariable(projects, env, "undefined", "", folder)), 0)
+}
@@ -0,0 +1,71 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+
+// extensionApi is the id of the api classic extensions are uploaded to
+const extensionApi = "extension"
+
+// extensionPayload builds the archive of an extension from its rendered plugin.json and the files located in
+// the folder named like the template without the .json extension, e.g. custom.python.demo/ next to
+// custom.python.demo.json. Extensions without such a folder are uploaded with their plugin.json only
+func extensionPayload(templatePath string, pluginJson string) ([]byte, error) {
+
+	folder := strings.TrimSuffix(templatePath, filepath.Ext(templatePath))
+
+	info, err := os.Stat(folder)
+	if os.IsNotExist(err) || (err == nil && !info.IsDir()) {
+		return []byte(pluginJson), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string][]byte)
+
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relative, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(relative)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rest.ExtensionArchive(pluginJson, files)
+}
@@ -0,0 +1,58 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestExtensionPayloadWithoutFilesIsPluginJson(t *testing.T) {
+
+	template := filepath.Join(t.TempDir(), "custom.python.demo.json")
+
+	payload, err := extensionPayload(template, `{"name": "custom.python.demo"}`)
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"name": "custom.python.demo"}`)
+}
+
+func TestExtensionPayloadContainsFilesOfExtensionFolder(t *testing.T) {
+
+	folder := t.TempDir()
+	template := filepath.Join(folder, "custom.python.demo.json")
+
+	err := os.MkdirAll(filepath.Join(folder, "custom.python.demo", "lib"), 0777)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(filepath.Join(folder, "custom.python.demo", "lib", "demo.py"), []byte("print()"), 0664)
+	assert.NilError(t, err)
+
+	payload, err := extensionPayload(template, `{"name": "custom.python.demo", "version": "1.0"}`)
+	assert.NilError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(payload), int64(len(payload)))
+	assert.NilError(t, err)
+	assert.Equal(t, len(reader.File), 2)
+	assert.Equal(t, reader.File[1].Name, "custom.python.demo/lib/demo.py")
+}
@@ -0,0 +1,48 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+)
+
+// Feature flags of environments, which enable experimental code paths of monaco for the environment in addition to
+// the configs gated by them. Command line flags enable the code paths for all environments
+const (
+	// successorsFeatureFlag deploys configs of classic apis to the Settings 2.0 successors the environment offers,
+	// like --successors=auto
+	successorsFeatureFlag = "successors"
+
+	// validateOnServerFeatureFlag lets the environment validate the rendered settings configs during dry runs,
+	// like --validate-on-server
+	validateOnServerFeatureFlag = "validate-on-server"
+)
+
+// withFeatureFlags returns the options with the experimental code paths enabled, which the environment enables by
+// feature flags. A successor mode other than classic given on the command line is kept
+func withFeatureFlags(options executionOptions, environment environment.Environment) executionOptions {
+
+	if environment.HasFeatureFlag(successorsFeatureFlag) && (options.successors == "" || options.successors == deployClassic) {
+		options.successors = deploySuccessorIfOffered
+	}
+
+	if environment.HasFeatureFlag(validateOnServerFeatureFlag) {
+		options.validateOnServer = true
+	}
+
+	return options
+}
@@ -0,0 +1,59 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func testEnvironmentWithFeatureFlags(t *testing.T, flags string) environment.Environment {
+
+	environments, errs := environment.NewEnvironments(map[string]map[string]string{
+		"dev": {"name": "Dev", "env-url": "https://dev.example.com", "env-token-name": "DEV_TOKEN", "featureFlags": flags},
+	})
+	assert.Equal(t, 0, len(errs))
+
+	return environments["dev"]
+}
+
+func TestFeatureFlagsEnableExperimentalCodePaths(t *testing.T) {
+
+	options := withFeatureFlags(executionOptions{successors: deployClassic}, testEnvironmentWithFeatureFlags(t, "successors, validate-on-server"))
+
+	assert.Equal(t, deploySuccessorIfOffered, options.successors)
+	assert.Equal(t, true, options.validateOnServer)
+}
+
+func TestFeatureFlagsKeepOptionsOfOtherEnvironments(t *testing.T) {
+
+	options := withFeatureFlags(executionOptions{successors: deployClassic}, testEnvironmentWithFeatureFlags(t, "settings-preview"))
+
+	assert.Equal(t, deployClassic, options.successors)
+	assert.Equal(t, false, options.validateOnServer)
+}
+
+func TestFeatureFlagsKeepSuccessorModeOfCommandLine(t *testing.T) {
+
+	options := withFeatureFlags(executionOptions{successors: deploySuccessor}, testEnvironmentWithFeatureFlags(t, "successors"))
+
+	assert.Equal(t, deploySuccessor, options.successors)
+}
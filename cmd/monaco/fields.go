@@ -0,0 +1,61 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// fieldOverridesUsage describes the flag of all commands reading or writing payloads which adds fields to the
+// server managed and diff ignored fields of the apis
+const fieldOverridesUsage = "Yaml file adding fields of apis which are server managed, i.e. stripped before upload and download, or ignored on diff."
+
+// stripServerManagedFields removes the fields the server generates or manages from the payload, e.g. as a template
+// was copied from an api response. The payload is returned unchanged if it contains none of them
+func stripServerManagedFields(apiId string, payload string, fields api.FieldOverrides) (string, int, error) {
+
+	var content interface{}
+
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&content)
+	if err != nil {
+		return payload, 0, err
+	}
+
+	removed := api.RemoveFields(content, fields.ServerManagedFields(apiId))
+	if removed == 0 {
+		return payload, 0, nil
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(content)
+	if err != nil {
+		return payload, 0, err
+	}
+
+	return buffer.String(), removed, nil
+}
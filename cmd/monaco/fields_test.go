@@ -0,0 +1,55 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestStripServerManagedFieldsKeepsPayloadWithoutThem(t *testing.T) {
+
+	payload := `{"name": "zone",   "value": 12345678901234567890}`
+
+	stripped, removed, err := stripServerManagedFields("management-zone", payload, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, payload, stripped)
+}
+
+func TestStripServerManagedFieldsRemovesNestedFields(t *testing.T) {
+
+	payload := `{"id": "1", "metadata": {"clusterVersion": "1.200"}, "name": "<zone>", "value": 12345678901234567890, "rules": [{"id": "2"}]}`
+
+	stripped, removed, err := stripServerManagedFields("management-zone", payload, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 3, removed)
+	assert.Equal(t, "{\n  \"name\": \"<zone>\",\n  \"rules\": [\n    {}\n  ],\n  \"value\": 12345678901234567890\n}\n", stripped)
+}
+
+func TestStripServerManagedFieldsKeepsSettingsValues(t *testing.T) {
+
+	payload := `{"id": "my-rule", "enabled": true}`
+
+	stripped, removed, err := stripServerManagedFields(settingsApi, payload, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, removed)
+	assert.Equal(t, payload, stripped)
+}
@@ -0,0 +1,49 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/freeze"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// checkFreeze blocks deployments to environments frozen by a freeze window, unless the freeze is overridden.
+// Validations are never blocked, they only warn about the freeze
+func checkFreeze(calendar *freeze.Calendar, environment environment.Environment, dryRun bool, override bool, now time.Time) error {
+
+	active, frozen := calendar.ActiveFreeze(environment, now)
+	if !frozen {
+		return nil
+	}
+
+	until := active.Until.UTC().Format(time.RFC3339)
+
+	switch {
+	case dryRun:
+		util.Log.Warn("Environment %s is frozen by %s until %s, a deployment would be blocked", environment.GetId(), active.Window, until)
+	case override:
+		util.Log.Warn("Environment %s is frozen by %s until %s, deploying anyway as --override-freeze is set", environment.GetId(), active.Window, until)
+	default:
+		return fmt.Errorf("environment is frozen by %s until %s, pass --override-freeze to deploy anyway", active.Window, until)
+	}
+
+	return nil
+}
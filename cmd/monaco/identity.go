@@ -0,0 +1,216 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// deployTarget holds the existing objects a config is deployed to. It is resolved once per config, so merging the
+// payload and uploading it use the same objects
+type deployTarget struct {
+	// ids of the existing objects the payload is deployed to, empty if a new object is created
+	ids []string
+
+	// createId is the id of the new object, if the identity strategy derives it. Otherwise, the environment assigns it
+	createId string
+
+	// renamedFrom is the old name of the object, if the config was renamed
+	renamedFrom string
+}
+
+func (t deployTarget) exists() bool {
+	return len(t.ids) > 0
+}
+
+// resolveTarget finds the existing objects of a config. In this order, it selects the object carrying the ownership
+// marker of the config, the object recorded for the name in the state, the object with the old name of a renamed config,
+// or the objects found by the identity strategy of the api. coordinates uniquely identify the config
+// (project/api/config id) and are the seed of markers and derived ids
+func resolveTarget(client rest.DynatraceClient, config config.Config, name string, coordinates string, environment environment.Environment,
+	dict map[string]api.DynatraceEntity, options executionOptions) (deployTarget, error) {
+
+	a := config.GetApi()
+
+	if options.ownershipMarkers && a.IsMarkable() {
+		target, err := targetByOwnershipMarker(client, a, name, coordinates)
+		if err != nil || target.exists() {
+			return target, err
+		}
+	}
+
+	target, err := targetByState(client, a, name, environment, options.state)
+	if err != nil || target.exists() {
+		return target, err
+	}
+
+	target, err = targetByOldName(client, config, name, environment, dict, options.state)
+	if err != nil || target.exists() {
+		return target, err
+	}
+
+	switch a.GetIdentityStrategy() {
+	case api.IdentityById:
+		return targetById(client, a, name, coordinates, environment, options.duplicateNames, options.state)
+	case api.IdentityByExternalId:
+		return targetByExternalId(client, a, name, coordinates, environment, options.duplicateNames, options.state)
+	default:
+		return targetByName(client, a, name, environment, options.duplicateNames, options.state)
+	}
+}
+
+// targetByState selects the object recorded for the name in the state, if it still exists
+func targetByState(client rest.DynatraceClient, a api.Api, name string, environment environment.Environment, deployState *state.State) (deployTarget, error) {
+
+	if deployState == nil {
+		return deployTarget{}, nil
+	}
+
+	id, found := deployState.Get(environment.GetId(), a.GetId(), name)
+	if !found {
+		return deployTarget{}, nil
+	}
+
+	values, err := client.List(a)
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	for _, value := range values {
+		if value.Id == id {
+			return deployTarget{ids: []string{id}}, nil
+		}
+	}
+	return deployTarget{}, nil
+}
+
+// targetById selects the object with the id derived from the coordinates. Objects created by name before are
+// adopted. Otherwise, the object is created with the derived id
+func targetById(client rest.DynatraceClient, a api.Api, name string, coordinates string, environment environment.Environment,
+	strategy duplicateNameStrategy, deployState *state.State) (deployTarget, error) {
+
+	values, err := client.List(a)
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	derivedId := util.DeterministicUuid(coordinates)
+
+	for _, value := range values {
+		if value.Id == derivedId {
+			return deployTarget{ids: []string{derivedId}}, nil
+		}
+	}
+
+	target, err := targetByName(client, a, name, environment, strategy, deployState)
+	if err == nil && !target.exists() {
+		target.createId = derivedId
+	}
+	return target, err
+}
+
+// targetByExternalId selects the object carrying the external id derived from the coordinates. Falls back to the
+// name, if no such object exists yet
+func targetByExternalId(client rest.DynatraceClient, a api.Api, name string, coordinates string, environment environment.Environment,
+	strategy duplicateNameStrategy, deployState *state.State) (deployTarget, error) {
+
+	values, err := client.List(a)
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	externalId := util.DeterministicUuid(coordinates)
+
+	for _, value := range values {
+		if value.ExternalId == externalId {
+			return deployTarget{ids: []string{value.Id}}, nil
+		}
+	}
+
+	return targetByName(client, a, name, environment, strategy, deployState)
+}
+
+func targetByName(client rest.DynatraceClient, a api.Api, name string, environment environment.Environment,
+	strategy duplicateNameStrategy, deployState *state.State) (deployTarget, error) {
+
+	exists, id, err := client.ExistsByName(a, name)
+
+	var duplicates rest.DuplicateNameError
+	if errors.As(err, &duplicates) {
+		ids, err := resolveDuplicates(a, duplicates, environment, strategy, deployState)
+		return deployTarget{ids: ids}, err
+	}
+	if err != nil || !exists {
+		return deployTarget{}, err
+	}
+
+	return deployTarget{ids: []string{id}}, nil
+}
+
+// deployToTarget uploads the payload to all objects of the target, or creates a new object if the target does not
+// exist yet. The entity of the first object is returned
+func deployToTarget(client rest.DynatraceClient, a api.Api, name string, target deployTarget, payload []byte) (entity api.DynatraceEntity, err error) {
+
+	if !target.exists() {
+		if target.createId != "" {
+			return client.UpsertById(a, target.createId, name, payload)
+		}
+		return client.UpsertByName(a, name, payload)
+	}
+
+	for i, id := range target.ids {
+		updated, err := client.UpsertById(a, id, name, payload)
+		if err != nil {
+			return entity, err
+		}
+		if i == 0 {
+			entity = updated
+		}
+	}
+	return entity, nil
+}
+
+// withIdentity stores the external id derived from the coordinates in the payload, if the api identifies its
+// objects by external id
+func withIdentity(a api.Api, coordinates string, payload []byte) ([]byte, error) {
+
+	if a.GetIdentityStrategy() != api.IdentityByExternalId {
+		return payload, nil
+	}
+	return withExternalId(payload, util.DeterministicUuid(coordinates))
+}
+
+func withExternalId(payload []byte, externalId string) ([]byte, error) {
+
+	var content map[string]interface{}
+	err := json.Unmarshal(payload, &content)
+	if err != nil {
+		return nil, fmt.Errorf("external id can not be set: %s", err)
+	}
+
+	content["externalId"] = externalId
+	return json.Marshal(content)
+}
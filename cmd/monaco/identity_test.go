@@ -0,0 +1,178 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+// recordingClient serves the given values and records all writes as "<operation> <id or name>"
+type recordingClient struct {
+	rest.DynatraceClient
+	values   []api.Value
+	writes   []string
+	payloads [][]byte
+	reads    []string
+}
+
+func (c *recordingClient) List(a api.Api) ([]api.Value, error) {
+	return c.values, nil
+}
+
+func (c *recordingClient) ExistsByName(a api.Api, name string) (bool, string, error) {
+	return rest.FindByName(a, c.values, name, rest.NameMatching{})
+}
+
+func (c *recordingClient) ReadById(a api.Api, id string) ([]byte, error) {
+	c.reads = append(c.reads, id)
+	return []byte(`{"id": "` + id + `", "kept": true}`), nil
+}
+
+func (c *recordingClient) UpsertByName(a api.Api, name string, payload []byte) (api.DynatraceEntity, error) {
+	c.writes = append(c.writes, "upsert-by-name "+name)
+	c.payloads = append(c.payloads, payload)
+	return api.DynatraceEntity{Name: name}, nil
+}
+
+func (c *recordingClient) UpsertById(a api.Api, id string, name string, payload []byte) (api.DynatraceEntity, error) {
+	c.writes = append(c.writes, "upsert-by-id "+id)
+	c.payloads = append(c.payloads, payload)
+	return api.DynatraceEntity{Id: id, Name: name}, nil
+}
+
+func (c *recordingClient) DeleteByName(a api.Api, name string) error {
+	c.writes = append(c.writes, "delete-by-name "+name)
+	return nil
+}
+
+func (c *recordingClient) DeleteById(a api.Api, id string) error {
+	c.writes = append(c.writes, "delete-by-id "+id)
+	return nil
+}
+
+const testCoordinates = "project/dashboard/overview"
+
+func TestTargetByIdCreatesObjectWithDerivedId(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "other", Name: "other"}}}
+
+	target, err := resolveTarget(client, testConfig(testDashboardApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames})
+	assert.NilError(t, err)
+	assert.Assert(t, !target.exists())
+	assert.Equal(t, target.createId, util.DeterministicUuid(testCoordinates))
+
+	_, err = deployToTarget(client, testDashboardApi, "overview", target, []byte("{}"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, client.writes, []string{"upsert-by-id " + util.DeterministicUuid(testCoordinates)})
+}
+
+func TestTargetByIdIsObjectWithDerivedIdDespiteDuplicateNames(t *testing.T) {
+
+	derivedId := util.DeterministicUuid(testCoordinates)
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}, {Id: derivedId, Name: "overview"}}}
+
+	target, err := resolveTarget(client, testConfig(testDashboardApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{derivedId})
+}
+
+func TestTargetPrefersIdFromState(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}, {Id: util.DeterministicUuid(testCoordinates), Name: "overview"}}}
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "1")
+
+	target, err := resolveTarget(client, testConfig(testDashboardApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames, state: deployState})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{"1"})
+}
+
+func TestTargetByIdAdoptsObjectCreatedByName(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}}}
+
+	target, err := resolveTarget(client, testConfig(testDashboardApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{"1"})
+}
+
+func TestTargetByNameResolvesDuplicates(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}, {Id: "2", Name: "overview"}}}
+	nameApi := &identityTestApi{Api: testDashboardApi, strategy: api.IdentityByName}
+
+	_, err := resolveTarget(client, testConfig(nameApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames})
+	assert.ErrorContains(t, err, "2 configs of dashboard are named 'overview'")
+
+	target, err := resolveTarget(client, testConfig(nameApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: updateAllDuplicates})
+	assert.NilError(t, err)
+
+	_, err = deployToTarget(client, nameApi, "overview", target, []byte("{}"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, client.writes, []string{"upsert-by-id 1", "upsert-by-id 2"})
+}
+
+func TestTargetPrefersIdFromStateForNamedObjects(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}, {Id: "2", Name: "overview"}}}
+	nameApi := &identityTestApi{Api: testDashboardApi, strategy: api.IdentityByName}
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "2")
+
+	target, err := resolveTarget(client, testConfig(nameApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames, state: deployState})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{"2"})
+}
+
+func TestTargetByExternalIdIsObjectWithExternalId(t *testing.T) {
+
+	externalId := util.DeterministicUuid(testCoordinates)
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "renamed", ExternalId: externalId}}}
+	externalIdApi := &identityTestApi{Api: testDashboardApi, strategy: api.IdentityByExternalId}
+
+	target, err := resolveTarget(client, testConfig(externalIdApi), "overview", testCoordinates, testDuplicatesEnvironment, nil, executionOptions{duplicateNames: failOnDuplicateNames})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{"1"})
+
+	payload, err := withIdentity(externalIdApi, testCoordinates, []byte(`{"name": "overview"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"externalId":"`+externalId+`","name":"overview"}`)
+}
+
+// testConfig returns the config overview of the given api
+func testConfig(a api.Api) config.Config {
+	return config.GetMockConfig("overview", "project", nil, map[string]map[string]string{"overview": {"name": "overview"}}, a, "overview.json")
+}
+
+// identityTestApi overrides the identity strategy of an api
+type identityTestApi struct {
+	api.Api
+	strategy api.IdentityStrategy
+}
+
+func (a *identityTestApi) GetIdentityStrategy() api.IdentityStrategy {
+	return a.strategy
+}
@@ -0,0 +1,122 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/export"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/terraform"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+const (
+	importFormatExport         = "export"
+	importFormatTerraformState = "terraform-state"
+)
+
+// runImport executes the import command, which converts a Dynatrace configuration export archive or a Terraform
+// state file into a monaco project. Returns 0 on success and -1 on errors
+func runImport(args []string, fileReader util.FileReader) int {
+
+	var projectName, outputFolder, format string
+	var verbose, caseInsensitiveNames bool
+
+	shorthand := " (shorthand)"
+
+	flagSet := flag.NewFlagSet("import", flag.ExitOnError)
+
+	projectUsage := "Mandatory name of the project the imported configs are written to."
+	flagSet.StringVar(&projectName, "project", "", projectUsage)
+	flagSet.StringVar(&projectName, "p", "", projectUsage+shorthand)
+
+	outputFolderUsage := "Folder the project is written to. Defaults to the current working dir."
+	flagSet.StringVar(&outputFolder, "output-folder", ".", outputFolderUsage)
+
+	formatUsage := "Format of the file to import, either " + importFormatExport + " (configuration export archive) or " + importFormatTerraformState + " (state file of the Dynatrace Terraform provider)."
+	flagSet.StringVar(&format, "format", importFormatExport, formatUsage)
+
+	addNameMatchingFlag(flagSet, &caseInsensitiveNames)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+shorthand)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if projectName == "" || flagSet.NArg() != 1 {
+		println("Please provide the project name with -p/--project and the file to import!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+	file := flagSet.Arg(0)
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	apis := createApis()
+	matching := rest.NameMatching{CaseInsensitive: caseInsensitiveNames}
+
+	var client rest.DynatraceClient
+
+	switch format {
+	case importFormatExport:
+		client, err = export.NewArchiveClient(file, apis, matching)
+	case importFormatTerraformState:
+		client, err = createTerraformStateClient(file, matching, fileReader)
+	default:
+		util.Log.Error("Unknown import format %s", format)
+		return -1
+	}
+
+	if err != nil {
+		util.Log.Error("Reading %s failed: %s", file, err)
+		return -1
+	}
+
+	projectFolder := filepath.Join(outputFolder, projectName)
+	util.Log.Info("Importing %s into project %s...", file, projectFolder)
+
+	count, err := download.DownloadConfigs(apis, client, projectFolder, download.Options{})
+	if err != nil {
+		util.Log.Error("Import of %s failed: %s", file, err)
+		return -1
+	}
+
+	util.Log.Info("Imported %d configs into %s", count, projectFolder)
+	return 0
+}
+
+func createTerraformStateClient(file string, matching rest.NameMatching, fileReader util.FileReader) (rest.DynatraceClient, error) {
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return terraform.NewStateClient(content, matching)
+}
@@ -0,0 +1,95 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"path/filepath"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/bootstrap"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// runInit executes the init command, which writes a project of a bootstrap profile into the projects folder, giving
+// new environments a default configuration to start from. Returns 0 on success and -1 on errors
+func runInit(args []string) int {
+
+	var profileFlag, projectFlag string
+	var list, verbose bool
+
+	flagSet := flag.NewFlagSet("init", flag.ExitOnError)
+
+	flagSet.StringVar(&profileFlag, "profile", "", "Mandatory profile the project is created from, e.g. baseline-alerts or k8s.")
+	flagSet.StringVar(&projectFlag, "project", "", "Name of the created project. Defaults to the name of the profile.")
+	flagSet.BoolVar(&list, "list", false, "List the available profiles.")
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+" (shorthand)")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	if list {
+		for _, p := range bootstrap.Profiles() {
+			util.Log.Info("%-20s %s", p.Name, p.Description)
+		}
+		return 0
+	}
+
+	if profileFlag == "" {
+		println("Please provide the profile to create the project from with --profile!")
+		flagSet.Usage()
+		return -1
+	}
+
+	profile, err := bootstrap.GetProfile(profileFlag)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	if projectFlag == "" {
+		projectFlag = profile.Name
+	}
+
+	path := "."
+	if flagSet.NArg() > 0 {
+		path = flagSet.Arg(0)
+	}
+	folder := filepath.Join(path, projectFlag)
+
+	written, err := bootstrap.Materialize(profile, folder)
+	if err != nil {
+		util.Log.Error("Creating project %s failed: %s", projectFlag, err)
+		return -1
+	}
+
+	for _, file := range written {
+		util.Log.Debug("\tWrote %s", file)
+	}
+	util.Log.Info("Project %s created from profile %s in %s with %d files", projectFlag, profile.Name, folder, len(written))
+	return 0
+}
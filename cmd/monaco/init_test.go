@@ -0,0 +1,54 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestInitCreatesProjectFromProfile(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-init-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	statusCode := runInit([]string{"init", "--profile", "k8s", "--project", "cluster", folder})
+	assert.Equal(t, statusCode, 0)
+
+	_, err = os.Stat(filepath.Join(folder, "cluster", "management-zone", "kubernetes.yaml"))
+	assert.NilError(t, err)
+
+	statusCode = runInit([]string{"init", "--profile", "k8s", "--project", "cluster", folder})
+	assert.Equal(t, statusCode, -1, "existing projects must not be overwritten")
+}
+
+func TestInitFailsOnUnknownProfile(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-init-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	assert.Equal(t, runInit([]string{"init", "--profile", "unknown", folder}), -1)
+	assert.Equal(t, runInit([]string{"init", folder}), -1)
+}
@@ -51,7 +51,7 @@ func AssertConfig(t *testing.T, environments map[string]environment.Environment,
 		token, err := environment.GetToken()
 		assert.NilError(t, err)
 
-		_, existingId, _ := rest.GetObjectIdIfAlreadyExists(configType, api.GetUrl(environment), name, token)
+		_, existingId, _ := rest.GetObjectIdIfAlreadyExists(configType, api.GetUrl(environment), name, token, rest.NameMatching{})
 
 		if config.IsSkipDeployment(environment) {
 			assert.Equal(t, existingId, "", "Object should NOT be available, but was. environment.Environment: '"+environment.GetId()+"', failed for '"+name+"' ("+configType+")")
@@ -62,7 +62,7 @@ func AssertConfig(t *testing.T, environments map[string]environment.Environment,
 
 		// 120 polling cycles -> Wait at most 120 * 2 seconds = 4 Minutes:
 		err = rest.Wait(description, 120, func() bool {
-			_, existingId, _ = rest.GetObjectIdIfAlreadyExists(configType, api.GetUrl(environment), name, token)
+			_, existingId, _ = rest.GetObjectIdIfAlreadyExists(configType, api.GetUrl(environment), name, token, rest.NameMatching{})
 			return (available && len(existingId) > 0) || (!available && len(existingId) == 0)
 		})
 		assert.NilError(t, err)
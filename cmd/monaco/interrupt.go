@@ -0,0 +1,128 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// defaultCheckpointFile is the file the progress of an interrupted deployment is written to
+const defaultCheckpointFile = "monaco-checkpoint.json"
+
+// interruptedExitCode is the conventional exit code of a process terminated by SIGINT
+const interruptedExitCode = 130
+
+// errInterrupted is returned by deployments stopped due to an interruption
+var errInterrupted = errors.New("deployment was interrupted")
+
+// interruptWatcher notices SIGINT and SIGTERM, e.g. sent when a CI job is cancelled. The first signal lets the
+// deployment finish the configs in flight and stop afterwards, a second signal aborts monaco immediately
+type interruptWatcher struct {
+	interrupted int32
+	signals     chan os.Signal
+}
+
+// watchInterrupts starts watching for interruptions until stop is called
+func watchInterrupts() *interruptWatcher {
+
+	w := &interruptWatcher{signals: make(chan os.Signal, 2)}
+	signal.Notify(w.signals, os.Interrupt, syscall.SIGTERM)
+
+	go w.watch()
+
+	return w
+}
+
+func (w *interruptWatcher) watch() {
+
+	for received := range w.signals {
+		if atomic.CompareAndSwapInt32(&w.interrupted, 0, 1) {
+			util.Log.Warn("Received %s, finishing in-flight requests before stopping. Send it again to abort immediately", received)
+			continue
+		}
+
+		util.Log.Error("Received %s again, aborting without writing a checkpoint", received)
+		os.Exit(interruptedExitCode)
+	}
+}
+
+// isInterrupted returns whether an interruption was received. A nil watcher is never interrupted
+func (w *interruptWatcher) isInterrupted() bool {
+	return w != nil && atomic.LoadInt32(&w.interrupted) == 1
+}
+
+// stop stops watching, signals received afterwards terminate monaco as usual
+func (w *interruptWatcher) stop() {
+
+	if w == nil {
+		return
+	}
+
+	signal.Stop(w.signals)
+	close(w.signals)
+}
+
+// remainingConfigs returns the references of all configs of the projects which are neither completed nor skipped
+// in the environment, in the order they are deployed
+func remainingConfigs(projects []project.Project, environment environment.Environment, options executionOptions) []string {
+
+	remaining := make([]string, 0)
+
+	for _, project := range projects {
+		for _, config := range project.GetConfigs() {
+
+			if config.IsSkipDeployment(environment) || !options.ownership.matchesConfig(config, environment) {
+				continue
+			}
+
+			reference := configReference(config, options.path)
+			if _, completed := options.checkpoint.Completed(environment.GetId(), reference); !completed {
+				remaining = append(remaining, reference)
+			}
+		}
+	}
+
+	return remaining
+}
+
+// configReference returns the id configs refer to the given config by
+func configReference(config config.Config, path string) string {
+	return strings.TrimPrefix(config.GetFullQualifiedId(), path)
+}
+
+// resumeCommand returns the command resuming the run with the given arguments from its checkpoint
+func resumeCommand(args []string) string {
+
+	resume := []string{args[0], "--resume"}
+	for _, arg := range args[1:] {
+		if arg != "--resume" && arg != "-resume" {
+			resume = append(resume, arg)
+		}
+	}
+
+	return strings.Join(resume, " ")
+}
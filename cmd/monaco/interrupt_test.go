@@ -0,0 +1,109 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/checkpoint"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestInterruptedDeploymentRecordsRemainingConfigsAndResumes(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-interrupt-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	err = writeBenchProject(folder, apis, 3)
+	assert.NilError(t, err)
+
+	projects, err := project.LoadProjectsToDeploy(benchProject, apis, folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("bench", "bench", "", server.URL, benchTokenEnv)
+	options := executionOptions{
+		path:          folder,
+		policies:      policy.NoPolicies(),
+		clientOptions: rest.DefaultClientOptions(),
+		checkpoint:    checkpoint.NewCheckpoint(),
+		interrupts:    &interruptWatcher{interrupted: 1},
+	}
+
+	err = execute(env, projects, options)
+	assert.Equal(t, err, errInterrupted)
+	assert.Equal(t, options.checkpoint.Remaining(), 3)
+	assert.Equal(t, server.Objects("management-zone"), 0)
+
+	first := projects[0].GetConfigs()[0]
+	options.checkpoint.Complete("bench", configReference(first, folder), api.DynatraceEntity{Id: "fake-0", Name: "deployed before"})
+	options.interrupts = nil
+
+	err = execute(env, projects, options)
+	assert.NilError(t, err)
+	assert.Equal(t, server.Objects("management-zone"), 2)
+}
+
+func TestResumeCommandAddsResumeFlag(t *testing.T) {
+
+	assert.Equal(t, resumeCommand([]string{"monaco", "-e=environments.yaml", "projects"}), "monaco --resume -e=environments.yaml projects")
+	assert.Equal(t, resumeCommand([]string{"monaco", "--resume", "-e=environments.yaml", "projects"}), "monaco --resume -e=environments.yaml projects")
+}
+
+func TestNilInterruptWatcherIsNeverInterrupted(t *testing.T) {
+
+	var watcher *interruptWatcher
+
+	assert.Assert(t, !watcher.isInterrupted())
+	watcher.stop()
+}
+
+func TestInterruptWatcherNoticesSignal(t *testing.T) {
+
+	watcher := watchInterrupts()
+	defer watcher.stop()
+
+	assert.Assert(t, !watcher.isInterrupted())
+
+	watcher.signals <- os.Interrupt
+
+	for attempt := 0; attempt < 100 && !watcher.isInterrupted(); attempt++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Assert(t, watcher.isInterrupted())
+}
@@ -0,0 +1,186 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/inventory"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// runInventory executes the inventory command, which exports the objects of the environments as csv or json table,
+// e.g. for audits. Objects of the configs of the given projects are flagged as managed by monaco.
+// Returns 0 on success and -1 on errors
+func runInventory(args []string, fileReader util.FileReader) (statusCode int) {
+
+	var environmentsFile, specificEnvironment, projectFlag, format, output string
+	var verbose, lastModified, caseInsensitiveNames bool
+	var clientFlags clientFlags
+
+	flagSet := flag.NewFlagSet("inventory", flag.ExitOnError)
+
+	environmentsUsage := "Mandatory yaml file containing the environments to export the objects of."
+	flagSet.StringVar(&environmentsFile, "environments", "", environmentsUsage)
+	flagSet.StringVar(&environmentsFile, "e", "", environmentsUsage+" (shorthand)")
+
+	specificEnvironmentUsage := "Specific environment (from list) to export the objects of. Defaults to all environments."
+	flagSet.StringVar(&specificEnvironment, "specific-environment", "", specificEnvironmentUsage)
+	flagSet.StringVar(&specificEnvironment, "se", "", specificEnvironmentUsage+" (shorthand)")
+
+	projectUsage := "Project whose objects are flagged as managed by monaco, together with the projects it depends on. Defaults to all projects."
+	flagSet.StringVar(&projectFlag, "project", "", projectUsage)
+	flagSet.StringVar(&projectFlag, "p", "", projectUsage+" (shorthand)")
+
+	formatUsage := "Format of the inventory: csv or json."
+	flagSet.StringVar(&format, "format", "csv", formatUsage)
+
+	outputUsage := "File the inventory is written to. Defaults to inventory.csv or inventory.json."
+	flagSet.StringVar(&output, "output", "", outputUsage)
+
+	lastModifiedUsage := "Read every object to export the time it was modified last, for the apis exposing it."
+	flagSet.BoolVar(&lastModified, "last-modified", false, lastModifiedUsage)
+
+	addNameMatchingFlag(flagSet, &caseInsensitiveNames)
+	addClientFlags(flagSet, &clientFlags)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+" (shorthand)")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	if environmentsFile == "" {
+		println("Please provide environments yaml with -e/--environments!")
+		flagSet.Usage()
+		return -1
+	}
+
+	if format != "csv" && format != "json" {
+		util.Log.Error("Invalid --format %s, expected csv or json", format)
+		return -1
+	}
+	if output == "" {
+		output = "inventory." + format
+	}
+
+	environments, errorList := environment.LoadEnvironmentList(specificEnvironment, environmentsFile, fileReader)
+	for _, err := range errorList {
+		util.Log.Error("Loading of environments failed: %s", err)
+	}
+	if len(errorList) > 0 {
+		return -1
+	}
+
+	var projects []project.Project
+	if flagSet.NArg() > 0 {
+		path := readPath(flagSet.Args(), fileReader)
+		projects, err = project.LoadProjectsToDeploy(projectFlag, createApis(), path, fileReader)
+		if err != nil {
+			util.Log.Error("Loading of projects failed: %s", err)
+			return -1
+		}
+	}
+
+	clientOptions, err := clientFlags.apply(rest.DefaultClientOptions(), fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+	defer func() {
+		if !clientFlags.saveNameCache(clientOptions) {
+			statusCode = -1
+		}
+	}()
+
+	entries := make([]inventory.Entry, 0)
+	for _, id := range sortedEnvironmentIds(environments) {
+		env := environments[id]
+		util.Log.Info("Exporting objects of environment %s...", id)
+
+		client, err := createClient(env, clientOptions)
+		if err != nil {
+			util.Log.Error("Inventory of %s failed: %s", id, err)
+			return -1
+		}
+
+		collected, err := inventory.Collect(id, client, createApis(), inventory.Options{
+			LastModified: lastModified,
+			Managed:      managedObjects(projects, env, rest.NameMatching{CaseInsensitive: caseInsensitiveNames}),
+		})
+		if err != nil {
+			util.Log.Error("Inventory of %s failed: %s", id, err)
+			return -1
+		}
+		entries = append(entries, collected...)
+	}
+
+	var content bytes.Buffer
+	if format == "json" {
+		err = inventory.WriteJson(&content, entries)
+	} else {
+		err = inventory.WriteCsv(&content, entries)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(output, content.Bytes(), 0644)
+	}
+	if err != nil {
+		util.Log.Error("Writing inventory to %s failed: %s", output, err)
+		return -1
+	}
+
+	util.Log.Info("Inventory of %d objects written to %s", len(entries), output)
+	return 0
+}
+
+// managedObjects returns whether an object is deployed by a config of the projects to the environment. Objects are
+// matched by name with the given name matching, configs whose name references other configs can not be matched
+// without deploying them
+func managedObjects(projects []project.Project, env environment.Environment, matching rest.NameMatching) func(apiId string, name string) bool {
+
+	managed := make(map[string]bool)
+
+	for _, p := range projects {
+		for _, c := range p.GetConfigs() {
+			if c.IsSkipDeployment(env) {
+				continue
+			}
+			name, err := c.GetObjectNameForEnvironment(env, nil)
+			if err == nil {
+				managed[c.GetApi().GetId()+"/"+matching.Normalize(name)] = true
+			}
+		}
+	}
+
+	return func(apiId string, name string) bool {
+		return managed[apiId+"/"+matching.Normalize(name)]
+	}
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/inventory"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestInventoryFlagsObjectsOfProjectsAsManaged(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-inventory-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	err = writeBenchProject(folder, apis, 2)
+	assert.NilError(t, err)
+
+	projects, err := project.LoadProjectsToDeploy(benchProject, apis, folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("bench", "bench", "", server.URL, benchTokenEnv)
+
+	err = execute(env, projects, executionOptions{path: folder, policies: policy.NoPolicies(), clientOptions: rest.DefaultClientOptions()})
+	assert.NilError(t, err)
+
+	client, err := createClient(env, rest.DefaultClientOptions())
+	assert.NilError(t, err)
+	_, err = client.UpsertByName(apis["management-zone"], "created manually", []byte(`{"name": "created manually"}`))
+	assert.NilError(t, err)
+
+	entries, err := inventory.Collect("bench", client, apis, inventory.Options{Managed: managedObjects(projects, env, rest.NameMatching{})})
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 3)
+
+	managed := make(map[string]bool)
+	for _, entry := range entries {
+		assert.Equal(t, entry.Environment, "bench")
+		managed[entry.Name] = entry.Managed
+	}
+	assert.DeepEqual(t, managed, map[string]bool{
+		"created manually":               false,
+		"monaco-bench-management-zone-1": true,
+		"monaco-bench-management-zone-2": true,
+	})
+}
@@ -22,15 +22,27 @@ import (
 	"fmt"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/catalog"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/checkpoint"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/deploy"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/estimate"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/freeze"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/lint"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rewrite"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
 )
@@ -46,9 +58,49 @@ func Run(args []string) int {
 
 func RunImpl(args []string, fileReader util.FileReader) (statusCode int) {
 
+	// deploying is the default command, it can also be called explicitly
+	if len(args) > 1 && args[1] == "deploy" {
+		args = append([]string{args[0]}, args[2:]...)
+	}
+
+	if len(args) > 1 {
+		switch args[1] {
+		case "compare":
+			return runCompare(args[1:], fileReader)
+		case "snapshot":
+			return runSnapshot(args[1:], fileReader)
+		case "restore":
+			return runRestore(args[1:], fileReader)
+		case "import":
+			return runImport(args[1:], fileReader)
+		case "download":
+			return runDownload(args[1:], fileReader)
+		case "render-diff":
+			return runRenderDiff(args[1:], fileReader)
+		case "bench":
+			return runBench(args[1:], fileReader)
+		case "package":
+			return runPackage(args[1:], fileReader)
+		case "preview":
+			return runPreview(args[1:], fileReader)
+		case "docs":
+			return runDocs(args[1:], fileReader)
+		case "inventory":
+			return runInventory(args[1:], fileReader)
+		case "explain-var":
+			return runExplainVar(args[1:], fileReader)
+		case "explain":
+			return runExplain(args[1:], fileReader)
+		case "convert":
+			return runConvert(args[1:], fileReader)
+		case "init":
+			return runInit(args[1:])
+		}
+	}
+
 	statusCode = 0
 
-	dryRun, verbose, environments, projectNameToDeploy, path, errorList, flagError := parseInputCommand(args, fileReader)
+	flags, environments, errorList, flagError := parseInputCommand(args, fileReader)
 
 	if flagError != nil {
 		util.FailOnError(flagError, "could not parse flags")
@@ -61,60 +113,424 @@ func RunImpl(args []string, fileReader util.FileReader) (statusCode int) {
 		deploymentErrors[configIssue] = err
 	}
 
-	err := util.SetupLogging(verbose)
+	setupLoggingForOutput(flags.verbose, flags.outputFormat)
+
+	util.Log.Info("Dynatrace Monitoring as Code v" + version.MonitoringAsCode)
 
+	err := validateOutputFormat(flags.outputFormat)
 	if err != nil {
-		util.Log.Error("Error writing log file: %s", err.Error())
+		util.Log.Error("%s", err)
+		return -1
 	}
 
-	util.Log.Info("Dynatrace Monitoring as Code v" + version.MonitoringAsCode)
+	err = freezeTime(flags.timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	// the summary is written once the run finished, including its final status code. With json or yaml output,
+	// it is also written to stdout, e.g. to process the plan of a dry run
+	var summary *runSummary
+	if flags.summaryFile != "" || flags.outputFormat != outputTable {
+		summary = newRunSummary(flags.dryRun, time.Now())
+		defer func() {
+			if flags.summaryFile != "" {
+				err := summary.write(flags.summaryFile, statusCode, time.Now())
+				if err != nil {
+					util.Log.Error("Writing summary to %s failed: %s", flags.summaryFile, err)
+				}
+			}
+			if flags.outputFormat != outputTable {
+				err := writeOutput(os.Stdout, flags.outputFormat, summary.content(statusCode, time.Now()))
+				if err != nil {
+					util.Log.Error("Writing summary failed: %s", err)
+				}
+			}
+		}()
+
+		// issues of the environments file are reported like failed environments
+		for issue, err := range deploymentErrors {
+			summary.finished(environmentResult{environment: issue, err: err})
+		}
+	}
+
+	var streamedResults deploy.Listener
+	if flags.resultsStream != "" {
+		stream, err := os.Create(flags.resultsStream)
+		if err != nil {
+			util.Log.Error("Opening results stream %s failed: %s", flags.resultsStream, err)
+			return -1
+		}
+		defer stream.Close()
+		streamedResults = newResultStream(stream)
+	}
+
+	// the requests are recorded during the whole run, but only written if it fails
+	var replay *rest.Replay
+	if flags.replayFile != "" {
+		replay = rest.NewReplay()
+		redactSecrets(replay)
+		defer func() {
+			if statusCode == 0 {
+				return
+			}
+			err := replay.Save(flags.replayFile)
+			if err != nil {
+				util.Log.Error("Writing replay file %s failed: %s", flags.replayFile, err)
+			} else {
+				util.Log.Info("The requests of the failed run were written to %s", flags.replayFile)
+			}
+		}()
+	}
+
+	if flags.artifact != "" {
+		artifactFolder, path, err := extractArtifact(flags.artifact)
+		if err != nil {
+			util.FailOnError(err, "Reading of artifact failed")
+		}
+		defer os.RemoveAll(artifactFolder)
+		flags.path = path
+	}
+
+	if flags.profileFolder != "" {
+		stopProfiling, err := startProfiling(flags.profileFolder)
+		if err != nil {
+			util.Log.Error("Profiling could not be started: %s", err)
+		} else {
+			defer stopProfiling()
+		}
+	}
+
+	fieldOverrides, err := api.LoadFieldOverrides(flags.fieldOverridesFile, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of field overrides failed")
+	}
 
 	apis := createApis()
 
-	projects, err := project.LoadProjectsToDeploy(projectNameToDeploy, apis, path, fileReader)
+	variables, err := project.LoadVariables(flags.path, apis, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of variables failed")
+	}
+
+	err = resolveVariables(variables, flags.variables, newStdinPrompter())
+	if err != nil {
+		util.FailOnError(err, "Resolving of variables failed")
+	}
+
+	projects, err := project.LoadProjectsToDeploy(flags.project, apis, flags.path, fileReader)
 	if err != nil {
 		util.FailOnError(err, "Loading of projects failed")
 	}
 
+	// dangling references are reported all at once, instead of failing the validation at the first one
+	if flags.dryRun {
+		dangling := findDanglingReferences(projects, flags.path, fileReader)
+		if len(dangling) > 0 {
+			logDanglingReferences(dangling)
+			err = fmt.Errorf("%d references to configs which do not exist found", len(dangling))
+			deploymentErrors["references"] = err
+			summary.finished(environmentResult{environment: "references", err: err})
+		}
+	}
+
+	policies, err := policy.LoadPolicies(flags.policyFolder, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of policies failed")
+	}
+
+	calendar, err := freeze.LoadCalendar(flags.freezeWindowsFile, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of freeze windows failed")
+	}
+
+	linter, err := lint.NewLinter(splitList(flags.disabledLintRules))
+	if err != nil {
+		util.FailOnError(err, "Setup of lint rules failed")
+	}
+
+	targets, err := targetOptions(flags, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Setup of the deployment failed")
+	}
+	deployState := targets.state
+
+	statusPolicy, err := rest.LoadStatusPolicy(flags.statusPolicyFile, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of status policy failed")
+	}
+
+	settingsMigrations, err := settings.LoadFieldMigrations(flags.settingsMigrations, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of settings migrations failed")
+	}
+
+	safetyLevels, err := delete.LoadSafetyLevels(flags.deletionSafetyFile, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Loading of deletion safety levels failed")
+	}
+
+	// interrupted deployments stop after the configs in flight and record their progress in a checkpoint
+	var deployCheckpoint *checkpoint.Checkpoint
+	var interrupts *interruptWatcher
+	if !flags.dryRun {
+		if flags.resume {
+			deployCheckpoint, err = checkpoint.LoadCheckpoint(flags.checkpointFile, fileReader)
+			if err != nil {
+				util.FailOnError(err, "Loading of checkpoint failed")
+			}
+			util.Log.Info("Resuming deployment from checkpoint %s", flags.checkpointFile)
+		} else {
+			deployCheckpoint = checkpoint.NewCheckpoint()
+		}
+		interrupts = watchInterrupts()
+	}
+
+	options := executionOptions{
+		dryRun:         flags.dryRun,
+		path:           flags.path,
+		policies:       policies,
+		linter:         linter,
+		strictLint:     flags.strictLint,
+		duplicateNames: targets.duplicateNames,
+		successors:     targets.successors,
+		state:          deployState,
+		checkpoint:     deployCheckpoint,
+		interrupts:     interrupts,
+		summary:        summary,
+		results:        streamedResults,
+		ownership:      ownershipFilter{owner: flags.owner, labels: flags.labels},
+
+		testNotifications: flags.testNotifications,
+		detectCoManaged:   flags.detectCoManaged,
+
+		strictDeprecations: flags.strictDeprecations,
+		ownershipMarkers:   targets.ownershipMarkers,
+		validateOnServer:   flags.validateOnServer,
+		acceptRemote:       targets.acceptRemote,
+		forcePinned:        targets.forcePinned,
+		fieldOverrides:     fieldOverrides,
+		settingsMigrations: settingsMigrations,
+		clientOptions: rest.ClientOptions{
+			ConflictRetries: flags.conflictRetries,
+			NameMatching:    targets.clientOptions.NameMatching,
+			StatusPolicy:    statusPolicy,
+			Replay:          replay,
+
+			MaxConcurrentRequests: flags.maxConcurrency,
+
+			ExtensionUploadTimeout: flags.uploadTimeout,
+			ExtensionUploadRetries: flags.uploadRetries,
+			WaitForExtensions:      flags.waitForExtensions,
+		},
+	}
+
+	options.clientOptions, err = flags.apply(options.clientOptions, fileReader)
+	if err != nil {
+		util.FailOnError(err, "Setup of the deployment failed")
+	}
+
+	if flags.rewriteUrls {
+		// urls of all environments are rewritten, not only of the ones deployed to
+		// rewriting with some environments missing would leave their urls unchanged without notice
+		allEnvironments, errorList := environment.LoadEnvironmentList("", flags.environmentsFile, fileReader)
+		for _, err := range errorList {
+			util.Log.Error("Loading of environments to rewrite urls of failed: %s", err)
+			statusCode = -1
+		}
+		if statusCode != 0 {
+			return statusCode
+		}
+		options.rewriter = rewrite.NewRewriter(allEnvironments, deployState)
+	}
+
 	util.Log.Info("Executing projects in this order: ")
 
 	for i, project := range projects {
 		util.Log.Info("\t%d: %s (%d configs)", i+1, project.GetId(), len(project.GetConfigs()))
 	}
 
-	for _, environment := range environments {
-		err := execute(environment, projects, dryRun, path)
+	if !options.ownership.isEmpty() {
+		util.Log.Info("Only configs with owner '%s' and labels %v are deployed and deleted", options.ownership.owner, options.ownership.labels)
+	}
+
+	// frozen environments are neither deployed to nor are configs deleted in them
+	unfrozenEnvironments := make(map[string]environment.Environment, len(environments))
+
+	for id, environment := range environments {
+		err := checkFreeze(calendar, environment, flags.dryRun, flags.overrideFreeze, time.Now())
 		if err != nil {
 			deploymentErrors[environment.GetId()] = err
+			summary.frozen(environment.GetId(), err)
+			continue
+		}
+		unfrozenEnvironments[id] = environment
+	}
+
+	results := forEachEnvironment(unfrozenEnvironments, flags.parallel, func(environment environment.Environment) error {
+		return retryDuringMaintenance(environment, flags.maintenanceRetries, flags.maintenanceDelay, func() error {
+			return execute(environment, projects, options)
+		})
+	})
+
+	for _, result := range results {
+		summary.finished(result)
+		if result.err != nil {
+			deploymentErrors[result.environment] = result.err
+		}
+	}
+
+	if len(results) > 1 {
+		logEnvironmentSummary(results, flags.dryRun)
+	}
+
+	interrupted := interrupts.isInterrupted()
+	interrupts.stop()
+
+	if interrupted {
+		err = deployCheckpoint.Save(flags.checkpointFile)
+		if err != nil {
+			util.Log.Error("Saving checkpoint to %s failed: %s", flags.checkpointFile, err)
+		} else {
+			util.Log.Warn("Deployment was interrupted with %d configs remaining, the progress was written to %s. Resume it with:\n\t%s",
+				deployCheckpoint.Remaining(), flags.checkpointFile, resumeCommand(args))
 		}
 	}
 
 	for environment, err := range deploymentErrors {
-		if dryRun {
+		if flags.dryRun {
 			util.Log.Error("Validation of %s failed with error %s\n", environment, err)
 		} else {
 			util.Log.Error("Deployment to %s failed with error %s\n", environment, err)
 		}
+		if entry, found := catalog.Lookup(err); found {
+			util.Log.Error("%s\n", entry.Describe())
+		}
 		statusCode = -1
 	}
 
 	if statusCode == 0 {
-		if dryRun {
+		if flags.dryRun {
 			util.Log.Info("Validation finished without errors")
 		} else {
 			util.Log.Info("Deployment finished without errors")
 		}
 	}
 
-	deleteConfigs(apis, environments, path, dryRun, fileReader)
+	if interrupted {
+		util.Log.Warn("Skipping deletion of configs, as the deployment was interrupted")
+	} else {
+		err = deleteConfigs(apis, unfrozenEnvironments, flags.path, deleteOptions{
+			dryRun:           flags.dryRun,
+			state:            deployState,
+			clientOptions:    options.clientOptions,
+			backupFolder:     flags.backupFolder,
+			environmentsFile: flags.environmentsFile,
+			allowProtected:   flags.allowProtected,
+			safetyLevels:     safetyLevels,
+			allowHighImpact:  flags.allowHighImpact,
+			ownership:        options.ownership,
+			deployed:         projects,
+			summary:          summary,
+		}, fileReader)
+		if err != nil {
+			util.Log.Error("Deletion of configs failed: %s", err)
+			statusCode = -1
+		}
+	}
+
+	// old names are only removed once every environment was renamed, as environments still being renamed need them
+	if flags.clearRenames && !flags.dryRun && statusCode == 0 {
+		err = clearRenames(projects, fileReader)
+		if err != nil {
+			util.Log.Error("Removing old names failed: %s", err)
+			statusCode = -1
+		}
+	}
+
+	if flags.resume && !flags.dryRun && statusCode == 0 {
+		err = os.Remove(flags.checkpointFile)
+		if err != nil {
+			util.Log.Warn("Removing checkpoint %s failed: %s", flags.checkpointFile, err)
+		}
+	}
+
+	if deployState != nil && !flags.dryRun {
+		err = deployState.Commit()
+		if err != nil {
+			util.Log.Error("Saving state to %s failed: %s", flags.stateFile, err)
+			statusCode = -1
+		}
+	}
+
+	if !flags.saveNameCache(options.clientOptions) {
+		statusCode = -1
+	}
 
 	return statusCode
 }
 
-func parseInputCommand(args []string, fileReader util.FileReader) (dryRun bool, verbose bool, environments map[string]environment.Environment, project string, path string, errorList []error, flagError error) {
+// deployFlags holds the flags passed to a deployment or validation run
+type deployFlags struct {
+	dryRun            bool
+	verbose           bool
+	project           string
+	path              string
+	policyFolder      string
+	disabledLintRules string
+	strictLint        bool
+	duplicateNames    string
+	stateFile         string
+	conflictRetries   int
+	maxConcurrency    int
+	uploadTimeout     time.Duration
+	uploadRetries     int
+	waitForExtensions bool
+	environmentsFile  string
+	backupFolder      string
+
+	caseInsensitiveNames bool
+	testNotifications    bool
+	rewriteUrls          bool
+	freezeWindowsFile    string
+	overrideFreeze       bool
+	parallel             int
+	profileFolder        string
+	allowProtected       bool
+	owner                string
+	labels               stringListFlag
+	artifact             string
+	maintenanceRetries   int
+	maintenanceDelay     time.Duration
+	checkpointFile       string
+	resume               bool
+	fieldOverridesFile   string
+	statusPolicyFile     string
+	settingsMigrations   string
+	summaryFile          string
+	variables            stringListFlag
+	clearRenames         bool
+	detectCoManaged      bool
+	deletionSafetyFile   string
+	allowHighImpact      bool
+	outputFormat         string
+	timestamp            string
+	strictDeprecations   bool
+	ownershipMarkers     bool
+	validateOnServer     bool
+	resultsStream        string
+	acceptRemote         bool
+	forcePinned          bool
+	replayFile           string
+	successors           string
+
+	clientFlags
+}
+
+func parseInputCommand(args []string, fileReader util.FileReader) (flags deployFlags, environments map[string]environment.Environment, errorList []error, flagError error) {
 
 	// define flags
-	var environmentsFile string
 	var specificEnvironment string
 
 	// parse flags
@@ -122,42 +538,178 @@ func parseInputCommand(args []string, fileReader util.FileReader) (dryRun bool,
 	dryRunUsage := "Set dry-run flag to just validate configurations instead of deploying."
 
 	flagSet := flag.NewFlagSet("arguments", flag.ExitOnError)
-	flagSet.BoolVar(&dryRun, "dry-run", false, dryRunUsage)
-	flagSet.BoolVar(&dryRun, "d", false, dryRunUsage+shorthand)
+	flagSet.BoolVar(&flags.dryRun, "dry-run", false, dryRunUsage)
+	flagSet.BoolVar(&flags.dryRun, "d", false, dryRunUsage+shorthand)
 
 	verboseUsage := "Set verbose flag to enable debug logging."
-	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
-	flagSet.BoolVar(&verbose, "v", false, verboseUsage+shorthand)
+	flagSet.BoolVar(&flags.verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&flags.verbose, "v", false, verboseUsage+shorthand)
 
 	projectUsage := "Project configuration to deploy. Also deploys any dependent configuration."
-	flagSet.StringVar(&project, "project", "", projectUsage)
-	flagSet.StringVar(&project, "p", "", projectUsage+shorthand)
+	flagSet.StringVar(&flags.project, "project", "", projectUsage)
+	flagSet.StringVar(&flags.project, "p", "", projectUsage+shorthand)
 
 	specificEnvironmentUsage := "Specific environment (from list) to deploy to."
 	flagSet.StringVar(&specificEnvironment, "specific-environment", "", specificEnvironmentUsage)
 	flagSet.StringVar(&specificEnvironment, "se", "", specificEnvironmentUsage+shorthand)
 
 	environmentsUsage := "Mandatory yaml file containing environments to deploy to."
-	flagSet.StringVar(&environmentsFile, "environments", "", environmentsUsage)
-	flagSet.StringVar(&environmentsFile, "e", "", environmentsUsage+shorthand)
+	flagSet.StringVar(&flags.environmentsFile, "environments", "", environmentsUsage)
+	flagSet.StringVar(&flags.environmentsFile, "e", "", environmentsUsage+shorthand)
+
+	policiesUsage := "Folder containing rego policies (*.rego) all configs are checked against before validation or deployment."
+	flagSet.StringVar(&flags.policyFolder, "policies", "", policiesUsage)
+
+	disableLintRulesUsage := "Comma separated list of lint rules not to check during validation. Available rules: " + lintRuleIds()
+	flagSet.StringVar(&flags.disabledLintRules, "disable-lint-rules", "", disableLintRulesUsage)
+
+	strictLintUsage := "Fail the validation if configs violate lint rules, instead of only warning about them."
+	flagSet.BoolVar(&flags.strictLint, "strict-lint", false, strictLintUsage)
+
+	conflictRetriesUsage := "Number of times an update is retried after it conflicted with a concurrent modification (HTTP 409)."
+	flagSet.IntVar(&flags.conflictRetries, "conflict-retries", rest.DefaultClientOptions().ConflictRetries, conflictRetriesUsage)
+
+	statusPolicyUsage := "Yaml file defining per api and operation which status codes are retried, treated as success (e.g. 404 on delete) or fatal."
+	flagSet.StringVar(&flags.statusPolicyFile, "status-policy", "", statusPolicyUsage)
+
+	settingsMigrationsUsage := "Yaml file listing the fields of settings schemas renamed or removed by a schema version, applied to settings configs written for older versions."
+	flagSet.StringVar(&flags.settingsMigrations, "settings-migrations", "", settingsMigrationsUsage)
+
+	maxConcurrencyUsage := "Maximum number of requests sent to an environment at the same time. Fewer requests are sent while the environment throttles requests (HTTP 429)."
+	flagSet.IntVar(&flags.maxConcurrency, "max-concurrent-requests", rest.DefaultClientOptions().MaxConcurrentRequests, maxConcurrencyUsage)
+
+	addClientFlags(flagSet, &flags.clientFlags)
+
+	uploadTimeoutUsage := "Maximum duration of uploading an extension."
+	flagSet.DurationVar(&flags.uploadTimeout, "extension-upload-timeout", rest.DefaultClientOptions().ExtensionUploadTimeout, uploadTimeoutUsage)
+
+	uploadRetriesUsage := "Number of times an extension upload is retried after it timed out or failed with a server error (HTTP 5xx)."
+	flagSet.IntVar(&flags.uploadRetries, "extension-upload-retries", rest.DefaultClientOptions().ExtensionUploadRetries, uploadRetriesUsage)
+
+	waitForExtensionsUsage := "Wait up to 30s after uploading an extension until its instances are initialized, e.g. if other configs depend on its metrics."
+	flagSet.BoolVar(&flags.waitForExtensions, "wait-for-extensions", false, waitForExtensionsUsage)
+
+	testNotificationsUsage := "Send a test message to the webhook of every deployed notification, to verify the channel works."
+	flagSet.BoolVar(&flags.testNotifications, "test-notifications", false, testNotificationsUsage)
+
+	rewriteUrlsUsage := "Rewrite urls of other environments of the environments file within payloads to the url of the environment deployed to."
+	flagSet.BoolVar(&flags.rewriteUrls, "rewrite-urls", false, rewriteUrlsUsage)
+
+	freezeWindowsUsage := "Yaml file defining freeze windows, during which deployments to the environments they apply to are blocked."
+	flagSet.StringVar(&flags.freezeWindowsFile, "freeze-windows", "", freezeWindowsUsage)
+
+	overrideFreezeUsage := "Deploy to environments even if they are frozen by a freeze window."
+	flagSet.BoolVar(&flags.overrideFreeze, "override-freeze", false, overrideFreezeUsage)
+
+	artifactUsage := "Deploy the projects of an artifact written by the package command, instead of the projects in the project folder."
+	flagSet.StringVar(&flags.artifact, "from-artifact", "", artifactUsage)
+
+	maintenanceRetriesUsage := "Number of times the deployment to an environment is retried after it was unavailable (HTTP 503), e.g. during maintenance."
+	flagSet.IntVar(&flags.maintenanceRetries, "maintenance-retries", 0, maintenanceRetriesUsage)
+
+	maintenanceDelayUsage := "Time waited before retrying the deployment to an unavailable environment, e.g. 5m."
+	flagSet.DurationVar(&flags.maintenanceDelay, "maintenance-delay", defaultMaintenanceDelay, maintenanceDelayUsage)
+
+	parallelUsage := "Number of environments deployed to in parallel. Failures in one environment do not affect the others."
+	flagSet.IntVar(&flags.parallel, "parallel", 1, parallelUsage)
+
+	profileUsage := "Folder cpu and heap profiles and a runtime summary of the run are written to, e.g. to diagnose slow deployments."
+	flagSet.StringVar(&flags.profileFolder, "profile", "", profileUsage)
+
+	checkpointFileUsage := "Json file the progress of a deployment is written to when it is interrupted by SIGINT or SIGTERM."
+	flagSet.StringVar(&flags.checkpointFile, "checkpoint-file", defaultCheckpointFile, checkpointFileUsage)
+
+	resumeUsage := "Resume an interrupted deployment from its checkpoint file, only deploying the configs which were not deployed yet."
+	flagSet.BoolVar(&flags.resume, "resume", false, resumeUsage)
+
+	ownerUsage := "Only deploy configs and apply delete entries with the given owner, e.g. the team running the pipeline."
+	flagSet.StringVar(&flags.owner, "owner", "", ownerUsage)
+
+	labelUsage := "Only deploy configs and apply delete entries with the given label. Can be passed multiple times to require all labels."
+	flagSet.Var(&flags.labels, "label", labelUsage)
+
+	allowProtectedUsage := "Delete objects of configs marked as protected, which are skipped by deletions otherwise."
+	flagSet.BoolVar(&flags.allowProtected, "allow-protected", false, allowProtectedUsage)
+
+	flagSet.StringVar(&flags.fieldOverridesFile, "field-overrides", "", fieldOverridesUsage)
+
+	variableUsage := "Sets the environment variable used by templates, given as NAME=value. Can be passed multiple times."
+	flagSet.Var(&flags.variables, "var", variableUsage)
+
+	summaryFileUsage := "Json file a summary of the run is written to, with the result, counts and errors of every environment."
+	flagSet.StringVar(&flags.summaryFile, "summary-file", "", summaryFileUsage)
+
+	clearRenamesUsage := "Remove the oldName properties from the config yamls once all environments were deployed to without errors."
+	flagSet.BoolVar(&flags.clearRenames, "clear-renames", false, clearRenamesUsage)
+
+	detectCoManagedUsage := "Warn about configs whose objects were changed by other tools or users, e.g. Terraform or the UI, according to the audit log. Also applies to dry runs."
+	flagSet.BoolVar(&flags.detectCoManaged, "detect-co-managed", false, detectCoManagedUsage)
+
+	deletionSafetyUsage := "Yaml file classifying apis by the impact of deleting their objects as low or high, overriding the defaults."
+	flagSet.StringVar(&flags.deletionSafetyFile, "deletion-safety", "", deletionSafetyUsage)
+
+	allowHighImpactUsage := "Delete objects of high impact apis, e.g. management zones, without asking for confirmation. They are skipped otherwise when not running interactively."
+	flagSet.BoolVar(&flags.allowHighImpact, "allow-high-impact-deletions", false, allowHighImpactUsage)
+
+	strictDeprecationsUsage := "Fail the deployment to an environment if it uses endpoints the server flags as deprecated."
+	flagSet.BoolVar(&flags.strictDeprecations, "strict-deprecations", false, strictDeprecationsUsage)
+
+	validateOnServerUsage := "During dry runs, let the environments validate the rendered settings configs, in batches of up to 100 objects and 1 MB per request. Requires the tokens of the environments."
+	flagSet.BoolVar(&flags.validateOnServer, "validate-on-server", false, validateOnServerUsage)
+
+	resultsStreamUsage := "File the result of every config is written to as a line of json as soon as it is known, e.g. a named pipe of a tool showing the progress."
+	flagSet.StringVar(&flags.resultsStream, "results-stream", "", resultsStreamUsage)
+
+	replayFileUsage := "File the requests sent to the environments and their responses are written to if the run fails, without tokens, e.g. to attach it to an issue."
+	flagSet.StringVar(&flags.replayFile, "replay-file", "", replayFileUsage)
+
+	addTargetFlags(flagSet, &flags)
+	addOutputFormatFlag(flagSet, &flags.outputFormat)
+	addTimestampFlag(flagSet, &flags.timestamp)
+
+	backupFolderUsage := "Folder configs are backed up to before they are deleted."
+	flagSet.StringVar(&flags.backupFolder, "backup-folder", defaultBackupFolder, backupFolderUsage)
 
 	err := flagSet.Parse(args[1:])
 	if err != nil {
-		return dryRun, verbose, environments, project, path, nil, err
+		return flags, environments, nil, err
 	}
 
 	// Show usage if flags are invalid
-	if environmentsFile == "" {
+	if flags.environmentsFile == "" {
 		println("Please provide environments yaml with -e/--environments!")
 		flagSet.Usage()
 		os.Exit(1)
 	}
 
-	environments, errorList = environment.LoadEnvironmentList(specificEnvironment, environmentsFile, fileReader)
+	environments, errorList = environment.LoadEnvironmentList(specificEnvironment, flags.environmentsFile, fileReader)
 
-	path = readPath(args, fileReader)
+	flags.path = readPath(args, fileReader)
 
-	return dryRun, verbose, environments, project, path, errorList, nil
+	return flags, environments, errorList, nil
+}
+
+func lintRuleIds() string {
+
+	ids := make([]string, 0)
+	for _, rule := range lint.Rules() {
+		ids = append(ids, rule.Id)
+	}
+
+	return strings.Join(ids, ", ")
+}
+
+// splitList splits a comma separated list into its trimmed, non empty entries
+func splitList(list string) []string {
+
+	result := make([]string, 0)
+	for _, entry := range strings.Split(list, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			result = append(result, entry)
+		}
+	}
+
+	return result
 }
 
 func readPath(args []string, fileReader util.FileReader) string {
@@ -167,6 +719,8 @@ func readPath(args []string, fileReader util.FileReader) string {
 	if !strings.HasSuffix(potentialPath, ".yaml") {
 		_, err := fileReader.ReadDir(potentialPath)
 		if err == nil {
+			potentialPath, err = util.RelativeToWorkingDir(potentialPath)
+			util.FailOnError(err, "Invalid project path")
 			if !strings.HasSuffix(potentialPath, string(os.PathSeparator)) {
 				potentialPath += string(os.PathSeparator)
 			}
@@ -183,12 +737,142 @@ func createApis() map[string]api.Api {
 	return api.NewApis()
 }
 
-func execute(environment environment.Environment, projects []project.Project, dryRun bool, path string) error {
+// executionOptions bundles the settings of a single deployment or validation run
+type executionOptions struct {
+	dryRun   bool
+	path     string
+	policies policy.Engine
+	linter   lint.Linter
+
+	// strictLint fails the validation if configs violate lint rules, they are only warned about otherwise
+	strictLint bool
+
+	// duplicateNames is applied to configs whose name is used by multiple existing objects
+	duplicateNames duplicateNameStrategy
+
+	// successors selects whether configs of classic apis are deployed to their Settings 2.0 successors
+	successors successorMode
+
+	// available probes the apis and schemas the environment offers, nil during dry runs
+	available *capabilities
+
+	// state records the ids of deployed objects, may be nil
+	state *state.State
+
+	// checkpoint records the deployed configs, configs it records as completed are not deployed again. May be nil
+	checkpoint *checkpoint.Checkpoint
+
+	// interrupts stops the deployment before the next config once monaco is interrupted, may be nil
+	interrupts *interruptWatcher
+
+	// ownership restricts the deployment to the configs of an owner or with labels
+	ownership ownershipFilter
+
+	// summary counts the deployed and skipped configs per environment, may be nil
+	summary *runSummary
+
+	// results receives the result of every config, may be nil
+	results deploy.Listener
+
+	// testNotifications sends a test message to the webhooks of deployed notifications
+	testNotifications bool
+
+	// rewriter rewrites urls of other environments within payloads, may be nil
+	rewriter *rewrite.Rewriter
+
+	// detectCoManaged warns about configs whose objects were changed by other tools or users, also in dry runs
+	detectCoManaged bool
+
+	// strictDeprecations fails the environment if deprecated endpoints were used
+	strictDeprecations bool
+
+	// validateOnServer validates settings configs by the environments during dry runs
+	validateOnServer bool
+
+	// ownershipMarkers marks deployed objects as owned in their description and finds them by the marker again
+	ownershipMarkers bool
+
+	// acceptRemote keeps and forcePinned overwrites objects which changed since their configs were pinned to them
+	acceptRemote bool
+	forcePinned  bool
+
+	// fieldOverrides add server managed, diff ignored and known fields to the built-in ones of the apis
+	fieldOverrides api.FieldOverrides
+
+	// settingsMigrations are applied to settings configs written for older schema versions
+	settingsMigrations []settings.FieldMigration
+
+	clientOptions rest.ClientOptions
+}
+
+func execute(environment environment.Environment, projects []project.Project, options executionOptions) error {
 	util.Log.Info("Processing environment " + environment.GetId() + "...")
+	options.summary.reset(environment.GetId())
+	options = withFeatureFlags(options, environment)
 
 	dict := make(map[string]api.DynatraceEntity)
+	deployedToSuccessors := make(map[string]bool)
 	var nameDict = make(map[string]string)
 	var name, configID string
+	var policyViolations, lintFindings int
+	consumption := estimate.Consumption{}
+
+	// a single client is used for the whole environment, so that the configs of an api are only listed once
+	deprecations := rest.NewDeprecations()
+	options.clientOptions.Deprecations = deprecations
+
+	var client rest.DynatraceClient
+	var available *capabilities
+	if !options.dryRun {
+		var err error
+		client, err = createClient(environment, options.clientOptions)
+		if err != nil {
+			return err
+		}
+		available = newCapabilities(client)
+		options.available = available
+		environment = probeVersion(client, environment)
+		options.clientOptions.Replay.SetVersion(environment.GetEnvironmentUrl(), environment.GetVersion())
+	} else if options.detectCoManaged || options.validateOnServer || hasPinnedConfigs(projects, environment) {
+		var err error
+		client, err = createClient(environment, options.clientOptions)
+		if err != nil {
+			return err
+		}
+	}
+
+	var serverValidation *settingsValidation
+	if options.dryRun && options.validateOnServer {
+		serverValidation = newSettingsValidation(client)
+	}
+
+	var coManaged *coManagedDetector
+	if options.detectCoManaged {
+		var err error
+		coManaged, err = newCoManagedDetector(client, environment, time.Now())
+		if err != nil {
+			return fmt.Errorf("reading the audit log of %s failed: %s", environment.GetId(), err)
+		}
+	}
+	coManagedConfigs := 0
+
+	// dry runs look the objects of the configs up while they are planned, so all apis are listed at once upfront
+	if options.dryRun && coManaged != nil {
+		var configs []config.Config
+		for _, p := range projects {
+			configs = append(configs, p.GetConfigs()...)
+		}
+		prefetchLists(client, configs, environment)
+	}
+
+	skip := func(config config.Config) error {
+		options.summary.skipped(environment.GetId())
+		return options.results.Report(deploy.Result{
+			Environment: environment.GetId(),
+			Config:      configReference(config, options.path),
+			Result:      deploy.Skipped,
+		})
+	}
 
 	for _, project := range projects {
 
@@ -203,8 +887,24 @@ func execute(environment environment.Environment, projects []project.Project, dr
 			var entity api.DynatraceEntity
 			var err error
 
+			if options.interrupts.isInterrupted() {
+				options.checkpoint.SetRemaining(environment.GetId(), remainingConfigs(projects, environment, options))
+				return errInterrupted
+			}
+
 			if config.IsSkipDeployment(environment) {
 				util.Log.Info("\t\t\tskipping deployment of %s: %s", config.GetId(), config.GetFilePath())
+				if err := skip(config); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !options.ownership.matchesConfig(config, environment) {
+				util.Log.Debug("\t\t\tskipping deployment of %s, it does not match the owner and labels: %s", config.GetId(), config.GetFilePath())
+				if err := skip(config); err != nil {
+					return err
+				}
 				continue
 			}
 
@@ -219,25 +919,255 @@ func execute(environment environment.Environment, projects []project.Project, dr
 			}
 			nameDict[name] = configID
 
-			if dryRun {
+			referenceId := configReference(config, options.path)
+			if completed, found := options.checkpoint.Completed(environment.GetId(), referenceId); found {
+				util.Log.Debug("\t\t\tskipping %s, it was deployed before the interruption", config.GetFilePath())
+				if completed.Name != "" {
+					dict[referenceId] = completed
+				}
+				if err := skip(config); err != nil {
+					return err
+				}
+				continue
+			}
+
+			supported, err := available.supports(config, environment, dict)
+			if err != nil {
+				return err
+			}
+			if !supported {
+				util.Log.Info("\t\t\tskipping deployment of %s, it is not supported by the environment: %s", config.GetId(), config.GetFilePath())
+				if err := skip(config); err != nil {
+					return err
+				}
+				continue
+			}
+
+			_, converted, err := successorFor(config, options)
+			if err != nil {
+				return err
+			}
+			if converted {
+				deployedToSuccessors[referenceId] = true
+			} else if err := checkSuccessorReferences(config, dict, deployedToSuccessors); err != nil {
+				return err
+			}
+
+			payload, err := renderPayload(config, dict, environment)
+			if err != nil {
+				return err
+			}
+
+			violations, err := checkPolicies(options.policies, config, payload, dict, environment)
+			if err != nil {
+				return err
+			}
+
+			// during validation all violations are reported, a deployment stops at the first violating config
+			policyViolations += len(violations)
+			if policyViolations > 0 && !options.dryRun {
+				return fmt.Errorf("%d policy violations found in %s", policyViolations, config.GetFilePath())
+			}
+
+			warnUnknownFields(config, payload, options.fieldOverrides)
+
+			if len(coManaged.check(config, environment, dict)) > 0 {
+				coManagedConfigs++
+				options.summary.coManaged(environment.GetId(), configReference(config, options.path))
+			}
+
+			if options.dryRun {
+				lintFindings += len(lintConfig(options.linter, config, payload, options.strictLint))
+				consumption.Add(config.GetType(), payload)
+
 				entity, err = validateConfig(project, config, dict, environment)
+				if err == nil && config.GetApi().GetId() == settingsApi {
+					err = serverValidation.add(config, environment, dict)
+				}
+				if err == nil {
+					err = validateSuccessorConversion(config, dict, environment, options)
+				}
+				if err == nil {
+					err = checkPinnedRemoteOfDryRun(client, config, dict, environment, options)
+				}
 			} else {
-				entity, err = uploadConfig(config, dict, environment)
+				entity, err = uploadConfig(client, config, dict, environment, options)
 			}
 
 			if err != nil {
+				if reportErr := options.results.Report(deploy.Result{Environment: environment.GetId(), Config: referenceId, Result: deploy.Failed, Error: err.Error()}); reportErr != nil {
+					return reportErr
+				}
 				return err
 			}
 
-			referenceId := strings.TrimPrefix(config.GetFullQualifiedId(), path)
+			if !options.dryRun {
+				options.checkpoint.Complete(environment.GetId(), referenceId, entity)
+			}
+			options.summary.deployed(environment.GetId())
+
+			result := deploy.Result{Environment: environment.GetId(), Config: referenceId, Result: deploy.Deployed, Id: entity.Id}
+			if options.dryRun {
+				result.Result = deploy.Valid
+			}
+			err = options.results.Report(result)
+			if err != nil {
+				return err
+			}
 			if entity.Name != "" {
 				dict[referenceId] = entity
 			}
 		}
 	}
+
+	if !options.dryRun {
+		err := evaluateAssertions(client, projects, createApis())
+		if err != nil {
+			return err
+		}
+	}
+
+	if options.dryRun {
+		logConsumption(environment, consumption)
+	}
+
+	rejected, err := serverValidation.validate()
+	if err != nil {
+		return fmt.Errorf("validation of settings by %s failed: %s", environment.GetId(), err)
+	}
+	for _, rejection := range rejected {
+		util.Log.Error("\t\tSettings config rejected by environment %s: %s", environment.GetId(), rejection)
+	}
+
+	if coManagedConfigs > 0 {
+		util.Log.Warn("\t%d configs of environment %s are co-managed by other tools or users", coManagedConfigs, environment.GetId())
+	}
+
+	err = reportDeprecations(environment, deprecations.List(), options)
+	if err != nil {
+		return err
+	}
+
+	if policyViolations > 0 {
+		return fmt.Errorf("%d policy violations found", policyViolations)
+	}
+	if lintFindings > 0 && options.strictLint {
+		return fmt.Errorf("%d lint rule violations found", lintFindings)
+	}
+	if lintFindings > 0 {
+		util.Log.Warn("%d lint rule violations found", lintFindings)
+	}
+	if len(rejected) > 0 {
+		return fmt.Errorf("%d settings configs were rejected by the environment", len(rejected))
+	}
 	return nil
 }
 
+// renderPayload renders the config for the given environment and parses it. Returns a nil payload
+// if the config is no valid json object, which is reported by the validation
+func renderPayload(config config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment) (payload map[string]interface{}, err error) {
+
+	jsonString, err := config.GetConfigForEnvironment(environment, dict)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal([]byte(jsonString), &payload)
+	if err != nil {
+		return nil, nil
+	}
+
+	return payload, nil
+}
+
+// lintConfig checks the rendered config against the enabled lint rules and logs the findings, as errors if they fail
+// the validation and as warnings otherwise
+func lintConfig(linter lint.Linter, config config.Config, payload map[string]interface{}, strict bool) (findings []string) {
+
+	if payload == nil {
+		return nil
+	}
+
+	findings = linter.Lint(config.GetType(), payload)
+
+	for _, finding := range findings {
+		if strict {
+			util.Log.Error("\t\tLint rule violation in %s: %s", config.GetFilePath(), finding)
+		} else {
+			util.Log.Warn("\t\tLint rule violation in %s: %s", config.GetFilePath(), finding)
+		}
+	}
+
+	return findings
+}
+
+// warnUnknownFields logs the fields of the rendered config which the api does not know and would silently ignore
+func warnUnknownFields(config config.Config, payload map[string]interface{}, fields api.FieldOverrides) (unknown []api.UnknownField) {
+
+	if payload == nil {
+		return nil
+	}
+
+	unknown = fields.UnknownFields(config.GetApi().GetId(), payload)
+
+	for _, field := range unknown {
+		if field.Suggestion != "" {
+			util.Log.Warn("\t\t%s contains field %s, which %s ignores (did you mean %s?)", config.GetFilePath(), field.Field, config.GetApi().GetId(), field.Suggestion)
+		} else {
+			util.Log.Warn("\t\t%s contains field %s, which %s ignores", config.GetFilePath(), field.Field, config.GetApi().GetId())
+		}
+	}
+
+	return unknown
+}
+
+// checkPolicies evaluates all policies against the rendered config and logs the violations
+func checkPolicies(policies policy.Engine, config config.Config, payload map[string]interface{}, dict map[string]api.DynatraceEntity, environment environment.Environment) (violations []string, err error) {
+
+	if payload == nil {
+		return nil, nil
+	}
+
+	name, err := config.GetObjectNameForEnvironment(environment, dict)
+	if err != nil {
+		return nil, err
+	}
+
+	violations, err = policies.Evaluate(policy.Input{
+		Api:         config.GetType(),
+		Project:     config.GetProject(),
+		Config:      config.GetId(),
+		Name:        name,
+		Owner:       config.GetOwner(environment),
+		Environment: environment.GetId(),
+		Group:       environment.GetGroup(),
+		Payload:     payload,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("evaluation of policies for %s failed: %s", config.GetFilePath(), err)
+	}
+
+	for _, violation := range violations {
+		util.Log.Error("\t\tPolicy violation in %s: %s", config.GetFilePath(), violation)
+	}
+
+	return violations, nil
+}
+
+// logConsumption logs the estimated consumption impact of all validated configs of an environment
+func logConsumption(environment environment.Environment, consumption estimate.Consumption) {
+
+	if consumption.IsEmpty() {
+		return
+	}
+
+	util.Log.Info("\tEstimated consumption impact of the configs for environment %s:", environment.GetId())
+	util.Log.Info("\t\tsynthetic browser monitor executions per month: %d", consumption.BrowserMonitorExecutionsPerMonth)
+	util.Log.Info("\t\tsynthetic HTTP monitor executions per month: %d", consumption.HttpMonitorExecutionsPerMonth)
+	util.Log.Info("\t\tmetric events: %d", consumption.MetricEvents)
+	util.Log.Info("\t\tcalculated metrics: %d", consumption.CalculatedMetrics)
+}
+
 func validateConfig(project project.Project, config config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment) (entity api.DynatraceEntity, err error) {
 	util.Log.Debug("\t\tValidating config " + config.GetFilePath())
 
@@ -285,6 +1215,24 @@ func validateConfig(project project.Project, config config.Config, dict map[stri
 	}
 
 	err = validateConfigJson(jsonString, config.GetFilePath())
+	if err == nil {
+		err = validatePayloadSize(jsonString, config)
+	}
+	if err == nil && config.GetApi().GetId() == settingsApi {
+		_, err = readSettingsProperties(config, environment, dict)
+	}
+	if err == nil && config.GetApi().GetId() == autoTagApi {
+		err = validateAutoTag(jsonString, config.GetFilePath())
+	}
+	if err == nil && config.GetApi().GetId() == notificationApi {
+		err = validateNotification(jsonString, config.GetFilePath())
+	}
+	if err == nil {
+		_, err = config.GetRequestOverrides(environment)
+	}
+	if err == nil {
+		_, err = mergesUpdates(config, environment)
+	}
 
 	return api.DynatraceEntity{
 		Id:          randomId,
@@ -293,38 +1241,174 @@ func validateConfig(project project.Project, config config.Config, dict map[stri
 	}, err
 }
 
-func uploadConfig(config config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment) (entity api.DynatraceEntity, err error) {
+func uploadConfig(client rest.DynatraceClient, config config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment, options executionOptions) (entity api.DynatraceEntity, err error) {
 	util.Log.Debug("\t\tApplying config " + config.GetFilePath())
 
-	configType := config.GetType()
-	url := config.GetApi().GetUrl(environment)
-	apiToken, err := environment.GetToken()
+	jsonString, err := config.GetConfigForEnvironment(environment, dict)
 	if err != nil {
 		return entity, err
 	}
-	jsonString, err := config.GetConfigForEnvironment(environment, dict)
+
+	overrides, err := config.GetRequestOverrides(environment)
 	if err != nil {
 		return entity, err
 	}
+	if !overrides.IsEmpty() {
+		util.Log.Debug("\t\t\tSending the requests of %s with overridden timeout or retries", config.GetFilePath())
+		client = rest.WithOverrides(client, overrides.Timeout, overrides.Retries)
+	}
+
+	if options.rewriter != nil {
+		var rewritten int
+		jsonString, rewritten = options.rewriter.Rewrite(jsonString, environment)
+		if rewritten > 0 {
+			util.Log.Debug("\t\t\tRewrote %d urls of other environments in %s", rewritten, config.GetFilePath())
+		}
+	}
+
+	err = validateConfigJson(jsonString, config.GetFilePath())
+	if err != nil {
+		return entity, err
+	}
+
+	// extensions are uploaded as they are, their payload is packaged and not an object of the api
+	if config.GetApi().GetId() != extensionApi {
+		var stripped int
+		jsonString, stripped, err = stripServerManagedFields(config.GetApi().GetId(), jsonString, options.fieldOverrides)
+		if err != nil {
+			return entity, fmt.Errorf("%s, responsible config: %s", err.Error(), config.GetFilePath())
+		}
+		if stripped > 0 {
+			util.Log.Debug("\t\t\tStripped %d server managed fields from %s", stripped, config.GetFilePath())
+		}
+	}
+
+	err = validatePayloadSize(jsonString, config)
+	if err != nil {
+		return entity, err
+	}
+
+	if config.GetApi().GetId() == autoTagApi {
+		err = validateAutoTag(jsonString, config.GetFilePath())
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	if config.GetApi().GetId() == notificationApi {
+		err = validateNotification(jsonString, config.GetFilePath())
+		if err != nil {
+			return entity, err
+		}
+	}
 
 	name, err := config.GetObjectNameForEnvironment(environment, dict)
 	if err != nil {
 		return entity, err
 	}
 
-	switch configType {
-	case "extension":
-		entity, err = rest.UploadExtension(url, name, jsonString, apiToken)
-	default:
-		entity, err = rest.UpsertDynatraceObject(url, name, configType, jsonString, apiToken)
+	coordinates := filepath.ToSlash(strings.TrimPrefix(config.GetFullQualifiedId(), options.path))
+
+	successor, converted, err := successorFor(config, options)
+	if err != nil {
+		return entity, err
+	}
+	if converted {
+		return uploadToSuccessor(client, config, successor, jsonString, name, coordinates)
 	}
 
+	accepted, entity, err := checkPinnedRemote(client, config, name, environment, options)
+	if err != nil || accepted {
+		return entity, err
+	}
+
+	payload := []byte(jsonString)
+	if config.GetApi().GetId() == extensionApi {
+		payload, err = extensionPayload(config.GetFilePath(), jsonString)
+		if err != nil {
+			return entity, fmt.Errorf("%s, responsible config: %s", err.Error(), config.GetFilePath())
+		}
+	}
+
+	merge, err := mergesUpdates(config, environment)
+	if err != nil {
+		return entity, err
+	}
+
+	if config.GetApi().GetId() == settingsApi {
+		entity, err = uploadSettings(client, config, jsonString, name, coordinates, environment, dict, options.settingsMigrations)
+		if err != nil {
+			err = fmt.Errorf("%w, responsible config: %s", err, config.GetFilePath())
+		}
+		return entity, err
+	}
+
+	entity, err = deployByIdentity(client, config, name, coordinates, payload, merge, environment, dict, options)
+
+	if err == nil && options.state != nil && entity.Id != "" {
+		options.state.Set(environment.GetId(), config.GetApi().GetId(), name, entity.Id)
+	}
+	if err == nil && entity.Id != "" && config.GetPinnedRemote(environment) != "" {
+		refreshPin(client, config, entity, environment, options.fieldOverrides)
+	}
+	if err == nil && options.testNotifications && config.GetApi().GetId() == notificationApi {
+		err = testNotification(jsonString, config.GetFilePath())
+	}
 	if err != nil {
-		err = fmt.Errorf("%s, responsible config: %s", err.Error(), config.GetFilePath())
+		err = fmt.Errorf("%w, responsible config: %s", err, config.GetFilePath())
 	}
 	return entity, err
 }
 
+// deployByIdentity resolves the existing objects of the config once and deploys the payload to them. The payload is
+// merged onto the existing object first, if the update strategy of the config asks for it
+func deployByIdentity(client rest.DynatraceClient, config config.Config, name string, coordinates string, payload []byte,
+	merge bool, environment environment.Environment, dict map[string]api.DynatraceEntity, options executionOptions) (entity api.DynatraceEntity, err error) {
+
+	a := config.GetApi()
+
+	target, err := resolveTarget(client, config, name, coordinates, environment, dict, options)
+	if err != nil {
+		return entity, err
+	}
+
+	if merge {
+		payload, err = mergeWithExisting(client, a, name, target, payload, options.fieldOverrides)
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	if options.ownershipMarkers && a.IsMarkable() {
+		payload, err = withOwnershipMarker(payload, ownershipMarker(coordinates))
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	payload, err = withIdentity(a, coordinates, payload)
+	if err != nil {
+		return entity, err
+	}
+
+	entity, err = deployToTarget(client, a, name, target, payload)
+	if err == nil && target.renamedFrom != "" && options.state != nil {
+		options.state.Remove(environment.GetId(), a.GetId(), target.renamedFrom)
+	}
+	return entity, err
+}
+
+// createClient creates a client for the given environment, which fails if no token is available
+func createClient(environment environment.Environment, options rest.ClientOptions) (rest.DynatraceClient, error) {
+
+	token, err := environment.GetToken()
+	if err != nil {
+		return nil, err
+	}
+
+	return rest.NewDynatraceClientWithOptions(environment.GetEnvironmentUrl(), token, options)
+}
+
 /* validates whether the json file is correct, by using the internal validation done
  * when unmarshalling to a an object. As none of our jsons can actually be unmarshalled
  * to a string, we catch that error, but report any other error as fatal.
@@ -334,27 +1418,213 @@ func validateConfigJson(jsonString string, filename string) error {
 	err := json.Unmarshal([]byte(jsonString), &j)
 
 	if err != nil && !strings.Contains(err.Error(), "cannot unmarshal") {
+		if syntaxError, ok := err.(*json.SyntaxError); ok {
+			line, column, snippet := locateOffset(jsonString, syntaxError.Offset)
+			return fmt.Errorf("%s is not a valid json! Error: %s at line %d, column %d (after rendering):\n%s", filename, err.Error(), line, column, snippet)
+		}
 		return fmt.Errorf("%s is not a valid json! Error: %s", filename, err.Error())
 	}
 
 	return nil
 }
 
-// deleteConfigs deletes specified configs, if a delete.yaml file was found
-func deleteConfigs(apis map[string]api.Api, environments map[string]environment.Environment, path string, dryRun bool, fileReader util.FileReader) {
+// validatePayloadSize checks that the rendered config does not exceed the payload size accepted by its api,
+// which would otherwise be rejected by the server with a rather unhelpful error
+func validatePayloadSize(jsonString string, config config.Config) error {
+
+	size := len(jsonString)
+	limit := config.GetApi().GetPayloadSizeLimit()
+
+	if size <= limit {
+		return nil
+	}
+
+	hint := "reduce the size of the config"
+	if config.GetType() == "dashboard" {
+		hint = "split the dashboard into multiple dashboards or remove tiles"
+	}
+
+	return fmt.Errorf("%s is too large: the rendered config has %d KiB, but %s accepts at most %d KiB, %s",
+		config.GetFilePath(), (size+1023)/1024, config.GetType(), limit/1024, hint)
+}
+
+// locateOffset returns the line and column of the given byte offset within the rendered json, as well as
+// the line itself with a marker below the column
+func locateOffset(jsonString string, offset int64) (line int, column int, snippet string) {
+
+	if offset > int64(len(jsonString)) {
+		offset = int64(len(jsonString))
+	}
+
+	before := jsonString[:offset]
+	line = strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndex(before, "\n") + 1
+	column = int(offset) - lineStart
+
+	lineEnd := strings.Index(jsonString[lineStart:], "\n")
+	if lineEnd < 0 {
+		lineEnd = len(jsonString) - lineStart
+	}
+	content := strings.TrimRight(jsonString[lineStart:lineStart+lineEnd], "\r")
+
+	indent := column - 1
+	if indent < 0 {
+		indent = 0
+	}
+	return line, column, "\t" + content + "\n\t" + strings.Repeat(" ", indent) + "^"
+}
+
+// deletionPlan holds the configs to delete from a single environment
+type deletionPlan struct {
+	environment environment.Environment
+	client      rest.DynatraceClient
+	targets     []delete.Target
+}
+
+// deleteOptions bundles the settings of deleting the configs specified in the delete.yaml file
+type deleteOptions struct {
+	dryRun           bool
+	state            *state.State
+	clientOptions    rest.ClientOptions
+	backupFolder     string
+	environmentsFile string
+
+	// allowProtected deletes the objects of configs marked as protected, which are skipped otherwise
+	allowProtected bool
+
+	// protected holds the configs of all projects, to find those marked as protected
+	protected []project.Project
+
+	// safetyLevels classifies the apis by the impact of deletions, high impact deletions have to be confirmed
+	// unless allowHighImpact is set
+	safetyLevels    delete.SafetyLevels
+	allowHighImpact bool
+
+	// ownership restricts the deleted configs to the entries of an owner or with labels
+	ownership ownershipFilter
+
+	// deployed holds the projects deployed by the run, whose objects deletions are previewed for
+	deployed []project.Project
+
+	// summary counts the deleted configs per environment, may be nil
+	summary *runSummary
+}
+
+// deleteConfigs deletes the configs specified in the delete.yaml file, if one was found. The configs to delete are
+// resolved and shown for all environments, before the first config is deleted. In dry-run mode, only the configs
+// which would be deleted are shown, including the objects the deployment would create. Configs are backed up before they are deleted, environments whose configs could
+// not be backed up are skipped. Deletions of high impact apis are skipped unless they are confirmed or allowed, which
+// fails the run after the other configs were deleted
+func deleteConfigs(apis map[string]api.Api, environments map[string]environment.Environment, path string, options deleteOptions, fileReader util.FileReader) error {
 
-	configs, err := delete.LoadConfigsToDelete(apis, path, fileReader)
+	entries, err := delete.LoadEntriesToDelete(apis, path, fileReader)
 	util.FailOnError(err, "deletion failed")
 
-	if len(configs) > 0 && !dryRun {
+	entries = options.ownership.filterEntries(entries)
 
-		for name, environment := range environments {
-			util.Log.Info("Deleting %d configs for environment %s...", len(configs), name)
+	if len(entries) == 0 {
+		return nil
+	}
+
+	deployState := options.state
+
+	// configs of all projects protect their objects, not only the ones of the projects deployed
+	if !options.allowProtected {
+		options.protected, err = project.LoadProjectsToDeploy("", apis, path, fileReader)
+		util.FailOnError(err, "loading of protected configs failed")
+	}
+
+	plans := planDeletions(entries, environments, options)
+	if options.dryRun {
+		return nil
+	}
+
+	withheld := 0
+	if !options.allowHighImpact {
+		plans, withheld = confirmHighImpactDeletions(plans, options.safetyLevels, newStdinPrompter())
+	}
 
-			for _, config := range configs {
-				util.Log.Debug("\tDeleting config " + config.GetId() + " (" + config.GetApi().GetId() + ")")
-				_ = rest.DeleteDynatraceObject(config, environment)
+	for _, plan := range plans {
+		if len(plan.targets) == 0 {
+			continue
+		}
+
+		archive, err := backupConfigs(plan, options.backupFolder)
+		if err != nil {
+			util.Log.Error("\tBackup of configs to delete from environment %s failed, skipping their deletion: %s", plan.environment.GetId(), err)
+			continue
+		}
+		util.Log.Info("Backed up configs to delete from environment %s to %s", plan.environment.GetId(), archive)
+		util.Log.Info("Restore them with: monaco restore -e=%s -se=%s %s", options.environmentsFile, plan.environment.GetId(), archive)
+
+		util.Log.Info("Deleting %d configs for environment %s...", len(plan.targets), plan.environment.GetId())
+
+		for _, target := range plan.targets {
+			util.Log.Debug("\tDeleting config %s (%s) of %s", target.Name, target.Id, target.Api.GetId())
+			err = plan.client.DeleteById(target.Api, target.Id)
+			if err != nil {
+				util.Log.Warn("\tFailed to delete config %s (%s) of %s: %s", target.Name, target.Id, target.Api.GetId(), err)
+				options.summary.deleted(plan.environment.GetId(), err)
+				continue
+			}
+			options.summary.deleted(plan.environment.GetId(), nil)
+
+			if deployState != nil {
+				deployState.Remove(plan.environment.GetId(), target.Api.GetId(), target.Name)
 			}
 		}
 	}
+
+	if withheld > 0 {
+		return fmt.Errorf("%d deletions of high impact configs were not confirmed, pass --allow-high-impact-deletions to delete them", withheld)
+	}
+	return nil
+}
+
+// planDeletions resolves the delete entries for every environment and logs the configs which are going to be deleted
+func planDeletions(entries []delete.Entry, environments map[string]environment.Environment, options deleteOptions) []deletionPlan {
+
+	ids := sortedEnvironmentIds(environments)
+
+	plans := make([]deletionPlan, 0, len(ids))
+	for _, id := range ids {
+		environment := environments[id]
+
+		client, err := createClient(environment, options.clientOptions)
+		if err != nil {
+			util.Log.Error("\tFailed to plan deletion of configs for environment %s: %s", id, err)
+			continue
+		}
+
+		targets, err := delete.PlanDeletion(entries, id, client, options.state, options.clientOptions.NameMatching)
+		if err != nil {
+			util.Log.Error("\tFailed to plan deletion of configs for environment %s: %s", id, err)
+			continue
+		}
+
+		// a dry run creates no objects, so deletions of objects the deployment would create are added to the preview
+		deployed := deployedObjects(options.deployed, environment, options.ownership)
+		if options.dryRun {
+			targets = append(targets, delete.PlanDeletionOfCreated(entries, id, deployed, targets, options.clientOptions.NameMatching)...)
+		}
+
+		if !options.allowProtected {
+			protected, err := protectedObjects(options.protected, environment, options.clientOptions.NameMatching)
+			if err != nil {
+				util.Log.Error("\tFailed to plan deletion of configs for environment %s: %s", id, err)
+				continue
+			}
+			targets = withoutProtected(targets, protected, options.clientOptions.NameMatching)
+		}
+
+		logDeletionTargets(id, targets, deployed)
+
+		if highImpact := options.safetyLevels.HighImpactTargets(targets); len(highImpact) > 0 && !options.allowHighImpact {
+			util.Log.Warn("\t%d of these configs are of high impact, their deletion has to be confirmed or allowed with --allow-high-impact-deletions", len(highImpact))
+		}
+
+		plans = append(plans, deletionPlan{environment: environment, client: client, targets: targets})
+	}
+
+	return plans
 }
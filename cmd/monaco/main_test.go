@@ -19,13 +19,22 @@
 package main
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/lint"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/golang/mock/gomock"
 	"gotest.tools/assert"
 )
 
@@ -66,6 +75,15 @@ func TestReadLongPath(t *testing.T) {
 	assert.Equal(t, path, location+string(os.PathSeparator))
 }
 
+func TestReadAbsolutePath(t *testing.T) {
+
+	workingDir, err := os.Getwd()
+	assert.NilError(t, err)
+
+	path := readPath([]string{"monaco", "--environments", "my-file.yaml", filepath.Join(workingDir, "test-resources")}, util.NewFileReader())
+	assert.Equal(t, path, "test-resources"+string(os.PathSeparator))
+}
+
 func TestReadPathNoDirectory(t *testing.T) {
 
 	path := readPath([]string{"monaco", "--environments", "my-file.yaml", "main.go"}, util.NewFileReader())
@@ -84,6 +102,15 @@ func testGetExecuteApis() map[string]api.Api {
 	apis["alerting-profile"] = api.NewApi("alerting-profile", "/api")
 	return apis
 }
+func testValidationOptions() executionOptions {
+	return executionOptions{
+		dryRun:   true,
+		path:     "",
+		policies: policy.NoPolicies(),
+		linter:   lint.NoRules(),
+	}
+}
+
 func TestExecuteFailOnDuplicateNamesWithinSameConfig(t *testing.T) {
 	environment := environment.NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV")
 
@@ -93,7 +120,7 @@ func TestExecuteFailOnDuplicateNamesWithinSameConfig(t *testing.T) {
 	projects, err := project.LoadProjectsToDeploy("project1", apis, path, util.NewFileReader())
 	assert.NilError(t, err)
 
-	err = execute(environment, projects, true, "")
+	err = execute(environment, projects, testValidationOptions())
 	assert.ErrorContains(t, err, "duplicate UID 'calculated-metrics-log/metric' found in")
 }
 
@@ -106,7 +133,7 @@ func TestExecutePassOnDifferentApis(t *testing.T) {
 	projects, err := project.LoadProjectsToDeploy("project2", apis, path, util.NewFileReader())
 	assert.NilError(t, err)
 
-	err = execute(environment, projects, true, "")
+	err = execute(environment, projects, testValidationOptions())
 	assert.NilError(t, err)
 }
 
@@ -119,7 +146,7 @@ func TestExecuteFailOnDuplicateNamesInDifferentProjects(t *testing.T) {
 	projects, err := project.LoadProjectsToDeploy("project1, project2", apis, path, util.NewFileReader())
 	assert.NilError(t, err)
 
-	err = execute(environment, projects, true, "")
+	err = execute(environment, projects, testValidationOptions())
 	assert.ErrorContains(t, err, "duplicate UID 'calculated-metrics-log/metric' found in")
 }
 
@@ -133,9 +160,36 @@ func TestExecutePassOnDuplicateNamesInDifferentEnvironments(t *testing.T) {
 	projects, err := project.LoadProjectsToDeploy("project5", apis, path, util.NewFileReader())
 	assert.NilError(t, err)
 
-	err = execute(environmentDev, projects, true, "")
+	err = execute(environmentDev, projects, testValidationOptions())
 	assert.NilError(t, err)
-	err = execute(environmentProd, projects, true, "")
+	err = execute(environmentProd, projects, testValidationOptions())
+	assert.NilError(t, err)
+}
+
+func TestExecuteFailsOnLintRuleViolationIfStrict(t *testing.T) {
+	environment := environment.NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV")
+
+	apis := map[string]api.Api{"management-zone": api.NewApi("management-zone", "/api")}
+
+	path := util.ReplacePathSeparators("test-resources/lint-test")
+	projects, err := project.LoadProjectsToDeploy("project", apis, path, util.NewFileReader())
+	assert.NilError(t, err)
+
+	options := testValidationOptions()
+	options.linter, err = lint.NewLinter(nil)
+	assert.NilError(t, err)
+
+	err = execute(environment, projects, options)
+	assert.NilError(t, err)
+
+	options.strictLint = true
+	err = execute(environment, projects, options)
+	assert.ErrorContains(t, err, "1 lint rule violations found")
+
+	options.linter, err = lint.NewLinter(splitList("management-zone-without-rules, "))
+	assert.NilError(t, err)
+
+	err = execute(environment, projects, options)
 	assert.NilError(t, err)
 }
 
@@ -146,3 +200,67 @@ func TestExecutePassOnDuplicateNamesInDifferentEnvironments(t *testing.T) {
 // }
 
 // TODO (CDF-6511) add tests when execute failures of single environments don't crash program anymore
+
+func TestValidateConfigJsonReportsLocationOfSyntaxError(t *testing.T) {
+
+	err := validateConfigJson("{\n  \"name\": \"zone\",\n  \"rules\": [}\n}", "project/management-zone/zone.json")
+	assert.Error(t, err, "project/management-zone/zone.json is not a valid json! Error: invalid character '}' looking for beginning of value at line 3, column 13 (after rendering):\n\t  \"rules\": [}\n\t            ^")
+}
+
+func TestValidateConfigJsonReportsIncompleteJson(t *testing.T) {
+
+	err := validateConfigJson("{\n  \"name\": \"zone\"", "zone.json")
+	assert.ErrorContains(t, err, "unexpected end of JSON input at line 2, column 16")
+}
+
+func TestValidatePayloadSizeFailsForTooLargeDashboard(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	dashboard := config.NewMockConfig(mockCtrl)
+	dashboard.EXPECT().GetApi().Return(api.NewApi("dashboard", "/api/config/v1/dashboards")).AnyTimes()
+	dashboard.EXPECT().GetType().Return("dashboard").AnyTimes()
+	dashboard.EXPECT().GetFilePath().Return("project/dashboard/overview.json").AnyTimes()
+
+	assert.NilError(t, validatePayloadSize(`{"tiles": []}`, dashboard))
+
+	err := validatePayloadSize(`{"tiles": "`+strings.Repeat("x", 600*1024)+`"}`, dashboard)
+	assert.Error(t, err, "project/dashboard/overview.json is too large: the rendered config has 601 KiB, but dashboard accepts at most 512 KiB, split the dashboard into multiple dashboards or remove tiles")
+}
+
+func TestRewriteUrlsFailsBeforeDeployingIfEnvironmentsCanNotBeLoaded(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-rewrite-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	err = writeBenchProject(folder, apis, 3)
+	assert.NilError(t, err)
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	assert.NilError(t, err)
+
+	// the environment without url is not deployed to, but its urls could not be rewritten
+	environmentsFile := filepath.Join(folder, "environments.yaml")
+	err = ioutil.WriteFile(environmentsFile, []byte(fmt.Sprintf(`bench:
+    - name: "bench"
+    - env-url: "%s"
+    - env-token-name: "%s"
+broken:
+    - name: "broken"
+    - env-token-name: "%s"
+`, server.URL, benchTokenEnv, benchTokenEnv)), 0664)
+	assert.NilError(t, err)
+
+	statusCode := RunImpl([]string{"monaco", "--rewrite-urls", "-e", environmentsFile, "-se", "bench", "-p", benchProject, folder}, util.NewFileReader())
+
+	assert.Equal(t, statusCode, -1)
+	assert.Equal(t, server.Objects("management-zone"), 0)
+}
@@ -0,0 +1,49 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// defaultMaintenanceDelay is long enough for the short maintenance windows of SaaS environments
+const defaultMaintenanceDelay = 5 * time.Minute
+
+// maintenanceSleep waits before retrying the deployment to an unavailable environment, it is replaced in tests
+var maintenanceSleep = time.Sleep
+
+// retryDuringMaintenance runs the deployment to the environment and retries it up to retries times after the given
+// delay, as long as it fails because the environment is unavailable. As deploying configs is idempotent, the
+// deployment is simply started from the beginning again
+func retryDuringMaintenance(environment environment.Environment, retries int, delay time.Duration, deploy func() error) error {
+
+	for attempt := 1; ; attempt++ {
+
+		err := deploy()
+		if err == nil || attempt > retries || !rest.IsMaintenance(err) {
+			return err
+		}
+
+		util.Log.Warn("Environment %s is unavailable (HTTP 503), likely due to maintenance. Retrying its deployment in %s (%d/%d)...",
+			environment.GetId(), delay, attempt, retries)
+		maintenanceSleep(delay)
+	}
+}
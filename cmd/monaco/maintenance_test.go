@@ -0,0 +1,89 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+var maintenanceTestEnvironment = environment.NewEnvironment("production", "production", "", "https://production", "TOKEN")
+
+func stubMaintenanceSleep(t *testing.T) *[]time.Duration {
+
+	var waits []time.Duration
+	maintenanceSleep = func(d time.Duration) { waits = append(waits, d) }
+	t.Cleanup(func() { maintenanceSleep = time.Sleep })
+
+	return &waits
+}
+
+func TestRetryDuringMaintenanceRetriesUnavailableEnvironments(t *testing.T) {
+
+	waits := stubMaintenanceSleep(t)
+
+	attempts := 0
+	err := retryDuringMaintenance(maintenanceTestEnvironment, 3, time.Minute, func() error {
+		attempts++
+		if attempts < 3 {
+			return fmt.Errorf("%w, responsible config: zone.json", rest.ResponseError{StatusCode: 503})
+		}
+		return nil
+	})
+
+	assert.NilError(t, err)
+	assert.Equal(t, attempts, 3)
+	assert.DeepEqual(t, *waits, []time.Duration{time.Minute, time.Minute})
+}
+
+func TestRetryDuringMaintenanceGivesUpAfterRetries(t *testing.T) {
+
+	waits := stubMaintenanceSleep(t)
+
+	attempts := 0
+	err := retryDuringMaintenance(maintenanceTestEnvironment, 2, time.Minute, func() error {
+		attempts++
+		return rest.ResponseError{StatusCode: 503}
+	})
+
+	assert.Assert(t, rest.IsMaintenance(err))
+	assert.Equal(t, attempts, 3)
+	assert.Equal(t, len(*waits), 2)
+}
+
+func TestRetryDuringMaintenanceDoesNotRetryOtherErrors(t *testing.T) {
+
+	waits := stubMaintenanceSleep(t)
+
+	attempts := 0
+	err := retryDuringMaintenance(maintenanceTestEnvironment, 2, time.Minute, func() error {
+		attempts++
+		return errors.New("invalid config")
+	})
+
+	assert.Error(t, err, "invalid config")
+	assert.Equal(t, attempts, 1)
+	assert.Equal(t, len(*waits), 0)
+}
@@ -0,0 +1,117 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// markerPattern matches ownership markers within descriptions
+var markerPattern = regexp.MustCompile(`\s*\[monaco:[0-9a-f-]+\]`)
+
+// ownershipMarker returns the marker identifying the objects deployed by the config with the given coordinates
+func ownershipMarker(coordinates string) string {
+	return "[monaco:" + util.DeterministicUuid(coordinates) + "]"
+}
+
+// withOwnershipMarker appends the marker to the description of the payload, replacing markers it already contains
+func withOwnershipMarker(payload []byte, marker string) ([]byte, error) {
+
+	var content map[string]interface{}
+	err := json.Unmarshal(payload, &content)
+	if err != nil {
+		return nil, fmt.Errorf("ownership marker can not be set: %s", err)
+	}
+
+	description, _ := content["description"].(string)
+	description = strings.TrimSpace(markerPattern.ReplaceAllString(description, ""))
+	if description == "" {
+		content["description"] = marker
+	} else {
+		content["description"] = description + " " + marker
+	}
+
+	return json.Marshal(content)
+}
+
+// targetByOwnershipMarker selects the object carrying the ownership marker of the config, regardless of its name.
+// The target does not exist if no object carries the marker yet, the object has to be found by name then
+func targetByOwnershipMarker(client rest.DynatraceClient, a api.Api, name string, coordinates string) (deployTarget, error) {
+
+	values, err := client.List(a)
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	marker := ownershipMarker(coordinates)
+
+	marked := make([]api.Value, 0, 1)
+	for _, value := range values {
+		description, err := descriptionOf(client, a, value)
+		if err != nil {
+			return deployTarget{}, err
+		}
+		if strings.Contains(description, marker) {
+			marked = append(marked, value)
+		}
+	}
+
+	if len(marked) == 0 {
+		return deployTarget{}, nil
+	}
+
+	if len(marked) > 1 {
+		util.Log.Warn("\t\t\t%d objects of %s carry the ownership marker of %s, e.g. as they were copied. Updating %s (%s)",
+			len(marked), a.GetId(), coordinates, marked[0].Name, marked[0].Id)
+	}
+
+	if marked[0].Name != name {
+		util.Log.Info("\t\t\tRenaming %s (%s) of %s to %s", marked[0].Name, marked[0].Id, a.GetId(), name)
+	}
+
+	return deployTarget{ids: []string{marked[0].Id}}, nil
+}
+
+// descriptionOf returns the description of the listed object, reading the object if the list omits descriptions
+func descriptionOf(client rest.DynatraceClient, a api.Api, value api.Value) (string, error) {
+
+	if a.ListsDescriptions() {
+		return value.Description, nil
+	}
+
+	object, err := client.ReadById(a, value.Id)
+	if err != nil {
+		return "", fmt.Errorf("reading object %s of %s to find its ownership marker failed: %s", value.Id, a.GetId(), err)
+	}
+
+	var content struct {
+		Description string `json:"description"`
+	}
+	err = json.Unmarshal(object, &content)
+	if err != nil {
+		return "", fmt.Errorf("object %s of %s is no valid json: %s", value.Id, a.GetId(), err)
+	}
+
+	return content.Description, nil
+}
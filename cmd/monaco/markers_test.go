@@ -0,0 +1,99 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+const testZoneCoordinates = "project/management-zone/zone"
+
+var testZoneApi = api.NewApi("management-zone", "/api/config/v1/managementZones")
+
+func TestWithOwnershipMarkerAppendsMarkerToDescription(t *testing.T) {
+
+	marker := ownershipMarker(testZoneCoordinates)
+
+	payload, err := withOwnershipMarker([]byte(`{"name": "zone"}`), marker)
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"description":"`+marker+`","name":"zone"}`)
+
+	payload, err = withOwnershipMarker([]byte(`{"description": "Zone of the team [monaco:0a1b-2c3d]"}`), marker)
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"description":"Zone of the team `+marker+`"}`)
+}
+
+func TestTargetByOwnershipMarkerFindsRenamedObject(t *testing.T) {
+
+	marker := ownershipMarker(testZoneCoordinates)
+	client := &recordingClient{values: []api.Value{
+		{Id: "1", Name: "zone"},
+		{Id: "2", Name: "old zone", Description: "Zone of the team " + marker},
+	}}
+	windowApi := api.NewApi("maintenance-window", "/api/config/v1/maintenanceWindows")
+
+	target, err := targetByOwnershipMarker(client, windowApi, "zone", testZoneCoordinates)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, target.ids, []string{"2"})
+	assert.Equal(t, len(client.reads), 0, "descriptions are listed")
+}
+
+func TestTargetByOwnershipMarkerIgnoresMarkersOfOtherConfigs(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{
+		{Id: "1", Name: "zone", Description: ownershipMarker("project/management-zone/other")},
+	}}
+	windowApi := api.NewApi("maintenance-window", "/api/config/v1/maintenanceWindows")
+
+	target, err := targetByOwnershipMarker(client, windowApi, "zone", testZoneCoordinates)
+	assert.NilError(t, err)
+	assert.Assert(t, !target.exists())
+}
+
+func TestTargetByOwnershipMarkerReadsObjectsIfListOmitsDescriptions(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+	zoneApi := apis["management-zone"]
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("dev", "dev", "", server.URL, "DEV")
+	zone := config.GetMockConfig("zone", "project", nil, map[string]map[string]string{"zone": {"name": "zone"}}, zoneApi, "zone.json")
+	options := executionOptions{duplicateNames: failOnDuplicateNames, ownershipMarkers: true}
+
+	created, err := deployByIdentity(client, zone, "zone", testZoneCoordinates, []byte(`{"name": "zone"}`), false, env, nil, options)
+	assert.NilError(t, err)
+
+	renamed, err := deployByIdentity(client, zone, "renamed zone", testZoneCoordinates, []byte(`{"name": "renamed zone"}`), false, env, nil, options)
+	assert.NilError(t, err)
+	assert.Equal(t, renamed.Id, created.Id)
+	assert.Equal(t, server.Objects("management-zone"), 1)
+}
@@ -0,0 +1,28 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+)
+
+// addNameMatchingFlag adds the flag selecting how existing objects are matched by name. Every command looking up
+// objects by name uses it, so they match the same objects as deployments do
+func addNameMatchingFlag(flagSet *flag.FlagSet, caseInsensitive *bool) {
+	caseInsensitiveNamesUsage := "Match existing objects by name regardless of case, e.g. to not duplicate an object renamed from 'my dashboard' to 'My Dashboard'."
+	flagSet.BoolVar(caseInsensitive, "case-insensitive-names", false, caseInsensitiveNamesUsage)
+}
@@ -0,0 +1,162 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// notificationApi is the id of the api problem notifications are deployed to
+const notificationApi = "notification"
+
+// testNotificationTimeout limits how long sending a test notification may take
+const testNotificationTimeout = 30 * time.Second
+
+type notificationPayload struct {
+	Type                 string `json:"type"`
+	Url                  string `json:"url"`
+	AcceptAnyCertificate bool   `json:"acceptAnyCertificate"`
+	Payload              string `json:"payload"`
+	Title                string `json:"title"`
+	Headers              []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"headers"`
+}
+
+// testNotificationPlaceholders replaces the placeholders of notification payloads and messages, which
+// Dynatrace fills with the details of the problem
+var testNotificationPlaceholders = strings.NewReplacer(
+	"{ImpactedEntities}", "[]",
+	"{ImpactedEntity}", "",
+	"{PID}", "TEST",
+	"{ProblemDetailsHTML}", "This is a test notification sent by monaco",
+	"{ProblemDetailsJSON}", "{}",
+	"{ProblemDetailsMarkdown}", "This is a test notification sent by monaco",
+	"{ProblemDetailsText}", "This is a test notification sent by monaco",
+	"{ProblemID}", "TEST",
+	"{ProblemImpact}", "APPLICATION",
+	"{ProblemSeverity}", "AVAILABILITY",
+	"{ProblemTitle}", "Test notification sent by monaco",
+	"{ProblemURL}", "",
+	"{State}", "OPEN",
+	"{Tags}", "",
+)
+
+// validateNotification checks the webhook of a notification. Webhook urls and secrets are usually rendered from
+// environment variables or secrets, so an empty or malformed url indicates a missing secret
+func validateNotification(jsonString string, fileName string) error {
+
+	payload, err := parseNotification(jsonString, fileName)
+	if err != nil {
+		return err
+	}
+
+	if payload.Type == "" {
+		return fmt.Errorf("notification %s has no type", fileName)
+	}
+
+	if payload.Type != "WEBHOOK" && payload.Type != "SLACK" {
+		return nil
+	}
+
+	parsed, err := url.Parse(payload.Url)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("notification %s has no valid webhook url", fileName)
+	}
+
+	for i, header := range payload.Headers {
+		if header.Name == "" {
+			return fmt.Errorf("header %d of notification %s has no name", i+1, fileName)
+		}
+	}
+
+	return nil
+}
+
+// testNotification sends a test message to the webhook of the notification, to verify the channel actually works.
+// Notifications of types without a webhook are skipped
+func testNotification(jsonString string, fileName string) error {
+
+	payload, err := parseNotification(jsonString, fileName)
+	if err != nil {
+		return err
+	}
+
+	var body string
+	switch payload.Type {
+	case "WEBHOOK":
+		body = testNotificationPlaceholders.Replace(payload.Payload)
+	case "SLACK":
+		message, err := json.Marshal(map[string]string{"text": testNotificationPlaceholders.Replace(payload.Title)})
+		if err != nil {
+			return err
+		}
+		body = string(message)
+	default:
+		util.Log.Warn("\t\t\tSkipping test of notification %s, only webhook and slack notifications can be tested", fileName)
+		return nil
+	}
+
+	request, err := http.NewRequest(http.MethodPost, payload.Url, bytes.NewBufferString(body))
+	if err != nil {
+		return fmt.Errorf("test notification of %s could not be created: %s", fileName, err)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for _, header := range payload.Headers {
+		request.Header.Set(header.Name, header.Value)
+	}
+
+	client := &http.Client{
+		Timeout: testNotificationTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: payload.AcceptAnyCertificate},
+		},
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		// the error contains the url, which may contain secrets
+		return fmt.Errorf("test notification of %s could not be sent", fileName)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("test notification of %s failed with status code %d", fileName, response.StatusCode)
+	}
+
+	util.Log.Info("\t\t\tTest notification of %s sent", fileName)
+	return nil
+}
+
+func parseNotification(jsonString string, fileName string) (payload notificationPayload, err error) {
+
+	err = json.Unmarshal([]byte(jsonString), &payload)
+	if err != nil {
+		return payload, fmt.Errorf("%s is not a valid notification: %s", fileName, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,83 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateNotification(t *testing.T) {
+
+	tests := []struct {
+		payload string
+		err     string
+	}{
+		{`{"name": "ops", "type": "WEBHOOK", "url": "https://hooks.example.com/ops", "headers": [{"name": "Authorization", "value": "Bearer token"}]}`, ""},
+		{`{"name": "ops", "type": "EMAIL", "receivers": ["ops@example.com"]}`, ""},
+		{`{"name": "ops"}`, "notification ops.json has no type"},
+		{`{"name": "ops", "type": "WEBHOOK", "url": ""}`, "notification ops.json has no valid webhook url"},
+		{`{"name": "ops", "type": "SLACK", "url": "hooks.slack.com/services/xyz"}`, "notification ops.json has no valid webhook url"},
+		{`{"name": "ops", "type": "WEBHOOK", "url": "https://hooks.example.com/ops", "headers": [{"value": "a"}]}`, "header 1 of notification ops.json has no name"},
+	}
+
+	for _, test := range tests {
+		err := validateNotification(test.payload, "ops.json")
+		if test.err == "" {
+			assert.NilError(t, err, test.payload)
+		} else {
+			assert.ErrorContains(t, err, test.err)
+		}
+	}
+}
+
+func TestTestNotificationSendsPayloadToWebhook(t *testing.T) {
+
+	var body, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		content, _ := ioutil.ReadAll(request.Body)
+		body = string(content)
+		authorization = request.Header.Get("Authorization")
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notification := `{"type": "WEBHOOK", "url": "` + server.URL + `", "payload": "{\"id\": \"{ProblemID}\", \"details\": {ProblemDetailsJSON}}",
+		"headers": [{"name": "Authorization", "value": "Bearer token"}]}`
+
+	err := testNotification(notification, "ops.json")
+	assert.NilError(t, err)
+	assert.Equal(t, body, `{"id": "TEST", "details": {}}`)
+	assert.Equal(t, authorization, "Bearer token")
+}
+
+func TestTestNotificationFailsOnUnsuccessfulResponse(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		writer.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := testNotification(`{"type": "SLACK", "url": "`+server.URL+`", "title": "{ProblemTitle}"}`, "ops.json")
+	assert.Error(t, err, "test notification of ops.json failed with status code 403")
+}
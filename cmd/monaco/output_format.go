@@ -0,0 +1,93 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// output formats of the read-only commands. The table is meant to be read by humans and is logged, json and yaml are
+// written to stdout, while the log goes to stderr, e.g. to be piped into jq
+const (
+	outputTable = "table"
+	outputJson  = "json"
+	outputYaml  = "yaml"
+)
+
+func addOutputFormatFlag(flagSet *flag.FlagSet, format *string) {
+
+	outputFormatUsage := "Output format: table, json or yaml. With json and yaml, the output is written to stdout and the log to stderr."
+	flagSet.StringVar(format, "output-format", outputTable, outputFormatUsage)
+	flagSet.StringVar(format, "o", outputTable, outputFormatUsage+" (shorthand)")
+}
+
+func validateOutputFormat(format string) error {
+	switch format {
+	case outputTable, outputJson, outputYaml:
+		return nil
+	default:
+		return fmt.Errorf("invalid output format %s, expected table, json or yaml", format)
+	}
+}
+
+// setupLoggingForOutput sets up logging to stderr for machine readable output formats, to keep stdout parsable
+func setupLoggingForOutput(verbose bool, format string) {
+
+	setup := util.SetupLogging
+	if format != outputTable {
+		setup = util.SetupLoggingToStderr
+	}
+
+	err := setup(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+}
+
+// writeOutput writes the value as json or yaml. The yaml uses the same keys as the json, as the value is converted
+// from its json representation, which is valid yaml
+func writeOutput(writer io.Writer, format string, value interface{}) error {
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if format == outputYaml {
+		var converted yaml.MapSlice
+		err = yaml.Unmarshal(data, &converted)
+		if err != nil {
+			return err
+		}
+
+		data, err = yaml.Marshal(converted)
+		if err != nil {
+			return err
+		}
+		_, err = writer.Write(data)
+		return err
+	}
+
+	_, err = writer.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,86 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/compare"
+	"gotest.tools/assert"
+)
+
+func testComparison() environmentComparison {
+	return newEnvironmentComparison([]compare.ApiResult{
+		{Api: "alerting-profile"},
+		{
+			Api:         "management-zone",
+			OnlyInFirst: []string{"zone"},
+			Differences: map[string][]compare.FieldDifference{"other": {{Path: "rules", First: "1", Second: "2"}}},
+		},
+	}, "dev", "prod")
+}
+
+func TestWriteOutputAsJson(t *testing.T) {
+
+	var output bytes.Buffer
+	err := writeOutput(&output, outputJson, testComparison())
+	assert.NilError(t, err)
+
+	var written environmentComparison
+	err = json.Unmarshal(output.Bytes(), &written)
+	assert.NilError(t, err)
+
+	assert.Equal(t, written.Equal, false)
+	assert.Equal(t, len(written.Apis), 1, "apis without differences are left out")
+	assert.Equal(t, written.Apis[0].Api, "management-zone")
+	assert.Equal(t, written.Apis[0].Differences["other"][0].Second, "2")
+}
+
+func TestWriteOutputAsYamlUsesTheKeysOfTheJson(t *testing.T) {
+
+	var output bytes.Buffer
+	err := writeOutput(&output, outputYaml, testComparison())
+	assert.NilError(t, err)
+
+	expected := `first: dev
+second: prod
+equal: false
+apis:
+- api: management-zone
+  onlyInFirst:
+  - zone
+  onlyInSecond: null
+  differences:
+    other:
+    - path: rules
+      first: "1"
+      second: "2"
+`
+	assert.Equal(t, output.String(), expected)
+}
+
+func TestValidateOutputFormat(t *testing.T) {
+
+	for _, format := range []string{outputTable, outputJson, outputYaml} {
+		assert.NilError(t, validateOutputFormat(format))
+	}
+	assert.ErrorContains(t, validateOutputFormat("xml"), "invalid output format xml")
+}
@@ -0,0 +1,80 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// ownershipFilter restricts deployments and deletions to the configs of an owner and with all given labels,
+// so that multiple teams can share a repository and environment. An empty filter matches everything
+type ownershipFilter struct {
+	owner  string
+	labels []string
+}
+
+// isEmpty returns whether the filter matches everything
+func (f ownershipFilter) isEmpty() bool {
+	return f.owner == "" && len(f.labels) == 0
+}
+
+// matchesConfig returns whether the config belongs to the owner and has all labels of the filter in the environment
+func (f ownershipFilter) matchesConfig(config config.Config, environment environment.Environment) bool {
+
+	if f.isEmpty() {
+		return true
+	}
+
+	return f.matches(config.GetOwner(environment), config.GetLabels(environment))
+}
+
+// filterEntries returns the delete entries belonging to the owner and having all labels of the filter
+func (f ownershipFilter) filterEntries(entries []delete.Entry) []delete.Entry {
+
+	if f.isEmpty() {
+		return entries
+	}
+
+	filtered := make([]delete.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if f.matches(entry.Owner, entry.Labels) {
+			filtered = append(filtered, entry)
+		} else {
+			util.Log.Debug("\tIgnoring delete entry %s, it does not match owner '%s' and labels %v", entry, f.owner, f.labels)
+		}
+	}
+
+	return filtered
+}
+
+func (f ownershipFilter) matches(owner string, labels []string) bool {
+
+	if f.owner != "" && owner != f.owner {
+		return false
+	}
+
+	for _, label := range f.labels {
+		if !contains(labels, label) {
+			return false
+		}
+	}
+
+	return true
+}
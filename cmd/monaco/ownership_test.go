@@ -0,0 +1,84 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+var ownershipTestEnvironment = environment.NewEnvironment("production", "production", "", "https://production", "TOKEN")
+
+func TestEmptyOwnershipFilterMatchesEverything(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	// the config is not even asked for its owner
+	c := config.NewMockConfig(mockCtrl)
+
+	filter := ownershipFilter{}
+	assert.Assert(t, filter.matchesConfig(c, ownershipTestEnvironment))
+
+	entries := []delete.Entry{{Name: "a"}, {Name: "b", Owner: "team-a"}}
+	assert.Equal(t, len(filter.filterEntries(entries)), 2)
+}
+
+func TestOwnershipFilterMatchesConfigsOfOwnerWithAllLabels(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	c := config.NewMockConfig(mockCtrl)
+	c.EXPECT().GetOwner(ownershipTestEnvironment).Return("team-a").AnyTimes()
+	c.EXPECT().GetLabels(ownershipTestEnvironment).Return([]string{"payments", "tier-1"}).AnyTimes()
+
+	assert.Assert(t, ownershipFilter{owner: "team-a"}.matchesConfig(c, ownershipTestEnvironment))
+	assert.Assert(t, ownershipFilter{owner: "team-a", labels: []string{"tier-1"}}.matchesConfig(c, ownershipTestEnvironment))
+	assert.Assert(t, ownershipFilter{labels: []string{"payments", "tier-1"}}.matchesConfig(c, ownershipTestEnvironment))
+
+	assert.Assert(t, !ownershipFilter{owner: "team-b"}.matchesConfig(c, ownershipTestEnvironment))
+	assert.Assert(t, !ownershipFilter{owner: "team-a", labels: []string{"tier-2"}}.matchesConfig(c, ownershipTestEnvironment))
+}
+
+func TestOwnershipFilterOnlyKeepsEntriesOfOwner(t *testing.T) {
+
+	dashboards := createApis()["dashboard"]
+
+	entries := []delete.Entry{
+		{Api: dashboards, Name: "unowned"},
+		{Api: dashboards, Name: "team a", Owner: "team-a"},
+		{Api: dashboards, Name: "team a payments", Owner: "team-a", Labels: []string{"payments"}},
+		{Api: dashboards, Name: "team b", Owner: "team-b"},
+	}
+
+	filtered := ownershipFilter{owner: "team-a"}.filterEntries(entries)
+	assert.Equal(t, len(filtered), 2)
+	assert.Equal(t, filtered[0].Name, "team a")
+	assert.Equal(t, filtered[1].Name, "team a payments")
+
+	filtered = ownershipFilter{owner: "team-a", labels: []string{"payments"}}.filterEntries(entries)
+	assert.Equal(t, len(filtered), 1)
+	assert.Equal(t, filtered[0].Name, "team a payments")
+}
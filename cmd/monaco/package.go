@@ -0,0 +1,124 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/artifact"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// runPackage executes the package command, which validates that the projects of a folder can be loaded and writes
+// them into a versioned artifact, which is deployed with --from-artifact. Returns 0 on success and -1 on errors
+func runPackage(args []string, fileReader util.FileReader) int {
+
+	var artifactVersion, output, timestamp string
+	var verbose bool
+
+	flagSet := flag.NewFlagSet("package", flag.ExitOnError)
+
+	versionUsage := "Mandatory version of the artifact, e.g. the release or commit the projects were packaged from."
+	flagSet.StringVar(&artifactVersion, "version", "", versionUsage)
+
+	outputUsage := "File the artifact is written to. Defaults to monaco-<version>.zip in the current working dir."
+	flagSet.StringVar(&output, "output", "", outputUsage)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(&verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(&verbose, "v", false, verboseUsage+" (shorthand)")
+
+	addTimestampFlag(flagSet, &timestamp)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if artifactVersion == "" {
+		println("Please provide the version of the artifact with --version!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+
+	err = util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	err = freezeTime(timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	path := "."
+	if flagSet.NArg() > 0 {
+		path = flagSet.Arg(0)
+	}
+	if output == "" {
+		output = "monaco-" + artifactVersion + ".zip"
+	}
+
+	projects, err := project.LoadProjectsToDeploy("", createApis(), path, fileReader)
+	if err != nil {
+		util.Log.Error("Loading projects of %s failed: %s", path, err)
+		return -1
+	}
+
+	manifest, err := artifact.Package(path, artifactVersion, output, util.Now())
+	if err != nil {
+		util.Log.Error("Packaging %s failed: %s", path, err)
+		return -1
+	}
+
+	util.Log.Info("Packaged %d projects (%d files) as version %s into %s", len(projects), len(manifest.Files), artifactVersion, output)
+	util.Log.Info("Deploy it with: monaco deploy -e=<environments file> --from-artifact=%s", output)
+
+	return 0
+}
+
+// extractArtifact extracts the artifact into a temporary folder and returns the path of its projects, relative to
+// the working dir like paths given on the command line. The folder has to be removed after the deployment
+func extractArtifact(file string) (folder string, path string, err error) {
+
+	folder, err = ioutil.TempDir("", "monaco-artifact")
+	if err != nil {
+		return "", "", err
+	}
+
+	manifest, err := artifact.Extract(file, folder)
+	if err != nil {
+		os.RemoveAll(folder)
+		return "", "", err
+	}
+
+	path, err = util.RelativeToWorkingDir(folder)
+	if err != nil {
+		os.RemoveAll(folder)
+		return "", "", err
+	}
+
+	util.Log.Info("Deploying version %s of artifact %s, packaged at %s", manifest.Version, file, manifest.Created.Format(time.RFC3339))
+
+	return folder, path + string(os.PathSeparator), nil
+}
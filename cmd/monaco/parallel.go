@@ -0,0 +1,105 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// environmentResult is the outcome of the deployment to or validation of one environment
+type environmentResult struct {
+	environment string
+	err         error
+	duration    time.Duration
+}
+
+// forEachEnvironment runs the given function for all environments, with at most parallel environments at the
+// same time. Environments are independent: a failing environment neither delays nor aborts the others.
+// The results are sorted by environment id
+func forEachEnvironment(environments map[string]environment.Environment, parallel int, run func(environment.Environment) error) []environmentResult {
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	ids := sortedEnvironmentIds(environments)
+	results := make([]environmentResult, len(ids))
+
+	slots := make(chan struct{}, parallel)
+	var wait sync.WaitGroup
+
+	for i, id := range ids {
+
+		slots <- struct{}{}
+		wait.Add(1)
+
+		go func(i int, environment environment.Environment) {
+			defer func() {
+				<-slots
+				wait.Done()
+			}()
+
+			start := time.Now()
+			err := run(environment)
+			results[i] = environmentResult{environment: environment.GetId(), err: err, duration: time.Since(start)}
+		}(i, environments[id])
+	}
+
+	wait.Wait()
+
+	return results
+}
+
+// logEnvironmentSummary logs the result of every environment as a table, which is easier to survey than the
+// interleaved logs of environments deployed to in parallel
+func logEnvironmentSummary(results []environmentResult, dryRun bool) {
+
+	width := len("Environment")
+	for _, result := range results {
+		if len(result.environment) > width {
+			width = len(result.environment)
+		}
+	}
+
+	succeeded := "deployed"
+	if dryRun {
+		succeeded = "valid"
+	}
+
+	util.Log.Info("Summary:")
+	util.Log.Info("\t%-*s  %-8s  %8s  %s", width, "Environment", "Result", "Duration", "Error")
+
+	failed := 0
+	for _, result := range results {
+
+		status, message := succeeded, ""
+		if result.err != nil {
+			status, message = "failed", strings.SplitN(result.err.Error(), "\n", 2)[0]
+			failed++
+		}
+
+		util.Log.Info("\t%-*s  %-8s  %8s  %s", width, result.environment, status, result.duration.Round(time.Second), message)
+	}
+
+	util.Log.Info("\t%s", fmt.Sprintf("%d of %d environments failed", failed, len(results)))
+}
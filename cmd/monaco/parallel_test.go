@@ -0,0 +1,69 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func TestForEachEnvironmentIsolatesFailures(t *testing.T) {
+
+	environments := make(map[string]environment.Environment)
+	for _, id := range []string{"c", "a", "b", "d"} {
+		environments[id] = environment.NewEnvironment(id, id, "", "https://"+id+".example.com", "TOKEN")
+	}
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+
+	results := forEachEnvironment(environments, 2, func(environment environment.Environment) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+
+		if environment.GetId() == "a" {
+			return errors.New("deployment failed")
+		}
+		return nil
+	})
+
+	assert.Equal(t, maxRunning, 2)
+	assert.Equal(t, len(results), 4)
+
+	for i, id := range []string{"a", "b", "c", "d"} {
+		assert.Equal(t, results[i].environment, id)
+		assert.Equal(t, results[i].err != nil, id == "a")
+		assert.Assert(t, results[i].duration > 0)
+	}
+}
@@ -0,0 +1,183 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// pinnedRemoteParameter is the parameter of configs holding the hash of the object they are pinned to
+const pinnedRemoteParameter = "pinnedRemote"
+
+// checkPinnedRemote compares the existing object of a pinned config with the hash it is pinned to, so that changes made
+// in the environment since the config was downloaded, e.g. manual fixes during an incident, are not overwritten. If the
+// object changed, the deployment fails, unless the remote object is accepted, in which case its entity is returned
+// and the config is not deployed, or the object is overwritten by force. Configs without pin are never checked
+func checkPinnedRemote(client rest.DynatraceClient, c config.Config, name string, environment environment.Environment, options executionOptions) (accepted bool, entity api.DynatraceEntity, err error) {
+
+	pin := c.GetPinnedRemote(environment)
+	if pin == "" {
+		return false, entity, nil
+	}
+
+	apiId := c.GetApi().GetId()
+	if apiId == extensionApi || apiId == settingsApi {
+		return false, entity, fmt.Errorf("pinnedRemote is not supported for %s, responsible config: %s", apiId, c.GetFilePath())
+	}
+
+	exists, id, err := client.ExistsByName(c.GetApi(), name)
+	if err != nil || !exists {
+		return false, entity, err
+	}
+
+	existing, err := client.ReadById(c.GetApi(), id)
+	if err != nil {
+		return false, entity, fmt.Errorf("reading object %s of %s to check its pin failed: %s", id, apiId, err)
+	}
+
+	hash, err := download.PayloadHash(apiId, existing, options.fieldOverrides)
+	if err != nil {
+		return false, entity, fmt.Errorf("object %s of %s is no valid json: %s", id, apiId, err)
+	}
+	if hash == pin {
+		return false, entity, nil
+	}
+
+	switch {
+	case options.acceptRemote:
+		util.Log.Warn("\t\t\tKeeping %s (%s) of %s, it changed since %s was pinned to it", name, id, apiId, c.GetFilePath())
+		return true, api.DynatraceEntity{Id: id, Name: name}, nil
+	case options.forcePinned:
+		util.Log.Warn("\t\t\tOverwriting %s (%s) of %s, although it changed since %s was pinned to it", name, id, apiId, c.GetFilePath())
+		return false, entity, nil
+	default:
+		return false, entity, fmt.Errorf("%s (%s) of %s changed since %s was pinned to it, pass --accept-remote to keep it or --force to overwrite it", name, id, apiId, c.GetFilePath())
+	}
+}
+
+// hasPinnedConfigs returns whether any config of the projects is pinned in the environment, dry runs then need a
+// client to check the pins
+func hasPinnedConfigs(projects []project.Project, environment environment.Environment) bool {
+
+	for _, p := range projects {
+		for _, c := range p.GetConfigs() {
+			if c.GetPinnedRemote(environment) != "" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkPinnedRemoteOfDryRun checks the pin of the config like a deployment does, so that dry runs report the configs
+// which would fail
+func checkPinnedRemoteOfDryRun(client rest.DynatraceClient, c config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment, options executionOptions) error {
+
+	if client == nil || c.GetPinnedRemote(environment) == "" {
+		return nil
+	}
+
+	name, err := c.GetObjectNameForEnvironment(environment, dict)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = checkPinnedRemote(client, c, name, environment, options)
+	return err
+}
+
+// refreshPin pins the config to the object it was deployed to, so that the next deployment does not mistake its own
+// changes for changes made in the environment. The pin is replaced in the section of the yaml file of the project it
+// is effective in, i.e. the section of the environment, of its group or of the config. A pin which can't be
+// refreshed is removed with a warning
+func refreshPin(client rest.DynatraceClient, c config.Config, entity api.DynatraceEntity, environment environment.Environment, fields api.FieldOverrides) {
+
+	pin := c.GetPinnedRemote(environment)
+
+	payload, err := client.ReadById(c.GetApi(), entity.Id)
+	var refreshed string
+	if err == nil {
+		refreshed, err = download.PayloadHash(c.GetApi().GetId(), payload, fields)
+	}
+	if err != nil {
+		util.Log.Warn("\t\t\tThe pin of %s could not be refreshed, remove it or download the config again with --pin: %s", c.GetFilePath(), err)
+		return
+	}
+
+	sections := []string{c.GetId() + "." + environment.GetId(), c.GetId() + "." + environment.GetGroup(), c.GetId()}
+	for _, section := range sections {
+		found, err := replacePin(c.GetProject(), section, pin, refreshed)
+		if err != nil {
+			util.Log.Warn("\t\t\tThe pin of %s could not be refreshed, remove it or download the config again with --pin: %s", c.GetFilePath(), err)
+			return
+		}
+		if found {
+			util.Log.Debug("\t\t\tRefreshed the pin of %s in section %s", c.GetFilePath(), section)
+			return
+		}
+	}
+
+	util.Log.Warn("\t\t\tThe pin of %s was not found in the yaml files of project %s, remove it or download the config again with --pin", c.GetFilePath(), c.GetProject())
+}
+
+// replacePin replaces the pin in the given top level section of the yaml files of the project folder. Returns whether
+// the pin was found
+func replacePin(folder string, section string, pin string, refreshed string) (found bool, err error) {
+
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || found || info.IsDir() || !strings.HasSuffix(path, ".yaml") {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		lines := strings.Split(string(content), "\n")
+		current := ""
+		for i, line := range lines {
+			if isTopLevelKey(line) {
+				current = strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `"'`)
+			}
+			if current == section && strings.Contains(line, pinnedRemoteParameter) && strings.Contains(line, pin) {
+				lines[i] = strings.Replace(line, pin, refreshed, 1)
+				found = true
+				return ioutil.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode())
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// isTopLevelKey returns whether the yaml line starts a section, e.g. "zone.production:"
+func isTopLevelKey(line string) bool {
+	return line != "" && line[0] != ' ' && line[0] != '\t' && line[0] != '-' && line[0] != '#' && strings.HasSuffix(strings.TrimSpace(line), ":")
+}
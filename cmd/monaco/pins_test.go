@@ -0,0 +1,172 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+var testPinEnvironment = environment.NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV")
+
+const testPinnedPayload = `{"id": "1234", "name": "zone", "rules": []}`
+
+// pinnedObjectClient serves one existing object with the given payload
+type pinnedObjectClient struct {
+	recordingClient
+	payload string
+}
+
+func (c *pinnedObjectClient) ReadById(a api.Api, id string) ([]byte, error) {
+	return []byte(c.payload), nil
+}
+
+func newPinnedConfig(mockCtrl *gomock.Controller, pin string) config.Config {
+
+	pinned := config.NewMockConfig(mockCtrl)
+	pinned.EXPECT().GetPinnedRemote(gomock.Any()).Return(pin).AnyTimes()
+	pinned.EXPECT().GetApi().Return(api.NewApi("management-zone", "/api/config/v1/managementZones")).AnyTimes()
+	pinned.EXPECT().GetFilePath().Return("project/management-zone/zone.json").AnyTimes()
+
+	return pinned
+}
+
+func testPin(t *testing.T) string {
+
+	pin, err := download.PayloadHash("management-zone", []byte(testPinnedPayload), nil)
+	assert.NilError(t, err)
+
+	return pin
+}
+
+func TestCheckPinnedRemotePassesUnchangedObjects(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &pinnedObjectClient{
+		recordingClient: recordingClient{values: []api.Value{{Id: "1234", Name: "zone"}}},
+		payload:         `{"rules": [], "name": "zone", "metadata": {"clusterVersion": "1.200"}, "id": "1234"}`,
+	}
+
+	accepted, _, err := checkPinnedRemote(client, newPinnedConfig(mockCtrl, testPin(t)), "zone", testPinEnvironment, executionOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !accepted)
+}
+
+func TestCheckPinnedRemoteFailsOnChangedObjects(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &pinnedObjectClient{
+		recordingClient: recordingClient{values: []api.Value{{Id: "1234", Name: "zone"}}},
+		payload:         `{"id": "1234", "name": "zone", "rules": [{"type": "SERVICE"}]}`,
+	}
+	pinned := newPinnedConfig(mockCtrl, testPin(t))
+
+	_, _, err := checkPinnedRemote(client, pinned, "zone", testPinEnvironment, executionOptions{})
+	assert.ErrorContains(t, err, "changed since project/management-zone/zone.json was pinned to it")
+
+	accepted, entity, err := checkPinnedRemote(client, pinned, "zone", testPinEnvironment, executionOptions{acceptRemote: true})
+	assert.NilError(t, err)
+	assert.Assert(t, accepted)
+	assert.Equal(t, entity, api.DynatraceEntity{Id: "1234", Name: "zone"})
+
+	accepted, _, err = checkPinnedRemote(client, pinned, "zone", testPinEnvironment, executionOptions{forcePinned: true})
+	assert.NilError(t, err)
+	assert.Assert(t, !accepted)
+}
+
+func TestCheckPinnedRemoteIgnoresMissingObjectsAndUnpinnedConfigs(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &pinnedObjectClient{payload: `{"name": "other"}`}
+
+	accepted, _, err := checkPinnedRemote(client, newPinnedConfig(mockCtrl, testPin(t)), "zone", testPinEnvironment, executionOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !accepted)
+
+	client.values = []api.Value{{Id: "1234", Name: "zone"}}
+
+	accepted, _, err = checkPinnedRemote(client, newPinnedConfig(mockCtrl, ""), "zone", testPinEnvironment, executionOptions{})
+	assert.NilError(t, err)
+	assert.Assert(t, !accepted)
+}
+
+func TestRefreshPinReplacesPinOfEnvironment(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	folder := t.TempDir()
+	yamlFile := filepath.Join(folder, "zone.yaml")
+	content := "config:\n- zone: zone.json\nzone:\n- name: zone\nzone.prod:\n- pinnedRemote: " + testPin(t) + "\nzone.dev:\n- pinnedRemote: " + testPin(t) + "\n"
+	assert.NilError(t, ioutil.WriteFile(yamlFile, []byte(content), 0664))
+
+	pinned := config.NewMockConfig(mockCtrl)
+	pinned.EXPECT().GetPinnedRemote(gomock.Any()).Return(testPin(t)).AnyTimes()
+	pinned.EXPECT().GetApi().Return(api.NewApi("management-zone", "/api/config/v1/managementZones")).AnyTimes()
+	pinned.EXPECT().GetFilePath().Return(filepath.Join(folder, "zone.json")).AnyTimes()
+	pinned.EXPECT().GetProject().Return(folder).AnyTimes()
+	pinned.EXPECT().GetId().Return("zone").AnyTimes()
+
+	deployed := `{"id": "1234", "name": "zone", "rules": [{"type": "SERVICE"}]}`
+	refreshed, err := download.PayloadHash("management-zone", []byte(deployed), nil)
+	assert.NilError(t, err)
+
+	refreshPin(&pinnedObjectClient{payload: deployed}, pinned, api.DynatraceEntity{Id: "1234", Name: "zone"}, testPinEnvironment, nil)
+
+	updated, err := ioutil.ReadFile(yamlFile)
+	assert.NilError(t, err)
+	assert.Equal(t, string(updated), strings.Replace(content, "zone.dev:\n- pinnedRemote: "+testPin(t), "zone.dev:\n- pinnedRemote: "+refreshed, 1))
+}
+
+func TestCheckPinnedRemoteOfDryRunFailsOnChangedObjects(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &pinnedObjectClient{
+		recordingClient: recordingClient{values: []api.Value{{Id: "1234", Name: "zone"}}},
+		payload:         `{"id": "1234", "name": "zone", "rules": [{"type": "SERVICE"}]}`,
+	}
+	pinned := config.NewMockConfig(mockCtrl)
+	pinned.EXPECT().GetPinnedRemote(gomock.Any()).Return(testPin(t)).AnyTimes()
+	pinned.EXPECT().GetApi().Return(api.NewApi("management-zone", "/api/config/v1/managementZones")).AnyTimes()
+	pinned.EXPECT().GetFilePath().Return("project/management-zone/zone.json").AnyTimes()
+	pinned.EXPECT().GetObjectNameForEnvironment(gomock.Any(), gomock.Any()).Return("zone", nil).AnyTimes()
+
+	err := checkPinnedRemoteOfDryRun(client, pinned, map[string]api.DynatraceEntity{}, testPinEnvironment, executionOptions{dryRun: true})
+	assert.ErrorContains(t, err, "changed since project/management-zone/zone.json was pinned to it")
+
+	err = checkPinnedRemoteOfDryRun(nil, pinned, map[string]api.DynatraceEntity{}, testPinEnvironment, executionOptions{dryRun: true})
+	assert.NilError(t, err)
+}
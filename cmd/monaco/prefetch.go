@@ -0,0 +1,55 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// unlistedApis are not listed upfront, as their objects are not looked up in the lists of their api
+var unlistedApis = map[string]bool{
+	settingsApi:  true,
+	extensionApi: true,
+}
+
+// prefetchLists lists the apis of all configs deployed to the environment concurrently, so that looking the objects
+// of the configs up by name is served by the list cache of the client instead of listing one api after the other.
+// Failures are only logged, as the lookups list the apis again and report their errors
+func prefetchLists(client rest.DynatraceClient, configs []config.Config, environment environment.Environment) {
+
+	apis := make(map[string]api.Api)
+	for _, c := range configs {
+		if !c.IsSkipDeployment(environment) && !unlistedApis[c.GetApi().GetId()] {
+			apis[c.GetApi().GetId()] = c.GetApi()
+		}
+	}
+
+	if len(apis) < 2 {
+		return
+	}
+
+	util.Log.Debug("\tListing %d apis of %s upfront...", len(apis), environment.GetId())
+
+	_, err := rest.ListAll(client, apis)
+	if err != nil {
+		util.Log.Debug("\tListing the apis of %s upfront failed: %s", environment.GetId(), err)
+	}
+}
@@ -0,0 +1,71 @@
+//go:build unit
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+// prefetchClient records the listed apis
+type prefetchClient struct {
+	rest.DynatraceClient
+	lock   sync.Mutex
+	listed map[string]int
+}
+
+func (c *prefetchClient) List(a api.Api) ([]api.Value, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.listed[a.GetId()]++
+	return nil, nil
+}
+
+func TestPrefetchListsListsTheApisOfDeployedConfigs(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	deployed := func(apiId string, skipped bool) config.Config {
+		c := newTestApiConfig(mockCtrl, apiId)
+		c.(*config.MockConfig).EXPECT().IsSkipDeployment(gomock.Any()).Return(skipped).AnyTimes()
+		return c
+	}
+
+	client := &prefetchClient{listed: make(map[string]int)}
+	configs := []config.Config{
+		deployed("management-zone", false),
+		deployed("management-zone", false),
+		deployed("alerting-profile", false),
+		deployed("dashboard", true),
+		deployed(settingsApi, false),
+		deployed(extensionApi, false),
+	}
+
+	prefetchLists(client, configs, testDuplicatesEnvironment)
+	assert.DeepEqual(t, client.listed, map[string]int{"management-zone": 1, "alerting-profile": 1})
+}
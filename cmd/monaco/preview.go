@@ -0,0 +1,290 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/assertion"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/lint"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// unpreviewableApis identify their objects by other means than their name, e.g. by scope or extension id.
+// Their configs cannot be deployed with a prefix and are skipped in previews
+var unpreviewableApis = map[string]bool{
+	settingsApi:  true,
+	extensionApi: true,
+}
+
+// runPreview executes the preview command, which deploys projects with a prefix for the names of all objects to a
+// sandbox environment, evaluates the assertions of the projects and deletes all deployed objects again.
+// Returns 0 if the preview succeeded and -1 otherwise
+func runPreview(args []string, fileReader util.FileReader) (statusCode int) {
+
+	var environmentsFile, specificEnvironment, projectFlag, prefix, policyFolder, timestamp string
+	var verbose, keep bool
+	var clientFlags clientFlags
+
+	flagSet := flag.NewFlagSet("preview", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Sandbox environment (from list) the preview is deployed to.")
+
+	projectUsage := "Project configuration to preview. Also deploys any dependent configuration."
+	flagSet.StringVar(&projectFlag, "project", "", projectUsage)
+	flagSet.StringVar(&projectFlag, "p", "", projectUsage+" (shorthand)")
+
+	prefixUsage := "Prefix of the names of all objects of the preview, e.g. \"[pr-123] \". Defaults to a prefix unique for the run."
+	flagSet.StringVar(&prefix, "prefix", "", prefixUsage)
+
+	keepUsage := "Keep the objects of the preview instead of deleting them once it finished, e.g. to look at dashboards."
+	flagSet.BoolVar(&keep, "keep", false, keepUsage)
+
+	policiesUsage := "Folder containing rego policies (*.rego) all configs are checked against before they are deployed."
+	flagSet.StringVar(&policyFolder, "policies", "", policiesUsage)
+
+	addClientFlags(flagSet, &clientFlags)
+	addTimestampFlag(flagSet, &timestamp)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return -1
+	}
+
+	err = freezeTime(timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	if prefix == "" {
+		prefix = "[preview " + util.Now().Format("20060102-150405") + "] "
+	}
+
+	path := ""
+	if flagSet.NArg() > 0 {
+		path = readPath(flagSet.Args(), fileReader)
+	}
+
+	apis := createApis()
+
+	variables, err := project.LoadVariables(path, apis, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of variables failed: %s", err)
+		return -1
+	}
+
+	err = resolveVariables(variables, nil, newStdinPrompter())
+	if err != nil {
+		util.Log.Error("Resolving of variables failed: %s", err)
+		return -1
+	}
+
+	projects, err := project.LoadProjectsToDeploy(projectFlag, apis, path, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of projects failed: %s", err)
+		return -1
+	}
+
+	policies, err := policy.LoadPolicies(policyFolder, fileReader)
+	if err != nil {
+		util.Log.Error("Loading of policies failed: %s", err)
+		return -1
+	}
+
+	clientOptions, err := clientFlags.apply(rest.DefaultClientOptions(), fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+	defer func() {
+		if !clientFlags.saveNameCache(clientOptions) {
+			statusCode = -1
+		}
+	}()
+
+	err = deployPreview(env, projects, previewOptions{path: path, prefix: prefix, keep: keep, policies: policies, clientOptions: clientOptions})
+	if err != nil {
+		util.Log.Error("Preview in environment %s failed: %s", env.GetId(), err)
+		return -1
+	}
+
+	util.Log.Info("Preview in environment %s succeeded", env.GetId())
+	return 0
+}
+
+// previewOptions bundles the settings of a preview
+type previewOptions struct {
+	path          string
+	prefix        string
+	keep          bool
+	policies      policy.Engine
+	clientOptions rest.ClientOptions
+}
+
+// deployPreview deploys the projects with the prefix, evaluates their assertions and deletes the deployed objects,
+// unless they should be kept. The objects are deleted even if the deployment failed or was interrupted
+func deployPreview(env environment.Environment, projects []project.Project, options previewOptions) error {
+
+	linter, err := lint.NewLinter(nil)
+	if err != nil {
+		return err
+	}
+
+	client, err := createClient(env, options.clientOptions)
+	if err != nil {
+		return err
+	}
+
+	// objects are deleted by the ids they were deployed to, so that objects of other previews are never deleted
+	deployState := state.NewState()
+	interrupts := watchInterrupts()
+
+	util.Log.Info("Deploying preview to environment %s, the names of all objects are prefixed with %q", env.GetId(), options.prefix)
+
+	previews := previewProjects(projects, options.prefix)
+	err = execute(env, previews, executionOptions{
+		path:           options.path,
+		policies:       options.policies,
+		linter:         linter,
+		duplicateNames: failOnDuplicateNames,
+		state:          deployState,
+		interrupts:     interrupts,
+		clientOptions:  options.clientOptions,
+	})
+	interrupts.stop()
+
+	if options.keep {
+		util.Log.Info("Keeping the %d objects of the preview, their names start with %q", len(deployState.Ids(env.GetId())), options.prefix)
+		return err
+	}
+
+	failed := tearDownPreview(client, env, previews, deployState)
+	if err == nil && failed > 0 {
+		err = fmt.Errorf("%d objects of the preview could not be deleted, their names start with %q", failed, options.prefix)
+	}
+	return err
+}
+
+// previewProject is a project whose configs and assertions use the names of the objects of a preview
+type previewProject struct {
+	project.Project
+	configs    []config.Config
+	assertions []assertion.Assertion
+}
+
+// previewProjects prefixes the names of the configs of the projects and of the objects their assertions check
+func previewProjects(projects []project.Project, prefix string) []project.Project {
+
+	result := make([]project.Project, 0, len(projects))
+
+	for _, p := range projects {
+		preview := &previewProject{Project: p}
+
+		for _, c := range p.GetConfigs() {
+			if unpreviewableApis[c.GetApi().GetId()] {
+				util.Log.Warn("\tSkipping %s in the preview, configs of %s cannot be previewed", c.GetFilePath(), c.GetApi().GetId())
+				continue
+			}
+			preview.configs = append(preview.configs, c.WithNamePrefix(prefix))
+		}
+
+		for _, a := range p.GetAssertions() {
+			a.Object = prefix + a.Object
+			preview.assertions = append(preview.assertions, a)
+		}
+
+		result = append(result, preview)
+	}
+
+	return result
+}
+
+func (p *previewProject) GetConfigs() []config.Config {
+	return p.configs
+}
+
+func (p *previewProject) GetConfig(id string) (config.Config, error) {
+	for _, c := range p.configs {
+		if c.GetId() == id {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("config %s not found in preview of project %s", id, p.GetId())
+}
+
+func (p *previewProject) GetAssertions() []assertion.Assertion {
+	return p.assertions
+}
+
+// tearDownPreview deletes the objects recorded in the state, in the reverse order of their deployment, so that
+// objects are deleted before the objects they reference. Returns the number of objects which could not be deleted
+func tearDownPreview(client rest.DynatraceClient, env environment.Environment, projects []project.Project, deployState *state.State) (failed int) {
+
+	ids := deployState.Ids(env.GetId())
+	util.Log.Info("Deleting the %d objects of the preview from environment %s...", len(ids), env.GetId())
+
+	apis := createApis()
+	keys := make([]string, 0, len(ids))
+
+	for i := len(projects) - 1; i >= 0; i-- {
+		configs := projects[i].GetConfigs()
+		for j := len(configs) - 1; j >= 0; j-- {
+			// names referencing other configs cannot be resolved here, their objects are deleted afterwards
+			name, err := configs[j].GetObjectNameForEnvironment(env, nil)
+			key := configs[j].GetApi().GetId() + "/" + name
+			if _, found := ids[key]; err == nil && found {
+				keys = append(keys, key)
+				delete(ids, key)
+			}
+		}
+	}
+
+	remaining := make([]string, 0, len(ids))
+	for key := range ids {
+		remaining = append(remaining, key)
+	}
+	sort.Strings(remaining)
+
+	all := deployState.Ids(env.GetId())
+	for _, key := range append(keys, remaining...) {
+		apiId := strings.SplitN(key, "/", 2)[0]
+
+		util.Log.Debug("\tDeleting %s (%s)", key, all[key])
+		err := client.DeleteById(apis[apiId], all[key])
+		if err != nil {
+			util.Log.Warn("\tFailed to delete %s (%s): %s", key, all[key], err)
+			failed++
+		}
+	}
+
+	return failed
+}
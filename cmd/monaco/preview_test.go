@@ -0,0 +1,128 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/assertion"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestPreviewDeploysWithPrefixAndTearsDown(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-preview-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	err = writeBenchProject(folder, apis, 3)
+	assert.NilError(t, err)
+
+	projects, err := project.LoadProjectsToDeploy(benchProject, apis, folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("bench", "bench", "", server.URL, benchTokenEnv)
+
+	// objects deployed regularly are neither updated nor deleted by previews
+	err = execute(env, projects, executionOptions{path: folder, policies: policy.NoPolicies(), clientOptions: rest.DefaultClientOptions()})
+	assert.NilError(t, err)
+	assert.Equal(t, server.Objects("management-zone"), 3)
+
+	options := previewOptions{path: folder, prefix: "[pr-1] ", keep: true, policies: policy.NoPolicies(), clientOptions: rest.DefaultClientOptions()}
+	err = deployPreview(env, projects, options)
+	assert.NilError(t, err)
+	assert.Equal(t, server.Objects("management-zone"), 6)
+
+	client, err := createClient(env, rest.DefaultClientOptions())
+	assert.NilError(t, err)
+	values, err := client.List(apis["management-zone"])
+	assert.NilError(t, err)
+
+	prefixed := 0
+	for _, value := range values {
+		if strings.HasPrefix(value.Name, "[pr-1] ") {
+			prefixed++
+		}
+	}
+	assert.Equal(t, prefixed, 3)
+
+	options.prefix = "[pr-2] "
+	options.keep = false
+	err = deployPreview(env, projects, options)
+	assert.NilError(t, err)
+	assert.Equal(t, server.Objects("management-zone"), 6)
+}
+
+// previewTestProject only implements the methods of a project which previews use
+type previewTestProject struct {
+	project.Project
+	configs    []config.Config
+	assertions []assertion.Assertion
+}
+
+func (p previewTestProject) GetConfigs() []config.Config {
+	return p.configs
+}
+
+func (p previewTestProject) GetAssertions() []assertion.Assertion {
+	return p.assertions
+}
+
+func TestPreviewProjectsPrefixAssertionsAndSkipSettings(t *testing.T) {
+
+	apis := createApis()
+	properties := map[string]map[string]string{"zone": {"name": "zone"}}
+
+	projects := previewProjects([]project.Project{previewTestProject{
+		configs: []config.Config{
+			config.GetMockConfig("zone", "project", nil, properties, apis["management-zone"], "zone.json"),
+			config.GetMockConfig("zone", "project", nil, properties, apis[settingsApi], "zone.json"),
+		},
+		assertions: []assertion.Assertion{{Name: "exists", Api: "management-zone", Object: "zone"}},
+	}}, "[pr-1] ")
+
+	configs := projects[0].GetConfigs()
+	assert.Equal(t, len(configs), 1)
+	assert.Equal(t, configs[0].GetApi().GetId(), "management-zone")
+
+	name, err := configs[0].GetObjectNameForEnvironment(environment.NewEnvironment("dev", "dev", "", "https://dev", "DEV"), nil)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "[pr-1] zone")
+
+	assert.Equal(t, projects[0].GetAssertions()[0].Object, "[pr-1] zone")
+}
@@ -0,0 +1,131 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+)
+
+const (
+	cpuProfileFile     = "cpu.pprof"
+	heapProfileFile    = "heap.pprof"
+	runtimeSummaryFile = "runtime.txt"
+)
+
+// startProfiling starts writing a cpu profile into folder. The returned function stops the cpu profile and writes
+// a heap profile and a summary of the runtime metrics of the run, which can be attached to issues about slow runs
+func startProfiling(folder string) (stop func(), err error) {
+
+	err = os.MkdirAll(folder, 0777)
+	if err != nil {
+		return nil, err
+	}
+
+	cpuProfile, err := os.Create(filepath.Join(folder, cpuProfileFile))
+	if err != nil {
+		return nil, err
+	}
+
+	err = pprof.StartCPUProfile(cpuProfile)
+	if err != nil {
+		cpuProfile.Close()
+		return nil, err
+	}
+
+	started := time.Now()
+	util.Log.Info("Writing profiles to %s", folder)
+
+	return func() {
+		pprof.StopCPUProfile()
+		cpuProfile.Close()
+
+		err := writeHeapProfile(filepath.Join(folder, heapProfileFile))
+		if err != nil {
+			util.Log.Error("Writing heap profile failed: %s", err)
+		}
+
+		summary := runtimeSummary(time.Since(started))
+		util.Log.Info("Runtime summary:\n%s", summary)
+
+		err = ioutil.WriteFile(filepath.Join(folder, runtimeSummaryFile), []byte(summary), 0664)
+		if err != nil {
+			util.Log.Error("Writing runtime summary failed: %s", err)
+		}
+	}, nil
+}
+
+func writeHeapProfile(file string) error {
+
+	heapProfile, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer heapProfile.Close()
+
+	// collect garbage first, so that the profile shows the memory still in use
+	runtime.GC()
+	return pprof.WriteHeapProfile(heapProfile)
+}
+
+// runtimeSummary describes the duration and the memory and garbage collection metrics of the run
+func runtimeSummary(duration time.Duration) string {
+
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	lines := []string{
+		fmt.Sprintf("monaco version:      %s", version.MonitoringAsCode),
+		fmt.Sprintf("go version:          %s %s/%s", runtime.Version(), runtime.GOOS, runtime.GOARCH),
+		fmt.Sprintf("duration:            %s", duration.Round(time.Millisecond)),
+		fmt.Sprintf("cpus (GOMAXPROCS):   %d", runtime.GOMAXPROCS(0)),
+		fmt.Sprintf("goroutines:          %d", runtime.NumGoroutine()),
+		fmt.Sprintf("heap in use:         %s", formatBytes(memory.HeapInuse)),
+		fmt.Sprintf("memory from system:  %s", formatBytes(memory.Sys)),
+		fmt.Sprintf("total allocated:     %s", formatBytes(memory.TotalAlloc)),
+		fmt.Sprintf("allocations:         %d", memory.Mallocs),
+		fmt.Sprintf("garbage collections: %d", memory.NumGC),
+		fmt.Sprintf("gc pause total:      %s", time.Duration(memory.PauseTotalNs).Round(time.Microsecond)),
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func formatBytes(bytes uint64) string {
+
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value, exponent := float64(bytes)/unit, 0
+	for value >= unit && exponent < 3 {
+		value /= unit
+		exponent++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", value, "KMGT"[exponent])
+}
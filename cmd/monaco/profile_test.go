@@ -0,0 +1,58 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestStartProfilingWritesProfilesAndSummary(t *testing.T) {
+
+	folder := filepath.Join(t.TempDir(), "profile")
+
+	stop, err := startProfiling(folder)
+	assert.NilError(t, err)
+	stop()
+
+	for _, file := range []string{cpuProfileFile, heapProfileFile, runtimeSummaryFile} {
+		info, err := os.Stat(filepath.Join(folder, file))
+		assert.NilError(t, err)
+		assert.Assert(t, info.Size() > 0, file)
+	}
+}
+
+func TestRuntimeSummaryContainsMetrics(t *testing.T) {
+
+	summary := runtimeSummary(1500000000)
+
+	assert.Assert(t, strings.Contains(summary, "duration:            1.5s"), summary)
+	assert.Assert(t, strings.Contains(summary, "garbage collections:"), summary)
+}
+
+func TestFormatBytes(t *testing.T) {
+
+	assert.Equal(t, formatBytes(512), "512 B")
+	assert.Equal(t, formatBytes(1536), "1.5 KiB")
+	assert.Equal(t, formatBytes(3*1024*1024*1024), "3.0 GiB")
+}
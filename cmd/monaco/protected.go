@@ -0,0 +1,70 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// protectedObjects returns the objects of all configs marked as protected in the environment, by api and name
+// normalized by matching. Fails if the name of a protected config can't be resolved, as its object could be deleted
+func protectedObjects(projects []project.Project, environment environment.Environment, matching rest.NameMatching) (map[string]bool, error) {
+
+	protected := make(map[string]bool)
+
+	for _, project := range projects {
+		for _, config := range project.GetConfigs() {
+
+			if !config.IsProtected(environment) {
+				continue
+			}
+
+			name, err := config.GetObjectNameForEnvironment(environment, map[string]api.DynatraceEntity{})
+			if err != nil {
+				return nil, fmt.Errorf("name of protected config %s could not be resolved: %s", config.GetFilePath(), err)
+			}
+
+			protected[config.GetApi().GetId()+"/"+matching.Normalize(name)] = true
+		}
+	}
+
+	return protected, nil
+}
+
+// withoutProtected removes the targets of protected objects, which are logged as skipped. Names are compared like
+// the objects of configs are found when deploying
+func withoutProtected(targets []delete.Target, protected map[string]bool, matching rest.NameMatching) []delete.Target {
+
+	kept := make([]delete.Target, 0, len(targets))
+
+	for _, target := range targets {
+		if protected[target.Api.GetId()+"/"+matching.Normalize(target.Name)] {
+			util.Log.Warn("\tSkipping deletion of protected config %s '%s' (%s), pass --allow-protected to delete it", target.Api.GetId(), target.Name, target.Id)
+			continue
+		}
+		kept = append(kept, target)
+	}
+
+	return kept
+}
@@ -0,0 +1,130 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/delete"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+const protectedTestConfigs = `config:
+  - sre: "dashboard.json"
+  - team: "dashboard.json"
+
+sre:
+  - name: "SRE Overview"
+  - protected: "true"
+
+sre.staging:
+  - protected: "false"
+
+team:
+  - name: "Team Overview"
+`
+
+func TestProtectedObjectsContainsProtectedConfigsOfEnvironment(t *testing.T) {
+
+	projects := loadProtectedTestProject(t)
+
+	production := environment.NewEnvironment("production", "production", "", "https://production", "TOKEN")
+	staging := environment.NewEnvironment("staging", "staging", "", "https://staging", "TOKEN")
+
+	protected, err := protectedObjects(projects, production, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, protected, map[string]bool{"dashboard/SRE Overview": true})
+
+	protected, err = protectedObjects(projects, staging, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, protected, map[string]bool{})
+
+	protected, err = protectedObjects(projects, production, rest.NameMatching{CaseInsensitive: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, protected, map[string]bool{"dashboard/sre overview": true})
+}
+
+func TestProtectedObjectsFailsForUnresolvableNames(t *testing.T) {
+
+	projects := loadProtectedTestProjectWithConfigs(t, `config:
+  - sre: "dashboard.json"
+
+sre:
+  - name: "project/dashboard/team.name"
+  - protected: "true"
+`)
+
+	production := environment.NewEnvironment("production", "production", "", "https://production", "TOKEN")
+
+	_, err := protectedObjects(projects, production, rest.NameMatching{})
+	assert.ErrorContains(t, err, "name of protected config")
+}
+
+func TestWithoutProtectedSkipsProtectedTargets(t *testing.T) {
+
+	dashboards := createApis()["dashboard"]
+	zones := createApis()["management-zone"]
+
+	targets := []delete.Target{
+		{Api: dashboards, Id: "1", Name: "SRE Overview"},
+		{Api: dashboards, Id: "2", Name: "Team Overview"},
+		{Api: zones, Id: "3", Name: "SRE Overview"},
+	}
+
+	kept := withoutProtected(targets, map[string]bool{"dashboard/SRE Overview": true}, rest.NameMatching{})
+
+	assert.Equal(t, len(kept), 2)
+	assert.Equal(t, kept[0].Id, "2")
+	assert.Equal(t, kept[1].Id, "3")
+
+	targets[0].Name = "sre overview"
+	assert.Equal(t, len(withoutProtected(targets, map[string]bool{"dashboard/sre overview": true}, rest.NameMatching{CaseInsensitive: true})), 2)
+}
+
+func loadProtectedTestProject(t *testing.T) []project.Project {
+	return loadProtectedTestProjectWithConfigs(t, protectedTestConfigs)
+}
+
+func loadProtectedTestProjectWithConfigs(t *testing.T, configs string) []project.Project {
+
+	folder, err := ioutil.TempDir(".", "monaco-protected-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	dashboardFolder := filepath.Join(folder, "project", "dashboard")
+	err = os.MkdirAll(dashboardFolder, 0777)
+	assert.NilError(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dashboardFolder, "dashboard.json"), []byte(`{"dashboardMetadata": {"name": "{{ .name }}"}}`), 0664)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dashboardFolder, "dashboards.yaml"), []byte(configs), 0664)
+	assert.NilError(t, err)
+
+	projects, err := project.LoadProjectsToDeploy("project", createApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	return projects
+}
@@ -0,0 +1,153 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// danglingReference is a reference of a config to a config which is not part of the projects, together with the
+// yaml file and line it is declared in. The line is 0 if the declaration was not found
+type danglingReference struct {
+	config    config.Config
+	reference config.Reference
+	file      string
+	line      int
+}
+
+// findDanglingReferences resolves the references of all configs of the projects offline, like they are resolved
+// during a deployment, and returns the references to configs which are not part of the projects
+func findDanglingReferences(projects []project.Project, path string, fileReader util.FileReader) []danglingReference {
+
+	known := make(map[string]api.DynatraceEntity)
+	for _, project := range projects {
+		for _, config := range project.GetConfigs() {
+			known[configReference(config, path)] = api.DynatraceEntity{Id: config.GetId(), Name: config.GetId()}
+		}
+	}
+
+	dangling := make([]danglingReference, 0)
+	for _, project := range projects {
+		yamls := yamlFiles(project.GetId(), fileReader)
+
+		for _, config := range project.GetConfigs() {
+			for _, reference := range config.GetUnresolvedReferences(known) {
+				file, line := locateReference(config, reference, yamls, fileReader)
+				dangling = append(dangling, danglingReference{config: config, reference: reference, file: file, line: line})
+			}
+		}
+	}
+
+	return dangling
+}
+
+func logDanglingReferences(dangling []danglingReference) {
+
+	for _, d := range dangling {
+		location := d.file
+		if location == "" {
+			location = d.config.GetFilePath()
+		}
+		if d.line > 0 {
+			location = location + ":" + strconv.Itoa(d.line)
+		}
+
+		util.Log.Error("\t%s: property %s of %s references %s, which is not a config of the deployed projects",
+			location, d.reference.Property, d.reference.Section, d.reference.Value)
+	}
+}
+
+// yamlFiles returns the yaml files within the folder and its sub folders
+func yamlFiles(folder string, fileReader util.FileReader) []string {
+
+	files, err := fileReader.ReadDir(folder)
+	if err != nil {
+		return nil
+	}
+
+	yamls := make([]string, 0)
+	for _, file := range files {
+		name := filepath.Join(folder, file.Name())
+		if file.IsDir() {
+			yamls = append(yamls, yamlFiles(name, fileReader)...)
+		} else if strings.HasSuffix(file.Name(), ".yaml") {
+			yamls = append(yamls, name)
+		}
+	}
+
+	return yamls
+}
+
+// locateReference finds the yaml file and line declaring the reference. Yaml files next to the config's template
+// are searched first, as configs are usually declared in the folder of their template
+func locateReference(config config.Config, reference config.Reference, yamls []string, fileReader util.FileReader) (file string, line int) {
+
+	templateFolder := filepath.Dir(config.GetFilePath())
+
+	candidates := append([]string{}, yamls...)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return filepath.Dir(candidates[i]) == templateFolder && filepath.Dir(candidates[j]) != templateFolder
+	})
+
+	for _, candidate := range candidates {
+		content, err := fileReader.ReadFile(candidate)
+		if err != nil {
+			continue
+		}
+		if line := findPropertyLine(string(content), reference); line > 0 {
+			return candidate, line
+		}
+	}
+
+	return "", 0
+}
+
+// findPropertyLine returns the line of the reference's property within its top level section of the yaml,
+// or 0 if the yaml does not declare it
+func findPropertyLine(content string, reference config.Reference) int {
+
+	inSection := false
+	for i, line := range strings.Split(content, "\n") {
+
+		if isSectionHeader(line) {
+			section := strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `"'`)
+			inSection = section == reference.Section
+			continue
+		}
+
+		if inSection && strings.Contains(line, reference.Property) && strings.Contains(line, reference.Value) {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// isSectionHeader returns whether the yaml line starts a top level section
+func isSectionHeader(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed != "" && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") &&
+		!strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "#")
+}
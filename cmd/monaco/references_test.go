@@ -0,0 +1,100 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+const referencesTestZones = `config:
+  - zone: "zone.json"
+
+zone:
+  - name: "Zone"
+`
+
+const referencesTestProfiles = `config:
+  - profile: "profile.json"
+
+profile:
+  - name: "Profile"
+  - zone: "management-zone/zone.id"
+
+profile.production:
+  - zone: "management-zone/removed.id"
+`
+
+func TestFindDanglingReferencesReportsFileAndLine(t *testing.T) {
+
+	projects := loadReferencesTestProject(t)
+
+	dangling := findDanglingReferences(projects, "", util.NewFileReader())
+
+	assert.Equal(t, len(dangling), 1)
+	assert.DeepEqual(t, dangling[0].reference, config.Reference{
+		Section:  "profile.production",
+		Property: "zone",
+		Value:    filepath.Join("management-zone", "removed.id"),
+	})
+	assert.Equal(t, filepath.Base(dangling[0].file), "profiles.yaml")
+	assert.Equal(t, dangling[0].line, 9)
+}
+
+func TestFindPropertyLineOnlySearchesSectionOfReference(t *testing.T) {
+
+	reference := config.Reference{Section: "profile.production", Property: "zone", Value: "management-zone/removed.id"}
+	assert.Equal(t, findPropertyLine(referencesTestProfiles, reference), 9)
+
+	reference.Section = "profile"
+	assert.Equal(t, findPropertyLine(referencesTestProfiles, reference), 0)
+}
+
+func loadReferencesTestProject(t *testing.T) []project.Project {
+
+	folder, err := ioutil.TempDir(".", "monaco-references-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	files := map[string]string{
+		filepath.Join("management-zone", "zone.json"):      `{"name": "{{ .name }}"}`,
+		filepath.Join("management-zone", "zones.yaml"):     referencesTestZones,
+		filepath.Join("alerting-profile", "profile.json"):  `{"displayName": "{{ .name }}", "mzId": "{{ .zone }}"}`,
+		filepath.Join("alerting-profile", "profiles.yaml"): referencesTestProfiles,
+	}
+	for name, content := range files {
+		file := filepath.Join(folder, "project", name)
+		err = os.MkdirAll(filepath.Dir(file), 0777)
+		assert.NilError(t, err)
+		err = ioutil.WriteFile(file, []byte(content), 0664)
+		assert.NilError(t, err)
+	}
+
+	projects, err := project.LoadProjectsToDeploy("project", createApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	return projects
+}
@@ -0,0 +1,175 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// oldNameParameter holds the name the objects of a renamed config were deployed with. The object with the old name is
+// renamed, instead of creating an object with the new name and leaving the old one behind
+const oldNameParameter = "oldName"
+
+// targetByOldName selects the object deployed with the old name of the config, if the config declares one and no
+// object with the new name exists yet. The object recorded for the old name in the state is preferred
+func targetByOldName(client rest.DynatraceClient, config config.Config, name string, environment environment.Environment,
+	dict map[string]api.DynatraceEntity, deployState *state.State) (deployTarget, error) {
+
+	oldName, err := config.GetPropertyForEnvironment(environment, oldNameParameter, dict)
+	if err != nil || oldName == "" || oldName == name {
+		return deployTarget{}, err
+	}
+
+	a := config.GetApi()
+
+	exists, _, err := client.ExistsByName(a, name)
+
+	var duplicates rest.DuplicateNameError
+	if exists || errors.As(err, &duplicates) {
+		util.Log.Debug("\t\t\t%s was renamed from %s before, its %s can be removed", name, oldName, oldNameParameter)
+		return deployTarget{}, nil
+	}
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	target, err := targetByState(client, a, oldName, environment, deployState)
+	if err != nil {
+		return deployTarget{}, err
+	}
+
+	if !target.exists() {
+		exists, id, err := client.ExistsByName(a, oldName)
+		if err != nil || !exists {
+			return deployTarget{}, err
+		}
+		target = deployTarget{ids: []string{id}}
+	}
+
+	util.Log.Info("\t\t\tRenaming %s (%s) of %s to %s", oldName, target.ids[0], a.GetId(), name)
+	target.renamedFrom = oldName
+	return target, nil
+}
+
+// clearRenames removes the old names from the config yamls of the projects, once all environments were renamed
+func clearRenames(projects []project.Project, fileReader util.FileReader) error {
+
+	lines := make(map[string][]int)
+
+	for _, project := range projects {
+		yamls := yamlFiles(project.GetId(), fileReader)
+
+		for _, config := range project.GetConfigs() {
+			for _, reference := range oldNames(config) {
+				file, line := locateReference(config, reference, yamls, fileReader)
+				if line == 0 {
+					util.Log.Warn("\tCould not find %s of %s, please remove it manually", reference.Property, config.GetFilePath())
+					continue
+				}
+				lines[file] = append(lines[file], line)
+			}
+		}
+	}
+
+	for file, remove := range lines {
+		err := removeLines(file, remove)
+		if err != nil {
+			return err
+		}
+		util.Log.Info("\tRemoved %d old names from %s", len(remove), file)
+	}
+
+	return nil
+}
+
+// oldNames returns the properties holding old names of the config, in all environments and groups
+func oldNames(c config.Config) []config.Reference {
+
+	references := make([]config.Reference, 0)
+	for section, properties := range c.GetProperties() {
+		for property, value := range properties {
+			if property == oldNameParameter || strings.HasPrefix(property, oldNameParameter+".") {
+				references = append(references, config.Reference{Section: section, Property: property, Value: value})
+			}
+		}
+	}
+	return references
+}
+
+// removeLines removes the given lines, counted from 1, from the file. Sections which are empty afterwards are
+// removed as well, as empty sections are invalid
+func removeLines(file string, lines []int) error {
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return err
+	}
+
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+
+	remove := make(map[int]bool, len(lines))
+	for _, line := range lines {
+		remove[line] = true
+	}
+
+	kept := make([]string, 0)
+	for i, line := range strings.Split(string(content), "\n") {
+		if !remove[i+1] {
+			kept = append(kept, line)
+		}
+	}
+
+	result := make([]string, 0, len(kept))
+	for i, line := range kept {
+		if isSectionHeader(line) && sectionIsEmpty(kept[i+1:]) {
+			// the blank line separating the section from the previous one is removed with it
+			if len(result) > 0 && strings.TrimSpace(result[len(result)-1]) == "" {
+				result = result[:len(result)-1]
+			}
+			continue
+		}
+		result = append(result, line)
+	}
+
+	return ioutil.WriteFile(file, []byte(strings.Join(result, "\n")), info.Mode())
+}
+
+// sectionIsEmpty returns whether the lines following a section header end the section without declaring anything
+func sectionIsEmpty(following []string) bool {
+	for _, line := range following {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		return isSectionHeader(line)
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestDeployRenamesObjectWithOldName(t *testing.T) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+	zoneApi := apis["management-zone"]
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	old, err := client.UpsertByName(zoneApi, "old zone", []byte(`{"name": "old zone"}`))
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("dev", "dev", "", server.URL, "DEV")
+	zone := config.GetMockConfig("zone", "project", nil, map[string]map[string]string{
+		"zone": {"name": "new zone", "oldName": "old zone"},
+	}, zoneApi, "zone.json")
+
+	deployState := state.NewState()
+	deployState.Set("dev", "management-zone", "old zone", old.Id)
+
+	options := executionOptions{duplicateNames: failOnDuplicateNames, state: deployState}
+
+	entity, err := deployByIdentity(client, zone, "new zone", testZoneCoordinates, []byte(`{"name": "new zone"}`), false, env, nil, options)
+	assert.NilError(t, err)
+	assert.Equal(t, entity.Id, old.Id)
+	assert.Equal(t, server.Objects("management-zone"), 1)
+
+	_, found := deployState.Get("dev", "management-zone", "old zone")
+	assert.Assert(t, !found)
+
+	values, err := client.List(zoneApi)
+	assert.NilError(t, err)
+	assert.Equal(t, values[0].Name, "new zone")
+
+	// once renamed, the object is deployed by its new name
+	target, err := targetByOldName(client, zone, "new zone", env, nil, deployState)
+	assert.NilError(t, err)
+	assert.Assert(t, !target.exists())
+}
+
+func TestClearRenamesRemovesOldNames(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-rename-")
+	assert.NilError(t, err)
+	t.Cleanup(func() { os.RemoveAll(folder) })
+
+	zoneFolder := filepath.Join(folder, "project", "management-zone")
+	assert.NilError(t, os.MkdirAll(zoneFolder, 0755))
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(zoneFolder, "zone.json"), []byte(`{"name": "{{.name}}"}`), 0644))
+
+	yaml := filepath.Join(zoneFolder, "zone.yaml")
+	assert.NilError(t, ioutil.WriteFile(yaml, []byte(`config:
+  - zone: "zone.json"
+
+zone:
+  - name: "new zone"
+  - oldName: "old zone"
+
+zone.production:
+  - oldName: "old production zone"
+`), 0644))
+
+	projects, err := project.LoadProjectsToDeploy("project", createApis(), folder+string(os.PathSeparator), util.NewFileReader())
+	assert.NilError(t, err)
+
+	err = clearRenames(projects, util.NewFileReader())
+	assert.NilError(t, err)
+
+	content, err := ioutil.ReadFile(yaml)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `config:
+  - zone: "zone.json"
+
+zone:
+  - name: "new zone"
+`)
+}
@@ -0,0 +1,381 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/compare"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// renderDiff is the artifact written by the render-diff command
+type renderDiff struct {
+	Base         string                           `json:"base"`
+	Head         string                           `json:"head"`
+	Environments map[string]environmentRenderDiff `json:"environments"`
+}
+
+// environmentRenderDiff holds the configs added, removed or changed between base and head for one environment.
+// Configs are identified by their coordinates within the projects folder
+type environmentRenderDiff struct {
+	Added   []string                       `json:"added"`
+	Removed []string                       `json:"removed"`
+	Changed map[string][]payloadDifference `json:"changed"`
+}
+
+// payloadDifference is a changed field of a rendered payload. A missing field is represented by an empty value
+type payloadDifference struct {
+	Path string `json:"path"`
+	Base string `json:"base"`
+	Head string `json:"head"`
+}
+
+func (d environmentRenderDiff) hasDifferences() bool {
+	return len(d.Added) > 0 || len(d.Removed) > 0 || len(d.Changed) > 0
+}
+
+// runRenderDiff executes the render-diff command, which renders all configs of two git refs for every environment
+// and writes the payload level differences into a json artifact, e.g. to be posted to a pull request by CI.
+// Returns 0 if the rendered configs are equal, 1 if they differ and -1 on errors
+func runRenderDiff(args []string, fileReader util.FileReader) int {
+
+	var environmentsFile, specificEnvironment, base, head, output, outputFormat, timestamp string
+	var verbose bool
+
+	flagSet := flag.NewFlagSet("render-diff", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Environment (from list) to render the configs for. Defaults to all environments.")
+
+	baseUsage := "Mandatory git ref to compare against, e.g. the target branch of a pull request."
+	flagSet.StringVar(&base, "base", "", baseUsage)
+
+	headUsage := "Git ref containing the changes."
+	flagSet.StringVar(&head, "head", "HEAD", headUsage)
+
+	outputUsage := "File the json diff artifact is written to."
+	flagSet.StringVar(&output, "output", "render-diff.json", outputUsage)
+
+	addOutputFormatFlag(flagSet, &outputFormat)
+
+	addTimestampFlag(flagSet, &timestamp)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if environmentsFile == "" || base == "" {
+		println("Please provide environments yaml with -e/--environments and the git ref to compare against with --base!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+
+	path := "."
+	if flagSet.NArg() > 0 {
+		path = flagSet.Arg(0)
+	}
+
+	setupLoggingForOutput(verbose, outputFormat)
+
+	err = freezeTime(timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	err = validateOutputFormat(outputFormat)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	environments, errorList := environment.LoadEnvironmentList(specificEnvironment, environmentsFile, fileReader)
+	for _, err := range errorList {
+		util.Log.Error("Loading of environments failed: %s", err)
+	}
+	if len(errorList) > 0 {
+		return -1
+	}
+
+	util.Log.Info("Rendering configs of %s and %s...", base, head)
+
+	baseConfigs, err := renderRef(base, path, environments)
+	if err != nil {
+		util.Log.Error("Rendering configs of %s failed: %s", base, err)
+		return -1
+	}
+
+	headConfigs, err := renderRef(head, path, environments)
+	if err != nil {
+		util.Log.Error("Rendering configs of %s failed: %s", head, err)
+		return -1
+	}
+
+	result := renderDiff{Base: base, Head: head, Environments: make(map[string]environmentRenderDiff)}
+	foundDifferences := false
+
+	for _, id := range sortedEnvironmentIds(environments) {
+		diff := diffRenderedConfigs(baseConfigs[id], headConfigs[id])
+		result.Environments[id] = diff
+
+		if diff.hasDifferences() {
+			foundDifferences = true
+			util.Log.Info("%s: %d added, %d removed, %d changed configs", id, len(diff.Added), len(diff.Removed), len(diff.Changed))
+		} else {
+			util.Log.Info("%s: no changes", id)
+		}
+	}
+
+	content, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		util.Log.Error("Writing render diff failed: %s", err)
+		return -1
+	}
+
+	err = ioutil.WriteFile(output, content, 0664)
+	if err != nil {
+		util.Log.Error("Writing render diff to %s failed: %s", output, err)
+		return -1
+	}
+
+	util.Log.Info("Render diff written to %s", output)
+
+	if outputFormat != outputTable {
+		err = writeOutput(os.Stdout, outputFormat, result)
+		if err != nil {
+			util.Log.Error("Writing render diff failed: %s", err)
+			return -1
+		}
+	}
+
+	if foundDifferences {
+		return 1
+	}
+	return 0
+}
+
+// renderRef extracts the given git ref into a temporary folder and renders the configs of the projects found at path
+// for all environments
+func renderRef(ref string, path string, environments map[string]environment.Environment) (map[string]map[string]string, error) {
+
+	prefix, err := git("rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	// the projects are extracted into the working directory, as project folders outside of it (../) are not picked up
+	workingDir, err := ioutil.TempDir(".", "monaco-render-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(workingDir)
+
+	archive, err := git("archive", "--format=tar", ref)
+	if err != nil {
+		return nil, err
+	}
+
+	err = util.UntarToFolder(bytes.NewReader(archive), workingDir)
+	if err != nil {
+		return nil, err
+	}
+
+	projectsPath := filepath.Join(workingDir, strings.TrimSpace(string(prefix)), path) + string(os.PathSeparator)
+
+	projects, err := project.LoadProjectsToDeploy("", createApis(), projectsPath, util.NewFileReader())
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]map[string]string, len(environments))
+	for id, environment := range environments {
+		result[id], err = renderConfigs(projects, environment, projectsPath)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %s", id, err)
+		}
+	}
+
+	return result, nil
+}
+
+func git(args ...string) ([]byte, error) {
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %s %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return out, nil
+}
+
+// renderedConfig is the payload of a config rendered for an environment
+type renderedConfig struct {
+	coordinates string
+	api         string
+	name        string
+	payload     string
+}
+
+// renderConfigs renders the payloads of all configs of the projects for the environment, keyed by their coordinates.
+// References render as the coordinates (id) and the name of the referenced config, so that payloads do not depend on
+// the ids of objects in an environment
+func renderConfigs(projects []project.Project, environment environment.Environment, path string) (map[string]string, error) {
+
+	configs, err := renderConfigList(projects, environment, path)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string]string, len(configs))
+	for _, config := range configs {
+		rendered[config.coordinates] = config.payload
+	}
+
+	return rendered, nil
+}
+
+// renderConfigList renders the configs of the projects like renderConfigs, in the order they are deployed in
+func renderConfigList(projects []project.Project, environment environment.Environment, path string) ([]renderedConfig, error) {
+	return renderConfigListWithIds(projects, environment, path, func(apiId string, name string, coordinates string) string {
+		return "<" + coordinates + ">"
+	})
+}
+
+// renderConfigListWithIds renders the configs like renderConfigList, references render as the id returned by idOf
+// for the api, name and coordinates of the referenced config
+func renderConfigListWithIds(projects []project.Project, environment environment.Environment, path string,
+	idOf func(apiId string, name string, coordinates string) string) ([]renderedConfig, error) {
+
+	dict := make(map[string]api.DynatraceEntity)
+	rendered := make([]renderedConfig, 0)
+
+	for _, project := range projects {
+		for _, config := range project.GetConfigs() {
+
+			if config.IsSkipDeployment(environment) {
+				continue
+			}
+
+			name, err := config.GetObjectNameForEnvironment(environment, dict)
+			if err != nil {
+				return nil, err
+			}
+
+			payload, err := config.GetConfigForEnvironment(environment, dict)
+			if err != nil {
+				return nil, err
+			}
+
+			referenceId := strings.TrimPrefix(config.GetFullQualifiedId(), path)
+			coordinates := filepath.ToSlash(referenceId)
+
+			dict[referenceId] = api.DynatraceEntity{Id: idOf(config.GetApi().GetId(), name, coordinates), Name: name}
+			rendered = append(rendered, renderedConfig{
+				coordinates: coordinates,
+				api:         config.GetApi().GetId(),
+				name:        name,
+				payload:     payload,
+			})
+		}
+	}
+
+	return rendered, nil
+}
+
+// diffRenderedConfigs compares the payloads rendered for base and head. Payloads which are not valid json are
+// compared as a whole
+func diffRenderedConfigs(base map[string]string, head map[string]string) environmentRenderDiff {
+
+	diff := environmentRenderDiff{
+		Added:   []string{},
+		Removed: []string{},
+		Changed: make(map[string][]payloadDifference),
+	}
+
+	for coordinates, basePayload := range base {
+
+		headPayload, found := head[coordinates]
+		if !found {
+			diff.Removed = append(diff.Removed, coordinates)
+			continue
+		}
+
+		if basePayload == headPayload {
+			continue
+		}
+
+		// coordinates are project/api/config, the api decides how payloads are normalized
+		apiId := path.Base(path.Dir(coordinates))
+
+		differences, err := compare.DiffPayloads(apiId, []byte(basePayload), []byte(headPayload), nil)
+		if err != nil {
+			diff.Changed[coordinates] = []payloadDifference{{Base: basePayload, Head: headPayload}}
+			continue
+		}
+
+		if len(differences) == 0 {
+			continue
+		}
+
+		changes := make([]payloadDifference, 0, len(differences))
+		for _, difference := range differences {
+			changes = append(changes, payloadDifference{Path: difference.Path, Base: difference.First, Head: difference.Second})
+		}
+		diff.Changed[coordinates] = changes
+	}
+
+	for coordinates := range head {
+		if _, found := base[coordinates]; !found {
+			diff.Added = append(diff.Added, coordinates)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	return diff
+}
+
+func sortedEnvironmentIds(environments map[string]environment.Environment) []string {
+
+	ids := make([]string, 0, len(environments))
+	for id := range environments {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,79 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestRenderConfigsResolvesReferencesToCoordinates(t *testing.T) {
+
+	environment := environment.NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV")
+
+	path := util.ReplacePathSeparators("test-resources/render-diff-test/")
+	projects, err := project.LoadProjectsToDeploy("project", createApis(), path, util.NewFileReader())
+	assert.NilError(t, err)
+
+	rendered, err := renderConfigs(projects, environment, path)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(rendered), 2)
+	assert.Assert(t, strings.Contains(rendered["project/alerting-profile/profile"], `"managementZoneId": "<project/management-zone/zone>"`))
+}
+
+func TestDiffRenderedConfigs(t *testing.T) {
+
+	base := map[string]string{
+		"project/dashboard/removed": `{}`,
+		"project/dashboard/equal":   `{"name": "a"}`,
+		"project/dashboard/changed": `{"name": "a", "tiles": [1]}`,
+		"project/dashboard/invalid": `{`,
+	}
+	head := map[string]string{
+		"project/dashboard/added":   `{}`,
+		"project/dashboard/equal":   `{"name": "a"}`,
+		"project/dashboard/changed": `{"name": "b", "tiles": [1]}`,
+		"project/dashboard/invalid": `{}`,
+	}
+
+	diff := diffRenderedConfigs(base, head)
+
+	assert.Assert(t, diff.hasDifferences())
+	assert.DeepEqual(t, diff.Added, []string{"project/dashboard/added"})
+	assert.DeepEqual(t, diff.Removed, []string{"project/dashboard/removed"})
+	assert.DeepEqual(t, diff.Changed, map[string][]payloadDifference{
+		"project/dashboard/changed": {{Path: "name", Base: `"a"`, Head: `"b"`}},
+		"project/dashboard/invalid": {{Base: `{`, Head: `{}`}},
+	})
+}
+
+func TestDiffRenderedConfigsWithoutChanges(t *testing.T) {
+
+	configs := map[string]string{"project/dashboard/equal": `{"name": "a"}`}
+
+	diff := diffRenderedConfigs(configs, configs)
+
+	assert.Assert(t, !diff.hasDifferences())
+}
@@ -0,0 +1,61 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// secretEnvNames are the parts of the names of environment variables whose values are never written to replay files
+var secretEnvNames = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "CREDENTIAL", "APIKEY", "API_KEY", "PRIVATE_KEY"}
+
+// redactSecrets makes replay redact the values configs are rendered with from secrets, and from environment variables
+// named like a secret
+func redactSecrets(replay *rest.Replay) {
+
+	// a secrets folder that can't be read fails the run when the configs are rendered
+	if secrets, err := util.Secrets(); err == nil {
+		for _, secret := range secrets {
+			replay.Redact(secret)
+		}
+	}
+
+	for _, variable := range os.Environ() {
+		i := strings.Index(variable, "=")
+		if i < 0 {
+			continue
+		}
+		if isSecretEnvName(variable[:i]) {
+			replay.Redact(variable[i+1:])
+		}
+	}
+}
+
+func isSecretEnvName(name string) bool {
+
+	name = strings.ToUpper(name)
+	for _, part := range secretEnvNames {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
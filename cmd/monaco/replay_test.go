@@ -0,0 +1,58 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestRedactSecretsRedactsEnvValuesNamedLikeSecrets(t *testing.T) {
+
+	util.SetEnv(t, "REPLAY_WEBHOOK_PASSWORD", "webhook-password")
+	defer util.UnsetEnv(t, "REPLAY_WEBHOOK_PASSWORD")
+	util.SetEnv(t, "REPLAY_ZONE_NAME", "zone-name")
+	defer util.UnsetEnv(t, "REPLAY_ZONE_NAME")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"values": []}`))
+	}))
+	defer server.Close()
+
+	replay := rest.NewReplay()
+	redactSecrets(replay)
+
+	client, err := rest.NewDynatraceClientWithOptions(server.URL, "token", rest.ClientOptions{Replay: replay})
+	assert.NilError(t, err)
+	_, err = client.List(api.NewApi("management-zone", "/api/config/v1/managementZones?zone-name=webhook-password"))
+	assert.NilError(t, err)
+
+	content, err := json.Marshal(replay.Bundle())
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(content), "webhook-password"), string(content))
+	assert.Assert(t, strings.Contains(string(content), "zone-name"), string(content))
+}
@@ -0,0 +1,48 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/deploy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// newResultStream returns a listener writing every result as a line of json to the writer, so that tools running
+// monaco can follow a deployment without parsing its logs. Results of environments deployed in parallel are written
+// one after another, failed writes are logged but do not abort the deployment
+func newResultStream(writer io.Writer) deploy.Listener {
+
+	var lock sync.Mutex
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	return func(result deploy.Result) error {
+
+		lock.Lock()
+		defer lock.Unlock()
+
+		err := encoder.Encode(result)
+		if err != nil {
+			util.Log.Warn("\t\tWriting the result of %s failed: %s", result.Config, err)
+		}
+		return nil
+	}
+}
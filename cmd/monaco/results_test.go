@@ -0,0 +1,121 @@
+//go:build unit
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/deploy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func setupResultsDeployment(t *testing.T) (environment.Environment, []project.Project, *fake.Server, func()) {
+
+	apis, err := selectBenchApis([]string{"management-zone"})
+	assert.NilError(t, err)
+
+	folder, err := ioutil.TempDir(".", "monaco-results-")
+	assert.NilError(t, err)
+
+	err = writeBenchProject(folder, apis, 3)
+	assert.NilError(t, err)
+
+	projects, err := project.LoadProjectsToDeploy(benchProject, apis, folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	server := fake.NewServer(apis, 0)
+
+	err = os.Setenv(benchTokenEnv, "bench")
+	assert.NilError(t, err)
+
+	env := environment.NewEnvironment("bench", "bench", "", server.URL, benchTokenEnv)
+	return env, projects, server, func() {
+		server.Close()
+		os.RemoveAll(folder)
+	}
+}
+
+func TestExecuteReportsTheResultOfEveryConfig(t *testing.T) {
+
+	env, projects, _, cleanup := setupResultsDeployment(t)
+	defer cleanup()
+
+	var results []deploy.Result
+	options := executionOptions{
+		policies:      policy.NoPolicies(),
+		clientOptions: rest.DefaultClientOptions(),
+		results: func(result deploy.Result) error {
+			results = append(results, result)
+			return nil
+		},
+	}
+
+	err := execute(env, projects, options)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(results), 3)
+	for _, result := range results {
+		assert.Equal(t, result.Environment, "bench")
+		assert.Equal(t, result.Result, deploy.Deployed)
+		assert.Assert(t, result.Id != "")
+	}
+}
+
+func TestExecuteIsAbortedByTheResultListener(t *testing.T) {
+
+	env, projects, server, cleanup := setupResultsDeployment(t)
+	defer cleanup()
+
+	abort := errors.New("aborted")
+	options := executionOptions{
+		policies:      policy.NoPolicies(),
+		clientOptions: rest.DefaultClientOptions(),
+		results: func(result deploy.Result) error {
+			return abort
+		},
+	}
+
+	err := execute(env, projects, options)
+	assert.Equal(t, err, abort)
+	assert.Equal(t, server.Objects("management-zone"), 1)
+}
+
+func TestResultStreamWritesJsonLines(t *testing.T) {
+
+	buffer := bytes.Buffer{}
+	stream := newResultStream(&buffer)
+
+	assert.NilError(t, stream.Report(deploy.Result{Environment: "bench", Config: "project/api/a", Result: deploy.Skipped}))
+	assert.NilError(t, stream.Report(deploy.Result{Environment: "bench", Config: "project/api/b", Result: deploy.Failed, Error: "<invalid>"}))
+
+	assert.Equal(t, buffer.String(), `{"environment":"bench","config":"project/api/a","result":"skipped"}
+{"environment":"bench","config":"project/api/b","result":"failed","error":"<invalid>"}
+`)
+}
@@ -0,0 +1,290 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// settingsApi is the id of the api Settings 2.0 configs are stored in
+const settingsApi = "settings"
+
+// defaultSettingsScope is used for settings configs without a scope property
+const defaultSettingsScope = "environment"
+
+// settingsProperties holds the properties of a settings config, which define where its value is deployed to
+type settingsProperties struct {
+	schemaId      string
+	schemaVersion string
+	scope         string
+
+	// scopes and the entities matching scopeSelector are the scopes a config fanning out is deployed to,
+	// one object per scope
+	scopes        []string
+	scopeSelector string
+}
+
+// isFanOut checks if the config is deployed to multiple scopes instead of a single one
+func (p settingsProperties) isFanOut() bool {
+	return len(p.scopes) > 0 || p.scopeSelector != ""
+}
+
+func readSettingsProperties(config config.Config, environment environment.Environment, dict map[string]api.DynatraceEntity) (properties settingsProperties, err error) {
+
+	properties.schemaId, err = config.GetPropertyForEnvironment(environment, "schemaId", dict)
+	if err != nil {
+		return properties, err
+	}
+	if properties.schemaId == "" {
+		return properties, fmt.Errorf("settings config %s has no schemaId property", config.GetFilePath())
+	}
+
+	properties.schemaVersion, err = config.GetPropertyForEnvironment(environment, "schemaVersion", dict)
+	if err != nil {
+		return properties, err
+	}
+
+	properties.scope, err = config.GetPropertyForEnvironment(environment, "scope", dict)
+	if err != nil {
+		return properties, err
+	}
+
+	scopes, err := config.GetPropertyForEnvironment(environment, "scopes", dict)
+	if err != nil {
+		return properties, err
+	}
+	properties.scopes = splitList(scopes)
+
+	properties.scopeSelector, err = config.GetPropertyForEnvironment(environment, "scopeSelector", dict)
+	if err != nil {
+		return properties, err
+	}
+
+	if properties.isFanOut() && properties.scope != "" {
+		return properties, fmt.Errorf("settings config %s defines a scope as well as scopes or a scopeSelector", config.GetFilePath())
+	}
+	if properties.scope == "" {
+		properties.scope = defaultSettingsScope
+	}
+
+	return properties, nil
+}
+
+// resolveSettingsScopes returns the scopes the config is deployed to: its single scope, or for configs fanning out
+// the listed scopes followed by the entities matching the scope selector
+func resolveSettingsScopes(client rest.DynatraceClient, config config.Config, properties settingsProperties) ([]string, error) {
+
+	if !properties.isFanOut() {
+		return []string{properties.scope}, nil
+	}
+
+	scopes := append([]string{}, properties.scopes...)
+
+	if properties.scopeSelector != "" {
+		entitiesClient, ok := client.(rest.EntitiesClient)
+		if !ok {
+			return nil, fmt.Errorf("settings config %s can not be deployed, the client does not support entity selectors", config.GetFilePath())
+		}
+
+		ids, err := entitiesClient.ListEntityIds(properties.scopeSelector)
+		if err != nil {
+			return nil, fmt.Errorf("scope selector of %s could not be resolved: %w", config.GetFilePath(), err)
+		}
+
+		for _, id := range ids {
+			if !contains(scopes, id) {
+				scopes = append(scopes, id)
+			}
+		}
+	}
+
+	return scopes, nil
+}
+
+// uploadSettings deploys the value of a settings config to the object with the external id derived from the config
+// coordinates. Values written for an older schema version are migrated to the current version of the schema first,
+// with the given migrations.
+// Configs fanning out are deployed to one object per scope, whose external id is derived from the coordinates and
+// the scope, the objects not existing yet are created in batches. The returned entity has the id of the object of
+// the first scope
+func uploadSettings(client rest.DynatraceClient, config config.Config, value string, name string, coordinates string,
+	environment environment.Environment, dict map[string]api.DynatraceEntity, migrations []settings.FieldMigration) (entity api.DynatraceEntity, err error) {
+
+	settingsClient, ok := client.(rest.SettingsClient)
+	if !ok {
+		return entity, fmt.Errorf("settings config %s can not be deployed, the client does not support settings", config.GetFilePath())
+	}
+
+	properties, err := readSettingsProperties(config, environment, dict)
+	if err != nil {
+		return entity, err
+	}
+
+	payload := json.RawMessage(value)
+	version := properties.schemaVersion
+
+	if version != "" {
+		payload, version, err = migrateSettings(settingsClient, config, payload, properties, migrations)
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	scopes, err := resolveSettingsScopes(client, config, properties)
+	if err != nil {
+		return entity, err
+	}
+	if len(scopes) == 0 {
+		util.Log.Warn("\t\t\tscope selector of %s matches no entities, nothing is deployed", config.GetFilePath())
+		return api.DynatraceEntity{Name: name}, nil
+	}
+
+	objects, err := settingsClient.ListSettings(properties.schemaId)
+	if err != nil {
+		return entity, err
+	}
+
+	// objects of configs fanning out which do not exist yet are created in batches, if the client supports it
+	batchCreator, batched := client.(rest.SettingsBatchCreator)
+	batched = batched && properties.isFanOut()
+
+	ids := make([]string, len(scopes))
+	pending := make([]rest.SettingsObject, 0)
+	pendingScopes := make([]int, 0)
+
+	entity.Name = name
+	for i, scope := range scopes {
+
+		externalId := util.DeterministicUuid(coordinates)
+		if properties.isFanOut() {
+			externalId = util.DeterministicUuid(coordinates + "@" + scope)
+		}
+
+		object := rest.SettingsObject{
+			SchemaId:      properties.schemaId,
+			SchemaVersion: version,
+			Scope:         scope,
+			ExternalId:    externalId,
+			Value:         payload,
+		}
+
+		for _, existing := range objects {
+			if existing.ExternalId == object.ExternalId {
+				object.ObjectId = existing.ObjectId
+				break
+			}
+		}
+
+		if batched && object.ObjectId == "" {
+			pending = append(pending, object)
+			pendingScopes = append(pendingScopes, i)
+			continue
+		}
+
+		objectId, err := settingsClient.UpsertSettings(object)
+		if err != nil {
+			return entity, fmt.Errorf("%w, scope: %s", err, scope)
+		}
+		ids[i] = objectId
+	}
+
+	if len(pending) > 0 {
+		err = createSettingsBatches(batchCreator, config, pending, pendingScopes, ids)
+		if err != nil {
+			return entity, err
+		}
+	}
+
+	entity.Id = ids[0]
+
+	if properties.isFanOut() {
+		util.Log.Debug("\t\t\tDeployed %s to %d scopes", config.GetFilePath(), len(scopes))
+	}
+
+	return entity, nil
+}
+
+// maxReportedSettingsFailures limits how many of the objects which could not be created are listed in the error
+const maxReportedSettingsFailures = 5
+
+// createSettingsBatches creates the objects of a config fanning out in batches and records their ids at the index of
+// their scope. Objects failing to be created do not stop the others, all failures are reported once all batches were
+// sent. The objects created are found by their external ids and updated by the next deployment
+func createSettingsBatches(batchCreator rest.SettingsBatchCreator, config config.Config, objects []rest.SettingsObject, scopeIndices []int, ids []string) error {
+
+	util.Log.Debug("\t\t\tCreating %d settings objects of %s in batches", len(objects), config.GetFilePath())
+
+	failures := make([]string, 0)
+	for i, result := range batchCreator.CreateSettings(objects) {
+		if result.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s, scope: %s", result.Err, objects[i].Scope))
+			continue
+		}
+		ids[scopeIndices[i]] = result.ObjectId
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+
+	reported := failures
+	if len(reported) > maxReportedSettingsFailures {
+		reported = append(reported[:maxReportedSettingsFailures:maxReportedSettingsFailures], fmt.Sprintf("and %d more", len(failures)-maxReportedSettingsFailures))
+	}
+	return fmt.Errorf("%d of %d settings objects of %s could not be created: %s", len(failures), len(objects), config.GetFilePath(), strings.Join(reported, "; "))
+}
+
+// migrateSettings migrates the value to the current version of the schema, if it was written for an older version.
+// Fails if the value contains fields which are not part of the current schema version
+func migrateSettings(settingsClient rest.SettingsClient, config config.Config, payload json.RawMessage, properties settingsProperties, migrations []settings.FieldMigration) (json.RawMessage, string, error) {
+
+	schema, err := settingsClient.GetSchema(properties.schemaId)
+	if err != nil {
+		return payload, properties.schemaVersion, err
+	}
+
+	if settings.CompareVersions(properties.schemaVersion, schema.Version) >= 0 {
+		return payload, properties.schemaVersion, nil
+	}
+
+	result, err := settings.Migrate(payload, properties.schemaVersion, schema, migrations)
+	if err != nil {
+		return payload, properties.schemaVersion, err
+	}
+
+	for _, applied := range result.Applied {
+		util.Log.Info("\t\t\tMigrated %s to version %s of %s: %s", config.GetFilePath(), schema.Version, schema.SchemaId, applied)
+	}
+
+	if len(result.Unmigratable) > 0 {
+		return payload, properties.schemaVersion, fmt.Errorf("%s was written for version %s of %s, but fields %s are not part of the current version %s and can not be migrated automatically",
+			config.GetFilePath(), properties.schemaVersion, schema.SchemaId, strings.Join(result.Unmigratable, ", "), schema.Version)
+	}
+
+	util.Log.Warn("\t\t\t%s was written for version %s of %s, please update it to the current version %s", config.GetFilePath(), properties.schemaVersion, schema.SchemaId, schema.Version)
+
+	return result.Value, schema.Version, nil
+}
@@ -0,0 +1,259 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+// testSettingsClient serves the given objects and schema and records all upserted objects
+type testSettingsClient struct {
+	rest.DynatraceClient
+	objects  []rest.SettingsObject
+	schema   rest.SettingsSchema
+	upserted []rest.SettingsObject
+}
+
+func (c *testSettingsClient) ListSettings(schemaId string) ([]rest.SettingsObject, error) {
+	return c.objects, nil
+}
+
+func (c *testSettingsClient) GetSchema(schemaId string) (rest.SettingsSchema, error) {
+	return c.schema, nil
+}
+
+func (c *testSettingsClient) UpsertSettings(object rest.SettingsObject) (string, error) {
+	c.upserted = append(c.upserted, object)
+	if object.ObjectId == "" {
+		return "created", nil
+	}
+	return object.ObjectId, nil
+}
+
+var testSettingsSchema = rest.SettingsSchema{
+	SchemaId: "builtin:test",
+	Version:  "1.3",
+	Properties: map[string]json.RawMessage{
+		"enabled":   json.RawMessage(`{}`),
+		"threshold": json.RawMessage(`{}`),
+	},
+}
+
+func newTestSettingsConfig(mockCtrl *gomock.Controller, schemaVersion string) config.Config {
+	return newTestFanOutSettingsConfig(mockCtrl, schemaVersion, "", "")
+}
+
+func newTestFanOutSettingsConfig(mockCtrl *gomock.Controller, schemaVersion string, scopes string, scopeSelector string) config.Config {
+
+	settingsConfig := config.NewMockConfig(mockCtrl)
+	settingsConfig.EXPECT().GetFilePath().Return("project/settings/test.json").AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "schemaId", gomock.Any()).Return("builtin:test", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "schemaVersion", gomock.Any()).Return(schemaVersion, nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scope", gomock.Any()).Return("", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scopes", gomock.Any()).Return(scopes, nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scopeSelector", gomock.Any()).Return(scopeSelector, nil).AnyTimes()
+
+	return settingsConfig
+}
+
+func TestUploadSettingsUpdatesObjectWithExternalId(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	externalId := util.DeterministicUuid(testCoordinates)
+	client := &testSettingsClient{objects: []rest.SettingsObject{{ObjectId: "other"}, {ObjectId: "existing", ExternalId: externalId}}}
+
+	entity, err := uploadSettings(client, newTestSettingsConfig(mockCtrl, ""), `{"enabled": true}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, "existing", entity.Id)
+
+	assert.Equal(t, 1, len(client.upserted))
+	assert.Equal(t, "existing", client.upserted[0].ObjectId)
+	assert.Equal(t, "environment", client.upserted[0].Scope)
+	assert.Equal(t, externalId, client.upserted[0].ExternalId)
+}
+
+func TestUploadSettingsMigratesOlderSchemaVersions(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	migrations := []settings.FieldMigration{{SchemaId: "builtin:test", Version: "1.2", Field: "limit", RenamedTo: "threshold"}}
+
+	client := &testSettingsClient{schema: testSettingsSchema}
+
+	_, err := uploadSettings(client, newTestSettingsConfig(mockCtrl, "1.1"), `{"enabled": true, "limit": 3}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, migrations)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 1, len(client.upserted))
+	assert.Equal(t, "1.3", client.upserted[0].SchemaVersion)
+	assert.Equal(t, `{"enabled":true,"threshold":3}`, string(client.upserted[0].Value))
+}
+
+func TestUploadSettingsReportsUnmigratableFields(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testSettingsClient{schema: testSettingsSchema}
+
+	_, err := uploadSettings(client, newTestSettingsConfig(mockCtrl, "1.1"), `{"enabled": true, "limit": 3}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.Error(t, err, "project/settings/test.json was written for version 1.1 of builtin:test, but fields limit are not part of the current version 1.3 and can not be migrated automatically")
+	assert.Equal(t, 0, len(client.upserted))
+}
+
+// testEntitiesClient is a testSettingsClient resolving all entity selectors to the given ids
+type testEntitiesClient struct {
+	testSettingsClient
+	ids       []string
+	selectors []string
+}
+
+func (c *testEntitiesClient) ListEntityIds(entitySelector string) ([]string, error) {
+	c.selectors = append(c.selectors, entitySelector)
+	return c.ids, nil
+}
+
+func TestUploadSettingsFansOutToScopes(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	existing := util.DeterministicUuid(testCoordinates + "@HOST_GROUP-2")
+	client := &testEntitiesClient{
+		testSettingsClient: testSettingsClient{objects: []rest.SettingsObject{{ObjectId: "existing", ExternalId: existing}}},
+		ids:                []string{"HOST_GROUP-2", "HOST_GROUP-3"},
+	}
+
+	settingsConfig := newTestFanOutSettingsConfig(mockCtrl, "", "HOST_GROUP-1, HOST_GROUP-2", `type("HOST_GROUP")`)
+
+	entity, err := uploadSettings(client, settingsConfig, `{"enabled": true}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, "created", entity.Id)
+	assert.DeepEqual(t, []string{`type("HOST_GROUP")`}, client.selectors)
+
+	assert.Equal(t, 3, len(client.upserted))
+	for i, scope := range []string{"HOST_GROUP-1", "HOST_GROUP-2", "HOST_GROUP-3"} {
+		assert.Equal(t, scope, client.upserted[i].Scope)
+		assert.Equal(t, util.DeterministicUuid(testCoordinates+"@"+scope), client.upserted[i].ExternalId)
+	}
+	assert.Equal(t, "existing", client.upserted[1].ObjectId)
+}
+
+// testBatchSettingsClient is a testEntitiesClient creating objects in batches, objects of the failing scopes are
+// not created
+type testBatchSettingsClient struct {
+	testEntitiesClient
+	batches [][]rest.SettingsObject
+	failing []string
+}
+
+func (c *testBatchSettingsClient) CreateSettings(objects []rest.SettingsObject) []rest.SettingsCreateResult {
+
+	c.batches = append(c.batches, objects)
+
+	results := make([]rest.SettingsCreateResult, len(objects))
+	for i, object := range objects {
+		if contains(c.failing, object.Scope) {
+			results[i].Err = fmt.Errorf("failed to create settings object of %s (code 400)", object.SchemaId)
+		} else {
+			results[i].ObjectId = "created-" + object.Scope
+		}
+	}
+	return results
+}
+
+func TestUploadSettingsCreatesObjectsOfFanOutInBatches(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	existing := util.DeterministicUuid(testCoordinates + "@HOST_GROUP-1")
+	client := &testBatchSettingsClient{}
+	client.objects = []rest.SettingsObject{{ObjectId: "existing", ExternalId: existing}}
+
+	settingsConfig := newTestFanOutSettingsConfig(mockCtrl, "", "HOST_GROUP-1, HOST_GROUP-2, HOST_GROUP-3", "")
+
+	entity, err := uploadSettings(client, settingsConfig, `{"enabled": true}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, "existing", entity.Id)
+
+	assert.Equal(t, 1, len(client.upserted))
+	assert.Equal(t, "existing", client.upserted[0].ObjectId)
+
+	assert.Equal(t, 1, len(client.batches))
+	assert.Equal(t, 2, len(client.batches[0]))
+	assert.Equal(t, "HOST_GROUP-2", client.batches[0][0].Scope)
+	assert.Equal(t, util.DeterministicUuid(testCoordinates+"@HOST_GROUP-3"), client.batches[0][1].ExternalId)
+}
+
+func TestUploadSettingsReportsAllObjectsFailingInBatches(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testBatchSettingsClient{failing: []string{"HOST_GROUP-1", "HOST_GROUP-3"}}
+	settingsConfig := newTestFanOutSettingsConfig(mockCtrl, "", "HOST_GROUP-1, HOST_GROUP-2, HOST_GROUP-3", "")
+
+	_, err := uploadSettings(client, settingsConfig, `{"enabled": true}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.Error(t, err, "2 of 3 settings objects of project/settings/test.json could not be created: "+
+		"failed to create settings object of builtin:test (code 400), scope: HOST_GROUP-1; "+
+		"failed to create settings object of builtin:test (code 400), scope: HOST_GROUP-3")
+}
+
+func TestUploadSettingsDeploysNothingIfSelectorMatchesNoEntities(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testEntitiesClient{}
+
+	entity, err := uploadSettings(client, newTestFanOutSettingsConfig(mockCtrl, "", "", `type("HOST_GROUP")`), `{"enabled": true}`, "test", testCoordinates, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, "", entity.Id)
+	assert.Equal(t, 0, len(client.upserted))
+}
+
+func TestReadSettingsPropertiesRejectsScopeAndScopes(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	settingsConfig := config.NewMockConfig(mockCtrl)
+	settingsConfig.EXPECT().GetFilePath().Return("project/settings/test.json").AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "schemaId", gomock.Any()).Return("builtin:test", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "schemaVersion", gomock.Any()).Return("", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scope", gomock.Any()).Return("HOST-1", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scopes", gomock.Any()).Return("HOST-2", nil).AnyTimes()
+	settingsConfig.EXPECT().GetPropertyForEnvironment(gomock.Any(), "scopeSelector", gomock.Any()).Return("", nil).AnyTimes()
+
+	_, err := readSettingsProperties(settingsConfig, testDuplicatesEnvironment, map[string]api.DynatraceEntity{})
+	assert.ErrorContains(t, err, "defines a scope as well as scopes or a scopeSelector")
+}
@@ -0,0 +1,107 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// settingsValidation collects the settings configs of a dry run, which the environment validates in batches,
+// so that dry runs of thousands of configs do not need a request per config. A nil validation validates nothing
+type settingsValidation struct {
+	client  rest.DynatraceClient
+	objects []rest.SettingsObject
+
+	// configs holds the file of the config of each object
+	configs []string
+}
+
+func newSettingsValidation(client rest.DynatraceClient) *settingsValidation {
+	return &settingsValidation{client: client}
+}
+
+// add renders the settings config for the environment and queues it for validation. Configs fanning out to
+// multiple scopes are validated for their first scope only, as the value is the same for all scopes
+func (v *settingsValidation) add(config config.Config, environment environment.Environment, dict map[string]api.DynatraceEntity) error {
+
+	if v == nil {
+		return nil
+	}
+
+	value, err := config.GetConfigForEnvironment(environment, dict)
+	if err != nil {
+		return err
+	}
+
+	properties, err := readSettingsProperties(config, environment, dict)
+	if err != nil {
+		return err
+	}
+
+	scopes, err := resolveSettingsScopes(v.client, config, properties)
+	if err != nil {
+		return err
+	}
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	v.objects = append(v.objects, rest.SettingsObject{
+		SchemaId:      properties.schemaId,
+		SchemaVersion: properties.schemaVersion,
+		Scope:         scopes[0],
+		Value:         json.RawMessage(value),
+	})
+	v.configs = append(v.configs, config.GetFilePath())
+	return nil
+}
+
+// validate sends the queued objects to the environment, which the client splits into batches, and returns the
+// configs the environment rejected, with the reason
+func (v *settingsValidation) validate() ([]error, error) {
+
+	if v == nil || len(v.objects) == 0 {
+		return nil, nil
+	}
+
+	validator, ok := v.client.(rest.SettingsValidator)
+	if !ok {
+		return nil, fmt.Errorf("settings configs can not be validated, the client does not support validating settings")
+	}
+
+	util.Log.Debug("\t\tValidating %d settings objects", len(v.objects))
+	results, err := validator.ValidateSettings(v.objects)
+	if err != nil {
+		return nil, err
+	}
+
+	rejected := make([]error, 0)
+	for i, result := range results {
+		if result != nil {
+			rejected = append(rejected, fmt.Errorf("%s: %w", v.configs[i], result))
+		}
+	}
+
+	return rejected, nil
+}
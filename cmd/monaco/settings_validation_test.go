@@ -0,0 +1,96 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+// testSettingsValidator rejects objects whose value is invalid and records the number of objects of every call
+type testSettingsValidator struct {
+	rest.DynatraceClient
+	batches []int
+}
+
+func (c *testSettingsValidator) ValidateSettings(objects []rest.SettingsObject) ([]error, error) {
+
+	c.batches = append(c.batches, len(objects))
+
+	rejected := make([]error, len(objects))
+	for i, object := range objects {
+		if string(object.Value) == `{"enabled": "yes"}` {
+			rejected[i] = fmt.Errorf("enabled: must be a boolean")
+		}
+	}
+	return rejected, nil
+}
+
+func TestSettingsValidationValidatesAllConfigsAtOnce(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	valid := newTestSettingsConfig(mockCtrl, "")
+	valid.(*config.MockConfig).EXPECT().GetConfigForEnvironment(gomock.Any(), gomock.Any()).Return(`{"enabled": true}`, nil).AnyTimes()
+
+	client := &testSettingsValidator{}
+	validation := newSettingsValidation(client)
+	for i := 0; i < 201; i++ {
+		assert.NilError(t, validation.add(valid, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}))
+	}
+
+	rejected, err := validation.validate()
+	assert.NilError(t, err)
+	assert.Equal(t, len(rejected), 0)
+	assert.DeepEqual(t, client.batches, []int{201})
+}
+
+func TestSettingsValidationReportsRejectedConfigs(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	invalid := newTestSettingsConfig(mockCtrl, "")
+	invalid.(*config.MockConfig).EXPECT().GetConfigForEnvironment(gomock.Any(), gomock.Any()).Return(`{"enabled": "yes"}`, nil).AnyTimes()
+
+	validation := newSettingsValidation(&testSettingsValidator{})
+	assert.NilError(t, validation.add(invalid, testDuplicatesEnvironment, map[string]api.DynatraceEntity{}))
+
+	rejected, err := validation.validate()
+	assert.NilError(t, err)
+	assert.Equal(t, len(rejected), 1)
+	assert.ErrorContains(t, rejected[0], "project/settings/test.json: enabled: must be a boolean")
+}
+
+func TestNilSettingsValidationValidatesNothing(t *testing.T) {
+
+	var validation *settingsValidation
+
+	assert.NilError(t, validation.add(nil, testDuplicatesEnvironment, nil))
+	rejected, err := validation.validate()
+	assert.NilError(t, err)
+	assert.Equal(t, len(rejected), 0)
+}
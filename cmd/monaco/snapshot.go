@@ -0,0 +1,230 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/download"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/lint"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/policy"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// snapshotProject is the name of the project folder within a snapshot archive
+const snapshotProject = "snapshot"
+
+// runSnapshot executes the snapshot command, which downloads all supported configs of an environment into
+// a timestamped zip archive. Returns 0 on success and -1 on errors
+func runSnapshot(args []string, fileReader util.FileReader) (statusCode int) {
+
+	var environmentsFile, specificEnvironment, outputFolder, timestamp string
+	var verbose bool
+	var clientFlags clientFlags
+
+	flagSet := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Environment (from list) to snapshot.")
+
+	outputFolderUsage := "Folder the snapshot archive is written to. Defaults to the current working dir."
+	flagSet.StringVar(&outputFolder, "output-folder", ".", outputFolderUsage)
+
+	addClientFlags(flagSet, &clientFlags)
+	addTimestampFlag(flagSet, &timestamp)
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return -1
+	}
+
+	err = freezeTime(timestamp)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	clientOptions, err := clientFlags.apply(rest.DefaultClientOptions(), fileReader)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+	defer func() {
+		if !clientFlags.saveNameCache(clientOptions) {
+			statusCode = -1
+		}
+	}()
+
+	client, err := createClientForEnvironment(map[string]environment.Environment{env.GetId(): env}, env.GetId(), clientOptions)
+	if err != nil {
+		util.Log.Error("%s", err)
+		return -1
+	}
+
+	workingDir, err := ioutil.TempDir("", "monaco-snapshot")
+	if err != nil {
+		util.Log.Error("Could not create temporary folder: %s", err)
+		return -1
+	}
+	defer os.RemoveAll(workingDir)
+
+	util.Log.Info("Creating snapshot of environment %s...", env.GetId())
+
+	count, err := download.DownloadConfigs(createApis(), client, filepath.Join(workingDir, snapshotProject), download.Options{})
+	if err != nil {
+		util.Log.Error("Snapshot of %s failed: %s", env.GetId(), err)
+		return -1
+	}
+
+	archive := filepath.Join(outputFolder, "snapshot-"+env.GetId()+"-"+util.Now().Format("20060102-150405")+".zip")
+
+	err = util.ZipFolder(workingDir, archive)
+	if err != nil {
+		util.Log.Error("Writing snapshot archive %s failed: %s", archive, err)
+		return -1
+	}
+
+	util.Log.Info("Snapshot of %d configs written to %s", count, archive)
+	util.Log.Info("Restore it with: monaco restore -e=%s -se=%s %s", environmentsFile, env.GetId(), archive)
+
+	return 0
+}
+
+// runRestore executes the restore command, which deploys all configs of a snapshot archive to an environment.
+// Returns 0 on success and -1 on errors
+func runRestore(args []string, fileReader util.FileReader) int {
+
+	var environmentsFile, specificEnvironment string
+	var verbose, dryRun bool
+
+	flagSet := flag.NewFlagSet("restore", flag.ExitOnError)
+	addEnvironmentFlags(flagSet, &environmentsFile, &specificEnvironment, &verbose, "Environment (from list) to restore the snapshot to.")
+
+	dryRunUsage := "Set dry-run flag to just validate the snapshot instead of restoring it."
+	flagSet.BoolVar(&dryRun, "dry-run", false, dryRunUsage)
+	flagSet.BoolVar(&dryRun, "d", false, dryRunUsage+" (shorthand)")
+
+	err := flagSet.Parse(args[1:])
+	if err != nil {
+		util.Log.Error("could not parse flags: %s", err)
+		return -1
+	}
+
+	if flagSet.NArg() != 1 {
+		println("Please provide the snapshot archive to restore!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+	archive := flagSet.Arg(0)
+
+	env, ok := loadSingleEnvironment(flagSet, environmentsFile, specificEnvironment, verbose, fileReader)
+	if !ok {
+		return -1
+	}
+
+	workingDir, err := ioutil.TempDir("", "monaco-restore")
+	if err != nil {
+		util.Log.Error("Could not create temporary folder: %s", err)
+		return -1
+	}
+	defer os.RemoveAll(workingDir)
+
+	err = util.UnzipToFolder(archive, workingDir)
+	if err != nil {
+		util.Log.Error("Reading snapshot archive %s failed: %s", archive, err)
+		return -1
+	}
+
+	path := workingDir + string(os.PathSeparator)
+
+	projects, err := project.LoadProjectsToDeploy("", createApis(), path, util.NewFileReader())
+	if err != nil {
+		util.Log.Error("Loading snapshot %s failed: %s", archive, err)
+		return -1
+	}
+
+	util.Log.Info("Restoring snapshot %s to environment %s...", archive, env.GetId())
+
+	options := executionOptions{
+		dryRun:        dryRun,
+		path:          path,
+		policies:      policy.NoPolicies(),
+		linter:        lint.NoRules(),
+		clientOptions: rest.DefaultClientOptions(),
+	}
+
+	err = execute(env, projects, options)
+	if err != nil {
+		util.Log.Error("Restore of %s failed with error %s", env.GetId(), err)
+		return -1
+	}
+
+	util.Log.Info("Restore finished without errors")
+	return 0
+}
+
+func addEnvironmentFlags(flagSet *flag.FlagSet, environmentsFile *string, specificEnvironment *string, verbose *bool, specificEnvironmentUsage string) {
+
+	shorthand := " (shorthand)"
+
+	environmentsUsage := "Mandatory yaml file containing environments."
+	flagSet.StringVar(environmentsFile, "environments", "", environmentsUsage)
+	flagSet.StringVar(environmentsFile, "e", "", environmentsUsage+shorthand)
+
+	flagSet.StringVar(specificEnvironment, "specific-environment", "", specificEnvironmentUsage)
+	flagSet.StringVar(specificEnvironment, "se", "", specificEnvironmentUsage+shorthand)
+
+	verboseUsage := "Set verbose flag to enable debug logging."
+	flagSet.BoolVar(verbose, "verbose", false, verboseUsage)
+	flagSet.BoolVar(verbose, "v", false, verboseUsage+shorthand)
+}
+
+// loadSingleEnvironment sets up logging and loads the environment a command operates on.
+// Shows the usage of the command and exits if no environment has been specified
+func loadSingleEnvironment(flagSet *flag.FlagSet, environmentsFile string, specificEnvironment string, verbose bool, fileReader util.FileReader) (environment.Environment, bool) {
+
+	if environmentsFile == "" || specificEnvironment == "" {
+		println("Please provide environments yaml with -e/--environments and the environment with -se/--specific-environment!")
+		flagSet.Usage()
+		os.Exit(1)
+	}
+
+	err := util.SetupLogging(verbose)
+	if err != nil {
+		util.Log.Error("Error writing log file: %s", err.Error())
+	}
+
+	environments, errorList := environment.LoadEnvironmentList(specificEnvironment, environmentsFile, fileReader)
+	if len(errorList) > 0 {
+		for _, err := range errorList {
+			util.Log.Error("Loading of environments failed: %s", err)
+		}
+		return nil, false
+	}
+
+	return environments[specificEnvironment], true
+}
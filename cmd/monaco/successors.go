@@ -0,0 +1,235 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// successorMode defines whether configs of classic apis with a Settings 2.0 successor are deployed to the successor
+type successorMode string
+
+const (
+	// deployClassic deploys the configs to their classic apis
+	deployClassic successorMode = "classic"
+
+	// deploySuccessorIfOffered deploys the configs to their successors in environments offering the successor schema,
+	// and to their classic apis in the others
+	deploySuccessorIfOffered successorMode = "auto"
+
+	// deploySuccessor deploys the configs to their successors
+	deploySuccessor successorMode = "settings"
+)
+
+func parseSuccessorMode(value string) (successorMode, error) {
+
+	switch mode := successorMode(value); mode {
+	case deployClassic, deploySuccessorIfOffered, deploySuccessor:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown successor mode %s, use one of %s, %s or %s", value, deployClassic, deploySuccessorIfOffered, deploySuccessor)
+	}
+}
+
+// runConvert executes the convert command, which converts the payload of a config of a classic api rendered for an
+// environment to a value of its Settings 2.0 successor, e.g. to migrate the config to a settings config.
+// Returns 0 on success and -1 on errors
+func runConvert(args []string, fileReader util.FileReader) int {
+
+	explanation, _, ok := explainFromArgs("convert", args, fileReader)
+	if !ok {
+		return -1
+	}
+
+	parts := strings.Split(explanation.Config, "/")
+	apiId := parts[len(parts)-2]
+
+	successor, found := settings.SuccessorOf(apiId)
+	if !found {
+		util.Log.Error("Configs of %s have no Settings 2.0 successor", apiId)
+		return -1
+	}
+
+	result, err := successor.Convert([]byte(explanation.Payload))
+	if err != nil {
+		util.Log.Error("Converting %s failed: %s", explanation.Config, err)
+		return -1
+	}
+
+	for _, field := range result.Unconvertible {
+		util.Log.Warn("\tField %s has no equivalent in %s and was dropped", field, successor.SchemaId)
+	}
+
+	var value bytes.Buffer
+	err = json.Indent(&value, result.Value, "", "  ")
+	if err != nil {
+		util.Log.Error("Converting %s failed: %s", explanation.Config, err)
+		return -1
+	}
+
+	util.Log.Info("%s converted to a value of %s, deploy it as settings config with schemaId %s:", explanation.Config, successor.SchemaId, successor.SchemaId)
+	fmt.Println(value.String())
+	return 0
+}
+
+// successorFor returns the Settings 2.0 successor the config is deployed to, or false if it is deployed to its
+// classic api
+func successorFor(config config.Config, options executionOptions) (settings.Successor, bool, error) {
+
+	if options.successors == "" || options.successors == deployClassic {
+		return settings.Successor{}, false, nil
+	}
+
+	successor, found := settings.SuccessorOf(config.GetApi().GetId())
+	if !found {
+		return successor, false, nil
+	}
+
+	if options.successors == deploySuccessor {
+		return successor, true, nil
+	}
+
+	offered, err := options.available.offersSchema(successor.SchemaId)
+	return successor, offered, err
+}
+
+// convertToSuccessor converts the classic payload of the config to a value of its successor schema. Fails if the
+// payload has fields without equivalent in the schema, as dropping them would change the deployed config
+func convertToSuccessor(config config.Config, successor settings.Successor, jsonString string) (settings.ConversionResult, error) {
+
+	result, err := successor.Convert([]byte(jsonString))
+	if err != nil {
+		return result, fmt.Errorf("%s, responsible config: %s", err, config.GetFilePath())
+	}
+
+	if len(result.Unconvertible) > 0 {
+		return result, fmt.Errorf("%s can not be converted to %s, fields %s have no equivalent in the schema",
+			config.GetFilePath(), successor.SchemaId, strings.Join(result.Unconvertible, ", "))
+	}
+
+	return result, nil
+}
+
+// checkSuccessorReferences fails configs deployed to classic apis referencing configs deployed to a successor, as
+// classic apis don't accept the ids of settings objects, e.g. notifications referencing alerting profiles.
+// deployedToSuccessors holds the references of the configs deployed to a successor
+func checkSuccessorReferences(config config.Config, dict map[string]api.DynatraceEntity, deployedToSuccessors map[string]bool) error {
+
+	if len(deployedToSuccessors) == 0 {
+		return nil
+	}
+
+	classic := make(map[string]api.DynatraceEntity, len(dict))
+	for reference, entity := range dict {
+		if !deployedToSuccessors[reference] {
+			classic[reference] = entity
+		}
+	}
+
+	unresolved := make(map[string]bool)
+	for _, reference := range config.GetUnresolvedReferences(dict) {
+		unresolved[reference.Section+"."+reference.Property] = true
+	}
+
+	for _, reference := range config.GetUnresolvedReferences(classic) {
+		if !unresolved[reference.Section+"."+reference.Property] {
+			return fmt.Errorf("%s of %s references %s, which is deployed to its Settings 2.0 successor. Classic apis don't accept the ids "+
+				"of settings objects, deploy it to its classic api with --successors=%s", reference.Property, config.GetFilePath(), reference.Value, deployClassic)
+		}
+	}
+	return nil
+}
+
+// validateSuccessorConversion checks that the config can be converted to its successor, if it may be deployed to it
+func validateSuccessorConversion(config config.Config, dict map[string]api.DynatraceEntity, environment environment.Environment, options executionOptions) error {
+
+	successor, converted, err := successorFor(config, options)
+	if err != nil || !converted {
+		return err
+	}
+
+	jsonString, err := config.GetConfigForEnvironment(environment, dict)
+	if err != nil {
+		return err
+	}
+
+	_, err = convertToSuccessor(config, successor, jsonString)
+	return err
+}
+
+// uploadToSuccessor converts the classic payload of the config and deploys it to the settings object of the
+// successor schema with the external id derived from the config coordinates. Objects without external id named like
+// the config, e.g. created in the web ui or migrated from the classic api by Dynatrace, are adopted instead of creating
+// a duplicate
+func uploadToSuccessor(client rest.DynatraceClient, config config.Config, successor settings.Successor, jsonString string,
+	name string, coordinates string) (entity api.DynatraceEntity, err error) {
+
+	settingsClient, ok := client.(rest.SettingsClient)
+	if !ok {
+		return entity, fmt.Errorf("%s can not be deployed to %s, the client does not support settings", config.GetFilePath(), successor.SchemaId)
+	}
+
+	result, err := convertToSuccessor(config, successor, jsonString)
+	if err != nil {
+		return entity, err
+	}
+
+	objects, err := settingsClient.ListSettings(successor.SchemaId)
+	if err != nil {
+		return entity, err
+	}
+
+	object := rest.SettingsObject{
+		SchemaId:   successor.SchemaId,
+		Scope:      defaultSettingsScope,
+		ExternalId: util.DeterministicUuid(coordinates),
+		Value:      result.Value,
+	}
+	for _, existing := range objects {
+		if existing.ExternalId == object.ExternalId {
+			object.ObjectId = existing.ObjectId
+			break
+		}
+	}
+	if object.ObjectId == "" {
+		for _, existing := range objects {
+			if existing.ExternalId == "" && successor.NameOf(existing.Value) == name {
+				util.Log.Debug("\t\t\tAdopting object %s of %s named %s", existing.ObjectId, successor.SchemaId, name)
+				object.ObjectId = existing.ObjectId
+				break
+			}
+		}
+	}
+
+	objectId, err := settingsClient.UpsertSettings(object)
+	if err != nil {
+		return entity, fmt.Errorf("%w, responsible config: %s", err, config.GetFilePath())
+	}
+
+	util.Log.Debug("\t\t\tDeployed %s to its successor %s", config.GetFilePath(), successor.SchemaId)
+	return api.DynatraceEntity{Id: objectId, Name: name}, nil
+}
@@ -0,0 +1,171 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+const testMaintenanceWindow = `{"name": "Patching", "type": "PLANNED", "suppression": "DONT_DETECT_PROBLEMS",
+	"schedule": {"recurrenceType": "ONCE", "start": "2021-05-01 10:00", "end": "2021-05-01 12:00", "zoneId": "UTC"}}`
+
+const testMaintenanceWindowSchema = "builtin:alerting.maintenance-window"
+
+func newTestMaintenanceWindowConfig(mockCtrl *gomock.Controller) config.Config {
+
+	windowConfig := config.NewMockConfig(mockCtrl)
+	windowConfig.EXPECT().GetApi().Return(api.NewApis()["maintenance-window"]).AnyTimes()
+	windowConfig.EXPECT().GetFilePath().Return("project/maintenance-window/patching.json").AnyTimes()
+
+	return windowConfig
+}
+
+func TestParseSuccessorMode(t *testing.T) {
+
+	for _, value := range []string{"classic", "auto", "settings"} {
+		mode, err := parseSuccessorMode(value)
+		assert.NilError(t, err)
+		assert.Equal(t, string(mode), value)
+	}
+
+	_, err := parseSuccessorMode("both")
+	assert.Error(t, err, "unknown successor mode both, use one of classic, auto or settings")
+}
+
+func TestSuccessorForDependsOnModeAndEnvironment(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	windowConfig := newTestMaintenanceWindowConfig(mockCtrl)
+
+	_, converted, err := successorFor(windowConfig, executionOptions{successors: deployClassic})
+	assert.NilError(t, err)
+	assert.Assert(t, !converted)
+
+	successor, converted, err := successorFor(windowConfig, executionOptions{successors: deploySuccessor})
+	assert.NilError(t, err)
+	assert.Assert(t, converted)
+	assert.Equal(t, successor.SchemaId, testMaintenanceWindowSchema)
+
+	legacy := &testCapabilitiesClient{missingSchemas: map[string]bool{testMaintenanceWindowSchema: true}}
+	options := executionOptions{successors: deploySuccessorIfOffered, available: newCapabilities(legacy)}
+	for i := 0; i < 2; i++ {
+		_, converted, err = successorFor(windowConfig, options)
+		assert.NilError(t, err)
+		assert.Assert(t, !converted)
+	}
+	assert.Equal(t, legacy.probes, 1)
+
+	current := &testCapabilitiesClient{}
+	_, converted, err = successorFor(windowConfig, executionOptions{successors: deploySuccessorIfOffered, available: newCapabilities(current)})
+	assert.NilError(t, err)
+	assert.Assert(t, converted)
+}
+
+func TestSuccessorForIgnoresApisWithoutSuccessor(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	zoneConfig := config.NewMockConfig(mockCtrl)
+	zoneConfig.EXPECT().GetApi().Return(api.NewApis()["management-zone"]).AnyTimes()
+
+	_, converted, err := successorFor(zoneConfig, executionOptions{successors: deploySuccessor})
+	assert.NilError(t, err)
+	assert.Assert(t, !converted)
+}
+
+func TestUploadToSuccessorUpdatesObjectWithExternalId(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	externalId := util.DeterministicUuid(testCoordinates)
+	client := &testSettingsClient{objects: []rest.SettingsObject{{ObjectId: "existing", ExternalId: externalId}}}
+	successor, _ := settings.SuccessorOf("maintenance-window")
+
+	entity, err := uploadToSuccessor(client, newTestMaintenanceWindowConfig(mockCtrl), successor, testMaintenanceWindow, "Patching", testCoordinates)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, entity, api.DynatraceEntity{Id: "existing", Name: "Patching"})
+
+	assert.Equal(t, len(client.upserted), 1)
+	assert.Equal(t, client.upserted[0].SchemaId, testMaintenanceWindowSchema)
+	assert.Equal(t, client.upserted[0].Scope, "environment")
+	assert.Equal(t, client.upserted[0].ExternalId, externalId)
+	assert.Equal(t, string(client.upserted[0].Value), `{"enabled":true,"filters":[],`+
+		`"generalProperties":{"disableSyntheticMonitorExecution":false,"maintenanceType":"PLANNED","name":"Patching","suppression":"DONT_DETECT_PROBLEMS"},`+
+		`"schedule":{"onceRecurrence":{"endTime":"2021-05-01T12:00:00","startTime":"2021-05-01T10:00:00","timeZone":"UTC"},"scheduleType":"ONCE"}}`)
+}
+
+func TestUploadToSuccessorAdoptsObjectNamedLikeConfig(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testSettingsClient{objects: []rest.SettingsObject{
+		{ObjectId: "other", ExternalId: "other-config", Value: []byte(`{"generalProperties": {"name": "Patching"}}`)},
+		{ObjectId: "migrated", Value: []byte(`{"generalProperties": {"name": "Patching"}}`)},
+	}}
+	successor, _ := settings.SuccessorOf("maintenance-window")
+
+	entity, err := uploadToSuccessor(client, newTestMaintenanceWindowConfig(mockCtrl), successor, testMaintenanceWindow, "Patching", testCoordinates)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, entity, api.DynatraceEntity{Id: "migrated", Name: "Patching"})
+	assert.Equal(t, len(client.upserted), 1)
+	assert.Equal(t, client.upserted[0].ObjectId, "migrated")
+}
+
+func TestCheckSuccessorReferencesFailsClassicConfigsReferencingSuccessors(t *testing.T) {
+
+	apis := createApis()
+	notification := config.GetMockConfig("hook", "project", nil, map[string]map[string]string{
+		"hook": {"name": "hook", "profile": util.ReplacePathSeparators("project/alerting-profile/profile.id")},
+	}, apis["notification"], "hook.json")
+
+	profile := util.ReplacePathSeparators("project/alerting-profile/profile")
+	dict := map[string]api.DynatraceEntity{profile: {Id: "object-id", Name: "profile"}}
+
+	assert.NilError(t, checkSuccessorReferences(notification, dict, map[string]bool{}))
+
+	err := checkSuccessorReferences(notification, dict, map[string]bool{profile: true})
+	assert.ErrorContains(t, err, "profile of hook.json references")
+	assert.ErrorContains(t, err, "--successors=classic")
+}
+
+func TestUploadToSuccessorFailsForUnconvertibleFields(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	client := &testSettingsClient{}
+	successor, _ := settings.SuccessorOf("maintenance-window")
+
+	_, err := uploadToSuccessor(client, newTestMaintenanceWindowConfig(mockCtrl), successor, `{"owner": "ops", `+testMaintenanceWindow[1:], "Patching", testCoordinates)
+	assert.Error(t, err, "project/maintenance-window/patching.json can not be converted to builtin:alerting.maintenance-window, fields owner have no equivalent in the schema")
+	assert.Equal(t, len(client.upserted), 0)
+}
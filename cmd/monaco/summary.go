@@ -0,0 +1,221 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/catalog"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+)
+
+// runSummary collects the outcome of a deployment or validation per environment, which is written to the summary
+// file at the end of the run, so that pipeline steps can act on it without parsing logs. A nil summary collects nothing
+type runSummary struct {
+	lock         sync.Mutex
+	started      time.Time
+	dryRun       bool
+	environments map[string]*environmentSummary
+}
+
+// environmentSummary is the outcome of one environment
+type environmentSummary struct {
+	Environment     string  `json:"environment"`
+	Result          string  `json:"result"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	Error           string  `json:"error,omitempty"`
+	Deployed        int     `json:"deployed"`
+	Skipped         int     `json:"skipped"`
+	Deleted         int     `json:"deleted"`
+	DeletionsFailed int     `json:"deletionsFailed"`
+
+	// CoManaged lists the configs whose objects were changed by other sources since monaco changed them last
+	CoManaged []string `json:"coManaged,omitempty"`
+
+	// Deprecations lists the deprecated endpoints used during the run
+	Deprecations []string `json:"deprecations,omitempty"`
+
+	// ErrorCode is the code of the failure in the error catalog, if it is a known one, e.g. MON1001
+	ErrorCode string `json:"errorCode,omitempty"`
+}
+
+// summaryCounts are the totals of all environments
+type summaryCounts struct {
+	Environments int `json:"environments"`
+	Failed       int `json:"failed"`
+	Deployed     int `json:"deployed"`
+	Skipped      int `json:"skipped"`
+	Deleted      int `json:"deleted"`
+}
+
+type summaryFile struct {
+	MonacoVersion   string               `json:"monacoVersion"`
+	DryRun          bool                 `json:"dryRun"`
+	Started         time.Time            `json:"started"`
+	DurationSeconds float64              `json:"durationSeconds"`
+	Result          string               `json:"result"`
+	Counts          summaryCounts        `json:"counts"`
+	Environments    []environmentSummary `json:"environments"`
+}
+
+func newRunSummary(dryRun bool, started time.Time) *runSummary {
+	return &runSummary{
+		started:      started,
+		dryRun:       dryRun,
+		environments: make(map[string]*environmentSummary),
+	}
+}
+
+// update applies the change to the summary of the environment while holding the lock, as environments may be
+// deployed to in parallel
+func (s *runSummary) update(environment string, change func(summary *environmentSummary)) {
+
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	summary, found := s.environments[environment]
+	if !found {
+		summary = &environmentSummary{Environment: environment}
+		s.environments[environment] = summary
+	}
+	change(summary)
+}
+
+// reset drops the counts of the environment, e.g. as its deployment is started again after maintenance
+func (s *runSummary) reset(environment string) {
+	s.update(environment, func(summary *environmentSummary) {
+		*summary = environmentSummary{Environment: environment}
+	})
+}
+
+func (s *runSummary) deployed(environment string) {
+	s.update(environment, func(summary *environmentSummary) { summary.Deployed++ })
+}
+
+func (s *runSummary) skipped(environment string) {
+	s.update(environment, func(summary *environmentSummary) { summary.Skipped++ })
+}
+
+func (s *runSummary) coManaged(environment string, config string) {
+	s.update(environment, func(summary *environmentSummary) { summary.CoManaged = append(summary.CoManaged, config) })
+}
+
+func (s *runSummary) deprecated(environment string, deprecation string) {
+	s.update(environment, func(summary *environmentSummary) { summary.Deprecations = append(summary.Deprecations, deprecation) })
+}
+
+func (s *runSummary) deleted(environment string, err error) {
+	s.update(environment, func(summary *environmentSummary) {
+		if err == nil {
+			summary.Deleted++
+		} else {
+			summary.DeletionsFailed++
+		}
+	})
+}
+
+// finished records the result of an environment
+func (s *runSummary) finished(result environmentResult) {
+	s.update(result.environment, func(summary *environmentSummary) {
+		summary.DurationSeconds = result.duration.Seconds()
+		summary.Result = s.resultOf(result.err)
+		if result.err != nil {
+			summary.Error = result.err.Error()
+		}
+		if entry, found := catalog.Lookup(result.err); found {
+			summary.ErrorCode = entry.Code
+		}
+	})
+}
+
+// frozen records an environment which was not deployed to, as it is frozen
+func (s *runSummary) frozen(environment string, err error) {
+	s.update(environment, func(summary *environmentSummary) {
+		summary.Result = "frozen"
+		summary.Error = err.Error()
+	})
+}
+
+func (s *runSummary) resultOf(err error) string {
+	switch {
+	case errors.Is(err, errInterrupted):
+		return "interrupted"
+	case err != nil:
+		return "failed"
+	case s.dryRun:
+		return "valid"
+	default:
+		return "deployed"
+	}
+}
+
+// write writes the summary with the status code of the run to the given file
+func (s *runSummary) write(file string, statusCode int, now time.Time) error {
+
+	data, err := json.MarshalIndent(s.content(statusCode, now), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(data, '\n'), 0664)
+}
+
+// content returns the summary with the status code of the run, as it is written to the summary file
+func (s *runSummary) content(statusCode int, now time.Time) summaryFile {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	content := summaryFile{
+		MonacoVersion:   version.MonitoringAsCode,
+		DryRun:          s.dryRun,
+		Started:         s.started,
+		DurationSeconds: now.Sub(s.started).Seconds(),
+		Result:          "succeeded",
+		Environments:    make([]environmentSummary, 0, len(s.environments)),
+	}
+	if statusCode != 0 {
+		content.Result = "failed"
+	}
+
+	for _, summary := range s.environments {
+		content.Environments = append(content.Environments, *summary)
+
+		content.Counts.Environments++
+		if summary.Result != "deployed" && summary.Result != "valid" {
+			content.Counts.Failed++
+		}
+		content.Counts.Deployed += summary.Deployed
+		content.Counts.Skipped += summary.Skipped
+		content.Counts.Deleted += summary.Deleted
+	}
+
+	sort.Slice(content.Environments, func(i, j int) bool {
+		return content.Environments[i].Environment < content.Environments[j].Environment
+	})
+
+	return content
+}
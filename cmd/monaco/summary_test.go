@@ -0,0 +1,118 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+func TestSummaryIsWrittenWithCountsOfAllEnvironments(t *testing.T) {
+
+	started := time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC)
+	summary := newRunSummary(false, started)
+
+	summary.deployed("prod")
+	summary.skipped("prod")
+	summary.deleted("prod", nil)
+	summary.deleted("prod", errors.New("not found"))
+	summary.finished(environmentResult{environment: "prod", duration: 2 * time.Second})
+
+	summary.deployed("dev")
+	summary.finished(environmentResult{environment: "dev", err: errors.New("api unavailable"), duration: time.Second})
+
+	summary.frozen("staging", errors.New("staging is frozen"))
+
+	file := filepath.Join(t.TempDir(), "summary.json")
+	err := summary.write(file, -1, started.Add(10*time.Second))
+	assert.NilError(t, err)
+
+	written := readSummary(t, file)
+
+	assert.Equal(t, written.Result, "failed")
+	assert.Equal(t, written.DurationSeconds, 10.0)
+	assert.DeepEqual(t, written.Counts, summaryCounts{Environments: 3, Failed: 2, Deployed: 2, Skipped: 1, Deleted: 1})
+	assert.DeepEqual(t, written.Environments, []environmentSummary{
+		{Environment: "dev", Result: "failed", DurationSeconds: 1, Error: "api unavailable", Deployed: 1},
+		{Environment: "prod", Result: "deployed", DurationSeconds: 2, Deployed: 1, Skipped: 1, Deleted: 1, DeletionsFailed: 1},
+		{Environment: "staging", Result: "frozen", Error: "staging is frozen"},
+	})
+}
+
+func TestSummaryOfRetriedEnvironmentOnlyCountsLastAttempt(t *testing.T) {
+
+	summary := newRunSummary(false, time.Now())
+
+	summary.deployed("prod")
+	summary.reset("prod")
+	summary.deployed("prod")
+	summary.finished(environmentResult{environment: "prod"})
+
+	assert.DeepEqual(t, *summary.environments["prod"], environmentSummary{Environment: "prod", Result: "deployed", Deployed: 1})
+}
+
+func TestSummaryDistinguishesValidatedAndInterruptedEnvironments(t *testing.T) {
+
+	summary := newRunSummary(true, time.Now())
+
+	summary.finished(environmentResult{environment: "dev"})
+	summary.finished(environmentResult{environment: "prod", err: fmt.Errorf("stopped: %w", errInterrupted)})
+
+	assert.Equal(t, summary.environments["dev"].Result, "valid")
+	assert.Equal(t, summary.environments["prod"].Result, "interrupted")
+}
+
+func TestSummaryRecordsCodesOfKnownFailures(t *testing.T) {
+
+	summary := newRunSummary(false, time.Now())
+
+	summary.finished(environmentResult{environment: "dev", err: fmt.Errorf("%w, responsible config: zone.json", rest.ResponseError{StatusCode: 429})})
+	summary.finished(environmentResult{environment: "prod", err: fmt.Errorf("something else failed")})
+
+	assert.Equal(t, summary.environments["dev"].ErrorCode, "MON4001")
+	assert.Equal(t, summary.environments["prod"].ErrorCode, "")
+}
+
+func TestNilSummaryCollectsNothing(t *testing.T) {
+
+	var summary *runSummary
+
+	summary.deployed("prod")
+	summary.finished(environmentResult{environment: "prod"})
+}
+
+func readSummary(t *testing.T, file string) summaryFile {
+
+	data, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+
+	var written summaryFile
+	err = json.Unmarshal(data, &written)
+	assert.NilError(t, err)
+
+	return written
+}
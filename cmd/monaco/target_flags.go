@@ -0,0 +1,92 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// addTargetFlags adds the flags selecting the existing objects configs are deployed to and whether they are changed.
+// deploy and explain use them, so that explain resolves the objects the deployment would
+func addTargetFlags(flagSet *flag.FlagSet, flags *deployFlags) {
+
+	duplicateNamesUsage := "How to deploy configs whose name is used by multiple existing objects: update-first, fail, update-all or match-by-id-from-state."
+	flagSet.StringVar(&flags.duplicateNames, "duplicate-names", string(updateFirstDuplicate), duplicateNamesUsage)
+
+	stateFileUsage := "Json file in which the ids of deployed objects are recorded. Created if it does not exist. " +
+		"May be stored remotely, as s3://bucket/key, gs://bucket/object or azblob://account/container/blob."
+	flagSet.StringVar(&flags.stateFile, "state-file", "", stateFileUsage)
+
+	addNameMatchingFlag(flagSet, &flags.caseInsensitiveNames)
+
+	ownershipMarkersUsage := "Mark objects as deployed by monaco in their description, where the api allows it, and find them by the marker on later runs, even if they were renamed."
+	flagSet.BoolVar(&flags.ownershipMarkers, "ownership-markers", false, ownershipMarkersUsage)
+
+	acceptRemoteUsage := "Keep the objects of pinned configs which changed in the environment since they were pinned, instead of failing. Their configs are not deployed."
+	flagSet.BoolVar(&flags.acceptRemote, "accept-remote", false, acceptRemoteUsage)
+
+	forceUsage := "Overwrite the objects of pinned configs which changed in the environment since they were pinned, instead of failing."
+	flagSet.BoolVar(&flags.forcePinned, "force", false, forceUsage)
+
+	successorsUsage := "Where configs of classic apis with a Settings 2.0 successor, e.g. maintenance windows, are deployed to: classic, settings, or auto for the successor in environments offering it."
+	flagSet.StringVar(&flags.successors, "successors", string(deployClassic), successorsUsage)
+}
+
+// targetOptions returns the execution options the target flags select, with the state loaded from the state file
+// if one is given
+func targetOptions(flags deployFlags, fileReader util.FileReader) (options executionOptions, err error) {
+
+	if flags.acceptRemote && flags.forcePinned {
+		return options, errors.New("--accept-remote and --force can not be combined")
+	}
+
+	options.duplicateNames, err = parseDuplicateNameStrategy(flags.duplicateNames)
+	if err != nil {
+		return options, fmt.Errorf("invalid --duplicate-names: %s", err)
+	}
+
+	options.successors, err = parseSuccessorMode(flags.successors)
+	if err != nil {
+		return options, fmt.Errorf("invalid --successors: %s", err)
+	}
+
+	if flags.stateFile != "" {
+		backend, err := state.NewBackend(flags.stateFile, fileReader)
+		if err != nil {
+			return options, fmt.Errorf("invalid --state-file: %s", err)
+		}
+		options.state, err = state.Load(backend)
+		if err != nil {
+			return options, fmt.Errorf("loading of state failed: %s", err)
+		}
+	} else if options.duplicateNames == matchDuplicatesByIdFromState {
+		return options, fmt.Errorf("invalid --duplicate-names: no --state-file given")
+	}
+
+	options.ownershipMarkers = flags.ownershipMarkers
+	options.acceptRemote = flags.acceptRemote
+	options.forcePinned = flags.forcePinned
+	options.clientOptions.NameMatching = rest.NameMatching{CaseInsensitive: flags.caseInsensitiveNames}
+
+	return options, nil
+}
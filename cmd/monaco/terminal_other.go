@@ -0,0 +1,30 @@
+// +build !linux,!darwin,!windows
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "errors"
+
+// isTerminal reports no terminal on platforms whose terminals are not supported, so that variables are not asked for
+func isTerminal(fd uintptr) bool {
+	return false
+}
+
+func disableEcho(fd uintptr) (restore func(), err error) {
+	return nil, errors.New("hiding the input is not supported on this platform")
+}
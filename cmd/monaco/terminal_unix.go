@@ -0,0 +1,93 @@
+// +build linux darwin
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+func getTermios(fd uintptr) (*syscall.Termios, error) {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlGetTermios, uintptr(unsafe.Pointer(&termios)))
+	if errno != 0 {
+		return nil, errno
+	}
+	return &termios, nil
+}
+
+func setTermios(fd uintptr, termios *syscall.Termios) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, ioctlSetTermios, uintptr(unsafe.Pointer(termios)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// isTerminal checks whether the file descriptor refers to a terminal
+func isTerminal(fd uintptr) bool {
+	_, err := getTermios(fd)
+	return err == nil
+}
+
+// disableEcho stops the terminal from echoing the input, until restore is called
+func disableEcho(fd uintptr) (restore func(), err error) {
+
+	original, err := getTermios(fd)
+	if err != nil {
+		return nil, err
+	}
+
+	silent := *original
+	silent.Lflag &^= syscall.ECHO
+	silent.Lflag |= syscall.ICANON | syscall.ISIG
+
+	err = setTermios(fd, &silent)
+	if err != nil {
+		return nil, err
+	}
+
+	// an interrupted prompt must not leave the terminal without echo. The signal is raised again once the echo is
+	// restored, so monaco ends as it would have without the prompt
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case received := <-signals:
+			setTermios(fd, original)
+			signal.Stop(signals)
+			syscall.Kill(syscall.Getpid(), received.(syscall.Signal))
+		case <-done:
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+			setTermios(fd, original)
+		})
+	}, nil
+}
@@ -0,0 +1,46 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import "syscall"
+
+const enableEchoInput = 0x0004
+
+var setConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// isTerminal checks whether the handle refers to a console
+func isTerminal(fd uintptr) bool {
+	var mode uint32
+	return syscall.GetConsoleMode(syscall.Handle(fd), &mode) == nil
+}
+
+// disableEcho stops the console from echoing the input, until restore is called
+func disableEcho(fd uintptr) (restore func(), err error) {
+
+	var original uint32
+	err = syscall.GetConsoleMode(syscall.Handle(fd), &original)
+	if err != nil {
+		return nil, err
+	}
+
+	result, _, err := setConsoleMode.Call(fd, uintptr(original&^enableEchoInput))
+	if result == 0 {
+		return nil, err
+	}
+
+	return func() { setConsoleMode.Call(fd, uintptr(original)) }, nil
+}
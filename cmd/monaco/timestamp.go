@@ -0,0 +1,49 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+const timestampUsage = "Use the given time instead of the current one, as RFC 3339 date time (e.g. 2021-03-01T12:00:00Z) or unix seconds, " +
+	"so that rendered templates and generated names are reproducible."
+
+func addTimestampFlag(flagSet *flag.FlagSet, timestamp *string) {
+	flagSet.StringVar(timestamp, "timestamp", "", timestampUsage)
+}
+
+// freezeTime freezes the clock at the timestamp given by --timestamp, if any. Freeze windows, backups and the audit
+// log of co-managed configs keep using the real time
+func freezeTime(timestamp string) error {
+
+	if timestamp == "" {
+		return nil
+	}
+
+	frozen, err := util.ParseTimestamp(timestamp)
+	if err != nil {
+		return err
+	}
+
+	util.FreezeTime(frozen)
+	util.Log.Info("Using timestamp %s instead of the current time", frozen.Format(time.RFC3339))
+	return nil
+}
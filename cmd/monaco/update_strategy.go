@@ -0,0 +1,124 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// mergesUpdates returns whether the payload of the config is merged onto the existing object in the environment,
+// according to its update strategy. Merging is not supported for extensions and settings, as their payloads are not
+// the objects returned by their apis
+func mergesUpdates(c config.Config, environment environment.Environment) (bool, error) {
+
+	strategy, err := c.GetUpdateStrategy(environment)
+	if err != nil || strategy != config.UpdateMerge {
+		return false, err
+	}
+
+	apiId := c.GetApi().GetId()
+	if apiId == extensionApi || apiId == settingsApi {
+		return false, fmt.Errorf("updateStrategy %s is not supported for %s, responsible config: %s", strategy, apiId, c.GetFilePath())
+	}
+
+	return true, nil
+}
+
+// mergeWithExisting applies the payload as json merge patch (RFC 7386) onto the existing object of the target, so
+// fields managed outside of monaco, e.g. in the UI, are kept. The server managed fields of the existing object are
+// dropped. The payload is returned unchanged if the target does not exist yet, or consists of several objects
+func mergeWithExisting(client rest.DynatraceClient, a api.Api, name string, target deployTarget, payload []byte, fields api.FieldOverrides) ([]byte, error) {
+
+	if !target.exists() {
+		return payload, nil
+	}
+	if len(target.ids) > 1 {
+		util.Log.Warn("\t\t\tNot merging %s onto the existing objects, %d objects are updated", name, len(target.ids))
+		return payload, nil
+	}
+
+	id := target.ids[0]
+
+	existing, err := client.ReadById(a, id)
+	if err != nil {
+		return nil, fmt.Errorf("reading object %s of %s to merge onto failed: %s", id, a.GetId(), err)
+	}
+
+	var object, patch interface{}
+	if err = decodeJson(existing, &object); err != nil {
+		return nil, fmt.Errorf("object %s of %s is no valid json: %s", id, a.GetId(), err)
+	}
+	if err = decodeJson(payload, &patch); err != nil {
+		return nil, err
+	}
+
+	api.RemoveFields(object, fields.ServerManagedFields(a.GetId()))
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(mergePatch(object, patch))
+	if err != nil {
+		return nil, err
+	}
+
+	util.Log.Debug("\t\t\tMerged %s onto the existing object %s", name, id)
+	return buffer.Bytes(), nil
+}
+
+// mergePatch applies the patch onto the target: objects are merged key by key, null values remove the key from the
+// target and all other values, including lists, replace the value of the target
+func mergePatch(target interface{}, patch interface{}) interface{} {
+
+	patchObject, isObject := patch.(map[string]interface{})
+	if !isObject {
+		return patch
+	}
+
+	targetObject, isObject := target.(map[string]interface{})
+	if !isObject {
+		targetObject = make(map[string]interface{})
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+		} else {
+			targetObject[key] = mergePatch(targetObject[key], value)
+		}
+	}
+
+	return targetObject
+}
+
+func decodeJson(data []byte, value interface{}) error {
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	return decoder.Decode(value)
+}
@@ -0,0 +1,119 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"gotest.tools/assert"
+)
+
+func TestMergePatch(t *testing.T) {
+
+	var target, patch interface{}
+	assert.NilError(t, json.Unmarshal([]byte(`{"name": "zone", "rules": [1, 2], "owner": {"team": "a", "ui": true}, "legacy": 1}`), &target))
+	assert.NilError(t, json.Unmarshal([]byte(`{"rules": [3], "owner": {"team": "b"}, "legacy": null, "added": "x"}`), &patch))
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	assert.NilError(t, err)
+	assert.Equal(t, string(merged), `{"added":"x","name":"zone","owner":{"team":"b","ui":true},"rules":[3]}`)
+}
+
+func TestMergeWithExistingKeepsFieldsOfTheExistingObject(t *testing.T) {
+
+	apis := api.NewApis()
+	zones := apis["management-zone"]
+
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	payload := []byte(`{"name": "zone", "description": "deployed"}`)
+
+	merged, err := mergeWithExisting(client, zones, "zone", deployTarget{}, payload, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(merged), string(payload), "new objects are created from the payload as it is")
+
+	existing, err := client.UpsertByName(zones, "zone", []byte(`{"name": "zone", "description": "edited in the ui", "rules": [{"type": "HOST"}], "metadata": {"clusterVersion": "1.214"}}`))
+	assert.NilError(t, err)
+
+	merged, err = mergeWithExisting(client, zones, "zone", deployTarget{ids: []string{existing.Id}}, payload, nil)
+	assert.NilError(t, err)
+
+	var result map[string]interface{}
+	assert.NilError(t, json.Unmarshal(merged, &result))
+	assert.Equal(t, result["description"], "deployed")
+	assert.Equal(t, len(result["rules"].([]interface{})), 1, "fields not in the template are kept")
+	_, found := result["metadata"]
+	assert.Assert(t, !found, "server managed fields of the existing object are dropped")
+}
+
+func TestMergeWithExistingSkipsSeveralObjects(t *testing.T) {
+
+	payload := []byte(`{"name": "zone"}`)
+
+	merged, err := mergeWithExisting(&recordingClient{}, testDashboardApi, "zone", deployTarget{ids: []string{"1", "2"}}, payload, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(merged), string(payload))
+}
+
+func TestDeployByIdentityMergesOntoTheUpdatedObject(t *testing.T) {
+
+	client := &recordingClient{values: []api.Value{{Id: "1", Name: "overview"}, {Id: "2", Name: "overview"}}}
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "2")
+
+	dashboard := config.GetMockConfig("overview", "project", nil, map[string]map[string]string{"overview": {"name": "overview"}}, testDashboardApi, "overview.json")
+	options := executionOptions{duplicateNames: failOnDuplicateNames, state: deployState}
+
+	_, err := deployByIdentity(client, dashboard, "overview", testCoordinates, []byte(`{"name": "overview"}`), true, testDuplicatesEnvironment, nil, options)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, client.reads, []string{"2"})
+	assert.DeepEqual(t, client.writes, []string{"upsert-by-id 2"})
+	assert.Equal(t, string(client.payloads[0]), "{\n  \"kept\": true,\n  \"name\": \"overview\"\n}\n")
+}
+
+func TestMergesUpdates(t *testing.T) {
+
+	apis := api.NewApis()
+	env := environment.NewEnvironment("dev", "dev", "", "https://url/to/dev/environment", "DEV")
+
+	merged := config.GetMockConfig("zone", "project", nil, map[string]map[string]string{"zone": {"name": "zone", "updateStrategy": "merge"}}, apis["management-zone"], "zone.json")
+	merge, err := mergesUpdates(merged, env)
+	assert.NilError(t, err)
+	assert.Assert(t, merge)
+
+	replaced := config.GetMockConfig("zone", "project", nil, map[string]map[string]string{"zone": {"name": "zone"}}, apis["management-zone"], "zone.json")
+	merge, err = mergesUpdates(replaced, env)
+	assert.NilError(t, err)
+	assert.Assert(t, !merge)
+
+	settings := config.GetMockConfig("object", "project", nil, map[string]map[string]string{"object": {"name": "object", "updateStrategy": "merge"}}, apis["settings"], "object.json")
+	_, err = mergesUpdates(settings, env)
+	assert.ErrorContains(t, err, "updateStrategy merge is not supported for settings")
+}
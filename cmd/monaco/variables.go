@@ -0,0 +1,146 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+)
+
+// prompter asks for the values of variables. It only asks when monaco runs interactively, secrets are read
+// without echoing them
+type prompter struct {
+	in          *bufio.Reader
+	out         io.Writer
+	interactive bool
+
+	// hideInput stops the terminal from echoing the input, until restore is called
+	hideInput func() (restore func(), err error)
+}
+
+// newStdinPrompter creates a prompter asking on stderr and reading from stdin, if stdin is a terminal
+func newStdinPrompter() *prompter {
+	fd := os.Stdin.Fd()
+
+	return &prompter{
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stderr,
+		interactive: isTerminal(fd),
+		hideInput: func() (func(), error) {
+			return disableEcho(fd)
+		},
+	}
+}
+
+// resolveVariables sets the variables passed as NAME=value, and asks for the variables declared by the projects
+// which are neither passed nor set, if they are marked as prompt. Variables are set as environment variables, so that
+// templates and config yamls use them like any other, e.g. {{ .Env.INCIDENT_ID }}. As config yamls are rendered while
+// the projects are loaded, the variables of the project files are resolved before
+func resolveVariables(declared map[string][]project.Variable, values []string, prompter *prompter) error {
+
+	for _, value := range values {
+		split := strings.SplitN(value, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return fmt.Errorf("variable %s has to be given as NAME=value", value)
+		}
+		os.Setenv(split[0], split[1])
+	}
+
+	resolved := make(map[string]bool)
+
+	projectIds := make([]string, 0, len(declared))
+	for projectId := range declared {
+		projectIds = append(projectIds, projectId)
+	}
+	sort.Strings(projectIds)
+
+	for _, projectId := range projectIds {
+		for _, variable := range declared[projectId] {
+
+			if resolved[variable.Name] || os.Getenv(variable.Name) != "" {
+				resolved[variable.Name] = true
+				continue
+			}
+
+			if !variable.Prompt {
+				return fmt.Errorf("variable %s of project %s is not set, pass it with --var=%s=... or set it as environment variable",
+					variable.Name, projectId, variable.Name)
+			}
+
+			value, err := prompter.ask(variable)
+			if err != nil {
+				return fmt.Errorf("variable %s of project %s is not set: %s", variable.Name, projectId, err)
+			}
+
+			os.Setenv(variable.Name, value)
+			resolved[variable.Name] = true
+		}
+	}
+
+	return nil
+}
+
+// ask asks for the value of the variable until a value is entered
+func (p *prompter) ask(variable project.Variable) (string, error) {
+
+	if !p.interactive {
+		return "", fmt.Errorf("monaco does not run interactively, pass it with --var=%s=... or set it as environment variable", variable.Name)
+	}
+
+	for {
+		value, err := p.read(variable)
+		if err != nil {
+			return "", err
+		}
+		if value != "" {
+			return value, nil
+		}
+	}
+}
+
+func (p *prompter) read(variable project.Variable) (string, error) {
+
+	if variable.Description != "" {
+		fmt.Fprintf(p.out, "%s (%s): ", variable.Name, variable.Description)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", variable.Name)
+	}
+
+	if variable.Secret {
+		restore, err := p.hideInput()
+		if err != nil {
+			return "", err
+		}
+		defer restore()
+		// the newline entered is not echoed either
+		defer fmt.Fprintln(p.out)
+	}
+
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
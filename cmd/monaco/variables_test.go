@@ -0,0 +1,133 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"gotest.tools/assert"
+)
+
+func newTestPrompter(input string, interactive bool) (*prompter, *bytes.Buffer, *int) {
+
+	out := &bytes.Buffer{}
+	hidden := 0
+
+	return &prompter{
+		in:          bufio.NewReader(strings.NewReader(input)),
+		out:         out,
+		interactive: interactive,
+		hideInput: func() (func(), error) {
+			hidden++
+			return func() {}, nil
+		},
+	}, out, &hidden
+}
+
+func unsetAfterTest(t *testing.T, names ...string) {
+	for _, name := range names {
+		os.Unsetenv(name)
+	}
+	t.Cleanup(func() {
+		for _, name := range names {
+			os.Unsetenv(name)
+		}
+	})
+}
+
+func TestResolveVariablesAsksForMissingVariables(t *testing.T) {
+
+	unsetAfterTest(t, "MONACO_TEST_INCIDENT", "MONACO_TEST_KEY")
+
+	variables := map[string][]project.Variable{"break-glass": {
+		{Name: "MONACO_TEST_INCIDENT", Description: "incident id", Prompt: true},
+		{Name: "MONACO_TEST_KEY", Prompt: true, Secret: true},
+	}}
+
+	prompter, out, hidden := newTestPrompter("\nINC-42\nsecret\n", true)
+
+	err := resolveVariables(variables, nil, prompter)
+	assert.NilError(t, err)
+
+	assert.Equal(t, os.Getenv("MONACO_TEST_INCIDENT"), "INC-42")
+	assert.Equal(t, os.Getenv("MONACO_TEST_KEY"), "secret")
+	assert.Equal(t, *hidden, 1)
+	assert.Equal(t, out.String(), "MONACO_TEST_INCIDENT (incident id): MONACO_TEST_INCIDENT (incident id): MONACO_TEST_KEY: \n")
+}
+
+func TestResolveVariablesDoesNotAskForPassedVariables(t *testing.T) {
+
+	unsetAfterTest(t, "MONACO_TEST_INCIDENT", "MONACO_TEST_KEY")
+	os.Setenv("MONACO_TEST_KEY", "from-env")
+
+	variables := map[string][]project.Variable{"break-glass": {
+		{Name: "MONACO_TEST_INCIDENT", Prompt: true},
+		{Name: "MONACO_TEST_KEY", Prompt: true, Secret: true},
+	}}
+
+	prompter, out, _ := newTestPrompter("", true)
+
+	err := resolveVariables(variables, []string{"MONACO_TEST_INCIDENT=INC-1=a"}, prompter)
+	assert.NilError(t, err)
+
+	assert.Equal(t, os.Getenv("MONACO_TEST_INCIDENT"), "INC-1=a")
+	assert.Equal(t, os.Getenv("MONACO_TEST_KEY"), "from-env")
+	assert.Equal(t, out.String(), "")
+}
+
+func TestResolveVariablesFailsWithoutTerminal(t *testing.T) {
+
+	unsetAfterTest(t, "MONACO_TEST_INCIDENT")
+
+	variables := map[string][]project.Variable{"break-glass": {
+		{Name: "MONACO_TEST_INCIDENT", Prompt: true},
+	}}
+
+	prompter, _, _ := newTestPrompter("INC-42\n", false)
+
+	err := resolveVariables(variables, nil, prompter)
+	assert.ErrorContains(t, err, "variable MONACO_TEST_INCIDENT of project break-glass is not set: monaco does not run interactively")
+}
+
+func TestResolveVariablesFailsOnMissingVariableWithoutPrompt(t *testing.T) {
+
+	unsetAfterTest(t, "MONACO_TEST_INCIDENT")
+
+	variables := map[string][]project.Variable{"break-glass": {
+		{Name: "MONACO_TEST_INCIDENT"},
+	}}
+
+	prompter, _, _ := newTestPrompter("INC-42\n", true)
+
+	err := resolveVariables(variables, nil, prompter)
+	assert.ErrorContains(t, err, "variable MONACO_TEST_INCIDENT of project break-glass is not set, pass it with --var")
+}
+
+func TestResolveVariablesFailsOnInvalidValue(t *testing.T) {
+
+	prompter, _, _ := newTestPrompter("", false)
+
+	err := resolveVariables(nil, []string{"INCIDENT"}, prompter)
+	assert.ErrorContains(t, err, "has to be given as NAME=value")
+}
@@ -4,7 +4,9 @@ require (
 	github.com/golang/mock v1.4.4
 	github.com/google/go-cmp v0.5.4
 	github.com/jcelliott/lumber v0.0.0-20160324203708-dd349441af25
-	github.com/pkg/errors v0.8.1
+	github.com/open-policy-agent/opa v0.25.2
+	github.com/pkg/errors v0.9.1
+	golang.org/x/text v0.3.3
 	gopkg.in/src-d/go-billy.v4 v4.3.2
 	gopkg.in/yaml.v2 v2.4.0
 	gotest.tools v2.2.0+incompatible
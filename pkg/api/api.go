@@ -58,16 +58,122 @@ var apiMap = map[string]string{
 	"conditional-naming-service":      "/api/config/v1/conditionalNaming/service",
 	"maintenance-window":              "/api/config/v1/maintenanceWindows",
 	"request-naming-service":          "/api/config/v1/service/requestNaming",
+
+	// Settings 2.0 objects of all schemas, the schema of a config is defined by its schemaId property
+	"settings": "/api/v2/settings/objects",
+}
+
+// IdentityStrategy defines how the object a config was deployed to is found again on subsequent deployments
+type IdentityStrategy string
+
+const (
+	// IdentityByName matches objects by their name
+	IdentityByName IdentityStrategy = "name"
+
+	// IdentityById addresses objects by an id derived from the config, for apis allowing to create objects with a given id
+	IdentityById IdentityStrategy = "id"
+
+	// IdentityByExternalId matches objects by an external id derived from the config, which is stored in the object
+	IdentityByExternalId IdentityStrategy = "externalId"
+)
+
+// identityStrategies holds the apis whose objects are not reliably addressable by name.
+// All other apis use IdentityByName
+var identityStrategies = map[string]IdentityStrategy{
+	"dashboard": IdentityById,
+	"settings":  IdentityByExternalId,
+}
+
+// defaultPayloadSizeLimit is the maximum size in bytes of a payload the Dynatrace configuration apis accept
+const defaultPayloadSizeLimit = 1024 * 1024
+
+// payloadSizeLimits holds the apis accepting only smaller payloads than defaultPayloadSizeLimit
+var payloadSizeLimits = map[string]int{
+	"dashboard": 512 * 1024,
+}
+
+// apiDependencies holds the apis whose payloads refer to objects of other apis by their name, e.g. a calculated
+// service metric measuring a request attribute. Within a project, configs of these apis are deployed after the
+// configs of the apis they depend on, even if they do not reference them
+var apiDependencies = map[string][]string{
+	"calculated-metrics-service": {"request-attributes"},
+	"request-naming-service":     {"request-attributes"},
+}
+
+// orderedApis holds the apis whose rules are evaluated in order by Dynatrace, e.g. naming rules of which the first
+// matching rule applies. Within a project, configs of these apis are deployed in the order they are declared
+var orderedApis = map[string]bool{
+	"conditional-naming-host":         true,
+	"conditional-naming-processgroup": true,
+	"conditional-naming-service":      true,
+	"request-naming-service":          true,
+}
+
+// Deployment priority tiers of apis. Within a project, configs of lower tiers are deployed before configs of higher
+// tiers, unless references between the configs require another order
+const (
+	// foundationPriority holds apis whose objects are commonly referenced by objects of other apis, e.g. zones and tags
+	foundationPriority = 0
+
+	// defaultPriority holds all apis not listed in deploymentPriorities
+	defaultPriority = 1
+
+	// profilePriority holds apis scoping objects of other apis, which in turn refer to zones and tags
+	profilePriority = 2
+
+	// consumerPriority holds apis whose objects refer to objects of many other apis, e.g. dashboards
+	consumerPriority = 3
+)
+
+// deploymentPriorities holds the priority tier of the apis, which are not of the defaultPriority. Deploying them in
+// tiers avoids failures of references the server resolves by name or id on fresh environments, which monaco can
+// not detect as they are not declared as references of the configs
+var deploymentPriorities = map[string]int{
+	"management-zone":        foundationPriority,
+	"auto-tag":               foundationPriority,
+	"request-attributes":     foundationPriority,
+	"aws-credentials":        foundationPriority,
+	"azure-credentials":      foundationPriority,
+	"kubernetes-credentials": foundationPriority,
+
+	"alerting-profile": profilePriority,
+
+	"notification": consumerPriority,
+	"dashboard":    consumerPriority,
+}
+
+// markableApis holds the apis whose objects have a description. monaco marks the objects it deploys to as owned with
+// a marker in the description, to find them again if their name changed
+var markableApis = map[string]bool{
+	"management-zone":           true,
+	"auto-tag":                  true,
+	"maintenance-window":        true,
+	"anomaly-detection-metrics": true,
+}
+
+// unlistedDescriptionApis holds the markable apis whose list responses omit the descriptions of the objects
+var unlistedDescriptionApis = map[string]bool{
+	"management-zone": true,
+	"auto-tag":        true,
 }
 
 type Api interface {
 	GetUrl(environment environment.Environment) string
+	GetUrlFromEnvironmentUrl(environmentUrl string) string
 	GetId() string
+	GetIdentityStrategy() IdentityStrategy
+	GetPayloadSizeLimit() int
+	DependsOn(other Api) bool
+	IsOrdered() bool
+	GetPriority() int
+	IsMarkable() bool
+	ListsDescriptions() bool
 }
 
 type apiImpl struct {
-	id      string
-	apiPath string
+	id               string
+	apiPath          string
+	identityStrategy IdentityStrategy
 }
 
 func NewApis() map[string]Api {
@@ -87,20 +193,77 @@ func newApi(id string, apiPath string) Api {
 }
 
 func NewApi(id string, apiPath string) Api {
+
+	identityStrategy, found := identityStrategies[id]
+	if !found {
+		identityStrategy = IdentityByName
+	}
+
 	return &apiImpl{
-		id:      id,
-		apiPath: apiPath,
+		id:               id,
+		apiPath:          apiPath,
+		identityStrategy: identityStrategy,
 	}
 }
 
 func (a *apiImpl) GetUrl(environment environment.Environment) string {
-	return environment.GetEnvironmentUrl() + a.apiPath
+	return a.GetUrlFromEnvironmentUrl(environment.GetEnvironmentUrl())
+}
+
+func (a *apiImpl) GetUrlFromEnvironmentUrl(environmentUrl string) string {
+	return environmentUrl + a.apiPath
 }
 
 func (a *apiImpl) GetId() string {
 	return a.id
 }
 
+func (a *apiImpl) GetIdentityStrategy() IdentityStrategy {
+	return a.identityStrategy
+}
+
+// GetPayloadSizeLimit returns the maximum size in bytes of a payload accepted by the api
+func (a *apiImpl) GetPayloadSizeLimit() int {
+	if limit, found := payloadSizeLimits[a.id]; found {
+		return limit
+	}
+	return defaultPayloadSizeLimit
+}
+
+// DependsOn checks if configs of the api have to be deployed after the configs of the other api
+func (a *apiImpl) DependsOn(other Api) bool {
+	for _, dependency := range apiDependencies[a.id] {
+		if dependency == other.GetId() {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOrdered checks if configs of the api have to be deployed in the order they are declared
+func (a *apiImpl) IsOrdered() bool {
+	return orderedApis[a.id]
+}
+
+// GetPriority returns the deployment priority tier of the api, configs of lower tiers are deployed first
+func (a *apiImpl) GetPriority() int {
+	if priority, found := deploymentPriorities[a.id]; found {
+		return priority
+	}
+	return defaultPriority
+}
+
+// IsMarkable checks if objects of the api can be marked as owned by monaco in their description
+func (a *apiImpl) IsMarkable() bool {
+	return markableApis[a.id]
+}
+
+// ListsDescriptions checks if the list response of the api includes the descriptions of the objects. Otherwise,
+// they have to be read one by one
+func (a *apiImpl) ListsDescriptions() bool {
+	return markableApis[a.id] && !unlistedDescriptionApis[a.id]
+}
+
 func IsApi(dir string) bool {
 	_, ok := apiMap[dir]
 	return ok
@@ -53,6 +53,51 @@ func TestCreateApisResultsInError(t *testing.T) {
 	assert.Assert(t, !ok, "Expected error on `notexistingkey` key in createApis")
 }
 
+func TestGetIdentityStrategy(t *testing.T) {
+	assert.Equal(t, testDashboardApi.GetIdentityStrategy(), IdentityById)
+	assert.Equal(t, testManagementZoneApi.GetIdentityStrategy(), IdentityByName)
+}
+
+func TestGetPayloadSizeLimit(t *testing.T) {
+	assert.Equal(t, testDashboardApi.GetPayloadSizeLimit(), 512*1024)
+	assert.Equal(t, testManagementZoneApi.GetPayloadSizeLimit(), 1024*1024)
+}
+
+func TestDependsOn(t *testing.T) {
+	requestAttributesApi := NewApi("request-attributes", "/api/config/v1/service/requestAttributes")
+	calculatedMetricsApi := NewApi("calculated-metrics-service", "/api/config/v1/calculatedMetrics/service")
+
+	assert.Assert(t, calculatedMetricsApi.DependsOn(requestAttributesApi))
+	assert.Assert(t, !requestAttributesApi.DependsOn(calculatedMetricsApi))
+	assert.Assert(t, !testManagementZoneApi.DependsOn(requestAttributesApi))
+}
+
+func TestIsOrdered(t *testing.T) {
+	hostNamingApi := NewApi("conditional-naming-host", "/api/config/v1/conditionalNaming/host")
+
+	assert.Assert(t, hostNamingApi.IsOrdered())
+	assert.Assert(t, !testManagementZoneApi.IsOrdered())
+}
+
+func TestGetPriority(t *testing.T) {
+	alertingProfileApi := NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+	applicationApi := NewApi("application", "/api/config/v1/applications/web")
+
+	assert.Assert(t, testManagementZoneApi.GetPriority() < applicationApi.GetPriority())
+	assert.Assert(t, applicationApi.GetPriority() < alertingProfileApi.GetPriority())
+	assert.Assert(t, alertingProfileApi.GetPriority() < testDashboardApi.GetPriority())
+}
+
+func TestIsMarkable(t *testing.T) {
+	assert.Assert(t, testManagementZoneApi.IsMarkable())
+	assert.Assert(t, !testDashboardApi.IsMarkable())
+}
+
+func TestListsDescriptions(t *testing.T) {
+	assert.Assert(t, !testManagementZoneApi.ListsDescriptions())
+	assert.Assert(t, NewApi("maintenance-window", "/api/config/v1/maintenanceWindows").ListsDescriptions())
+}
+
 func TestIfFolderContainsApiInPath(t *testing.T) {
 	assert.Equal(t, ContainsApiName("trillian"), false, "Check if `trillian` is an API")
 	assert.Equal(t, ContainsApiName("extension"), true, "Check if `extension` is an API")
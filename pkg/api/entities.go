@@ -37,9 +37,12 @@ type ExtensionsResponse struct {
 }
 
 type Value struct {
-	Id    string  `json:"id"`
-	Name  string  `json:"name"`
-	Owner *string `json:"owner,omitempty"`
+	Id         string  `json:"id"`
+	Name       string  `json:"name"`
+	Owner      *string `json:"owner,omitempty"`
+	ExternalId string  `json:"externalId,omitempty"`
+
+	Description string `json:"description,omitempty"`
 }
 
 type SyntheticValue struct {
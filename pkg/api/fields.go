@@ -0,0 +1,174 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Fields are addressed by the keys of nested objects joined by dots, lists are transparent,
+// e.g. `rules.id` addresses the ids of all rules
+
+// ProvenanceField holds where a downloaded template originates from, if the download embedded it. It is no field
+// of any api, so it is stripped like the default server managed fields
+const ProvenanceField = "monacoProvenance"
+
+// defaultServerManagedFields are generated or managed by the server for the objects of all apis
+var defaultServerManagedFields = []string{"id", "entityId", "metadata"}
+
+// serverManagedFields holds the api specific fields generated or managed by the server, in addition to the default
+// ones. They are stripped from payloads before uploading them and from downloaded configs, and never compared
+var serverManagedFields = map[string][]string{
+	"auto-tag":          {"rules.id"},
+	"management-zone":   {"rules.id"},
+	"synthetic-monitor": {"createdFrom", "script.requests.id", "script.events.id"},
+}
+
+// diffIgnoredFields holds the fields which are uploaded, but may be changed by the server or differ between
+// environments by design, e.g. the owner of a dashboard. They are ignored when comparing objects
+var diffIgnoredFields = map[string][]string{
+	"dashboard": {"dashboardMetadata.owner"},
+}
+
+// withoutDefaultFields holds the apis whose payloads are no regular objects of the api, so that the default server
+// managed fields do not apply, e.g. the value of a settings object may well contain an `id`
+var withoutDefaultFields = map[string]bool{
+	"settings": true,
+}
+
+// FieldOverrides holds the fields added to the built-in ones per api id, see LoadFieldOverrides. The fields of apis
+// are looked up on it, so that the built-in ones apply with or without overrides. The zero value adds no fields
+type FieldOverrides map[string]fieldOverride
+
+// ServerManagedFields returns the fields of payloads of the given api which are generated or managed by the server
+func (o FieldOverrides) ServerManagedFields(apiId string) []string {
+
+	var fields []string
+	if !withoutDefaultFields[apiId] {
+		fields = append(fields, defaultServerManagedFields...)
+		fields = append(fields, ProvenanceField)
+	}
+
+	fields = append(fields, serverManagedFields[apiId]...)
+	return append(fields, o[apiId].ServerManaged...)
+}
+
+// DiffIgnoredFields returns the fields of payloads of the given api which are ignored when comparing objects,
+// which includes the server managed fields
+func (o FieldOverrides) DiffIgnoredFields(apiId string) []string {
+	fields := append(o.ServerManagedFields(apiId), diffIgnoredFields[apiId]...)
+	return append(fields, o[apiId].IgnoredOnDiff...)
+}
+
+// knownFields returns the fields of the schema of the api, if monaco knows it
+func (o FieldOverrides) knownFields(apiId string) ([]string, bool) {
+
+	fields, found := knownFields[apiId]
+	if !found {
+		return nil, false
+	}
+
+	return append(append([]string{}, fields...), o[apiId].Known...), true
+}
+
+// RemoveFields removes the given fields from the json content and returns how many were found
+func RemoveFields(content interface{}, fields []string) int {
+	return removeFields("", content, fields)
+}
+
+func removeFields(path string, value interface{}, fields []string) (removed int) {
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range typed {
+			innerPath := key
+			if path != "" {
+				innerPath = path + "." + key
+			}
+			if containsField(fields, innerPath) {
+				delete(typed, key)
+				removed++
+			} else {
+				removed += removeFields(innerPath, inner, fields)
+			}
+		}
+	case []interface{}:
+		for _, inner := range typed {
+			removed += removeFields(path, inner, fields)
+		}
+	}
+
+	return removed
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldOverride adds fields of an api to the built-in ones
+type fieldOverride struct {
+	ServerManaged []string `yaml:"serverManaged"`
+	IgnoredOnDiff []string `yaml:"ignoredOnDiff"`
+	Known         []string `yaml:"known"`
+}
+
+// LoadFieldOverrides reads a yaml file mapping api ids to the fields which are server managed, ignored on diff or
+// known, for quirks of an environment the built-in definitions do not cover. The fields are added to the built-in ones.
+// Known fields can only be added to apis whose schema monaco knows, as the fields of other apis are not checked.
+// No overrides are loaded if file is empty
+func LoadFieldOverrides(file string, fileReader util.FileReader) (FieldOverrides, error) {
+
+	if file == "" {
+		return nil, nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("field overrides file %s could not be read: %s", file, err)
+	}
+
+	var parsed FieldOverrides
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("field overrides file %s is invalid: %s", file, err)
+	}
+
+	for apiId, override := range parsed {
+		if !IsApi(apiId) {
+			return nil, fmt.Errorf("field overrides file %s refers to unknown api %s", file, apiId)
+		}
+		if _, found := knownFields[apiId]; !found && len(override.Known) > 0 {
+			return nil, fmt.Errorf("field overrides file %s adds known fields to api %s, whose schema is not known", file, apiId)
+		}
+		for _, field := range append(append(override.ServerManaged, override.IgnoredOnDiff...), override.Known...) {
+			if strings.TrimSpace(field) == "" {
+				return nil, fmt.Errorf("field overrides file %s contains an empty field for api %s", file, apiId)
+			}
+		}
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,145 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestServerManagedFieldsIncludeDefaults(t *testing.T) {
+
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField, "rules.id"}, FieldOverrides{}.ServerManagedFields("auto-tag"))
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField}, FieldOverrides{}.ServerManagedFields("alerting-profile"))
+	assert.Equal(t, 0, len(FieldOverrides{}.ServerManagedFields("settings")))
+}
+
+func TestDiffIgnoredFieldsIncludeServerManagedFields(t *testing.T) {
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField, "dashboardMetadata.owner"}, FieldOverrides{}.DiffIgnoredFields("dashboard"))
+}
+
+func TestRemoveFieldsOfNestedObjectsAndLists(t *testing.T) {
+
+	var content interface{}
+	err := json.Unmarshal([]byte(`{"id": "1", "name": "zone", "rules": [{"id": "2", "type": "SERVICE"}, {"id": "3"}]}`), &content)
+	assert.NilError(t, err)
+
+	removed := RemoveFields(content, []string{"id", "rules.id"})
+	assert.Equal(t, 3, removed)
+
+	result, err := json.Marshal(content)
+	assert.NilError(t, err)
+	assert.Equal(t, `{"name":"zone","rules":[{"type":"SERVICE"},{}]}`, string(result))
+}
+
+func writeFieldOverrides(t *testing.T, content string) string {
+
+	file := filepath.Join(t.TempDir(), "fields.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0664))
+
+	return file
+}
+
+func TestLoadFieldOverridesAddsFields(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+dashboard:
+  serverManaged:
+    - dashboardMetadata.sharingDetails
+  ignoredOnDiff:
+    - dashboardMetadata.preset
+`)
+
+	overrides, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField, "dashboardMetadata.sharingDetails"}, overrides.ServerManagedFields("dashboard"))
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField, "dashboardMetadata.sharingDetails", "dashboardMetadata.owner", "dashboardMetadata.preset"}, overrides.DiffIgnoredFields("dashboard"))
+}
+
+func TestLoadFieldOverridesDoesNotChangeTheBuiltInFields(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+dashboard:
+  serverManaged:
+    - dashboardMetadata.sharingDetails
+`)
+
+	_, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, []string{"id", "entityId", "metadata", ProvenanceField}, FieldOverrides{}.ServerManagedFields("dashboard"))
+}
+
+func TestLoadFieldOverridesAddsKnownFields(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+dashboard:
+  known:
+    - preset
+`)
+
+	overrides, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	payload := map[string]interface{}{"dashboardMetadata": map[string]interface{}{}, "tiles": []interface{}{}, "preset": true}
+	assert.Equal(t, 0, len(overrides.UnknownFields("dashboard", payload)))
+	assert.Equal(t, 1, len(FieldOverrides{}.UnknownFields("dashboard", payload)))
+}
+
+func TestLoadFieldOverridesFailsOnKnownFieldsOfApisWithoutSchema(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+notification:
+  known:
+    - name
+`)
+
+	_, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.ErrorContains(t, err, "schema is not known")
+}
+
+func TestLoadFieldOverridesFailsOnUnknownApis(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+dashboards:
+  serverManaged:
+    - clusterVersion
+`)
+
+	_, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.ErrorContains(t, err, "unknown api dashboards")
+}
+
+func TestLoadFieldOverridesFailsOnUnknownKeys(t *testing.T) {
+
+	file := writeFieldOverrides(t, `
+dashboard:
+  stripped:
+    - clusterVersion
+`)
+
+	_, err := LoadFieldOverrides(file, util.NewFileReader())
+	assert.ErrorContains(t, err, "is invalid")
+}
@@ -0,0 +1,215 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"sort"
+	"strings"
+)
+
+// knownFields holds the fields of the payloads of the apis whose schema monaco knows. The server silently ignores
+// fields of payloads it does not know, so they are reported to catch typos in templates. Nested fields are only
+// checked for the fields listed with their nested fields, e.g. the conditions of management zone rules
+var knownFields = map[string][]string{
+	"management-zone": {
+		"name", "description", "rules", "dimensionalRules", "entitySelectorBasedRules",
+		"rules.type", "rules.enabled", "rules.propagationTypes", "rules.conditions",
+		"rules.conditions.key", "rules.conditions.comparisonInfo",
+	},
+	"auto-tag": {
+		"name", "description", "rules", "entitySelectorBasedRules",
+		"rules.type", "rules.enabled", "rules.valueFormat", "rules.normalization", "rules.propagationTypes", "rules.conditions",
+		"rules.conditions.key", "rules.conditions.comparisonInfo",
+		"entitySelectorBasedRules.enabled", "entitySelectorBasedRules.entitySelector", "entitySelectorBasedRules.valueFormat",
+		"entitySelectorBasedRules.normalization",
+	},
+	"alerting-profile": {
+		"displayName", "managementZoneId", "mzId", "rules", "eventTypeFilters",
+		"rules.severityLevel", "rules.tagFilter", "rules.delayInMinutes",
+		"rules.tagFilter.includeMode", "rules.tagFilter.tagFilters",
+	},
+	"maintenance-window": {
+		"name", "description", "type", "suppression", "suppressSyntheticMonitorsExecution", "scope", "schedule",
+	},
+	"anomaly-detection-metrics": {
+		"name", "description", "metricId", "metricSelector", "aggregationType", "eventType", "dimensions", "metricDimensions",
+		"alertingScope", "severity", "monitoringStrategy", "primaryDimensionKey", "queryOffset", "alertCondition",
+		"samples", "violatingSamples", "dealertingSamples", "threshold", "enabled", "tagFilters", "unit",
+		"disabledReason", "warningReason",
+	},
+	"dashboard": {
+		"dashboardMetadata", "tiles",
+	},
+}
+
+// objectFields holds the fields of the known schemas whose values are single objects rather than lists of objects.
+// Sources representing every nested object as a list, e.g. the blocks of the Terraform provider, are converted by them
+var objectFields = map[string][]string{
+	"management-zone":  {"rules.conditions.key", "rules.conditions.comparisonInfo"},
+	"auto-tag":         {"rules.conditions.key", "rules.conditions.comparisonInfo"},
+	"alerting-profile": {"rules.tagFilter"},
+	"maintenance-window": {
+		"scope", "schedule",
+	},
+	"anomaly-detection-metrics": {
+		"alertingScope", "monitoringStrategy",
+	},
+	"dashboard": {
+		"dashboardMetadata",
+	},
+}
+
+// IsObjectField checks if the schema of the api defines the field as a single object
+func IsObjectField(apiId string, field string) bool {
+	return containsField(objectFields[apiId], field)
+}
+
+// UnknownField is a field of a payload the api does not know, with the known field it was most likely meant to be
+type UnknownField struct {
+	Field      string
+	Suggestion string
+}
+
+// maxSuggestionDistance is the maximum number of edits between an unknown field and the field suggested for it
+const maxSuggestionDistance = 2
+
+// UnknownFields returns the fields of the payload which are not part of the schema of the api, sorted by field.
+// Nothing is returned for apis whose schema is unknown
+func (o FieldOverrides) UnknownFields(apiId string, payload map[string]interface{}) []UnknownField {
+
+	fields, found := o.knownFields(apiId)
+	if !found {
+		return nil
+	}
+
+	schema := make(map[string]bool)
+	for _, field := range fields {
+		schema[field] = true
+	}
+
+	allowed := make(map[string]bool)
+	for _, field := range append(o.DiffIgnoredFields(apiId), fields...) {
+		allowed[field] = true
+	}
+
+	unknown := make([]UnknownField, 0)
+	collectUnknownFields("", payload, schema, allowed, &unknown)
+
+	sort.Slice(unknown, func(i, j int) bool { return unknown[i].Field < unknown[j].Field })
+	return unknown
+}
+
+// collectUnknownFields walks the value at path. Fields of the schema and server managed or diff ignored fields are
+// allowed, but only the schema decides which nested fields are checked and which fields are suggested
+func collectUnknownFields(path string, value interface{}, schema map[string]bool, allowed map[string]bool, unknown *[]UnknownField) {
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		checked := path == "" || hasNestedFields(schema, path)
+		for key, inner := range typed {
+			field := joinField(path, key)
+			switch {
+			case allowed[field]:
+				collectUnknownFields(field, inner, schema, allowed, unknown)
+			case checked:
+				*unknown = append(*unknown, UnknownField{Field: field, Suggestion: suggestField(schema, path, key)})
+			}
+		}
+	case []interface{}:
+		for _, inner := range typed {
+			collectUnknownFields(path, inner, schema, allowed, unknown)
+		}
+	}
+}
+
+// hasNestedFields checks if the schema lists nested fields of the field at path
+func hasNestedFields(schema map[string]bool, path string) bool {
+	for field := range schema {
+		if strings.HasPrefix(field, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestField returns the known field next to the unknown key, which is the fewest edits away from it
+func suggestField(schema map[string]bool, path string, key string) string {
+
+	suggestion := ""
+	best := maxSuggestionDistance + 1
+
+	for field := range schema {
+		name := field
+		if path != "" {
+			if !strings.HasPrefix(field, path+".") {
+				continue
+			}
+			name = strings.TrimPrefix(field, path+".")
+		}
+		if strings.Contains(name, ".") {
+			continue
+		}
+
+		distance := editDistance(strings.ToLower(key), strings.ToLower(name))
+		if distance < best || (distance == best && joinField(path, name) < suggestion) {
+			suggestion, best = joinField(path, name), distance
+		}
+	}
+
+	return suggestion
+}
+
+func joinField(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// editDistance returns the Levenshtein distance of a and b
+func editDistance(a string, b string) int {
+
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func min(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,89 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestUnknownFieldsReportsTyposWithSuggestions(t *testing.T) {
+
+	payload := map[string]interface{}{
+		"name":            "zone",
+		"managementZones": []interface{}{},
+		"rules": []interface{}{
+			map[string]interface{}{"type": "SERVICE", "enable": true},
+		},
+	}
+
+	unknown := FieldOverrides{}.UnknownFields("auto-tag", payload)
+
+	assert.DeepEqual(t, []UnknownField{
+		{Field: "managementZones"},
+		{Field: "rules.enable", Suggestion: "rules.enabled"},
+	}, unknown)
+}
+
+func TestUnknownFieldsSuggestsClosestField(t *testing.T) {
+
+	unknown := FieldOverrides{}.UnknownFields("management-zone", map[string]interface{}{"nmae": "zone", "descriptoin": "text"})
+
+	assert.DeepEqual(t, []UnknownField{
+		{Field: "descriptoin", Suggestion: "description"},
+		{Field: "nmae", Suggestion: "name"},
+	}, unknown)
+}
+
+func TestUnknownFieldsAllowsServerManagedFields(t *testing.T) {
+
+	payload := map[string]interface{}{
+		"id":            "1234",
+		"metadata":      map[string]interface{}{"clusterVersion": "1.200"},
+		ProvenanceField: map[string]interface{}{},
+		"name":          "zone",
+		"rules":         []interface{}{map[string]interface{}{"id": "5678", "conditions": []interface{}{}}},
+	}
+
+	assert.Equal(t, 0, len(FieldOverrides{}.UnknownFields("management-zone", payload)))
+}
+
+func TestUnknownFieldsOnlyChecksNestedFieldsOfTheSchema(t *testing.T) {
+
+	payload := map[string]interface{}{
+		"dashboardMetadata": map[string]interface{}{"name": "dashboard", "owner": "someone", "anything": true},
+		"tiles":             []interface{}{map[string]interface{}{"anything": true}},
+	}
+
+	assert.Equal(t, 0, len(FieldOverrides{}.UnknownFields("dashboard", payload)))
+}
+
+func TestUnknownFieldsSkipsApisWithoutSchema(t *testing.T) {
+
+	assert.Equal(t, 0, len(FieldOverrides{}.UnknownFields("settings", map[string]interface{}{"anything": true})))
+}
+
+func TestEditDistance(t *testing.T) {
+
+	assert.Equal(t, 0, editDistance("rules", "rules"))
+	assert.Equal(t, 1, editDistance("rule", "rules"))
+	assert.Equal(t, 2, editDistance("nmae", "name"))
+	assert.Equal(t, 5, editDistance("", "rules"))
+}
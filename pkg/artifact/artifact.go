@@ -0,0 +1,226 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifact
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+)
+
+// manifestFile is the file within an artifact describing its version and contents
+const manifestFile = "monaco-artifact.json"
+
+// Manifest describes a packaged artifact. It records the digest of every packaged file, so that the deployment of
+// an artifact can verify it deploys exactly the files which were packaged
+type Manifest struct {
+	Version       string            `json:"version"`
+	MonacoVersion string            `json:"monacoVersion"`
+	Created       time.Time         `json:"created"`
+	Files         map[string]string `json:"files"`
+}
+
+// Package writes all files of the projects in folder into the zip archive file, along with a manifest of the given
+// version. Hidden files and folders, e.g. .git or .logs, are not packaged
+func Package(folder string, artifactVersion string, file string, now time.Time) (manifest Manifest, err error) {
+
+	manifest = Manifest{Version: artifactVersion, MonacoVersion: version.MonitoringAsCode, Created: now.UTC(), Files: make(map[string]string)}
+
+	output, err := filepath.Abs(file)
+	if err != nil {
+		return manifest, err
+	}
+
+	var files []string
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != folder && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if absolute, err := filepath.Abs(path); info.IsDir() || err != nil || absolute == output {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		files = append(files, filepath.ToSlash(relativePath))
+		return nil
+	})
+	if err != nil {
+		return manifest, err
+	}
+
+	if len(files) == 0 {
+		return manifest, fmt.Errorf("folder %s does not contain any files to package", folder)
+	}
+
+	out, err := os.Create(file)
+	if err != nil {
+		return manifest, err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	archive := zip.NewWriter(out)
+
+	for _, name := range files {
+		content, err := ioutil.ReadFile(filepath.Join(folder, filepath.FromSlash(name)))
+		if err != nil {
+			return manifest, err
+		}
+
+		err = writeEntry(archive, name, content, manifest.Created)
+		if err != nil {
+			return manifest, err
+		}
+		manifest.Files[name] = digest(content)
+	}
+
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return manifest, err
+	}
+
+	err = writeEntry(archive, manifestFile, append(content, '\n'), manifest.Created)
+	if err != nil {
+		return manifest, err
+	}
+
+	return manifest, archive.Close()
+}
+
+// Extract extracts the artifact file into folder and verifies that its files match its manifest
+func Extract(file string, folder string) (Manifest, error) {
+
+	var manifest Manifest
+
+	err := util.UnzipToFolder(file, folder)
+	if err != nil {
+		return manifest, err
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(folder, manifestFile))
+	if os.IsNotExist(err) {
+		return manifest, fmt.Errorf("%s is no monaco artifact, it does not contain %s", file, manifestFile)
+	}
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("manifest of artifact %s is invalid: %s", file, err)
+	}
+
+	err = verify(folder, manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("artifact %s does not match its manifest: %s", file, err)
+	}
+
+	return manifest, nil
+}
+
+// verify checks that folder contains exactly the files of the manifest, with the recorded digests
+func verify(folder string, manifest Manifest) error {
+
+	found := make(map[string]bool, len(manifest.Files))
+
+	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(relativePath)
+		if name == manifestFile {
+			return nil
+		}
+
+		expected, known := manifest.Files[name]
+		if !known {
+			return fmt.Errorf("file %s is not part of the manifest", name)
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if digest(content) != expected {
+			return fmt.Errorf("file %s was modified", name)
+		}
+
+		found[name] = true
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var missing []string
+	for name := range manifest.Files {
+		if !found[name] {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("files %s are missing", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+func writeEntry(archive *zip.Writer, name string, content []byte, modified time.Time) error {
+
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+	return err
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,153 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package artifact
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func writeTestProject(t *testing.T) string {
+
+	folder := filepath.Join(t.TempDir(), "projects")
+
+	files := map[string]string{
+		"project/dashboard/dashboard.json": `{"dashboardMetadata": {"name": "{{ .name }}"}}`,
+		"project/dashboard/dashboard.yaml": "config:\n  - overview: \"dashboard.json\"\n",
+		"delete.yaml":                      "delete:\n  - \"dashboard/old\"\n",
+		".git/config":                      "[core]",
+		"project/.hidden":                  "hidden",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(folder, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0777))
+		assert.NilError(t, ioutil.WriteFile(path, []byte(content), 0664))
+	}
+
+	return folder
+}
+
+func TestPackagedArtifactCanBeExtracted(t *testing.T) {
+
+	folder := writeTestProject(t)
+	file := filepath.Join(t.TempDir(), "artifact.zip")
+	created := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	manifest, err := Package(folder, "1.2.3", file, created)
+	assert.NilError(t, err)
+	assert.Equal(t, len(manifest.Files), 3)
+
+	target := t.TempDir()
+	extracted, err := Extract(file, target)
+	assert.NilError(t, err)
+
+	assert.Equal(t, extracted.Version, "1.2.3")
+	assert.Equal(t, extracted.Created, created)
+	assert.DeepEqual(t, extracted.Files, manifest.Files)
+
+	content, err := ioutil.ReadFile(filepath.Join(target, "project", "dashboard", "dashboard.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `{"dashboardMetadata": {"name": "{{ .name }}"}}`)
+
+	_, err = os.Stat(filepath.Join(target, ".git", "config"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestExtractFailsForModifiedArtifacts(t *testing.T) {
+
+	folder := writeTestProject(t)
+	file := filepath.Join(t.TempDir(), "artifact.zip")
+
+	_, err := Package(folder, "1.2.3", file, time.Now())
+	assert.NilError(t, err)
+
+	modified := filepath.Join(t.TempDir(), "modified.zip")
+	rewriteArtifact(t, file, modified, func(name string, content []byte) []byte {
+		if name == "delete.yaml" {
+			return []byte("delete:\n  - \"dashboard/overview\"\n")
+		}
+		return content
+	})
+
+	_, err = Extract(modified, t.TempDir())
+	assert.ErrorContains(t, err, "file delete.yaml was modified")
+}
+
+func TestExtractFailsForArchivesWithoutManifest(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "snapshot.zip")
+	out, err := os.Create(file)
+	assert.NilError(t, err)
+	archive := zip.NewWriter(out)
+	writer, err := archive.Create("project/dashboard/dashboard.json")
+	assert.NilError(t, err)
+	_, err = writer.Write([]byte("{}"))
+	assert.NilError(t, err)
+	assert.NilError(t, archive.Close())
+	assert.NilError(t, out.Close())
+
+	_, err = Extract(file, t.TempDir())
+	assert.ErrorContains(t, err, "is no monaco artifact")
+}
+
+func TestVerifyReportsMissingAndUnknownFiles(t *testing.T) {
+
+	folder := t.TempDir()
+	assert.NilError(t, ioutil.WriteFile(filepath.Join(folder, "extra.json"), []byte("{}"), 0664))
+
+	err := verify(folder, Manifest{Files: map[string]string{"extra.json": digest([]byte("{}")), "missing.json": digest([]byte("{}"))}})
+	assert.ErrorContains(t, err, "files missing.json are missing")
+
+	err = verify(folder, Manifest{Files: map[string]string{}})
+	assert.ErrorContains(t, err, "file extra.json is not part of the manifest")
+}
+
+// rewriteArtifact copies the artifact source to target, replacing the content of its files
+func rewriteArtifact(t *testing.T, source string, target string, replace func(name string, content []byte) []byte) {
+
+	reader, err := zip.OpenReader(source)
+	assert.NilError(t, err)
+	defer reader.Close()
+
+	out, err := os.Create(target)
+	assert.NilError(t, err)
+	defer out.Close()
+
+	archive := zip.NewWriter(out)
+	for _, file := range reader.File {
+		in, err := file.Open()
+		assert.NilError(t, err)
+		content, err := ioutil.ReadAll(in)
+		assert.NilError(t, err)
+		in.Close()
+
+		writer, err := archive.Create(file.Name)
+		assert.NilError(t, err)
+		_, err = writer.Write(replace(file.Name, content))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, archive.Close())
+}
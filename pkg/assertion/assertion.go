@@ -0,0 +1,231 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertion
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+
+// pollInterval is the time between two evaluations of an assertion which has to hold within a given time
+var pollInterval = 15 * time.Second
+
+// monitoredEntityApis are the apis whose objects are monitored entities Dynatrace detects problems for, and whose
+// ids are the ids of the entities
+var monitoredEntityApis = map[string]bool{
+	"synthetic-monitor": true,
+}
+
+// Assertion is a check of an object in an environment, which is evaluated after a project was deployed
+type Assertion struct {
+	Name   string  `yaml:"name"`
+	Api    string  `yaml:"api"`
+	Object string  `yaml:"object"`
+	Absent bool    `yaml:"absent"`
+	Checks []Check `yaml:"checks"`
+
+	// Healthy requires the object to have no open problems, e.g. a synthetic monitor whose executions succeed
+	Healthy bool `yaml:"healthy"`
+
+	// Within is the time the assertion may take to hold (e.g. "10m"), it is evaluated once if empty
+	Within string `yaml:"within"`
+}
+
+// Check is a condition on a field of the payload of an object. The field is addressed by its path, the keys
+// of nested objects joined by dots, e.g. `dashboardMetadata.shared`
+type Check struct {
+	Path     string      `yaml:"path"`
+	Equals   interface{} `yaml:"equals"`
+	MinCount *int        `yaml:"min-count"`
+}
+
+// Validate checks that the assertion is complete, so that invalid assertions are reported before deploying
+func (a *Assertion) Validate(apis map[string]api.Api) error {
+
+	if a.Name == "" {
+		return fmt.Errorf("assertion on %s %s has no name", a.Api, a.Object)
+	}
+	if _, found := apis[a.Api]; !found {
+		return fmt.Errorf("assertion %s refers to unknown api %s", a.Name, a.Api)
+	}
+	if a.Object == "" {
+		return fmt.Errorf("assertion %s has no object", a.Name)
+	}
+	if a.Absent && (len(a.Checks) > 0 || a.Healthy) {
+		return fmt.Errorf("assertion %s can not check an absent object", a.Name)
+	}
+	if a.Healthy && !monitoredEntityApis[a.Api] {
+		return fmt.Errorf("assertion %s can not check the health of %s objects, only of %s", a.Name, a.Api, strings.Join(sortedKeys(monitoredEntityApis), ", "))
+	}
+	if a.Within != "" {
+		if _, err := time.ParseDuration(a.Within); err != nil {
+			return fmt.Errorf("assertion %s has an invalid duration %s", a.Name, a.Within)
+		}
+	}
+
+	for i, check := range a.Checks {
+		if check.Path == "" {
+			return fmt.Errorf("check %d of assertion %s has no path", i+1, a.Name)
+		}
+		if (check.Equals == nil) == (check.MinCount == nil) {
+			return fmt.Errorf("check %d of assertion %s must define either equals or min-count", i+1, a.Name)
+		}
+	}
+
+	return nil
+}
+
+// Evaluate checks the assertion against the environment of client. Assertions with a duration are evaluated
+// repeatedly, until they hold or the duration passed
+func (a *Assertion) Evaluate(client rest.DynatraceClient, apis map[string]api.Api) error {
+
+	var within time.Duration
+	if a.Within != "" {
+		within, _ = time.ParseDuration(a.Within)
+	}
+	deadline := time.Now().Add(within)
+
+	for {
+		err := a.evaluateOnce(client, apis[a.Api])
+		if err == nil || !time.Now().Add(pollInterval).Before(deadline) {
+			return err
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (a *Assertion) evaluateOnce(client rest.DynatraceClient, objectApi api.Api) error {
+
+	exists, id, err := client.ExistsByName(objectApi, a.Object)
+	if err != nil {
+		return err
+	}
+
+	if a.Absent {
+		if exists {
+			return fmt.Errorf("%s %s exists", a.Api, a.Object)
+		}
+		return nil
+	}
+	if !exists {
+		return fmt.Errorf("%s %s does not exist", a.Api, a.Object)
+	}
+
+	if a.Healthy {
+		err = a.evaluateHealth(client, id)
+		if err != nil {
+			return err
+		}
+	}
+	if len(a.Checks) == 0 {
+		return nil
+	}
+
+	payload, err := client.ReadById(objectApi, id)
+	if err != nil {
+		return err
+	}
+
+	var content interface{}
+	err = json.Unmarshal(payload, &content)
+	if err != nil {
+		return fmt.Errorf("%s %s could not be read: %s", a.Api, a.Object, err)
+	}
+
+	for _, check := range a.Checks {
+		err = check.evaluate(content)
+		if err != nil {
+			return fmt.Errorf("%s %s: %s", a.Api, a.Object, err)
+		}
+	}
+
+	return nil
+}
+
+// evaluateHealth fails if Dynatrace detected open problems for the object with the given id
+func (a *Assertion) evaluateHealth(client rest.DynatraceClient, id string) error {
+
+	problemsClient, ok := client.(rest.ProblemsClient)
+	if !ok {
+		return fmt.Errorf("health of %s %s can not be checked, the client does not support problems", a.Api, a.Object)
+	}
+
+	count, err := problemsClient.CountOpenProblems(id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return fmt.Errorf("%s %s has %d open problems", a.Api, a.Object, count)
+	}
+	return nil
+}
+
+func (c Check) evaluate(content interface{}) error {
+
+	value, found := lookup(content, c.Path)
+
+	if c.MinCount != nil {
+		list, isList := value.([]interface{})
+		if !isList {
+			return fmt.Errorf("%s is no list", c.Path)
+		}
+		if len(list) < *c.MinCount {
+			return fmt.Errorf("%s has %d entries, expected at least %d", c.Path, len(list), *c.MinCount)
+		}
+		return nil
+	}
+
+	if !found {
+		return fmt.Errorf("%s is missing, expected %v", c.Path, c.Equals)
+	}
+
+	// yaml and json decode numbers into different types, so values are compared by their formatting
+	if fmt.Sprint(value) != fmt.Sprint(c.Equals) {
+		return fmt.Errorf("%s is %v, expected %v", c.Path, value, c.Equals)
+	}
+	return nil
+}
+
+func lookup(content interface{}, path string) (value interface{}, found bool) {
+
+	value = content
+	for _, key := range strings.Split(path, ".") {
+		object, isObject := value.(map[string]interface{})
+		if !isObject {
+			return nil, false
+		}
+		if value, found = object[key]; !found {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
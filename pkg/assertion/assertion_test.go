@@ -0,0 +1,169 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package assertion
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+var testApis = map[string]api.Api{
+	"management-zone":   api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	"synthetic-monitor": api.NewApi("synthetic-monitor", "/api/v1/synthetic/monitors"),
+}
+
+// testClient serves objects by name, every read counts as one evaluation
+type testClient struct {
+	rest.DynatraceClient
+	ids      map[string]string
+	payloads map[string]string
+	reads    int
+}
+
+func (c *testClient) ExistsByName(a api.Api, name string) (bool, string, error) {
+	c.reads++
+	id, found := c.ids[name]
+	return found, id, nil
+}
+
+func (c *testClient) ReadById(a api.Api, id string) ([]byte, error) {
+	payload, found := c.payloads[id]
+	if !found {
+		return nil, fmt.Errorf("%s not found", id)
+	}
+	return []byte(payload), nil
+}
+
+func newTestZoneClient() *testClient {
+	return &testClient{
+		ids:      map[string]string{"zone": "1"},
+		payloads: map[string]string{"1": `{"name": "zone", "rules": [{"enabled": true}], "meta": {"version": 3}}`},
+	}
+}
+
+func intPointer(value int) *int {
+	return &value
+}
+
+func TestValidateRejectsIncompleteAssertions(t *testing.T) {
+
+	assertions := map[string]Assertion{
+		"has no name":                {Api: "management-zone", Object: "zone"},
+		"unknown api":                {Name: "a", Api: "dashboard", Object: "zone"},
+		"has no object":              {Name: "a", Api: "management-zone"},
+		"can not check an absent":    {Name: "a", Api: "management-zone", Object: "zone", Absent: true, Checks: []Check{{Path: "name", Equals: "zone"}}},
+		"invalid duration":           {Name: "a", Api: "management-zone", Object: "zone", Within: "soon"},
+		"has no path":                {Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{{Equals: "zone"}}},
+		"either equals or min-count": {Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{{Path: "rules"}}},
+		"health of management-zone":  {Name: "a", Api: "management-zone", Object: "zone", Healthy: true},
+		"a can not check an absent":  {Name: "a", Api: "synthetic-monitor", Object: "check", Absent: true, Healthy: true},
+	}
+
+	for expected, assertion := range assertions {
+		err := assertion.Validate(testApis)
+		assert.ErrorContains(t, err, expected)
+	}
+
+	valid := Assertion{Name: "a", Api: "management-zone", Object: "zone", Within: "10m", Checks: []Check{{Path: "rules", MinCount: intPointer(1)}}}
+	assert.NilError(t, valid.Validate(testApis))
+}
+
+func TestEvaluateChecksPayload(t *testing.T) {
+
+	client := newTestZoneClient()
+
+	passing := Assertion{Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{
+		{Path: "rules", MinCount: intPointer(1)},
+		{Path: "meta.version", Equals: 3},
+	}}
+	assert.NilError(t, passing.Evaluate(client, testApis))
+
+	tooFewRules := Assertion{Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{{Path: "rules", MinCount: intPointer(2)}}}
+	assert.ErrorContains(t, tooFewRules.Evaluate(client, testApis), "rules has 1 entries, expected at least 2")
+
+	wrongValue := Assertion{Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{{Path: "meta.version", Equals: 4}}}
+	assert.ErrorContains(t, wrongValue.Evaluate(client, testApis), "meta.version is 3, expected 4")
+
+	missingField := Assertion{Name: "a", Api: "management-zone", Object: "zone", Checks: []Check{{Path: "meta.owner", Equals: "team"}}}
+	assert.ErrorContains(t, missingField.Evaluate(client, testApis), "meta.owner is missing, expected team")
+}
+
+func TestEvaluateChecksExistence(t *testing.T) {
+
+	client := newTestZoneClient()
+
+	assert.NilError(t, (&Assertion{Name: "a", Api: "management-zone", Object: "zone"}).Evaluate(client, testApis))
+	assert.ErrorContains(t, (&Assertion{Name: "a", Api: "management-zone", Object: "other"}).Evaluate(client, testApis), "does not exist")
+
+	assert.NilError(t, (&Assertion{Name: "a", Api: "management-zone", Object: "other", Absent: true}).Evaluate(client, testApis))
+	assert.ErrorContains(t, (&Assertion{Name: "a", Api: "management-zone", Object: "zone", Absent: true}).Evaluate(client, testApis), "exists")
+}
+
+func TestEvaluateRetriesWithinDuration(t *testing.T) {
+
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = time.Millisecond
+
+	client := newTestZoneClient()
+
+	failing := Assertion{Name: "a", Api: "management-zone", Object: "other", Within: "50ms"}
+	assert.ErrorContains(t, failing.Evaluate(client, testApis), "does not exist")
+	assert.Assert(t, client.reads > 1, "assertion was evaluated %d times", client.reads)
+
+	client.reads = 0
+	once := Assertion{Name: "a", Api: "management-zone", Object: "other"}
+	assert.ErrorContains(t, once.Evaluate(client, testApis), "does not exist")
+	assert.Equal(t, client.reads, 1)
+}
+
+// testProblemsClient is a testClient reporting the given number of open problems for every object
+type testProblemsClient struct {
+	testClient
+	openProblems int
+}
+
+func (c *testProblemsClient) CountOpenProblems(entityId string) (int, error) {
+	c.reads++
+	return c.openProblems, nil
+}
+
+func TestEvaluateChecksHealthWithinDuration(t *testing.T) {
+
+	defer func(interval time.Duration) { pollInterval = interval }(pollInterval)
+	pollInterval = time.Millisecond
+
+	client := &testProblemsClient{
+		testClient:   testClient{ids: map[string]string{"check": "SYNTHETIC_TEST-1"}},
+		openProblems: 1,
+	}
+
+	healthy := Assertion{Name: "a", Api: "synthetic-monitor", Object: "check", Healthy: true, Within: "20ms"}
+	assert.ErrorContains(t, healthy.Evaluate(client, testApis), "synthetic-monitor check has 1 open problems")
+
+	client.openProblems = 0
+	assert.NilError(t, healthy.Evaluate(client, testApis))
+
+	withoutProblems := &testClient{ids: map[string]string{"check": "SYNTHETIC_TEST-1"}}
+	assert.ErrorContains(t, healthy.Evaluate(withoutProblems, testApis), "the client does not support problems")
+}
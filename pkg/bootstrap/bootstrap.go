@@ -0,0 +1,112 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package bootstrap holds curated projects new environments can be set up with. The projects are kept in the
+// profiles folder and compiled into monaco, see profiles_gen.go
+package bootstrap
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:generate go run ./gen -output profiles_gen.go
+
+// Profile is a curated project monaco ships with
+type Profile struct {
+	Name        string
+	Description string
+
+	// Files holds the contents of all files of the project by their slash separated path within the project
+	Files map[string]string
+}
+
+// aliases holds short names of profiles
+var aliases = map[string]string{
+	"baseline": "baseline-alerts",
+	"k8s":      "k8s-monitoring",
+}
+
+// Profiles returns all profiles sorted by name
+func Profiles() []Profile {
+
+	result := make([]Profile, len(profiles))
+	copy(result, profiles)
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// GetProfile returns the profile with the given name or alias
+func GetProfile(name string) (Profile, error) {
+
+	if aliased, found := aliases[name]; found {
+		name = aliased
+	}
+
+	names := make([]string, 0, len(profiles))
+	for _, p := range Profiles() {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+
+	return Profile{}, fmt.Errorf("unknown profile %s, available profiles are %s", name, strings.Join(names, ", "))
+}
+
+// Materialize writes the files of the profile into folder and returns the paths of the written files. Existing
+// files are never overwritten, nothing is written if any of the files already exists
+func Materialize(p Profile, folder string) ([]string, error) {
+
+	paths := make([]string, 0, len(p.Files))
+	for file := range p.Files {
+		paths = append(paths, file)
+	}
+	sort.Strings(paths)
+
+	existing := make([]string, 0)
+	for _, file := range paths {
+		path := filepath.Join(folder, filepath.FromSlash(file))
+		if _, err := os.Stat(path); err == nil {
+			existing = append(existing, path)
+		}
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("profile %s can not be written to %s, as these files already exist: %s", p.Name, folder, strings.Join(existing, ", "))
+	}
+
+	written := make([]string, 0, len(paths))
+	for _, file := range paths {
+		path := filepath.Join(folder, filepath.FromSlash(file))
+
+		err := os.MkdirAll(filepath.Dir(path), 0777)
+		if err != nil {
+			return written, err
+		}
+
+		err = ioutil.WriteFile(path, []byte(p.Files[file]), 0644)
+		if err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,122 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bootstrap
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestProfilesAreGeneratedFromProfilesFolder(t *testing.T) {
+
+	for _, p := range Profiles() {
+		folder := filepath.Join("profiles", p.Name)
+
+		files := make(map[string]string)
+		err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || info.Name() == "description.txt" {
+				return err
+			}
+			content, err := ioutil.ReadFile(path)
+			relative, _ := filepath.Rel(folder, path)
+			files[filepath.ToSlash(relative)] = string(content)
+			return err
+		})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, p.Files, files)
+	}
+}
+
+func TestGetProfileResolvesAliases(t *testing.T) {
+
+	p, err := GetProfile("k8s")
+	assert.NilError(t, err)
+	assert.Equal(t, p.Name, "k8s-monitoring")
+
+	p, err = GetProfile("baseline-alerts")
+	assert.NilError(t, err)
+	assert.Equal(t, p.Name, "baseline-alerts")
+
+	_, err = GetProfile("unknown")
+	assert.ErrorContains(t, err, "available profiles are baseline-alerts, k8s-monitoring")
+}
+
+func TestMaterializedProfilesRenderValidConfigs(t *testing.T) {
+
+	for _, p := range Profiles() {
+		t.Run(p.Name, func(t *testing.T) {
+
+			folder, err := ioutil.TempDir(".", "monaco-bootstrap-")
+			assert.NilError(t, err)
+			defer os.RemoveAll(folder)
+
+			written, err := Materialize(p, filepath.Join(folder, "project"))
+			assert.NilError(t, err)
+			assert.Equal(t, len(written), len(p.Files))
+
+			projects, err := project.LoadProjectsToDeploy("project", api.NewApis(), folder, util.NewFileReader())
+			assert.NilError(t, err)
+			assert.Equal(t, len(projects), 1)
+
+			env := environment.NewEnvironment("dev", "Dev", "", "https://dev.live.dynatrace.com", "TOKEN")
+			dict := make(map[string]api.DynatraceEntity)
+			for _, c := range projects[0].GetConfigs() {
+				rendered, err := c.GetConfigForEnvironment(env, dict)
+				assert.NilError(t, err, c.GetFullQualifiedId())
+
+				var payload map[string]interface{}
+				assert.NilError(t, json.Unmarshal([]byte(rendered), &payload), c.GetFullQualifiedId())
+				dict[c.GetFullQualifiedId()] = api.DynatraceEntity{Id: c.GetId() + "-id", Name: c.GetId()}
+			}
+		})
+	}
+}
+
+func TestMaterializeDoesNotOverwriteFiles(t *testing.T) {
+
+	folder, err := ioutil.TempDir(".", "monaco-bootstrap-")
+	assert.NilError(t, err)
+	defer os.RemoveAll(folder)
+
+	p, err := GetProfile("baseline")
+	assert.NilError(t, err)
+
+	existing := filepath.Join(folder, "alerting-profile", "baseline.yaml")
+	assert.NilError(t, os.MkdirAll(filepath.Dir(existing), 0777))
+	assert.NilError(t, ioutil.WriteFile(existing, []byte("config:\n"), 0644))
+
+	_, err = Materialize(p, folder)
+	assert.ErrorContains(t, err, "already exist")
+
+	content, err := ioutil.ReadFile(existing)
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "config:\n")
+
+	_, err = os.Stat(filepath.Join(folder, "anomaly-detection-metrics"))
+	assert.Assert(t, os.IsNotExist(err), "no file must be written")
+}
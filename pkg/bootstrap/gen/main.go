@@ -0,0 +1,132 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command gen compiles the projects of the profiles folder into the profiles of pkg/bootstrap
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// descriptionFile holds the description of a profile, it is not part of the project
+const descriptionFile = "description.txt"
+
+type generatedProfile struct {
+	Name        string
+	Description string
+	Files       map[string]string
+}
+
+var generated = template.Must(template.New("profiles").Parse(`// Code generated by pkg/bootstrap/gen. DO NOT EDIT.
+
+package bootstrap
+
+var profiles = []Profile{
+{{- range . }}
+	{
+		Name:        {{ printf "%q" .Name }},
+		Description: {{ printf "%q" .Description }},
+		Files: map[string]string{
+		{{- range $path, $content := .Files }}
+			{{ printf "%q" $path }}: {{ printf "%q" $content }},
+		{{- end }}
+		},
+	},
+{{- end }}
+}
+`))
+
+func main() {
+
+	folder := flag.String("profiles", "profiles", "Folder holding a project per profile.")
+	output := flag.String("output", "profiles_gen.go", "File the profiles are written to.")
+	flag.Parse()
+
+	entries, err := ioutil.ReadDir(*folder)
+	if err != nil {
+		log.Fatalf("reading profiles failed: %s", err)
+	}
+
+	profiles := make([]generatedProfile, 0)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		profile, err := readProfile(filepath.Join(*folder, entry.Name()))
+		if err != nil {
+			log.Fatalf("reading profile %s failed: %s", entry.Name(), err)
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	var content bytes.Buffer
+	err = generated.Execute(&content, profiles)
+	if err != nil {
+		log.Fatalf("generating profiles failed: %s", err)
+	}
+
+	source, err := format.Source(content.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated profiles failed: %s", err)
+	}
+
+	err = ioutil.WriteFile(*output, source, 0644)
+	if err != nil {
+		log.Fatalf("writing %s failed: %s", *output, err)
+	}
+}
+
+// readProfile reads the description and all files of the project in folder
+func readProfile(folder string) (generatedProfile, error) {
+
+	profile := generatedProfile{Name: filepath.Base(folder), Files: make(map[string]string)}
+
+	err := filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		relative, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		if relative == descriptionFile {
+			profile.Description = strings.TrimSpace(string(content))
+		} else {
+			profile.Files[filepath.ToSlash(relative)] = string(content)
+		}
+		return nil
+	})
+
+	return profile, err
+}
@@ -0,0 +1,119 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+
+// docsUrl is the documentation of the error codes, each code has its own section
+const docsUrl = "https://github.com/dynatrace-oss/dynatrace-monitoring-as-code#"
+
+// Entry describes a common failure mode by a stable code, which support and runbooks can refer to
+type Entry struct {
+
+	// Code is the stable code of the failure, e.g. MON1001
+	Code  string
+	Title string
+
+	// Hint tells how to remediate the failure
+	Hint string
+
+	matches func(err error) bool
+}
+
+// DocsUrl returns the link to the documentation of the failure
+func (e Entry) DocsUrl() string {
+	return docsUrl + "mon" + e.Code[len("MON"):]
+}
+
+// Describe describes the failure by its code, its remediation and its documentation, e.g. to be logged after the error
+func (e Entry) Describe() string {
+	return fmt.Sprintf("%s: %s\n    Hint: %s\n    Docs: %s", e.Code, e.Title, e.Hint, e.DocsUrl())
+}
+
+// Entries are the failure modes with a code. Codes are never reused, new failure modes get new codes
+var Entries = []Entry{
+	{
+		Code:    "MON1001",
+		Title:   "authentication failed",
+		Hint:    "Check that the token of the environment is set, not expired and was created for this environment.",
+		matches: hasStatus(http.StatusUnauthorized),
+	},
+	{
+		Code:    "MON1002",
+		Title:   "permission denied",
+		Hint:    "Add the scopes the api requires to the token of the environment, e.g. 'Read configuration' and 'Write configuration'.",
+		matches: hasStatus(http.StatusForbidden),
+	},
+	{
+		Code:    "MON2001",
+		Title:   "payload rejected by validation",
+		Hint:    "Fix the fields named by the constraint violations, run a dry run with --validate-on-server to check settings configs.",
+		matches: hasStatus(http.StatusBadRequest),
+	},
+	{
+		Code:  "MON3001",
+		Title: "reference could not be resolved",
+		Hint:  "Check that the referenced config exists, is spelled as project/api/config and is not skipped for this environment.",
+		matches: func(err error) bool {
+			var referenceErr config.UnresolvedReferenceError
+			return errors.As(err, &referenceErr)
+		},
+	},
+	{
+		Code:    "MON4001",
+		Title:   "rate limit exceeded",
+		Hint:    "Lower --max-concurrent-requests or deploy the environments one after another, and retry once the limit is reset.",
+		matches: hasStatus(http.StatusTooManyRequests),
+	},
+	{
+		Code:    "MON4002",
+		Title:   "environment unavailable",
+		Hint:    "The environment is likely undergoing maintenance, retry later or with --maintenance-retries.",
+		matches: rest.IsMaintenance,
+	},
+}
+
+// Lookup returns the entry describing the failure which caused err
+func Lookup(err error) (Entry, bool) {
+
+	if err == nil {
+		return Entry{}, false
+	}
+
+	for _, entry := range Entries {
+		if entry.matches(err) {
+			return entry, true
+		}
+	}
+
+	return Entry{}, false
+}
+
+// hasStatus matches errors of requests answered with the given status code
+func hasStatus(statusCode int) func(error) bool {
+	return func(err error) bool {
+		var responseErr rest.ResponseError
+		return errors.As(err, &responseErr) && responseErr.StatusCode == statusCode
+	}
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package catalog
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+func TestLookupClassifiesWrappedErrors(t *testing.T) {
+
+	tests := []struct {
+		err  error
+		code string
+	}{
+		{rest.ResponseError{StatusCode: 401}, "MON1001"},
+		{fmt.Errorf("%w, responsible config: zone.json", rest.ResponseError{StatusCode: 403}), "MON1002"},
+		{rest.ResponseError{StatusCode: 400}, "MON2001"},
+		{fmt.Errorf("%w, responsible config: zone.json", config.UnresolvedReferenceError{Id: "project/management-zone/zone"}), "MON3001"},
+		{rest.ResponseError{StatusCode: 429}, "MON4001"},
+		{rest.ResponseError{StatusCode: 503}, "MON4002"},
+	}
+
+	for _, test := range tests {
+		entry, found := Lookup(test.err)
+		assert.Assert(t, found, test.err)
+		assert.Equal(t, entry.Code, test.code)
+	}
+}
+
+func TestLookupIgnoresUnknownFailures(t *testing.T) {
+
+	_, found := Lookup(rest.ResponseError{StatusCode: 500})
+	assert.Assert(t, !found)
+
+	_, found = Lookup(fmt.Errorf("something else failed"))
+	assert.Assert(t, !found)
+
+	_, found = Lookup(nil)
+	assert.Assert(t, !found)
+}
+
+func TestEntriesHaveUniqueCodes(t *testing.T) {
+
+	seen := make(map[string]bool)
+	for _, entry := range Entries {
+		assert.Assert(t, strings.HasPrefix(entry.Code, "MON") && len(entry.Code) == 7, entry.Code)
+		assert.Assert(t, !seen[entry.Code], entry.Code)
+		assert.Assert(t, entry.Hint != "", entry.Code)
+		seen[entry.Code] = true
+	}
+}
+
+func TestDescribe(t *testing.T) {
+
+	entry, _ := Lookup(rest.ResponseError{StatusCode: 429})
+	assert.Equal(t, entry.Describe(), "MON4001: rate limit exceeded\n"+
+		"    Hint: Lower --max-concurrent-requests or deploy the environments one after another, and retry once the limit is reset.\n"+
+		"    Docs: https://github.com/dynatrace-oss/dynatrace-monitoring-as-code#mon4001")
+}
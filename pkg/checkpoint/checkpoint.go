@@ -0,0 +1,161 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// Checkpoint records the progress of a deployment per environment: the configs which were deployed, with the
+// objects they were deployed to, and the configs which remained when the deployment was interrupted. Resuming from
+// a checkpoint only deploys the configs which were not completed yet. A nil checkpoint records nothing
+type Checkpoint struct {
+	lock         sync.Mutex
+	environments map[string]*progress
+}
+
+// progress is the json representation of the progress of a single environment
+type progress struct {
+	Completed map[string]api.DynatraceEntity `json:"completed"`
+	Remaining []string                       `json:"remaining"`
+}
+
+// checkpointFile is the json representation of a Checkpoint
+type checkpointFile struct {
+	Environments map[string]*progress `json:"environments"`
+}
+
+// NewCheckpoint creates a checkpoint without any progress
+func NewCheckpoint() *Checkpoint {
+	return &Checkpoint{environments: make(map[string]*progress)}
+}
+
+// LoadCheckpoint reads the checkpoint from the given file, which has to exist
+func LoadCheckpoint(file string, fileReader util.FileReader) (*Checkpoint, error) {
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed checkpointFile
+	err = json.Unmarshal(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("checkpoint file %s is invalid: %s", file, err)
+	}
+
+	checkpoint := NewCheckpoint()
+	for environment, p := range parsed.Environments {
+		if p == nil || p.Completed == nil {
+			return nil, fmt.Errorf("checkpoint file %s is invalid: progress of environment %s is missing", file, environment)
+		}
+		checkpoint.environments[environment] = p
+	}
+
+	return checkpoint, nil
+}
+
+// Completed returns the object the given config was deployed to, if its deployment completed
+func (c *Checkpoint) Completed(environment string, config string) (entity api.DynatraceEntity, completed bool) {
+
+	if c == nil {
+		return entity, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	p, found := c.environments[environment]
+	if !found {
+		return entity, false
+	}
+
+	entity, completed = p.Completed[config]
+	return entity, completed
+}
+
+// Complete records that the given config was deployed to entity
+func (c *Checkpoint) Complete(environment string, config string, entity api.DynatraceEntity) {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.progressOf(environment).Completed[config] = entity
+}
+
+// SetRemaining records the configs which are still to be deployed to the environment, in their order
+func (c *Checkpoint) SetRemaining(environment string, configs []string) {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.progressOf(environment).Remaining = configs
+}
+
+// Remaining returns the number of configs which remained in all environments
+func (c *Checkpoint) Remaining() (remaining int) {
+
+	if c == nil {
+		return 0
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range c.environments {
+		remaining += len(p.Remaining)
+	}
+	return remaining
+}
+
+// Save writes the checkpoint to the given file
+func (c *Checkpoint) Save(file string) error {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	content, err := json.MarshalIndent(checkpointFile{Environments: c.environments}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(content, '\n'), 0664)
+}
+
+func (c *Checkpoint) progressOf(environment string) *progress {
+
+	p, found := c.environments[environment]
+	if !found {
+		p = &progress{Completed: make(map[string]api.DynatraceEntity), Remaining: []string{}}
+		c.environments[environment] = p
+	}
+	return p
+}
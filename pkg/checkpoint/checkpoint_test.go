@@ -0,0 +1,82 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package checkpoint
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestSavedCheckpointCanBeLoaded(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	checkpoint := NewCheckpoint()
+	checkpoint.Complete("production", "project/management-zone/zone", api.DynatraceEntity{Id: "123", Name: "zone"})
+	checkpoint.SetRemaining("production", []string{"project/alerting-profile/profile"})
+	checkpoint.SetRemaining("staging", []string{"project/management-zone/zone", "project/alerting-profile/profile"})
+
+	err := checkpoint.Save(file)
+	assert.NilError(t, err)
+
+	loaded, err := LoadCheckpoint(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	entity, completed := loaded.Completed("production", "project/management-zone/zone")
+	assert.Assert(t, completed)
+	assert.Equal(t, entity.Id, "123")
+	assert.Equal(t, entity.Name, "zone")
+
+	_, completed = loaded.Completed("staging", "project/management-zone/zone")
+	assert.Assert(t, !completed)
+	assert.Equal(t, loaded.Remaining(), 3)
+}
+
+func TestLoadCheckpointFailsOnMissingFile(t *testing.T) {
+
+	_, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json"), util.NewFileReader())
+	assert.Assert(t, err != nil)
+}
+
+func TestLoadCheckpointFailsOnInvalidFile(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "invalid.json")
+	err := ioutil.WriteFile(file, []byte(`{"environments": {"production": null}}`), 0664)
+	assert.NilError(t, err)
+
+	_, err = LoadCheckpoint(file, util.NewFileReader())
+	assert.ErrorContains(t, err, "progress of environment production is missing")
+}
+
+func TestNilCheckpointRecordsNothing(t *testing.T) {
+
+	var checkpoint *Checkpoint
+
+	checkpoint.Complete("production", "project/management-zone/zone", api.DynatraceEntity{Id: "123"})
+	checkpoint.SetRemaining("production", []string{"project/alerting-profile/profile"})
+
+	_, completed := checkpoint.Completed("production", "project/management-zone/zone")
+	assert.Assert(t, !completed)
+	assert.Equal(t, checkpoint.Remaining(), 0)
+}
@@ -0,0 +1,309 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compare
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// unnamedApis are skipped, as their objects have no names which would allow to match them between environments
+var unnamedApis = map[string]bool{
+	"settings": true,
+}
+
+// ApiResult holds the differences of all configs of one api between two environments
+type ApiResult struct {
+	Api          string                       `json:"api"`
+	OnlyInFirst  []string                     `json:"onlyInFirst"`
+	OnlyInSecond []string                     `json:"onlyInSecond"`
+	Differences  map[string][]FieldDifference `json:"differences"`
+}
+
+// FieldDifference describes a field which has different values in the same-named config of both environments.
+// A missing field is represented by an empty value
+type FieldDifference struct {
+	Path   string `json:"path"`
+	First  string `json:"first"`
+	Second string `json:"second"`
+}
+
+// HasDifferences returns true if the environments differ in any way for this api
+func (r ApiResult) HasDifferences() bool {
+	return len(r.OnlyInFirst) > 0 || len(r.OnlyInSecond) > 0 || len(r.Differences) > 0
+}
+
+// DifferentConfigs returns the sorted names of all configs with field level differences
+func (r ApiResult) DifferentConfigs() []string {
+
+	names := make([]string, 0, len(r.Differences))
+	for name := range r.Differences {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CompareEnvironments lists, for all given apis, the configs which are only available in one of the two environments
+// and the field level differences of configs with the same name. Fields ignored on diff by fields are not compared.
+// Results are sorted by api id
+func CompareEnvironments(apis map[string]api.Api, first rest.DynatraceClient, second rest.DynatraceClient, fields api.FieldOverrides) (results []ApiResult, err error) {
+
+	apis = comparableApis(apis)
+
+	// both environments are listed at the same time, as each of them takes as long as its slowest api
+	var secondValues map[string][]api.Value
+	var secondErr error
+	listed := make(chan struct{})
+	go func() {
+		defer close(listed)
+		secondValues, secondErr = rest.ListAll(second, apis)
+	}()
+
+	firstValues, err := rest.ListAll(first, apis)
+	<-listed
+	if err != nil {
+		return results, err
+	}
+	if secondErr != nil {
+		return results, secondErr
+	}
+
+	for _, id := range sortedApiIds(apis) {
+
+		util.Log.Debug("Comparing %s...", id)
+
+		result, err := compareApi(apis[id], first, byName(firstValues[id]), second, byName(secondValues[id]), fields)
+		if err != nil {
+			return results, fmt.Errorf("comparison of %s failed: %s", id, err)
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// comparableApis filters the apis whose objects have no names to compare them by
+func comparableApis(apis map[string]api.Api) map[string]api.Api {
+
+	result := make(map[string]api.Api, len(apis))
+	for id, a := range apis {
+		if !unnamedApis[id] {
+			result[id] = a
+		}
+	}
+
+	return result
+}
+
+func compareApi(a api.Api, first rest.DynatraceClient, firstValues map[string]api.Value, second rest.DynatraceClient, secondValues map[string]api.Value, fields api.FieldOverrides) (result ApiResult, err error) {
+
+	result = ApiResult{
+		Api:          a.GetId(),
+		OnlyInFirst:  []string{},
+		OnlyInSecond: []string{},
+		Differences:  make(map[string][]FieldDifference),
+	}
+
+	for _, name := range sortedNames(firstValues) {
+
+		secondValue, found := secondValues[name]
+		if !found {
+			result.OnlyInFirst = append(result.OnlyInFirst, name)
+			continue
+		}
+
+		differences, err := compareConfig(a, first, firstValues[name], second, secondValue, fields)
+		if err != nil {
+			return result, err
+		}
+
+		if len(differences) > 0 {
+			result.Differences[name] = differences
+		}
+	}
+
+	for _, name := range sortedNames(secondValues) {
+		if _, found := firstValues[name]; !found {
+			result.OnlyInSecond = append(result.OnlyInSecond, name)
+		}
+	}
+
+	return result, nil
+}
+
+func compareConfig(a api.Api, first rest.DynatraceClient, firstValue api.Value, second rest.DynatraceClient, secondValue api.Value, fields api.FieldOverrides) ([]FieldDifference, error) {
+
+	firstFields, err := readFlattened(a, first, firstValue.Id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	secondFields, err := readFlattened(a, second, secondValue.Id, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFields(firstFields, secondFields), nil
+}
+
+// DiffPayloads returns the field level differences of two json payloads of the given api, after normalizing them
+func DiffPayloads(apiId string, first []byte, second []byte, fields api.FieldOverrides) ([]FieldDifference, error) {
+
+	firstFields, err := flattenPayload(apiId, first, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	secondFields, err := flattenPayload(apiId, second, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFields(firstFields, secondFields), nil
+}
+
+func flattenPayload(apiId string, payload []byte, overrides api.FieldOverrides) (map[string]string, error) {
+
+	var content interface{}
+	err := json.Unmarshal(payload, &content)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]string)
+	flatten("", normalize(apiId, content, overrides), fields)
+
+	return fields, nil
+}
+
+func byName(values []api.Value) map[string]api.Value {
+
+	result := make(map[string]api.Value, len(values))
+	for _, value := range values {
+		if _, exists := result[value.Name]; !exists {
+			result[value.Name] = value
+		}
+	}
+
+	return result
+}
+
+func readFlattened(a api.Api, client rest.DynatraceClient, id string, overrides api.FieldOverrides) (map[string]string, error) {
+
+	payload, err := client.ReadById(a, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var content map[string]interface{}
+	err = json.Unmarshal(payload, &content)
+	if err != nil {
+		return nil, fmt.Errorf("response for %s/%s is not a valid json object: %s", a.GetId(), id, err)
+	}
+
+	fields := make(map[string]string)
+	flatten("", normalize(a.GetId(), content, overrides), fields)
+
+	return fields, nil
+}
+
+// flatten converts the given json value to a map of json paths to single values,
+// e.g. {"a": {"b": [1]}} becomes {"a.b[0]": "1"}
+func flatten(path string, value interface{}, fields map[string]string) {
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, inner := range typed {
+			if path == "" {
+				flatten(key, inner, fields)
+			} else {
+				flatten(path+"."+key, inner, fields)
+			}
+		}
+	case []interface{}:
+		for i, inner := range typed {
+			flatten(path+"["+strconv.Itoa(i)+"]", inner, fields)
+		}
+	default:
+		encoded, _ := json.Marshal(typed)
+		fields[path] = string(encoded)
+	}
+}
+
+func diffFields(first map[string]string, second map[string]string) []FieldDifference {
+
+	differences := make([]FieldDifference, 0)
+
+	for _, path := range sortedPaths(first) {
+		if first[path] != second[path] {
+			differences = append(differences, FieldDifference{Path: path, First: first[path], Second: second[path]})
+		}
+	}
+
+	for _, path := range sortedPaths(second) {
+		if _, found := first[path]; !found {
+			differences = append(differences, FieldDifference{Path: path, Second: second[path]})
+		}
+	}
+
+	sort.Slice(differences, func(i, j int) bool {
+		return differences[i].Path < differences[j].Path
+	})
+
+	return differences
+}
+
+func sortedApiIds(apis map[string]api.Api) []string {
+
+	ids := make([]string, 0, len(apis))
+	for id := range apis {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
+
+func sortedNames(values map[string]api.Value) []string {
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedPaths(fields map[string]string) []string {
+
+	paths := make([]string, 0, len(fields))
+	for path := range fields {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	return paths
+}
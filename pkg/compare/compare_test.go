@@ -0,0 +1,196 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compare
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+var testManagementZoneApi = api.NewApi("management-zone", "/api/config/v1/managementZones")
+
+// testClient only serves reads, writes are not expected in these tests
+type testClient struct {
+	rest.DynatraceClient
+	values   []api.Value
+	payloads map[string]string
+}
+
+func (c *testClient) List(a api.Api) ([]api.Value, error) {
+	return c.values, nil
+}
+
+func (c *testClient) ReadById(a api.Api, id string) ([]byte, error) {
+	payload, found := c.payloads[id]
+	if !found {
+		return nil, fmt.Errorf("%s not found", id)
+	}
+	return []byte(payload), nil
+}
+
+func TestCompareEnvironmentsFindsConfigsOnlyInOneEnvironment(t *testing.T) {
+
+	first := &testClient{
+		values:   []api.Value{{Id: "1", Name: "zone-a"}, {Id: "2", Name: "zone-b"}},
+		payloads: map[string]string{"1": `{"name": "zone-a"}`, "2": `{"name": "zone-b"}`},
+	}
+	second := &testClient{
+		values:   []api.Value{{Id: "3", Name: "zone-b"}, {Id: "4", Name: "zone-c"}},
+		payloads: map[string]string{"3": `{"name": "zone-b"}`, "4": `{"name": "zone-c"}`},
+	}
+
+	apis := map[string]api.Api{"management-zone": testManagementZoneApi}
+
+	results, err := CompareEnvironments(apis, first, second, nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(results), 1)
+	assert.Equal(t, results[0].Api, "management-zone")
+	assert.DeepEqual(t, results[0].OnlyInFirst, []string{"zone-a"})
+	assert.DeepEqual(t, results[0].OnlyInSecond, []string{"zone-c"})
+	assert.Equal(t, len(results[0].Differences), 0)
+}
+
+func TestCompareEnvironmentsFindsFieldDifferences(t *testing.T) {
+
+	first := &testClient{
+		values:   []api.Value{{Id: "1", Name: "zone"}},
+		payloads: map[string]string{"1": `{"id": "1", "name": "zone", "rules": [{"enabled": true}], "description": "a"}`},
+	}
+	second := &testClient{
+		values:   []api.Value{{Id: "2", Name: "zone"}},
+		payloads: map[string]string{"2": `{"id": "2", "name": "zone", "rules": [{"enabled": false}], "other": 1}`},
+	}
+
+	apis := map[string]api.Api{"management-zone": testManagementZoneApi}
+
+	results, err := CompareEnvironments(apis, first, second, nil)
+	assert.NilError(t, err)
+
+	assert.Assert(t, results[0].HasDifferences())
+	assert.DeepEqual(t, results[0].Differences["zone"], []FieldDifference{
+		{Path: "description", First: `"a"`, Second: ""},
+		{Path: "other", First: "", Second: "1"},
+		{Path: "rules[0].enabled", First: "true", Second: "false"},
+	})
+}
+
+func TestCompareEnvironmentsWithEqualEnvironments(t *testing.T) {
+
+	client := &testClient{
+		values:   []api.Value{{Id: "1", Name: "zone"}},
+		payloads: map[string]string{"1": `{"id": "1", "name": "zone", "metadata": {"clusterVersion": "1.2"}}`},
+	}
+
+	apis := map[string]api.Api{"management-zone": testManagementZoneApi}
+
+	results, err := CompareEnvironments(apis, client, client, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !results[0].HasDifferences())
+}
+
+func TestCompareEnvironmentsFailsOnUnreadableConfig(t *testing.T) {
+
+	first := &testClient{
+		values:   []api.Value{{Id: "1", Name: "zone"}},
+		payloads: map[string]string{},
+	}
+
+	apis := map[string]api.Api{"management-zone": testManagementZoneApi}
+
+	_, err := CompareEnvironments(apis, first, first, nil)
+	assert.ErrorContains(t, err, "comparison of management-zone failed")
+}
+
+// rendezvousClient only returns its values once the other environment is listed as well
+type rendezvousClient struct {
+	testClient
+	listing *sync.WaitGroup
+}
+
+func (c *rendezvousClient) List(a api.Api) ([]api.Value, error) {
+
+	c.listing.Done()
+
+	listed := make(chan struct{})
+	go func() {
+		c.listing.Wait()
+		close(listed)
+	}()
+
+	select {
+	case <-listed:
+		return c.values, nil
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("other environment was not listed at the same time")
+	}
+}
+
+func TestCompareEnvironmentsListsBothEnvironmentsConcurrently(t *testing.T) {
+
+	listing := &sync.WaitGroup{}
+	listing.Add(2)
+
+	first := &rendezvousClient{testClient: testClient{values: []api.Value{{Id: "1", Name: "zone-a"}}}, listing: listing}
+	second := &rendezvousClient{testClient: testClient{values: []api.Value{{Id: "2", Name: "zone-b"}}}, listing: listing}
+
+	results, err := CompareEnvironments(map[string]api.Api{"management-zone": testManagementZoneApi}, first, second, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, results[0].OnlyInFirst, []string{"zone-a"})
+	assert.DeepEqual(t, results[0].OnlyInSecond, []string{"zone-b"})
+}
+
+func TestDiffPayloads(t *testing.T) {
+
+	differences, err := DiffPayloads("management-zone", []byte(`{"id": "1", "name": "zone", "rules": [1, 2]}`), []byte(`{"id": "1", "name": "zone", "rules": [1]}`), nil)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, differences, []FieldDifference{
+		{Path: "rules[1]", First: "2", Second: ""},
+	})
+}
+
+func TestDiffPayloadsIgnoresFieldsOfOverrides(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "fields.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte("management-zone: {ignoredOnDiff: [rules]}"), 0664))
+
+	overrides, err := api.LoadFieldOverrides(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	differences, err := DiffPayloads("management-zone", []byte(`{"name": "zone", "rules": [1, 2]}`), []byte(`{"name": "zone", "rules": [1]}`), overrides)
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(differences))
+}
+
+func TestDiffPayloadsFailsOnInvalidJson(t *testing.T) {
+
+	_, err := DiffPayloads("management-zone", []byte(`{}`), []byte(`{`), nil)
+	assert.Assert(t, err != nil)
+}
@@ -0,0 +1,147 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compare
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// normalization describes how the payloads of an api are normalized before comparing them, so that comparisons only
+// show differences in the configuration and not the ones the server introduces. Fields are addressed by the keys of
+// nested objects joined by dots, lists are transparent, e.g. `rules.conditions` addresses the conditions of all rules
+type normalization struct {
+
+	// ignoredFields are server generated or managed and therefore dropped, as defined by the api catalog
+	ignoredFields []string
+
+	// unorderedLists are lists the server does not keep the order of, their entries are sorted
+	unorderedLists []string
+}
+
+// normalizations holds the lists of each api which are sorted
+var normalizations = map[string]normalization{
+	"alerting-profile": {
+		unorderedLists: []string{"rules", "eventTypeFilters"},
+	},
+	"auto-tag": {
+		unorderedLists: []string{"rules", "rules.conditions", "entitySelectorBasedRules"},
+	},
+	"management-zone": {
+		unorderedLists: []string{"rules", "rules.conditions", "dimensionalRules", "dimensionalRules.conditions"},
+	},
+	"maintenance-window": {
+		unorderedLists: []string{"scope.entities", "scope.matches", "scope.matches.tags"},
+	},
+	"notification": {
+		unorderedLists: []string{"headers"},
+	},
+	"synthetic-monitor": {
+		unorderedLists: []string{"locations", "tags", "manuallyAssignedApps"},
+	},
+	"conditional-naming-host": {
+		unorderedLists: []string{"conditions"},
+	},
+	"conditional-naming-processgroup": {
+		unorderedLists: []string{"conditions"},
+	},
+	"conditional-naming-service": {
+		unorderedLists: []string{"conditions"},
+	},
+	"request-naming-service": {
+		unorderedLists: []string{"conditions"},
+	},
+	"request-attributes": {
+		unorderedLists: []string{"dataSources"},
+	},
+}
+
+// normalize returns a normalized copy of the json content of a payload of the given api
+func normalize(apiId string, content interface{}, fields api.FieldOverrides) interface{} {
+
+	rules := normalization{
+		ignoredFields:  fields.DiffIgnoredFields(apiId),
+		unorderedLists: normalizations[apiId].unorderedLists,
+	}
+
+	return rules.apply("", content)
+}
+
+func (n normalization) apply(path string, value interface{}) interface{} {
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, inner := range typed {
+			innerPath := key
+			if path != "" {
+				innerPath = path + "." + key
+			}
+			if !contains(n.ignoredFields, innerPath) {
+				result[key] = n.apply(innerPath, inner)
+			}
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typed))
+		for i, inner := range typed {
+			result[i] = n.apply(path, inner)
+		}
+		if contains(n.unorderedLists, path) {
+			sortByEncoding(result)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// sortByEncoding sorts the entries of a list by their json encoding, which is stable as objects are encoded with
+// sorted keys
+func sortByEncoding(values []interface{}) {
+
+	encoded := make(map[int]string, len(values))
+	for i, value := range values {
+		bytes, _ := json.Marshal(value)
+		encoded[i] = string(bytes)
+	}
+
+	indices := make([]int, len(values))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return encoded[indices[i]] < encoded[indices[j]]
+	})
+
+	sorted := make([]interface{}, len(values))
+	for i, index := range indices {
+		sorted[i] = values[index]
+	}
+	copy(values, sorted)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
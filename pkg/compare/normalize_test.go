@@ -0,0 +1,77 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package compare
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func normalizeForTest(t *testing.T, apiId string, payload string) string {
+
+	var content interface{}
+	err := json.Unmarshal([]byte(payload), &content)
+	assert.NilError(t, err)
+
+	normalized, err := json.Marshal(normalize(apiId, content, nil))
+	assert.NilError(t, err)
+
+	return string(normalized)
+}
+
+func TestNormalizeDropsIgnoredFields(t *testing.T) {
+
+	normalized := normalizeForTest(t, "management-zone", `{"id": "1", "metadata": {"clusterVersion": "1.208"}, "name": "zone",
+		"rules": [{"id": "rule-1", "type": "HOST"}]}`)
+
+	assert.Equal(t, normalized, `{"name":"zone","rules":[{"type":"HOST"}]}`)
+}
+
+func TestNormalizeSortsUnorderedLists(t *testing.T) {
+
+	first := normalizeForTest(t, "management-zone", `{"rules": [{"type": "SERVICE", "conditions": [{"key": "b"}, {"key": "a"}]}, {"type": "HOST"}]}`)
+	second := normalizeForTest(t, "management-zone", `{"rules": [{"type": "HOST"}, {"type": "SERVICE", "conditions": [{"key": "a"}, {"key": "b"}]}]}`)
+
+	assert.Equal(t, first, second)
+}
+
+func TestNormalizeKeepsOrderOfOtherLists(t *testing.T) {
+
+	normalized := normalizeForTest(t, "dashboard", `{"tiles": [{"name": "b"}, {"name": "a"}], "dashboardMetadata": {"name": "d", "owner": "me"}}`)
+
+	assert.Equal(t, normalized, `{"dashboardMetadata":{"name":"d"},"tiles":[{"name":"b"},{"name":"a"}]}`)
+}
+
+func TestNormalizeOfUnknownApiOnlyDropsDefaultFields(t *testing.T) {
+
+	normalized := normalizeForTest(t, "unknown", `{"id": "1", "entityId": "2", "rules": [2, 1], "nested": {"id": "3"}}`)
+
+	assert.Equal(t, normalized, `{"nested":{"id":"3"},"rules":[2,1]}`)
+}
+
+func TestDiffPayloadsIgnoresServerReordering(t *testing.T) {
+
+	differences, err := DiffPayloads("alerting-profile", []byte(`{"displayName": "profile", "rules": [{"severityLevel": "AVAILABILITY"}, {"severityLevel": "ERROR"}]}`),
+		[]byte(`{"displayName": "profile", "rules": [{"severityLevel": "ERROR"}, {"severityLevel": "AVAILABILITY"}]}`), nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(differences), 0)
+}
@@ -17,11 +17,14 @@
 package config
 
 import (
-	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
@@ -33,8 +36,15 @@ import (
 type Config interface {
 	GetConfigForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error)
 	IsSkipDeployment(environment environment.Environment) bool
+	IsProtected(environment environment.Environment) bool
+	GetOwner(environment environment.Environment) string
+	GetLabels(environment environment.Environment) []string
+	GetUpdateStrategy(environment environment.Environment) (string, error)
+	GetRequestOverrides(environment environment.Environment) (RequestOverrides, error)
+	GetPinnedRemote(environment environment.Environment) string
 	GetApi() api.Api
 	GetObjectNameForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error)
+	GetPropertyForEnvironment(environment environment.Environment, property string, dict map[string]api.DynatraceEntity) (string, error)
 	HasDependencyOn(config Config) bool
 	GetFilePath() string
 	GetFullQualifiedId() string
@@ -44,22 +54,84 @@ type Config interface {
 	GetProject() string
 	GetProperties() map[string]map[string]string
 	GetRequiredByConfigIdList() []string
+	GetUnresolvedReferences(dict map[string]api.DynatraceEntity) []Reference
+	WithNamePrefix(prefix string) Config
+	WithDefaults(defaults Defaults) Config
+	WithProjectRoot(root string) Config
+	ExplainProperty(environment environment.Environment, property string) []PropertySource
 	addToRequiredByConfigIdList(config string)
 }
 
+// Reference is a property of a config referencing the id or name of another config, e.g.
+// `- zone: "management-zone/zone.id"` in section `alert`
+type Reference struct {
+	Section  string
+	Property string
+	Value    string
+}
+
 var dependencySuffixes = []string{".id", ".name"}
 
 const skipConfigDeploymentParameter = "skipDeployment"
 
+// featureFlagParameter marks configs using experimental apis or schemas, which are only deployed to the environments
+// enabling the feature flag in the environments file
+const featureFlagParameter = "featureFlag"
+
+// protectedParameter marks configs whose objects must not be deleted, unless deleting protected objects is allowed
+const protectedParameter = "protected"
+
+// ownerParameter and labelsParameter hold the team owning a config and its comma separated labels, which allow
+// deployments to be restricted to the configs of a team
+const ownerParameter = "owner"
+const labelsParameter = "labels"
+
+// updateStrategyParameter defines how the object of a config is updated, UpdateReplace if it is not set
+const updateStrategyParameter = "updateStrategy"
+
+// UpdateReplace uploads the rendered template as it is, replacing the existing object. UpdateMerge applies the
+// rendered template as json merge patch onto the existing object, so fields not in the template are kept
+const (
+	UpdateReplace = "replace"
+	UpdateMerge   = "merge"
+)
+
+// timeoutParameter limits the time of each request deploying the config, e.g. of a giant dashboard or an extension
+const timeoutParameter = "requestTimeout"
+
+// retriesParameter is the number of times the requests deploying the config are retried
+const retriesParameter = "requestRetries"
+
+// pinnedRemoteParameter holds the hash of the object a config was downloaded from. The object is only updated as
+// long as it did not change in the environment since
+const pinnedRemoteParameter = "pinnedRemote"
+
+// RequestOverrides change how the requests deploying a single config are sent, instead of the settings of the run
+type RequestOverrides struct {
+	// Timeout of each request, the timeout of the run applies if it is zero
+	Timeout time.Duration
+
+	// Retries of conflicting updates and failed extension uploads, the retries of the run apply if it is nil
+	Retries *int
+}
+
+// IsEmpty checks if the overrides change nothing
+func (o RequestOverrides) IsEmpty() bool {
+	return o.Timeout == 0 && o.Retries == nil
+}
+
 type configImpl struct {
 	id                  string
 	project             string
 	properties          map[string]map[string]string
 	template            util.Template
+	variants            []templateVariant
 	api                 api.Api
 	objectName          string
 	fileName            string
 	requiredByConfigIds []string
+	defaults            Defaults
+	projectRoot         string
 }
 
 // configFactory is used to create new Configs - this is needed for testing purposes
@@ -80,11 +152,16 @@ func NewConfig(id string, project string, fileName string, properties map[string
 		return nil, fmt.Errorf("loading config %s failed with %s", project+string(os.PathSeparator)+id, err)
 	}
 
-	return newConfig(id, project, template, filterProperties(id, properties), api, fileName), nil
-}
+	properties = filterProperties(id, properties)
+
+	variants, err := loadTemplateVariants(project+string(os.PathSeparator)+id, properties[id])
+	if err != nil {
+		return nil, err
+	}
 
-func NewConfigForDelete(id string, fileName string, properties map[string]map[string]string, api api.Api) Config {
-	return newConfig(id, "", nil, filterProperties(id, properties), api, fileName)
+	config := newConfig(id, project, template, properties, api, fileName)
+	config.(*configImpl).variants = variants
+	return config, nil
 }
 
 func newConfig(id string, project string, template util.Template, properties map[string]map[string]string, api api.Api, fileName string) Config {
@@ -112,30 +189,159 @@ func filterProperties(id string, properties map[string]map[string]string) map[st
 	return result
 }
 
+// propertyLocation is a section of the config yaml and a key within it, at which a property can be defined
+type propertyLocation struct {
+	section string
+	key     string
+}
+
+// propertyLocations returns where a property is looked up for the given environment, from the most to the least
+// specific location. Properties can be overridden in an environment or group section of the config, or within the
+// config's section by suffixing the property with the environment (property.<environment>) or the group
+// (property.group.<group>). Environment overrides win over group overrides, which win over the plain property.
+// On the same level, a section wins over a suffixed property
+func (c *configImpl) propertyLocations(environment environment.Environment, property string) []propertyLocation {
+	return []propertyLocation{
+		{section: c.id + "." + environment.GetId(), key: property},
+		{section: c.id, key: property + "." + environment.GetId()},
+		{section: c.id + "." + environment.GetGroup(), key: property},
+		{section: c.id, key: property + ".group." + environment.GetGroup()},
+		{section: c.id, key: property},
+	}
+}
+
+// lookupProperty returns the value defined at the most specific location of the property, falling back to the
+// defaults of the config
+func (c *configImpl) lookupProperty(properties map[string]map[string]string, environment environment.Environment, property string) (value string, found bool) {
+
+	for _, source := range c.propertySources(properties, environment, property) {
+		if source.Defined {
+			return source.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// propertiesForEnvironment resolves all properties of the config for the given environment
+func (c *configImpl) propertiesForEnvironment(properties map[string]map[string]string, environment environment.Environment) map[string]string {
+
+	environmentSuffix := "." + environment.GetId()
+	groupSuffix := ".group." + environment.GetGroup()
+
+	names := make(map[string]bool)
+	for key := range properties[c.id] {
+		if strings.HasSuffix(key, groupSuffix) {
+			key = strings.TrimSuffix(key, groupSuffix)
+		} else if strings.HasSuffix(key, environmentSuffix) {
+			key = strings.TrimSuffix(key, environmentSuffix)
+		}
+		names[key] = true
+	}
+	for _, section := range []string{c.id + "." + environment.GetGroup(), c.id + environmentSuffix} {
+		for key := range properties[section] {
+			names[key] = true
+		}
+	}
+	for _, name := range c.defaults.names(environment) {
+		names[name] = true
+	}
+
+	result := make(map[string]string, len(names))
+	for name := range names {
+		result[name], _ = c.lookupProperty(properties, environment, name)
+	}
+
+	return result
+}
+
 func (c *configImpl) IsSkipDeployment(environment environment.Environment) bool {
-	environmentKey := c.id + "." + environment.GetId()
 
-	if properties, ok := c.properties[environmentKey]; ok {
-		if value, ok := properties[skipConfigDeploymentParameter]; ok {
-			return strings.EqualFold(value, "true")
+	if flag, ok := c.lookupProperty(c.properties, environment, featureFlagParameter); ok && !environment.HasFeatureFlag(flag) {
+		return true
+	}
+
+	if value, ok := c.lookupProperty(c.properties, environment, skipConfigDeploymentParameter); ok {
+		return strings.EqualFold(value, "true")
+	}
+
+	return false
+}
+
+// IsProtected returns whether the object the config is deployed to in the environment is protected from deletion
+func (c *configImpl) IsProtected(environment environment.Environment) bool {
+
+	if value, ok := c.lookupProperty(c.properties, environment, protectedParameter); ok {
+		return strings.EqualFold(value, "true")
+	}
+
+	return false
+}
+
+// GetOwner returns the owner of the config in the environment, or an empty string if it has no owner
+func (c *configImpl) GetOwner(environment environment.Environment) string {
+
+	owner, _ := c.lookupProperty(c.properties, environment, ownerParameter)
+	return strings.TrimSpace(owner)
+}
+
+// GetLabels returns the labels of the config in the environment
+func (c *configImpl) GetLabels(environment environment.Environment) []string {
+
+	value, _ := c.lookupProperty(c.properties, environment, labelsParameter)
+
+	labels := make([]string, 0)
+	for _, label := range strings.Split(value, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
 		}
 	}
+	return labels
+}
+
+// GetUpdateStrategy returns how the object of the config is updated in the environment, UpdateReplace or UpdateMerge
+func (c *configImpl) GetUpdateStrategy(environment environment.Environment) (string, error) {
+
+	value, found := c.lookupProperty(c.properties, environment, updateStrategyParameter)
+	if !found {
+		return UpdateReplace, nil
+	}
 
-	environmentGroupKey := c.id + "." + environment.GetGroup()
+	switch strategy := strings.ToLower(strings.TrimSpace(value)); strategy {
+	case UpdateReplace, UpdateMerge:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid %s %s of config %s, expected %s or %s", updateStrategyParameter, value, c.GetFilePath(), UpdateReplace, UpdateMerge)
+	}
+}
 
-	if properties, ok := c.properties[environmentGroupKey]; ok {
-		if value, ok := properties[skipConfigDeploymentParameter]; ok {
-			return strings.EqualFold(value, "true")
+// GetRequestOverrides returns the timeout and retries the requests deploying the config to the environment use
+func (c *configImpl) GetRequestOverrides(environment environment.Environment) (overrides RequestOverrides, err error) {
+
+	if value, found := c.lookupProperty(c.properties, environment, timeoutParameter); found {
+		overrides.Timeout, err = time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || overrides.Timeout <= 0 {
+			return overrides, fmt.Errorf("invalid %s %s of config %s, expected a positive duration like 90s or 5m", timeoutParameter, value, c.GetFilePath())
 		}
 	}
 
-	if properties, ok := c.properties[c.id]; ok {
-		if value, ok := properties[skipConfigDeploymentParameter]; ok {
-			return strings.EqualFold(value, "true")
+	if value, found := c.lookupProperty(c.properties, environment, retriesParameter); found {
+		retries, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || retries < 0 {
+			return overrides, fmt.Errorf("invalid %s %s of config %s, expected a number of at least 0", retriesParameter, value, c.GetFilePath())
 		}
+		overrides.Retries = &retries
 	}
 
-	return false
+	return overrides, nil
+}
+
+// GetPinnedRemote returns the hash the object of the config in the environment is pinned to, or an empty string
+// if it is not pinned
+func (c *configImpl) GetPinnedRemote(environment environment.Environment) string {
+
+	pin, _ := c.lookupProperty(c.properties, environment, pinnedRemoteParameter)
+	return strings.TrimSpace(pin)
 }
 
 func (c *configImpl) GetConfigForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error) {
@@ -147,58 +353,91 @@ func (c *configImpl) GetConfigForEnvironment(environment environment.Environment
 	}
 
 	if len(filtered) == 0 {
-		json, err := c.template.ExecuteTemplate(map[string]string{})
+		json, err := c.templateFor(environment).ExecuteTemplateWithContext(map[string]string{}, c.templateContext(environment))
 		return json, err
 	}
 
-	environmentGroupKey := c.id + "." + environment.GetGroup()
-	environmentKey := c.id + "." + environment.GetId()
+	json, err := c.templateFor(environment).ExecuteTemplateWithContext(c.propertiesForEnvironment(filtered, environment), c.templateContext(environment))
+	if err != nil {
+		return "", err
+	}
 
-	// collect all group and environment properties
-	// environment override group properties
-	for _, environment := range []string{environmentGroupKey, environmentKey} {
-		filteredForEnvironment := filterProperties(environment, c.properties)
-		if len(filteredForEnvironment) > 0 {
-			for key, value := range filteredForEnvironment[environment] {
-				_, ok := filtered[c.id]
-				if !ok {
-					filtered[c.id] = make(map[string]string)
-				}
+	json = strings.ReplaceAll(json, "&#34;", "\"")
 
-				filtered[c.id][key] = value
-			}
-		}
+	if c.api.GetId() == dashboardApi {
+		return c.withDashboardMetadata(json, environment)
 	}
 
-	json, err := c.template.ExecuteTemplate(filtered[c.id])
-	if err != nil {
-		return "", err
+	return json, nil
+}
+
+// templateContext returns the context the config's template is rendered in for the environment
+func (c *configImpl) templateContext(environment environment.Environment) util.TemplateContext {
+	return util.TemplateContext{
+		Environment: environmentFacts(environment),
+		Coordinates: c.coordinates(),
 	}
+}
+
+// coordinates returns the full qualified id of the config relative to the projects root, as used to derive external
+// ids when deploying. They use slashes on all platforms, so that external ids do not depend on the platform monaco
+// runs on
+func (c *configImpl) coordinates() string {
 
-	return strings.ReplaceAll(json, "&#34;", "\""), nil
+	coordinates := c.GetFullQualifiedId()
+	if c.projectRoot != "" {
+		coordinates = strings.TrimPrefix(coordinates, c.projectRoot+string(os.PathSeparator))
+	}
+	return filepath.ToSlash(coordinates)
 }
 
-func (c *configImpl) GetObjectNameForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error) {
-	environmentKey := c.id + "." + environment.GetId()
-	environmentGroupKey := c.id + "." + environment.GetGroup()
-	name := c.properties[environmentKey]["name"]
-	// assign group value if exists
-	if name == "" {
-		name = c.properties[environmentGroupKey]["name"]
+// WithProjectRoot returns a copy of the config, whose coordinates are relative to the given projects root
+func (c *configImpl) WithProjectRoot(root string) Config {
+
+	copied := *c
+	copied.projectRoot = strings.TrimSuffix(root, string(os.PathSeparator))
+	return &copied
+}
+
+// environmentFacts returns the facts about the environment a config is deployed to, which templates use
+// as {{ .Environment.XXX }}, e.g. to link to the environment in a dashboard
+func environmentFacts(environment environment.Environment) map[string]interface{} {
+	return map[string]interface{}{
+		"Id":    environment.GetId(),
+		"Name":  environment.GetName(),
+		"Url":   strings.TrimSuffix(environment.GetEnvironmentUrl(), "/"),
+		"Group": environment.GetGroup(),
+		"Tags":  environment.GetTags(),
 	}
-	// assign default value
-	if name == "" {
-		name = c.properties[c.id]["name"]
+}
+
+func (c *configImpl) GetObjectNameForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error) {
+	name, err := c.GetPropertyForEnvironment(environment, "name", dict)
+	if err != nil {
+		return "", err
 	}
 	if name == "" {
 		return "", fmt.Errorf("could not find name property in config %s, please make sure `name` is defined", c.GetFullQualifiedId())
 	}
-	if isDependency(name) {
-		return c.parseDependency(name, dict)
-	}
 	return name, nil
 }
 
+// GetPropertyForEnvironment returns the value of the given property, preferring environment over group specific
+// values (see propertyLocations) and falling back to the defaults. References of the config to other configs are
+// resolved. Returns an empty string, if the property is not defined
+func (c *configImpl) GetPropertyForEnvironment(environment environment.Environment, property string, dict map[string]api.DynatraceEntity) (string, error) {
+	for _, source := range c.propertySources(c.properties, environment, property) {
+		if source.Value == "" {
+			continue
+		}
+		if source.Level == ConfigLevel && isDependency(source.Value) {
+			return c.parseDependency(source.Value, dict)
+		}
+		return source.Value, nil
+	}
+	return "", nil
+}
+
 func copyProperties(original map[string]map[string]string) map[string]map[string]string {
 
 	copies := make(map[string]map[string]string)
@@ -227,6 +466,16 @@ func (c *configImpl) replaceDependencies(data map[string]map[string]string, dict
 	return data, nil
 }
 
+// UnresolvedReferenceError is returned if a config references a config which was not deployed before it, e.g. as it
+// does not exist
+type UnresolvedReferenceError struct {
+	Id string
+}
+
+func (e UnresolvedReferenceError) Error() string {
+	return "Id '" + e.Id + "' was not available. Please make sure the reference exists."
+}
+
 func (c *configImpl) parseDependency(dependency string, dict map[string]api.DynatraceEntity) (string, error) {
 
 	// in case of an absolute path within the dependency:
@@ -241,7 +490,10 @@ func (c *configImpl) parseDependency(dependency string, dict map[string]api.Dyna
 	}
 	dtObject, ok := dict[id]
 	if !ok {
-		return "", errors.New("Id '" + id + "' was not available. Please make sure the reference exists.")
+		dtObject, ok = c.lookupRelativeDependency(id, dict)
+	}
+	if !ok {
+		return "", UnresolvedReferenceError{Id: id}
 	}
 
 	switch access {
@@ -254,6 +506,28 @@ func (c *configImpl) parseDependency(dependency string, dict map[string]api.Dyna
 	}
 }
 
+// lookupRelativeDependency resolves a dependency on a config of the same project, which is given relative to the
+// project (api/config). As the keys of dict do not contain the projects root folder, the longest key matching the
+// end of the project path joined with the dependency is used
+func (c *configImpl) lookupRelativeDependency(id string, dict map[string]api.DynatraceEntity) (api.DynatraceEntity, bool) {
+
+	if len(strings.Split(id, string(os.PathSeparator))) >= 3 {
+		return api.DynatraceEntity{}, false
+	}
+
+	path := c.GetProject() + string(os.PathSeparator) + id
+
+	var found api.DynatraceEntity
+	longest := -1
+	for key, entity := range dict {
+		if (key == path || strings.HasSuffix(path, string(os.PathSeparator)+key)) && len(key) > longest {
+			found, longest = entity, len(key)
+		}
+	}
+
+	return found, longest >= 0
+}
+
 func isDependency(property string) bool {
 	for _, suffix := range dependencySuffixes {
 		if strings.HasSuffix(property, suffix) {
@@ -306,6 +580,53 @@ func (c *configImpl) GetProperties() map[string]map[string]string {
 	return c.properties
 }
 
+// WithNamePrefix returns a copy of the config, whose name is prefixed in all environments and groups, so that its
+// objects do not collide with the ones of the config. Names referencing other configs are not prefixed, as they
+// resolve to the names of the objects deployed for them
+func (c *configImpl) WithNamePrefix(prefix string) Config {
+
+	prefixed := *c
+	prefixed.properties = copyProperties(c.properties)
+	prefixed.requiredByConfigIds = append([]string{}, c.requiredByConfigIds...)
+
+	for _, properties := range prefixed.properties {
+		for key, value := range properties {
+			if (key == "name" || strings.HasPrefix(key, "name.")) && !isDependency(value) {
+				properties[key] = prefix + value
+			}
+		}
+	}
+
+	return &prefixed
+}
+
+// GetUnresolvedReferences returns the references of the config's properties to configs which are not contained in
+// dict, sorted by section and property. References are resolved like during deployments, so that passing the
+// configs to deploy as dict finds dangling references without deploying
+func (c *configImpl) GetUnresolvedReferences(dict map[string]api.DynatraceEntity) []Reference {
+
+	unresolved := make([]Reference, 0)
+	for section, properties := range c.properties {
+		for property, value := range properties {
+			if !isDependency(value) {
+				continue
+			}
+			if _, err := c.parseDependency(value, dict); err != nil {
+				unresolved = append(unresolved, Reference{Section: section, Property: property, Value: value})
+			}
+		}
+	}
+
+	sort.Slice(unresolved, func(i, j int) bool {
+		if unresolved[i].Section != unresolved[j].Section {
+			return unresolved[i].Section < unresolved[j].Section
+		}
+		return unresolved[i].Property < unresolved[j].Property
+	})
+
+	return unresolved
+}
+
 // HasDependencyOn checks if one config depends on the given parameter config
 // Having a dependency means, that the config having the dependency needs to be applied AFTER the config it depends on
 func (c *configImpl) HasDependencyOn(config Config) bool {
@@ -331,9 +652,9 @@ func (c *configImpl) HasDependencyOn(config Config) bool {
 					if valueString == strings.Join([]string{config.GetType(), config.GetId()}, string(os.PathSeparator)) {
 						config.addToRequiredByConfigIdList(c.GetFullQualifiedId())
 						return true
-					} else {
-						return false
 					}
+					// other properties may still reference the config
+					continue
 				}
 
 				// generate configuration path of configuration to be checked for dependency
@@ -63,6 +63,92 @@ func (mr *MockConfigMockRecorder) IsSkipDeployment(environment interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsSkipDeployment", reflect.TypeOf((*MockConfig)(nil).IsSkipDeployment), environment)
 }
 
+// IsProtected mocks base method
+func (m *MockConfig) IsProtected(environment environment.Environment) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsProtected", environment)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsProtected indicates an expected call of IsProtected
+func (mr *MockConfigMockRecorder) IsProtected(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsProtected", reflect.TypeOf((*MockConfig)(nil).IsProtected), environment)
+}
+
+// GetOwner mocks base method
+func (m *MockConfig) GetOwner(environment environment.Environment) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOwner", environment)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetOwner indicates an expected call of GetOwner
+func (mr *MockConfigMockRecorder) GetOwner(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOwner", reflect.TypeOf((*MockConfig)(nil).GetOwner), environment)
+}
+
+// GetUpdateStrategy mocks base method
+func (m *MockConfig) GetUpdateStrategy(environment environment.Environment) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUpdateStrategy", environment)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUpdateStrategy indicates an expected call of GetUpdateStrategy
+func (mr *MockConfigMockRecorder) GetUpdateStrategy(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUpdateStrategy", reflect.TypeOf((*MockConfig)(nil).GetUpdateStrategy), environment)
+}
+
+// GetLabels mocks base method
+func (m *MockConfig) GetLabels(environment environment.Environment) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLabels", environment)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetLabels indicates an expected call of GetLabels
+func (mr *MockConfigMockRecorder) GetLabels(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLabels", reflect.TypeOf((*MockConfig)(nil).GetLabels), environment)
+}
+
+// GetRequestOverrides mocks base method
+func (m *MockConfig) GetRequestOverrides(environment environment.Environment) (RequestOverrides, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRequestOverrides", environment)
+	ret0, _ := ret[0].(RequestOverrides)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRequestOverrides indicates an expected call of GetRequestOverrides
+func (mr *MockConfigMockRecorder) GetRequestOverrides(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequestOverrides", reflect.TypeOf((*MockConfig)(nil).GetRequestOverrides), environment)
+}
+
+// GetPinnedRemote mocks base method
+func (m *MockConfig) GetPinnedRemote(environment environment.Environment) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPinnedRemote", environment)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetPinnedRemote indicates an expected call of GetPinnedRemote
+func (mr *MockConfigMockRecorder) GetPinnedRemote(environment interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPinnedRemote", reflect.TypeOf((*MockConfig)(nil).GetPinnedRemote), environment)
+}
+
 // GetApi mocks base method
 func (m *MockConfig) GetApi() api.Api {
 	m.ctrl.T.Helper()
@@ -92,6 +178,21 @@ func (mr *MockConfigMockRecorder) GetObjectNameForEnvironment(environment, dict
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetObjectNameForEnvironment", reflect.TypeOf((*MockConfig)(nil).GetObjectNameForEnvironment), environment, dict)
 }
 
+// GetPropertyForEnvironment mocks base method
+func (m *MockConfig) GetPropertyForEnvironment(environment environment.Environment, property string, dict map[string]api.DynatraceEntity) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPropertyForEnvironment", environment, property, dict)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPropertyForEnvironment indicates an expected call of GetPropertyForEnvironment
+func (mr *MockConfigMockRecorder) GetPropertyForEnvironment(environment, property, dict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPropertyForEnvironment", reflect.TypeOf((*MockConfig)(nil).GetPropertyForEnvironment), environment, property, dict)
+}
+
 // HasDependencyOn mocks base method
 func (m *MockConfig) HasDependencyOn(config Config) bool {
 	m.ctrl.T.Helper()
@@ -218,6 +319,76 @@ func (mr *MockConfigMockRecorder) GetRequiredByConfigIdList() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRequiredByConfigIdList", reflect.TypeOf((*MockConfig)(nil).GetRequiredByConfigIdList))
 }
 
+// GetUnresolvedReferences mocks base method
+func (m *MockConfig) GetUnresolvedReferences(dict map[string]api.DynatraceEntity) []Reference {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnresolvedReferences", dict)
+	ret0, _ := ret[0].([]Reference)
+	return ret0
+}
+
+// GetUnresolvedReferences indicates an expected call of GetUnresolvedReferences
+func (mr *MockConfigMockRecorder) GetUnresolvedReferences(dict interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnresolvedReferences", reflect.TypeOf((*MockConfig)(nil).GetUnresolvedReferences), dict)
+}
+
+// WithNamePrefix mocks base method
+func (m *MockConfig) WithNamePrefix(prefix string) Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithNamePrefix", prefix)
+	ret0, _ := ret[0].(Config)
+	return ret0
+}
+
+// WithNamePrefix indicates an expected call of WithNamePrefix
+func (mr *MockConfigMockRecorder) WithNamePrefix(prefix interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithNamePrefix", reflect.TypeOf((*MockConfig)(nil).WithNamePrefix), prefix)
+}
+
+// WithDefaults mocks base method
+func (m *MockConfig) WithDefaults(defaults Defaults) Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithDefaults", defaults)
+	ret0, _ := ret[0].(Config)
+	return ret0
+}
+
+// WithDefaults indicates an expected call of WithDefaults
+func (mr *MockConfigMockRecorder) WithDefaults(defaults interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithDefaults", reflect.TypeOf((*MockConfig)(nil).WithDefaults), defaults)
+}
+
+// WithProjectRoot mocks base method
+func (m *MockConfig) WithProjectRoot(root string) Config {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithProjectRoot", root)
+	ret0, _ := ret[0].(Config)
+	return ret0
+}
+
+// WithProjectRoot indicates an expected call of WithProjectRoot
+func (mr *MockConfigMockRecorder) WithProjectRoot(root interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithProjectRoot", reflect.TypeOf((*MockConfig)(nil).WithProjectRoot), root)
+}
+
+// ExplainProperty mocks base method
+func (m *MockConfig) ExplainProperty(environment environment.Environment, property string) []PropertySource {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExplainProperty", environment, property)
+	ret0, _ := ret[0].([]PropertySource)
+	return ret0
+}
+
+// ExplainProperty indicates an expected call of ExplainProperty
+func (mr *MockConfigMockRecorder) ExplainProperty(environment, property interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExplainProperty", reflect.TypeOf((*MockConfig)(nil).ExplainProperty), environment, property)
+}
+
 // addToRequiredByConfigIdList mocks base method
 func (m *MockConfig) addToRequiredByConfigIdList(config string) {
 	m.ctrl.T.Helper()
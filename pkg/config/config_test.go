@@ -23,6 +23,7 @@ import (
 	"os"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
@@ -232,6 +233,72 @@ func TestGetConfigWithMergingGroupAndEnvironmentOverrides(t *testing.T) {
 	assert.Equal(t, "Follow the brown dog", productionResult)
 }
 
+// Test overriding single properties within the config section
+// property.<environment> overrides property.group.<group>, which overrides the plain property
+func TestGetConfigWithPropertyOverrides(t *testing.T) {
+
+	m := map[string]map[string]string{
+		"test": {
+			"color":                  "white",
+			"color.group.production": "brown",
+			"animalType":             "rabbit",
+			"animalType.development": "cow",
+		},
+	}
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	devResult, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "Follow the white cow", devResult)
+
+	productionResult, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "Follow the brown rabbit", productionResult)
+
+	m["test"]["color.prod-environment"] = "black"
+	productionResult, err = config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "Follow the black rabbit", productionResult)
+}
+
+// Test precedence of property overrides and override sections
+// on the same level, sections win over suffixed properties
+func TestPropertyOverridesPrecedence(t *testing.T) {
+
+	m := map[string]map[string]string{
+		"test": {
+			"name":                        "default",
+			"name.group.production":       "group property",
+			"name.prod-environment":       "environment property",
+			skipConfigDeploymentParameter: "false",
+		},
+		"test.production": {
+			"name": "group section",
+		},
+	}
+	config := newConfig("test", "testproject", getTestTemplate(t), m, testManagementZoneApi, "")
+
+	name, err := config.GetObjectNameForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "environment property", name)
+
+	m["test.prod-environment"] = map[string]string{"name": "environment section"}
+	name, err = config.GetObjectNameForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "environment section", name)
+
+	delete(m, "test.prod-environment")
+	delete(m["test"], "name.prod-environment")
+	name, err = config.GetObjectNameForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, "group section", name)
+
+	m["test"][skipConfigDeploymentParameter+".group.production"] = "true"
+	assert.Equal(t, true, config.IsSkipDeployment(testProductionEnvironment))
+	assert.Equal(t, false, config.IsSkipDeployment(testDevEnvironment))
+}
+
 func TestSkipConfigDeployment(t *testing.T) {
 
 	m := getTestPropertiesWithGroupAndEnvironment()
@@ -259,6 +326,137 @@ func TestSkipConfigDeployment(t *testing.T) {
 	assert.Equal(t, false, skipDeployment)
 }
 
+// featureFlagEnvironment enables the given feature flags
+type featureFlagEnvironment struct {
+	environment.Environment
+	flags []string
+}
+
+func (e featureFlagEnvironment) HasFeatureFlag(flag string) bool {
+	for _, enabled := range e.flags {
+		if enabled == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSkipConfigDeploymentWithoutFeatureFlag(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	delete(m["test.prod-environment"], skipConfigDeploymentParameter)
+	m["test"][featureFlagParameter] = "settings-preview"
+
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	assert.Equal(t, true, config.IsSkipDeployment(testDevEnvironment))
+	assert.Equal(t, false, config.IsSkipDeployment(featureFlagEnvironment{testDevEnvironment, []string{"settings-preview"}}))
+
+	m["test.development"] = map[string]string{skipConfigDeploymentParameter: "true"}
+	assert.Equal(t, true, config.IsSkipDeployment(featureFlagEnvironment{testDevEnvironment, []string{"settings-preview"}}))
+}
+
+func TestIsProtected(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	assert.Equal(t, false, config.IsProtected(testProductionEnvironment))
+
+	m["test"][protectedParameter] = "true"
+	assert.Equal(t, true, config.IsProtected(testProductionEnvironment))
+	assert.Equal(t, true, config.IsProtected(testDevEnvironment))
+
+	m["test.production"][protectedParameter] = "false"
+	assert.Equal(t, false, config.IsProtected(testProductionEnvironment))
+	assert.Equal(t, true, config.IsProtected(testDevEnvironment))
+}
+
+func TestGetOwnerAndLabels(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	assert.Equal(t, "", config.GetOwner(testProductionEnvironment))
+	assert.DeepEqual(t, []string{}, config.GetLabels(testProductionEnvironment))
+
+	m["test"][ownerParameter] = "team-a"
+	m["test"][labelsParameter] = "payments, tier-1,"
+	m["test.production"][ownerParameter] = "team-b"
+
+	assert.Equal(t, "team-b", config.GetOwner(testProductionEnvironment))
+	assert.Equal(t, "team-a", config.GetOwner(testDevEnvironment))
+	assert.DeepEqual(t, []string{"payments", "tier-1"}, config.GetLabels(testProductionEnvironment))
+}
+
+func TestGetPinnedRemote(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	assert.Equal(t, "", config.GetPinnedRemote(testProductionEnvironment))
+
+	m["test.production"][pinnedRemoteParameter] = " 1a2b "
+
+	assert.Equal(t, "1a2b", config.GetPinnedRemote(testProductionEnvironment))
+	assert.Equal(t, "", config.GetPinnedRemote(testDevEnvironment))
+}
+
+func TestGetUpdateStrategy(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	strategy, err := config.GetUpdateStrategy(testProductionEnvironment)
+	assert.NilError(t, err)
+	assert.Equal(t, UpdateReplace, strategy)
+
+	m["test.production"][updateStrategyParameter] = "Merge"
+	strategy, err = config.GetUpdateStrategy(testProductionEnvironment)
+	assert.NilError(t, err)
+	assert.Equal(t, UpdateMerge, strategy)
+
+	m["test"][updateStrategyParameter] = "patch"
+	_, err = config.GetUpdateStrategy(testDevEnvironment)
+	assert.ErrorContains(t, err, "invalid updateStrategy patch")
+}
+
+func TestGetRequestOverrides(t *testing.T) {
+
+	m := getTestPropertiesWithGroupAndEnvironment()
+	templ := getTestTemplate(t)
+	config := newConfig("test", "testproject", templ, m, testManagementZoneApi, "")
+
+	overrides, err := config.GetRequestOverrides(testProductionEnvironment)
+	assert.NilError(t, err)
+	assert.Assert(t, overrides.IsEmpty())
+
+	m["test"][timeoutParameter] = "2m"
+	m["test.production"][retriesParameter] = "5"
+	overrides, err = config.GetRequestOverrides(testProductionEnvironment)
+	assert.NilError(t, err)
+	assert.Equal(t, overrides.Timeout, 2*time.Minute)
+	assert.Equal(t, *overrides.Retries, 5)
+
+	overrides, err = config.GetRequestOverrides(testDevEnvironment)
+	assert.NilError(t, err)
+	assert.Assert(t, overrides.Retries == nil)
+
+	m["test"][timeoutParameter] = "forever"
+	_, err = config.GetRequestOverrides(testDevEnvironment)
+	assert.ErrorContains(t, err, "invalid requestTimeout forever")
+
+	m["test"][timeoutParameter] = "1m"
+	m["test"][retriesParameter] = "-1"
+	_, err = config.GetRequestOverrides(testDevEnvironment)
+	assert.ErrorContains(t, err, "invalid requestRetries -1")
+}
+
 // Test getting object name for environment
 // considering environment and group overrides
 func TestGetObjectNameForEnvironment(t *testing.T) {
@@ -387,6 +585,26 @@ func TestHasDependencyCheck(t *testing.T) {
 	assert.Equal(t, true, config.HasDependencyOn(otherConfig))
 }
 
+func TestHasDependencyCheckWithMultipleRelativeDependencies(t *testing.T) {
+	prop := make(map[string]map[string]string)
+	prop["test"] = make(map[string]string)
+	prop["test"]["name"] = "A name"
+	temp, e := util.NewTemplateFromString("test", "{{.name}}")
+	assert.NilError(t, e)
+
+	otherConfig := newConfig("other", "testproject", temp, make(map[string]map[string]string), testManagementZoneApi, "other.json")
+
+	// properties are stored in a map, so each run checks them in a different order
+	for i := 0; i < 20; i++ {
+		prop["test"][fmt.Sprintf("zone%d", i)] = util.ReplacePathSeparators(fmt.Sprintf("management-zone/zone%d.id", i))
+	}
+	prop["test"]["other"] = util.ReplacePathSeparators("management-zone/other.id")
+
+	config := newConfig("test", "testproject", temp, prop, testManagementZoneApi, "test.json")
+
+	assert.Equal(t, true, config.HasDependencyOn(otherConfig))
+}
+
 func TestMeIdRegex(t *testing.T) {
 	assert.Check(t, isMeId("HOST_GROUP-95BEC188F318D09C"))
 	assert.Check(t, isMeId("APPLICATION-95BEC188F318D09C"))
@@ -466,6 +684,51 @@ func TestParseDependencyWithRelativePath(t *testing.T) {
 	assert.Equal(t, "zone", managementZoneId)
 }
 
+func TestParseDependencyWithinSameProject(t *testing.T) {
+
+	prop := make(map[string]map[string]string)
+	templ := getTestTemplate(t)
+
+	config := createConfigForTest("test", util.ReplacePathSeparators("projects/infrastructure"), templ, prop, testManagementZoneApi, "")
+
+	dict := make(map[string]api.DynatraceEntity)
+	dict[util.ReplacePathSeparators("infrastructure/management-zone/zone")] = api.DynatraceEntity{Id: "zone"}
+	dict[util.ReplacePathSeparators("other/management-zone/zone")] = api.DynatraceEntity{Id: "other-zone"}
+
+	managementZoneId, err := config.parseDependency(util.ReplacePathSeparators("management-zone/zone.id"), dict)
+	assert.NilError(t, err)
+	assert.Equal(t, "zone", managementZoneId)
+
+	_, err = config.parseDependency(util.ReplacePathSeparators("management-zone/unknown.id"), dict)
+	assert.ErrorContains(t, err, "was not available")
+	assert.Equal(t, err.(UnresolvedReferenceError).Id, util.ReplacePathSeparators("management-zone/unknown"))
+}
+
+func TestGetUnresolvedReferences(t *testing.T) {
+
+	prop := map[string]map[string]string{
+		"test": {
+			"name": "Test",
+			"zone": util.ReplacePathSeparators("management-zone/zone.id"),
+			"tag":  util.ReplacePathSeparators("management-zone/unknown.name"),
+		},
+		"test.dev": {
+			"zone": util.ReplacePathSeparators("/other/management-zone/missing.id"),
+		},
+	}
+	templ := getTestTemplate(t)
+
+	config := createConfigForTest("test", util.ReplacePathSeparators("projects/infrastructure"), templ, prop, testManagementZoneApi, "")
+
+	dict := make(map[string]api.DynatraceEntity)
+	dict[util.ReplacePathSeparators("infrastructure/management-zone/zone")] = api.DynatraceEntity{Id: "zone"}
+
+	assert.DeepEqual(t, config.GetUnresolvedReferences(dict), []Reference{
+		{Section: "test", Property: "tag", Value: util.ReplacePathSeparators("management-zone/unknown.name")},
+		{Section: "test.dev", Property: "zone", Value: util.ReplacePathSeparators("/other/management-zone/missing.id")},
+	})
+}
+
 func TestGetConfigStringWithEnvVar(t *testing.T) {
 
 	templ := getTestTemplateWithEnvVars(t)
@@ -492,3 +755,89 @@ func TestGetConfigStringWithEnvVarLeadsToErrorIfEnvVarNotPresent(t *testing.T) {
 
 	assert.ErrorContains(t, err, "map has no entry for key \"ANIMAL\"")
 }
+
+func TestGetConfigStringWithEnvironmentFacts(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", `{"color": "{{ .color }}", "link": "{{ .Environment.Url }}/#dashboards", `+
+		`"title": "{{ .Environment.Name }} ({{ .Environment.Id }}, {{ .Environment.Group }})"}`)
+	assert.NilError(t, err)
+
+	config := newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
+
+	result, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"color": "brown", "link": "https://url/to/production/environment/#dashboards", "title": "prod-environment (prod-environment, production)"}`)
+
+	templ, err = util.NewTemplateFromString("test", `{"unknown": "{{ .Environment.Unknown }}"}`)
+	assert.NilError(t, err)
+
+	config = newConfig("test", "testproject", templ, getTestProperties(), testManagementZoneApi, "")
+	_, err = config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.ErrorContains(t, err, "Unknown")
+}
+
+func TestGetConfigStringWithExternalId(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", `{"externalId": "{{ externalId }}"}`)
+	assert.NilError(t, err)
+
+	config := newConfig("test", util.ReplacePathSeparators("projects/testproject"), templ, getTestProperties(), testManagementZoneApi, "")
+	config = config.WithProjectRoot("projects")
+
+	// the id is derived from the coordinates relative to the projects root, like the ids of deployed objects
+	result, err := config.GetConfigForEnvironment(testProductionEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"externalId": "`+util.DeterministicUuid("testproject/management-zone/test")+`"}`)
+
+	// the id is the same in every environment
+	otherResult, err := config.GetConfigForEnvironment(testDevEnvironment, make(map[string]api.DynatraceEntity))
+	assert.NilError(t, err)
+	assert.Equal(t, otherResult, result)
+}
+
+func TestWithNamePrefix(t *testing.T) {
+
+	properties := map[string]map[string]string{
+		"zone": {
+			"name":           "zone",
+			"name.hardening": "hardened zone",
+			"color":          "red",
+		},
+		"zone.production": {
+			"name": "production zone",
+		},
+	}
+	template, err := util.NewTemplateFromString("zone", `{"name": "{{.name}}", "color": "{{.color}}"}`)
+	assert.NilError(t, err)
+
+	original := newConfig("zone", "project", template, properties, testManagementZoneApi, "zone.json")
+	prefixed := original.WithNamePrefix("[pr-1] ")
+
+	for env, expected := range map[environment.Environment]string{
+		testDevEnvironment:        "[pr-1] zone",
+		testHardeningEnvironment:  "[pr-1] hardened zone",
+		testProductionEnvironment: "[pr-1] production zone",
+	} {
+		name, err := prefixed.GetObjectNameForEnvironment(env, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, name, expected)
+	}
+
+	json, err := prefixed.GetConfigForEnvironment(testDevEnvironment, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, json, `{"name": "[pr-1] zone", "color": "red"}`)
+
+	name, err := original.GetObjectNameForEnvironment(testDevEnvironment, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, name, "zone")
+}
+
+func TestWithNamePrefixKeepsReferencedNames(t *testing.T) {
+
+	properties := map[string]map[string]string{
+		"profile": {"name": "/project/management-zone/zone.name"},
+	}
+	prefixed := newConfig("profile", "project", nil, properties, testManagementZoneApi, "profile.json").WithNamePrefix("[pr-1] ")
+
+	assert.Equal(t, prefixed.GetProperties()["profile"]["name"], "/project/management-zone/zone.name")
+}
@@ -0,0 +1,101 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+)
+
+const dashboardApi = "dashboard"
+
+// dashboardOwnerParameter, dashboardPresetParameter and dashboardTagsParameter define the owner, preset flag and comma
+// separated tags of the metadata of a dashboard in yaml, so that they can differ per environment
+const (
+	dashboardOwnerParameter  = "dashboardOwner"
+	dashboardPresetParameter = "dashboardPreset"
+	dashboardTagsParameter   = "dashboardTags"
+)
+
+// withDashboardMetadata sets the metadata fields the config defines for the environment in the rendered dashboard.
+// Fields not defined by the config are left as the template renders them
+func (c *configImpl) withDashboardMetadata(rendered string, environment environment.Environment) (string, error) {
+
+	fields := make(map[string]interface{})
+
+	if owner, found := c.lookupProperty(c.properties, environment, dashboardOwnerParameter); found {
+		fields["owner"] = strings.TrimSpace(owner)
+	}
+
+	if value, found := c.lookupProperty(c.properties, environment, dashboardPresetParameter); found {
+		preset, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return "", fmt.Errorf("%s of %s must be true or false, but is '%s'", dashboardPresetParameter, c.GetFilePath(), value)
+		}
+		fields["preset"] = preset
+	}
+
+	if value, found := c.lookupProperty(c.properties, environment, dashboardTagsParameter); found {
+		tags := make([]string, 0)
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+		fields["tags"] = tags
+	}
+
+	if len(fields) == 0 {
+		return rendered, nil
+	}
+
+	// numbers are kept as they are rendered and html characters are not escaped, so that only the metadata changes
+	var dashboard map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(rendered))
+	decoder.UseNumber()
+	err := decoder.Decode(&dashboard)
+	if err != nil {
+		return "", fmt.Errorf("dashboard metadata of %s can not be set, as it is not a valid json object: %s", c.GetFilePath(), err)
+	}
+
+	metadata, isObject := dashboard["dashboardMetadata"].(map[string]interface{})
+	if !isObject {
+		metadata = make(map[string]interface{})
+		dashboard["dashboardMetadata"] = metadata
+	}
+
+	for field, value := range fields {
+		metadata[field] = value
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err = encoder.Encode(dashboard)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
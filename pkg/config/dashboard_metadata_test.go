@@ -0,0 +1,109 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+var testDashboardApi = api.NewApi("dashboard", "/api/config/v1/dashboards")
+
+const testDashboardTemplate = `{"dashboardMetadata": {"name": "{{ .name }}", "owner": "template-owner"}, "tiles": []}`
+
+func TestDashboardMetadataIsSetPerEnvironment(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", testDashboardTemplate)
+	assert.NilError(t, err)
+
+	properties := map[string]map[string]string{
+		"test": {
+			"name":                   "Overview",
+			dashboardOwnerParameter:  "sre@example.com",
+			dashboardPresetParameter: "true",
+			dashboardTagsParameter:   "sre, tier-1",
+		},
+		"test.prod-environment": {
+			dashboardOwnerParameter: "production-sre@example.com",
+		},
+	}
+	config := newConfig("test", "testproject", templ, properties, testDashboardApi, "")
+
+	rendered, err := config.GetConfigForEnvironment(testProductionEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, `{
+  "dashboardMetadata": {
+    "name": "Overview",
+    "owner": "production-sre@example.com",
+    "preset": true,
+    "tags": [
+      "sre",
+      "tier-1"
+    ]
+  },
+  "tiles": []
+}`)
+
+	rendered, err = config.GetConfigForEnvironment(testDevEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(rendered, `"owner": "sre@example.com"`), rendered)
+}
+
+func TestDashboardWithoutMetadataParametersIsNotChanged(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", testDashboardTemplate)
+	assert.NilError(t, err)
+
+	properties := map[string]map[string]string{"test": {"name": "Overview"}}
+	config := newConfig("test", "testproject", templ, properties, testDashboardApi, "")
+
+	rendered, err := config.GetConfigForEnvironment(testProductionEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, `{"dashboardMetadata": {"name": "Overview", "owner": "template-owner"}, "tiles": []}`)
+}
+
+func TestDashboardMetadataKeepsNumbersAndHtmlCharacters(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", `{"dashboardMetadata": {"name": "{{ .name }}"}, "tiles": [{"bounds": {"top": 12345678901234567890}, "markdown": "<b>A & B</b>"}]}`)
+	assert.NilError(t, err)
+
+	properties := map[string]map[string]string{"test": {"name": "Overview", dashboardOwnerParameter: "sre@example.com"}}
+	config := newConfig("test", "testproject", templ, properties, testDashboardApi, "")
+
+	rendered, err := config.GetConfigForEnvironment(testProductionEnvironment, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(rendered, `"top": 12345678901234567890`), rendered)
+	assert.Assert(t, strings.Contains(rendered, `"markdown": "<b>A & B</b>"`), rendered)
+}
+
+func TestInvalidDashboardPresetFails(t *testing.T) {
+
+	templ, err := util.NewTemplateFromString("test", testDashboardTemplate)
+	assert.NilError(t, err)
+
+	properties := map[string]map[string]string{"test": {"name": "Overview", dashboardPresetParameter: "yes please"}}
+	config := newConfig("test", "testproject", templ, properties, testDashboardApi, "")
+
+	_, err = config.GetConfigForEnvironment(testProductionEnvironment, map[string]api.DynatraceEntity{})
+	assert.ErrorContains(t, err, "dashboardPreset")
+}
@@ -0,0 +1,141 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+)
+
+// The levels of the chain properties are resolved along, from the most to the least specific one
+const (
+	ConfigLevel   = "config"
+	ProjectLevel  = "project"
+	GroupLevel    = "group"
+	GlobalLevel   = "global"
+	TemplateLevel = "template"
+)
+
+// Defaults hold the values configs fall back to for properties they do not define, by the level they are defined on.
+// Defaults are plain values, they can not reference other configs
+type Defaults struct {
+
+	// Project holds the defaults of the project of the config, declared in its project file
+	Project map[string]string
+
+	// Groups holds the defaults of the environments of a group, by group
+	Groups map[string]map[string]string
+
+	// Global holds the defaults of all environments
+	Global map[string]string
+}
+
+// PropertySource is a location a property is looked up at, and the value defined there
+type PropertySource struct {
+
+	// Level is one of ConfigLevel, ProjectLevel, GroupLevel, GlobalLevel and TemplateLevel
+	Level string
+
+	// Location describes where the value is defined within the level, e.g. the section and key of the config yaml
+	Location string
+
+	Value   string
+	Defined bool
+}
+
+// IsEmpty returns whether no defaults are defined on any level
+func (d Defaults) IsEmpty() bool {
+	return len(d.Project) == 0 && len(d.Groups) == 0 && len(d.Global) == 0
+}
+
+// Defines returns whether the property has a default on any level
+func (d Defaults) Defines(property string) bool {
+
+	if _, found := d.Project[property]; found {
+		return true
+	}
+	for _, defaults := range d.Groups {
+		if _, found := defaults[property]; found {
+			return true
+		}
+	}
+	_, found := d.Global[property]
+	return found
+}
+
+// sources returns where the defaults of the property are looked up for the environment, the project level first
+func (d Defaults) sources(environment environment.Environment, property string) []PropertySource {
+
+	project, inProject := d.Project[property]
+	group, inGroup := d.Groups[environment.GetGroup()][property]
+	global, inGlobal := d.Global[property]
+
+	return []PropertySource{
+		{Level: ProjectLevel, Location: "defaults: " + property, Value: project, Defined: inProject},
+		{Level: GroupLevel, Location: "groups." + environment.GetGroup() + ": " + property, Value: group, Defined: inGroup},
+		{Level: GlobalLevel, Location: "global: " + property, Value: global, Defined: inGlobal},
+	}
+}
+
+// names returns the properties with defaults for the environment
+func (d Defaults) names(environment environment.Environment) []string {
+
+	names := make([]string, 0)
+	for _, defaults := range []map[string]string{d.Project, d.Groups[environment.GetGroup()], d.Global} {
+		for name := range defaults {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// ExplainProperty returns all locations the property is looked up at for the environment, from the most to the
+// least specific. The first defined one holds the value used
+func (c *configImpl) ExplainProperty(environment environment.Environment, property string) []PropertySource {
+
+	sources := c.propertySources(c.properties, environment, property)
+
+	var fallback string
+	var found bool
+	if template := c.templateFor(environment); template != nil {
+		fallback, found = template.Defaults()[property]
+	}
+
+	return append(sources, PropertySource{Level: TemplateLevel, Location: "{{ ." + property + " | default }}", Value: fallback, Defined: found})
+}
+
+// propertySources returns the locations of the property along the chain config, project, group and global. Defaults
+// of the template are applied when it is rendered, they are not included
+func (c *configImpl) propertySources(properties map[string]map[string]string, environment environment.Environment, property string) []PropertySource {
+
+	sources := make([]PropertySource, 0)
+
+	for _, location := range c.propertyLocations(environment, property) {
+		value, found := properties[location.section][location.key]
+		sources = append(sources, PropertySource{Level: ConfigLevel, Location: location.section + ": " + location.key, Value: value, Defined: found})
+	}
+
+	return append(sources, c.defaults.sources(environment, property)...)
+}
+
+// WithDefaults returns a copy of the config falling back to the given defaults
+func (c *configImpl) WithDefaults(defaults Defaults) Config {
+
+	copied := *c
+	copied.defaults = defaults
+	return &copied
+}
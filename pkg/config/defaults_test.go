@@ -0,0 +1,95 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func defaultsTestConfig(t *testing.T) Config {
+
+	template, err := util.NewTemplateFromString("alert", `{"name": "{{ .name }}", "threshold": {{ .threshold }}, "window": {{ .window }}, "severity": "{{ .severity }}", "mode": "{{ .mode | default "auto" }}"}`)
+	assert.NilError(t, err)
+
+	properties := map[string]map[string]string{
+		"alert": {"name": "alert", "threshold.production": "99"},
+	}
+
+	return GetMockConfig("alert", "project", template, properties, api.NewApis()["alerting-profile"], "alert.json").WithDefaults(Defaults{
+		Project: map[string]string{"threshold": "80"},
+		Groups:  map[string]map[string]string{"prod": {"window": "10"}},
+		Global:  map[string]string{"window": "5", "severity": "high"},
+	})
+}
+
+func TestPropertiesFallBackToDefaults(t *testing.T) {
+
+	config := defaultsTestConfig(t)
+
+	production := environment.NewEnvironment("production", "production", "prod", "https://prod.example.com", "PROD")
+	rendered, err := config.GetConfigForEnvironment(production, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, `{"name": "alert", "threshold": 99, "window": 10, "severity": "high", "mode": "auto"}`)
+
+	development := environment.NewEnvironment("development", "development", "dev", "https://dev.example.com", "DEV")
+	rendered, err = config.GetConfigForEnvironment(development, map[string]api.DynatraceEntity{})
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, `{"name": "alert", "threshold": 80, "window": 5, "severity": "high", "mode": "auto"}`)
+
+	severity, err := config.GetPropertyForEnvironment(development, "severity", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, severity, "high")
+}
+
+func TestExplainPropertyListsTheChain(t *testing.T) {
+
+	config := defaultsTestConfig(t)
+	production := environment.NewEnvironment("production", "production", "prod", "https://prod.example.com", "PROD")
+
+	defined := func(sources []PropertySource) []PropertySource {
+		result := make([]PropertySource, 0)
+		for _, source := range sources {
+			if source.Defined {
+				result = append(result, source)
+			}
+		}
+		return result
+	}
+
+	sources := config.ExplainProperty(production, "window")
+	assert.Equal(t, len(sources), 9)
+	assert.DeepEqual(t, defined(sources), []PropertySource{
+		{Level: GroupLevel, Location: "groups.prod: window", Value: "10", Defined: true},
+		{Level: GlobalLevel, Location: "global: window", Value: "5", Defined: true},
+	})
+
+	assert.DeepEqual(t, defined(config.ExplainProperty(production, "threshold")), []PropertySource{
+		{Level: ConfigLevel, Location: "alert: threshold.production", Value: "99", Defined: true},
+		{Level: ProjectLevel, Location: "defaults: threshold", Value: "80", Defined: true},
+	})
+
+	assert.DeepEqual(t, defined(config.ExplainProperty(production, "mode")), []PropertySource{
+		{Level: TemplateLevel, Location: "{{ .mode | default }}", Value: "auto", Defined: true},
+	})
+}
@@ -0,0 +1,176 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/settings"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// templateVariantPrefix marks the properties holding the template files of the variants of a config, as
+// template.<variant>. The condition of a variant is held by template.<variant>.when, e.g. version >= 1.230.
+// This allows one config to serve environments of different versions, whose apis expect different payloads
+const templateVariantPrefix = "template."
+const templateConditionSuffix = ".when"
+
+// versionOperators are the operators conditions compare the version of an environment with. Longer operators
+// come first, so that >= is not parsed as >
+var versionOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// templateVariant is a template of a config, which is used for the environments matching its condition
+type templateVariant struct {
+	name      string
+	condition []versionCondition
+	template  util.Template
+}
+
+// versionCondition compares the version of an environment with a fixed version
+type versionCondition struct {
+	operator string
+	version  string
+}
+
+// IsTemplateVariant returns whether the property holds the template file of a variant of a config
+func IsTemplateVariant(property string) bool {
+	name := strings.TrimPrefix(property, templateVariantPrefix)
+	return strings.HasPrefix(property, templateVariantPrefix) && name != "" && !strings.Contains(name, ".")
+}
+
+// loadTemplateVariants loads the variants declared in the properties of the config, sorted by their names
+func loadTemplateVariants(id string, properties map[string]string) ([]templateVariant, error) {
+
+	variants := make([]templateVariant, 0)
+
+	for key, fileName := range properties {
+		if !IsTemplateVariant(key) {
+			continue
+		}
+		name := strings.TrimPrefix(key, templateVariantPrefix)
+
+		expression, found := properties[key+templateConditionSuffix]
+		if !found {
+			return nil, fmt.Errorf("template variant %s of config %s has no condition, please define %s", name, id, key+templateConditionSuffix)
+		}
+
+		condition, err := parseCondition(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid condition of template variant %s of config %s: %s", name, id, err)
+		}
+
+		template, err := util.NewTemplate(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("loading template variant %s of config %s failed with %s", name, id, err)
+		}
+
+		variants = append(variants, templateVariant{name: name, condition: condition, template: template})
+	}
+
+	sort.Slice(variants, func(i, j int) bool {
+		return variants[i].name < variants[j].name
+	})
+
+	return variants, nil
+}
+
+// parseCondition parses conditions like version >= 1.220 && version < 1.230
+func parseCondition(expression string) ([]versionCondition, error) {
+
+	condition := make([]versionCondition, 0)
+
+	for _, clause := range strings.Split(expression, "&&") {
+		clause = strings.TrimSpace(clause)
+		if !strings.HasPrefix(clause, "version") {
+			return nil, fmt.Errorf("%q does not compare the version, expected e.g. version >= 1.230", clause)
+		}
+		clause = strings.TrimSpace(strings.TrimPrefix(clause, "version"))
+
+		parsed := versionCondition{}
+		for _, operator := range versionOperators {
+			if strings.HasPrefix(clause, operator) {
+				parsed = versionCondition{operator: operator, version: strings.TrimSpace(strings.TrimPrefix(clause, operator))}
+				break
+			}
+		}
+		if parsed.operator == "" || parsed.version == "" {
+			return nil, fmt.Errorf("%q is not a comparison with a version, expected e.g. version >= 1.230", expression)
+		}
+
+		condition = append(condition, parsed)
+	}
+
+	return condition, nil
+}
+
+// matches returns whether all comparisons of the condition hold for the version. Unknown versions match no condition
+func (v templateVariant) matches(version string) bool {
+
+	if version == "" {
+		return false
+	}
+
+	for _, comparison := range v.condition {
+		result := settings.CompareVersions(version, comparison.version)
+
+		switch comparison.operator {
+		case ">=":
+			if result < 0 {
+				return false
+			}
+		case "<=":
+			if result > 0 {
+				return false
+			}
+		case "==":
+			if result != 0 {
+				return false
+			}
+		case "!=":
+			if result == 0 {
+				return false
+			}
+		case ">":
+			if result <= 0 {
+				return false
+			}
+		case "<":
+			if result >= 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// templateFor returns the template of the first variant, by name, matching the version of the environment. The
+// template of the config is used, if no variant matches
+func (c *configImpl) templateFor(environment environment.Environment) util.Template {
+
+	for _, variant := range c.variants {
+		if variant.matches(environment.GetVersion()) {
+			util.Log.Debug("\t\t\tusing template variant %s of %s for environment %s", variant.name, c.GetFullQualifiedId(), environment.GetId())
+			return variant.template
+		}
+	}
+
+	return c.template
+}
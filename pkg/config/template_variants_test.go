@@ -0,0 +1,84 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"gotest.tools/assert"
+)
+
+func writeTemplate(t *testing.T, folder string, name string, content string) string {
+	fileName := filepath.Join(folder, name)
+	assert.NilError(t, ioutil.WriteFile(fileName, []byte(content), 0644))
+	return fileName
+}
+
+func TestTemplateVariantsAreSelectedByVersion(t *testing.T) {
+
+	folder := t.TempDir()
+	properties := map[string]map[string]string{
+		"zone": {
+			"name":                 "zone",
+			"template.legacy":      writeTemplate(t, folder, "legacy.json", `{"legacy": "{{.name}}"}`),
+			"template.legacy.when": "version < 1.220",
+			"template.modern":      writeTemplate(t, folder, "modern.json", `{"modern": "{{.name}}"}`),
+			"template.modern.when": "version >= 1.230 && version < 2",
+		},
+	}
+
+	config, err := NewConfig("zone", "project", writeTemplate(t, folder, "zone.json", `{"default": "{{.name}}"}`), properties, testManagementZoneApi)
+	assert.NilError(t, err)
+
+	for version, expected := range map[string]string{
+		"1.210.3.20210315-101010": `{"legacy": "zone"}`,
+		"1.225":                   `{"default": "zone"}`,
+		"1.232.0.20211214-112149": `{"modern": "zone"}`,
+		"2.1":                     `{"default": "zone"}`,
+		"":                        `{"default": "zone"}`,
+	} {
+		json, err := config.GetConfigForEnvironment(environment.WithVersion(testDevEnvironment, version), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, json, expected, version)
+	}
+}
+
+func TestTemplateVariantsNeedValidConditions(t *testing.T) {
+
+	folder := t.TempDir()
+	template := writeTemplate(t, folder, "zone.json", `{}`)
+
+	_, err := NewConfig("zone", "project", template, map[string]map[string]string{
+		"zone": {"name": "zone", "template.v2": template},
+	}, testManagementZoneApi)
+	assert.ErrorContains(t, err, "has no condition")
+
+	_, err = NewConfig("zone", "project", template, map[string]map[string]string{
+		"zone": {"name": "zone", "template.v2": template, "template.v2.when": "schema >= 1.2"},
+	}, testManagementZoneApi)
+	assert.ErrorContains(t, err, "does not compare the version")
+
+	_, err = NewConfig("zone", "project", template, map[string]map[string]string{
+		"zone": {"name": "zone", "template.v2": template, "template.v2.when": "version ~ 1.2"},
+	}, testManagementZoneApi)
+	assert.ErrorContains(t, err, "is not a comparison")
+}
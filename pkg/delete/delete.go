@@ -18,10 +18,14 @@ package delete
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
-	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 	"gopkg.in/yaml.v2"
 )
@@ -29,48 +33,285 @@ import (
 const deleteDelimiter = "/"
 const deleteFileName = "delete.yaml"
 
+// unnamedApis can not be deleted by name, as their objects are not listed with a name
+var unnamedApis = map[string]bool{
+	"settings": true,
+}
+
 type deleteYaml struct {
-	Delete interface{}
+	Delete []entryYaml
+}
+
+// entryYaml is either a "<api>/<name>" string or a structured entry
+type entryYaml struct {
+	reference    string
+	Api          string   `yaml:"api"`
+	Name         string   `yaml:"name"`
+	Id           string   `yaml:"id"`
+	Environments []string `yaml:"environments"`
+	Owner        string   `yaml:"owner"`
+	Labels       []string `yaml:"labels"`
+}
+
+func (e *entryYaml) UnmarshalYAML(unmarshal func(interface{}) error) error {
+
+	if err := unmarshal(&e.reference); err == nil {
+		return nil
+	}
+
+	type plain entryYaml
+	return unmarshal((*plain)(e))
 }
 
-// LoadConfigsToDelete loads the delete.yaml file (if available) and converts its entries into configs which need
-// to be deleted
-func LoadConfigsToDelete(apis map[string]api.Api, path string, fileReader util.FileReader) (configs []config.Config, err error) {
+// Entry of the delete.yaml, selecting the configs of an api to delete either by id or by name.
+// Names may contain the wildcards * and ?
+type Entry struct {
+	Api  api.Api
+	Name string
+	Id   string
+
+	// Environments the entry is restricted to. Applies to all environments if empty
+	Environments []string
+
+	// Owner and Labels of the deleted configs, deletions restricted to an owner or labels only apply matching entries
+	Owner  string
+	Labels []string
 
-	result := make([]config.Config, 0)
+	// pattern matches the names selected by a Name with wildcards, it is compiled once when the entry is loaded
+	pattern *regexp.Regexp
+}
+
+// Target is an existing config an Entry resolved to
+type Target struct {
+	Api  api.Api
+	Id   string
+	Name string
+}
+
+// LoadEntriesToDelete loads the delete.yaml file (if available) and validates its entries
+func LoadEntriesToDelete(apis map[string]api.Api, path string, fileReader util.FileReader) (entries []Entry, err error) {
 
 	data, err := fileReader.ReadFile(path + "" + deleteFileName)
 	if err != nil {
 		// Don't raise an error. The delete.yaml might not be there, that's a valid case
-		return result, nil
+		return entries, nil
 	}
 
 	list, err := unmarshalDeleteYaml(string(data), deleteFileName)
 	if util.CheckError(err, deleteFileName+" file content was invalid") {
-		return configs, err
+		return entries, err
 	}
 
 	for _, element := range list {
 
-		configType, name, err := splitConfigToDelete(element)
+		entry, err := toEntry(element, apis)
 		if util.CheckError(err, "deletion failed") {
-			return configs, err
+			return entries, err
 		}
 
-		api, validConfig := apis[configType]
-		if !validConfig {
-			return configs, errors.New("config type " + configType + " was not valid")
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func toEntry(element entryYaml, apis map[string]api.Api) (Entry, error) {
+
+	if element.reference != "" {
+		configType, name, err := splitConfigToDelete(element.reference)
+		if err != nil {
+			return Entry{}, err
 		}
+		element = entryYaml{Api: configType, Name: name}
+	}
 
-		properties := make(map[string]map[string]string)
-		properties[name] = make(map[string]string)
-		properties[name]["name"] = name
+	a, validConfig := apis[element.Api]
+	if !validConfig {
+		return Entry{}, errors.New("config type " + element.Api + " was not valid")
+	}
+
+	if unnamedApis[element.Api] {
+		return Entry{}, fmt.Errorf("deletion of %s is not supported", element.Api)
+	}
 
-		configForDeletion := config.NewConfigForDelete(name, "delete.yaml", properties, api)
-		result = append(result, configForDeletion)
+	if (element.Name == "") == (element.Id == "") {
+		return Entry{}, fmt.Errorf("entry of %s must either define a name or an id", element.Api)
 	}
 
-	return result, nil
+	return Entry{Api: a, Name: element.Name, Id: element.Id, Environments: element.Environments, Owner: element.Owner, Labels: element.Labels}.compiled()
+}
+
+// compiled returns the entry with the pattern of its name compiled, if its name contains wildcards
+func (e Entry) compiled() (Entry, error) {
+
+	if e.Id != "" || !strings.ContainsAny(e.Name, "*?") {
+		return e, nil
+	}
+
+	pattern := regexp.QuoteMeta(e.Name)
+	pattern = strings.ReplaceAll(pattern, `\*`, ".*")
+	pattern = strings.ReplaceAll(pattern, `\?`, ".")
+
+	compiled, err := regexp.Compile("^" + pattern + "$")
+	if err != nil {
+		return Entry{}, fmt.Errorf("entry %s has an invalid name pattern: %s", e, err)
+	}
+
+	e.pattern = compiled
+	return e, nil
+}
+
+// AppliesTo checks if the entry targets the given environment
+func (e Entry) AppliesTo(environment string) bool {
+
+	if len(e.Environments) == 0 {
+		return true
+	}
+
+	for _, target := range e.Environments {
+		if target == environment {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e Entry) isPattern() bool {
+	return e.pattern != nil
+}
+
+// matches checks if the value is selected by the entry. Names are compared with the given name matching
+func (e Entry) matches(value api.Value, matching rest.NameMatching) bool {
+
+	if e.Id != "" {
+		return value.Id == e.Id
+	}
+
+	if !e.isPattern() {
+		return matching.Matches(value.Name, e.Name)
+	}
+
+	return e.pattern.MatchString(value.Name)
+}
+
+func (e Entry) String() string {
+
+	if e.Id != "" {
+		return e.Api.GetId() + " with id " + e.Id
+	}
+
+	return e.Api.GetId() + "/" + e.Name
+}
+
+// PlanDeletion resolves the entries applying to the environment into the existing configs to delete. Patterns
+// select all matching configs, while a name which is used by multiple configs is only resolved if the state
+// knows which of them was deployed. Entries not matching any config are skipped. Names are compared with the given
+// name matching, like when configs are deployed
+func PlanDeletion(entries []Entry, environment string, client rest.DynatraceClient, deployState *state.State, matching rest.NameMatching) (targets []Target, err error) {
+
+	// the apis of all entries are listed at once, instead of one after the other
+	apis := make(map[string]api.Api)
+	for _, entry := range entries {
+		if entry.AppliesTo(environment) {
+			apis[entry.Api.GetId()] = entry.Api
+		}
+	}
+
+	listed, err := rest.ListAll(client, apis)
+	if err != nil {
+		return nil, err
+	}
+
+	planned := make(map[string]bool)
+
+	for _, entry := range entries {
+
+		if !entry.AppliesTo(environment) {
+			continue
+		}
+
+		values := listed[entry.Api.GetId()]
+
+		var matches []api.Value
+		for _, value := range values {
+			if entry.matches(value, matching) {
+				matches = append(matches, value)
+			}
+		}
+
+		if len(matches) > 1 && entry.Id == "" && !entry.isPattern() {
+			matches, err = resolveDuplicates(entry, matches, environment, deployState)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, value := range matches {
+			key := entry.Api.GetId() + "/" + value.Id
+			if planned[key] {
+				continue
+			}
+			planned[key] = true
+			targets = append(targets, Target{Api: entry.Api, Id: value.Id, Name: value.Name})
+		}
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].Api.GetId() < targets[j].Api.GetId()
+	})
+
+	return targets, nil
+}
+
+// PlanDeletionOfCreated resolves the entries applying to the environment against the names of objects a deployment
+// creates, by api, which are not planned for deletion yet as they do not exist. A dry run creates no objects, so this
+// shows the objects a deployment would delete right after creating them. The returned targets have no id
+func PlanDeletionOfCreated(entries []Entry, environment string, created map[string][]string, planned []Target, matching rest.NameMatching) []Target {
+
+	known := make(map[string]bool, len(planned))
+	for _, target := range planned {
+		known[target.Api.GetId()+"/"+matching.Normalize(target.Name)] = true
+	}
+
+	var targets []Target
+	for _, entry := range entries {
+
+		if !entry.AppliesTo(environment) || entry.Id != "" {
+			continue
+		}
+
+		for _, name := range created[entry.Api.GetId()] {
+			key := entry.Api.GetId() + "/" + matching.Normalize(name)
+			if known[key] || !entry.matches(api.Value{Name: name}, matching) {
+				continue
+			}
+			known[key] = true
+			targets = append(targets, Target{Api: entry.Api, Name: name})
+		}
+	}
+
+	return targets
+}
+
+func resolveDuplicates(entry Entry, matches []api.Value, environment string, deployState *state.State) ([]api.Value, error) {
+
+	ids := make([]string, 0, len(matches))
+	for _, value := range matches {
+		ids = append(ids, value.Id)
+	}
+
+	if deployState != nil {
+		if id, found := deployState.Get(environment, entry.Api.GetId(), entry.Name); found {
+			for _, value := range matches {
+				if value.Id == id {
+					return []api.Value{value}, nil
+				}
+			}
+		}
+	}
+
+	return nil, rest.DuplicateNameError{Api: entry.Api.GetId(), Name: entry.Name, Ids: ids}
 }
 
 // splitConfigToDelete gets one line of the delete.yaml as input and splits it into config type and name
@@ -91,14 +332,16 @@ func splitConfigToDelete(config string) (configType string, name string, err err
 	return split[0], split[1], nil
 }
 
-// unmarshalDeleteYaml takes the contents of a yaml file and converts it to a string array
+// unmarshalDeleteYaml takes the contents of a yaml file and converts it into its entries
 // The yaml file should have the following format:
 //
 // delete:
-//  - "list-entry-1"
-//  - "list-entry-2"
+//  - "<api>/<name>"
+//  - api: "<api>"
+//    name: "<name or pattern>" # or id: "<id>"
+//    environments: ["<environment>"] # optional
 //
-func unmarshalDeleteYaml(text string, fileName string) (typed []string, err error) {
+func unmarshalDeleteYaml(text string, fileName string) (typed []entryYaml, err error) {
 
 	d := deleteYaml{}
 
@@ -107,28 +350,11 @@ func unmarshalDeleteYaml(text string, fileName string) (typed []string, err erro
 		return typed, err
 	}
 
-	typed, err = convertList(d)
-	if util.CheckError(err, "Failed to unmarshal yaml\n"+text+"for file name"+fileName+"\nerror:") {
+	if d.Delete == nil {
+		err = errors.New("invalid YAML structure")
+		util.CheckError(err, "Failed to unmarshal yaml\n"+text+"for file name"+fileName+"\nerror:")
 		return typed, err
 	}
 
-	return typed, nil
-}
-
-// convertList converts the unmarshalled yaml into a string array
-func convertList(original deleteYaml) ([]string, error) {
-
-	if original.Delete == nil {
-		return nil, errors.New("invalid YAML structure")
-	}
-
-	result := make([]string, 0)
-	list, _ := original.Delete.([]interface{})
-
-	for _, x := range list {
-		y := x.(string)
-		result = append(result, y)
-	}
-
-	return result, nil
+	return d.Delete, nil
 }
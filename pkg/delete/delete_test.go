@@ -19,28 +19,232 @@
 package delete
 
 import (
-	"gotest.tools/assert"
+	"sync"
 	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"github.com/google/go-cmp/cmp"
+	"gotest.tools/assert"
 )
 
 const testYamlList = `
 delete:
-- Han Solo
-- Chewbacca
-- Darth Maul
-- "Count - Doku"
+- dashboard/Han Solo
+- "management-zone/Count - Doku"
+- api: dashboard
+  name: "Darth *"
+  environments: [dev]
+- api: management-zone
+  id: "42"
+  owner: team-a
+  labels: [payments]
 `
 
+var testDeleteApis = map[string]api.Api{
+	"dashboard":       api.NewApi("dashboard", "/api/config/v1/dashboards"),
+	"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	"settings":        api.NewApi("settings", "/api/v2/settings/objects"),
+}
+
+// listingClient serves the given values per api
+type listingClient struct {
+	rest.DynatraceClient
+	values map[string][]api.Value
+}
+
+func (c listingClient) List(a api.Api) ([]api.Value, error) {
+	return c.values[a.GetId()], nil
+}
+
+// compiledEntry compiles the name pattern of an entry built by a test, like toEntry does for the entries of delete.yaml
+func compiledEntry(t *testing.T, entry Entry) Entry {
+	compiled, err := entry.compiled()
+	assert.NilError(t, err)
+	return compiled
+}
+
 func TestUnmarshalDeleteYaml(t *testing.T) {
 
 	result, e := unmarshalDeleteYaml(testYamlList, "test-yaml")
 	assert.NilError(t, e)
 
 	assert.Check(t, len(result) == 4)
-	assert.Equal(t, "Han Solo", result[0])
-	assert.Equal(t, "Chewbacca", result[1])
-	assert.Equal(t, "Darth Maul", result[2])
-	assert.Equal(t, "Count - Doku", result[3])
+	assert.Equal(t, "dashboard/Han Solo", result[0].reference)
+	assert.Equal(t, "management-zone/Count - Doku", result[1].reference)
+	assert.DeepEqual(t, entryYaml{Api: "dashboard", Name: "Darth *", Environments: []string{"dev"}}, result[2], cmp.AllowUnexported(entryYaml{}))
+	assert.DeepEqual(t, entryYaml{Api: "management-zone", Id: "42", Owner: "team-a", Labels: []string{"payments"}}, result[3], cmp.AllowUnexported(entryYaml{}))
+}
+
+func TestToEntryValidatesEntries(t *testing.T) {
+
+	entry, err := toEntry(entryYaml{reference: "dashboard/Han Solo"}, testDeleteApis)
+	assert.NilError(t, err)
+	assert.Equal(t, entry.Api.GetId(), "dashboard")
+	assert.Equal(t, entry.Name, "Han Solo")
+
+	_, err = toEntry(entryYaml{Api: "unknown", Name: "x"}, testDeleteApis)
+	assert.ErrorContains(t, err, "config type unknown was not valid")
+
+	_, err = toEntry(entryYaml{Api: "dashboard", Name: "x", Id: "1"}, testDeleteApis)
+	assert.ErrorContains(t, err, "must either define a name or an id")
+
+	_, err = toEntry(entryYaml{Api: "settings", Id: "1"}, testDeleteApis)
+	assert.ErrorContains(t, err, "deletion of settings is not supported")
+}
+
+func TestToEntryCompilesNamePatternsOnce(t *testing.T) {
+
+	entry, err := toEntry(entryYaml{Api: "dashboard", Name: "Darth *"}, testDeleteApis)
+	assert.NilError(t, err)
+	assert.Assert(t, entry.pattern != nil)
+	assert.Assert(t, entry.matches(api.Value{Name: "Darth Vader"}, rest.NameMatching{}))
+
+	entry, err = toEntry(entryYaml{Api: "dashboard", Name: "Han Solo"}, testDeleteApis)
+	assert.NilError(t, err)
+	assert.Assert(t, entry.pattern == nil)
+	assert.Assert(t, entry.matches(api.Value{Name: "Han Solo"}, rest.NameMatching{}))
+}
+
+func TestEntryMatchesPatterns(t *testing.T) {
+
+	entry := compiledEntry(t, Entry{Api: testDeleteApis["dashboard"], Name: "Team ? - *"})
+
+	assert.Assert(t, entry.matches(api.Value{Name: "Team A - Overview"}, rest.NameMatching{}))
+	assert.Assert(t, !entry.matches(api.Value{Name: "Team AB - Overview"}, rest.NameMatching{}))
+	assert.Assert(t, !entry.matches(api.Value{Name: "My Team A - Overview"}, rest.NameMatching{}))
+	assert.Assert(t, Entry{Name: "a.b (c)"}.matches(api.Value{Name: "a.b (c)"}, rest.NameMatching{}))
+}
+
+func TestEntryAppliesToEnvironments(t *testing.T) {
+
+	assert.Assert(t, Entry{}.AppliesTo("dev"))
+	assert.Assert(t, Entry{Environments: []string{"dev"}}.AppliesTo("dev"))
+	assert.Assert(t, !Entry{Environments: []string{"dev"}}.AppliesTo("prod"))
+}
+
+func TestPlanDeletion(t *testing.T) {
+
+	client := listingClient{values: map[string][]api.Value{
+		"dashboard":       {{Id: "1", Name: "Darth Maul"}, {Id: "2", Name: "Darth Vader"}, {Id: "3", Name: "Han Solo"}, {Id: "4", Name: "Yoda"}},
+		"management-zone": {{Id: "42", Name: "Count - Doku"}},
+	}}
+
+	entries := []Entry{
+		{Api: testDeleteApis["management-zone"], Name: "Count - Doku"},
+		compiledEntry(t, Entry{Api: testDeleteApis["dashboard"], Name: "Darth *", Environments: []string{"dev"}}),
+		{Api: testDeleteApis["dashboard"], Name: "Han Solo"},
+		{Api: testDeleteApis["dashboard"], Name: "Missing"},
+		{Api: testDeleteApis["management-zone"], Id: "42"},
+	}
+
+	targets, err := PlanDeletion(entries, "dev", client, nil, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetIds(targets), []string{"dashboard/1", "dashboard/2", "dashboard/3", "management-zone/42"})
+
+	targets, err = PlanDeletion(entries, "prod", client, nil, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetIds(targets), []string{"dashboard/3", "management-zone/42"})
+}
+
+// countingClient counts how often each api is listed
+type countingClient struct {
+	listingClient
+	lock  sync.Mutex
+	lists map[string]int
+}
+
+func (c *countingClient) List(a api.Api) ([]api.Value, error) {
+	c.lock.Lock()
+	c.lists[a.GetId()]++
+	c.lock.Unlock()
+
+	return c.listingClient.List(a)
+}
+
+func TestPlanDeletionMatchesNamesLikeDeployments(t *testing.T) {
+
+	client := listingClient{values: map[string][]api.Value{
+		"dashboard": {{Id: "1", Name: "Han Solo"}},
+	}}
+
+	entries := []Entry{{Api: testDeleteApis["dashboard"], Name: "han solo"}}
+
+	targets, err := PlanDeletion(entries, "dev", client, nil, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(targets), 0)
+
+	targets, err = PlanDeletion(entries, "dev", client, nil, rest.NameMatching{CaseInsensitive: true})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetIds(targets), []string{"dashboard/1"})
+}
+
+func TestPlanDeletionListsEachApiOfTheEnvironmentOnce(t *testing.T) {
+
+	client := &countingClient{
+		listingClient: listingClient{values: map[string][]api.Value{"dashboard": {{Id: "1", Name: "Darth Maul"}, {Id: "3", Name: "Han Solo"}}}},
+		lists:         make(map[string]int),
+	}
+
+	entries := []Entry{
+		compiledEntry(t, Entry{Api: testDeleteApis["dashboard"], Name: "Darth *"}),
+		{Api: testDeleteApis["dashboard"], Name: "Han Solo"},
+		{Api: testDeleteApis["management-zone"], Id: "42", Environments: []string{"prod"}},
+	}
+
+	targets, err := PlanDeletion(entries, "dev", client, nil, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetIds(targets), []string{"dashboard/1", "dashboard/3"})
+	assert.DeepEqual(t, client.lists, map[string]int{"dashboard": 1})
+}
+
+func TestPlanDeletionOfCreated(t *testing.T) {
+
+	entries := []Entry{
+		compiledEntry(t, Entry{Api: testDeleteApis["dashboard"], Name: "Darth *"}),
+		{Api: testDeleteApis["dashboard"], Name: "Han Solo", Environments: []string{"prod"}},
+		{Api: testDeleteApis["management-zone"], Id: "42"},
+	}
+	created := map[string][]string{
+		"dashboard":       {"Darth Maul", "Darth Vader", "Han Solo", "Yoda"},
+		"management-zone": {"Count - Doku"},
+	}
+	planned := []Target{{Api: testDeleteApis["dashboard"], Id: "1", Name: "Darth Maul"}}
+
+	targets := PlanDeletionOfCreated(entries, "dev", created, planned, rest.NameMatching{})
+
+	assert.Equal(t, 1, len(targets))
+	assert.Equal(t, "Darth Vader", targets[0].Name)
+	assert.Equal(t, "", targets[0].Id)
+}
+
+func TestPlanDeletionResolvesDuplicateNamesByState(t *testing.T) {
+
+	client := listingClient{values: map[string][]api.Value{
+		"dashboard": {{Id: "1", Name: "overview"}, {Id: "2", Name: "overview"}},
+	}}
+	entries := []Entry{{Api: testDeleteApis["dashboard"], Name: "overview"}}
+
+	_, err := PlanDeletion(entries, "dev", client, nil, rest.NameMatching{})
+	assert.DeepEqual(t, err, rest.DuplicateNameError{Api: "dashboard", Name: "overview", Ids: []string{"1", "2"}})
+
+	deployState := state.NewState()
+	deployState.Set("dev", "dashboard", "overview", "2")
+
+	targets, err := PlanDeletion(entries, "dev", client, deployState, rest.NameMatching{})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, targetIds(targets), []string{"dashboard/2"})
+}
+
+func targetIds(targets []Target) []string {
+
+	ids := make([]string, 0, len(targets))
+	for _, target := range targets {
+		ids = append(ids, target.Api.GetId()+"/"+target.Id)
+	}
+
+	return ids
 }
 
 func TestSplitValidConfigLine(t *testing.T) {
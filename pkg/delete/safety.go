@@ -0,0 +1,124 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// SafetyLevel classifies the blast radius of deleting the objects of an api
+type SafetyLevel string
+
+const (
+	// LowImpact deletions only affect the deleted object, e.g. a dashboard
+	LowImpact SafetyLevel = "low"
+
+	// HighImpact deletions affect other configs or access to data, e.g. a management zone scoping permissions
+	HighImpact SafetyLevel = "high"
+)
+
+// defaultHighImpactApis are the apis whose objects are used by other configs or grant access to data
+var defaultHighImpactApis = []string{
+	"alerting-profile",
+	"application",
+	"auto-tag",
+	"aws-credentials",
+	"azure-credentials",
+	"kubernetes-credentials",
+	"management-zone",
+	"notification",
+	"request-attributes",
+}
+
+// SafetyLevels holds the safety level of every api, apis not classified are of low impact
+type SafetyLevels struct {
+	levels map[string]SafetyLevel
+}
+
+type safetyLevelsFile struct {
+	Levels map[string]SafetyLevel `yaml:"levels"`
+}
+
+// DefaultSafetyLevels classifies the defaultHighImpactApis as high impact
+func DefaultSafetyLevels() SafetyLevels {
+
+	levels := make(map[string]SafetyLevel, len(defaultHighImpactApis))
+	for _, id := range defaultHighImpactApis {
+		levels[id] = HighImpact
+	}
+
+	return SafetyLevels{levels: levels}
+}
+
+// LoadSafetyLevels reads the levels of apis from the given yaml file, overriding the defaults of these apis.
+// If file is empty, the defaults are returned
+func LoadSafetyLevels(file string, fileReader util.FileReader) (SafetyLevels, error) {
+
+	levels := DefaultSafetyLevels()
+	if file == "" {
+		return levels, nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return levels, fmt.Errorf("deletion safety levels %s could not be read: %s", file, err)
+	}
+
+	var parsed safetyLevelsFile
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return levels, fmt.Errorf("deletion safety levels %s are invalid: %s", file, err)
+	}
+
+	for id, level := range parsed.Levels {
+		if !api.IsApi(id) {
+			return levels, fmt.Errorf("deletion safety levels %s refer to unknown api %s", file, id)
+		}
+		if level != LowImpact && level != HighImpact {
+			return levels, fmt.Errorf("deletion safety level %s of %s in %s is unknown, supported are low and high", level, id, file)
+		}
+		levels.levels[id] = level
+	}
+
+	return levels, nil
+}
+
+// Level returns the safety level of the api
+func (l SafetyLevels) Level(apiId string) SafetyLevel {
+
+	if level, found := l.levels[apiId]; found {
+		return level
+	}
+	return LowImpact
+}
+
+// HighImpactTargets returns the targets whose api is of high impact
+func (l SafetyLevels) HighImpactTargets(targets []Target) []Target {
+
+	highImpact := make([]Target, 0)
+	for _, target := range targets {
+		if l.Level(target.Api.GetId()) == HighImpact {
+			highImpact = append(highImpact, target)
+		}
+	}
+
+	return highImpact
+}
@@ -0,0 +1,79 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package delete
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func loadTestSafetyLevels(t *testing.T, content string) (SafetyLevels, error) {
+
+	file := filepath.Join(t.TempDir(), "deletion-safety.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0664))
+
+	return LoadSafetyLevels(file, util.NewFileReader())
+}
+
+func TestDefaultSafetyLevels(t *testing.T) {
+
+	levels, err := LoadSafetyLevels("", util.NewFileReader())
+	assert.NilError(t, err)
+
+	assert.Equal(t, levels.Level("management-zone"), HighImpact)
+	assert.Equal(t, levels.Level("dashboard"), LowImpact)
+}
+
+func TestLoadSafetyLevelsOverridesDefaults(t *testing.T) {
+
+	levels, err := loadTestSafetyLevels(t, "levels:\n  dashboard: high\n  auto-tag: low\n")
+	assert.NilError(t, err)
+
+	assert.Equal(t, levels.Level("dashboard"), HighImpact)
+	assert.Equal(t, levels.Level("auto-tag"), LowImpact)
+	assert.Equal(t, levels.Level("management-zone"), HighImpact)
+
+	apis := api.NewApis()
+	targets := []Target{
+		{Api: apis["dashboard"], Id: "1", Name: "dashboard"},
+		{Api: apis["auto-tag"], Id: "2", Name: "tag"},
+		{Api: apis["management-zone"], Id: "3", Name: "zone"},
+	}
+	highImpact := levels.HighImpactTargets(targets)
+	assert.Equal(t, len(highImpact), 2)
+	assert.Equal(t, highImpact[0].Id, "1")
+	assert.Equal(t, highImpact[1].Id, "3")
+}
+
+func TestLoadSafetyLevelsValidatesLevels(t *testing.T) {
+
+	_, err := loadTestSafetyLevels(t, "levels:\n  unknown-api: high\n")
+	assert.ErrorContains(t, err, "unknown api unknown-api")
+
+	_, err = loadTestSafetyLevels(t, "levels:\n  dashboard: critical\n")
+	assert.ErrorContains(t, err, "critical of dashboard")
+
+	_, err = loadTestSafetyLevels(t, "dashboard: high\n")
+	assert.ErrorContains(t, err, "are invalid")
+}
@@ -0,0 +1,48 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package deploy
+
+// The results of a config
+const (
+	Deployed = "deployed"
+	Valid    = "valid"
+	Skipped  = "skipped"
+	Failed   = "failed"
+)
+
+// Result is the outcome of one config in an environment, reported as soon as it is known
+type Result struct {
+	Environment string `json:"environment"`
+	Config      string `json:"config"`
+	Result      string `json:"result"`
+	Id          string `json:"id,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// Listener receives the result of every config while an environment is deployed or validated, e.g. to show
+// the progress live. Returning an error aborts the environment with it. A nil listener receives nothing
+type Listener func(result Result) error
+
+// Report hands the result to the listener
+func (l Listener) Report(result Result) error {
+
+	if l == nil {
+		return nil
+	}
+
+	return l(result)
+}
@@ -0,0 +1,121 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// dashboardApiId is the api of dashboards, whose tiles can be split into separate files
+const dashboardApiId = "dashboard"
+
+// tilesPlaceholder marks the tiles in the encoded dashboard template, where the includes of the tile files are inserted
+const tilesPlaceholder = "__monaco_tiles__"
+
+// tileFragment is a tile of a split dashboard, written to its own file within the api folder
+type tileFragment struct {
+	path    string
+	content []byte
+}
+
+// toSplitDashboardTemplate converts the dashboard payload like toTemplate. If the dashboard has more tiles than
+// maxTiles, every tile is converted into its own template within tilesFolder. The dashboard template includes them
+// in their order, so that it serves as the manifest of the tiles
+func toSplitDashboardTemplate(payload []byte, name string, references *zoneReferences, fields api.FieldOverrides, tilesFolder string, maxTiles int) ([]byte, []tileFragment, error) {
+
+	content, err := toTemplateContent(dashboardApiId, payload, name, references, fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tiles, isList := content["tiles"].([]interface{})
+	if !isList || len(tiles) <= maxTiles {
+		template, err := encodeTemplate(content, references)
+		return template, nil, err
+	}
+
+	fragments := make([]tileFragment, 0, len(tiles))
+	includes := make([]string, 0, len(tiles))
+
+	for i, tile := range tiles {
+
+		fragment, err := encodeTemplate(tile, references)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		path := tilesFolder + "/" + tileFileName(i, tile)
+		fragments = append(fragments, tileFragment{path: path, content: fragment})
+		includes = append(includes, `    {{ include "`+path+`" . }}`)
+	}
+
+	content["tiles"] = tilesPlaceholder
+
+	template, err := encodeTemplate(content, references)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tileList := "[\n" + strings.Join(includes, ",\n") + "\n  ]"
+	return []byte(strings.Replace(string(template), `"`+tilesPlaceholder+`"`, tileList, 1)), fragments, nil
+}
+
+// tileFileName names the file of a tile by its position and its name or type, e.g. 001-markdown.json
+func tileFileName(index int, tile interface{}) string {
+
+	label := "tile"
+	if object, isObject := tile.(map[string]interface{}); isObject {
+		if tileName, isString := object["name"].(string); isString && tileName != "" {
+			label = tileName
+		} else if tileType, isString := object["tileType"].(string); isString && tileType != "" {
+			label = tileType
+		}
+	}
+
+	label = strings.Trim(invalidConfigIdCharacters.ReplaceAllString(strings.ToLower(label), "-"), "-")
+	if label == "" {
+		label = "tile"
+	}
+
+	return fmt.Sprintf("%03d-%s.json", index+1, label)
+}
+
+func writeTileFragments(apiFolder string, fragments []tileFragment) error {
+
+	for _, fragment := range fragments {
+
+		path := filepath.Join(apiFolder, filepath.FromSlash(fragment.path))
+
+		err := os.MkdirAll(filepath.Dir(path), 0777)
+		if err != nil {
+			return err
+		}
+
+		err = ioutil.WriteFile(path, fragment.content, 0664)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,553 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// volatileFields change on the server without the config being changed. They are removed on all levels of
+// a payload, so that repeated downloads of an unchanged environment result in the same files
+var volatileFields = map[string]bool{
+	"creationTimestamp":     true,
+	"lastModified":          true,
+	"lastModifiedBy":        true,
+	"lastModifiedTimestamp": true,
+	"modificationTimestamp": true,
+}
+
+// unsupportedApis can not be downloaded as json templates, as their payload is not uploaded as json
+// or can not be listed without further parameters
+var unsupportedApis = map[string]bool{
+	"extension": true,
+	"settings":  true,
+}
+
+var invalidConfigIdCharacters = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// Options control how downloaded configs are written
+type Options struct {
+
+	// SplitDashboardTiles is the number of tiles above which the tiles of a dashboard are written to separate files,
+	// which are included by the dashboard template. Dashboards are not split if it is 0
+	SplitDashboardTiles int
+
+	// Provenance embeds where configs originate from into their templates (ProvenanceField) or writes it to a
+	// sidecar file per config (ProvenanceSidecar). No provenance is recorded if it is empty
+	Provenance string
+
+	// Environment is the id of the environment the configs are downloaded from. It is recorded as provenance of the
+	// downloaded configs together with Timestamp, and pins are written to the section of the environment
+	Environment string
+	Timestamp   time.Time
+
+	// OwnerRules rewrite the owners of downloaded dashboards, see LoadOwnerRules
+	OwnerRules []OwnerRule
+
+	// Pin records the hash of every downloaded object as pinnedRemote parameter of its config in the section of the
+	// environment (<configId>.<environment>), see PayloadHash
+	Pin bool
+
+	// FieldOverrides add server managed and diff ignored fields to the built-in ones, which are stripped from
+	// templates and do not contribute to pins
+	FieldOverrides api.FieldOverrides
+}
+
+// DownloadConfigs downloads all configs of the given apis and writes them as a monaco project into projectFolder.
+// For each api a folder containing a yaml file and one json template per config is created.
+// Returns the number of downloaded configs
+func DownloadConfigs(apis map[string]api.Api, client rest.DynatraceClient, projectFolder string, options Options) (count int, err error) {
+
+	supportedApis := make(map[string]api.Api, len(apis))
+	for _, id := range sortedApiIds(apis) {
+		if unsupportedApis[id] {
+			util.Log.Info("\tSkipping %s, download is not supported for this api", id)
+			continue
+		}
+		supportedApis[id] = apis[id]
+	}
+
+	// each api is listed right before it is downloaded, instead of listing all apis upfront, and only the ids and names
+	// of its configs are kept, so that the payloads of large environments are never held in memory at once
+	return downloadPages(supportedApis, func(a api.Api) valuePages {
+		return func(handle func(values []api.Value) error) error {
+			return rest.ListPages(client, a, handle)
+		}
+	}, client, projectFolder, options)
+}
+
+// DownloadObjects downloads the objects with the given ids, keyed by api id, and writes them as a monaco project
+// into projectFolder. Fails if an api is unknown or can not be downloaded, or if an object does not exist.
+// Returns the number of downloaded configs
+func DownloadObjects(apis map[string]api.Api, client rest.DynatraceClient, projectFolder string, ids map[string][]string, options Options) (count int, err error) {
+
+	selectedApis := make(map[string]api.Api, len(ids))
+	for id := range ids {
+		a, found := apis[id]
+		if !found {
+			return 0, fmt.Errorf("unknown api %s", id)
+		}
+		if unsupportedApis[id] {
+			return 0, fmt.Errorf("download is not supported for api %s", id)
+		}
+		selectedApis[id] = a
+	}
+
+	// objects are listed, as their names can only be taken from the list for all apis
+	listed, err := rest.ListAll(client, selectedApis)
+	if err != nil {
+		return 0, err
+	}
+
+	values := make(map[string][]api.Value, len(ids))
+	for _, apiId := range sortedApiIds(selectedApis) {
+
+		byId := make(map[string]api.Value, len(listed[apiId]))
+		for _, value := range listed[apiId] {
+			byId[value.Id] = value
+		}
+
+		for _, id := range ids[apiId] {
+			value, found := byId[id]
+			if !found {
+				return 0, fmt.Errorf("%s %s does not exist", apiId, id)
+			}
+			values[apiId] = append(values[apiId], value)
+		}
+	}
+
+	return downloadAll(selectedApis, values, client, projectFolder, options)
+}
+
+// ParseObjectIds parses a comma separated list of objects given as api:id, e.g. dashboard:abc-123,alerting-profile:xyz,
+// into the ids of the objects keyed by api id
+func ParseObjectIds(list string) (map[string][]string, error) {
+
+	ids := make(map[string][]string)
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		separator := strings.Index(entry, ":")
+		if separator <= 0 || separator == len(entry)-1 {
+			return nil, fmt.Errorf("invalid object %s, expected api:id", entry)
+		}
+
+		apiId, id := entry[:separator], entry[separator+1:]
+		ids[apiId] = append(ids[apiId], id)
+	}
+
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("no objects given")
+	}
+
+	return ids, nil
+}
+
+// valuePages hands the listed configs of an api page by page to handle
+type valuePages func(handle func(values []api.Value) error) error
+
+// listedValues hands configs which were already listed to handle as a single page
+func listedValues(values []api.Value) valuePages {
+	return func(handle func(values []api.Value) error) error {
+		return handle(values)
+	}
+}
+
+// downloadAll downloads the listed configs of all apis into projectFolder
+func downloadAll(apis map[string]api.Api, values map[string][]api.Value, client rest.DynatraceClient, projectFolder string, options Options) (count int, err error) {
+	return downloadPages(apis, func(a api.Api) valuePages {
+		return listedValues(values[a.GetId()])
+	}, client, projectFolder, options)
+}
+
+// downloadPages downloads the configs of all apis, as they are handed over page by page, into projectFolder
+func downloadPages(apis map[string]api.Api, pages func(a api.Api) valuePages, client rest.DynatraceClient, projectFolder string, options Options) (count int, err error) {
+
+	// management zones are downloaded first, so that the configs of other apis can refer to them
+	ids := sortedApiIds(apis)
+	sort.SliceStable(ids, func(i, j int) bool {
+		return ids[i] == managementZoneApiId && ids[j] != managementZoneApiId
+	})
+
+	var zoneConfigIds map[string]string
+
+	for _, id := range ids {
+
+		downloaded, configIds, err := downloadApi(apis[id], pages(apis[id]), client, filepath.Join(projectFolder, id), zoneConfigIds, options)
+		if err != nil {
+			return count, fmt.Errorf("download of %s failed: %s", id, err)
+		}
+		if id == managementZoneApiId {
+			zoneConfigIds = configIds
+		}
+		count += downloaded
+	}
+
+	return count, nil
+}
+
+// IsSupported checks if the configs of the api can be downloaded
+func IsSupported(apiId string) bool {
+	return !unsupportedApis[apiId]
+}
+
+// DownloadValues downloads the given configs of the api into the folder of the api within projectFolder.
+// Management zone ids are kept as they are, as the management zones are not part of the download
+func DownloadValues(a api.Api, values []api.Value, client rest.DynatraceClient, projectFolder string) (count int, err error) {
+	count, _, err = downloadApi(a, listedValues(values), client, filepath.Join(projectFolder, a.GetId()), nil, Options{})
+	return count, err
+}
+
+// apiDownload is the state of the download of a single api. Payloads, templates and yaml entries are written as soon
+// as a config was downloaded, only the ids and names of the configs and the digests of their templates are kept
+type apiDownload struct {
+	api           api.Api
+	client        rest.DynatraceClient
+	folder        string
+	zoneConfigIds map[string]string
+	options       Options
+
+	configIds map[string]string
+	usedIds   map[string]bool
+	usedNames map[string]bool
+	fileNames map[[sha256.Size]byte]string
+	yaml      *yamlSpool
+	count     int
+}
+
+// downloadApi downloads the configs of the api into apiFolder and returns the config ids by the ids of the configs.
+// All pages are listed before the first config is downloaded, as the order of the configs across all pages
+// determines config ids and file order. Each config is written before the next one is read.
+// Known management zone ids of zoneConfigIds are replaced by references to the management zone configs
+func downloadApi(a api.Api, pages valuePages, client rest.DynatraceClient, apiFolder string,
+	zoneConfigIds map[string]string, options Options) (count int, configIds map[string]string, err error) {
+
+	download := &apiDownload{
+		api:           a,
+		client:        client,
+		folder:        apiFolder,
+		zoneConfigIds: zoneConfigIds,
+		options:       options,
+		configIds:     make(map[string]string),
+		usedIds:       make(map[string]bool),
+		usedNames:     make(map[string]bool),
+		fileNames:     make(map[[sha256.Size]byte]string),
+	}
+
+	values := make([]api.Value, 0)
+	err = pages(func(page []api.Value) error {
+		values = append(values, page...)
+		return nil
+	})
+	if err != nil {
+		return 0, download.configIds, err
+	}
+
+	if len(values) == 0 {
+		util.Log.Debug("\tNo configs found for %s", a.GetId())
+		return 0, download.configIds, nil
+	}
+
+	util.Log.Info("\tDownloading %d configs of %s...", len(values), a.GetId())
+
+	err = os.MkdirAll(apiFolder, 0777)
+	if err != nil {
+		return 0, download.configIds, err
+	}
+
+	download.yaml, err = newYamlSpool()
+	if err != nil {
+		return 0, download.configIds, err
+	}
+
+	// the order of listed configs is not guaranteed by the apis, but determines config ids and file order
+	for _, value := range sortedValues(values) {
+		err = download.downloadValue(value)
+		if err != nil {
+			download.yaml.discard()
+			return download.count, download.configIds, err
+		}
+	}
+
+	return download.count, download.configIds, download.yaml.finish(filepath.Join(apiFolder, a.GetId()+".yaml"))
+}
+
+// downloadValue downloads a single config and writes its template and yaml entries
+func (d *apiDownload) downloadValue(value api.Value) error {
+
+	a, options := d.api, d.options
+
+	if d.usedNames[value.Name] {
+		util.Log.Warn("\tSkipping %s (%s) of %s, a config with the same name was already downloaded", value.Name, value.Id, a.GetId())
+		return nil
+	}
+	d.usedNames[value.Name] = true
+
+	payload, err := d.client.ReadById(a, value.Id)
+	if err != nil {
+		return err
+	}
+
+	var pin string
+	if options.Pin {
+		pin, err = PayloadHash(a.GetId(), payload, options.FieldOverrides)
+		if err != nil {
+			return fmt.Errorf("config %s (%s) could not be pinned: %s", value.Name, value.Id, err)
+		}
+	}
+
+	var references *zoneReferences
+	if d.zoneConfigIds != nil {
+		references = newZoneReferences(a.GetId(), d.zoneConfigIds)
+	}
+
+	configId := uniqueConfigId(value.Name, d.usedIds)
+	d.configIds[value.Id] = configId
+
+	var ownerRule *OwnerRule
+	if a.GetId() == dashboardApiId {
+		payload, ownerRule, err = rewriteOwner(payload, options.OwnerRules)
+		if err != nil {
+			return fmt.Errorf("owner of config %s (%s) could not be rewritten: %s", value.Name, value.Id, err)
+		}
+	}
+
+	var template []byte
+	var tiles []tileFragment
+	if a.GetId() == dashboardApiId && options.SplitDashboardTiles > 0 {
+		template, tiles, err = toSplitDashboardTemplate(payload, value.Name, references, options.FieldOverrides, configId+"-tiles", options.SplitDashboardTiles)
+	} else {
+		template, err = toTemplate(a.GetId(), payload, value.Name, references, options.FieldOverrides)
+	}
+	if err != nil {
+		return fmt.Errorf("config %s (%s) could not be converted: %s", value.Name, value.Id, err)
+	}
+
+	template, err = recordProvenance(d.folder, configId, template, newProvenance(a, value, options), options.Provenance)
+	if err != nil {
+		return fmt.Errorf("provenance of config %s (%s) could not be written: %s", value.Name, value.Id, err)
+	}
+
+	err = writeTileFragments(d.folder, tiles)
+	if err != nil {
+		return err
+	}
+
+	// configs which only differ in their name share one template, just like when they were deployed from one
+	digest := sha256.Sum256(template)
+	fileName, shared := d.fileNames[digest]
+	if shared {
+		util.Log.Debug("\t%s shares the template %s", value.Name, fileName)
+	} else {
+		fileName = configId + ".json"
+		d.fileNames[digest] = fileName
+
+		err = ioutil.WriteFile(filepath.Join(d.folder, fileName), template, 0664)
+		if err != nil {
+			return err
+		}
+	}
+
+	ownerProperties, ownerSections := ownerProperties(ownerRule, configId)
+	properties := append([]map[string]string{{"name": value.Name}}, references.variables()...)
+	properties = append(properties, ownerProperties...)
+
+	// the pin only holds for the object in the environment it was downloaded from
+	var pinSections yaml.MapSlice
+	if pin != "" && options.Environment != "" {
+		pinSections = yaml.MapSlice{{Key: configId + "." + options.Environment, Value: []map[string]string{{pinnedRemoteParameter: pin}}}}
+	} else if pin != "" {
+		properties = append(properties, map[string]string{pinnedRemoteParameter: pin})
+	}
+
+	sections := append(yaml.MapSlice{{Key: configId, Value: properties}}, pinSections...)
+	err = d.yaml.add(configId, fileName, append(sections, ownerSections...))
+	if err != nil {
+		return err
+	}
+
+	d.count++
+	return nil
+}
+
+// toTemplate strips all server managed and volatile fields from the payload and formats it with sorted keys.
+// The name of the config is replaced by the name variable, management zone ids by the variables of references.
+// Numbers are kept as they are, to not change their formatting
+func toTemplate(apiId string, payload []byte, name string, references *zoneReferences, fields api.FieldOverrides) ([]byte, error) {
+
+	content, err := toTemplateContent(apiId, payload, name, references, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeTemplate(content, references)
+}
+
+// toTemplateContent decodes the payload and strips and replaces its fields like toTemplate
+func toTemplateContent(apiId string, payload []byte, name string, references *zoneReferences, fields api.FieldOverrides) (map[string]interface{}, error) {
+
+	var content map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&content)
+	if err != nil {
+		return nil, err
+	}
+
+	api.RemoveFields(content, fields.ServerManagedFields(apiId))
+	removeVolatileFields(content)
+	references.replace(content)
+	if isTemplateSafe(name) {
+		replaceName(content, name)
+	}
+
+	return content, nil
+}
+
+// encodeTemplate formats the content of a template with sorted keys
+func encodeTemplate(content interface{}, references *zoneReferences) ([]byte, error) {
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(content)
+	if err != nil {
+		return nil, err
+	}
+
+	return references.restoreNumbers(buffer.Bytes()), nil
+}
+
+func removeVolatileFields(value interface{}) {
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if volatileFields[key] {
+				delete(typed, key)
+			} else {
+				removeVolatileFields(child)
+			}
+		}
+	case []interface{}:
+		for _, child := range typed {
+			removeVolatileFields(child)
+		}
+	}
+}
+
+// replaceName replaces all fields of the object and its nested objects with the value name by the name variable.
+// Lists are not searched, as their entries (e.g. rule values) match the name by coincidence rather than by design
+func replaceName(object map[string]interface{}, name string) {
+
+	for key, child := range object {
+		switch typed := child.(type) {
+		case string:
+			if typed == name {
+				object[key] = "{{ .name }}"
+			}
+		case map[string]interface{}:
+			replaceName(typed, name)
+		}
+	}
+}
+
+// isTemplateSafe checks if the name can be rendered into a json template as it is, which is
+// not the case for names which have to be escaped in json
+func isTemplateSafe(name string) bool {
+
+	if name == "" {
+		return false
+	}
+
+	buffer := bytes.Buffer{}
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(name)
+	return err == nil && buffer.String() == `"`+name+`"`+"\n"
+}
+
+// sortedValues returns a copy of values sorted by name and id
+func sortedValues(values []api.Value) []api.Value {
+
+	sorted := make([]api.Value, len(values))
+	copy(sorted, values)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		return sorted[i].Id < sorted[j].Id
+	})
+
+	return sorted
+}
+
+// uniqueConfigId derives a config id from the given name, which can safely be used as file name and yaml key
+func uniqueConfigId(name string, usedIds map[string]bool) string {
+
+	base := invalidConfigIdCharacters.ReplaceAllString(name, "_")
+	if base == "" {
+		base = "config"
+	}
+	if util.IsReservedFileName(base) {
+		base += "_"
+	}
+
+	id := base
+	for i := 1; usedIds[id]; i++ {
+		id = fmt.Sprintf("%s_%d", base, i)
+	}
+	usedIds[id] = true
+
+	return id
+}
+
+func sortedApiIds(apis map[string]api.Api) []string {
+
+	ids := make([]string, 0, len(apis))
+	for id := range apis {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return ids
+}
@@ -0,0 +1,488 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/compare"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/project"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+var testDevEnvironment = environment.NewEnvironment("development", "Dev", "", "https://url/to/dev/environment", "DEV")
+
+// testClient only serves reads, writes are not expected in these tests
+type testClient struct {
+	rest.DynatraceClient
+	values   map[string][]api.Value
+	payloads map[string]string
+}
+
+func (c *testClient) List(a api.Api) ([]api.Value, error) {
+	return c.values[a.GetId()], nil
+}
+
+func (c *testClient) ReadById(a api.Api, id string) ([]byte, error) {
+	payload, found := c.payloads[id]
+	if !found {
+		return nil, fmt.Errorf("%s not found", id)
+	}
+	return []byte(payload), nil
+}
+
+func TestDownloadConfigsWritesLoadableProject(t *testing.T) {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone":  {{Id: "1", Name: "my zone"}, {Id: "2", Name: "my.zone"}},
+			"alerting-profile": {{Id: "3", Name: "profile"}},
+		},
+		payloads: map[string]string{
+			"1": `{"id": "1", "name": "my zone", "rules": []}`,
+			"2": `{"id": "2", "name": "my.zone", "rules": []}`,
+			"3": `{"metadata": {"clusterVersion": "1.0"}, "id": "3", "displayName": "profile"}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone":  api.NewApi("management-zone", "/api/config/v1/managementZones"),
+		"alerting-profile": api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"),
+		"extension":        api.NewApi("extension", "/api/config/v1/extensions"),
+	}
+
+	root := t.TempDir()
+	count, err := DownloadConfigs(apis, client, filepath.Join(root, "project"), Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+
+	template, err := ioutil.ReadFile(filepath.Join(root, "project", "alerting-profile", "profile.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"displayName\": \"{{ .name }}\"\n}\n")
+
+	loaded, err := project.NewProject(filepath.Join(root, "project"), apis, root, util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.GetConfigs()), 3)
+
+	names := make(map[string]bool)
+	for _, config := range loaded.GetConfigs() {
+		name, err := config.GetObjectNameForEnvironment(testDevEnvironment, map[string]api.DynatraceEntity{})
+		assert.NilError(t, err)
+		names[config.GetType()+"/"+name] = true
+	}
+	assert.DeepEqual(t, names, map[string]bool{
+		"management-zone/my zone":  true,
+		"management-zone/my.zone":  true,
+		"alerting-profile/profile": true,
+	})
+}
+
+func TestDownloadConfigsSkipsDuplicateNames(t *testing.T) {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone": {{Id: "1", Name: "zone"}, {Id: "2", Name: "zone"}},
+		},
+		payloads: map[string]string{
+			"1": `{"name": "zone"}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	}
+
+	count, err := DownloadConfigs(apis, client, t.TempDir(), Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 1)
+}
+
+func TestUniqueConfigId(t *testing.T) {
+
+	used := make(map[string]bool)
+
+	assert.Equal(t, uniqueConfigId("my dashboard", used), "my_dashboard")
+	assert.Equal(t, uniqueConfigId("my.dashboard", used), "my_dashboard_1")
+	assert.Equal(t, uniqueConfigId("", used), "config")
+	assert.Equal(t, uniqueConfigId("con", used), "con_")
+	assert.Equal(t, uniqueConfigId("COM1", used), "COM1_")
+}
+
+func TestDownloadConfigsIsDeterministic(t *testing.T) {
+
+	payloads := map[string]string{
+		"1": `{"name": "b", "rules": [{"modificationTimestamp": 1600000000000, "enabled": true}], "lastModified": 1600000000000}`,
+		"2": `{"name": "a", "value": 12345678901234567890, "description": "</b>"}`,
+	}
+
+	apis := map[string]api.Api{
+		"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	}
+
+	download := func(values []api.Value) (yamlFile string, template string) {
+		folder := t.TempDir()
+		_, err := DownloadConfigs(apis, &testClient{values: map[string][]api.Value{"management-zone": values}, payloads: payloads}, folder, Options{})
+		assert.NilError(t, err)
+
+		yamlContent, err := ioutil.ReadFile(filepath.Join(folder, "management-zone", "management-zone.yaml"))
+		assert.NilError(t, err)
+		templateContent, err := ioutil.ReadFile(filepath.Join(folder, "management-zone", "b.json"))
+		assert.NilError(t, err)
+		return string(yamlContent), string(templateContent)
+	}
+
+	firstYaml, firstTemplate := download([]api.Value{{Id: "1", Name: "b"}, {Id: "2", Name: "a"}})
+	secondYaml, secondTemplate := download([]api.Value{{Id: "2", Name: "a"}, {Id: "1", Name: "b"}})
+
+	assert.Equal(t, firstYaml, secondYaml)
+	assert.Equal(t, firstTemplate, secondTemplate)
+	assert.Equal(t, firstTemplate, "{\n  \"name\": \"{{ .name }}\",\n  \"rules\": [\n    {\n      \"enabled\": true\n    }\n  ]\n}\n")
+}
+
+func TestToTemplateKeepsNumbersAndSpecialCharacters(t *testing.T) {
+
+	template, err := toTemplate("management-zone", []byte(`{"value": 12345678901234567890, "description": "</b>"}`), "zone", nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"description\": \"</b>\",\n  \"value\": 12345678901234567890\n}\n")
+}
+
+func TestToTemplateReplacesName(t *testing.T) {
+
+	template, err := toTemplate("dashboard", []byte(`{"dashboardMetadata": {"name": "my dashboard"}, "tiles": [{"name": "my dashboard"}]}`), "my dashboard", nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"dashboardMetadata\": {\n    \"name\": \"{{ .name }}\"\n  },\n  \"tiles\": [\n    {\n      \"name\": \"my dashboard\"\n    }\n  ]\n}\n")
+
+	template, err = toTemplate("management-zone", []byte(`{"name": "my \"zone\""}`), `my "zone"`, nil, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"name\": \"my \\\"zone\\\"\"\n}\n")
+}
+
+func TestDownloadConfigsSharesTemplatesOfConfigsOnlyDifferingInName(t *testing.T) {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone": {{Id: "1", Name: "zone a"}, {Id: "2", Name: "zone b"}, {Id: "3", Name: "zone c"}},
+		},
+		payloads: map[string]string{
+			"1": `{"id": "1", "name": "zone a", "rules": []}`,
+			"2": `{"id": "2", "name": "zone b", "rules": []}`,
+			"3": `{"id": "3", "name": "zone c", "rules": [{"enabled": true}]}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	}
+
+	folder := t.TempDir()
+	count, err := DownloadConfigs(apis, client, folder, Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+
+	yamlContent, err := ioutil.ReadFile(filepath.Join(folder, "management-zone", "management-zone.yaml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(yamlContent), "- zone_b: zone_a.json\n"), string(yamlContent))
+	assert.Assert(t, strings.Contains(string(yamlContent), "- zone_c: zone_c.json\n"), string(yamlContent))
+
+	_, err = os.Stat(filepath.Join(folder, "management-zone", "zone_b.json"))
+	assert.Assert(t, os.IsNotExist(err))
+}
+
+func TestDownloadConfigsReplacesManagementZoneIdsByReferences(t *testing.T) {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone":    {{Id: "-101", Name: "zone a"}, {Id: "102", Name: "zone b"}},
+			"alerting-profile":   {{Id: "profile-id", Name: "profile"}},
+			"dashboard":          {{Id: "dashboard-id", Name: "dashboard"}},
+			"maintenance-window": {{Id: "window-id", Name: "window"}},
+		},
+		payloads: map[string]string{
+			"-101":       `{"id": "-101", "name": "zone a", "rules": []}`,
+			"102":        `{"id": "102", "name": "zone b", "rules": []}`,
+			"profile-id": `{"displayName": "profile", "managementZoneId": -101}`,
+			"dashboard-id": `{"dashboardMetadata": {"name": "dashboard", "dashboardFilter": {"managementZone": {"id": "102", "name": "zone b"}}},
+				"tiles": [{"tileFilter": {"managementZone": {"id": "-101", "name": "zone a"}}}, {"tileFilter": {"managementZone": {"id": "999", "name": "unknown"}}}]}`,
+			"window-id": `{"name": "window", "scope": {"matches": [{"mzId": "102"}]}}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone":    api.NewApi("management-zone", "/api/config/v1/managementZones"),
+		"alerting-profile":   api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"),
+		"dashboard":          api.NewApi("dashboard", "/api/config/v1/dashboards"),
+		"maintenance-window": api.NewApi("maintenance-window", "/api/config/v1/maintenanceWindows"),
+	}
+
+	root := t.TempDir()
+	count, err := DownloadConfigs(apis, client, filepath.Join(root, "project"), Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 5)
+
+	yamlContent, err := ioutil.ReadFile(filepath.Join(root, "project", "dashboard", "dashboard.yaml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(yamlContent), "- managementZoneId: management-zone/zone_b.id\n"), string(yamlContent))
+	assert.Assert(t, strings.Contains(string(yamlContent), "- managementZoneName2: management-zone/zone_a.name\n"), string(yamlContent))
+
+	loaded, err := project.NewProject(filepath.Join(root, "project"), apis, root, util.NewFileReader())
+	assert.NilError(t, err)
+
+	dict := map[string]api.DynatraceEntity{
+		util.ReplacePathSeparators("project/management-zone/zone_a"): {Id: "-201", Name: "renamed a"},
+		util.ReplacePathSeparators("project/management-zone/zone_b"): {Id: "202", Name: "renamed b"},
+	}
+
+	rendered := make(map[string]string)
+	for _, config := range loaded.GetConfigs() {
+		if config.GetType() == "management-zone" {
+			continue
+		}
+		for _, zone := range loaded.GetConfigs() {
+			if zone.GetType() == "management-zone" && zone.GetId() == "zone_b" {
+				assert.Equal(t, config.GetType() != "alerting-profile", config.HasDependencyOn(zone), config.GetType())
+			}
+		}
+		content, err := config.GetConfigForEnvironment(testDevEnvironment, dict)
+		assert.NilError(t, err)
+		rendered[config.GetType()] = strings.Join(strings.Fields(string(content)), "")
+	}
+
+	assert.Equal(t, rendered["alerting-profile"], `{"displayName":"profile","managementZoneId":-201}`)
+	assert.Equal(t, rendered["maintenance-window"], `{"name":"window","scope":{"matches":[{"mzId":"202"}]}}`)
+	assert.Equal(t, rendered["dashboard"], `{"dashboardMetadata":{"dashboardFilter":{"managementZone":{"id":"202","name":"renamedb"}},"name":"dashboard"},`+
+		`"tiles":[{"tileFilter":{"managementZone":{"id":"-201","name":"renameda"}}},{"tileFilter":{"managementZone":{"id":"999","name":"unknown"}}}]}`)
+}
+
+func TestDownloadValuesKeepsManagementZoneIds(t *testing.T) {
+
+	client := &testClient{
+		payloads: map[string]string{
+			"profile-id": `{"displayName": "profile", "managementZoneId": -101}`,
+		},
+	}
+
+	a := api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+
+	folder := t.TempDir()
+	_, err := DownloadValues(a, []api.Value{{Id: "profile-id", Name: "profile"}}, client, folder)
+	assert.NilError(t, err)
+
+	template, err := ioutil.ReadFile(filepath.Join(folder, "alerting-profile", "profile.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(template), "{\n  \"displayName\": \"{{ .name }}\",\n  \"managementZoneId\": -101\n}\n")
+}
+
+func TestDownloadObjectsOnlyDownloadsGivenObjects(t *testing.T) {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"dashboard":        {{Id: "abc-123", Name: "overview"}, {Id: "def-456", Name: "other"}},
+			"alerting-profile": {{Id: "xyz", Name: "profile"}},
+		},
+		payloads: map[string]string{
+			"abc-123": `{"dashboardMetadata": {"name": "overview"}, "tiles": []}`,
+			"def-456": `{"dashboardMetadata": {"name": "other"}, "tiles": []}`,
+			"xyz":     `{"displayName": "profile"}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"dashboard":        api.NewApi("dashboard", "/api/config/v1/dashboards"),
+		"alerting-profile": api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"),
+		"management-zone":  api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	}
+
+	projectFolder := filepath.Join(t.TempDir(), "project")
+	count, err := DownloadObjects(apis, client, projectFolder, map[string][]string{"dashboard": {"abc-123"}}, Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 1)
+
+	_, err = os.Stat(filepath.Join(projectFolder, "dashboard", "overview.json"))
+	assert.NilError(t, err)
+	_, err = os.Stat(filepath.Join(projectFolder, "dashboard", "other.json"))
+	assert.Assert(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(projectFolder, "alerting-profile"))
+	assert.Assert(t, os.IsNotExist(err))
+
+	_, err = DownloadObjects(apis, client, projectFolder, map[string][]string{"dashboard": {"missing"}}, Options{})
+	assert.ErrorContains(t, err, "dashboard missing does not exist")
+
+	_, err = DownloadObjects(apis, client, projectFolder, map[string][]string{"unknown": {"abc-123"}}, Options{})
+	assert.ErrorContains(t, err, "unknown api unknown")
+}
+
+func TestParseObjectIds(t *testing.T) {
+
+	ids, err := ParseObjectIds("dashboard:abc-123, alerting-profile:xyz,dashboard:def-456")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ids, map[string][]string{
+		"dashboard":        {"abc-123", "def-456"},
+		"alerting-profile": {"xyz"},
+	})
+
+	_, err = ParseObjectIds("dashboard")
+	assert.ErrorContains(t, err, "expected api:id")
+
+	_, err = ParseObjectIds("dashboard:")
+	assert.ErrorContains(t, err, "expected api:id")
+
+	_, err = ParseObjectIds(",")
+	assert.ErrorContains(t, err, "no objects given")
+}
+
+func TestDownloadConfigsSplitsLargeDashboards(t *testing.T) {
+
+	dashboard := `{"dashboardMetadata": {"name": "overview", "dashboardFilter": {"managementZone": {"id": "-101", "name": "zone"}}},
+		"tiles": [{"name": "Markdown", "tileType": "MARKDOWN", "markdown": "# Overview"}, {"name": "Hosts", "tileType": "HOSTS"},
+		{"tileType": "DATA_EXPLORER", "tileFilter": {"managementZone": {"id": "-101", "name": "zone"}}}]}`
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone": {{Id: "-101", Name: "zone"}},
+			"dashboard":       {{Id: "large", Name: "overview"}, {Id: "small", Name: "small"}},
+		},
+		payloads: map[string]string{
+			"-101":  `{"id": "-101", "name": "zone", "rules": []}`,
+			"large": dashboard,
+			"small": `{"dashboardMetadata": {"name": "small"}, "tiles": [{"name": "Markdown", "tileType": "MARKDOWN"}]}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+		"dashboard":       api.NewApi("dashboard", "/api/config/v1/dashboards"),
+	}
+
+	root := t.TempDir()
+	count, err := DownloadConfigs(apis, client, filepath.Join(root, "project"), Options{SplitDashboardTiles: 2})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+
+	dashboardFolder := filepath.Join(root, "project", "dashboard")
+	template, err := ioutil.ReadFile(filepath.Join(dashboardFolder, "overview.json"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(template), `{{ include "overview-tiles/001-markdown.json" . }},`), string(template))
+	assert.Assert(t, strings.Contains(string(template), `{{ include "overview-tiles/003-data_explorer.json" . }}`), string(template))
+
+	tile, err := ioutil.ReadFile(filepath.Join(dashboardFolder, "overview-tiles", "002-hosts.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(tile), "{\n  \"name\": \"Hosts\",\n  \"tileType\": \"HOSTS\"\n}\n")
+
+	_, err = os.Stat(filepath.Join(dashboardFolder, "small-tiles"))
+	assert.Assert(t, os.IsNotExist(err), "dashboards with few tiles must not be split")
+
+	loaded, err := project.NewProject(filepath.Join(root, "project"), apis, root, util.NewFileReader())
+	assert.NilError(t, err)
+
+	dict := map[string]api.DynatraceEntity{
+		util.ReplacePathSeparators("project/management-zone/zone"): {Id: "-201", Name: "zone"},
+	}
+
+	rendered := false
+	for _, config := range loaded.GetConfigs() {
+		if config.GetId() != "overview" {
+			continue
+		}
+		rendered = true
+
+		payload, err := config.GetConfigForEnvironment(testDevEnvironment, dict)
+		assert.NilError(t, err)
+
+		expected := strings.ReplaceAll(dashboard, "-101", "-201")
+		differences, err := compare.DiffPayloads("dashboard", []byte(expected), []byte(payload), nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(differences), 0, "%v", differences)
+	}
+	assert.Assert(t, rendered, "the split dashboard was not loaded")
+}
+
+// pagedTestClient hands the values of each api over in pages, and calls beforeNext before each further page
+type pagedTestClient struct {
+	testClient
+	pages        map[string][][]api.Value
+	beforeNext   func(previous []api.Value)
+	pagesHandled int
+}
+
+func (c *pagedTestClient) ListPages(a api.Api, handle func(values []api.Value) error) error {
+	for i, page := range c.pages[a.GetId()] {
+		if i > 0 {
+			c.beforeNext(c.pages[a.GetId()][i-1])
+		}
+
+		err := handle(page)
+		if err != nil {
+			return err
+		}
+		c.pagesHandled++
+	}
+	return nil
+}
+
+func TestDownloadConfigsSortsConfigsAcrossPages(t *testing.T) {
+
+	folder := t.TempDir()
+	apiFolder := filepath.Join(folder, "alerting-profile")
+
+	client := &pagedTestClient{
+		testClient: testClient{
+			payloads: map[string]string{
+				"1": `{"displayName": "c", "severityRules": [1]}`,
+				"2": `{"displayName": "a", "severityRules": [2]}`,
+				"3": `{"displayName": "b", "severityRules": [3]}`,
+			},
+		},
+		pages: map[string][][]api.Value{
+			"alerting-profile": {{{Id: "1", Name: "c"}, {Id: "2", Name: "a"}}, {{Id: "3", Name: "b"}}},
+		},
+	}
+
+	// the order of all configs is only known once all pages were listed
+	client.beforeNext = func(previous []api.Value) {
+		_, err := os.Stat(apiFolder)
+		assert.Assert(t, os.IsNotExist(err), "configs were written before all pages were listed")
+	}
+
+	apis := map[string]api.Api{
+		"alerting-profile": api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"),
+	}
+
+	count, err := DownloadConfigs(apis, client, folder, Options{})
+	assert.NilError(t, err)
+	assert.Equal(t, count, 3)
+	assert.Equal(t, client.pagesHandled, 2)
+
+	yamlContent, err := ioutil.ReadFile(filepath.Join(apiFolder, "alerting-profile.yaml"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(yamlContent), "config:\n- a: a.json\n- b: b.json\n- c: c.json\na:\n- name: a\nb:\n- name: b\nc:\n- name: c\n")
+
+	// the spooled yaml entries are removed once the yaml file was written
+	files, err := ioutil.ReadDir(apiFolder)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 4)
+}
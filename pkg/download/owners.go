@@ -0,0 +1,198 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// OwnerStrip removes the owner from the downloaded dashboard, it is owned by the user of the token it is deployed with
+	OwnerStrip = "strip"
+
+	// OwnerReplace replaces the owner of the downloaded dashboard by the owner of the rule
+	OwnerReplace = "replace"
+
+	// OwnerVariable moves the owner into the dashboardOwner parameter of the config, set to the owner of the rule
+	// and to the owners of the rule per environment
+	OwnerVariable = "variable"
+)
+
+// dashboardOwnerParameter is the parameter of configs overriding the owner in the dashboard metadata
+const dashboardOwnerParameter = "dashboardOwner"
+
+// OwnerRule rewrites the owners of downloaded dashboards matching the regular expression Match, or all owners if
+// Match is empty, so that downloads do not leak the email addresses of users into projects
+type OwnerRule struct {
+	Match        string            `yaml:"match"`
+	Action       string            `yaml:"action"`
+	Owner        string            `yaml:"owner"`
+	Environments map[string]string `yaml:"environments"`
+
+	pattern *regexp.Regexp
+}
+
+type ownerRulesFile struct {
+	Rules []OwnerRule `yaml:"rules"`
+}
+
+// LoadOwnerRules reads the owner rules from a yaml file. The first rule matching the owner of a dashboard applies,
+// owners matching no rule are kept. Nothing is loaded if file is empty
+func LoadOwnerRules(file string, fileReader util.FileReader) ([]OwnerRule, error) {
+
+	if file == "" {
+		return nil, nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("owner rules file %s could not be read: %s", file, err)
+	}
+
+	var parsed ownerRulesFile
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("owner rules file %s is invalid: %s", file, err)
+	}
+
+	for i := range parsed.Rules {
+		err = parsed.Rules[i].compile()
+		if err != nil {
+			return nil, fmt.Errorf("rule %d of owner rules file %s is invalid: %s", i+1, file, err)
+		}
+	}
+
+	return parsed.Rules, nil
+}
+
+func (r *OwnerRule) compile() error {
+
+	pattern, err := regexp.Compile("^(?:" + r.Match + ")$")
+	if err != nil {
+		return fmt.Errorf("match %s is no valid regular expression: %s", r.Match, err)
+	}
+	r.pattern = pattern
+
+	switch r.Action {
+	case OwnerStrip:
+		if r.Owner != "" || len(r.Environments) > 0 {
+			return fmt.Errorf("action %s takes no owner", OwnerStrip)
+		}
+	case OwnerReplace:
+		if strings.TrimSpace(r.Owner) == "" || len(r.Environments) > 0 {
+			return fmt.Errorf("action %s needs an owner and takes no environments", OwnerReplace)
+		}
+	case OwnerVariable:
+		if strings.TrimSpace(r.Owner) == "" && len(r.Environments) == 0 {
+			return fmt.Errorf("action %s needs an owner or owners per environment", OwnerVariable)
+		}
+	default:
+		return fmt.Errorf("unknown action '%s', supported are %s, %s and %s", r.Action, OwnerStrip, OwnerReplace, OwnerVariable)
+	}
+
+	return nil
+}
+
+func (r *OwnerRule) matches(owner string) bool {
+	return r.Match == "" || r.pattern.MatchString(owner)
+}
+
+// rewriteOwner applies the first rule matching the owner of the dashboard payload and returns the rewritten payload
+// and the applied rule. The payload is returned as it is, if it has no owner or no rule matches it
+func rewriteOwner(payload []byte, rules []OwnerRule) ([]byte, *OwnerRule, error) {
+
+	if len(rules) == 0 {
+		return payload, nil, nil
+	}
+
+	var content map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadata, isObject := content["dashboardMetadata"].(map[string]interface{})
+	if !isObject {
+		return payload, nil, nil
+	}
+
+	owner, isString := metadata["owner"].(string)
+	if !isString {
+		return payload, nil, nil
+	}
+
+	for i := range rules {
+
+		rule := &rules[i]
+		if !rule.matches(owner) {
+			continue
+		}
+
+		if rule.Action == OwnerReplace {
+			metadata["owner"] = rule.Owner
+		} else {
+			delete(metadata, "owner")
+		}
+
+		rewritten, err := json.Marshal(content)
+		return rewritten, rule, err
+	}
+
+	return payload, nil, nil
+}
+
+// ownerProperties returns the properties of the config and the sections per environment, which the rule adds
+// to the yaml of a downloaded dashboard. The sections are sorted by environment
+func ownerProperties(rule *OwnerRule, configId string) ([]map[string]string, yaml.MapSlice) {
+
+	if rule == nil || rule.Action != OwnerVariable {
+		return nil, nil
+	}
+
+	var properties []map[string]string
+	if rule.Owner != "" {
+		properties = append(properties, map[string]string{dashboardOwnerParameter: rule.Owner})
+	}
+
+	environments := make([]string, 0, len(rule.Environments))
+	for environment := range rule.Environments {
+		environments = append(environments, environment)
+	}
+	sort.Strings(environments)
+
+	sections := yaml.MapSlice{}
+	for _, environment := range environments {
+		sections = append(sections, yaml.MapItem{
+			Key:   configId + "." + environment,
+			Value: []map[string]string{{dashboardOwnerParameter: rule.Environments[environment]}},
+		})
+	}
+
+	return properties, sections
+}
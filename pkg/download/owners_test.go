@@ -0,0 +1,156 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func loadTestOwnerRules(t *testing.T, content string) ([]OwnerRule, error) {
+
+	file := filepath.Join(t.TempDir(), "owners.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0664))
+
+	return LoadOwnerRules(file, util.NewFileReader())
+}
+
+func ownerTestDownload(t *testing.T, rules []OwnerRule) (template string, yamlContent string) {
+
+	client := &testClient{
+		values: map[string][]api.Value{"dashboard": {{Id: "1", Name: "overview"}}},
+		payloads: map[string]string{
+			"1": `{"id": "1", "dashboardMetadata": {"name": "overview", "owner": "jane.doe@example.com", "shared": true}, "tiles": [{"bounds": {"top": 0}}]}`,
+		},
+	}
+	apis := map[string]api.Api{"dashboard": api.NewApi("dashboard", "/api/config/v1/dashboards")}
+
+	folder := t.TempDir()
+	_, err := DownloadConfigs(apis, client, folder, Options{OwnerRules: rules})
+	assert.NilError(t, err)
+
+	templateContent, err := ioutil.ReadFile(filepath.Join(folder, "dashboard", "overview.json"))
+	assert.NilError(t, err)
+	configContent, err := ioutil.ReadFile(filepath.Join(folder, "dashboard", "dashboard.yaml"))
+	assert.NilError(t, err)
+
+	return string(templateContent), string(configContent)
+}
+
+func TestDownloadConfigsStripsOwners(t *testing.T) {
+
+	rules, err := loadTestOwnerRules(t, `
+rules:
+  - match: ".*@example\\.com"
+    action: strip
+`)
+	assert.NilError(t, err)
+
+	template, yamlContent := ownerTestDownload(t, rules)
+
+	assert.Assert(t, !strings.Contains(template, "jane.doe"), template)
+	assert.Assert(t, strings.Contains(template, `"top": 0`), template)
+	assert.Assert(t, !strings.Contains(yamlContent, dashboardOwnerParameter), yamlContent)
+}
+
+func TestDownloadConfigsReplacesOwners(t *testing.T) {
+
+	rules, err := loadTestOwnerRules(t, `
+rules:
+  - action: replace
+    owner: sre-team@example.com
+`)
+	assert.NilError(t, err)
+
+	template, _ := ownerTestDownload(t, rules)
+
+	assert.Assert(t, strings.Contains(template, `"owner": "sre-team@example.com"`), template)
+}
+
+func TestDownloadConfigsMovesOwnersIntoParametersPerEnvironment(t *testing.T) {
+
+	rules, err := loadTestOwnerRules(t, `
+rules:
+  - match: someone@other\.com
+    action: strip
+  - match: ".*@example\\.com"
+    action: variable
+    owner: sre-team@example.com
+    environments:
+      production: production-sre@example.com
+      development: dev-sre@example.com
+`)
+	assert.NilError(t, err)
+
+	template, yamlContent := ownerTestDownload(t, rules)
+
+	assert.Assert(t, !strings.Contains(template, "owner"), template)
+	assert.Equal(t, yamlContent, `config:
+- overview: overview.json
+overview:
+- name: overview
+- dashboardOwner: sre-team@example.com
+overview.development:
+- dashboardOwner: dev-sre@example.com
+overview.production:
+- dashboardOwner: production-sre@example.com
+`)
+}
+
+func TestDownloadConfigsKeepsOwnersMatchingNoRule(t *testing.T) {
+
+	rules, err := loadTestOwnerRules(t, `
+rules:
+  - match: ".*@other\\.com"
+    action: strip
+`)
+	assert.NilError(t, err)
+
+	template, _ := ownerTestDownload(t, rules)
+
+	assert.Assert(t, strings.Contains(template, `"owner": "jane.doe@example.com"`), template)
+}
+
+func TestLoadOwnerRulesFailsOnInvalidRules(t *testing.T) {
+
+	_, err := loadTestOwnerRules(t, "rules:\n  - action: rename\n")
+	assert.ErrorContains(t, err, "unknown action 'rename'")
+
+	_, err = loadTestOwnerRules(t, "rules:\n  - action: replace\n")
+	assert.ErrorContains(t, err, "needs an owner")
+
+	_, err = loadTestOwnerRules(t, "rules:\n  - action: strip\n    match: \"[\"\n")
+	assert.ErrorContains(t, err, "no valid regular expression")
+
+	_, err = loadTestOwnerRules(t, "owners:\n  - action: strip\n")
+	assert.ErrorContains(t, err, "is invalid")
+}
+
+func TestLoadOwnerRulesWithoutFile(t *testing.T) {
+
+	rules, err := LoadOwnerRules("", util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 0)
+}
@@ -0,0 +1,55 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// pinnedRemoteParameter is the parameter of configs holding the hash of the object they are pinned to
+const pinnedRemoteParameter = "pinnedRemote"
+
+// PayloadHash returns the hash configs are pinned to for the payload of an object of the api. Fields which are
+// ignored on diff and volatile fields do not contribute to it, so that the hash only changes with the configuration
+func PayloadHash(apiId string, payload []byte, fields api.FieldOverrides) (string, error) {
+
+	var content interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&content)
+	if err != nil {
+		return "", err
+	}
+
+	api.RemoveFields(content, fields.DiffIgnoredFields(apiId))
+	removeVolatileFields(content)
+
+	// maps are encoded with sorted keys, so that the hash does not depend on the order of fields
+	canonical, err := json.Marshal(content)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(canonical)), nil
+}
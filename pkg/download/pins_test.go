@@ -0,0 +1,80 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func TestPayloadHashIgnoresFieldOrderAndServerChanges(t *testing.T) {
+
+	first, err := PayloadHash("dashboard", []byte(`{"id": "1", "dashboardMetadata": {"name": "a", "owner": "jane"}, "tiles": [1.50]}`), nil)
+	assert.NilError(t, err)
+
+	second, err := PayloadHash("dashboard", []byte(`{"tiles": [1.50], "dashboardMetadata": {"owner": "john", "name": "a"}, "lastModified": 12}`), nil)
+	assert.NilError(t, err)
+
+	changed, err := PayloadHash("dashboard", []byte(`{"dashboardMetadata": {"name": "b"}, "tiles": [1.50]}`), nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Assert(t, first != changed)
+}
+
+func TestPayloadHashFailsOnInvalidJson(t *testing.T) {
+
+	_, err := PayloadHash("dashboard", []byte(`{`), nil)
+	assert.Assert(t, err != nil)
+}
+
+func TestDownloadConfigsPinsConfigs(t *testing.T) {
+
+	payload := `{"id": "1", "name": "zone", "rules": []}`
+	client := &testClient{
+		values:   map[string][]api.Value{"management-zone": {{Id: "1", Name: "zone"}}},
+		payloads: map[string]string{"1": payload},
+	}
+	apis := map[string]api.Api{"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones")}
+
+	folder := t.TempDir()
+	_, err := DownloadConfigs(apis, client, folder, Options{Pin: true})
+	assert.NilError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(folder, "management-zone", "management-zone.yaml"))
+	assert.NilError(t, err)
+
+	pin, err := PayloadHash("management-zone", []byte(payload), nil)
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "- pinnedRemote: "+pin+"\n"), string(content))
+
+	// the pin of a download from an environment only holds for that environment
+	folder = t.TempDir()
+	_, err = DownloadConfigs(apis, client, folder, Options{Pin: true, Environment: "production"})
+	assert.NilError(t, err)
+
+	content, err = ioutil.ReadFile(filepath.Join(folder, "management-zone", "management-zone.yaml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(content), "zone.production:\n- pinnedRemote: "+pin+"\n"), string(content))
+}
@@ -0,0 +1,121 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+const (
+	// ProvenanceField embeds the provenance into the template as the api.ProvenanceField field
+	ProvenanceField = "field"
+
+	// ProvenanceSidecar writes the provenance of a config to <config id>.provenance.json next to its template
+	ProvenanceSidecar = "sidecar"
+)
+
+// Provenance records where a downloaded config originates from, so that later changes can be traced back to it
+type Provenance struct {
+	Environment  string `json:"environment"`
+	Api          string `json:"api"`
+	Id           string `json:"id"`
+	DownloadedAt string `json:"downloadedAt"`
+}
+
+// ValidateProvenanceMode checks that the mode is empty, field or sidecar
+func ValidateProvenanceMode(mode string) error {
+
+	switch mode {
+	case "", ProvenanceField, ProvenanceSidecar:
+		return nil
+	default:
+		return fmt.Errorf("unknown provenance mode %s, supported are %s and %s", mode, ProvenanceField, ProvenanceSidecar)
+	}
+}
+
+func newProvenance(a api.Api, value api.Value, options Options) Provenance {
+	return Provenance{
+		Environment:  options.Environment,
+		Api:          a.GetId(),
+		Id:           value.Id,
+		DownloadedAt: options.Timestamp.UTC().Format(time.RFC3339),
+	}
+}
+
+// withProvenanceField adds the provenance as first field of the template. The template is extended as text, as it
+// may contain references which are no valid json
+func withProvenanceField(template []byte, provenance Provenance) ([]byte, error) {
+
+	if !bytes.HasPrefix(template, []byte("{\n")) {
+		return nil, fmt.Errorf("provenance can only be embedded into templates of json objects")
+	}
+
+	field, err := json.MarshalIndent(provenance, "  ", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	rest := template[2:]
+	separator := ",\n"
+	if bytes.HasPrefix(rest, []byte("}")) {
+		separator = "\n"
+	}
+
+	result := bytes.Buffer{}
+	result.WriteString("{\n  \"" + api.ProvenanceField + "\": ")
+	result.Write(field)
+	result.WriteString(separator)
+	result.Write(rest)
+
+	return result.Bytes(), nil
+}
+
+// provenanceSidecar returns the content of the sidecar file of the provenance
+func provenanceSidecar(provenance Provenance) ([]byte, error) {
+
+	content, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
+
+// recordProvenance returns the template embedding the provenance or writes its sidecar file into apiFolder, depending
+// on the mode. Templates embedding their provenance are no longer shared by configs, as it differs per config
+func recordProvenance(apiFolder string, configId string, template []byte, provenance Provenance, mode string) ([]byte, error) {
+
+	switch mode {
+	case ProvenanceField:
+		return withProvenanceField(template, provenance)
+	case ProvenanceSidecar:
+		content, err := provenanceSidecar(provenance)
+		if err != nil {
+			return nil, err
+		}
+		return template, ioutil.WriteFile(filepath.Join(apiFolder, configId+".provenance.json"), content, 0664)
+	}
+
+	return template, nil
+}
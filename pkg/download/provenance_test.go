@@ -0,0 +1,125 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func provenanceTestDownload(t *testing.T, mode string) string {
+
+	client := &testClient{
+		values: map[string][]api.Value{
+			"management-zone": {{Id: "1", Name: "zone a"}, {Id: "2", Name: "zone b"}},
+		},
+		payloads: map[string]string{
+			"1": `{"id": "1", "name": "zone a", "rules": []}`,
+			"2": `{"id": "2", "name": "zone b", "rules": []}`,
+		},
+	}
+
+	apis := map[string]api.Api{
+		"management-zone": api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	}
+
+	options := Options{
+		Provenance:  mode,
+		Environment: "production",
+		Timestamp:   time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC),
+	}
+
+	folder := t.TempDir()
+	count, err := DownloadConfigs(apis, client, folder, options)
+	assert.NilError(t, err)
+	assert.Equal(t, count, 2)
+
+	return filepath.Join(folder, "management-zone")
+}
+
+func TestDownloadConfigsEmbedsProvenanceField(t *testing.T) {
+
+	folder := provenanceTestDownload(t, ProvenanceField)
+
+	// the provenance differs per config, so that templates are no longer shared
+	for _, file := range []string{"zone_a.json", "zone_b.json"} {
+		content, err := ioutil.ReadFile(filepath.Join(folder, file))
+		assert.NilError(t, err)
+
+		var template map[string]interface{}
+		assert.NilError(t, json.Unmarshal(content, &template))
+		assert.Equal(t, template[api.ProvenanceField].(map[string]interface{})["environment"], "production")
+
+		assert.Equal(t, api.RemoveFields(template, api.FieldOverrides{}.ServerManagedFields("management-zone")), 1)
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(folder, "zone_b.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), `{
+  "monacoProvenance": {
+    "environment": "production",
+    "api": "management-zone",
+    "id": "2",
+    "downloadedAt": "2021-03-04T05:06:07Z"
+  },
+  "name": "{{ .name }}",
+  "rules": []
+}
+`)
+}
+
+func TestDownloadConfigsWritesProvenanceSidecars(t *testing.T) {
+
+	folder := provenanceTestDownload(t, ProvenanceSidecar)
+
+	content, err := ioutil.ReadFile(filepath.Join(folder, "zone_b.provenance.json"))
+	assert.NilError(t, err)
+
+	var provenance Provenance
+	assert.NilError(t, json.Unmarshal(content, &provenance))
+	assert.DeepEqual(t, provenance, Provenance{Environment: "production", Api: "management-zone", Id: "2", DownloadedAt: "2021-03-04T05:06:07Z"})
+
+	// the templates are shared as without provenance
+	yamlContent, err := ioutil.ReadFile(filepath.Join(folder, "management-zone.yaml"))
+	assert.NilError(t, err)
+	assert.Assert(t, strings.Contains(string(yamlContent), "- zone_b: zone_a.json\n"), string(yamlContent))
+}
+
+func TestWithProvenanceFieldOfEmptyObject(t *testing.T) {
+
+	template, err := withProvenanceField([]byte("{\n}\n"), Provenance{Id: "1"})
+	assert.NilError(t, err)
+	assert.Assert(t, json.Valid(template), string(template))
+
+	_, err = withProvenanceField([]byte("[]\n"), Provenance{Id: "1"})
+	assert.ErrorContains(t, err, "json objects")
+}
+
+func TestValidateProvenanceMode(t *testing.T) {
+	assert.NilError(t, ValidateProvenanceMode(""))
+	assert.NilError(t, ValidateProvenanceMode(ProvenanceSidecar))
+	assert.ErrorContains(t, ValidateProvenanceMode("header"), "unknown provenance mode header")
+}
@@ -0,0 +1,178 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+const managementZoneApiId = "management-zone"
+
+// numberPlaceholder marks ids, which were numbers in the payload and thus must be rendered without quotes
+const numberPlaceholder = "monaco-number-placeholder:"
+
+// zoneLocation is a field of a payload referring to a management zone by its id and optionally by its name.
+// The path leads to the object containing the fields, "[]" descends into all entries of a list
+type zoneLocation struct {
+	path []string
+	id   string
+	name string
+}
+
+// zoneLocations lists the locations of management zone references for all apis referring to management zones
+var zoneLocations = map[string][]zoneLocation{
+	"alerting-profile": {
+		{id: "managementZoneId"},
+	},
+	"dashboard": {
+		{path: []string{"dashboardMetadata", "dashboardFilter", "managementZone"}, id: "id", name: "name"},
+		{path: []string{"tiles", "[]", "tileFilter", "managementZone"}, id: "id", name: "name"},
+	},
+	"maintenance-window": {
+		{path: []string{"scope", "matches", "[]"}, id: "mzId"},
+	},
+}
+
+// zoneReferences replaces the management zone ids of a payload by variables referring to the downloaded
+// management zones, so that the config keeps working when zones are renamed or deployed to other environments
+type zoneReferences struct {
+	apiId      string
+	configIds  map[string]string
+	suffixes   map[string]string
+	defined    map[string]bool
+	numbers    []string
+	properties []map[string]string
+}
+
+// newZoneReferences creates the references for one payload of the api. configIds maps the ids of the
+// downloaded management zones to their config ids
+func newZoneReferences(apiId string, configIds map[string]string) *zoneReferences {
+	return &zoneReferences{
+		apiId:     apiId,
+		configIds: configIds,
+		suffixes:  make(map[string]string),
+		defined:   make(map[string]bool),
+	}
+}
+
+// replace replaces all known management zone ids at the locations of the api within content
+func (r *zoneReferences) replace(content map[string]interface{}) {
+
+	if r == nil {
+		return
+	}
+
+	for _, location := range zoneLocations[r.apiId] {
+		r.replaceAt(content, location.path, location)
+	}
+}
+
+func (r *zoneReferences) replaceAt(value interface{}, path []string, location zoneLocation) {
+
+	switch typed := value.(type) {
+	case []interface{}:
+		if len(path) > 0 && path[0] == "[]" {
+			for _, entry := range typed {
+				r.replaceAt(entry, path[1:], location)
+			}
+		}
+	case map[string]interface{}:
+		if len(path) == 0 {
+			r.replaceReference(typed, location)
+		} else if child, found := typed[path[0]]; found {
+			r.replaceAt(child, path[1:], location)
+		}
+	}
+}
+
+func (r *zoneReferences) replaceReference(object map[string]interface{}, location zoneLocation) {
+
+	var id string
+	_, isNumber := object[location.id].(json.Number)
+
+	switch typed := object[location.id].(type) {
+	case string:
+		id = typed
+	case json.Number:
+		id = typed.String()
+	default:
+		return
+	}
+
+	configId, found := r.configIds[id]
+	if !found {
+		return
+	}
+
+	idVariable := r.variable(configId, "managementZoneId", "id")
+	if isNumber {
+		object[location.id] = numberPlaceholder + idVariable
+		r.numbers = append(r.numbers, idVariable)
+	} else {
+		object[location.id] = "{{ ." + idVariable + " }}"
+	}
+
+	if _, isString := object[location.name].(string); location.name != "" && isString {
+		object[location.name] = "{{ ." + r.variable(configId, "managementZoneName", "name") + " }}"
+	}
+}
+
+// variable returns the name of the variable referring to the field of the management zone and defines it
+// on first use. The first referenced zone uses the plain variable names, further zones are numbered
+func (r *zoneReferences) variable(configId string, name string, field string) string {
+
+	suffix, found := r.suffixes[configId]
+	if !found {
+		if len(r.suffixes) > 0 {
+			suffix = strconv.Itoa(len(r.suffixes) + 1)
+		}
+		r.suffixes[configId] = suffix
+	}
+
+	variable := name + suffix
+	if !r.defined[variable] {
+		r.defined[variable] = true
+		r.properties = append(r.properties, map[string]string{variable: managementZoneApiId + "/" + configId + "." + field})
+	}
+
+	return variable
+}
+
+// restoreNumbers removes the quotes around variables of ids, which were numbers in the payload
+func (r *zoneReferences) restoreNumbers(template []byte) []byte {
+
+	if r == nil {
+		return template
+	}
+
+	for _, variable := range r.numbers {
+		template = bytes.ReplaceAll(template, []byte(`"`+numberPlaceholder+variable+`"`), []byte("{{ ."+variable+" }}"))
+	}
+
+	return template
+}
+
+// variables returns the properties defining the variables used in the payload
+func (r *zoneReferences) variables() []map[string]string {
+
+	if r == nil {
+		return nil
+	}
+	return r.properties
+}
@@ -0,0 +1,119 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package download
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// yamlSpool writes the yaml file of an api while its configs are downloaded, instead of collecting all entries in
+// memory. The list of templates and the config sections are spooled to separate temporary files, as the list of
+// templates precedes the sections in the yaml file, and joined once all configs were downloaded
+type yamlSpool struct {
+	templates *os.File
+	sections  *os.File
+}
+
+// newYamlSpool creates the temporary files of the spool in the temporary directory, so that they are never left in
+// the project if the download is interrupted
+func newYamlSpool() (*yamlSpool, error) {
+
+	templates, err := ioutil.TempFile(os.TempDir(), ".templates-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := ioutil.TempFile(os.TempDir(), ".sections-*.yaml")
+	if err != nil {
+		_ = templates.Close()
+		_ = os.Remove(templates.Name())
+		return nil, err
+	}
+
+	return &yamlSpool{templates: templates, sections: sections}, nil
+}
+
+// add writes the template of the config and its sections to the spool
+func (s *yamlSpool) add(configId string, fileName string, sections yaml.MapSlice) error {
+
+	// yaml.v2 does not indent lists within maps, so entries marshalled one by one join to the same
+	// document as marshalling all of them at once
+	err := marshalTo(s.templates, []map[string]string{{configId: fileName}})
+	if err != nil {
+		return err
+	}
+
+	return marshalTo(s.sections, sections)
+}
+
+// finish joins the spooled entries into the yaml file at path and removes the temporary files
+func (s *yamlSpool) finish(path string) (err error) {
+
+	defer s.discard()
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0664)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.WriteString(file, "config:\n")
+	if err != nil {
+		return err
+	}
+
+	for _, spooled := range []*os.File{s.templates, s.sections} {
+		_, err = spooled.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(file, spooled)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// discard removes the temporary files of the spool
+func (s *yamlSpool) discard() {
+	for _, spooled := range []*os.File{s.templates, s.sections} {
+		_ = spooled.Close()
+		_ = os.Remove(spooled.Name())
+	}
+}
+
+func marshalTo(writer io.Writer, value interface{}) error {
+
+	content, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(content)
+	return err
+}
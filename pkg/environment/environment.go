@@ -29,6 +29,16 @@ type Environment interface {
 	GetEnvironmentUrl() string
 	GetToken() (string, error)
 	GetGroup() string
+	GetName() string
+	GetTags() []string
+
+	// GetVersion returns the version of the cluster the environment runs on, as probed or declared in the
+	// environments file, or an empty string if it is unknown
+	GetVersion() string
+
+	// HasFeatureFlag checks if the feature flag is enabled for the environment in the environments file, which
+	// gates configs using experimental apis or schemas
+	HasFeatureFlag(flag string) bool
 }
 
 type environmentImpl struct {
@@ -37,6 +47,15 @@ type environmentImpl struct {
 	group          string
 	environmentUrl string
 	envTokenName   string
+	tags           []string
+	version        string
+	featureFlags   []string
+}
+
+// versionedEnvironment is an environment, whose version was probed
+type versionedEnvironment struct {
+	Environment
+	version string
 }
 
 func NewEnvironments(maps map[string]map[string]string) (map[string]Environment, []error) {
@@ -90,7 +109,30 @@ func newEnvironment(id string, properties map[string]string) (Environment, error
 		return nil, fmt.Errorf("failed to parse config for environment %s (issues: %s %s %s)", id, nameErr, urlErr, tokenErr)
 	}
 
-	return NewEnvironment(id, environmentName, environmentGroup, environmentUrl, envTokenName), nil
+	environment := &environmentImpl{
+		id:             id,
+		name:           environmentName,
+		group:          environmentGroup,
+		environmentUrl: environmentUrl,
+		envTokenName:   envTokenName,
+		tags:           parseTags(properties["tags"]),
+		version:        strings.TrimSpace(properties["version"]),
+		featureFlags:   parseTags(properties["featureFlags"]),
+	}
+
+	return environment, nil
+}
+
+// parseTags splits the comma separated tags or feature flags of an environment
+func parseTags(tags string) []string {
+
+	result := make([]string, 0)
+	for _, tag := range strings.Split(tags, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
 }
 
 func NewEnvironment(id string, name string, group string, environmentUrl string, envTokenName string) Environment {
@@ -100,6 +142,8 @@ func NewEnvironment(id string, name string, group string, environmentUrl string,
 		group:          group,
 		environmentUrl: environmentUrl,
 		envTokenName:   envTokenName,
+		tags:           make([]string, 0),
+		featureFlags:   make([]string, 0),
 	}
 }
 
@@ -122,3 +166,34 @@ func (s *environmentImpl) GetToken() (string, error) {
 func (s *environmentImpl) GetGroup() string {
 	return s.group
 }
+
+func (s *environmentImpl) GetName() string {
+	return s.name
+}
+
+func (s *environmentImpl) GetTags() []string {
+	return s.tags
+}
+
+func (s *environmentImpl) GetVersion() string {
+	return s.version
+}
+
+func (s *environmentImpl) HasFeatureFlag(flag string) bool {
+	for _, enabled := range s.featureFlags {
+		if strings.EqualFold(enabled, strings.TrimSpace(flag)) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithVersion returns the environment with the given version, e.g. the one probed from the environment.
+// The environment itself is not changed
+func WithVersion(environment Environment, version string) Environment {
+	return &versionedEnvironment{Environment: environment, version: version}
+}
+
+func (s *versionedEnvironment) GetVersion() string {
+	return s.version
+}
@@ -19,6 +19,9 @@ package environment
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 )
@@ -52,14 +55,149 @@ func LoadEnvironmentList(specificEnvironment string, environmentsFile string, fi
 	return environments, errorList
 }
 
+// includeSection of an environments file lists further environments files or folders containing environments files,
+// which are loaded as if their environments were defined in the including file. Relative paths are resolved against
+// the folder of the including file. Environments can therefore not be named like it
+const includeSection = "include"
+
 // readEnvironments reads the yaml file for the environments and returns the parsed environments
 func readEnvironments(file string, fileReader util.FileReader) (map[string]Environment, []error) {
 
 	dat, err := fileReader.ReadFile(file)
-	util.FailOnError(err, "Error while reading file")
+	if err != nil {
+		return nil, []error{fmt.Errorf("environments file %s could not be read: %s", file, err)}
+	}
 
 	err, environmentMaps := util.UnmarshalYaml(string(dat), file)
-	util.FailOnError(err, "Error while converting file")
+	if err != nil {
+		return nil, []error{fmt.Errorf("environments file %s could not be parsed: %s", file, err)}
+	}
+
+	if _, found := environmentMaps[includeSection]; !found {
+		return NewEnvironments(environmentMaps)
+	}
+	err = checkIncludeSection(file, environmentMaps)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	resolver := &includeResolver{
+		fileReader:   fileReader,
+		environments: make(map[string]map[string]string),
+		definedIn:    make(map[string]string),
+		included:     map[string]bool{filepath.Clean(file): true},
+	}
+	resolver.add(file, environmentMaps)
+
+	environments, errorList := NewEnvironments(resolver.environments)
+	return environments, append(resolver.errors, errorList...)
+}
+
+// includeResolver merges the environments of an environments file and all files included by it.
+// Each file is read once, so including a file multiple times or cyclic includes do not define environments twice
+type includeResolver struct {
+	fileReader   util.FileReader
+	environments map[string]map[string]string
+	definedIn    map[string]string
+	included     map[string]bool
+	errors       []error
+}
+
+func (r *includeResolver) add(file string, environmentMaps map[string]map[string]string) {
+
+	for id, details := range environmentMaps {
+		if id == includeSection {
+			continue
+		}
+
+		if other, found := r.definedIn[id]; found {
+			r.errors = append(r.errors, fmt.Errorf("environment `%s` is defined in %s and %s, please use unique environment names", id, other, file))
+			continue
+		}
+
+		r.definedIn[id] = file
+		r.environments[id] = details
+	}
+
+	includes := environmentMaps[includeSection]
+
+	names := make([]string, 0, len(includes))
+	for name := range includes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		path := includes[name]
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(file), path)
+		}
+
+		for _, included := range r.environmentsFiles(path) {
+			r.include(included)
+		}
+	}
+}
+
+func (r *includeResolver) include(file string) {
+
+	if r.included[file] {
+		return
+	}
+	r.included[file] = true
+
+	dat, err := r.fileReader.ReadFile(file)
+	if err != nil {
+		r.errors = append(r.errors, fmt.Errorf("included environments file %s could not be read: %s", file, err))
+		return
+	}
+
+	err, environmentMaps := util.UnmarshalYaml(string(dat), file)
+	if err != nil {
+		r.errors = append(r.errors, fmt.Errorf("included environments file %s could not be parsed: %s", file, err))
+		return
+	}
+
+	err = checkIncludeSection(file, environmentMaps)
+	if err != nil {
+		r.errors = append(r.errors, err)
+		return
+	}
+
+	r.add(file, environmentMaps)
+}
+
+// checkIncludeSection rejects include sections defining an environment, as environments named like the section
+// would otherwise be read as includes
+func checkIncludeSection(file string, environmentMaps map[string]map[string]string) error {
+
+	if _, found := environmentMaps[includeSection]["env-url"]; found {
+		return fmt.Errorf("environment `%s` in %s is not allowed, the name is reserved for including other environments files", includeSection, file)
+	}
+	return nil
+}
+
+// environmentsFiles returns the path itself if it is a file, or all yaml files within the folder and its
+// sub folders in lexical order
+func (r *includeResolver) environmentsFiles(path string) []string {
+
+	entries, err := r.fileReader.ReadDir(path)
+	if err != nil {
+		return []string{path}
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+
+		entryPath := filepath.Join(path, entry.Name())
+
+		if entry.IsDir() {
+			files = append(files, r.environmentsFiles(entryPath)...)
+		} else if strings.HasSuffix(entry.Name(), ".yaml") {
+			files = append(files, entryPath)
+		}
+	}
 
-	return NewEnvironments(environmentMaps)
+	return files
 }
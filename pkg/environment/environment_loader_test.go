@@ -0,0 +1,93 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package environment
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+func TestLoadEnvironmentListWithIncludes(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/include-test/environments.yaml")
+	environments, errorList := LoadEnvironmentList("", file, util.NewFileReader())
+
+	assert.Equal(t, len(errorList), 0)
+	assert.Equal(t, len(environments), 4)
+
+	assert.Equal(t, environments["development"].GetGroup(), "")
+	assert.Equal(t, environments["vienna"].GetGroup(), "emea")
+	assert.Equal(t, environments["tokyo"].GetEnvironmentUrl(), "https://url/to/tokyo/environment")
+	assert.Equal(t, environments["sydney"].GetGroup(), "apac")
+}
+
+func TestLoadEnvironmentListWithIncludesSelectsSpecificEnvironment(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/include-test/environments.yaml")
+	environments, errorList := LoadEnvironmentList("tokyo", file, util.NewFileReader())
+
+	assert.Equal(t, len(errorList), 0)
+	assert.Equal(t, len(environments), 1)
+}
+
+func TestLoadEnvironmentListFailsOnEnvironmentDefinedInMultipleFiles(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/duplicate-include-test/environments.yaml")
+	_, errorList := LoadEnvironmentList("", file, util.NewFileReader())
+
+	assert.Equal(t, len(errorList), 1)
+	assert.ErrorContains(t, errorList[0], "environment `development` is defined in")
+}
+
+func TestLoadEnvironmentListFailsOnMissingInclude(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/include-test/environments.yaml")
+
+	resolver := &includeResolver{
+		fileReader:   util.NewFileReader(),
+		environments: make(map[string]map[string]string),
+		definedIn:    make(map[string]string),
+		included:     make(map[string]bool),
+	}
+	resolver.add(file, map[string]map[string]string{includeSection: {"missing": "missing.yaml"}})
+
+	assert.Equal(t, len(resolver.errors), 1)
+	assert.ErrorContains(t, resolver.errors[0], "could not be read")
+}
+
+func TestLoadEnvironmentListFailsOnEnvironmentNamedInclude(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/reserved-name-test/environments.yaml")
+	_, errorList := LoadEnvironmentList("", file, util.NewFileReader())
+
+	assert.Assert(t, len(errorList) > 0)
+	assert.ErrorContains(t, errorList[0], "environment `include` in "+file+" is not allowed")
+}
+
+func TestLoadEnvironmentListFailsOnMissingFile(t *testing.T) {
+
+	file := util.ReplacePathSeparators("test-resources/missing.yaml")
+	_, errorList := LoadEnvironmentList("", file, util.NewFileReader())
+
+	assert.Assert(t, len(errorList) > 0)
+	assert.ErrorContains(t, errorList[0], "environments file "+file+" could not be read")
+}
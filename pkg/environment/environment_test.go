@@ -191,3 +191,67 @@ func setupEnvironment(t *testing.T, environmentYamlContent string, environmentOf
 
 	return e, devEnvironment
 }
+
+func TestParsingEnvironmentTags(t *testing.T) {
+
+	e, result := util.UnmarshalYaml(`
+development:
+    - name: "Dev"
+    - env-url: "https://url/to/dev/environment"
+    - env-token-name: "DEV"
+    - tags: "eu, non-production,"
+`, "test-yaml")
+	assert.NilError(t, e)
+
+	environments, errorList := NewEnvironments(result)
+	assert.Check(t, len(errorList) == 0)
+
+	assert.DeepEqual(t, environments["development"].GetTags(), []string{"eu", "non-production"})
+	assert.Equal(t, environments["development"].GetName(), "Dev")
+}
+
+func TestEnvironmentVersion(t *testing.T) {
+
+	e, result := util.UnmarshalYaml(`
+development:
+    - name: "Dev"
+    - env-url: "https://url/to/dev/environment"
+    - env-token-name: "DEV"
+    - version: "1.230"
+`, "test-yaml")
+	assert.NilError(t, e)
+
+	environments, errorList := NewEnvironments(result)
+	assert.Check(t, len(errorList) == 0)
+
+	development := environments["development"]
+	assert.Equal(t, development.GetVersion(), "1.230")
+
+	probed := WithVersion(development, "1.232.0.20211214-112149")
+	assert.Equal(t, probed.GetVersion(), "1.232.0.20211214-112149")
+	assert.Equal(t, probed.GetName(), "Dev")
+	assert.Equal(t, development.GetVersion(), "1.230")
+}
+
+func TestEnvironmentFeatureFlags(t *testing.T) {
+
+	e, result := util.UnmarshalYaml(`
+development:
+    - name: "Dev"
+    - env-url: "https://url/to/dev/environment"
+    - env-token-name: "DEV"
+    - featureFlags: "settings-preview, platform"
+`, "test-yaml")
+	assert.NilError(t, e)
+
+	environments, errorList := NewEnvironments(result)
+	assert.Check(t, len(errorList) == 0)
+
+	development := environments["development"]
+	assert.Assert(t, development.HasFeatureFlag("platform"))
+	assert.Assert(t, development.HasFeatureFlag("Settings-Preview"))
+	assert.Assert(t, !development.HasFeatureFlag("other"))
+	assert.Assert(t, WithVersion(development, "1.232").HasFeatureFlag("platform"))
+
+	assert.Assert(t, !NewEnvironment("dev", "Dev", "", "https://url/to/dev/environment", "DEV").HasFeatureFlag("platform"))
+}
@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package estimate
+
+import (
+	"strings"
+)
+
+const minutesPerMonth = 30 * 24 * 60
+
+// Consumption is an estimate of the license consumption caused by a set of configs. Only consumption which is
+// predictable from the configs themselves is taken into account
+type Consumption struct {
+	BrowserMonitorExecutionsPerMonth int
+	HttpMonitorExecutionsPerMonth    int
+	MetricEvents                     int
+	CalculatedMetrics                int
+}
+
+// Add adds the consumption caused by the given payload of a config of the given api
+func (c *Consumption) Add(apiId string, payload map[string]interface{}) {
+
+	if payload == nil {
+		return
+	}
+
+	switch {
+	case apiId == "synthetic-monitor":
+		c.addSyntheticMonitor(payload)
+	case apiId == "anomaly-detection-metrics":
+		if enabled(payload) {
+			c.MetricEvents++
+		}
+	case strings.HasPrefix(apiId, "calculated-metrics-"):
+		if enabled(payload) {
+			c.CalculatedMetrics++
+		}
+	}
+}
+
+// IsEmpty returns true if no consumption has been estimated
+func (c *Consumption) IsEmpty() bool {
+	return *c == Consumption{}
+}
+
+func (c *Consumption) addSyntheticMonitor(payload map[string]interface{}) {
+
+	frequency, _ := payload["frequencyMin"].(float64)
+	locations, _ := payload["locations"].([]interface{})
+
+	// monitors with a frequency of 0 are only executed on demand
+	if !enabled(payload) || frequency <= 0 {
+		return
+	}
+
+	executions := int(minutesPerMonth/frequency) * len(locations)
+
+	switch payload["type"] {
+	case "BROWSER":
+		c.BrowserMonitorExecutionsPerMonth += executions
+	case "HTTP":
+		c.HttpMonitorExecutionsPerMonth += executions
+	}
+}
+
+// enabled returns false only if the payload explicitly disables the config
+func enabled(payload map[string]interface{}) bool {
+	return payload["enabled"] != false
+}
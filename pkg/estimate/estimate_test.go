@@ -0,0 +1,71 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package estimate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func payload(t *testing.T, content string) map[string]interface{} {
+	var result map[string]interface{}
+	err := json.Unmarshal([]byte(content), &result)
+	assert.NilError(t, err)
+	return result
+}
+
+func TestAddSyntheticMonitors(t *testing.T) {
+
+	consumption := Consumption{}
+
+	consumption.Add("synthetic-monitor", payload(t, `{"type": "BROWSER", "frequencyMin": 15, "locations": ["a", "b"]}`))
+	consumption.Add("synthetic-monitor", payload(t, `{"type": "HTTP", "frequencyMin": 60, "locations": ["a"]}`))
+	consumption.Add("synthetic-monitor", payload(t, `{"type": "HTTP", "frequencyMin": 0, "locations": ["a"]}`))
+	consumption.Add("synthetic-monitor", payload(t, `{"type": "HTTP", "enabled": false, "frequencyMin": 1, "locations": ["a"]}`))
+
+	assert.Equal(t, consumption.BrowserMonitorExecutionsPerMonth, 2*30*24*4)
+	assert.Equal(t, consumption.HttpMonitorExecutionsPerMonth, 30*24)
+}
+
+func TestAddMetricEventsAndCalculatedMetrics(t *testing.T) {
+
+	consumption := Consumption{}
+
+	consumption.Add("anomaly-detection-metrics", payload(t, `{"enabled": true}`))
+	consumption.Add("anomaly-detection-metrics", payload(t, `{"enabled": false}`))
+	consumption.Add("calculated-metrics-service", payload(t, `{}`))
+	consumption.Add("calculated-metrics-log", payload(t, `{"enabled": true}`))
+
+	assert.Equal(t, consumption.MetricEvents, 1)
+	assert.Equal(t, consumption.CalculatedMetrics, 2)
+}
+
+func TestIsEmpty(t *testing.T) {
+
+	consumption := Consumption{}
+	assert.Assert(t, consumption.IsEmpty())
+
+	consumption.Add("dashboard", payload(t, `{}`))
+	assert.Assert(t, consumption.IsEmpty())
+
+	consumption.Add("calculated-metrics-log", payload(t, `{}`))
+	assert.Assert(t, !consumption.IsEmpty())
+}
@@ -0,0 +1,203 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// archiveClient is a read-only rest.DynatraceClient serving the configs contained in a configuration export archive.
+// Every json file in the archive is one config. The folder a file is located in denotes its api, either by the api
+// id (e.g. management-zone) or by the api path (e.g. managementZones or aws/credentials)
+type archiveClient struct {
+	configs  map[string][]archivedConfig
+	matching rest.NameMatching
+}
+
+type archivedConfig struct {
+	value   api.Value
+	payload []byte
+}
+
+// NewArchiveClient reads the given configuration export archive. Files which can not be mapped to any of the given
+// apis are skipped. Configs are looked up by name with the given name matching
+func NewArchiveClient(archive string, apis map[string]api.Api, matching rest.NameMatching) (rest.DynatraceClient, error) {
+
+	reader, err := zip.OpenReader(archive)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	configs := make(map[string][]archivedConfig)
+
+	for _, file := range reader.File {
+
+		if file.FileInfo().IsDir() || !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		folder := path.Dir(file.Name)
+		apiId, found := findApiForFolder(folder, apis)
+		if !found {
+			util.Log.Warn("\tSkipping %s, folder %s does not match any known api", file.Name, folder)
+			continue
+		}
+
+		payload, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := extractName(payload)
+		if err != nil {
+			return nil, fmt.Errorf("config %s could not be imported: %s", file.Name, err)
+		}
+
+		configs[apiId] = append(configs[apiId], archivedConfig{
+			value:   api.Value{Id: file.Name, Name: name},
+			payload: payload,
+		})
+	}
+
+	for _, archived := range configs {
+		sort.Slice(archived, func(i, j int) bool {
+			return archived[i].value.Id < archived[j].value.Id
+		})
+	}
+
+	return &archiveClient{configs: configs, matching: matching}, nil
+}
+
+func (c *archiveClient) List(a api.Api) (values []api.Value, err error) {
+
+	for _, archived := range c.configs[a.GetId()] {
+		values = append(values, archived.value)
+	}
+
+	return values, nil
+}
+
+func (c *archiveClient) ReadById(a api.Api, id string) (json []byte, err error) {
+
+	for _, archived := range c.configs[a.GetId()] {
+		if archived.value.Id == id {
+			return archived.payload, nil
+		}
+	}
+
+	return nil, fmt.Errorf("config %s of %s not found in archive", id, a.GetId())
+}
+
+func (c *archiveClient) UpsertByName(a api.Api, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+	return entity, rest.ErrReadOnly
+}
+
+func (c *archiveClient) UpsertById(a api.Api, id string, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+	return entity, rest.ErrReadOnly
+}
+
+func (c *archiveClient) DeleteByName(a api.Api, name string) error {
+	return rest.ErrReadOnly
+}
+
+func (c *archiveClient) DeleteById(a api.Api, id string) error {
+	return rest.ErrReadOnly
+}
+
+func (c *archiveClient) ExistsByName(a api.Api, name string) (exists bool, id string, err error) {
+
+	values, err := c.List(a)
+	if err != nil {
+		return false, "", err
+	}
+
+	return rest.FindByName(a, values, name, c.matching)
+}
+
+// findApiForFolder returns the id of the api whose id or path matches the end of the given folder.
+// If multiple api paths match, the longest one wins
+func findApiForFolder(folder string, apis map[string]api.Api) (apiId string, found bool) {
+
+	folder = "/" + strings.ToLower(strings.Trim(folder, "/"))
+	longestMatch := 0
+
+	for id, a := range apis {
+
+		if path.Base(folder) == id {
+			return id, true
+		}
+
+		apiPath := strings.ToLower(a.GetUrlFromEnvironmentUrl(""))
+		for _, prefix := range []string{"/api/config/v1", "/api/v1"} {
+			apiPath = strings.TrimPrefix(apiPath, prefix)
+		}
+
+		if strings.HasSuffix(folder, apiPath) && len(apiPath) > longestMatch {
+			apiId, found = id, true
+			longestMatch = len(apiPath)
+		}
+	}
+
+	return apiId, found
+}
+
+// extractName returns the name of the config. Depending on the api the name is stored in different fields
+func extractName(payload []byte) (string, error) {
+
+	var content struct {
+		Name              string `json:"name"`
+		DisplayName       string `json:"displayName"`
+		DashboardMetadata struct {
+			Name string `json:"name"`
+		} `json:"dashboardMetadata"`
+	}
+
+	err := json.Unmarshal(payload, &content)
+	if err != nil {
+		return "", err
+	}
+
+	for _, name := range []string{content.Name, content.DisplayName, content.DashboardMetadata.Name} {
+		if name != "" {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("no name found")
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+
+	in, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	return ioutil.ReadAll(in)
+}
@@ -0,0 +1,110 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+var testApis = map[string]api.Api{
+	"management-zone":        api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	"dashboard":              api.NewApi("dashboard", "/api/config/v1/dashboards"),
+	"aws-credentials":        api.NewApi("aws-credentials", "/api/config/v1/aws/credentials"),
+	"kubernetes-credentials": api.NewApi("kubernetes-credentials", "/api/config/v1/kubernetes/credentials"),
+}
+
+func writeTestArchive(t *testing.T, files map[string]string) string {
+
+	archive := filepath.Join(t.TempDir(), "export.zip")
+	out, err := os.Create(archive)
+	assert.NilError(t, err)
+
+	writer := zip.NewWriter(out)
+	for name, content := range files {
+		file, err := writer.Create(name)
+		assert.NilError(t, err)
+		_, err = file.Write([]byte(content))
+		assert.NilError(t, err)
+	}
+
+	assert.NilError(t, writer.Close())
+	assert.NilError(t, out.Close())
+
+	return archive
+}
+
+func TestArchiveClientMapsFoldersToApis(t *testing.T) {
+
+	archive := writeTestArchive(t, map[string]string{
+		"export/managementZones/1.json":   `{"id": "1", "name": "zone"}`,
+		"export/dashboard/2.json":         `{"dashboardMetadata": {"name": "overview"}}`,
+		"export/aws/credentials/3.json":   `{"label": "aws", "name": "aws-connection"}`,
+		"export/unknown/4.json":           `{"name": "unknown"}`,
+		"export/managementZones/notes.md": `not a config`,
+	})
+
+	client, err := NewArchiveClient(archive, testApis, rest.NameMatching{})
+	assert.NilError(t, err)
+
+	values, err := client.List(testApis["management-zone"])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []api.Value{{Id: "export/managementZones/1.json", Name: "zone"}})
+
+	values, err = client.List(testApis["dashboard"])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []api.Value{{Id: "export/dashboard/2.json", Name: "overview"}})
+
+	values, err = client.List(testApis["aws-credentials"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(values), 1)
+
+	values, err = client.List(testApis["kubernetes-credentials"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(values), 0)
+
+	payload, err := client.ReadById(testApis["management-zone"], "export/managementZones/1.json")
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"id": "1", "name": "zone"}`)
+}
+
+func TestArchiveClientFailsOnConfigWithoutName(t *testing.T) {
+
+	archive := writeTestArchive(t, map[string]string{
+		"managementZones/1.json": `{"id": "1"}`,
+	})
+
+	_, err := NewArchiveClient(archive, testApis, rest.NameMatching{})
+	assert.ErrorContains(t, err, "no name found")
+}
+
+func TestReadByIdFailsForUnknownConfig(t *testing.T) {
+
+	client, err := NewArchiveClient(writeTestArchive(t, map[string]string{}), testApis, rest.NameMatching{})
+	assert.NilError(t, err)
+
+	_, err = client.ReadById(testApis["dashboard"], "missing.json")
+	assert.ErrorContains(t, err, "not found in archive")
+}
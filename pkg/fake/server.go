@@ -0,0 +1,234 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// listKeys holds the apis listing their objects under another key than "values"
+var listKeys = map[string]string{
+	"dashboard": "dashboards",
+}
+
+// Server is an in-memory fake of the Dynatrace configuration apis. It lists, creates, reads, updates and deletes
+// objects of all given apis, without validating their payloads. It is meant for benchmarks and tests of monaco
+// itself and only mimics the behavior of Dynatrace as far as monaco relies on it
+type Server struct {
+	// URL is the environment url of the server
+	URL string
+
+	server   *httptest.Server
+	apis     map[string]string
+	latency  time.Duration
+	requests int64
+
+	lock    sync.Mutex
+	nextId  int
+	objects map[string]map[string]object
+}
+
+type object struct {
+	name    string
+	payload []byte
+}
+
+type value struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// NewServer starts a server faking the given apis. Every response is delayed by latency, to simulate the round
+// trip to a real environment. The server has to be closed after use
+func NewServer(apis map[string]api.Api, latency time.Duration) *Server {
+
+	s := &Server{
+		apis:    make(map[string]string, len(apis)),
+		latency: latency,
+		objects: make(map[string]map[string]object),
+	}
+
+	for id, a := range apis {
+		s.apis[a.GetUrlFromEnvironmentUrl("")] = id
+		s.objects[id] = make(map[string]object)
+	}
+
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	s.URL = s.server.URL
+
+	return s
+}
+
+// Close shuts the server down
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Requests returns the number of requests the server handled
+func (s *Server) Requests() int {
+	return int(atomic.LoadInt64(&s.requests))
+}
+
+// Objects returns the number of objects stored for the given api
+func (s *Server) Objects(apiId string) int {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.objects[apiId])
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+
+	atomic.AddInt64(&s.requests, 1)
+	time.Sleep(s.latency)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	apiId, id, found := s.resolve(r.URL.EscapedPath())
+	if !found {
+		writeError(w, http.StatusNotFound, "unknown api "+r.URL.Path)
+		return
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	objects := s.objects[apiId]
+
+	switch {
+	case id == "" && r.Method == http.MethodGet:
+		s.list(w, apiId, objects)
+	case id == "" && r.Method == http.MethodPost:
+		s.create(w, objects, body)
+	case id != "" && r.Method == http.MethodGet:
+		if current, exists := objects[id]; exists {
+			_, _ = w.Write(current.payload)
+		} else {
+			writeError(w, http.StatusNotFound, "object "+id+" does not exist")
+		}
+	case id != "" && r.Method == http.MethodPut:
+		// objects are created by updates as well, as some apis create objects with a given id that way
+		objects[id] = object{name: nameOf(body, id), payload: body}
+		w.WriteHeader(http.StatusNoContent)
+	case id != "" && r.Method == http.MethodDelete:
+		if _, exists := objects[id]; exists {
+			delete(objects, id)
+			w.WriteHeader(http.StatusNoContent)
+		} else {
+			writeError(w, http.StatusNotFound, "object "+id+" does not exist")
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, r.Method+" is not supported")
+	}
+}
+
+// resolve returns the api a request path belongs to and the id of the addressed object, which is empty
+// if the path addresses all objects of the api
+func (s *Server) resolve(path string) (apiId string, id string, found bool) {
+
+	if apiId, found = s.apis[path]; found {
+		return apiId, "", true
+	}
+
+	separator := strings.LastIndex(path, "/")
+	if separator < 0 {
+		return "", "", false
+	}
+
+	apiId, found = s.apis[path[:separator]]
+	if !found {
+		return "", "", false
+	}
+
+	id, err := url.PathUnescape(path[separator+1:])
+	return apiId, id, err == nil && id != ""
+}
+
+func (s *Server) list(w http.ResponseWriter, apiId string, objects map[string]object) {
+
+	values := make([]value, 0, len(objects))
+	for id, o := range objects {
+		values = append(values, value{Id: id, Name: o.name})
+	}
+
+	key := listKeys[apiId]
+	if key == "" {
+		key = "values"
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string][]value{key: values})
+}
+
+func (s *Server) create(w http.ResponseWriter, objects map[string]object, body []byte) {
+
+	s.nextId++
+	id := fmt.Sprintf("fake-%d", s.nextId)
+	name := nameOf(body, id)
+
+	objects[id] = object{name: name, payload: body}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(value{Id: id, Name: name})
+}
+
+// nameOf returns the name of the object in body, as given by its name or, for dashboards, by its dashboard metadata
+func nameOf(body []byte, fallback string) string {
+
+	var payload struct {
+		Name              string `json:"name"`
+		DashboardMetadata struct {
+			Name string `json:"name"`
+		} `json:"dashboardMetadata"`
+	}
+
+	if json.Unmarshal(body, &payload) != nil {
+		return fallback
+	}
+	if payload.Name != "" {
+		return payload.Name
+	}
+	if payload.DashboardMetadata.Name != "" {
+		return payload.DashboardMetadata.Name
+	}
+	return fallback
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{"code": status, "message": message},
+	})
+}
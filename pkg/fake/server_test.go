@@ -0,0 +1,90 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fake
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+func TestServerCreatesListsUpdatesAndDeletesObjects(t *testing.T) {
+
+	apis := api.NewApis()
+	zones := apis["management-zone"]
+
+	server := NewServer(map[string]api.Api{zones.GetId(): zones}, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	created, err := client.UpsertByName(zones, "zone", []byte(`{"name": "zone"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, created.Name, "zone")
+
+	updated, err := client.UpsertByName(zones, "zone", []byte(`{"name": "zone", "description": "updated"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, updated.Id, created.Id)
+	assert.Equal(t, server.Objects(zones.GetId()), 1)
+
+	payload, err := client.ReadById(zones, created.Id)
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"name": "zone", "description": "updated"}`)
+
+	err = client.DeleteByName(zones, "zone")
+	assert.NilError(t, err)
+	assert.Equal(t, server.Objects(zones.GetId()), 0)
+	assert.Assert(t, server.Requests() > 0)
+}
+
+func TestServerListsDashboardsByTheirMetadataName(t *testing.T) {
+
+	dashboards := api.NewApis()["dashboard"]
+
+	server := NewServer(map[string]api.Api{dashboards.GetId(): dashboards}, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.UpsertById(dashboards, "dashboard-id", "overview", []byte(`{"dashboardMetadata": {"name": "overview"}}`))
+	assert.NilError(t, err)
+
+	exists, id, err := client.ExistsByName(dashboards, "overview")
+	assert.NilError(t, err)
+	assert.Assert(t, exists)
+	assert.Equal(t, id, "dashboard-id")
+}
+
+func TestServerRejectsUnknownApis(t *testing.T) {
+
+	apis := api.NewApis()
+
+	server := NewServer(map[string]api.Api{}, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.List(apis["management-zone"])
+	assert.Assert(t, err != nil)
+}
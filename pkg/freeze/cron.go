@@ -0,0 +1,140 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package freeze
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSchedule is a parsed cron expression of the five fields minute, hour, day of month, month and day of week
+type cronSchedule struct {
+	minutes  []bool
+	hours    []bool
+	days     []bool
+	months   []bool
+	weekdays []bool
+
+	// restricted days and weekdays are combined like cron does: if both are restricted, either has to match
+	daysRestricted     bool
+	weekdaysRestricted bool
+}
+
+// parseCron parses a cron expression. Fields support `*`, values, ranges (`1-5`), steps (`*/15`) and lists (`1,15`)
+func parseCron(expression string) (schedule cronSchedule, err error) {
+
+	fields := strings.Fields(expression)
+	if len(fields) != 5 {
+		return schedule, fmt.Errorf("cron expression `%s` must consist of 5 fields (minute hour day month weekday)", expression)
+	}
+
+	if schedule.minutes, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return schedule, fmt.Errorf("invalid minute in cron expression `%s`: %s", expression, err)
+	}
+	if schedule.hours, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return schedule, fmt.Errorf("invalid hour in cron expression `%s`: %s", expression, err)
+	}
+	if schedule.days, schedule.daysRestricted, err = parseCronField(fields[2], 1, 31); err != nil {
+		return schedule, fmt.Errorf("invalid day of month in cron expression `%s`: %s", expression, err)
+	}
+	if schedule.months, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return schedule, fmt.Errorf("invalid month in cron expression `%s`: %s", expression, err)
+	}
+	if schedule.weekdays, schedule.weekdaysRestricted, err = parseCronField(fields[4], 0, 7); err != nil {
+		return schedule, fmt.Errorf("invalid day of week in cron expression `%s`: %s", expression, err)
+	}
+
+	// sunday is either 0 or 7
+	schedule.weekdays[0] = schedule.weekdays[0] || schedule.weekdays[7]
+
+	return schedule, nil
+}
+
+func parseCronField(field string, min int, max int) (values []bool, restricted bool, err error) {
+
+	values = make([]bool, max+1)
+
+	for _, part := range strings.Split(field, ",") {
+
+		step := 1
+		if index := strings.Index(part, "/"); index >= 0 {
+			step, err = strconv.Atoi(part[index+1:])
+			if err != nil || step < 1 {
+				return nil, false, fmt.Errorf("invalid step `%s`", part[index+1:])
+			}
+			part = part[:index]
+		}
+
+		from, to := min, max
+		if part != "*" {
+			restricted = true
+
+			bounds := strings.SplitN(part, "-", 2)
+			from, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid value `%s`", bounds[0])
+			}
+
+			to = from
+			if len(bounds) == 2 {
+				to, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, false, fmt.Errorf("invalid value `%s`", bounds[1])
+				}
+			} else if step > 1 {
+				to = max
+			}
+		}
+
+		if from < min || to > max || from > to {
+			return nil, false, fmt.Errorf("`%s` is out of range %d-%d", part, min, max)
+		}
+
+		for value := from; value <= to; value += step {
+			values[value] = true
+		}
+	}
+
+	return values, restricted, nil
+}
+
+// matches checks if the schedule fires at the minute of the given time
+func (s cronSchedule) matches(t time.Time) bool {
+
+	if !s.minutes[t.Minute()] || !s.hours[t.Hour()] || !s.months[int(t.Month())] {
+		return false
+	}
+
+	day, weekday := s.days[t.Day()], s.weekdays[int(t.Weekday())]
+	if s.daysRestricted && s.weekdaysRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// lastStart returns the latest time within (after, until] the schedule fires at
+func (s cronSchedule) lastStart(after time.Time, until time.Time) (start time.Time, found bool) {
+
+	for t := until.Truncate(time.Minute); t.After(after); t = t.Add(-time.Minute) {
+		if s.matches(t) {
+			return t, true
+		}
+	}
+	return start, false
+}
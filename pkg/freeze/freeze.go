@@ -0,0 +1,188 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package freeze
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Calendar holds the freeze windows during which deployments to environments are blocked
+type Calendar struct {
+	windows []window
+}
+
+// Freeze is a freeze window an environment is currently frozen by
+type Freeze struct {
+	Window string
+	Until  time.Time
+}
+
+type window struct {
+	name        string
+	group       string
+	environment string
+	location    *time.Location
+
+	// a window is either recurring by a cron schedule or consists of the events of an iCalendar file
+	schedule *cronSchedule
+	duration time.Duration
+	events   []event
+}
+
+// calendarFile is the yaml representation of a Calendar
+type calendarFile struct {
+	FreezeWindows []struct {
+		Name        string `yaml:"name"`
+		Group       string `yaml:"group"`
+		Environment string `yaml:"environment"`
+		Cron        string `yaml:"cron"`
+		Duration    string `yaml:"duration"`
+		Ical        string `yaml:"ical"`
+		Timezone    string `yaml:"timezone"`
+	} `yaml:"freeze-windows"`
+}
+
+// NoFreezeWindows returns a Calendar which never freezes an environment
+func NoFreezeWindows() *Calendar {
+	return &Calendar{}
+}
+
+// LoadCalendar reads the freeze windows of the given yaml file. iCalendar files are read relative to it.
+// If file is empty, a calendar without freeze windows is returned
+func LoadCalendar(file string, fileReader util.FileReader) (*Calendar, error) {
+
+	if file == "" {
+		return NoFreezeWindows(), nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("freeze windows file %s could not be read: %s", file, err)
+	}
+
+	var parsed calendarFile
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("freeze windows file %s is invalid: %s", file, err)
+	}
+
+	calendar := &Calendar{}
+
+	for i, definition := range parsed.FreezeWindows {
+
+		w := window{
+			name:        definition.Name,
+			group:       definition.Group,
+			environment: definition.Environment,
+			location:    time.UTC,
+		}
+		if w.name == "" {
+			w.name = fmt.Sprintf("freeze window %d", i+1)
+		}
+
+		if (w.group == "") == (w.environment == "") {
+			return nil, fmt.Errorf("%s of %s must define either a group or an environment", w.name, file)
+		}
+
+		if definition.Timezone != "" {
+			w.location, err = time.LoadLocation(definition.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("%s of %s has an unknown timezone %s", w.name, file, definition.Timezone)
+			}
+		}
+
+		switch {
+		case definition.Cron != "" && definition.Ical == "":
+			schedule, err := parseCron(definition.Cron)
+			if err != nil {
+				return nil, fmt.Errorf("%s of %s is invalid: %s", w.name, file, err)
+			}
+			w.schedule = &schedule
+
+			w.duration, err = time.ParseDuration(definition.Duration)
+			if err != nil || w.duration <= 0 {
+				return nil, fmt.Errorf("%s of %s needs a positive duration like `48h`", w.name, file)
+			}
+		case definition.Ical != "" && definition.Cron == "":
+			icalFile := definition.Ical
+			if !filepath.IsAbs(icalFile) {
+				icalFile = filepath.Join(filepath.Dir(file), icalFile)
+			}
+
+			ical, err := fileReader.ReadFile(icalFile)
+			if err != nil {
+				return nil, fmt.Errorf("calendar %s of %s could not be read: %s", icalFile, w.name, err)
+			}
+
+			w.events, err = parseIcal(string(ical), w.location)
+			if err != nil {
+				return nil, fmt.Errorf("calendar %s of %s is invalid: %s", icalFile, w.name, err)
+			}
+		default:
+			return nil, fmt.Errorf("%s of %s must define either a cron expression or an ical file", w.name, file)
+		}
+
+		calendar.windows = append(calendar.windows, w)
+	}
+
+	return calendar, nil
+}
+
+// ActiveFreeze returns the freeze window the environment is frozen by at the given time. If multiple windows
+// are active, the one lasting the longest is returned
+func (c *Calendar) ActiveFreeze(environment environment.Environment, now time.Time) (freeze Freeze, frozen bool) {
+
+	for _, w := range c.windows {
+
+		if w.environment != "" && w.environment != environment.GetId() {
+			continue
+		}
+		if w.group != "" && w.group != environment.GetGroup() {
+			continue
+		}
+
+		until, active := w.activeUntil(now)
+		if active && until.After(freeze.Until) {
+			freeze = Freeze{Window: w.name, Until: until}
+			frozen = true
+		}
+	}
+
+	return freeze, frozen
+}
+
+func (w window) activeUntil(now time.Time) (until time.Time, active bool) {
+
+	if w.schedule != nil {
+		local := now.In(w.location)
+		start, found := w.schedule.lastStart(local.Add(-w.duration), local)
+		return start.Add(w.duration), found
+	}
+
+	for _, event := range w.events {
+		if !now.Before(event.start) && now.Before(event.end) && event.end.After(until) {
+			until, active = event.end, true
+		}
+	}
+	return until, active
+}
@@ -0,0 +1,128 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package freeze
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+var productionEnvironment = environment.NewEnvironment("prod-eu", "Prod EU", "production", "https://prod-eu.example.com", "PROD")
+var otherProductionEnvironment = environment.NewEnvironment("prod-us", "Prod US", "production", "https://prod-us.example.com", "PROD")
+var developmentEnvironment = environment.NewEnvironment("dev", "Dev", "", "https://dev.example.com", "DEV")
+
+func TestParseCron(t *testing.T) {
+
+	schedule, err := parseCron("*/15 9-17 * * 1-5")
+	assert.NilError(t, err)
+
+	assert.Assert(t, schedule.matches(time.Date(2020, 12, 1, 9, 45, 0, 0, time.UTC)))  // tuesday
+	assert.Assert(t, !schedule.matches(time.Date(2020, 12, 1, 9, 40, 0, 0, time.UTC))) // not a quarter
+	assert.Assert(t, !schedule.matches(time.Date(2020, 12, 1, 18, 0, 0, 0, time.UTC))) // after hours
+	assert.Assert(t, !schedule.matches(time.Date(2020, 12, 6, 10, 0, 0, 0, time.UTC))) // sunday
+
+	schedule, err = parseCron("0 0 1 * 7")
+	assert.NilError(t, err)
+	assert.Assert(t, schedule.matches(time.Date(2020, 12, 6, 0, 0, 0, 0, time.UTC))) // sunday, not the 1st
+	assert.Assert(t, schedule.matches(time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC))) // the 1st, not a sunday
+}
+
+func TestParseCronFailsOnInvalidExpressions(t *testing.T) {
+
+	for _, expression := range []string{"* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		_, err := parseCron(expression)
+		assert.Assert(t, err != nil, expression)
+	}
+}
+
+func TestParseIcal(t *testing.T) {
+
+	events, err := parseIcal("BEGIN:VEVENT\nSUMMARY:Freeze\nDTSTART;TZID=Europe/Vienna:20201224T080000\nDTEND:20201224T120000Z\nEND:VEVENT\n"+
+		"BEGIN:VEVENT\nDTSTART;VALUE=DATE:20201231\nEND:VEVENT\n", time.UTC)
+	assert.NilError(t, err)
+	assert.Equal(t, len(events), 2)
+
+	assert.Equal(t, events[0].summary, "Freeze")
+	assert.Assert(t, events[0].start.Equal(time.Date(2020, 12, 24, 7, 0, 0, 0, time.UTC)))
+	assert.Assert(t, events[0].end.Equal(time.Date(2020, 12, 24, 12, 0, 0, 0, time.UTC)))
+	assert.Assert(t, events[1].end.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	_, err = parseIcal("BEGIN:VEVENT\nDTSTART:20201224T080000Z\nRRULE:FREQ=YEARLY\nEND:VEVENT\n", time.UTC)
+	assert.ErrorContains(t, err, "recurring events are not supported")
+}
+
+func TestActiveFreeze(t *testing.T) {
+
+	calendar, err := LoadCalendar("test-resources/freeze-windows.yaml", util.NewFileReader())
+	assert.NilError(t, err)
+
+	// friday 19:00 in vienna is within the weekend freeze of all production environments
+	friday := time.Date(2020, 12, 4, 18, 0, 0, 0, time.UTC)
+	freeze, frozen := calendar.ActiveFreeze(otherProductionEnvironment, friday)
+	assert.Assert(t, frozen)
+	assert.Equal(t, freeze.Window, "weekend")
+	assert.Assert(t, freeze.Until.Equal(time.Date(2020, 12, 7, 5, 0, 0, 0, time.UTC)), freeze.Until)
+
+	_, frozen = calendar.ActiveFreeze(developmentEnvironment, friday)
+	assert.Assert(t, !frozen)
+
+	_, frozen = calendar.ActiveFreeze(productionEnvironment, time.Date(2020, 12, 8, 12, 0, 0, 0, time.UTC))
+	assert.Assert(t, !frozen)
+
+	// christmas only freezes prod-eu
+	christmas := time.Date(2020, 12, 24, 12, 0, 0, 0, time.UTC)
+	freeze, frozen = calendar.ActiveFreeze(productionEnvironment, christmas)
+	assert.Assert(t, frozen)
+	assert.Equal(t, freeze.Window, "holidays")
+	assert.Assert(t, freeze.Until.Equal(time.Date(2020, 12, 27, 0, 0, 0, 0, time.UTC)))
+
+	_, frozen = calendar.ActiveFreeze(otherProductionEnvironment, christmas)
+	assert.Assert(t, !frozen)
+
+	// the weekend freeze starting on christmas lasts longer than the holidays
+	freeze, frozen = calendar.ActiveFreeze(productionEnvironment, time.Date(2020, 12, 25, 18, 0, 0, 0, time.UTC))
+	assert.Assert(t, frozen)
+	assert.Equal(t, freeze.Window, "weekend")
+}
+
+func TestLoadCalendarFailsOnIncompleteWindows(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "freeze.yaml")
+
+	tests := map[string]string{
+		"freeze-windows:\n  - cron: \"0 18 * * 5\"\n    duration: 1h\n":                      "must define either a group or an environment",
+		"freeze-windows:\n  - group: production\n":                                           "must define either a cron expression or an ical file",
+		"freeze-windows:\n  - group: production\n    cron: \"0 18 * * 5\"\n":                 "needs a positive duration",
+		"freeze-windows:\n  - group: production\n    cron: \"0 18 * * 5\"\n    unknown: 1\n": "is invalid",
+	}
+
+	for content, expected := range tests {
+		err := ioutil.WriteFile(file, []byte(content), 0664)
+		assert.NilError(t, err)
+
+		_, err = LoadCalendar(file, util.NewFileReader())
+		assert.ErrorContains(t, err, expected)
+	}
+}
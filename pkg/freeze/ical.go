@@ -0,0 +1,128 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package freeze
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// event is a freeze period read from an iCalendar file
+type event struct {
+	summary string
+	start   time.Time
+	end     time.Time
+}
+
+// parseIcal reads the events (VEVENT) of an iCalendar file. Times without time zone are interpreted in location,
+// events given as dates last the whole day. Recurring events (RRULE) are not supported
+func parseIcal(content string, location *time.Location) (events []event, err error) {
+
+	var current *event
+
+	for i, line := range unfoldIcalLines(content) {
+
+		name, parameters, value := splitIcalLine(line)
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = &event{}
+		case name == "END" && value == "VEVENT":
+			if current == nil || current.start.IsZero() {
+				return nil, fmt.Errorf("event ending in line %d has no start", i+1)
+			}
+			if current.end.IsZero() {
+				current.end = current.start.AddDate(0, 0, 1)
+			}
+			events = append(events, *current)
+			current = nil
+		case current == nil:
+			continue
+		case name == "SUMMARY":
+			current.summary = value
+		case name == "DTSTART" || name == "DTEND":
+			t, err := parseIcalTime(value, parameters, location)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s in line %d: %s", name, i+1, err)
+			}
+			if name == "DTSTART" {
+				current.start = t
+			} else {
+				current.end = t
+			}
+		case name == "RRULE":
+			return nil, fmt.Errorf("recurring events are not supported (line %d), use a cron expression instead", i+1)
+		}
+	}
+
+	return events, nil
+}
+
+// unfoldIcalLines splits the content into lines, joining lines continued by a leading space or tab
+func unfoldIcalLines(content string) []string {
+
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+		} else {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitIcalLine splits a content line like `DTSTART;TZID=Europe/Vienna:20201224T000000` into its parts
+func splitIcalLine(line string) (name string, parameters map[string]string, value string) {
+
+	parameters = make(map[string]string)
+
+	index := strings.Index(line, ":")
+	if index < 0 {
+		return strings.ToUpper(line), parameters, ""
+	}
+
+	parts := strings.Split(line[:index], ";")
+	for _, parameter := range parts[1:] {
+		if split := strings.SplitN(parameter, "=", 2); len(split) == 2 {
+			parameters[strings.ToUpper(split[0])] = split[1]
+		}
+	}
+
+	return strings.ToUpper(parts[0]), parameters, strings.TrimSpace(line[index+1:])
+}
+
+func parseIcalTime(value string, parameters map[string]string, location *time.Location) (time.Time, error) {
+
+	if tzid, found := parameters["TZID"]; found {
+		loaded, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %s", tzid)
+		}
+		location = loaded
+	}
+
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse("20060102T150405Z", value)
+	case len(value) == len("20060102"):
+		return time.ParseInLocation("20060102", value, location)
+	default:
+		return time.ParseInLocation("20060102T150405", value, location)
+	}
+}
@@ -0,0 +1,164 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inventory
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+
+// unlistableApis can not be listed without further parameters, so their objects are not part of inventories
+var unlistableApis = map[string]bool{
+	"extension": true,
+	"settings":  true,
+}
+
+// lastModifiedFields hold the time an object was modified last, for the apis exposing it
+var lastModifiedFields = []string{"lastModified", "lastModifiedTimestamp", "modificationTimestamp"}
+
+// Entry is an object deployed to an environment
+type Entry struct {
+	Environment  string `json:"environment"`
+	Api          string `json:"api"`
+	Name         string `json:"name"`
+	Id           string `json:"id"`
+	LastModified string `json:"lastModified,omitempty"`
+	Managed      bool   `json:"managedByMonaco"`
+}
+
+// Options control which objects are inventoried and what is known about them
+type Options struct {
+
+	// LastModified reads every object to take the time it was modified last from its payload, which requires
+	// one request per object. The time is left empty for apis not exposing it
+	LastModified bool
+
+	// Managed returns whether the object with the given name is deployed by monaco, may be nil
+	Managed func(apiId string, name string) bool
+}
+
+// Collect lists the objects of all given apis in the environment of the client. Entries are sorted by api and name
+func Collect(environment string, client rest.DynatraceClient, apis map[string]api.Api, options Options) ([]Entry, error) {
+
+	listable := make(map[string]api.Api, len(apis))
+	for id, a := range apis {
+		if !unlistableApis[id] {
+			listable[id] = a
+		}
+	}
+
+	values, err := rest.ListAll(client, listable)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0)
+	for apiId, apiValues := range values {
+		for _, value := range apiValues {
+			entry := Entry{Environment: environment, Api: apiId, Name: value.Name, Id: value.Id}
+
+			if options.Managed != nil {
+				entry.Managed = options.Managed(apiId, value.Name)
+			}
+
+			if options.LastModified {
+				entry.LastModified, err = lastModified(client, listable[apiId], value.Id)
+				if err != nil {
+					return nil, fmt.Errorf("reading %s %s failed: %s", apiId, value.Id, err)
+				}
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Api != entries[j].Api {
+			return entries[i].Api < entries[j].Api
+		}
+		if entries[i].Name != entries[j].Name {
+			return entries[i].Name < entries[j].Name
+		}
+		return entries[i].Id < entries[j].Id
+	})
+
+	return entries, nil
+}
+
+// lastModified reads the time the object was modified last from its payload. Timestamps in milliseconds are
+// formatted as RFC 3339
+func lastModified(client rest.DynatraceClient, a api.Api, id string) (string, error) {
+
+	payload, err := client.ReadById(a, id)
+	if err != nil {
+		return "", err
+	}
+
+	var fields map[string]interface{}
+	if json.Unmarshal(payload, &fields) != nil {
+		return "", nil
+	}
+
+	for _, field := range lastModifiedFields {
+		switch value := fields[field].(type) {
+		case string:
+			return value, nil
+		case float64:
+			return time.Unix(0, int64(value)*int64(time.Millisecond)).UTC().Format(time.RFC3339), nil
+		}
+	}
+
+	return "", nil
+}
+
+// WriteCsv writes the entries as csv with a header row
+func WriteCsv(out io.Writer, entries []Entry) error {
+
+	writer := csv.NewWriter(out)
+
+	err := writer.Write([]string{"environment", "api", "name", "id", "lastModified", "managedByMonaco"})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		err = writer.Write([]string{entry.Environment, entry.Api, entry.Name, entry.Id, entry.LastModified, strconv.FormatBool(entry.Managed)})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteJson writes the entries as json array
+func WriteJson(out io.Writer, entries []Entry) error {
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(entries)
+}
@@ -0,0 +1,88 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inventory
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+var testApis = map[string]api.Api{
+	"management-zone":  api.NewApi("management-zone", "/api/config/v1/managementZones"),
+	"alerting-profile": api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"),
+}
+
+func TestCollect(t *testing.T) {
+
+	server := fake.NewServer(testApis, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	zone, err := client.UpsertByName(testApis["management-zone"], "zone", []byte(`{"name": "zone", "lastModified": 1600000000000}`))
+	assert.NilError(t, err)
+	profile, err := client.UpsertByName(testApis["alerting-profile"], "profile", []byte(`{"name": "profile"}`))
+	assert.NilError(t, err)
+
+	entries, err := Collect("dev", client, testApis, Options{
+		LastModified: true,
+		Managed: func(apiId string, name string) bool {
+			return apiId == "management-zone" && name == "zone"
+		},
+	})
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, entries, []Entry{
+		{Environment: "dev", Api: "alerting-profile", Name: "profile", Id: profile.Id},
+		{Environment: "dev", Api: "management-zone", Name: "zone", Id: zone.Id, LastModified: "2020-09-13T12:26:40Z", Managed: true},
+	})
+}
+
+func TestWriteCsv(t *testing.T) {
+
+	var out bytes.Buffer
+	err := WriteCsv(&out, []Entry{{Environment: "dev", Api: "dashboard", Name: "Overview, EMEA", Id: "1", Managed: true}})
+	assert.NilError(t, err)
+
+	assert.Equal(t, out.String(), "environment,api,name,id,lastModified,managedByMonaco\ndev,dashboard,\"Overview, EMEA\",1,,true\n")
+}
+
+func TestWriteJson(t *testing.T) {
+
+	var out bytes.Buffer
+	err := WriteJson(&out, []Entry{{Environment: "dev", Api: "dashboard", Name: "Overview", Id: "1"}})
+	assert.NilError(t, err)
+
+	assert.Equal(t, out.String(), `[
+  {
+    "environment": "dev",
+    "api": "dashboard",
+    "name": "Overview",
+    "id": "1",
+    "managedByMonaco": false
+  }
+]
+`)
+}
@@ -0,0 +1,154 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// recommendedMinimumSyntheticFrequency is the lowest frequency (in minutes) recommended for synthetic monitors
+const recommendedMinimumSyntheticFrequency = 5
+
+// Rule is a best-practice check for configs of a single api
+type Rule struct {
+	Id          string
+	Api         string
+	Description string
+	check       func(payload map[string]interface{}) bool
+}
+
+// Linter checks rendered configs against a set of enabled rules
+type Linter interface {
+
+	// Lint returns the ids and descriptions of all rules violated by the payload of a config of the given api
+	Lint(apiId string, payload map[string]interface{}) (findings []string)
+}
+
+type linterImpl struct {
+	rules []Rule
+}
+
+var rules = []Rule{
+	{
+		Id:          "management-zone-without-rules",
+		Api:         "management-zone",
+		Description: "management zone has no rules and therefore contains no entities",
+		check:       managementZoneWithoutRules,
+	},
+	{
+		Id:          "auto-tag-matches-everything",
+		Api:         "auto-tag",
+		Description: "auto-tag rule has no conditions and therefore matches every entity",
+		check:       autoTagMatchesEverything,
+	},
+	{
+		Id:          "synthetic-monitor-frequency",
+		Api:         "synthetic-monitor",
+		Description: fmt.Sprintf("synthetic monitor frequency is below the recommended minimum of %d minutes", recommendedMinimumSyntheticFrequency),
+		check:       syntheticMonitorFrequencyTooLow,
+	},
+}
+
+// Rules returns all built-in rules sorted by id
+func Rules() []Rule {
+
+	result := make([]Rule, len(rules))
+	copy(result, rules)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Id < result[j].Id
+	})
+
+	return result
+}
+
+// NoRules returns a Linter which does not check any rule
+func NoRules() Linter {
+	return &linterImpl{}
+}
+
+// NewLinter creates a Linter checking all built-in rules except the disabled ones.
+// Fails if a disabled rule does not exist
+func NewLinter(disabledRules []string) (Linter, error) {
+
+	disabled := make(map[string]bool)
+	for _, id := range disabledRules {
+		disabled[strings.TrimSpace(id)] = true
+	}
+
+	enabled := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		if disabled[rule.Id] {
+			delete(disabled, rule.Id)
+			continue
+		}
+		enabled = append(enabled, rule)
+	}
+
+	for id := range disabled {
+		if id != "" {
+			return nil, fmt.Errorf("unknown lint rule %s", id)
+		}
+	}
+
+	return &linterImpl{rules: enabled}, nil
+}
+
+func (l *linterImpl) Lint(apiId string, payload map[string]interface{}) (findings []string) {
+
+	for _, rule := range l.rules {
+		if rule.Api == apiId && rule.check(payload) {
+			findings = append(findings, rule.Id+": "+rule.Description)
+		}
+	}
+
+	return findings
+}
+
+func managementZoneWithoutRules(payload map[string]interface{}) bool {
+
+	zoneRules, _ := payload["rules"].([]interface{})
+	return len(zoneRules) == 0
+}
+
+func autoTagMatchesEverything(payload map[string]interface{}) bool {
+
+	tagRules, _ := payload["rules"].([]interface{})
+
+	for _, rule := range tagRules {
+
+		typed, ok := rule.(map[string]interface{})
+		if !ok || typed["enabled"] == false {
+			continue
+		}
+
+		conditions, _ := typed["conditions"].([]interface{})
+		if len(conditions) == 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func syntheticMonitorFrequencyTooLow(payload map[string]interface{}) bool {
+
+	// a frequency of 0 is used for monitors which are only executed on demand
+	frequency, ok := payload["frequencyMin"].(float64)
+	return ok && frequency > 0 && frequency < recommendedMinimumSyntheticFrequency
+}
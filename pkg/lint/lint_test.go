@@ -0,0 +1,93 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lint
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func payload(t *testing.T, content string) map[string]interface{} {
+	var result map[string]interface{}
+	err := json.Unmarshal([]byte(content), &result)
+	assert.NilError(t, err)
+	return result
+}
+
+func TestLintManagementZoneWithoutRules(t *testing.T) {
+
+	linter, err := NewLinter(nil)
+	assert.NilError(t, err)
+
+	findings := linter.Lint("management-zone", payload(t, `{"name": "zone", "rules": []}`))
+	assert.Equal(t, len(findings), 1)
+	assert.Assert(t, findings[0] == "management-zone-without-rules: management zone has no rules and therefore contains no entities")
+
+	findings = linter.Lint("management-zone", payload(t, `{"name": "zone", "rules": [{"type": "SERVICE"}]}`))
+	assert.Equal(t, len(findings), 0)
+}
+
+func TestLintAutoTagMatchingEverything(t *testing.T) {
+
+	linter, err := NewLinter(nil)
+	assert.NilError(t, err)
+
+	findings := linter.Lint("auto-tag", payload(t, `{"rules": [{"enabled": true, "conditions": []}]}`))
+	assert.Equal(t, len(findings), 1)
+
+	findings = linter.Lint("auto-tag", payload(t, `{"rules": [{"enabled": false, "conditions": []}]}`))
+	assert.Equal(t, len(findings), 0)
+
+	findings = linter.Lint("auto-tag", payload(t, `{"rules": [{"enabled": true, "conditions": [{"key": {}}]}]}`))
+	assert.Equal(t, len(findings), 0)
+}
+
+func TestLintSyntheticMonitorFrequency(t *testing.T) {
+
+	linter, err := NewLinter(nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(linter.Lint("synthetic-monitor", payload(t, `{"frequencyMin": 1}`))), 1)
+	assert.Equal(t, len(linter.Lint("synthetic-monitor", payload(t, `{"frequencyMin": 5}`))), 0)
+	assert.Equal(t, len(linter.Lint("synthetic-monitor", payload(t, `{"frequencyMin": 0}`))), 0)
+}
+
+func TestLintOnlyChecksRulesOfMatchingApi(t *testing.T) {
+
+	linter, err := NewLinter(nil)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(linter.Lint("dashboard", payload(t, `{"rules": []}`))), 0)
+}
+
+func TestDisabledRulesAreNotChecked(t *testing.T) {
+
+	linter, err := NewLinter([]string{"management-zone-without-rules"})
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(linter.Lint("management-zone", payload(t, `{"rules": []}`))), 0)
+}
+
+func TestDisablingUnknownRuleFails(t *testing.T) {
+
+	_, err := NewLinter([]string{"no-such-rule"})
+	assert.ErrorContains(t, err, "unknown lint rule no-such-rule")
+}
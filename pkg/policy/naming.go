@@ -0,0 +1,206 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// namingConventionsFile is the file in the policy folder declaring the naming conventions per api
+const namingConventionsFile = "naming-conventions.yaml"
+
+// namingConvention restricts the names of the configs of apis, either by a regular expression (pattern) or a
+// template the name has to match. Templates are rendered with the input of the config, e.g. {{ .Owner }}, and
+// `*` matches any text. The text between the `*` is rendered on its own, so actions must not contain a `*`:
+//
+//	conventions:
+//	  - apis: [alerting-profile]
+//	    template: "{{ .Owner }}-*"
+//	    message: "alerting profiles must start with the code of the owning team"
+type namingConvention struct {
+	Apis     []string `yaml:"apis"`
+	Pattern  string   `yaml:"pattern"`
+	Template string   `yaml:"template"`
+	Message  string   `yaml:"message"`
+
+	pattern *regexp.Regexp
+
+	// templates are the parsed parts of the template between its wildcards
+	templates []*template.Template
+}
+
+type namingConventions struct {
+	Conventions []namingConvention `yaml:"conventions"`
+}
+
+// namingEngine reports the names violating a naming convention of their api
+type namingEngine struct {
+	conventions []namingConvention
+}
+
+// loadNamingConventions reads the naming conventions of the policy folder. Returns nil, if the folder contains none
+func loadNamingConventions(folder string, fileReader util.FileReader) (*namingEngine, error) {
+
+	fileName := filepath.Join(folder, namingConventionsFile)
+
+	content, err := fileReader.ReadFile(fileName)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("naming conventions %s could not be read: %s", fileName, err)
+	}
+
+	var parsed namingConventions
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("naming conventions %s are invalid: %s", fileName, err)
+	}
+
+	for i := range parsed.Conventions {
+		err = parsed.Conventions[i].compile()
+		if err != nil {
+			return nil, fmt.Errorf("naming convention %d of %s is invalid: %s", i+1, fileName, err)
+		}
+	}
+
+	util.Log.Debug("Loaded %d naming conventions from %s", len(parsed.Conventions), fileName)
+
+	return &namingEngine{conventions: parsed.Conventions}, nil
+}
+
+func (c *namingConvention) compile() (err error) {
+
+	if len(c.Apis) == 0 {
+		return fmt.Errorf("no apis given")
+	}
+	for _, apiId := range c.Apis {
+		if !api.IsApi(apiId) {
+			return fmt.Errorf("unknown api %s", apiId)
+		}
+	}
+
+	switch {
+	case c.Pattern != "" && c.Template != "":
+		return fmt.Errorf("either pattern or template can be given")
+	case c.Pattern != "":
+		c.pattern, err = regexp.Compile(c.Pattern)
+		return err
+	case c.Template != "":
+		for _, part := range strings.Split(c.Template, "*") {
+			parsed, err := template.New("naming-convention").Option("missingkey=error").Parse(part)
+			if err != nil {
+				return err
+			}
+			c.templates = append(c.templates, parsed)
+		}
+		return nil
+	default:
+		return fmt.Errorf("neither pattern nor template given")
+	}
+}
+
+func (e *namingEngine) Evaluate(input Input) (violations []string, err error) {
+
+	for _, convention := range e.conventions {
+
+		if !contains(convention.Apis, input.Api) {
+			continue
+		}
+
+		matches, expected, err := convention.matches(input)
+		if err != nil {
+			return nil, err
+		}
+		if matches {
+			continue
+		}
+
+		if convention.Message != "" {
+			violations = append(violations, fmt.Sprintf("%s (name %q)", convention.Message, input.Name))
+		} else {
+			violations = append(violations, fmt.Sprintf("%s name %q does not match the naming convention %s", input.Api, input.Name, expected))
+		}
+	}
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+// matches checks the name of the input against the convention, returning the pattern or rendered template it expects
+func (c *namingConvention) matches(input Input) (matches bool, expected string, err error) {
+
+	if c.pattern != nil {
+		return c.pattern.MatchString(input.Name), c.Pattern, nil
+	}
+
+	parts := make([]string, 0, len(c.templates))
+	for _, part := range c.templates {
+		var rendered bytes.Buffer
+		err = part.Execute(&rendered, input)
+		if err != nil {
+			return false, "", fmt.Errorf("naming convention %s could not be rendered: %s", c.Template, err)
+		}
+		parts = append(parts, rendered.String())
+	}
+
+	return matchesWildcards(input.Name, parts), strings.Join(parts, "*"), nil
+}
+
+// matchesWildcards checks if the name consists of the parts in their order, with any text between them
+func matchesWildcards(name string, parts []string) bool {
+
+	if len(parts) == 1 {
+		return name == parts[0]
+	}
+
+	first, last := parts[0], parts[len(parts)-1]
+	if !strings.HasPrefix(name, first) {
+		return false
+	}
+	name = name[len(first):]
+
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, part)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(part):]
+	}
+
+	return strings.HasSuffix(name, last)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
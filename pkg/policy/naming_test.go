@@ -0,0 +1,131 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/golang/mock/gomock"
+	"gotest.tools/assert"
+)
+
+func loadTestNamingConventions(t *testing.T) Engine {
+	engine, err := LoadPolicies("test-resources/naming", util.NewFileReader())
+	assert.NilError(t, err)
+	return engine
+}
+
+func TestNamingConventionTemplateIsRenderedWithInput(t *testing.T) {
+
+	engine := loadTestNamingConventions(t)
+
+	violations, err := engine.Evaluate(Input{Api: "alerting-profile", Name: "SRE-critical", Owner: "SRE"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+
+	violations, err = engine.Evaluate(Input{Api: "management-zone", Name: "OPS-hosts", Owner: "SRE"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, violations, []string{`names must start with the code of the owning team (name "OPS-hosts")`})
+}
+
+func TestNamingConventionPattern(t *testing.T) {
+
+	engine := loadTestNamingConventions(t)
+
+	violations, err := engine.Evaluate(Input{Api: "dashboard", Name: "Team Overview"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+
+	violations, err = engine.Evaluate(Input{Api: "dashboard", Name: "my dashboard"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, violations, []string{`dashboard name "my dashboard" does not match the naming convention ^[A-Z][a-z]+ Overview$`})
+}
+
+func TestNamingConventionsOnlyApplyToTheirApis(t *testing.T) {
+
+	violations, err := loadTestNamingConventions(t).Evaluate(Input{Api: "auto-tag", Name: "anything"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+}
+
+func TestNamingConventionsAreCombinedWithPolicies(t *testing.T) {
+
+	engine, err := LoadPolicies("test-resources/combined", util.NewFileReader())
+	assert.NilError(t, err)
+
+	violations, err := engine.Evaluate(Input{
+		Api:     "dashboard",
+		Name:    "overview",
+		Payload: payload(t, `{"dashboardMetadata": {"shared": true}}`),
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, violations, []string{
+		`dashboard name "overview" does not match the naming convention ^[A-Z]`,
+		"dashboard overview must not be shared publicly",
+	})
+}
+
+func TestNamingConventionRequiresPatternOrTemplate(t *testing.T) {
+
+	convention := namingConvention{Apis: []string{"dashboard"}}
+	assert.ErrorContains(t, convention.compile(), "neither pattern nor template given")
+
+	convention = namingConvention{Apis: []string{"dashboard"}, Pattern: "^a", Template: "a*"}
+	assert.ErrorContains(t, convention.compile(), "either pattern or template")
+
+	convention = namingConvention{Pattern: "^a"}
+	assert.ErrorContains(t, convention.compile(), "no apis given")
+
+	convention = namingConvention{Apis: []string{"dashbaord"}, Pattern: "^a"}
+	assert.ErrorContains(t, convention.compile(), "unknown api dashbaord")
+
+	convention = namingConvention{Apis: []string{"dashboard"}, Template: "{{ .Owner }-*"}
+	assert.ErrorContains(t, convention.compile(), "unexpected")
+}
+
+func TestNamingConventionTemplateMatchesWildcards(t *testing.T) {
+
+	assert.Assert(t, matchesWildcards("SRE-hosts", []string{"SRE-", ""}))
+	assert.Assert(t, matchesWildcards("SRE-hosts-eu-prod", []string{"SRE-", "-", "-prod"}))
+	assert.Assert(t, matchesWildcards("SRE", []string{"SRE"}))
+	assert.Assert(t, !matchesWildcards("SRE-host", []string{"SRE"}))
+	assert.Assert(t, !matchesWildcards("SRE-prod", []string{"SRE-", "-prod"}))
+	assert.Assert(t, !matchesWildcards("OPS-hosts", []string{"SRE-", ""}))
+}
+
+func TestNamingConventionsFailIfTheyCannotBeRead(t *testing.T) {
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	fileReader := util.NewMockFileReader(mockCtrl)
+	fileReader.EXPECT().ReadFile(gomock.Any()).Return(nil, errors.New("permission denied"))
+
+	_, err := loadNamingConventions("policies", fileReader)
+	assert.ErrorContains(t, err, "permission denied")
+
+	fileReader.EXPECT().ReadFile(gomock.Any()).Return(nil, os.ErrNotExist)
+
+	naming, err := loadNamingConventions("policies", fileReader)
+	assert.NilError(t, err)
+	assert.Assert(t, naming == nil)
+}
@@ -0,0 +1,176 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"github.com/open-policy-agent/opa/rego"
+)
+
+// query is evaluated for every config. Policies add violation messages to the deny set of package monaco:
+//
+//	package monaco
+//
+//	deny[msg] {
+//	  input.api == "dashboard"
+//	  input.payload.dashboardMetadata.shared
+//	  msg := "dashboards must not be shared"
+//	}
+const query = "data.monaco.deny"
+
+// Input is the document a policy is evaluated against, available as `input` in rego
+type Input struct {
+	Api         string      `json:"api"`
+	Project     string      `json:"project"`
+	Config      string      `json:"config"`
+	Name        string      `json:"name"`
+	Owner       string      `json:"owner"`
+	Environment string      `json:"environment"`
+	Group       string      `json:"group"`
+	Payload     interface{} `json:"payload"`
+}
+
+// Engine evaluates organizational policies against configs
+type Engine interface {
+
+	// Evaluate returns the sorted violation messages of all policies denying the given input
+	Evaluate(input Input) (violations []string, err error)
+}
+
+type regoEngine struct {
+	query *rego.PreparedEvalQuery
+}
+
+type noPoliciesEngine struct{}
+
+// combinedEngine reports the violations of all its engines
+type combinedEngine struct {
+	engines []Engine
+}
+
+// NoPolicies returns an Engine which never reports violations
+func NoPolicies() Engine {
+	return &noPoliciesEngine{}
+}
+
+// LoadPolicies loads all rego files (*.rego) and the naming conventions (naming-conventions.yaml) contained in the
+// given folder. If folder is empty, an engine without any policies is returned
+func LoadPolicies(folder string, fileReader util.FileReader) (Engine, error) {
+
+	if folder == "" {
+		return NoPolicies(), nil
+	}
+
+	files, err := fileReader.ReadDir(folder)
+	if err != nil {
+		return nil, fmt.Errorf("policy folder %s could not be read: %s", folder, err)
+	}
+
+	options := []func(*rego.Rego){rego.Query(query)}
+
+	for _, file := range files {
+
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".rego") {
+			continue
+		}
+
+		fileName := filepath.Join(folder, file.Name())
+		content, err := fileReader.ReadFile(fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		util.Log.Debug("Loading policy %s", fileName)
+		options = append(options, rego.Module(fileName, string(content)))
+	}
+
+	naming, err := loadNamingConventions(folder, fileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options) == 1 && naming == nil {
+		util.Log.Warn("No policies (*.rego) or %s found in %s", namingConventionsFile, folder)
+		return NoPolicies(), nil
+	}
+
+	engines := make([]Engine, 0, 2)
+	if len(options) > 1 {
+		prepared, err := rego.New(options...).PrepareForEval(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("policies in %s could not be compiled: %s", folder, err)
+		}
+		engines = append(engines, &regoEngine{query: &prepared})
+	}
+	if naming != nil {
+		engines = append(engines, naming)
+	}
+
+	if len(engines) == 1 {
+		return engines[0], nil
+	}
+	return &combinedEngine{engines: engines}, nil
+}
+
+func (e *regoEngine) Evaluate(input Input) (violations []string, err error) {
+
+	results, err := e.query.Eval(context.Background(), rego.EvalInput(input))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		for _, expression := range result.Expressions {
+
+			messages, ok := expression.Value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("%s has to be a set of messages, but was %v", query, expression.Value)
+			}
+
+			for _, message := range messages {
+				violations = append(violations, fmt.Sprint(message))
+			}
+		}
+	}
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+func (e *combinedEngine) Evaluate(input Input) (violations []string, err error) {
+
+	for _, engine := range e.engines {
+		found, err := engine.Evaluate(input)
+		if err != nil {
+			return nil, err
+		}
+		violations = append(violations, found...)
+	}
+	sort.Strings(violations)
+
+	return violations, nil
+}
+
+func (e *noPoliciesEngine) Evaluate(input Input) (violations []string, err error) {
+	return nil, nil
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func loadTestPolicies(t *testing.T) Engine {
+	engine, err := LoadPolicies("test-resources/policies", util.NewFileReader())
+	assert.NilError(t, err)
+	return engine
+}
+
+func payload(t *testing.T, content string) interface{} {
+	var result interface{}
+	err := json.Unmarshal([]byte(content), &result)
+	assert.NilError(t, err)
+	return result
+}
+
+func TestEvaluateReportsViolation(t *testing.T) {
+
+	violations, err := loadTestPolicies(t).Evaluate(Input{
+		Api:     "dashboard",
+		Name:    "overview",
+		Payload: payload(t, `{"dashboardMetadata": {"shared": true}}`),
+	})
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, violations, []string{"dashboard overview must not be shared publicly"})
+}
+
+func TestEvaluateWithoutViolation(t *testing.T) {
+
+	engine := loadTestPolicies(t)
+
+	violations, err := engine.Evaluate(Input{
+		Api:     "dashboard",
+		Name:    "overview",
+		Payload: payload(t, `{"dashboardMetadata": {"shared": false}}`),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+
+	violations, err = engine.Evaluate(Input{
+		Api:     "alerting-profile",
+		Name:    "[team] profile",
+		Payload: payload(t, `{}`),
+	})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+}
+
+func TestEvaluateReportsViolationBasedOnName(t *testing.T) {
+
+	violations, err := loadTestPolicies(t).Evaluate(Input{
+		Api:     "alerting-profile",
+		Name:    "profile",
+		Payload: payload(t, `{}`),
+	})
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, violations, []string{"alerting profile names must start with the team prefix"})
+}
+
+func TestLoadPoliciesWithoutFolder(t *testing.T) {
+
+	engine, err := LoadPolicies("", util.NewFileReader())
+	assert.NilError(t, err)
+
+	violations, err := engine.Evaluate(Input{Api: "dashboard"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(violations), 0)
+}
+
+func TestLoadPoliciesFailsOnMissingFolder(t *testing.T) {
+
+	_, err := LoadPolicies("test-resources/does-not-exist", util.NewFileReader())
+	assert.ErrorContains(t, err, "could not be read")
+}
@@ -0,0 +1,7 @@
+package monaco
+
+deny[msg] {
+	input.api == "alerting-profile"
+	not startswith(input.name, "[team]")
+	msg := "alerting profile names must start with the team prefix"
+}
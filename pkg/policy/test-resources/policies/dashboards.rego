@@ -0,0 +1,7 @@
+package monaco
+
+deny[msg] {
+	input.api == "dashboard"
+	input.payload.dashboardMetadata.shared
+	msg := sprintf("dashboard %s must not be shared publicly", [input.name])
+}
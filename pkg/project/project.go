@@ -20,24 +20,62 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/assertion"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
+// projectFileName is the file in the root folder of a project, which declares the projects it depends on and the
+// assertions evaluated after the project was deployed
+const projectFileName = "project.yaml"
+
+// defaultsFileName is the file in the projects root folder, which declares the defaults of properties for all
+// environments and per environment group
+const defaultsFileName = "defaults.yaml"
+
 type Project interface {
 	HasDependencyOn(project Project) bool
 	GetConfigs() []config.Config
 	GetConfig(id string) (config.Config, error)
 	GetId() string
+	GetDeclaredDependencies() []string
+	GetAssertions() []assertion.Assertion
+	GetVariables() []Variable
+}
+
+// Variable is an environment variable the templates of a project use, which is declared in the project file.
+// Variables marked as prompt are asked for in interactive runs if they are not set, secrets are read without echoing
+type Variable struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Prompt      bool   `yaml:"prompt"`
+	Secret      bool   `yaml:"secret"`
 }
 
 type projectImpl struct {
-	id      string
-	configs []config.Config
+	id           string
+	configs      []config.Config
+	dependencies []string
+	assertions   []assertion.Assertion
+	variables    []Variable
+}
+
+type projectYaml struct {
+	Dependencies []string              `yaml:"dependencies"`
+	Assertions   []assertion.Assertion `yaml:"assertions"`
+	Variables    []Variable            `yaml:"variables"`
+	Defaults     map[string]string     `yaml:"defaults"`
+}
+
+type defaultsYaml struct {
+	Global map[string]string            `yaml:"global"`
+	Groups map[string]map[string]string `yaml:"groups"`
 }
 
 type projectBuilder struct {
@@ -47,6 +85,7 @@ type projectBuilder struct {
 	apis              map[string]api.Api
 	configFactory     config.ConfigFactory
 	fileReader        util.FileReader
+	defaults          config.Defaults
 }
 
 // NewProject loads a new project from folder. Returns either project or a reading/sorting error respectively.
@@ -58,6 +97,16 @@ func NewProject(folder string, apis map[string]api.Api, projectRootFolder string
 	// trim path separator from projectRoot
 	projectRootFolder = strings.Trim(projectRootFolder, string(os.PathSeparator))
 
+	projectFile, err := readProjectFile(folder, projectRootFolder, apis, fileReader)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults, err := readDefaultsFile(projectRootFolder, fileReader)
+	if err != nil {
+		return nil, err
+	}
+
 	builder := projectBuilder{
 		projectRootFolder: projectRootFolder,
 		projectId:         folder,
@@ -65,8 +114,9 @@ func NewProject(folder string, apis map[string]api.Api, projectRootFolder string
 		apis:              apis,
 		configFactory:     config.NewConfigFactory(),
 		fileReader:        fileReader,
+		defaults:          config.Defaults{Project: projectFile.Defaults, Groups: defaults.Groups, Global: defaults.Global},
 	}
-	err := builder.readFolder(folder, true)
+	err = builder.readFolder(folder, true)
 	if err != nil {
 		//debug log here?
 		return nil, err
@@ -79,11 +129,73 @@ func NewProject(folder string, apis map[string]api.Api, projectRootFolder string
 	}
 
 	return &projectImpl{
-		id:      folder,
-		configs: builder.configs,
+		id:           folder,
+		configs:      builder.configs,
+		dependencies: projectFile.Dependencies,
+		assertions:   projectFile.Assertions,
+		variables:    projectFile.Variables,
 	}, nil
 }
 
+// readProjectFile reads the ids of the projects declared as dependencies, the assertions and the variables in the
+// project file of the folder. Dependencies are declared relative to the projects root folder, they are returned
+// relative to the working directory. Projects don't need a project file
+func readProjectFile(folder string, projectRootFolder string, apis map[string]api.Api, fileReader util.FileReader) (projectYaml, error) {
+
+	fileName := filepath.Join(folder, projectFileName)
+
+	data, err := fileReader.ReadFile(fileName)
+	if err != nil {
+		return projectYaml{}, nil
+	}
+
+	var parsed projectYaml
+	err = yaml.Unmarshal(data, &parsed)
+	if err != nil {
+		return projectYaml{}, fmt.Errorf("project file %s is invalid: %s", fileName, err)
+	}
+
+	dependencies := make([]string, 0, len(parsed.Dependencies))
+	for _, dependency := range parsed.Dependencies {
+		dependencies = append(dependencies, filepath.Join(projectRootFolder, util.ReplacePathSeparators(dependency)))
+	}
+	parsed.Dependencies = dependencies
+
+	for _, declared := range parsed.Assertions {
+		err = declared.Validate(apis)
+		if err != nil {
+			return projectYaml{}, fmt.Errorf("project file %s is invalid: %s", fileName, err)
+		}
+	}
+
+	for _, variable := range parsed.Variables {
+		if variable.Name == "" {
+			return projectYaml{}, fmt.Errorf("project file %s is invalid: variables need a name", fileName)
+		}
+	}
+
+	return parsed, nil
+}
+
+// readDefaultsFile reads the defaults of the defaults file in the projects root folder, which is optional
+func readDefaultsFile(projectRootFolder string, fileReader util.FileReader) (defaultsYaml, error) {
+
+	fileName := filepath.Join(projectRootFolder, defaultsFileName)
+
+	data, err := fileReader.ReadFile(fileName)
+	if err != nil {
+		return defaultsYaml{}, nil
+	}
+
+	var parsed defaultsYaml
+	err = yaml.UnmarshalStrict(data, &parsed)
+	if err != nil {
+		return defaultsYaml{}, fmt.Errorf("defaults file %s is invalid: %s", fileName, err)
+	}
+
+	return parsed, nil
+}
+
 func (p *projectBuilder) readFolder(folder string, isProjectRoot bool) error {
 	files, err := p.fileReader.ReadDir(folder)
 
@@ -96,6 +208,9 @@ func (p *projectBuilder) readFolder(folder string, isProjectRoot bool) error {
 		fullFileName := filepath.Join(folder, file.Name())
 
 		if file.IsDir() {
+			if isExtensionFilesFolder(folder, file.Name(), files) {
+				continue
+			}
 			err = p.readFolder(fullFileName, false)
 			if err != nil {
 				return err
@@ -117,7 +232,7 @@ func (p *projectBuilder) processYaml(filename string) error {
 		return err
 	}
 
-	err, properties := util.UnmarshalYaml(string(bytes), filename)
+	err, properties, order := util.UnmarshalYamlWithConfigOrder(string(bytes), filename)
 	if util.CheckError(err, "Error while converting file "+filename) {
 		return err
 	}
@@ -127,12 +242,14 @@ func (p *projectBuilder) processYaml(filename string) error {
 		return err
 	}
 
-	err = p.processConfigSection(properties, folderPath)
+	err = p.processConfigSection(properties, order, folderPath)
 
 	return err
 }
 
-func (p *projectBuilder) processConfigSection(properties map[string]map[string]string, folderPath string) error {
+// processConfigSection creates the configs of the config section in the order they are declared in, as given
+// by order. Configs not contained in order are created afterwards, sorted by their id
+func (p *projectBuilder) processConfigSection(properties map[string]map[string]string, order []string, folderPath string) error {
 
 	templates, ok := properties["config"]
 	if !ok {
@@ -140,15 +257,24 @@ func (p *projectBuilder) processConfigSection(properties map[string]map[string]s
 		return errors.New("Property 'config' was not available")
 	}
 
-	for configName, location := range templates {
+	configsByTemplate := make(map[string][]config.Config)
+
+	for _, configName := range orderedConfigIds(templates, order) {
 
-		location = p.standardizeLocation(location, folderPath)
+		location := p.standardizeLocation(templates[configName], folderPath)
 
 		err, api := p.getExtendedInformationFromLocation(location)
 		if util.CheckError(err, "Could not find API fom location") {
 			return err
 		}
 
+		// the templates of variants are located like the template of the config
+		for key, value := range properties[configName] {
+			if config.IsTemplateVariant(key) {
+				properties[configName][key] = p.standardizeLocation(value, folderPath)
+			}
+		}
+
 		config, err := p.configFactory.NewConfig(configName, p.projectId, location, properties, api)
 		if util.CheckError(err, "Could not create config"+configName) {
 			return err
@@ -157,12 +283,111 @@ func (p *projectBuilder) processConfigSection(properties map[string]map[string]s
 		if err != nil {
 			return err
 		}
+		if !p.defaults.IsEmpty() {
+			config = config.WithDefaults(p.defaults)
+		}
+		if p.projectRootFolder != "" {
+			config = config.WithProjectRoot(p.projectRootFolder)
+		}
 
 		p.configs = append(p.configs, config)
+		configsByTemplate[location] = append(configsByTemplate[location], config)
+	}
+
+	// configs sharing a template may define their variables in ways the check does not know, e.g. in templates of
+	// variables, so problems are reported without failing the project
+	for location, configs := range configsByTemplate {
+		if len(configs) > 1 {
+			err := validateSharedTemplate(location, configs, p.defaults)
+			if err != nil {
+				util.Log.Warn("%s", err)
+			}
+		}
 	}
 	return nil
 }
 
+func orderedConfigIds(templates map[string]string, order []string) []string {
+
+	ids := make([]string, 0, len(templates))
+	added := make(map[string]bool, len(templates))
+
+	for _, id := range order {
+		if _, found := templates[id]; found && !added[id] {
+			ids = append(ids, id)
+			added[id] = true
+		}
+	}
+
+	remaining := make([]string, 0)
+	for id := range templates {
+		if !added[id] {
+			remaining = append(remaining, id)
+		}
+	}
+	sort.Strings(remaining)
+
+	return append(ids, remaining...)
+}
+
+// validateSharedTemplate checks that a template used by multiple configs of the same yaml can be rendered for each
+// of them: the template has to use the name of the config, otherwise all configs would be deployed with the
+// same name, and all variables of the template without default have to be defined for every config
+func validateSharedTemplate(location string, configs []config.Config, defaults config.Defaults) error {
+
+	template, err := util.NewTemplate(location)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(configs))
+	for _, config := range configs {
+		ids = append(ids, config.GetId())
+	}
+	sort.Strings(ids)
+
+	variables := template.Variables()
+	templateDefaults := template.Defaults()
+
+	usesName := false
+	for _, variable := range variables {
+		if variable == "name" {
+			usesName = true
+		}
+	}
+	if !usesName {
+		return fmt.Errorf("template %s is used by configs %s, but does not use {{ .name }}", location, strings.Join(ids, ", "))
+	}
+
+	for _, config := range configs {
+		for _, variable := range variables {
+			if _, found := templateDefaults[variable]; found || defaults.Defines(variable) {
+				continue
+			}
+			if !definesProperty(config, variable) {
+				return fmt.Errorf("config %s does not define %s, which is required by the template %s shared with configs %s",
+					config.GetId(), variable, location, strings.Join(ids, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
+// definesProperty checks if the property is defined for the config, either in general or for an environment or group
+func definesProperty(config config.Config, property string) bool {
+
+	for _, properties := range config.GetProperties() {
+		for key := range properties {
+			if key == property || strings.HasPrefix(key, property+".") {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 // standardizeLocation aims to standardize the location of the passed json file
 // When it is called with an absolute path (starting with /), we simply strip the "/" away
 // Otherwise we assume that the location is relative to the given yaml - so it needs to pe prepended with the folder
@@ -220,6 +445,23 @@ func (p *projectBuilder) getConfigTypeFromLocation(location string) (error, api.
 	return errors.New("API was unknown. Not found in " + location), nil
 }
 
+// isExtensionFilesFolder checks if the folder contains further files of the extension with the template
+// <name>.json, which are uploaded together with the extension instead of being read as configs
+func isExtensionFilesFolder(parent string, name string, siblings []os.FileInfo) bool {
+
+	if filepath.Base(parent) != "extension" {
+		return false
+	}
+
+	for _, sibling := range siblings {
+		if !sibling.IsDir() && sibling.Name() == name+".json" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isYaml(file string) bool {
 	return strings.HasSuffix(file, ".yaml")
 }
@@ -255,10 +497,33 @@ func (p *projectImpl) GetId() string {
 	return p.id
 }
 
+// GetVariables returns the variables declared in the project file
+func (p *projectImpl) GetVariables() []Variable {
+	return p.variables
+}
+
+// GetDeclaredDependencies returns the ids of the projects declared as dependencies in the project file
+func (p *projectImpl) GetDeclaredDependencies() []string {
+	return p.dependencies
+}
+
+// GetAssertions returns the assertions evaluated after the project was deployed
+func (p *projectImpl) GetAssertions() []assertion.Assertion {
+	return p.assertions
+}
+
 // HasDependencyOn checks if one project depends on the given parameter config
-// Having a dependency means, that the project having the dependency needs to be applied AFTER the project it depends on
+// Having a dependency means, that the project having the dependency needs to be applied AFTER the project it depends on.
+// Besides references between configs, a project depends on the projects declared in its project file, including
+// all subprojects of a declared project
 func (p *projectImpl) HasDependencyOn(project Project) bool {
 
+	for _, dependency := range p.dependencies {
+		if project.GetId() == dependency || strings.HasPrefix(project.GetId(), dependency+string(os.PathSeparator)) {
+			return true
+		}
+	}
+
 	for _, myConfig := range p.configs {
 		for _, otherConfig := range project.GetConfigs() {
 			if myConfig.HasDependencyOn(otherConfig) {
@@ -31,7 +31,7 @@ import (
 // it also resolves all project dependencies
 // if no -p parameter specified, then it creates a list of all projects
 func LoadProjectsToDeploy(specificProjectToDeploy string, apis map[string]api.Api, path string, fileReader util.FileReader) (projectsToDeploy []Project, err error) {
-	projectsFolder := filepath.Join(".", path)
+	projectsFolder := filepath.Clean(path)
 	projectsToDeploy = make([]Project, 0)
 
 	util.Log.Debug("Reading projects...")
@@ -51,6 +51,11 @@ func LoadProjectsToDeploy(specificProjectToDeploy string, apis map[string]api.Ap
 		availableProjects = append(availableProjects, project)
 	}
 
+	err = validateDeclaredDependencies(availableProjects)
+	if err != nil {
+		return nil, err
+	}
+
 	// return all projects if no projects specified by -p parameter
 	// otherwise only add projects specified by parameter
 	if specificProjectToDeploy == "" {
@@ -81,6 +86,52 @@ func LoadProjectsToDeploy(specificProjectToDeploy string, apis map[string]api.Ap
 	return returnSortedProjects(projectsToDeploy)
 }
 
+// LoadVariables returns the variables declared in the project files of all projects in path, keyed by project id.
+// The configs of the projects are not read, so the variables can be set before the config yamls are rendered
+func LoadVariables(path string, apis map[string]api.Api, fileReader util.FileReader) (map[string][]Variable, error) {
+
+	folders, err := getAllProjectFoldersRecursively(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+
+	variables := make(map[string][]Variable)
+	for _, folder := range folders {
+		projectFile, err := readProjectFile(folder, strings.Trim(path, string(os.PathSeparator)), apis, fileReader)
+		if err != nil {
+			return nil, err
+		}
+		if len(projectFile.Variables) > 0 {
+			variables[folder] = projectFile.Variables
+		}
+	}
+
+	return variables, nil
+}
+
+// validateDeclaredDependencies checks that the dependencies declared by the projects exist
+func validateDeclaredDependencies(projects []Project) error {
+
+	for _, project := range projects {
+		for _, dependency := range project.GetDeclaredDependencies() {
+
+			found := false
+			for _, other := range projects {
+				if other.GetId() == dependency || strings.HasPrefix(other.GetId(), dependency+string(os.PathSeparator)) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				return fmt.Errorf("project %s depends on project %s, which does not exist", project.GetId(), dependency)
+			}
+		}
+	}
+
+	return nil
+}
+
 func returnSortedProjects(projectsToDeploy []Project) ([]Project, error) {
 	util.Log.Debug("Sorting projects...")
 	projectsToDeploy, err := sortProjects(projectsToDeploy)
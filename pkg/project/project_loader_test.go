@@ -25,6 +25,8 @@ import (
 	"gotest.tools/assert"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 )
 
@@ -155,6 +157,127 @@ func TestLoadProjectsWithResolvingDependenciesInProjectsTreeProjectSubprojectWit
 	assert.Equal(t, len(projects), 1, "Check if there is only 1 project in the list.")
 }
 
+/*Test loading of project team
+ * Declared dependencies: team -> shared-zones & platform (platform/base, platform/extra)
+ * Expected: team is deployed after all declared dependencies
+ */
+func TestLoadProjectsWithDeclaredDependencies(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/declared-dependency-test")
+
+	projects, err := LoadProjectsToDeploy("team", api.NewApis(), folder, util.NewFileReader())
+
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(projects), 4, "Check if there are only 4 projects in the list.")
+
+	ps := string(os.PathSeparator)
+	assert.Equal(t, projects[3].GetId(), folder+ps+"team", "Check if `team` is deployed last")
+	assert.DeepEqual(t, projects[3].GetDeclaredDependencies(), []string{folder + ps + "shared-zones", folder + ps + "platform"})
+
+	assertions := projects[3].GetAssertions()
+	assert.Equal(t, len(assertions), 1)
+	assert.Equal(t, assertions[0].Name, "team profile exists")
+	assert.Equal(t, assertions[0].Api, "alerting-profile")
+	assert.Equal(t, *assertions[0].Checks[0].MinCount, 1)
+
+	assert.DeepEqual(t, projects[3].GetVariables(), []Variable{
+		{Name: "INCIDENT_ID", Description: "id of the incident", Prompt: true},
+		{Name: "API_KEY", Prompt: true, Secret: true},
+	})
+}
+
+func TestLoadVariablesWithoutReadingConfigs(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/declared-dependency-test")
+
+	variables, err := LoadVariables(folder, api.NewApis(), util.NewFileReader())
+	assert.NilError(t, err)
+
+	ps := string(os.PathSeparator)
+	assert.DeepEqual(t, variables, map[string][]Variable{
+		folder + ps + "team": {
+			{Name: "INCIDENT_ID", Description: "id of the incident", Prompt: true},
+			{Name: "API_KEY", Prompt: true, Secret: true},
+		},
+	})
+}
+
+func TestLoadProjectsFailsOnUnknownDeclaredDependency(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/unknown-dependency-test")
+
+	_, err := LoadProjectsToDeploy("team", api.NewApis(), folder, util.NewFileReader())
+
+	assert.ErrorContains(t, err, "depends on project "+util.ReplacePathSeparators(folder+"/missing")+", which does not exist")
+}
+
+func TestLoadProjectsWithSharedTemplate(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/shared-template-test")
+
+	projects, err := LoadProjectsToDeploy("project", api.NewApis(), folder, util.NewFileReader())
+
+	assert.NilError(t, err)
+	assert.Equal(t, len(projects[0].GetConfigs()), 2)
+}
+
+func TestLoadProjectsWarnsOnSharedTemplateWithoutName(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/shared-template-without-name-test")
+
+	projects, err := LoadProjectsToDeploy("project", api.NewApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	err = validateSharedTemplate(sharedTemplateOf(t, projects), projects[0].GetConfigs(), config.Defaults{})
+	assert.ErrorContains(t, err, "is used by configs zone-a, zone-b, but does not use {{ .name }}")
+}
+
+func TestLoadProjectsWarnsOnSharedTemplateWithMissingVariable(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/shared-template-missing-variable-test")
+
+	projects, err := LoadProjectsToDeploy("project", api.NewApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	err = validateSharedTemplate(sharedTemplateOf(t, projects), projects[0].GetConfigs(), config.Defaults{})
+	assert.ErrorContains(t, err, "config zone-b does not define hostGroup")
+}
+
+// sharedTemplateOf returns the template shared by all configs of the only project
+func sharedTemplateOf(t *testing.T, projects []Project) string {
+
+	assert.Equal(t, len(projects), 1)
+	configs := projects[0].GetConfigs()
+	assert.Assert(t, len(configs) > 1)
+
+	return configs[0].GetFilePath()
+}
+
+func TestLoadProjectsResolvesPropertiesFromDefaults(t *testing.T) {
+	folder := util.ReplacePathSeparators("test-resources/defaults-test")
+
+	projects, err := LoadProjectsToDeploy("project", api.NewApis(), folder, util.NewFileReader())
+	assert.NilError(t, err)
+
+	development := environment.NewEnvironment("development", "Dev", "development", "https://url/to/dev/environment", "DEV")
+	production := environment.NewEnvironment("production", "Prod", "production", "https://url/to/prod/environment", "PROD")
+
+	for _, c := range projects[0].GetConfigs() {
+		if c.GetId() != "zone-b" {
+			continue
+		}
+
+		hostGroup, err := c.GetPropertyForEnvironment(development, "hostGroup", nil)
+		assert.NilError(t, err)
+		assert.Equal(t, hostGroup, "HOST_GROUP-GLOBAL")
+
+		hostGroup, err = c.GetPropertyForEnvironment(production, "hostGroup", nil)
+		assert.NilError(t, err)
+		assert.Equal(t, hostGroup, "HOST_GROUP-PRODUCTION")
+
+		contact, err := c.GetPropertyForEnvironment(production, "contact", nil)
+		assert.NilError(t, err)
+		assert.Equal(t, contact, "team")
+		return
+	}
+	t.Fatal("config zone-b was not loaded")
+}
+
 func TestFilterProjectsWithSubproject(t *testing.T) {
 	ca := util.ReplacePathSeparators("caveman/anjie")
 	cag := util.ReplacePathSeparators("caveman/anjie/garkbit")
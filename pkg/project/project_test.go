@@ -163,7 +163,7 @@ func TestProcessConfigSection(t *testing.T) {
 	factory.EXPECT().NewConfig("test2", "testProject", profile, m, testAlertingProfileApi).Times(1)
 
 	folderPath := util.ReplacePathSeparators("test/management-zone")
-	err := builder.processConfigSection(m, folderPath)
+	err := builder.processConfigSection(m, nil, folderPath)
 	assert.NilError(t, err)
 }
 
@@ -182,12 +182,37 @@ func TestProcessConfigSectionWithProjectRootParameter(t *testing.T) {
 
 	zoneA := util.ReplacePathSeparators("testProjectsRoot/test/management-zone/zoneA.json")
 	profile := util.ReplacePathSeparators("testProjectsRoot/test/alerting-profile/profile.json")
-	factory.EXPECT().NewConfig("testconfig1", "test", zoneA, m, testManagementZoneApi).Times(1)
-	factory.EXPECT().NewConfig("testconfig2", "test", profile, m, testAlertingProfileApi).Times(1)
+	factory.EXPECT().NewConfig("testconfig1", "test", zoneA, m, testManagementZoneApi).Times(1).
+		Return(config.GetMockConfig("testconfig1", "test", nil, m, testManagementZoneApi, zoneA), nil)
+	factory.EXPECT().NewConfig("testconfig2", "test", profile, m, testAlertingProfileApi).Times(1).
+		Return(config.GetMockConfig("testconfig2", "test", nil, m, testAlertingProfileApi, profile), nil)
 
 	folderPath := util.ReplacePathSeparators("test/management-zone")
-	err := builder.processConfigSection(m, folderPath)
+	err := builder.processConfigSection(m, nil, folderPath)
 	assert.NilError(t, err)
+	assert.Equal(t, len(builder.configs), 2)
+}
+
+func TestProcessConfigSectionLocatesTemplateVariants(t *testing.T) {
+
+	factory := config.CreateConfigMockFactory(t)
+	fileReaderMock := util.CreateFileReaderMock(t)
+	builder := testCreateProjectBuilderWithMock(factory, fileReaderMock, "testProject", "")
+
+	m := make(map[string]map[string]string)
+
+	m["config"] = map[string]string{"test1": "zoneA.json"}
+	m["test1"] = map[string]string{"template.v2": "zoneA-v2.json", "template.v2.when": "version >= 1.230"}
+
+	zoneA := util.ReplacePathSeparators("test/management-zone/zoneA.json")
+	factory.EXPECT().NewConfig("test1", "testProject", zoneA, m, testManagementZoneApi).Times(1)
+
+	folderPath := util.ReplacePathSeparators("test/management-zone")
+	err := builder.processConfigSection(m, nil, folderPath)
+	assert.NilError(t, err)
+
+	assert.Equal(t, m["test1"]["template.v2"], util.ReplacePathSeparators("test/management-zone/zoneA-v2.json"))
+	assert.Equal(t, m["test1"]["template.v2.when"], "version >= 1.230")
 }
 
 func TestIsYaml(t *testing.T) {
@@ -262,3 +287,11 @@ func TestProcessYaml(t *testing.T) {
 	config := builder.configs[0]
 	assert.Check(t, config != nil)
 }
+
+func TestOrderedConfigIds(t *testing.T) {
+
+	templates := map[string]string{"c": "c.json", "b": "b.json", "a": "a.json", "d": "d.json"}
+
+	assert.DeepEqual(t, orderedConfigIds(templates, []string{"c", "a", "c", "unknown"}), []string{"c", "a", "b", "d"})
+	assert.DeepEqual(t, orderedConfigIds(templates, nil), []string{"a", "b", "c", "d"})
+}
@@ -26,7 +26,7 @@ import (
 func sortProjects(projects []Project) (sorted []Project, err error) {
 	sorted = []Project{}
 	incomingDeps, inDegrees := calculateIncomingProjectDependencies(projects)
-	reverse, err, errorOn := topologySort(incomingDeps, inDegrees)
+	reverse, err, errorOn := topologySort(incomingDeps, inDegrees, nil)
 	if err != nil {
 		return sorted, fmt.Errorf("failed to sort projects, circular dependency on project %s detected, please check dependencies in project configs", projects[errorOn].GetId())
 	}
@@ -62,7 +62,13 @@ func calculateIncomingProjectDependencies(projects []Project) (adjacencyMatrix [
 func sortConfigurations(configs []config.Config) (sorted []config.Config, err error) {
 	sorted = []config.Config{}
 	incomingDeps, inDegrees := calculateIncomingConfigDependencies(configs)
-	reverse, err, errorOn := topologySort(incomingDeps, inDegrees)
+
+	priorities := make([]int, len(configs))
+	for i, c := range configs {
+		priorities[i] = c.GetApi().GetPriority()
+	}
+
+	reverse, err, errorOn := topologySort(incomingDeps, inDegrees, priorities)
 	if err != nil {
 		util.Log.Debug(err.Error())
 		return sorted, fmt.Errorf("failed to sort configs, circular dependency on config %s detected, please check dependencies", configs[errorOn].GetFullQualifiedId())
@@ -94,18 +100,54 @@ func calculateIncomingConfigDependencies(configs []config.Config) (adjacencyMatr
 		}
 	}
 
+	// configs of apis depending on other apis are deployed after them, unless they are referenced the other way round
+	for i := range configs {
+		for j := range configs {
+			if i != j && !adjacencyMatrix[i][j] && !adjacencyMatrix[j][i] && configs[j].GetApi().DependsOn(configs[i].GetApi()) {
+				util.Log.Debug("\t\t%s is deployed after %s", configs[j].GetFullQualifiedId(), configs[i].GetFullQualifiedId())
+				adjacencyMatrix[i][j] = true
+				inDegrees[i]++
+			}
+		}
+	}
+
+	// configs of ordered apis are deployed in the order they are declared, unless they are referenced the other way round
+	previous := make(map[string]int)
+	for j := range configs {
+		a := configs[j].GetApi()
+		if !a.IsOrdered() {
+			continue
+		}
+		if i, found := previous[a.GetId()]; found && !adjacencyMatrix[i][j] && !adjacencyMatrix[j][i] {
+			util.Log.Debug("\t\t%s is deployed after %s", configs[j].GetFullQualifiedId(), configs[i].GetFullQualifiedId())
+			adjacencyMatrix[i][j] = true
+			inDegrees[i]++
+		}
+		previous[a.GetId()] = j
+	}
+
 	return adjacencyMatrix, inDegrees
 }
 
 // https://en.wikipedia.org/wiki/Topological_sorting#Kahn's_algorithm
-func topologySort(incomingEdges [][]bool, inDegrees []int) (topoSorted []int, err error, errorOnId int) {
+// The result is in reverse order. If priorities are given, the node of the highest priority is taken next of all
+// nodes without remaining edges, so that nodes of lower priorities come last in the result, i.e. first once reversed
+func topologySort(incomingEdges [][]bool, inDegrees []int, priorities []int) (topoSorted []int, err error, errorOnId int) {
 
 	nodes := getAllLeaves(inDegrees)
 
 	topoSorted = []int{}
 	for len(nodes) > 0 {
-		cur := nodes[0]
-		nodes = nodes[1:]
+		next := 0
+		if priorities != nil {
+			for n := range nodes {
+				if priorities[nodes[n]] > priorities[nodes[next]] {
+					next = n
+				}
+			}
+		}
+		cur := nodes[next]
+		nodes = append(nodes[:next:next], nodes[next+1:]...)
 		topoSorted = append(topoSorted, cur)
 		for i := range inDegrees {
 			if incomingEdges[i][cur] {
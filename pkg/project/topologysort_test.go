@@ -67,6 +67,86 @@ func TestSortingByConfigDependencyWithRootDirectory(t *testing.T) {
 	assert.Check(t, configB.HasDependencyOn(configA))
 }
 
+func TestSortingDeploysRequestAttributesBeforeDependingApis(t *testing.T) {
+
+	metric := createTestConfig("metric", util.ReplacePathSeparators("projects/service/calculated-metrics-service/"), "foo")
+	naming := createTestConfig("naming", util.ReplacePathSeparators("projects/service/request-naming-service/"), "foo")
+	attribute := createTestConfig("attribute", util.ReplacePathSeparators("projects/service/request-attributes/"), "foo")
+
+	configs, err := sortConfigurations([]config.Config{metric, naming, attribute})
+	assert.NilError(t, err)
+
+	assert.Equal(t, attribute, configs[0])
+}
+
+func TestSortingKeepsReferencesAgainstApiDependencies(t *testing.T) {
+
+	pathMetric := util.ReplacePathSeparators("projects/service/calculated-metrics-service/")
+	metric := createTestConfig("metric", pathMetric, "foo")
+	attribute := createTestConfig("attribute", util.ReplacePathSeparators("projects/service/request-attributes/"), pathMetric+"metric.name")
+
+	configs, err := sortConfigurations([]config.Config{attribute, metric})
+	assert.NilError(t, err)
+
+	assert.Equal(t, metric, configs[0])
+	assert.Equal(t, attribute, configs[1])
+}
+
+func TestSortingKeepsDeclaredOrderOfNamingRules(t *testing.T) {
+
+	path := util.ReplacePathSeparators("projects/naming/conditional-naming-host/")
+	first := createTestConfig("first", path, "foo")
+	second := createTestConfig("second", path, "foo")
+	third := createTestConfig("third", path, path+"second.id")
+	attribute := createTestConfig("attribute", util.ReplacePathSeparators("projects/service/request-attributes/"), "foo")
+
+	configs, err := sortConfigurations([]config.Config{first, attribute, second, third})
+	assert.NilError(t, err)
+
+	positions := make(map[config.Config]int)
+	for i, config := range configs {
+		positions[config] = i
+	}
+	assert.Assert(t, positions[first] < positions[second])
+	assert.Assert(t, positions[second] < positions[third])
+}
+
+func TestSortingDeploysApisByPriority(t *testing.T) {
+
+	dashboard := createTestConfig("overview", util.ReplacePathSeparators("projects/team/dashboard/"), "foo")
+	profile := createTestConfig("profile", util.ReplacePathSeparators("projects/team/alerting-profile/"), "foo")
+	application := createTestConfig("app", util.ReplacePathSeparators("projects/team/application/"), "foo")
+	tag := createTestConfig("tag", util.ReplacePathSeparators("projects/team/auto-tag/"), "foo")
+	zone := createTestConfig("zone", util.ReplacePathSeparators("projects/team/management-zone/"), "foo")
+
+	configs, err := sortConfigurations([]config.Config{dashboard, profile, application, tag, zone})
+	assert.NilError(t, err)
+
+	positions := make(map[config.Config]int)
+	for i, config := range configs {
+		positions[config] = i
+	}
+	assert.Assert(t, positions[zone] < positions[application])
+	assert.Assert(t, positions[tag] < positions[application])
+	assert.Assert(t, positions[application] < positions[profile])
+	assert.Assert(t, positions[profile] < positions[dashboard])
+}
+
+func TestSortingKeepsReferencesAgainstPriorities(t *testing.T) {
+
+	pathDashboard := util.ReplacePathSeparators("projects/team/dashboard/")
+	dashboard := createTestConfig("overview", pathDashboard, "foo")
+	zone := createTestConfig("zone", util.ReplacePathSeparators("projects/team/management-zone/"), pathDashboard+"overview.id")
+	tag := createTestConfig("tag", util.ReplacePathSeparators("projects/team/auto-tag/"), "foo")
+
+	configs, err := sortConfigurations([]config.Config{zone, tag, dashboard})
+	assert.NilError(t, err)
+
+	assert.Equal(t, configs[0], tag)
+	assert.Equal(t, configs[1], dashboard)
+	assert.Equal(t, configs[2], zone)
+}
+
 func TestFailsOnCircularConfigDependency(t *testing.T) {
 
 	pathA := util.ReplacePathSeparators("projects/infrastructure/management-zone/")
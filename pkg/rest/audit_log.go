@@ -0,0 +1,124 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// auditLogsPath is the path of the audit log api, relative to the environment url
+const auditLogsPath = "/api/v2/auditlogs"
+
+// AuditLogClient reads the changes of configs recorded by the audit log of an environment.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type AuditLogClient interface {
+
+	// ListConfigAuditLogs lists the changes of configs recorded since the given time, oldest first
+	ListConfigAuditLogs(from time.Time) (entries []AuditLogEntry, err error)
+}
+
+// AuditLogEntry is a change of a config recorded by the audit log
+type AuditLogEntry struct {
+	EventType  string `json:"eventType"`
+	EntityId   string `json:"entityId"`
+	User       string `json:"user"`
+	UserType   string `json:"userType"`
+	UserOrigin string `json:"userOrigin"`
+	Timestamp  int64  `json:"timestamp"`
+}
+
+// ObjectId returns the id of the changed object. The audit log prefixes it by the type of the object,
+// e.g. MANAGEMENT_ZONE: 4711
+func (e AuditLogEntry) ObjectId() string {
+
+	if i := strings.LastIndex(e.EntityId, ": "); i >= 0 {
+		return e.EntityId[i+2:]
+	}
+	return e.EntityId
+}
+
+// Time returns when the change was made
+func (e AuditLogEntry) Time() time.Time {
+	return time.Unix(0, e.Timestamp*int64(time.Millisecond)).UTC()
+}
+
+// Source describes who made the change, e.g. user jane.doe@example.com (webui)
+func (e AuditLogEntry) Source() string {
+
+	kind := "user"
+	if strings.Contains(strings.ToUpper(e.UserType), "TOKEN") {
+		kind = "token"
+	}
+
+	if e.UserOrigin == "" {
+		return kind + " " + e.User
+	}
+	return kind + " " + e.User + " (" + e.UserOrigin + ")"
+}
+
+type auditLogListResponse struct {
+	AuditLogs   []AuditLogEntry `json:"auditLogs"`
+	NextPageKey string          `json:"nextPageKey"`
+}
+
+func (d *dynatraceClientImpl) ListConfigAuditLogs(from time.Time) (entries []AuditLogEntry, err error) {
+
+	query := url.Values{}
+	query.Set("filter", `category("CONFIG")`)
+	query.Set("from", strconv.FormatInt(from.UnixNano()/int64(time.Millisecond), 10))
+	query.Set("sort", "timestamp")
+	query.Set("pageSize", "1000")
+
+	for {
+		resp, err := d.get(d.environmentUrl + auditLogsPath + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var page auditLogListResponse
+		err = json.Unmarshal(resp.Body, &page)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal audit log: %s", err)
+		}
+
+		entries = append(entries, page.AuditLogs...)
+
+		if page.NextPageKey == "" {
+			return entries, nil
+		}
+
+		// subsequent pages are requested by the page key only
+		query = url.Values{}
+		query.Set("nextPageKey", page.NextPageKey)
+	}
+}
+
+// PublicTokenIdentifier returns the public part of a token, e.g. dt0c01.ABC for dt0c01.ABC.XYZ, which the audit log
+// records as user of changes made with the token
+func PublicTokenIdentifier(token string) string {
+
+	parts := strings.SplitN(token, ".", 3)
+	if len(parts) < 3 {
+		return token
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,63 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestListConfigAuditLogsFollowsPages(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, auditLogsPath)
+		if r.URL.Query().Get("nextPageKey") == "" {
+			assert.Equal(t, r.URL.Query().Get("filter"), `category("CONFIG")`)
+			assert.Equal(t, r.URL.Query().Get("from"), "1600000000000")
+			_, _ = w.Write([]byte(`{"auditLogs": [{"entityId": "MANAGEMENT_ZONE: 4711", "user": "jane", "userType": "USER_NAME", "userOrigin": "webui", "timestamp": 1600000001000}], "nextPageKey": "page2"}`))
+		} else {
+			assert.Equal(t, r.URL.Query().Get("filter"), "")
+			_, _ = w.Write([]byte(`{"auditLogs": [{"entityId": "ALERTING_PROFILE: 42", "user": "dt0c01.ABC", "userType": "PUBLIC_TOKEN_IDENTIFIER", "timestamp": 1600000002000}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	entries, err := client.(AuditLogClient).ListConfigAuditLogs(time.Unix(1600000000, 0))
+	assert.NilError(t, err)
+	assert.Equal(t, len(entries), 2)
+
+	assert.Equal(t, entries[0].ObjectId(), "4711")
+	assert.Equal(t, entries[0].Source(), "user jane (webui)")
+	assert.Equal(t, entries[0].Time(), time.Unix(1600000001, 0).UTC())
+
+	assert.Equal(t, entries[1].ObjectId(), "42")
+	assert.Equal(t, entries[1].Source(), "token dt0c01.ABC")
+}
+
+func TestPublicTokenIdentifier(t *testing.T) {
+	assert.Equal(t, PublicTokenIdentifier("dt0c01.ABC.SECRET"), "dt0c01.ABC")
+	assert.Equal(t, PublicTokenIdentifier("legacy-token"), "legacy-token")
+}
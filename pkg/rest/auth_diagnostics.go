@@ -0,0 +1,101 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// tokenLookupPath is the endpoint returning the metadata of an api token
+const tokenLookupPath = "/api/v1/tokens/lookup"
+
+type tokenMetadata struct {
+	Name    string   `json:"name"`
+	Revoked bool     `json:"revoked"`
+	Expires *int64   `json:"expires"`
+	Scopes  []string `json:"scopes"`
+}
+
+// diagnose extends errors of requests rejected with 401 or 403 by a diagnosis of the token, as the status code
+// alone does not tell whether the token is expired, lacks a scope or belongs to a different environment
+func (d *dynatraceClientImpl) diagnose(err error) error {
+
+	var responseErr ResponseError
+	if !errors.As(err, &responseErr) {
+		return err
+	}
+	if responseErr.StatusCode != http.StatusUnauthorized && responseErr.StatusCode != http.StatusForbidden {
+		return err
+	}
+
+	d.diagnosisOnce.Do(func() {
+		d.diagnosis = d.diagnoseToken()
+	})
+	if d.diagnosis == "" {
+		return err
+	}
+
+	return fmt.Errorf("%w\n    Token: %s", err, d.diagnosis)
+}
+
+// diagnoseToken looks up the metadata of the token. Returns an empty string if nothing can be told about the token
+func (d *dynatraceClientImpl) diagnoseToken() string {
+
+	body, err := json.Marshal(struct {
+		Token string `json:"token"`
+	}{d.token})
+	if err != nil {
+		return ""
+	}
+
+	resp := post(d.client, d.environmentUrl+tokenLookupPath, string(body), d.token)
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Sprintf("the token is not accepted by %s. It is either expired, revoked or was created for a different environment", d.environmentUrl)
+	}
+	if !success(resp) {
+		return ""
+	}
+
+	var token tokenMetadata
+	err = json.Unmarshal(resp.Body, &token)
+	if err != nil {
+		return ""
+	}
+
+	return describeToken(token, time.Now())
+}
+
+func describeToken(token tokenMetadata, now time.Time) string {
+
+	if token.Revoked {
+		return fmt.Sprintf("the token '%s' has been revoked", token.Name)
+	}
+
+	if token.Expires != nil {
+		expires := time.Unix(0, *token.Expires*int64(time.Millisecond))
+		if expires.Before(now) {
+			return fmt.Sprintf("the token '%s' expired on %s", token.Name, expires.UTC().Format(time.RFC3339))
+		}
+	}
+
+	return fmt.Sprintf("the token '%s' is valid, but lacks a permission required by this request. Its scopes are: %s", token.Name, strings.Join(token.Scopes, ", "))
+}
@@ -0,0 +1,101 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func TestForbiddenRequestReportsScopesOfToken(t *testing.T) {
+
+	lookups := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenLookupPath {
+			lookups++
+			_, _ = w.Write([]byte(`{"name": "monaco", "revoked": false, "scopes": ["DataExport", "ReadConfig"]}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	testApi := api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+
+	_, err = client.List(testApi)
+	assert.ErrorContains(t, err, "the token 'monaco' is valid, but lacks a permission required by this request. Its scopes are: DataExport, ReadConfig")
+
+	_, err = client.UpsertById(testApi, "id", "name", []byte("{}"))
+	assert.ErrorContains(t, err, "Its scopes are: DataExport, ReadConfig")
+
+	assert.Equal(t, lookups, 1)
+}
+
+func TestUnauthorizedRequestReportsTokenNotAccepted(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	err = client.DeleteById(api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"), "id")
+	assert.ErrorContains(t, err, "the token is not accepted by "+server.URL)
+}
+
+func TestOtherFailuresAreNotDiagnosed(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == tokenLookupPath {
+			t.Fatal("token must not be looked up")
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.List(api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"))
+	assert.Assert(t, err != nil)
+	assert.Assert(t, !strings.Contains(err.Error(), "Token:"))
+}
+
+func TestDescribeToken(t *testing.T) {
+
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	expired := now.Add(-time.Hour).UnixNano() / int64(time.Millisecond)
+	valid := now.Add(time.Hour).UnixNano() / int64(time.Millisecond)
+
+	assert.Equal(t, describeToken(tokenMetadata{Name: "t", Revoked: true}, now), "the token 't' has been revoked")
+	assert.Equal(t, describeToken(tokenMetadata{Name: "t", Expires: &expired}, now), "the token 't' expired on 2021-02-28T23:00:00Z")
+	assert.Equal(t, describeToken(tokenMetadata{Name: "t", Expires: &valid, Scopes: []string{"ReadConfig"}}, now),
+		"the token 't' is valid, but lacks a permission required by this request. Its scopes are: ReadConfig")
+}
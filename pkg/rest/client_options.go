@@ -0,0 +1,77 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import "time"
+
+// ClientOptions configure how a DynatraceClient deals with failing requests
+type ClientOptions struct {
+
+	// ConflictRetries is the number of times an update is retried after the server reported
+	// a conflicting concurrent modification (HTTP 409)
+	ConflictRetries int
+
+	// NameMatching defines how names are compared when configs are looked up by name
+	NameMatching NameMatching
+
+	// NameCache keeps the configs listed per api across monaco runs, nothing is kept if it is nil
+	NameCache *NameCache
+
+	// MaxConcurrentRequests is the number of requests sent to the environment at the same time, as long as the
+	// server does not throttle them. Once it does, fewer requests are sent concurrently. Defaults to 8 if not set
+	MaxConcurrentRequests int
+
+	// StatusPolicy classifies the status codes of failed responses per api and operation, built-in
+	// classifications apply if it is nil
+	StatusPolicy *StatusPolicy
+
+	// HttpCache keeps the responses of GET requests across monaco runs, nothing is kept if it is nil
+	HttpCache *HttpCache
+
+	// RequestTimeout limits the time of a request to the apis including reading its response, requests are not
+	// limited if it is not set. It does not apply to extension uploads
+	RequestTimeout time.Duration
+
+	// ExtensionUploadTimeout limits the time of uploading an extension archive, which is a lot larger than the
+	// payloads of other apis. Defaults to 5 minutes if not set
+	ExtensionUploadTimeout time.Duration
+
+	// ExtensionUploadRetries is the number of times an extension upload is retried after it failed without
+	// response, e.g. as it timed out, or with a server error (HTTP 5xx)
+	ExtensionUploadRetries int
+
+	// WaitForExtensions waits after uploading an extension until its instances are initialized, for up to 30
+	// seconds. Uploads return right away if it is not set
+	WaitForExtensions bool
+
+	// Deprecations collects the endpoints the server flags as deprecated, nothing is collected if it is nil
+	Deprecations *Deprecations
+
+	// Replay records the requests sent and the responses received, e.g. to attach them to an issue, nothing is
+	// recorded if it is nil
+	Replay *Replay
+}
+
+// DefaultClientOptions returns the options used by NewDynatraceClient
+func DefaultClientOptions() ClientOptions {
+	return ClientOptions{
+		ConflictRetries:        3,
+		MaxConcurrentRequests:  maxConcurrentListRequests,
+		ExtensionUploadTimeout: defaultExtensionUploadTimeout,
+		ExtensionUploadRetries: 2,
+	}
+}
@@ -0,0 +1,108 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientPool hands out one DynatraceClient per environment and token, so that all goroutines of a process embedding
+// monaco share the http transport, the cache of listed configs and the limiter of concurrent requests of an
+// environment instead of creating a new client per operation. Clients older than the time to live of the pool are
+// replaced by new ones, as their listed configs may miss objects created or deleted by others meanwhile. It is safe
+// for concurrent use
+type ClientPool struct {
+	options ClientOptions
+	ttl     time.Duration
+	now     func() time.Time
+
+	lock    sync.Mutex
+	clients map[clientPoolKey]pooledClient
+}
+
+type clientPoolKey struct {
+	environmentUrl string
+	token          string
+}
+
+// pooledClient holds a client of the pool and the time it was created at
+type pooledClient struct {
+	client  DynatraceClient
+	created time.Time
+}
+
+// NewClientPool creates an empty pool, whose clients are created with the given options and replaced once they are
+// older than the given time to live
+func NewClientPool(options ClientOptions, ttl time.Duration) *ClientPool {
+	return &ClientPool{
+		options: options,
+		ttl:     ttl,
+		now:     time.Now,
+		clients: make(map[clientPoolKey]pooledClient),
+	}
+}
+
+// Get returns the client of the environment reachable under environmentUrl, creating it on first use or once the
+// pooled one expired
+func (p *ClientPool) Get(environmentUrl string, token string) (DynatraceClient, error) {
+
+	key := clientPoolKey{environmentUrl: strings.TrimSuffix(environmentUrl, "/"), token: token}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := p.now()
+	for k, pooled := range p.clients {
+		if now.Sub(pooled.created) > p.ttl {
+			delete(p.clients, k)
+		}
+	}
+
+	if pooled, found := p.clients[key]; found {
+		return pooled.client, nil
+	}
+
+	client, err := NewDynatraceClientWithOptions(environmentUrl, token, p.options)
+	if err != nil {
+		return nil, err
+	}
+
+	p.clients[key] = pooledClient{client: client, created: now}
+	return client, nil
+}
+
+// Remove drops the client of the environment from the pool, e.g. after its token has been rotated. Goroutines still
+// holding the client can keep using it
+func (p *ClientPool) Remove(environmentUrl string, token string) {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.clients, clientPoolKey{environmentUrl: strings.TrimSuffix(environmentUrl, "/"), token: token})
+}
+
+// Reset drops all clients from the pool, e.g. after the environments were changed by others. Goroutines still
+// holding clients can keep using them
+func (p *ClientPool) Reset() {
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.clients = make(map[clientPoolKey]pooledClient)
+}
@@ -0,0 +1,114 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestClientPoolReusesClientsPerEnvironment(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	pool := NewClientPool(DefaultClientOptions(), time.Minute)
+
+	clients := make([]DynatraceClient, 8)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := pool.Get(server.URL, "token")
+			assert.Check(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		assert.Assert(t, client == clients[0], "all goroutines must share one client")
+	}
+
+	withSlash, err := pool.Get(server.URL+"/", "token")
+	assert.NilError(t, err)
+	assert.Assert(t, withSlash == clients[0])
+
+	for _, client := range []DynatraceClient{clients[0], withSlash} {
+		_, err := client.List(testManagementZoneApi)
+		assert.NilError(t, err)
+	}
+	assert.Equal(t, 1, listRequests, "the pooled client must cache the list")
+
+	otherToken, err := pool.Get(server.URL, "other")
+	assert.NilError(t, err)
+	assert.Assert(t, otherToken != clients[0], "clients of different tokens must not be shared")
+
+	pool.Remove(server.URL, "token")
+	recreated, err := pool.Get(server.URL, "token")
+	assert.NilError(t, err)
+	assert.Assert(t, recreated != clients[0])
+}
+
+func TestClientPoolDoesNotKeepFailedClients(t *testing.T) {
+
+	pool := NewClientPool(DefaultClientOptions(), time.Minute)
+
+	_, err := pool.Get("https://url/to/environment", "")
+	assert.ErrorContains(t, err, "no token provided")
+	assert.Equal(t, 0, len(pool.clients))
+}
+
+func TestClientPoolReplacesExpiredClients(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	pool := NewClientPool(DefaultClientOptions(), time.Minute)
+	pool.now = func() time.Time { return now }
+
+	client, err := pool.Get(server.URL, "token")
+	assert.NilError(t, err)
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+
+	now = now.Add(30 * time.Second)
+	unexpired, err := pool.Get(server.URL, "token")
+	assert.NilError(t, err)
+	assert.Assert(t, unexpired == client)
+
+	now = now.Add(time.Minute)
+	expired, err := pool.Get(server.URL, "token")
+	assert.NilError(t, err)
+	assert.Assert(t, expired != client)
+
+	_, err = expired.List(testManagementZoneApi)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, listRequests, "the configs must be listed again after the client expired")
+
+	pool.Reset()
+	assert.Equal(t, 0, len(pool.clients))
+}
@@ -0,0 +1,51 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// clusterVersionPath is the path of the api returning the version of the cluster, relative to the environment url
+const clusterVersionPath = "/api/v1/config/clusterversion"
+
+// ClusterVersionClient reads the version of the Dynatrace cluster an environment runs on.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type ClusterVersionClient interface {
+
+	// GetClusterVersion returns the version of the cluster, e.g. 1.232.0.20211214-112149
+	GetClusterVersion() (version string, err error)
+}
+
+func (d *dynatraceClientImpl) GetClusterVersion() (version string, err error) {
+
+	resp, err := d.get(d.environmentUrl + clusterVersionPath)
+	if err != nil {
+		return "", err
+	}
+
+	var body struct {
+		Version string `json:"version"`
+	}
+	err = json.Unmarshal(resp.Body, &body)
+	if err != nil {
+		return "", fmt.Errorf("cannot unmarshal cluster version: %s", err)
+	}
+
+	return body.Version, nil
+}
@@ -0,0 +1,43 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestGetClusterVersion(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, clusterVersionPath)
+		_, _ = w.Write([]byte(`{"version": "1.232.0.20211214-112149"}`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	version, err := client.(ClusterVersionClient).GetClusterVersion()
+	assert.NilError(t, err)
+	assert.Equal(t, version, "1.232.0.20211214-112149")
+}
@@ -24,17 +24,75 @@ import (
 	"time"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
-	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/config"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 )
 
+// versionFields hold the version of an object, which the server uses to detect conflicting concurrent modifications
+var versionFields = []string{"metadata", "version"}
+
+// conflictRetryDelay is the time waited before the first re-sync after a conflict, it grows with every attempt, so
+// that a concurrent modification still in progress has finished before the current version is fetched
+const conflictRetryDelay = 250 * time.Millisecond
+
+// conflictRetrySleep waits before re-syncing after a conflict, it is replaced in tests
+var conflictRetrySleep = time.Sleep
+
+// UpsertDynatraceObject creates the given object, or updates the object with the same name.
+//
+// Deprecated: Use DynatraceClient.UpsertByName instead, which reuses connections and the listed objects
 func UpsertDynatraceObject(apiPath string, objectName string, configType string, configJson string, apiToken string) (api.DynatraceEntity, error) {
-	isDashBoard, existingObjectId, err := GetObjectIdIfAlreadyExists(configType, apiPath, objectName, apiToken)
-	var dtEntity api.DynatraceEntity
+
+	_, existingObjectId, err := GetObjectIdIfAlreadyExists(configType, apiPath, objectName, apiToken, NameMatching{})
 	if err != nil {
-		return dtEntity, err
+		return api.DynatraceEntity{}, err
 	}
+
+	return upsertDynatraceObject(&http.Client{}, apiPath, objectName, configType, configJson, apiToken, existingObjectId, 0)
+}
+
+// DeletableConfig is the part of config.Config DeleteDynatraceObject needs, the config package itself can not be
+// imported, as it depends on this package
+type DeletableConfig interface {
+	GetObjectNameForEnvironment(environment environment.Environment, dict map[string]api.DynatraceEntity) (string, error)
+	GetApi() api.Api
+	GetType() string
+}
+
+// DeleteDynatraceObject deletes the object of the config in the environment, if it exists.
+//
+// Deprecated: Use DynatraceClient.DeleteByName instead
+func DeleteDynatraceObject(config DeletableConfig, environment environment.Environment) error {
+
+	emptyDict := make(map[string]api.DynatraceEntity)
+	name, err := config.GetObjectNameForEnvironment(environment, emptyDict)
+	if err != nil {
+		return err
+	}
+	token, err := environment.GetToken()
+	if err != nil {
+		return err
+	}
+	url := config.GetApi().GetUrl(environment)
+
+	_, existingId, err := GetObjectIdIfAlreadyExists(config.GetType(), url, name, token, NameMatching{})
+	if err != nil {
+		return err
+	}
+
+	if len(existingId) > 0 {
+		resp := deleteConfig(&http.Client{}, url, token, existingId)
+		if !success(resp) {
+			return responseError(resp, "failed to delete DT object %s", name)
+		}
+	}
+	return nil
+}
+
+// upsertDynatraceObject creates the given object, or updates it if an existingObjectId is given.
+// Updates conflicting with a concurrent modification are retried up to conflictRetries times
+func upsertDynatraceObject(client *http.Client, apiPath string, objectName string, configType string, configJson string, apiToken string, existingObjectId string, conflictRetries int) (api.DynatraceEntity, error) {
+	var dtEntity api.DynatraceEntity
 	var resp Response
 	path := apiPath
 	body := configJson
@@ -46,17 +104,36 @@ func UpsertDynatraceObject(apiPath string, objectName string, configType string,
 	}
 
 	if existingObjectId != "" {
-		path = apiPath + "/" + existingObjectId
+		path = objectUrl(apiPath, existingObjectId)
 		// Updating a dashboard requires the ID to be contained in the JSON, so we just add it...
-		if isDashBoard {
+		if configType == "dashboard" {
 			body = strings.Replace(configJson, "{", "{\n\"id\":\""+existingObjectId+"\",\n", 1)
 		}
-		resp = Put(path, body, apiToken)
+		resp = put(client, path, body, apiToken)
+
+		for attempt := 1; resp.StatusCode == http.StatusConflict && attempt <= conflictRetries; attempt++ {
+			wait := time.Duration(attempt) * conflictRetryDelay
+			util.Log.Warn("\t\tUpdate of '%s - %s' conflicts with a concurrent modification. Re-syncing and retrying in %s (%d/%d)...", configType, objectName, wait, attempt, conflictRetries)
+			conflictRetrySleep(wait)
+
+			current := get(client, path, apiToken)
+			if !success(current) {
+				break
+			}
+
+			synced, err := withCurrentVersion(body, current.Body)
+			if err != nil {
+				return dtEntity, fmt.Errorf("failed to re-sync DT object %s after conflict: %s", objectName, err)
+			}
+			body = synced
+
+			resp = put(client, path, body, apiToken)
+		}
 	} else {
 		if configType == "app-detection-rule" {
 			path += "?position=PREPEND"
 		}
-		resp = Post(path, body, apiToken)
+		resp = post(client, path, body, apiToken)
 
 		// It can happen that the post fails because config needs time to be propagated on all cluster nodes. If the error
 		// constraintViolations":[{"path":"name","message":"X must have a unique name...
@@ -65,17 +142,20 @@ func UpsertDynatraceObject(apiPath string, objectName string, configType string,
 			// Try again after 5 seconds:
 			util.Log.Warn("\t\tConfig '%s - %s' needs to have a unique name. Waiting for 5 seconds before retry...", configType, objectName)
 			time.Sleep(5 * time.Second)
-			resp = Post(path, body, apiToken)
+			resp = post(client, path, body, apiToken)
 		}
 		// It can take longer until request attributes are ready to be used
 		if !success(resp) && strings.Contains(string(resp.Body), "must specify a known request attribute") {
 			util.Log.Warn("\t\tSpecified request attribute not known for %s. Waiting for 10 seconds before retry...", objectName)
 			time.Sleep(10 * time.Second)
-			resp = Post(path, body, apiToken)
+			resp = post(client, path, body, apiToken)
 		}
 	}
 	if !success(resp) {
-		return dtEntity, fmt.Errorf("Failed to upsert DT object %s (HTTP %d)!\n    Response was: %s", objectName, resp.StatusCode, string(resp.Body))
+		return dtEntity, responseError(resp, "Failed to upsert DT object %s", objectName)
+	}
+	if err := checkIntercepted(path, resp); err != nil {
+		return dtEntity, err
 	}
 	if updateSuccess(resp) {
 		util.Log.Debug("\t\t\tUpdated existing object for %s (%s)", objectName, existingObjectId)
@@ -104,31 +184,32 @@ func UpsertDynatraceObject(apiPath string, objectName string, configType string,
 	return dtEntity, nil
 }
 
-func DeleteDynatraceObject(config config.Config, environment environment.Environment) error {
+// withCurrentVersion copies the version fields of the current object into the payload of an update
+func withCurrentVersion(body string, current []byte) (string, error) {
 
-	emptyDict := make(map[string]api.DynatraceEntity)
-	name, err := config.GetObjectNameForEnvironment(environment, emptyDict)
+	var payload map[string]interface{}
+	err := json.Unmarshal([]byte(body), &payload)
 	if err != nil {
-		return err
+		return body, err
 	}
-	token, err := environment.GetToken()
-	if err != nil {
-		return err
-	}
-	url := config.GetApi().GetUrl(environment)
 
-	_, existingId, err := GetObjectIdIfAlreadyExists(config.GetType(), url, name, token)
+	var currentObject map[string]interface{}
+	err = json.Unmarshal(current, &currentObject)
 	if err != nil {
-		return err
+		return body, err
 	}
 
-	if len(existingId) > 0 {
-		Delete(url, token, existingId)
+	for _, field := range versionFields {
+		if value, found := currentObject[field]; found {
+			payload[field] = value
+		}
 	}
-	return nil
+
+	synced, err := json.Marshal(payload)
+	return string(synced), err
 }
 
-func GetObjectIdIfAlreadyExists(configType string, url string, objectName string, apiToken string) (isDashboard bool, existingId string, err error) {
+func GetObjectIdIfAlreadyExists(configType string, url string, objectName string, apiToken string, matching NameMatching) (isDashboard bool, existingId string, err error) {
 	isDashboard, values, err := GetExistingValuesFromEndpoint(configType, url, apiToken)
 	if err != nil {
 		return isDashboard, "", err
@@ -136,7 +217,7 @@ func GetObjectIdIfAlreadyExists(configType string, url string, objectName string
 
 	for i := 0; i < len(values); i++ {
 		value := values[i]
-		if value.Name == objectName {
+		if matching.Matches(value.Name, objectName) {
 			return isDashboard, value.Id, nil
 		}
 	}
@@ -147,6 +228,13 @@ func GetExistingValuesFromEndpoint(configType string, url string, apiToken strin
 
 	resp := Get(url, apiToken)
 
+	return unmarshalExistingValues(configType, resp)
+}
+
+// unmarshalExistingValues converts the response of a list request into values. The structure of the
+// response depends on the config type
+func unmarshalExistingValues(configType string, resp Response) (isDashboard bool, values []api.Value, err error) {
+
 	switch configType {
 	case "dashboard":
 		var jsonResp api.DashboardResponse
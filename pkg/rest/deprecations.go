@@ -0,0 +1,167 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// Deprecation is an endpoint the server flagged as deprecated by the Deprecation or Sunset header of a response
+type Deprecation struct {
+
+	// Endpoint is the id of the api, or the path of the request for endpoints of no config api
+	Endpoint string
+
+	// Deprecated is the value of the Deprecation header, e.g. "true" or the date the endpoint was deprecated at
+	Deprecated string
+
+	// Sunset is the value of the Sunset header, the date the endpoint is going to be removed at
+	Sunset string
+
+	// Link is the link to the documentation of the deprecation or sunset, if the server sent one
+	Link string
+}
+
+func (d Deprecation) String() string {
+
+	description := d.Endpoint + " is deprecated"
+	if d.Deprecated != "" && !strings.EqualFold(d.Deprecated, "true") {
+		description += " since " + d.Deprecated
+	}
+	if d.Sunset != "" {
+		description += fmt.Sprintf(" and will be removed on %s", d.Sunset)
+	}
+	if d.Link != "" {
+		description += " (see " + d.Link + ")"
+	}
+	return description
+}
+
+// Deprecations collects the deprecated endpoints a client sent requests to, each endpoint is recorded once.
+// It is safe for concurrent use, nothing is collected if it is nil
+type Deprecations struct {
+	lock     sync.Mutex
+	apiPaths map[string]string
+	seen     map[string]Deprecation
+}
+
+// NewDeprecations creates an empty collection of deprecations
+func NewDeprecations() *Deprecations {
+
+	deprecations := &Deprecations{apiPaths: make(map[string]string), seen: make(map[string]Deprecation)}
+	for id, a := range api.NewApis() {
+		deprecations.apiPaths[id] = a.GetUrlFromEnvironmentUrl("")
+	}
+
+	return deprecations
+}
+
+// List returns the collected deprecations sorted by endpoint
+func (d *Deprecations) List() []Deprecation {
+
+	if d == nil {
+		return nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	list := make([]Deprecation, 0, len(d.seen))
+	for _, deprecation := range d.seen {
+		list = append(list, deprecation)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Endpoint < list[j].Endpoint })
+
+	return list
+}
+
+// record records the endpoint of the request, if the response flags it as deprecated
+func (d *Deprecations) record(request *http.Request, resp *http.Response) {
+
+	deprecated := resp.Header.Get("Deprecation")
+	sunset := resp.Header.Get("Sunset")
+	if deprecated == "" && sunset == "" {
+		return
+	}
+
+	endpoint := d.endpointOf(request.URL.Path)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if _, found := d.seen[endpoint]; !found {
+		d.seen[endpoint] = Deprecation{Endpoint: endpoint, Deprecated: deprecated, Sunset: sunset, Link: deprecationLink(resp.Header)}
+	}
+}
+
+// endpointOf returns the id of the api the path belongs to, objects of an api are recorded as the api
+func (d *Deprecations) endpointOf(path string) string {
+
+	endpoint, matched := path, ""
+	for apiId, apiPath := range d.apiPaths {
+		if strings.Contains(path, apiPath) && len(apiPath) > len(matched) {
+			endpoint, matched = apiId, apiPath
+		}
+	}
+
+	return endpoint
+}
+
+// deprecationLink returns the target of the Link header with relation deprecation or sunset, e.g.
+// Link: <https://www.dynatrace.com/support/help/...>; rel="deprecation"
+func deprecationLink(header http.Header) string {
+
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			parts := strings.Split(link, ";")
+			for _, parameter := range parts[1:] {
+				parameter = strings.TrimSpace(parameter)
+				if !strings.HasPrefix(parameter, "rel=") {
+					continue
+				}
+				relation := strings.Trim(strings.TrimPrefix(parameter, "rel="), `"`)
+				if relation == "deprecation" || relation == "sunset" {
+					return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+				}
+			}
+		}
+	}
+
+	return ""
+}
+
+// deprecationTransport records the deprecations of the responses of its base transport
+type deprecationTransport struct {
+	base         http.RoundTripper
+	deprecations *Deprecations
+}
+
+func (t *deprecationTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	resp, err := t.base.RoundTrip(request)
+	if err == nil {
+		t.deprecations.record(request, resp)
+	}
+
+	return resp, err
+}
@@ -0,0 +1,81 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDeprecationsAreRecordedOncePerEndpoint(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Sunset", "Sat, 01 Jan 2022 00:00:00 GMT")
+		w.Header().Add("Link", `<https://example.com/migration>; rel="sunset"`)
+		_, _ = w.Write([]byte(`{"values": [{"id": "42", "name": "zone"}]}`))
+	}))
+	defer server.Close()
+
+	deprecations := NewDeprecations()
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{Deprecations: deprecations})
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+	_, err = client.ReadById(testManagementZoneApi, "42")
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, deprecations.List(), []Deprecation{{
+		Endpoint:   "management-zone",
+		Deprecated: "true",
+		Sunset:     "Sat, 01 Jan 2022 00:00:00 GMT",
+		Link:       "https://example.com/migration",
+	}})
+	assert.Equal(t, deprecations.List()[0].String(), "management-zone is deprecated and will be removed on Sat, 01 Jan 2022 00:00:00 GMT (see https://example.com/migration)")
+}
+
+func TestResponsesWithoutDeprecationHeadersAreNotRecorded(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	deprecations := NewDeprecations()
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{Deprecations: deprecations})
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(deprecations.List()), 0)
+	assert.Equal(t, len((*Deprecations)(nil).List()), 0)
+}
+
+func TestDeprecationLink(t *testing.T) {
+
+	header := http.Header{}
+	header.Add("Link", `<https://example.com/next>; rel="next", <https://example.com/deprecation>; rel="deprecation"`)
+
+	assert.Equal(t, deprecationLink(header), "https://example.com/deprecation")
+	assert.Equal(t, deprecationLink(http.Header{}), "")
+}
@@ -0,0 +1,243 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// OperationType is the kind of write a DryRunClient recorded
+type OperationType string
+
+const (
+	OperationCreate OperationType = "create"
+	OperationUpdate OperationType = "update"
+	OperationDelete OperationType = "delete"
+)
+
+// Operation is a write a DryRunClient recorded instead of sending it to the environment. Objects the client created
+// have ids starting with dry-run-, the payload of deletions is empty
+type Operation struct {
+	Type    OperationType
+	Api     string
+	Id      string
+	Name    string
+	Payload []byte
+}
+
+// DryRunClient is a DynatraceClient which records the writes of a deployment instead of sending them. Reads are
+// answered from the given source, e.g. a read-only client of a snapshot or the client of the actual environment,
+// overlaid by the recorded writes, so that later reads of a deployment see the objects it created, updated or deleted.
+// This allows building previews on top of the deploy logic of monaco without modifying the environment
+type DryRunClient struct {
+	source   DynatraceClient
+	matching NameMatching
+
+	lock       sync.Mutex
+	nextId     int
+	operations []Operation
+
+	// written holds the created and updated objects, payload their payloads and deleted the ids of the deleted
+	// objects, per api
+	written map[string]map[string]api.Value
+	payload map[string]map[string][]byte
+	deleted map[string]map[string]bool
+}
+
+// NewDryRunClient creates a client recording all writes, which reads from source
+func NewDryRunClient(source DynatraceClient, matching NameMatching) *DryRunClient {
+	return &DryRunClient{
+		source:   source,
+		matching: matching,
+		written:  make(map[string]map[string]api.Value),
+		payload:  make(map[string]map[string][]byte),
+		deleted:  make(map[string]map[string]bool),
+	}
+}
+
+// Operations returns the recorded writes in the order they were made
+func (c *DryRunClient) Operations() []Operation {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return append([]Operation{}, c.operations...)
+}
+
+func (c *DryRunClient) List(a api.Api) (values []api.Value, err error) {
+
+	source, err := c.source.List(a)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.overlay(a, source), nil
+}
+
+// overlay applies the recorded writes of the api to the values listed by the source
+func (c *DryRunClient) overlay(a api.Api, source []api.Value) []api.Value {
+
+	written := c.written[a.GetId()]
+	deleted := c.deleted[a.GetId()]
+
+	values := make([]api.Value, 0, len(source)+len(written))
+	listed := make(map[string]bool, len(source))
+
+	for _, value := range source {
+		listed[value.Id] = true
+		if deleted[value.Id] {
+			continue
+		}
+		if updated, found := written[value.Id]; found {
+			value = updated
+		}
+		values = append(values, value)
+	}
+
+	for _, operation := range c.operations {
+		// objects updated by id are created if they do not exist, like a PUT does
+		if operation.Api == a.GetId() && operation.Type != OperationDelete && !listed[operation.Id] && !deleted[operation.Id] {
+			listed[operation.Id] = true
+			values = append(values, written[operation.Id])
+		}
+	}
+
+	return values
+}
+
+func (c *DryRunClient) ReadById(a api.Api, id string) (json []byte, err error) {
+
+	c.lock.Lock()
+	deleted := c.deleted[a.GetId()][id]
+	payload, written := c.payload[a.GetId()][id]
+	c.lock.Unlock()
+
+	if deleted {
+		return nil, fmt.Errorf("config %s of %s was deleted by the dry run", id, a.GetId())
+	}
+	if written {
+		return payload, nil
+	}
+
+	return c.source.ReadById(a, id)
+}
+
+func (c *DryRunClient) UpsertByName(a api.Api, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+
+	exists, id, err := c.ExistsByName(a, name)
+	if err != nil {
+		return entity, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	operation := OperationUpdate
+	if !exists {
+		c.nextId++
+		id = fmt.Sprintf("dry-run-%d", c.nextId)
+		operation = OperationCreate
+	}
+
+	c.record(a, operation, id, name, payload)
+
+	return api.DynatraceEntity{Id: id, Name: name}, nil
+}
+
+func (c *DryRunClient) UpsertById(a api.Api, id string, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.record(a, OperationUpdate, id, name, payload)
+
+	return api.DynatraceEntity{Id: id, Name: name}, nil
+}
+
+func (c *DryRunClient) DeleteByName(a api.Api, name string) error {
+
+	exists, id, err := c.ExistsByName(a, name)
+	if err != nil || !exists {
+		return err
+	}
+
+	return c.DeleteById(a, id)
+}
+
+func (c *DryRunClient) DeleteById(a api.Api, id string) error {
+
+	// the name is recorded for previews, it is looked up like the real client would find the object
+	values, err := c.List(a)
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	for _, value := range values {
+		if value.Id == id {
+			name = value.Name
+		}
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.record(a, OperationDelete, id, name, nil)
+
+	return nil
+}
+
+func (c *DryRunClient) ExistsByName(a api.Api, name string) (exists bool, id string, err error) {
+
+	values, err := c.List(a)
+	if err != nil {
+		return false, "", err
+	}
+
+	return FindByName(a, values, name, c.matching)
+}
+
+// record adds the operation and applies it to the overlay of the api, c.lock has to be held
+func (c *DryRunClient) record(a api.Api, operation OperationType, id string, name string, payload []byte) {
+
+	apiId := a.GetId()
+
+	c.operations = append(c.operations, Operation{Type: operation, Api: apiId, Id: id, Name: name, Payload: payload})
+
+	if c.written[apiId] == nil {
+		c.written[apiId] = make(map[string]api.Value)
+		c.payload[apiId] = make(map[string][]byte)
+		c.deleted[apiId] = make(map[string]bool)
+	}
+
+	if operation == OperationDelete {
+		c.deleted[apiId][id] = true
+		delete(c.payload[apiId], id)
+		return
+	}
+
+	// an update by id may revive an object deleted before, like a PUT does
+	delete(c.deleted[apiId], id)
+	c.written[apiId][id] = api.Value{Id: id, Name: name}
+	c.payload[apiId][id] = payload
+}
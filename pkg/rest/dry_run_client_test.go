@@ -0,0 +1,113 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+// snapshotClient answers reads from fixed values and fails on writes
+type snapshotClient struct {
+	DynatraceClient
+	values   []api.Value
+	payloads map[string][]byte
+}
+
+func (c *snapshotClient) List(a api.Api) ([]api.Value, error) {
+	return c.values, nil
+}
+
+func (c *snapshotClient) ReadById(a api.Api, id string) ([]byte, error) {
+	if payload, found := c.payloads[id]; found {
+		return payload, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func newTestDryRunClient() *DryRunClient {
+	return NewDryRunClient(&snapshotClient{
+		values:   []api.Value{{Id: "existing-id", Name: "existing"}},
+		payloads: map[string][]byte{"existing-id": []byte(`{"name":"existing"}`)},
+	}, NameMatching{})
+}
+
+func TestDryRunClientRecordsUpserts(t *testing.T) {
+
+	testApi := api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+	client := newTestDryRunClient()
+
+	entity, err := client.UpsertByName(testApi, "existing", []byte(`{"name":"existing","changed":true}`))
+	assert.NilError(t, err)
+	assert.Equal(t, entity.Id, "existing-id")
+
+	entity, err = client.UpsertByName(testApi, "new", []byte(`{"name":"new"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, entity.Id, "dry-run-1")
+
+	values, err := client.List(testApi)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []api.Value{{Id: "existing-id", Name: "existing"}, {Id: "dry-run-1", Name: "new"}})
+
+	payload, err := client.ReadById(testApi, "existing-id")
+	assert.NilError(t, err)
+	assert.Equal(t, string(payload), `{"name":"existing","changed":true}`)
+
+	operations := client.Operations()
+	assert.Equal(t, len(operations), 2)
+	assert.Equal(t, operations[0].Type, OperationUpdate)
+	assert.Equal(t, operations[1].Type, OperationCreate)
+	assert.Equal(t, operations[1].Api, "alerting-profile")
+}
+
+func TestDryRunClientRecordsDeletes(t *testing.T) {
+
+	testApi := api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+	client := newTestDryRunClient()
+
+	assert.NilError(t, client.DeleteByName(testApi, "existing"))
+	assert.NilError(t, client.DeleteByName(testApi, "missing"))
+
+	exists, _, err := client.ExistsByName(testApi, "existing")
+	assert.NilError(t, err)
+	assert.Equal(t, exists, false)
+
+	_, err = client.ReadById(testApi, "existing-id")
+	assert.ErrorContains(t, err, "deleted by the dry run")
+
+	operations := client.Operations()
+	assert.DeepEqual(t, operations, []Operation{{Type: OperationDelete, Api: "alerting-profile", Id: "existing-id", Name: "existing"}})
+}
+
+func TestDryRunClientUpsertByIdCreatesMissingObjects(t *testing.T) {
+
+	testApi := api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles")
+	client := newTestDryRunClient()
+
+	_, err := client.UpsertById(testApi, "fixed-id", "fixed", []byte(`{}`))
+	assert.NilError(t, err)
+
+	exists, id, err := client.ExistsByName(testApi, "fixed")
+	assert.NilError(t, err)
+	assert.Equal(t, exists, true)
+	assert.Equal(t, id, "fixed-id")
+}
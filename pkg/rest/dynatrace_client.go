@@ -0,0 +1,356 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// DynatraceClient provides access to the configuration APIs of a single Dynatrace environment
+type DynatraceClient interface {
+
+	// List lists the available configs for the given api
+	List(a api.Api) (values []api.Value, err error)
+
+	// ReadById reads the config with the given id from the given api and returns the raw json payload
+	ReadById(a api.Api, id string) (json []byte, err error)
+
+	// UpsertByName creates the given config or updates the existing config with the same name.
+	// Returns a DuplicateNameError if multiple configs with this name exist
+	UpsertByName(a api.Api, name string, payload []byte) (entity api.DynatraceEntity, err error)
+
+	// UpsertById updates the config with the given id
+	UpsertById(a api.Api, id string, name string, payload []byte) (entity api.DynatraceEntity, err error)
+
+	// DeleteByName deletes the config with the given name, if it exists.
+	// Returns a DuplicateNameError if multiple configs with this name exist
+	DeleteByName(a api.Api, name string) error
+
+	// DeleteById deletes the config with the given id
+	DeleteById(a api.Api, id string) error
+
+	// ExistsByName checks if a config with the given name exists and returns its id.
+	// Returns a DuplicateNameError if multiple configs with this name exist
+	ExistsByName(a api.Api, name string) (exists bool, id string, err error)
+}
+
+// ErrReadOnly is returned on writes to clients serving configs from sources which can not be written to
+var ErrReadOnly = errors.New("client is read-only")
+
+// DuplicateNameError is returned if a config is addressed by name, but multiple configs with this name exist
+type DuplicateNameError struct {
+	Api  string
+	Name string
+	Ids  []string
+}
+
+func (e DuplicateNameError) Error() string {
+	return fmt.Sprintf("%d configs of %s are named '%s' (ids %s)", len(e.Ids), e.Api, e.Name, strings.Join(e.Ids, ", "))
+}
+
+// dynatraceClientImpl caches the results of List per api for the lifetime of the client, as a deployment
+// would otherwise list the same api for every single config. The cache of an api is invalidated whenever
+// the client writes to it.
+type dynatraceClientImpl struct {
+	environmentUrl string
+	token          string
+	client         *http.Client
+	options        ClientOptions
+
+	extensionUploader extensionUploader
+
+	cacheLock *sync.Mutex
+	listCache map[string][]api.Value
+
+	diagnosisOnce sync.Once
+	diagnosis     string
+}
+
+// NewDynatraceClient creates a new DynatraceClient for the environment reachable under environmentUrl
+func NewDynatraceClient(environmentUrl string, token string) (DynatraceClient, error) {
+	return NewDynatraceClientWithOptions(environmentUrl, token, DefaultClientOptions())
+}
+
+// NewDynatraceClientWithOptions creates a new DynatraceClient using the given options
+func NewDynatraceClientWithOptions(environmentUrl string, token string, options ClientOptions) (DynatraceClient, error) {
+
+	if environmentUrl == "" {
+		return nil, fmt.Errorf("no environment url provided")
+	}
+
+	if token == "" {
+		return nil, fmt.Errorf("no token provided for environment %s", environmentUrl)
+	}
+
+	// every request sent is recorded, including the ones retried after throttling
+	var base http.RoundTripper = http.DefaultTransport
+	if options.Replay != nil {
+		base = &replayTransport{base: base, replay: options.Replay}
+	}
+
+	var transport http.RoundTripper = &throttlingTransport{base: base, limiter: newAdaptiveLimiter(options.MaxConcurrentRequests), policy: options.StatusPolicy}
+	if options.StatusPolicy != nil {
+		transport = &policyTransport{base: transport, policy: options.StatusPolicy}
+	}
+	if options.HttpCache != nil {
+		transport = &cachingTransport{base: transport, cache: options.HttpCache}
+	}
+	if options.Deprecations != nil {
+		transport = &deprecationTransport{base: transport, deprecations: options.Deprecations}
+	}
+
+	uploadTimeout := options.ExtensionUploadTimeout
+	if uploadTimeout <= 0 {
+		uploadTimeout = defaultExtensionUploadTimeout
+	}
+
+	return &dynatraceClientImpl{
+		extensionUploader: extensionUploader{
+			client:            &http.Client{Transport: transport, Timeout: uploadTimeout},
+			retries:           options.ExtensionUploadRetries,
+			waitForActivation: options.WaitForExtensions,
+		},
+		environmentUrl: strings.TrimSuffix(environmentUrl, "/"),
+		token:          token,
+		client:         &http.Client{Transport: transport, Timeout: options.RequestTimeout},
+		options:        options,
+		cacheLock:      &sync.Mutex{},
+		listCache:      make(map[string][]api.Value),
+	}, nil
+}
+
+// WithOverrides returns a client whose requests use the given timeout and retries instead of the ones of the
+// options of client, e.g. for a single slow config. A zero timeout or nil retries keep the ones of the options.
+// The returned client shares the listed configs with client. Clients not created by NewDynatraceClient are
+// returned as they are
+func WithOverrides(client DynatraceClient, timeout time.Duration, retries *int) DynatraceClient {
+
+	d, ok := client.(*dynatraceClientImpl)
+	if !ok || (timeout == 0 && retries == nil) {
+		return client
+	}
+
+	overridden := &dynatraceClientImpl{
+		environmentUrl:    d.environmentUrl,
+		token:             d.token,
+		client:            d.client,
+		options:           d.options,
+		extensionUploader: d.extensionUploader,
+		cacheLock:         d.cacheLock,
+		listCache:         d.listCache,
+	}
+
+	if timeout > 0 {
+		overridden.client = &http.Client{Transport: d.client.Transport, Timeout: timeout}
+		overridden.extensionUploader.client = &http.Client{Transport: d.extensionUploader.client.Transport, Timeout: timeout}
+	}
+	if retries != nil {
+		overridden.options.ConflictRetries = *retries
+		overridden.extensionUploader.retries = *retries
+	}
+
+	return overridden
+}
+
+func (d *dynatraceClientImpl) List(a api.Api) (values []api.Value, err error) {
+
+	d.cacheLock.Lock()
+	cached, found := d.listCache[a.GetId()]
+	d.cacheLock.Unlock()
+
+	if found {
+		return cached, nil
+	}
+
+	cached, found = d.options.NameCache.lookup(d.environmentUrl, a.GetId())
+	if found {
+		util.Log.Debug("\t\tUsing cached list of %s", a.GetId())
+
+		d.cacheLock.Lock()
+		d.listCache[a.GetId()] = cached
+		d.cacheLock.Unlock()
+
+		return cached, nil
+	}
+
+	resp, err := d.get(a.GetUrlFromEnvironmentUrl(d.environmentUrl))
+	if err != nil {
+		return values, err
+	}
+
+	_, values, err = unmarshalExistingValues(a.GetId(), resp)
+	if err != nil {
+		return values, err
+	}
+
+	d.cacheLock.Lock()
+	d.listCache[a.GetId()] = values
+	d.cacheLock.Unlock()
+
+	d.options.NameCache.store(d.environmentUrl, a.GetId(), values)
+
+	return values, nil
+}
+
+func (d *dynatraceClientImpl) ReadById(a api.Api, id string) (json []byte, err error) {
+
+	resp, err := d.get(objectUrl(a.GetUrlFromEnvironmentUrl(d.environmentUrl), id))
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Body, nil
+}
+
+func (d *dynatraceClientImpl) UpsertByName(a api.Api, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+
+	defer d.invalidate(a)
+
+	url := a.GetUrlFromEnvironmentUrl(d.environmentUrl)
+
+	if a.GetId() == "extension" {
+		entity, err = uploadExtension(d.client, d.extensionUploader, url, name, payload, d.token)
+		return entity, d.diagnose(err)
+	}
+
+	_, existingId, err := d.ExistsByName(a, name)
+	if err != nil {
+		return entity, err
+	}
+
+	entity, err = upsertDynatraceObject(d.client, url, name, a.GetId(), string(payload), d.token, existingId, d.conflictRetries(a))
+	return entity, d.diagnose(err)
+}
+
+func (d *dynatraceClientImpl) UpsertById(a api.Api, id string, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+
+	defer d.invalidate(a)
+
+	url := a.GetUrlFromEnvironmentUrl(d.environmentUrl)
+
+	// extensions are identified by the name in their plugin.json, so uploading them replaces the extension with that id
+	if a.GetId() == "extension" {
+		entity, err = uploadExtension(d.client, d.extensionUploader, url, name, payload, d.token)
+		return entity, d.diagnose(err)
+	}
+
+	entity, err = upsertDynatraceObject(d.client, url, name, a.GetId(), string(payload), d.token, id, d.conflictRetries(a))
+	return entity, d.diagnose(err)
+}
+
+func (d *dynatraceClientImpl) DeleteByName(a api.Api, name string) error {
+
+	exists, id, err := d.ExistsByName(a, name)
+	if err != nil || !exists {
+		return err
+	}
+
+	return d.DeleteById(a, id)
+}
+
+func (d *dynatraceClientImpl) DeleteById(a api.Api, id string) error {
+
+	defer d.invalidate(a)
+
+	url := a.GetUrlFromEnvironmentUrl(d.environmentUrl)
+	resp := deleteConfig(d.client, url, d.token, id)
+	if !success(resp) {
+		return d.diagnose(responseError(resp, "failed to delete %s of %s", id, a.GetId()))
+	}
+
+	return nil
+}
+
+func (d *dynatraceClientImpl) ExistsByName(a api.Api, name string) (exists bool, id string, err error) {
+
+	values, err := d.List(a)
+	if err != nil {
+		return false, "", err
+	}
+
+	return FindByName(a, values, name, d.options.NameMatching)
+}
+
+// FindByName returns the id of the value matching the given name, or a DuplicateNameError if the name is not unique
+func FindByName(a api.Api, values []api.Value, name string, matching NameMatching) (exists bool, id string, err error) {
+
+	var ids []string
+	for _, value := range values {
+		if matching.Matches(value.Name, name) {
+			ids = append(ids, value.Id)
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return false, "", nil
+	case 1:
+		return true, ids[0], nil
+	default:
+		return true, "", DuplicateNameError{Api: a.GetId(), Name: name, Ids: ids}
+	}
+}
+
+// conflictRetries returns the number of times updates of the api are retried after a conflict, which is none
+// if the status policy classifies conflicts of the api as fatal
+func (d *dynatraceClientImpl) conflictRetries(a api.Api) int {
+
+	if d.options.StatusPolicy.isFatal(a.GetId(), http.MethodPut, http.StatusConflict) {
+		return 0
+	}
+	return d.options.ConflictRetries
+}
+
+// invalidate drops the cached list of the given api, so that the next List call reflects the writes of this client
+func (d *dynatraceClientImpl) invalidate(a api.Api) {
+
+	d.cacheLock.Lock()
+	defer d.cacheLock.Unlock()
+
+	delete(d.listCache, a.GetId())
+	d.options.NameCache.invalidate(d.environmentUrl, a.GetId())
+}
+
+func (d *dynatraceClientImpl) get(url string) (Response, error) {
+
+	resp, err := d.client.Do(request(http.MethodGet, url, d.token))
+	if err != nil {
+		return Response{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	response := Response{StatusCode: resp.StatusCode, Body: body, RequestId: resp.Header.Get(requestIdHeader), ContentType: resp.Header.Get("Content-Type")}
+	if !success(response) {
+		return response, d.diagnose(responseError(response, "GET request %s failed", url))
+	}
+
+	return response, checkIntercepted(url, response)
+}
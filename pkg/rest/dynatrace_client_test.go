@@ -0,0 +1,318 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+var testManagementZoneApi = api.NewApi("management-zone", "/api/config/v1/managementZones")
+
+func newTestServer(listRequests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			*listRequests++
+			_, _ = w.Write([]byte(`{"values": [{"id": "42", "name": "zone"}]}`))
+		case http.MethodPut:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestListIsCachedPerApi(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	for i := 0; i < 3; i++ {
+		values, err := client.List(testManagementZoneApi)
+		assert.NilError(t, err)
+		assert.Equal(t, 1, len(values))
+	}
+
+	exists, id, err := client.ExistsByName(testManagementZoneApi, "zone")
+	assert.NilError(t, err)
+	assert.Equal(t, true, exists)
+	assert.Equal(t, "42", id)
+
+	assert.Equal(t, 1, listRequests)
+}
+
+func TestUpsertInvalidatesListCache(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	entity, err := client.UpsertByName(testManagementZoneApi, "zone", []byte(`{"name": "zone"}`))
+	assert.NilError(t, err)
+	assert.Equal(t, "42", entity.Id)
+	assert.Equal(t, 1, listRequests)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+	assert.Equal(t, 2, listRequests)
+}
+
+func TestDeprecatedUpsertDynatraceObjectUpdatesObjectWithTheSameName(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	entity, err := UpsertDynatraceObject(server.URL+"/api/config/v1/managementZones", "zone", "management-zone", `{"name": "zone"}`, "token")
+	assert.NilError(t, err)
+	assert.Equal(t, "42", entity.Id)
+	assert.Equal(t, 1, listRequests)
+}
+
+func TestFindByNameReportsDuplicates(t *testing.T) {
+
+	values := []api.Value{{Id: "1", Name: "zone"}, {Id: "2", Name: "other"}, {Id: "3", Name: "zone"}}
+
+	exists, id, err := FindByName(testManagementZoneApi, values, "other", NameMatching{})
+	assert.NilError(t, err)
+	assert.Equal(t, true, exists)
+	assert.Equal(t, "2", id)
+
+	exists, _, err = FindByName(testManagementZoneApi, values, "missing", NameMatching{})
+	assert.NilError(t, err)
+	assert.Equal(t, false, exists)
+
+	_, _, err = FindByName(testManagementZoneApi, values, "zone", NameMatching{})
+	assert.DeepEqual(t, err, DuplicateNameError{Api: "management-zone", Name: "zone", Ids: []string{"1", "3"}})
+}
+
+func TestUpdateIsRetriedWithCurrentVersionAfterConflict(t *testing.T) {
+
+	var puts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": "42", "name": "zone", "metadata": {"configurationVersions": [2]}}`))
+		case http.MethodPut:
+			body, _ := ioutil.ReadAll(r.Body)
+			puts = append(puts, string(body))
+			if len(puts) == 1 {
+				w.WriteHeader(http.StatusConflict)
+			} else {
+				w.WriteHeader(http.StatusNoContent)
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone", "metadata": {"configurationVersions": [1]}}`))
+	assert.NilError(t, err)
+
+	assert.Equal(t, 2, len(puts))
+	assert.Equal(t, puts[1], `{"metadata":{"configurationVersions":[2]},"name":"zone"}`)
+}
+
+func TestUpdateFailsIfConflictPersists(t *testing.T) {
+
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": "42", "name": "zone"}`))
+		case http.MethodPut:
+			puts++
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{ConflictRetries: 2})
+	assert.NilError(t, err)
+
+	_, err = client.UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone"}`))
+	assert.ErrorContains(t, err, "HTTP 409")
+	assert.Equal(t, 3, puts)
+}
+
+func TestUpdateWaitsLongerBeforeEveryResyncAfterConflict(t *testing.T) {
+
+	var waits []time.Duration
+	conflictRetrySleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { conflictRetrySleep = time.Sleep }()
+
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": "42", "name": "zone"}`))
+		case http.MethodPut:
+			puts++
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{ConflictRetries: 3})
+	assert.NilError(t, err)
+
+	_, err = client.UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone"}`))
+	assert.ErrorContains(t, err, "HTTP 409")
+	assert.Equal(t, 4, puts)
+	assert.DeepEqual(t, waits, []time.Duration{conflictRetryDelay, 2 * conflictRetryDelay, 3 * conflictRetryDelay})
+}
+
+func TestOverriddenRetriesApplyToTheReturnedClientOnly(t *testing.T) {
+
+	var puts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"id": "42", "name": "zone"}`))
+		case http.MethodPut:
+			puts++
+			w.WriteHeader(http.StatusConflict)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{ConflictRetries: 2})
+	assert.NilError(t, err)
+
+	retries := 0
+	_, err = WithOverrides(client, time.Minute, &retries).UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone"}`))
+	assert.ErrorContains(t, err, "HTTP 409")
+	assert.Equal(t, 1, puts)
+
+	puts = 0
+	_, err = client.UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone"}`))
+	assert.ErrorContains(t, err, "HTTP 409")
+	assert.Equal(t, 3, puts)
+}
+
+func TestOverriddenTimeoutFailsSlowRequests(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"values": []}`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{})
+	assert.NilError(t, err)
+
+	_, err = WithOverrides(client, 10*time.Millisecond, nil).List(testManagementZoneApi)
+	assert.Assert(t, err != nil, "the request must time out")
+
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+}
+
+func TestWithoutOverridesTheClientIsKept(t *testing.T) {
+
+	client, err := NewDynatraceClient("https://environment.live.dynatrace.com", "token")
+	assert.NilError(t, err)
+
+	assert.Equal(t, WithOverrides(client, 0, nil), client)
+}
+
+func TestFailedRequestErrorContainsRequestId(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("x-request-id", "abc123")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": 403, "message": "Token is missing required scope."}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.ErrorContains(t, err, "(HTTP 403, request id abc123)!\n    Error: Token is missing required scope.")
+}
+
+func TestIdsAreEscapedInRequestPaths(t *testing.T) {
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.EscapedPath())
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"values": [{"id": "log:my metric/ü", "name": "log:my metric/ü"}]}`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	logMetricApi := api.NewApi("calculated-metrics-log", "/api/config/v1/calculatedMetrics/log")
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.ReadById(logMetricApi, "log:my metric/ü")
+	assert.NilError(t, err)
+
+	_, err = client.UpsertByName(logMetricApi, "log:my metric/ü", []byte(`{}`))
+	assert.NilError(t, err)
+
+	err = client.DeleteByName(logMetricApi, "log:my metric/ü")
+	assert.NilError(t, err)
+
+	escaped := "/api/config/v1/calculatedMetrics/log/log:my%20metric%2F%C3%BC"
+	assert.DeepEqual(t, paths, []string{
+		"GET " + escaped,
+		"GET /api/config/v1/calculatedMetrics/log",
+		"PUT " + escaped,
+		"GET /api/config/v1/calculatedMetrics/log",
+		"DELETE " + escaped,
+	})
+}
+
+func TestListFailsIfResponseWasIntercepted(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte("<html><body>Corporate login</body></html>"))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.ErrorContains(t, err, "intercepted by a proxy or single sign-on gateway")
+}
@@ -0,0 +1,75 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// entitiesPath is the path of the monitored entities api, relative to the environment url
+const entitiesPath = "/api/v2/entities"
+
+// EntitiesClient resolves entity selectors to the monitored entities of a Dynatrace environment.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type EntitiesClient interface {
+
+	// ListEntityIds lists the ids of all entities matching the given entity selector,
+	// e.g. type("HOST_GROUP"),entityName.startsWith("prod-")
+	ListEntityIds(entitySelector string) (ids []string, err error)
+}
+
+type entitiesListResponse struct {
+	Entities []struct {
+		EntityId string `json:"entityId"`
+	} `json:"entities"`
+	NextPageKey string `json:"nextPageKey"`
+}
+
+func (d *dynatraceClientImpl) ListEntityIds(entitySelector string) (ids []string, err error) {
+
+	query := url.Values{}
+	query.Set("entitySelector", entitySelector)
+	query.Set("fields", "entityId")
+	query.Set("pageSize", "500")
+
+	for {
+		resp, err := d.get(d.environmentUrl + entitiesPath + "?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var page entitiesListResponse
+		err = json.Unmarshal(resp.Body, &page)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal entities matching %s: %s", entitySelector, err)
+		}
+
+		for _, entity := range page.Entities {
+			ids = append(ids, entity.EntityId)
+		}
+
+		if page.NextPageKey == "" {
+			return ids, nil
+		}
+
+		// subsequent pages are requested by the page key only
+		query = url.Values{}
+		query.Set("nextPageKey", page.NextPageKey)
+	}
+}
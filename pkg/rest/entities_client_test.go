@@ -0,0 +1,49 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestListEntityIdsFollowsPages(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, entitiesPath)
+		if r.URL.Query().Get("nextPageKey") == "" {
+			assert.Equal(t, r.URL.Query().Get("entitySelector"), `type("HOST_GROUP")`)
+			_, _ = w.Write([]byte(`{"entities": [{"entityId": "HOST_GROUP-1"}], "nextPageKey": "page2"}`))
+		} else {
+			assert.Equal(t, r.URL.Query().Get("entitySelector"), "")
+			_, _ = w.Write([]byte(`{"entities": [{"entityId": "HOST_GROUP-2"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	ids, err := client.(EntitiesClient).ListEntityIds(`type("HOST_GROUP")`)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, []string{"HOST_GROUP-1", "HOST_GROUP-2"}, ids)
+}
@@ -19,50 +19,297 @@ package rest
 import (
 	"archive/zip"
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
 )
 
-func UploadExtension(apiPath string, extensionName string, extensionJson string, apiToken string) (api.DynatraceEntity, error) {
-	buffer, contentType, err := writeMultiPartForm(extensionName, extensionJson)
+// extensionManifestFile is the file describing the extension, which is located in the folder of the extension
+const extensionManifestFile = "plugin.json"
+
+// extensionStatesPath is appended to the url of an extension to query the states of its instances
+const extensionStatesPath = "/states"
+
+// extensionActivationPolls is the number of times the states of an uploaded extension are polled
+// until none of its instances is pending, if uploads wait for the activation
+const extensionActivationPolls = 15
+
+// customExtensionPrefix starts the names of all extensions which are not built into Dynatrace. Dynatrace rejects
+// uploads of extensions named otherwise, the prefix is checked before uploading to report them with their config
+const customExtensionPrefix = "custom."
+
+// defaultExtensionUploadTimeout limits the time of an extension upload if ClientOptions do not define it. Uploads
+// take much longer than other requests, as extension archives are large multipart requests
+const defaultExtensionUploadTimeout = 5 * time.Minute
+
+// extensionRetryDelay is the time waited before the first retry of a failed upload, it grows with every attempt
+const extensionRetryDelay = 5 * time.Second
+
+// extensionRetrySleep waits before retrying a failed upload, it is replaced in tests
+var extensionRetrySleep = time.Sleep
+
+// extensionUploader uploads extension archives with its own client, whose timeout differs from the timeout of the
+// requests to the other apis, and retries failed uploads
+type extensionUploader struct {
+	client  *http.Client
+	retries int
+
+	// waitForActivation polls the states of uploaded extensions until their instances are initialized
+	waitForActivation bool
+}
+
+// pendingExtensionStates are states of extension instances which have not been initialized yet
+var pendingExtensionStates = map[string]bool{
+	"UNINITIALIZED":     true,
+	"WAITING_FOR_STATE": true,
+}
+
+type extensionManifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type extensionStatesResponse struct {
+	States []struct {
+		State string `json:"state"`
+	} `json:"states"`
+}
+
+// ExtensionArchive builds the zip archive an extension is uploaded as. It contains the rendered plugin.json and further
+// files of the extension (e.g. python sources), keyed by their slash separated path within the folder of the extension
+func ExtensionArchive(pluginJson string, files map[string][]byte) ([]byte, error) {
+
+	var manifest extensionManifest
+	err := json.Unmarshal([]byte(pluginJson), &manifest)
 	if err != nil {
-		return api.DynatraceEntity{
-			Name: extensionName,
-		}, err
+		return nil, fmt.Errorf("%s is invalid: %s", extensionManifestFile, err)
+	}
+
+	folder := manifest.Name
+	if folder == "" {
+		folder = "custom"
 	}
 
-	resp := PostMultiPartFile(apiPath, buffer, contentType, apiToken)
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		if path == extensionManifestFile {
+			return nil, fmt.Errorf("%s is rendered from the template and must not be part of the extension files", extensionManifestFile)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
 
-	if resp.StatusCode != http.StatusCreated {
-		util.Log.Error("\t\t\tUpload of %s failed with status %d!\n\t\t\t\t\tError-message: %s\n", extensionName, resp.StatusCode, string(resp.Body))
-	} else {
-		util.Log.Debug("\t\t\tExtension upload successful for %s", extensionName)
+	buffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buffer)
 
-		// As other configs depend on metrics created by extensions, and metric creation seems to happen with delay...
-		time.Sleep(1 * time.Second)
+	err = writeZipEntry(zipWriter, folder+"/"+extensionManifestFile, []byte(pluginJson))
+	if err != nil {
+		return nil, err
 	}
 
-	return api.DynatraceEntity{
+	for _, path := range paths {
+		err = writeZipEntry(zipWriter, folder+"/"+path, files[path])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = zipWriter.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// UploadExtension uploads the extension given by its plugin.json, if it is not already present in the same version.
+//
+// Deprecated: Use DynatraceClient.UpsertByName with the extension api instead, which also uploads zip archives
+func UploadExtension(apiPath string, extensionName string, extensionJson string, apiToken string) (api.DynatraceEntity, error) {
+	client := &http.Client{}
+	return uploadExtension(client, extensionUploader{client: client}, apiPath, extensionName, []byte(extensionJson), apiToken)
+}
+
+// uploadExtension uploads the extension, if it is not already present in the same version. The payload is either the
+// plugin.json of the extension or a zip archive of the whole extension. If the uploader waits for the activation,
+// uploadExtension returns once the instances of the extension are initialized, e.g. as other configs depend on the
+// metrics the extension creates
+func uploadExtension(client *http.Client, uploader extensionUploader, apiPath string, extensionName string, payload []byte, apiToken string) (api.DynatraceEntity, error) {
+
+	entity := api.DynatraceEntity{
 		Name: extensionName,
-	}, nil
+	}
+
+	archive := payload
+	if !isZipArchive(payload) {
+		var err error
+		archive, err = ExtensionArchive(string(payload), nil)
+		if err != nil {
+			return entity, fmt.Errorf("extension %s is invalid: %s", extensionName, err)
+		}
+	}
+
+	manifest, err := validateExtensionArchive(archive)
+	if err != nil {
+		return entity, fmt.Errorf("extension %s is invalid: %s", extensionName, err)
+	}
+	entity.Id = manifest.Name
+
+	extensionUrl := objectUrl(apiPath, manifest.Name)
+
+	existing := get(client, extensionUrl, apiToken)
+	if success(existing) {
+		var current extensionManifest
+		if json.Unmarshal(existing.Body, &current) == nil && current.Version == manifest.Version {
+			util.Log.Debug("\t\t\tExtension %s is already uploaded in version %s", manifest.Name, manifest.Version)
+			return entity, nil
+		}
+	}
+
+	err = uploader.upload(apiPath, manifest.Name, archive, apiToken)
+	if err != nil {
+		return entity, err
+	}
+
+	util.Log.Debug("\t\t\tExtension upload successful for %s", extensionName)
 
+	if !uploader.waitForActivation {
+		return entity, nil
+	}
+	return entity, waitForExtension(client, extensionUrl, manifest.Name, apiToken)
 }
 
-func writeMultiPartForm(extensionName string, extensionJson string) (buffer *bytes.Buffer, contentType string, err error) {
+// upload posts the archive as multipart form. Uploads failing without response, e.g. as they timed out, or with a
+// server error (HTTP 5xx) are retried
+func (u extensionUploader) upload(apiPath string, extensionId string, archive []byte, apiToken string) error {
+
+	for attempt := 1; ; attempt++ {
+
+		buffer, contentType, err := writeMultiPartForm(extensionId, archive)
+		if err != nil {
+			return err
+		}
+
+		resp := postMultiPartFile(u.client, apiPath, buffer, contentType, apiToken)
+		if resp.StatusCode == http.StatusCreated {
+			return nil
+		}
+
+		retriable := resp.StatusCode == 0 || resp.StatusCode >= http.StatusInternalServerError
+		if !retriable || attempt > u.retries {
+			return responseError(resp, "upload of extension %s failed after %d attempts", extensionId, attempt)
+		}
+
+		wait := time.Duration(attempt) * extensionRetryDelay
+		util.Log.Debug("\t\t\tUpload of extension %s failed with status %d, retrying in %s", extensionId, resp.StatusCode, wait)
+		extensionRetrySleep(wait)
+	}
+}
+
+// validateExtensionArchive checks that the archive contains exactly one folder with the plugin.json describing
+// the extension, and that the plugin.json defines the name and version of the extension
+func validateExtensionArchive(archive []byte) (manifest extensionManifest, err error) {
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return manifest, fmt.Errorf("archive can not be read: %s", err)
+	}
+
+	folder := ""
+	var manifestFile *zip.File
+
+	for _, file := range reader.File {
+
+		parts := strings.SplitN(file.Name, "/", 2)
+		if len(parts) < 2 || parts[0] == "" {
+			return manifest, fmt.Errorf("%s is not located in the folder of the extension", file.Name)
+		}
+
+		if folder != "" && parts[0] != folder {
+			return manifest, fmt.Errorf("archive contains the folders %s and %s, but an extension must be located in exactly one folder", folder, parts[0])
+		}
+		folder = parts[0]
+
+		if parts[1] == extensionManifestFile {
+			manifestFile = file
+		}
+	}
+
+	if manifestFile == nil {
+		return manifest, fmt.Errorf("archive does not contain a %s", extensionManifestFile)
+	}
+
+	content, err := readZipEntry(manifestFile)
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(content, &manifest)
+	if err != nil {
+		return manifest, fmt.Errorf("%s is invalid: %s", extensionManifestFile, err)
+	}
+
+	if !strings.HasPrefix(manifest.Name, customExtensionPrefix) {
+		return manifest, fmt.Errorf("name '%s' of the extension must start with '%s', as Dynatrace only accepts uploads of custom extensions", manifest.Name, customExtensionPrefix)
+	}
+	if manifest.Version == "" {
+		return manifest, fmt.Errorf("version of the extension is missing in %s", extensionManifestFile)
+	}
+
+	return manifest, nil
+}
+
+// waitForExtension polls the states of the extension until none of its instances is pending. Instances in
+// an error state do not fail the upload, as they usually indicate a problem with the monitored endpoint
+func waitForExtension(client *http.Client, extensionUrl string, extensionId string, apiToken string) error {
+
+	return Wait("activation of extension "+extensionId, extensionActivationPolls, func() bool {
+
+		resp := get(client, extensionUrl+extensionStatesPath, apiToken)
+		if !success(resp) {
+			return false
+		}
+
+		var states extensionStatesResponse
+		err := json.Unmarshal(resp.Body, &states)
+		if err != nil {
+			return false
+		}
+
+		for _, state := range states.States {
+			if pendingExtensionStates[state.State] {
+				return false
+			}
+			if strings.HasPrefix(state.State, "ERROR") {
+				util.Log.Warn("\t\t\tAn instance of extension %s reports state %s", extensionId, state.State)
+			}
+		}
+
+		return true
+	})
+}
+
+func isZipArchive(payload []byte) bool {
+	return bytes.HasPrefix(payload, []byte("PK\x03\x04"))
+}
+
+func writeMultiPartForm(extensionName string, archive []byte) (buffer *bytes.Buffer, contentType string, err error) {
 	buffer = new(bytes.Buffer)
 	multipartWriter := multipart.NewWriter(buffer)
-	formFileWriter, _ := multipartWriter.CreateFormFile("file", extensionName+".zip")
-
-	zipBuffer, err := writeInMemoryZip("custom/plugin.json", extensionJson)
+	formFileWriter, err := multipartWriter.CreateFormFile("file", extensionName+".zip")
 	if err != nil {
 		return buffer, "", err
 	}
 
-	_, err = formFileWriter.Write(zipBuffer.Bytes())
+	_, err = formFileWriter.Write(archive)
 	if err != nil {
 		return buffer, "", err
 	}
@@ -77,21 +324,21 @@ func writeMultiPartForm(extensionName string, extensionJson string) (buffer *byt
 	return buffer, contentType, nil
 }
 
-func writeInMemoryZip(fileName string, fileContent string) (*bytes.Buffer, error) {
-	buffer := new(bytes.Buffer)
-	zipWriter := zip.NewWriter(buffer)
+func writeZipEntry(zipWriter *zip.Writer, fileName string, content []byte) error {
 	zipFile, err := zipWriter.Create(fileName)
 	if util.CheckError(err, "Failed to create .zip file") {
-		return buffer, err
-	}
-	_, err = zipFile.Write([]byte(fileContent))
-	if err != nil {
-		return buffer, err
+		return err
 	}
-	err = zipWriter.Close()
+	_, err = zipFile.Write(content)
+	return err
+}
+
+func readZipEntry(file *zip.File) ([]byte, error) {
+	in, err := file.Open()
 	if err != nil {
-		return buffer, err
+		return nil, err
 	}
+	defer in.Close()
 
-	return buffer, nil
+	return ioutil.ReadAll(in)
 }
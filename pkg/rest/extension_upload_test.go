@@ -0,0 +1,210 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+const testPluginJson = `{"name": "custom.python.demo", "version": "1.0.0"}`
+
+func TestExtensionArchiveContainsPluginJsonAndFiles(t *testing.T) {
+
+	archive, err := ExtensionArchive(testPluginJson, map[string][]byte{"demo.py": []byte("print()"), "lib/util.py": []byte("")})
+	assert.NilError(t, err)
+
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NilError(t, err)
+
+	names := make([]string, 0, len(reader.File))
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	assert.DeepEqual(t, names, []string{"custom.python.demo/plugin.json", "custom.python.demo/demo.py", "custom.python.demo/lib/util.py"})
+
+	manifest, err := validateExtensionArchive(archive)
+	assert.NilError(t, err)
+	assert.Equal(t, manifest.Name, "custom.python.demo")
+	assert.Equal(t, manifest.Version, "1.0.0")
+}
+
+func TestExtensionArchiveRejectsPluginJsonInFiles(t *testing.T) {
+
+	_, err := ExtensionArchive(testPluginJson, map[string][]byte{"plugin.json": []byte("{}")})
+	assert.ErrorContains(t, err, "must not be part of the extension files")
+}
+
+func TestValidateExtensionArchive(t *testing.T) {
+
+	tests := []struct {
+		files map[string]string
+		err   string
+	}{
+		{map[string]string{"custom.demo/demo.py": ""}, "does not contain a plugin.json"},
+		{map[string]string{"plugin.json": testPluginJson}, "is not located in the folder of the extension"},
+		{map[string]string{"a/plugin.json": testPluginJson, "b/demo.py": ""}, "exactly one folder"},
+		{map[string]string{"a/plugin.json": `{"name": "demo", "version": "1.0.0"}`}, "must start with 'custom.'"},
+		{map[string]string{"a/plugin.json": `{"name": "custom.demo"}`}, "version of the extension is missing"},
+	}
+
+	for _, test := range tests {
+		_, err := validateExtensionArchive(testZip(t, test.files))
+		assert.ErrorContains(t, err, test.err)
+	}
+}
+
+func TestUploadExtensionWaitsForActivation(t *testing.T) {
+
+	var uploads, statePolls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/api/config/v1/extensions/custom.python.demo/states":
+			statePolls++
+			_, _ = w.Write([]byte(`{"states": [{"state": "OK"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	uploader := extensionUploader{client: server.Client(), waitForActivation: true}
+	entity, err := uploadExtension(server.Client(), uploader, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.NilError(t, err)
+	assert.Equal(t, entity.Id, "custom.python.demo")
+	assert.Equal(t, uploads, 1)
+	assert.Equal(t, statePolls, 1)
+
+	uploader.waitForActivation = false
+	_, err = uploadExtension(server.Client(), uploader, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.NilError(t, err)
+	assert.Equal(t, uploads, 2)
+	assert.Equal(t, statePolls, 1, "states must only be polled if the upload waits for the activation")
+}
+
+func TestUploadExtensionSkipsUploadOfSameVersion(t *testing.T) {
+
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			uploads++
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": "custom.python.demo", "name": "custom.python.demo", "version": "1.0.0"}`))
+	}))
+	defer server.Close()
+
+	_, err := uploadExtension(server.Client(), extensionUploader{client: server.Client()}, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.NilError(t, err)
+	assert.Equal(t, uploads, 0)
+}
+
+func TestUploadExtensionFailsOnRejectedUpload(t *testing.T) {
+
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			uploads++
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"message": "invalid extension"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := uploadExtension(server.Client(), extensionUploader{client: server.Client()}, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.ErrorContains(t, err, "upload of extension custom.python.demo failed")
+	assert.Equal(t, uploads, 1, "rejected uploads must not be retried")
+}
+
+func TestUploadExtensionRetriesOnServerError(t *testing.T) {
+
+	var waits []time.Duration
+	extensionRetrySleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { extensionRetrySleep = time.Sleep }()
+
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost:
+			uploads++
+			if uploads < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+		case r.URL.Path == "/api/config/v1/extensions/custom.python.demo/states":
+			_, _ = w.Write([]byte(`{"states": [{"state": "OK"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	uploader := extensionUploader{client: server.Client(), retries: 2}
+	_, err := uploadExtension(server.Client(), uploader, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.NilError(t, err)
+	assert.Equal(t, uploads, 3)
+	assert.DeepEqual(t, waits, []time.Duration{extensionRetryDelay, 2 * extensionRetryDelay})
+}
+
+func TestUploadExtensionFailsAfterLastRetry(t *testing.T) {
+
+	extensionRetrySleep = func(time.Duration) {}
+	defer func() { extensionRetrySleep = time.Sleep }()
+
+	var uploads int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			uploads++
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	uploader := extensionUploader{client: server.Client(), retries: 1}
+	_, err := uploadExtension(server.Client(), uploader, server.URL+"/api/config/v1/extensions", "custom.python.demo", []byte(testPluginJson), "token")
+	assert.ErrorContains(t, err, "failed after 2 attempts")
+	assert.Equal(t, uploads, 2)
+}
+
+func testZip(t *testing.T, files map[string]string) []byte {
+
+	buffer := new(bytes.Buffer)
+	zipWriter := zip.NewWriter(buffer)
+	for name, content := range files {
+		assert.NilError(t, writeZipEntry(zipWriter, name, []byte(content)))
+	}
+	assert.NilError(t, zipWriter.Close())
+
+	return buffer.Bytes()
+}
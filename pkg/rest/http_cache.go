@@ -0,0 +1,158 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// HttpCache keeps the responses of GET requests in a folder, so that they are shared across monaco runs. Cached
+// responses are revalidated by their ETag (If-None-Match), so a response is only transferred again if it changed.
+// Responses without ETag are not cached. Responses are kept per token, as tokens may see different objects
+type HttpCache struct {
+	folder string
+}
+
+// cachedResponse is the json representation of a cached response
+type cachedResponse struct {
+	Url        string      `json:"url"`
+	ETag       string      `json:"etag"`
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+}
+
+// NewHttpCache creates a cache keeping its responses in the given folder, which is created if it does not exist
+func NewHttpCache(folder string) (*HttpCache, error) {
+
+	err := os.MkdirAll(folder, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("http cache %s could not be created: %s", folder, err)
+	}
+
+	return &HttpCache{folder: folder}, nil
+}
+
+// file returns the file the response of the request is kept in
+func (c *HttpCache) file(request *http.Request) string {
+
+	hash := sha256.Sum256([]byte(request.URL.String() + "\n" + request.Header.Get("Authorization")))
+	return filepath.Join(c.folder, hex.EncodeToString(hash[:])+".json")
+}
+
+func (c *HttpCache) lookup(request *http.Request) (cachedResponse, bool) {
+
+	content, err := ioutil.ReadFile(c.file(request))
+	if err != nil {
+		return cachedResponse{}, false
+	}
+
+	var cached cachedResponse
+	err = json.Unmarshal(content, &cached)
+	if err != nil || cached.ETag == "" {
+		return cachedResponse{}, false
+	}
+
+	return cached, true
+}
+
+func (c *HttpCache) store(request *http.Request, cached cachedResponse) {
+
+	content, err := json.Marshal(cached)
+	if err == nil {
+		err = ioutil.WriteFile(c.file(request), content, 0600)
+	}
+	if err != nil {
+		util.Log.Debug("\t\tCaching response of %s failed: %s", request.URL.Path, err)
+	}
+}
+
+func (r cachedResponse) toResponse(request *http.Request) *http.Response {
+	return &http.Response{
+		Status:        http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
+
+// cachingTransport answers GET requests from the cache if the server confirms that the cached response is current
+type cachingTransport struct {
+	base  http.RoundTripper
+	cache *HttpCache
+}
+
+func (t *cachingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	if request.Method != http.MethodGet {
+		return t.base.RoundTrip(request)
+	}
+
+	cached, found := t.cache.lookup(request)
+	if found {
+		request = request.Clone(request.Context())
+		request.Header.Set("If-None-Match", cached.ETag)
+	}
+
+	resp, err := t.base.RoundTrip(request)
+	if err != nil {
+		return resp, err
+	}
+
+	if found && resp.StatusCode == http.StatusNotModified {
+		resp.Body.Close()
+		util.Log.Debug("\t\tGET request to %s was answered from the http cache", request.URL.Path)
+		return cached.toResponse(request), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	t.cache.store(request, cachedResponse{
+		Url:        request.URL.String(),
+		ETag:       etag,
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       body,
+	})
+
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// newETagServer serves body with the ETag of the given version and answers requests for that version with 304
+func newETagServer(t *testing.T, version *string, body *string, requests *[]string) *httptest.Server {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.Header.Get("If-None-Match"))
+
+		if r.Header.Get("If-None-Match") != "" && r.Header.Get("If-None-Match") == *version {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", *version)
+		w.Write([]byte(*body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func getWithCache(t *testing.T, folder string, url string) string {
+
+	cache, err := NewHttpCache(folder)
+	assert.NilError(t, err)
+
+	client := &http.Client{Transport: &cachingTransport{base: http.DefaultTransport, cache: cache}}
+
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	assert.NilError(t, err)
+	request.Header.Set("Authorization", "Api-Token secret")
+
+	resp, err := client.Do(request)
+	assert.NilError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, resp.StatusCode, http.StatusOK)
+
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.NilError(t, err)
+
+	return string(body)
+}
+
+func TestHttpCacheRevalidatesResponsesAcrossRuns(t *testing.T) {
+
+	version, body := `"v1"`, `{"name": "first"}`
+	var requests []string
+	server := newETagServer(t, &version, &body, &requests)
+
+	folder := t.TempDir()
+
+	assert.Equal(t, getWithCache(t, folder, server.URL+"/api/config/v1/dashboards/1"), `{"name": "first"}`)
+	assert.Equal(t, getWithCache(t, folder, server.URL+"/api/config/v1/dashboards/1"), `{"name": "first"}`)
+
+	version, body = `"v2"`, `{"name": "second"}`
+	assert.Equal(t, getWithCache(t, folder, server.URL+"/api/config/v1/dashboards/1"), `{"name": "second"}`)
+
+	assert.DeepEqual(t, requests, []string{`GET `, `GET "v1"`, `GET "v1"`})
+}
+
+func TestHttpCacheOnlyCachesGetRequestsWithETag(t *testing.T) {
+
+	version, body := "", `{}`
+	var requests []string
+	server := newETagServer(t, &version, &body, &requests)
+
+	folder := t.TempDir()
+
+	getWithCache(t, folder, server.URL+"/api/config/v1/dashboards/1")
+	getWithCache(t, folder, server.URL+"/api/config/v1/dashboards/1")
+
+	files, err := ioutil.ReadDir(folder)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 0)
+	assert.DeepEqual(t, requests, []string{`GET `, `GET `})
+}
+
+func TestHttpCacheKeepsResponsesPerToken(t *testing.T) {
+
+	cache, err := NewHttpCache(t.TempDir())
+	assert.NilError(t, err)
+
+	first, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+	first.Header.Set("Authorization", "Api-Token first")
+	second, _ := http.NewRequest(http.MethodGet, "https://example.com/api", nil)
+	second.Header.Set("Authorization", "Api-Token second")
+
+	assert.Assert(t, cache.file(first) != cache.file(second))
+}
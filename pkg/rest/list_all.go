@@ -0,0 +1,76 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// maxConcurrentListRequests limits the number of apis ListAll lists at the same time
+const maxConcurrentListRequests = 8
+
+// ListAll lists the configs of all given apis concurrently, as listing more than 50 apis one after
+// the other takes considerable time. Returns the values per api id. If listing fails for
+// several apis, the error of the api with the lowest id is returned
+func ListAll(client DynatraceClient, apis map[string]api.Api) (map[string][]api.Value, error) {
+
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentListRequests)
+
+	results := make(map[string][]api.Value, len(apis))
+	errs := make(map[string]error)
+
+	for id, a := range apis {
+		wg.Add(1)
+
+		go func(id string, a api.Api) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			values, err := client.List(a)
+			<-limit
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			if err != nil {
+				errs[id] = err
+			} else {
+				results[id] = values
+			}
+		}(id, a)
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		failed := make([]string, 0, len(errs))
+		for id := range errs {
+			failed = append(failed, id)
+		}
+		sort.Strings(failed)
+
+		return results, fmt.Errorf("listing of %s failed: %s", failed[0], errs[failed[0]])
+	}
+
+	return results, nil
+}
@@ -0,0 +1,79 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+// listingClient returns one value per api, named after the api, and fails for the apis in failing
+type listingClient struct {
+	DynatraceClient
+	lock    sync.Mutex
+	calls   int
+	failing map[string]bool
+}
+
+func (c *listingClient) List(a api.Api) ([]api.Value, error) {
+	c.lock.Lock()
+	c.calls++
+	c.lock.Unlock()
+
+	if c.failing[a.GetId()] {
+		return nil, fmt.Errorf("%s unavailable", a.GetId())
+	}
+	return []api.Value{{Id: "1", Name: a.GetId()}}, nil
+}
+
+func testApis(count int) map[string]api.Api {
+	apis := make(map[string]api.Api, count)
+	for i := 0; i < count; i++ {
+		id := fmt.Sprintf("api-%02d", i)
+		apis[id] = api.NewApi(id, "/api/config/v1/"+id)
+	}
+	return apis
+}
+
+func TestListAllListsEveryApi(t *testing.T) {
+
+	client := &listingClient{}
+	apis := testApis(25)
+
+	values, err := ListAll(client, apis)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 25, client.calls)
+	assert.Equal(t, 25, len(values))
+	for id := range apis {
+		assert.Equal(t, id, values[id][0].Name)
+	}
+}
+
+func TestListAllReturnsErrorOfLowestApiId(t *testing.T) {
+
+	client := &listingClient{failing: map[string]bool{"api-07": true, "api-03": true}}
+
+	_, err := ListAll(client, testApis(10))
+	assert.Error(t, err, "listing of api-03 failed: api-03 unavailable")
+}
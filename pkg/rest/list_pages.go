@@ -0,0 +1,97 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"net/url"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// PageLister lists configs page by page, so that the configs of large environments need not be held in memory at once.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type PageLister interface {
+
+	// ListPages lists the available configs for the given api and hands each page to handle as soon as it is
+	// received. The next page is only requested after handle returned. Listing stops at the first error of handle
+	ListPages(a api.Api, handle func(values []api.Value) error) error
+}
+
+// ListPages lists the configs of the api page by page if the client is a PageLister, or as a single page otherwise
+func ListPages(client DynatraceClient, a api.Api, handle func(values []api.Value) error) error {
+
+	if lister, ok := client.(PageLister); ok {
+		return lister.ListPages(a, handle)
+	}
+
+	values, err := client.List(a)
+	if err != nil {
+		return err
+	}
+
+	return handle(values)
+}
+
+type nextPageResponse struct {
+	NextPageKey string `json:"nextPageKey"`
+}
+
+func (d *dynatraceClientImpl) ListPages(a api.Api, handle func(values []api.Value) error) error {
+
+	d.cacheLock.Lock()
+	cached, found := d.listCache[a.GetId()]
+	d.cacheLock.Unlock()
+
+	if found {
+		return handle(cached)
+	}
+
+	// pages are not cached, as caching them would keep all configs in memory again
+	listUrl := a.GetUrlFromEnvironmentUrl(d.environmentUrl)
+	pageUrl := listUrl
+
+	for {
+		resp, err := d.get(pageUrl)
+		if err != nil {
+			return err
+		}
+
+		_, values, err := unmarshalExistingValues(a.GetId(), resp)
+		if err != nil {
+			return err
+		}
+
+		// lists which are not paged, e.g. the plain arrays of aws-credentials, have no page key
+		var page nextPageResponse
+		_ = json.Unmarshal(resp.Body, &page)
+
+		err = handle(values)
+		if err != nil {
+			return err
+		}
+
+		if page.NextPageKey == "" {
+			return nil
+		}
+
+		// subsequent pages are requested by the page key only
+		query := url.Values{}
+		query.Set("nextPageKey", page.NextPageKey)
+		pageUrl = listUrl + "?" + query.Encode()
+	}
+}
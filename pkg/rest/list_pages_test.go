@@ -0,0 +1,73 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func TestListPagesHandlesEachPageBeforeRequestingTheNext(t *testing.T) {
+
+	var events []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, "/api/config/v1/managementZones")
+		events = append(events, "request "+r.URL.Query().Get("nextPageKey"))
+
+		if r.URL.Query().Get("nextPageKey") == "" {
+			_, _ = w.Write([]byte(`{"values": [{"id": "1", "name": "a"}], "nextPageKey": "page2"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"values": [{"id": "2", "name": "b"}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	a := api.NewApi("management-zone", "/api/config/v1/managementZones")
+	err = ListPages(client, a, func(values []api.Value) error {
+		for _, value := range values {
+			events = append(events, "handle "+value.Id)
+		}
+		return nil
+	})
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, events, []string{"request ", "handle 1", "request page2", "handle 2"})
+}
+
+func TestListPagesHandsListOfOtherClientsAsSinglePage(t *testing.T) {
+
+	client := &listingClient{}
+
+	var pages [][]api.Value
+	err := ListPages(client, api.NewApi("alerting-profile", "/api/config/v1/alertingProfiles"), func(values []api.Value) error {
+		pages = append(pages, values)
+		return nil
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, pages, [][]api.Value{{{Id: "1", Name: "alerting-profile"}}})
+	assert.Equal(t, client.calls, 1)
+}
@@ -0,0 +1,163 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// NameCache remembers the names and ids of the configs listed per environment and api in a file, so that consecutive
+// monaco runs, e.g. a deployment followed by a deletion, do not list the same apis again. Listings older than the
+// time to live are not used, as they may miss objects created or deleted by others
+type NameCache struct {
+	ttl  time.Duration
+	now  func() time.Time
+	lock sync.Mutex
+
+	// listings are keyed by environment url and api id
+	listings map[string]map[string]nameCacheListing
+}
+
+// nameCacheListing holds the configs of an api listed at the given time
+type nameCacheListing struct {
+	Listed time.Time   `json:"listed"`
+	Values []api.Value `json:"values"`
+}
+
+// nameCacheFile is the json representation of a NameCache
+type nameCacheFile struct {
+	Environments map[string]map[string]nameCacheListing `json:"environments"`
+}
+
+// NewNameCache creates an empty cache using listings for the given time to live
+func NewNameCache(ttl time.Duration) *NameCache {
+	return &NameCache{
+		ttl:      ttl,
+		now:      time.Now,
+		listings: make(map[string]map[string]nameCacheListing),
+	}
+}
+
+// LoadNameCache reads the cache from the given file. Returns an empty cache if the file does not exist yet
+func LoadNameCache(file string, ttl time.Duration, fileReader util.FileReader) (*NameCache, error) {
+
+	content, err := fileReader.ReadFile(file)
+	if os.IsNotExist(err) {
+		return NewNameCache(ttl), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed nameCacheFile
+	err = json.Unmarshal(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("name cache %s is invalid: %s", file, err)
+	}
+
+	cache := NewNameCache(ttl)
+	for environmentUrl, listings := range parsed.Environments {
+		cache.listings[environmentUrl] = listings
+	}
+
+	return cache, nil
+}
+
+// lookup returns the cached configs of the api, if they were listed within the time to live
+func (c *NameCache) lookup(environmentUrl string, apiId string) ([]api.Value, bool) {
+
+	if c == nil {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	listing, found := c.listings[environmentUrl][apiId]
+	if !found || !c.isFresh(listing) {
+		return nil, false
+	}
+	return listing.Values, true
+}
+
+// store remembers the configs of the api, which were just listed
+func (c *NameCache) store(environmentUrl string, apiId string, values []api.Value) {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.listings[environmentUrl] == nil {
+		c.listings[environmentUrl] = make(map[string]nameCacheListing)
+	}
+	c.listings[environmentUrl][apiId] = nameCacheListing{Listed: c.now(), Values: values}
+}
+
+// invalidate forgets the configs of the api, e.g. as one of them was written
+func (c *NameCache) invalidate(environmentUrl string, apiId string) {
+
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	delete(c.listings[environmentUrl], apiId)
+}
+
+// Save writes all listings within the time to live to the given file
+func (c *NameCache) Save(file string) error {
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	environments := make(map[string]map[string]nameCacheListing, len(c.listings))
+	for environmentUrl, listings := range c.listings {
+		for apiId, listing := range listings {
+			if !c.isFresh(listing) {
+				continue
+			}
+			if environments[environmentUrl] == nil {
+				environments[environmentUrl] = make(map[string]nameCacheListing)
+			}
+			environments[environmentUrl][apiId] = listing
+		}
+	}
+
+	content, err := json.MarshalIndent(nameCacheFile{Environments: environments}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(content, '\n'), 0664)
+}
+
+func (c *NameCache) isFresh(listing nameCacheListing) bool {
+	return c.now().Sub(listing.Listed) < c.ttl
+}
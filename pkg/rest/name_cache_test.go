@@ -0,0 +1,122 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestNameCacheIsSharedBetweenClients(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "names.json")
+
+	for run := 0; run < 2; run++ {
+
+		cache, err := LoadNameCache(file, time.Hour, util.NewFileReader())
+		assert.NilError(t, err)
+
+		client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{NameCache: cache})
+		assert.NilError(t, err)
+
+		exists, id, err := client.ExistsByName(testManagementZoneApi, "zone")
+		assert.NilError(t, err)
+		assert.Equal(t, true, exists)
+		assert.Equal(t, "42", id)
+
+		assert.NilError(t, cache.Save(file))
+	}
+
+	assert.Equal(t, 1, listRequests, "the second run must use the cached list")
+}
+
+func TestNameCacheIgnoresExpiredListings(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	cache := NewNameCache(time.Minute)
+	listed := time.Date(2020, 12, 1, 12, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return listed }
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{NameCache: cache})
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+
+	_, found := cache.lookup(server.URL, testManagementZoneApi.GetId())
+	assert.Equal(t, found, true)
+
+	cache.now = func() time.Time { return listed.Add(2 * time.Minute) }
+
+	_, found = cache.lookup(server.URL, testManagementZoneApi.GetId())
+	assert.Equal(t, found, false)
+
+	file := filepath.Join(t.TempDir(), "names.json")
+	assert.NilError(t, cache.Save(file))
+
+	loaded, err := LoadNameCache(file, time.Hour, util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.listings), 0, "expired listings must not be saved")
+}
+
+func TestUpsertInvalidatesNameCache(t *testing.T) {
+
+	var listRequests int
+	server := newTestServer(&listRequests)
+	defer server.Close()
+
+	cache := NewNameCache(time.Hour)
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{NameCache: cache})
+	assert.NilError(t, err)
+
+	_, err = client.UpsertByName(testManagementZoneApi, "zone", []byte(`{"name": "zone"}`))
+	assert.NilError(t, err)
+
+	_, found := cache.lookup(server.URL, testManagementZoneApi.GetId())
+	assert.Equal(t, found, false)
+}
+
+func TestLoadNameCacheFailsOnInvalidFile(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "names.json")
+	err := ioutil.WriteFile(file, []byte("{"), 0664)
+	assert.NilError(t, err)
+
+	_, err = LoadNameCache(file, time.Hour, util.NewFileReader())
+	assert.ErrorContains(t, err, "name cache "+file+" is invalid")
+}
+
+func TestLoadNameCacheOfMissingFileIsEmpty(t *testing.T) {
+
+	cache, err := LoadNameCache(filepath.Join(t.TempDir(), "names.json"), time.Hour, util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, len(cache.listings), 0)
+}
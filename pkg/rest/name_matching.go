@@ -0,0 +1,47 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NameMatching defines how names are compared when looking up configs by name. Names are always compared in
+// unicode normalization form NFC, so that names typed with composed or decomposed characters are the same
+type NameMatching struct {
+
+	// CaseInsensitive matches names which only differ in case
+	CaseInsensitive bool
+}
+
+// Normalize returns the form of the name used for comparisons
+func (m NameMatching) Normalize(name string) string {
+
+	name = norm.NFC.String(name)
+	if m.CaseInsensitive {
+		name = strings.ToLower(name)
+	}
+
+	return name
+}
+
+// Matches checks if both names are the same after normalization
+func (m NameMatching) Matches(first string, second string) bool {
+	return m.Normalize(first) == m.Normalize(second)
+}
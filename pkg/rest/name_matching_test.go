@@ -0,0 +1,57 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"gotest.tools/assert"
+)
+
+func TestNamesAreMatchedInNormalizationFormNfc(t *testing.T) {
+
+	composed := "Caf\u00e9"
+	decomposed := "Cafe\u0301"
+
+	assert.Assert(t, NameMatching{}.Matches(composed, decomposed))
+	assert.Assert(t, !NameMatching{}.Matches(composed, "café"))
+}
+
+func TestNamesAreMatchedCaseInsensitive(t *testing.T) {
+
+	matching := NameMatching{CaseInsensitive: true}
+
+	assert.Assert(t, matching.Matches("My Dashboard", "my dashboard"))
+	assert.Assert(t, matching.Matches("CAFÉ", "café"))
+	assert.Assert(t, !matching.Matches("My Dashboard", "My Dashboard 2"))
+}
+
+func TestFindByNameUsesNameMatching(t *testing.T) {
+
+	values := []api.Value{{Id: "1", Name: "Zone"}, {Id: "2", Name: "zone"}}
+
+	exists, id, err := FindByName(testManagementZoneApi, values, "zone", NameMatching{})
+	assert.NilError(t, err)
+	assert.Equal(t, exists, true)
+	assert.Equal(t, id, "2")
+
+	_, _, err = FindByName(testManagementZoneApi, values, "ZONE", NameMatching{CaseInsensitive: true})
+	assert.DeepEqual(t, err, DuplicateNameError{Api: "management-zone", Name: "ZONE", Ids: []string{"1", "2"}})
+}
@@ -0,0 +1,61 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+)
+
+// problemsPath is the path of the problems api, relative to the environment url
+const problemsPath = "/api/v2/problems"
+
+// ProblemsClient reads the problems Dynatrace detected for monitored entities of an environment.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type ProblemsClient interface {
+
+	// CountOpenProblems returns the number of open problems affecting the entity with the given id,
+	// e.g. the failing executions of a synthetic monitor
+	CountOpenProblems(entityId string) (count int, err error)
+}
+
+type problemsListResponse struct {
+	TotalCount int `json:"totalCount"`
+}
+
+func (d *dynatraceClientImpl) CountOpenProblems(entityId string) (count int, err error) {
+
+	query := url.Values{}
+	query.Set("problemSelector", `status("open")`)
+	query.Set("entitySelector", fmt.Sprintf("entityId(%q)", entityId))
+	query.Set("pageSize", "1")
+
+	resp, err := d.get(d.environmentUrl + problemsPath + "?" + query.Encode())
+	if err != nil {
+		return 0, err
+	}
+
+	var problems problemsListResponse
+	err = json.Unmarshal(resp.Body, &problems)
+	if err != nil {
+		return 0, fmt.Errorf("cannot unmarshal problems of %s: %s", entityId, err)
+	}
+
+	return problems.TotalCount, nil
+}
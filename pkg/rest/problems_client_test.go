@@ -0,0 +1,46 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+
+package rest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCountOpenProblemsOfEntity(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.URL.Path, problemsPath)
+		assert.Equal(t, r.URL.Query().Get("problemSelector"), `status("open")`)
+		assert.Equal(t, r.URL.Query().Get("entitySelector"), `entityId("SYNTHETIC_TEST-1")`)
+		_, _ = w.Write([]byte(`{"totalCount": 2, "pageSize": 1, "problems": [{"problemId": "1"}]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	count, err := client.(ProblemsClient).CountOpenProblems("SYNTHETIC_TEST-1")
+	assert.NilError(t, err)
+	assert.Equal(t, count, 2)
+}
@@ -0,0 +1,389 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+)
+
+// redacted replaces tokens and other credentials in recorded requests
+const redacted = "<redacted>"
+
+// sensitiveHeaders are the headers whose values are never recorded
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// sensitiveFields are the parts of the names of json fields whose values are never recorded, e.g. the password of a
+// webhook or the apiKey of an alerting integration
+var sensitiveFields = []string{"password", "secret", "token", "credential", "apikey", "privatekey", "accesskey"}
+
+// credentialPaths are the apis whose payloads consist of credentials, only the shape of their bodies is recorded
+var credentialPaths = []string{
+	"/api/config/v1/aws/credentials",
+	"/api/config/v1/azure/credentials",
+	"/api/config/v1/kubernetes/credentials",
+	"/api/config/v1/credentials",
+	"/api/config/v1/notifications",
+	"/api/v2/credentials",
+}
+
+// minRedactedLength is the length values need to be redacted, shorter values would redact unrelated parts of bodies
+const minRedactedLength = 4
+
+// ReplayExchange is a request sent to an environment together with the response the server answered it with
+type ReplayExchange struct {
+	Method          string      `json:"method"`
+	Url             string      `json:"url"`
+	RequestHeaders  http.Header `json:"requestHeaders,omitempty"`
+	RequestBody     string      `json:"requestBody,omitempty"`
+	StatusCode      int         `json:"statusCode,omitempty"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+
+	// Error is the reason the request failed without response, e.g. a timeout
+	Error string `json:"error,omitempty"`
+
+	Started    time.Time `json:"started"`
+	DurationMs int64     `json:"durationMs"`
+}
+
+// ReplayBundle is the content of a replay file, which lets maintainers reproduce the behavior of the apis in a run
+// without access to the environments
+type ReplayBundle struct {
+	MonacoVersion string `json:"monacoVersion"`
+
+	// Versions are the versions of the clusters the environments run on, by environment url
+	Versions  map[string]string `json:"versions,omitempty"`
+	Exchanges []ReplayExchange  `json:"exchanges"`
+}
+
+// Replay records the requests clients send and the responses they receive, with tokens and other credentials removed.
+// It is safe for concurrent use, nothing is recorded if it is nil
+type Replay struct {
+	lock      sync.Mutex
+	versions  map[string]string
+	exchanges []ReplayExchange
+	values    []string
+}
+
+// NewReplay creates an empty replay
+func NewReplay() *Replay {
+	return &Replay{versions: make(map[string]string), exchanges: make([]ReplayExchange, 0)}
+}
+
+// SetVersion records the version of the cluster the environment reachable under environmentUrl runs on
+func (r *Replay) SetVersion(environmentUrl string, version string) {
+
+	if r == nil || version == "" {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.versions[strings.TrimSuffix(environmentUrl, "/")] = version
+}
+
+// Redact makes the replay replace values wherever they occur, e.g. the secrets configs are rendered with
+func (r *Replay) Redact(values ...string) {
+
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, value := range values {
+		if len(value) >= minRedactedLength {
+			r.values = append(r.values, value)
+		}
+	}
+
+	// longer values first, a value containing another one is redacted entirely
+	sort.SliceStable(r.values, func(i, j int) bool { return len(r.values[i]) > len(r.values[j]) })
+}
+
+// Bundle returns the recorded exchanges in the order the requests were sent
+func (r *Replay) Bundle() ReplayBundle {
+
+	bundle := ReplayBundle{MonacoVersion: version.MonitoringAsCode, Versions: map[string]string{}, Exchanges: []ReplayExchange{}}
+	if r == nil {
+		return bundle
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for environmentUrl, v := range r.versions {
+		bundle.Versions[environmentUrl] = v
+	}
+	bundle.Exchanges = append(bundle.Exchanges, r.exchanges...)
+	sort.SliceStable(bundle.Exchanges, func(i, j int) bool { return bundle.Exchanges[i].Started.Before(bundle.Exchanges[j].Started) })
+
+	return bundle
+}
+
+// Save writes the bundle of the recorded exchanges to file
+func (r *Replay) Save(file string) error {
+
+	content, err := json.MarshalIndent(r.Bundle(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, append(content, '\n'), 0600)
+}
+
+func (r *Replay) record(exchange ReplayExchange) {
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+// sanitizeHeaders returns a copy of header without credentials
+func (r *Replay) sanitizeHeaders(header http.Header, token string) http.Header {
+
+	sanitized := make(http.Header, len(header))
+	for key, values := range header {
+		for _, value := range values {
+			sanitized.Add(key, r.sanitize(value, token))
+		}
+	}
+	for _, key := range sensitiveHeaders {
+		if sanitized.Get(key) != "" {
+			sanitized.Set(key, redacted)
+		}
+	}
+
+	return sanitized
+}
+
+// sanitize replaces the token and the values to redact in s, e.g. in a response echoing the request
+func (r *Replay) sanitize(s string, token string) string {
+
+	if token != "" {
+		s = strings.ReplaceAll(s, token, redacted)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, value := range r.values {
+		s = strings.ReplaceAll(s, value, redacted)
+	}
+	return s
+}
+
+// sanitizeBody returns body without credentials. Only the shape of the bodies of the credential apis is kept, of
+// other bodies the values of sensitive fields are removed
+func (r *Replay) sanitizeBody(path string, body string, token string) string {
+
+	body = r.sanitize(body, token)
+	if strings.TrimSpace(body) == "" {
+		return body
+	}
+
+	var content interface{}
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&content); err != nil {
+		if isCredentialPath(path) {
+			return fmt.Sprintf("<%d bytes>", len(body))
+		}
+		return body
+	}
+
+	var sanitized interface{}
+	if isCredentialPath(path) {
+		sanitized = redactStrings(content)
+	} else if containsSensitiveField(content) {
+		sanitized = redactSensitiveFields(content)
+	} else {
+		return body
+	}
+
+	var result bytes.Buffer
+	encoder := json.NewEncoder(&result)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(sanitized); err != nil {
+		return fmt.Sprintf("<%d bytes>", len(body))
+	}
+	return strings.TrimSuffix(result.String(), "\n")
+}
+
+func isCredentialPath(path string) bool {
+
+	path = strings.TrimSuffix(path, "/")
+	for _, credentialPath := range credentialPaths {
+		if strings.HasSuffix(path, credentialPath) || strings.Contains(path, credentialPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func isSensitiveField(name string) bool {
+
+	name = strings.ToLower(name)
+	for _, field := range sensitiveFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSensitiveField returns whether any object in content has a sensitive field
+func containsSensitiveField(content interface{}) bool {
+
+	switch value := content.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			if isSensitiveField(key) || containsSensitiveField(v) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, v := range value {
+			if containsSensitiveField(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// redactStrings replaces all strings in content, keeping its shape
+func redactStrings(content interface{}) interface{} {
+
+	switch value := content.(type) {
+	case map[string]interface{}:
+		redactedObject := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			redactedObject[key] = redactStrings(v)
+		}
+		return redactedObject
+	case []interface{}:
+		redactedArray := make([]interface{}, len(value))
+		for i, v := range value {
+			redactedArray[i] = redactStrings(v)
+		}
+		return redactedArray
+	case string:
+		return redacted
+	}
+	return content
+}
+
+// redactSensitiveFields replaces the values of the sensitive fields in content
+func redactSensitiveFields(content interface{}) interface{} {
+
+	switch value := content.(type) {
+	case map[string]interface{}:
+		redactedObject := make(map[string]interface{}, len(value))
+		for key, v := range value {
+			if isSensitiveField(key) && v != nil {
+				redactedObject[key] = redactStrings(v)
+			} else {
+				redactedObject[key] = redactSensitiveFields(v)
+			}
+		}
+		return redactedObject
+	case []interface{}:
+		redactedArray := make([]interface{}, len(value))
+		for i, v := range value {
+			redactedArray[i] = redactSensitiveFields(v)
+		}
+		return redactedArray
+	}
+	return content
+}
+
+// requestToken returns the token the request is authorized with, e.g. dt0c01.ABC.XYZ for Api-Token dt0c01.ABC.XYZ
+func requestToken(request *http.Request) string {
+
+	authorization := request.Header.Get("Authorization")
+	if i := strings.Index(authorization, " "); i >= 0 {
+		return strings.TrimSpace(authorization[i+1:])
+	}
+	return authorization
+}
+
+// replayTransport records the requests sent by its base transport and their responses
+type replayTransport struct {
+	base   http.RoundTripper
+	replay *Replay
+}
+
+func (t *replayTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	token := requestToken(request)
+	exchange := ReplayExchange{
+		Method:         request.Method,
+		Url:            t.replay.sanitize(request.URL.String(), token),
+		RequestHeaders: t.replay.sanitizeHeaders(request.Header, token),
+		Started:        time.Now(),
+	}
+
+	// archives, e.g. of extensions, are not recorded
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/") {
+		exchange.RequestBody = fmt.Sprintf("<%d bytes of %s>", request.ContentLength, request.Header.Get("Content-Type"))
+	} else if request.Body != nil && request.GetBody != nil {
+		body, err := request.GetBody()
+		if err == nil {
+			content, _ := ioutil.ReadAll(body)
+			body.Close()
+			exchange.RequestBody = t.replay.sanitizeBody(request.URL.Path, string(content), token)
+		}
+	}
+
+	resp, err := t.base.RoundTrip(request)
+	if err != nil {
+		exchange.Error = t.replay.sanitize(err.Error(), token)
+		exchange.DurationMs = time.Since(exchange.Started).Milliseconds()
+		t.replay.record(exchange)
+		return resp, err
+	}
+
+	// the body is read to be recorded and handed on to the client unchanged
+	content, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(content))
+	if err != nil {
+		exchange.Error = t.replay.sanitize(err.Error(), token)
+	}
+
+	exchange.StatusCode = resp.StatusCode
+	exchange.ResponseHeaders = t.replay.sanitizeHeaders(resp.Header, token)
+	exchange.ResponseBody = t.replay.sanitizeBody(request.URL.Path, string(content), token)
+	exchange.DurationMs = time.Since(exchange.Started).Milliseconds()
+	t.replay.record(exchange)
+
+	return resp, nil
+}
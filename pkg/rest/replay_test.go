@@ -0,0 +1,151 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
+	"gotest.tools/assert"
+)
+
+const replayToken = "dt0c01.PUBLIC.SECRET"
+
+func TestReplayRecordsExchangesWithoutTokens(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=1234")
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"values": []}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"message": "invalid token ` + replayToken + `"}}`))
+	}))
+	defer server.Close()
+
+	replay := NewReplay()
+	client, err := NewDynatraceClientWithOptions(server.URL, replayToken, ClientOptions{Replay: replay})
+	assert.NilError(t, err)
+
+	_, err = client.UpsertByName(testManagementZoneApi, "zone", []byte(`{"name": "zone"}`))
+	assert.ErrorContains(t, err, "400")
+	replay.SetVersion(server.URL+"/", "1.232.0.20211214-112149")
+
+	file := filepath.Join(t.TempDir(), "replay.json")
+	assert.NilError(t, replay.Save(file))
+
+	content, err := ioutil.ReadFile(file)
+	assert.NilError(t, err)
+	assert.Assert(t, !strings.Contains(string(content), "SECRET"), string(content))
+	assert.Assert(t, !strings.Contains(string(content), "session=1234"), string(content))
+
+	var bundle ReplayBundle
+	assert.NilError(t, json.Unmarshal(content, &bundle))
+	assert.Equal(t, bundle.MonacoVersion, version.MonitoringAsCode)
+	assert.DeepEqual(t, bundle.Versions, map[string]string{server.URL: "1.232.0.20211214-112149"})
+
+	assert.Equal(t, len(bundle.Exchanges), 2)
+	list, create := bundle.Exchanges[0], bundle.Exchanges[1]
+
+	assert.Equal(t, list.Method, http.MethodGet)
+	assert.Equal(t, list.StatusCode, http.StatusOK)
+	assert.Equal(t, list.ResponseBody, `{"values": []}`)
+	assert.Equal(t, list.RequestHeaders.Get("Authorization"), redacted)
+	assert.Equal(t, list.ResponseHeaders.Get("Set-Cookie"), redacted)
+
+	assert.Equal(t, create.Method, http.MethodPost)
+	assert.Equal(t, create.Url, server.URL+testManagementZoneApi.GetUrlFromEnvironmentUrl(""))
+	assert.Equal(t, create.RequestBody, `{"name": "zone"}`)
+	assert.Equal(t, create.StatusCode, http.StatusBadRequest)
+	assert.Equal(t, create.ResponseBody, `{"error": {"message": "invalid token <redacted>"}}`)
+	assert.Assert(t, !create.Started.Before(list.Started))
+}
+
+func TestReplayRecordsNoSecretsOfPayloads(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"values": []}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	replay := NewReplay()
+	replay.Redact("rendered-secret", "abc")
+	client, err := NewDynatraceClientWithOptions(server.URL, replayToken, ClientOptions{Replay: replay})
+	assert.NilError(t, err)
+
+	notifications := api.NewApi("notification", "/api/config/v1/notifications")
+	_, err = client.UpsertByName(notifications, "hook", []byte(`{"name": "hook", "url": "https://hook.example.com/?key=abc", "password": "webhook-password", "active": true}`))
+	assert.ErrorContains(t, err, "400")
+
+	_, err = client.UpsertByName(testManagementZoneApi, "zone", []byte(`{"name": "zone", "rules": [{"apiToken": "zone-token"}], "description": "rendered-secret"}`))
+	assert.ErrorContains(t, err, "400")
+
+	content, err := json.Marshal(replay.Bundle())
+	assert.NilError(t, err)
+	for _, secret := range []string{"webhook-password", "hook.example.com", "zone-token", "rendered-secret"} {
+		assert.Assert(t, !strings.Contains(string(content), secret), string(content))
+	}
+
+	exchanges := replay.Bundle().Exchanges
+	assert.Equal(t, len(exchanges), 4)
+	assert.Equal(t, exchanges[1].RequestBody, `{"active":true,"name":"<redacted>","password":"<redacted>","url":"<redacted>"}`)
+	assert.Equal(t, exchanges[3].RequestBody, `{"description":"<redacted>","name":"zone","rules":[{"apiToken":"<redacted>"}]}`)
+}
+
+func TestReplayRecordsFailedRequests(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+
+	replay := NewReplay()
+	client, err := NewDynatraceClientWithOptions(server.URL, replayToken, ClientOptions{Replay: replay})
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.Assert(t, err != nil)
+
+	exchanges := replay.Bundle().Exchanges
+	assert.Equal(t, len(exchanges), 1)
+	assert.Equal(t, exchanges[0].StatusCode, 0)
+	assert.Assert(t, exchanges[0].Error != "")
+}
+
+func TestNilReplayHasEmptyBundle(t *testing.T) {
+
+	var replay *Replay
+	replay.SetVersion("https://example.com", "1.232")
+
+	bundle := replay.Bundle()
+	assert.Equal(t, len(bundle.Exchanges), 0)
+	assert.Equal(t, len(bundle.Versions), 0)
+}
@@ -21,40 +21,69 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"runtime"
 
 	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/version"
 )
 
 type Response struct {
-	StatusCode int
-	Body       []byte
+	StatusCode  int
+	Body        []byte
+	RequestId   string
+	ContentType string
 }
 
 func Get(url string, apiToken string) Response {
-	req := request(http.MethodGet, url, apiToken)
-	return executeRequest(req)
+	return get(&http.Client{}, url, apiToken)
 }
 
 func Delete(url string, apiToken string, id string) {
-	req := request(http.MethodDelete, url+"/"+id, apiToken)
-	executeRequest(req)
+	deleteConfig(&http.Client{}, url, apiToken, id)
 }
 
 func Post(url string, data string, apiToken string) Response {
-	req := requestWithBody(http.MethodPost, url, bytes.NewBuffer([]byte(data)), apiToken)
-	return executeRequest(req)
+	return post(&http.Client{}, url, data, apiToken)
 }
 
 func PostMultiPartFile(url string, data *bytes.Buffer, contentType string, apiToken string) Response {
+	return postMultiPartFile(&http.Client{}, url, data, contentType, apiToken)
+}
+
+func Put(url string, data string, apiToken string) Response {
+	return put(&http.Client{}, url, data, apiToken)
+}
+
+func get(client *http.Client, url string, apiToken string) Response {
+	req := request(http.MethodGet, url, apiToken)
+	return executeRequest(client, req)
+}
+
+func deleteConfig(client *http.Client, url string, apiToken string, id string) Response {
+	req := request(http.MethodDelete, objectUrl(url, id), apiToken)
+	return executeRequest(client, req)
+}
+
+func post(client *http.Client, url string, data string, apiToken string) Response {
+	req := requestWithBody(http.MethodPost, url, bytes.NewBuffer([]byte(data)), apiToken)
+	return executeRequest(client, req)
+}
+
+func postMultiPartFile(client *http.Client, url string, data *bytes.Buffer, contentType string, apiToken string) Response {
 	req := requestWithBody(http.MethodPost, url, data, apiToken)
 	req.Header.Set("Content-type", contentType)
-	return executeRequest(req)
+	return executeRequest(client, req)
 }
 
-func Put(url string, data string, apiToken string) Response {
+func put(client *http.Client, url string, data string, apiToken string) Response {
 	req := requestWithBody(http.MethodPut, url, bytes.NewBuffer([]byte(data)), apiToken)
-	return executeRequest(req)
+	return executeRequest(client, req)
+}
+
+// objectUrl builds the url of the object with the given id. The id is escaped, as some apis use names
+// which may contain slashes, spaces or other special characters as ids (e.g. calculated log metrics)
+func objectUrl(apiUrl string, id string) string {
+	return apiUrl + "/" + url.PathEscape(id)
 }
 
 func request(method string, url string, apiToken string) *http.Request {
@@ -69,8 +98,7 @@ func requestWithBody(method string, url string, body io.Reader, apiToken string)
 	return req
 }
 
-func executeRequest(request *http.Request) Response {
-	client := &http.Client{}
+func executeRequest(client *http.Client, request *http.Request) Response {
 	resp, err := client.Do(request)
 	if err != nil {
 		println("HTTP Request failed with Error: " + err.Error())
@@ -80,5 +108,5 @@ func executeRequest(request *http.Request) Response {
 		err = resp.Body.Close()
 	}()
 	body, err := ioutil.ReadAll(resp.Body)
-	return Response{resp.StatusCode, body}
+	return Response{StatusCode: resp.StatusCode, Body: body, RequestId: resp.Header.Get(requestIdHeader), ContentType: resp.Header.Get("Content-Type")}
 }
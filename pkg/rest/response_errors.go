@@ -0,0 +1,128 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// requestIdHeader is the header in which Dynatrace returns the id of a request, which helps support to trace it
+const requestIdHeader = "x-request-id"
+
+// errorResponse is the payload Dynatrace returns for failed requests
+type errorResponse struct {
+	Error struct {
+		Code                 int                   `json:"code"`
+		Message              string                `json:"message"`
+		ConstraintViolations []constraintViolation `json:"constraintViolations"`
+	} `json:"error"`
+}
+
+type constraintViolation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// ResponseError is returned if Dynatrace answered a request with a failure status code
+type ResponseError struct {
+	StatusCode int
+	message    string
+}
+
+func (e ResponseError) Error() string {
+	return e.message
+}
+
+// IsMaintenance returns whether the error was caused by Dynatrace answering a request with 503 Service Unavailable,
+// as SaaS environments do while they undergo maintenance
+func IsMaintenance(err error) bool {
+
+	var responseErr ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusServiceUnavailable
+}
+
+// IsNotFound returns whether the error was caused by Dynatrace answering a request with 404 Not Found, as environments
+// do for apis and settings schemas they do not offer, e.g. older Managed versions
+func IsNotFound(err error) bool {
+
+	var responseErr ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound
+}
+
+// responseError creates a ResponseError with the given message, followed by the description of the failed response
+func responseError(resp Response, format string, args ...interface{}) error {
+	return ResponseError{
+		StatusCode: resp.StatusCode,
+		message:    fmt.Sprintf(format, args...) + " " + describeFailure(resp),
+	}
+}
+
+// interceptedSnippetLength limits how much of an intercepted response is included in the error
+const interceptedSnippetLength = 200
+
+// checkIntercepted fails for successful responses which are HTML instead of JSON. Proxies and single sign-on
+// gateways sometimes intercept requests and answer them with a login page and status 200, which would otherwise
+// only surface as a confusing json parse error further downstream
+func checkIntercepted(url string, resp Response) error {
+
+	body := strings.TrimSpace(string(resp.Body))
+	if !strings.Contains(strings.ToLower(resp.ContentType), "html") && !strings.HasPrefix(body, "<") {
+		return nil
+	}
+
+	if len(body) > interceptedSnippetLength {
+		body = body[:interceptedSnippetLength] + "..."
+	}
+
+	return fmt.Errorf("request %s returned a non-JSON response (HTTP %d, content type '%s'). The request was likely "+
+		"intercepted by a proxy or single sign-on gateway between monaco and Dynatrace. Response was: %s", url, resp.StatusCode, resp.ContentType, body)
+}
+
+// describeFailure describes a failed response by its status code, request id and the error returned by Dynatrace,
+// including all constraint violations. Responses not following the Dynatrace error format are included as they are
+func describeFailure(resp Response) string {
+
+	var description strings.Builder
+
+	if resp.RequestId != "" {
+		description.WriteString(fmt.Sprintf("(HTTP %d, request id %s)!", resp.StatusCode, resp.RequestId))
+	} else {
+		description.WriteString(fmt.Sprintf("(HTTP %d)!", resp.StatusCode))
+	}
+
+	var parsed errorResponse
+	err := json.Unmarshal(resp.Body, &parsed)
+	if err != nil || parsed.Error.Message == "" {
+		description.WriteString("\n    Response was: " + string(resp.Body))
+		return description.String()
+	}
+
+	description.WriteString("\n    Error: " + parsed.Error.Message)
+	for _, violation := range parsed.Error.ConstraintViolations {
+		if violation.Path != "" {
+			description.WriteString(fmt.Sprintf("\n      - %s: %s", violation.Path, violation.Message))
+		} else {
+			description.WriteString("\n      - " + violation.Message)
+		}
+	}
+
+	return description.String()
+}
@@ -0,0 +1,90 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDescribeFailureListsConstraintViolations(t *testing.T) {
+
+	resp := Response{
+		StatusCode: 400,
+		RequestId:  "abc123",
+		Body: []byte(`{"error": {"code": 400, "message": "Constraints violated.", "constraintViolations": [
+			{"path": "name", "message": "must not be empty", "parameterLocation": "PAYLOAD_BODY"},
+			{"message": "rules are invalid"}]}}`),
+	}
+
+	assert.Equal(t, describeFailure(resp), "(HTTP 400, request id abc123)!\n    Error: Constraints violated.\n      - name: must not be empty\n      - rules are invalid")
+}
+
+func TestDescribeFailureWithUnknownBody(t *testing.T) {
+
+	resp := Response{StatusCode: 502, Body: []byte("Bad Gateway")}
+
+	assert.Equal(t, describeFailure(resp), "(HTTP 502)!\n    Response was: Bad Gateway")
+}
+
+func TestCheckInterceptedAcceptsJson(t *testing.T) {
+
+	resp := Response{StatusCode: 200, ContentType: "application/json; charset=utf-8", Body: []byte(`{"values": []}`)}
+
+	assert.NilError(t, checkIntercepted("https://env", resp))
+}
+
+func TestCheckInterceptedFailsForHtml(t *testing.T) {
+
+	resp := Response{StatusCode: 200, ContentType: "text/html", Body: []byte("<html><body>Please log in</body></html>")}
+
+	err := checkIntercepted("https://env/api/config/v1/dashboards", resp)
+	assert.ErrorContains(t, err, "request https://env/api/config/v1/dashboards returned a non-JSON response (HTTP 200, content type 'text/html')")
+	assert.ErrorContains(t, err, "Response was: <html><body>Please log in</body></html>")
+}
+
+func TestCheckInterceptedDetectsHtmlWithoutContentType(t *testing.T) {
+
+	resp := Response{StatusCode: 200, Body: []byte("\n  <!DOCTYPE html><html></html>")}
+
+	assert.ErrorContains(t, checkIntercepted("https://env", resp), "non-JSON response")
+}
+
+func TestIsMaintenanceDetectsUnavailableEnvironments(t *testing.T) {
+
+	unavailable := responseError(Response{StatusCode: 503, Body: []byte("maintenance")}, "GET request failed")
+
+	assert.Assert(t, IsMaintenance(unavailable))
+	assert.Assert(t, IsMaintenance(fmt.Errorf("%w, responsible config: zone.json", unavailable)))
+	assert.Assert(t, !IsMaintenance(responseError(Response{StatusCode: 500}, "GET request failed")))
+	assert.Assert(t, !IsMaintenance(errors.New("connection refused")))
+}
+
+func TestIsNotFoundDetectsMissingEndpoints(t *testing.T) {
+
+	missing := responseError(Response{StatusCode: 404}, "GET request failed")
+
+	assert.Assert(t, IsNotFound(missing))
+	assert.Assert(t, IsNotFound(fmt.Errorf("%w, responsible config: zone.json", missing)))
+	assert.Assert(t, !IsNotFound(responseError(Response{StatusCode: 503}, "GET request failed")))
+	assert.Assert(t, !IsNotFound(errors.New("connection refused")))
+}
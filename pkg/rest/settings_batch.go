@@ -0,0 +1,146 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// maxSettingsBatchObjects is the maximum number of settings objects created by a single request
+const maxSettingsBatchObjects = 100
+
+// maxSettingsBatchBytes is the maximum size of the body of a request creating settings objects. Larger objects are
+// sent on their own
+const maxSettingsBatchBytes = 1 << 20
+
+// SettingsBatchCreator creates many Settings 2.0 objects with as few requests as the limits of the api allow.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type SettingsBatchCreator interface {
+
+	// CreateSettings creates the objects in batches. The returned results hold the id of each created object, or
+	// the reason it was not created, at the index of the object. A failing batch fails its objects only, the
+	// remaining batches are still sent
+	CreateSettings(objects []SettingsObject) (results []SettingsCreateResult)
+}
+
+// SettingsCreateResult is the result of creating a single settings object of a batch
+type SettingsCreateResult struct {
+	ObjectId string
+	Err      error
+}
+
+func (d *dynatraceClientImpl) CreateSettings(objects []SettingsObject) (results []SettingsCreateResult) {
+
+	results = make([]SettingsCreateResult, len(objects))
+
+	bodies := make([]json.RawMessage, len(objects))
+	for i, object := range objects {
+		body, err := json.Marshal(object)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		bodies[i] = body
+	}
+
+	for _, batch := range settingsBatches(bodies, maxSettingsBatchObjects, maxSettingsBatchBytes) {
+		d.createSettingsBatch(objects, bodies, batch, results)
+	}
+
+	return results
+}
+
+// createSettingsBatch creates the objects at the given indices with a single request and records their results.
+// Batches the server rejects as too large are split in halves
+func (d *dynatraceClientImpl) createSettingsBatch(objects []SettingsObject, bodies []json.RawMessage, batch []int, results []SettingsCreateResult) {
+
+	objectsUrl := d.environmentUrl + settingsPath + "/objects"
+
+	parts := make([]string, len(batch))
+	for i, index := range batch {
+		parts[i] = string(bodies[index])
+	}
+
+	resp := post(d.client, objectsUrl, "["+strings.Join(parts, ",")+"]", d.token)
+
+	if resp.StatusCode == http.StatusRequestEntityTooLarge && len(batch) > 1 {
+		d.createSettingsBatch(objects, bodies, batch[:len(batch)/2], results)
+		d.createSettingsBatch(objects, bodies, batch[len(batch)/2:], results)
+		return
+	}
+
+	// the server reports the result of every object, also if only some of them failed
+	var created []settingsCreateResponse
+	if json.Unmarshal(resp.Body, &created) != nil || len(created) != len(batch) {
+		err := fmt.Errorf("cannot unmarshal response for new settings objects: %s", string(resp.Body))
+		if !success(resp) {
+			err = d.diagnose(responseError(resp, "failed to create %d settings objects", len(batch)))
+		} else if intercepted := checkIntercepted(objectsUrl, resp); intercepted != nil {
+			err = intercepted
+		}
+		for _, index := range batch {
+			results[index].Err = err
+		}
+		return
+	}
+
+	for i, index := range batch {
+		if created[i].Code != http.StatusOK {
+			results[index].Err = fmt.Errorf("failed to create settings object of %s (code %d): %s", objects[index].SchemaId, created[i].Code, string(created[i].Error))
+			continue
+		}
+		results[index].ObjectId = created[i].ObjectId
+	}
+}
+
+// settingsBatches splits the objects with the given bodies into batches of at most maxObjects objects and maxBytes
+// bytes, keeping their order. Objects whose body could not be created are not part of any batch
+func settingsBatches(bodies []json.RawMessage, maxObjects int, maxBytes int) [][]int {
+
+	batches := make([][]int, 0)
+	batch := make([]int, 0)
+	size := 0
+
+	for i, body := range bodies {
+		if body == nil {
+			continue
+		}
+
+		// a batch is enclosed in brackets, its objects are separated by commas
+		if len(batch) > 0 && (len(batch) == maxObjects || size+1+len(body) > maxBytes) {
+			batches = append(batches, batch)
+			batch = make([]int, 0)
+			size = 0
+		}
+
+		if len(batch) == 0 {
+			size = 2 + len(body)
+		} else {
+			size += 1 + len(body)
+		}
+		batch = append(batch, i)
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
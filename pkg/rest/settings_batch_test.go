@@ -0,0 +1,170 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestSettingsBatchesRespectObjectAndByteLimits(t *testing.T) {
+
+	bodies := []json.RawMessage{
+		json.RawMessage(`{"a":1}`),
+		json.RawMessage(`{"b":1}`),
+		nil,
+		json.RawMessage(`{"c":1}`),
+		json.RawMessage(`{"too-large-for-a-batch":1}`),
+		json.RawMessage(`{"d":1}`),
+	}
+
+	assert.DeepEqual(t, settingsBatches(bodies, 2, 100), [][]int{{0, 1}, {3, 4}, {5}})
+	assert.DeepEqual(t, settingsBatches(bodies, 10, 20), [][]int{{0, 1}, {3}, {4}, {5}})
+	assert.DeepEqual(t, settingsBatches(nil, 10, 20), [][]int{})
+}
+
+// newSettingsBatchServer creates settings objects named by their external id. Objects with external id invalid are
+// rejected, batches of more than tooLarge objects are answered with 413 and batches containing broken with 500
+func newSettingsBatchServer(tooLarge int, requests *[]int) *httptest.Server {
+
+	var lock sync.Mutex
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		var objects []SettingsObject
+		body, _ := ioutil.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &objects)
+
+		lock.Lock()
+		*requests = append(*requests, len(objects))
+		lock.Unlock()
+
+		if len(objects) > tooLarge {
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		results := make([]string, len(objects))
+		for i, object := range objects {
+			switch object.ExternalId {
+			case "broken":
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error": {"code": 500, "message": "internal error"}}`))
+				return
+			case "invalid":
+				results[i] = `{"code": 400, "error": {"message": "invalid value"}}`
+			default:
+				results[i] = fmt.Sprintf(`{"code": 200, "objectId": "id-%s"}`, object.ExternalId)
+			}
+		}
+
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte("[" + strings.Join(results, ",") + "]"))
+	}))
+}
+
+func TestCreateSettingsReportsResultsPerObject(t *testing.T) {
+
+	var requests []int
+	server := newSettingsBatchServer(1000, &requests)
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	objects := make([]SettingsObject, 250)
+	for i := range objects {
+		objects[i] = SettingsObject{SchemaId: "builtin:test", Scope: "environment", ExternalId: fmt.Sprint(i), Value: json.RawMessage(`{}`)}
+	}
+	objects[7].ExternalId = "invalid"
+	objects[120].ExternalId = "broken"
+
+	results := client.(SettingsBatchCreator).CreateSettings(objects)
+
+	assert.DeepEqual(t, requests, []int{100, 100, 50})
+	assert.Equal(t, len(results), 250)
+
+	assert.Equal(t, results[0].ObjectId, "id-0")
+	assert.NilError(t, results[0].Err)
+	assert.ErrorContains(t, results[7].Err, "failed to create settings object of builtin:test (code 400)")
+
+	// the batch with the broken object failed as a whole, the others were still created
+	for i := 100; i < 200; i++ {
+		assert.ErrorContains(t, results[i].Err, "failed to create 100 settings objects")
+	}
+	assert.Equal(t, results[249].ObjectId, "id-249")
+}
+
+func TestCreateSettingsSplitsBatchesTooLarge(t *testing.T) {
+
+	var requests []int
+	server := newSettingsBatchServer(2, &requests)
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	objects := make([]SettingsObject, 5)
+	for i := range objects {
+		objects[i] = SettingsObject{SchemaId: "builtin:test", Scope: "environment", ExternalId: fmt.Sprint(i), Value: json.RawMessage(`{}`)}
+	}
+
+	results := client.(SettingsBatchCreator).CreateSettings(objects)
+
+	assert.DeepEqual(t, requests, []int{5, 2, 3, 1, 2})
+	for i, result := range results {
+		assert.NilError(t, result.Err)
+		assert.Equal(t, result.ObjectId, fmt.Sprintf("id-%d", i))
+	}
+}
+
+func TestValidateSettingsValidatesInBatches(t *testing.T) {
+
+	var requests []int
+	server := newSettingsBatchServer(60, &requests)
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	objects := make([]SettingsObject, 150)
+	for i := range objects {
+		objects[i] = SettingsObject{SchemaId: "builtin:test", Scope: "environment", ExternalId: fmt.Sprint(i), Value: json.RawMessage(`{}`)}
+	}
+	objects[120].ExternalId = "invalid"
+
+	rejected, err := client.(SettingsValidator).ValidateSettings(objects)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, requests, []int{100, 50, 50, 50})
+	for i, reason := range rejected {
+		if i == 120 {
+			assert.ErrorContains(t, reason, "invalid value")
+		} else {
+			assert.NilError(t, reason)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// settingsPath is the path of the Settings 2.0 api, relative to the environment url
+const settingsPath = "/api/v2/settings"
+
+// SettingsObject is a Settings 2.0 object, i.e. the value of a schema for a single scope
+type SettingsObject struct {
+	ObjectId      string          `json:"objectId,omitempty"`
+	SchemaId      string          `json:"schemaId"`
+	SchemaVersion string          `json:"schemaVersion,omitempty"`
+	Scope         string          `json:"scope"`
+	ExternalId    string          `json:"externalId,omitempty"`
+	Value         json.RawMessage `json:"value"`
+}
+
+// SettingsSchema is the part of a Settings 2.0 schema monaco needs to migrate objects between schema versions
+type SettingsSchema struct {
+	SchemaId   string                     `json:"schemaId"`
+	Version    string                     `json:"version"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// SettingsClient provides access to the Settings 2.0 api of a Dynatrace environment.
+// Clients returned by NewDynatraceClient implement it in addition to DynatraceClient
+type SettingsClient interface {
+
+	// ListSettings lists all objects of the given schema
+	ListSettings(schemaId string) (objects []SettingsObject, err error)
+
+	// GetSchema reads the current version of the given schema
+	GetSchema(schemaId string) (schema SettingsSchema, err error)
+
+	// UpsertSettings updates the object with the object id of the given object, or creates it if no id is set.
+	// Returns the id of the object
+	UpsertSettings(object SettingsObject) (objectId string, err error)
+}
+
+// SettingsValidator validates Settings 2.0 objects without storing them. Clients returned by NewDynatraceClient
+// implement it in addition to DynatraceClient
+type SettingsValidator interface {
+
+	// ValidateSettings validates the objects in batches, with as few requests as the limits of the api allow. The
+	// returned errors hold the reason an object was rejected for, at the index of the object, and nil for valid objects
+	ValidateSettings(objects []SettingsObject) (rejected []error, err error)
+}
+
+type settingsListResponse struct {
+	Items       []SettingsObject `json:"items"`
+	NextPageKey string           `json:"nextPageKey"`
+}
+
+type settingsCreateResponse struct {
+	ObjectId string          `json:"objectId"`
+	Code     int             `json:"code"`
+	Error    json.RawMessage `json:"error"`
+}
+
+type settingsValidationError struct {
+	Message              string `json:"message"`
+	ConstraintViolations []struct {
+		Path    string `json:"path"`
+		Message string `json:"message"`
+	} `json:"constraintViolations"`
+}
+
+func (d *dynatraceClientImpl) ListSettings(schemaId string) (objects []SettingsObject, err error) {
+
+	query := url.Values{}
+	query.Set("schemaIds", schemaId)
+	query.Set("fields", "objectId,externalId,schemaVersion,scope,value")
+	query.Set("pageSize", "500")
+
+	for {
+		resp, err := d.get(d.environmentUrl + settingsPath + "/objects?" + query.Encode())
+		if err != nil {
+			return nil, err
+		}
+
+		var page settingsListResponse
+		err = json.Unmarshal(resp.Body, &page)
+		if err != nil {
+			return nil, fmt.Errorf("cannot unmarshal settings of %s: %s", schemaId, err)
+		}
+
+		for _, object := range page.Items {
+			object.SchemaId = schemaId
+			objects = append(objects, object)
+		}
+
+		if page.NextPageKey == "" {
+			return objects, nil
+		}
+
+		// subsequent pages are requested by the page key only
+		query = url.Values{}
+		query.Set("nextPageKey", page.NextPageKey)
+	}
+}
+
+func (d *dynatraceClientImpl) GetSchema(schemaId string) (schema SettingsSchema, err error) {
+
+	resp, err := d.get(objectUrl(d.environmentUrl+settingsPath+"/schemas", schemaId))
+	if err != nil {
+		return schema, err
+	}
+
+	err = json.Unmarshal(resp.Body, &schema)
+	if err != nil {
+		return schema, fmt.Errorf("cannot unmarshal schema %s: %s", schemaId, err)
+	}
+
+	return schema, nil
+}
+
+func (d *dynatraceClientImpl) UpsertSettings(object SettingsObject) (objectId string, err error) {
+
+	objectsUrl := d.environmentUrl + settingsPath + "/objects"
+
+	if object.ObjectId != "" {
+		body, err := json.Marshal(struct {
+			SchemaVersion string          `json:"schemaVersion,omitempty"`
+			Value         json.RawMessage `json:"value"`
+		}{object.SchemaVersion, object.Value})
+		if err != nil {
+			return "", err
+		}
+
+		resp := put(d.client, objectUrl(objectsUrl, object.ObjectId), string(body), d.token)
+		if !success(resp) {
+			return "", d.diagnose(responseError(resp, "failed to update settings object %s of %s", object.ObjectId, object.SchemaId))
+		}
+		if err := checkIntercepted(objectsUrl, resp); err != nil {
+			return "", err
+		}
+		return object.ObjectId, nil
+	}
+
+	body, err := json.Marshal([]SettingsObject{object})
+	if err != nil {
+		return "", err
+	}
+
+	resp := post(d.client, objectsUrl, string(body), d.token)
+	if !success(resp) {
+		return "", d.diagnose(responseError(resp, "failed to create settings object of %s", object.SchemaId))
+	}
+	if err := checkIntercepted(objectsUrl, resp); err != nil {
+		return "", err
+	}
+
+	var created []settingsCreateResponse
+	err = json.Unmarshal(resp.Body, &created)
+	if err != nil || len(created) != 1 {
+		return "", fmt.Errorf("cannot unmarshal response for new settings object of %s: %s", object.SchemaId, string(resp.Body))
+	}
+	if created[0].Code != http.StatusOK {
+		return "", fmt.Errorf("failed to create settings object of %s (code %d): %s", object.SchemaId, created[0].Code, string(created[0].Error))
+	}
+
+	return created[0].ObjectId, nil
+}
+
+func (d *dynatraceClientImpl) ValidateSettings(objects []SettingsObject) (rejected []error, err error) {
+
+	bodies := make([]json.RawMessage, len(objects))
+	for i, object := range objects {
+		bodies[i], err = json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rejected = make([]error, len(objects))
+	for _, batch := range settingsBatches(bodies, maxSettingsBatchObjects, maxSettingsBatchBytes) {
+		err = d.validateSettingsBatch(bodies, batch, rejected)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rejected, nil
+}
+
+// validateSettingsBatch validates the objects at the given indices with a single request and records why they were
+// rejected. Batches the server rejects as too large are split in halves
+func (d *dynatraceClientImpl) validateSettingsBatch(bodies []json.RawMessage, batch []int, rejected []error) error {
+
+	validateUrl := d.environmentUrl + settingsPath + "/objects?validateOnly=true"
+
+	parts := make([]string, len(batch))
+	for i, index := range batch {
+		parts[i] = string(bodies[index])
+	}
+
+	resp := post(d.client, validateUrl, "["+strings.Join(parts, ",")+"]", d.token)
+
+	if resp.StatusCode == http.StatusRequestEntityTooLarge && len(batch) > 1 {
+		err := d.validateSettingsBatch(bodies, batch[:len(batch)/2], rejected)
+		if err != nil {
+			return err
+		}
+		return d.validateSettingsBatch(bodies, batch[len(batch)/2:], rejected)
+	}
+
+	// invalid objects are reported within the response, which has one entry per object, also on HTTP 400
+	var results []settingsCreateResponse
+	if json.Unmarshal(resp.Body, &results) != nil || len(results) != len(batch) {
+		if !success(resp) {
+			return d.diagnose(responseError(resp, "failed to validate %d settings objects", len(batch)))
+		}
+		// all objects are valid, if the server does not report them one by one
+		return nil
+	}
+
+	for i, result := range results {
+		if result.Code != 0 && result.Code != http.StatusOK {
+			rejected[batch[i]] = settingsValidationFailure(result)
+		}
+	}
+	return nil
+}
+
+// settingsValidationFailure describes why an object was rejected, by the violated constraints if the server lists them
+func settingsValidationFailure(result settingsCreateResponse) error {
+
+	var details settingsValidationError
+	if json.Unmarshal(result.Error, &details) != nil {
+		return fmt.Errorf("rejected (code %d): %s", result.Code, string(result.Error))
+	}
+
+	if len(details.ConstraintViolations) == 0 {
+		return fmt.Errorf("rejected (code %d): %s", result.Code, details.Message)
+	}
+
+	violations := make([]string, 0, len(details.ConstraintViolations))
+	for _, violation := range details.ConstraintViolations {
+		if violation.Path == "" {
+			violations = append(violations, violation.Message)
+		} else {
+			violations = append(violations, violation.Path+": "+violation.Message)
+		}
+	}
+	return fmt.Errorf("rejected (code %d): %s", result.Code, strings.Join(violations, "; "))
+}
@@ -0,0 +1,123 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestListSettingsFollowsPages(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("nextPageKey") == "" {
+			assert.Equal(t, r.URL.Query().Get("schemaIds"), "builtin:test")
+			_, _ = w.Write([]byte(`{"items": [{"objectId": "a", "externalId": "x", "scope": "environment", "value": {}}], "nextPageKey": "page2"}`))
+		} else {
+			_, _ = w.Write([]byte(`{"items": [{"objectId": "b", "scope": "HOST-1", "value": {}}]}`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	objects, err := client.(SettingsClient).ListSettings("builtin:test")
+	assert.NilError(t, err)
+
+	assert.Equal(t, 2, len(objects))
+	assert.Equal(t, "a", objects[0].ObjectId)
+	assert.Equal(t, "x", objects[0].ExternalId)
+	assert.Equal(t, "b", objects[1].ObjectId)
+	assert.Equal(t, "builtin:test", objects[1].SchemaId)
+}
+
+func TestUpsertSettingsCreatesAndUpdates(t *testing.T) {
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`[{"code": 200, "objectId": "new-id"}]`))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+	settingsClient := client.(SettingsClient)
+
+	id, err := settingsClient.UpsertSettings(SettingsObject{SchemaId: "builtin:test", Scope: "environment", ExternalId: "x", Value: []byte(`{"enabled":true}`)})
+	assert.NilError(t, err)
+	assert.Equal(t, "new-id", id)
+
+	id, err = settingsClient.UpsertSettings(SettingsObject{ObjectId: "new-id", SchemaId: "builtin:test", SchemaVersion: "1.2", Scope: "environment", Value: []byte(`{"enabled":false}`)})
+	assert.NilError(t, err)
+	assert.Equal(t, "new-id", id)
+
+	assert.DeepEqual(t, requests, []string{
+		`POST /api/v2/settings/objects [{"schemaId":"builtin:test","scope":"environment","externalId":"x","value":{"enabled":true}}]`,
+		`PUT /api/v2/settings/objects/new-id {"schemaVersion":"1.2","value":{"enabled":false}}`,
+	})
+}
+
+func TestValidateSettingsReportsRejectedObjects(t *testing.T) {
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`[{"code": 200}, {"code": 400, "error": {"message": "Validation failed", "constraintViolations": [{"path": "enabled", "message": "must be a boolean"}]}}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	rejected, err := client.(SettingsValidator).ValidateSettings([]SettingsObject{
+		{SchemaId: "builtin:test", Scope: "environment", Value: []byte(`{"enabled":true}`)},
+		{SchemaId: "builtin:test", Scope: "environment", Value: []byte(`{"enabled":"yes"}`)},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, requests, []string{"POST /api/v2/settings/objects?validateOnly=true"})
+
+	assert.Equal(t, len(rejected), 2)
+	assert.NilError(t, rejected[0])
+	assert.ErrorContains(t, rejected[1], "enabled: must be a boolean")
+}
+
+func TestValidateSettingsFailsOnRejectedRequest(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": 403, "message": "Token is missing required scope"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", ClientOptions{})
+	assert.NilError(t, err)
+
+	_, err = client.(SettingsValidator).ValidateSettings([]SettingsObject{{SchemaId: "builtin:test", Scope: "environment", Value: []byte(`{}`)}})
+	assert.ErrorContains(t, err, "failed to validate 1 settings objects")
+}
@@ -0,0 +1,338 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultPolicyRetries is the number of times a request is retried if a rule retries its status code without
+// defining the number of retries
+const defaultPolicyRetries = 3
+
+// defaultPolicyDelay is the time waited before retrying a request if a rule does not define it
+const defaultPolicyDelay = time.Second
+
+// policySleep waits before retrying a request, it is replaced in tests
+var policySleep = time.Sleep
+
+// operations maps the operations rules refer to to the methods of their requests
+var operations = map[string]string{
+	"read":   http.MethodGet,
+	"create": http.MethodPost,
+	"update": http.MethodPut,
+	"delete": http.MethodDelete,
+}
+
+// StatusPolicy classifies the status codes of failed responses per api and operation, as environments exhibit api
+// specific quirks monaco can not hardcode for everyone. The first rule applying to a request and listing the status
+// code of its response decides whether the request is retried, treated as success or is fatal, i.e. not retried
+// even if monaco retries the status code by default (e.g. 409 or 429). A nil policy classifies nothing
+type StatusPolicy struct {
+	rules    []statusRule
+	apiPaths map[string]string
+}
+
+type statusRule struct {
+	apis       []string
+	operations []string
+	retry      []int
+	success    []int
+	fatal      []int
+	retries    int
+	delay      time.Duration
+}
+
+type statusPolicyFile struct {
+	Rules []struct {
+		Apis       []string `yaml:"apis"`
+		Operations []string `yaml:"operations"`
+		Retry      []int    `yaml:"retry"`
+		Success    []int    `yaml:"success"`
+		Fatal      []int    `yaml:"fatal"`
+		Retries    *int     `yaml:"retries"`
+		Delay      string   `yaml:"delay"`
+	} `yaml:"rules"`
+}
+
+// statusClass is the classification of a status code by a policy
+type statusClass int
+
+const (
+	unclassified statusClass = iota
+	retried
+	successful
+	fatal
+)
+
+// LoadStatusPolicy reads the rules of a status policy from the given yaml file.
+// If file is empty, nil is returned
+func LoadStatusPolicy(file string, fileReader util.FileReader) (*StatusPolicy, error) {
+
+	if file == "" {
+		return nil, nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("status policy %s could not be read: %s", file, err)
+	}
+
+	var parsed statusPolicyFile
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("status policy %s is invalid: %s", file, err)
+	}
+
+	policy := &StatusPolicy{apiPaths: make(map[string]string)}
+	for id, a := range api.NewApis() {
+		policy.apiPaths[id] = a.GetUrlFromEnvironmentUrl("")
+	}
+
+	for i, definition := range parsed.Rules {
+
+		rule := statusRule{
+			apis:       definition.Apis,
+			operations: definition.Operations,
+			retry:      definition.Retry,
+			success:    definition.Success,
+			fatal:      definition.Fatal,
+			retries:    defaultPolicyRetries,
+			delay:      defaultPolicyDelay,
+		}
+
+		for _, id := range rule.apis {
+			if !api.IsApi(id) {
+				return nil, fmt.Errorf("rule %d of status policy %s refers to unknown api %s", i+1, file, id)
+			}
+		}
+		for _, operation := range rule.operations {
+			if _, found := operations[operation]; !found {
+				return nil, fmt.Errorf("rule %d of status policy %s has unknown operation %s, supported are read, create, update and delete", i+1, file, operation)
+			}
+		}
+		// a failed create, read or update treated as success would be an object monaco believes to be deployed
+		if len(rule.success) > 0 && !onlyDeletes(rule.operations) {
+			return nil, fmt.Errorf("rule %d of status policy %s treats status codes as success for operations other than delete, restrict it with operations: [delete]", i+1, file)
+		}
+		if code, found := duplicateStatus(rule.retry, rule.success, rule.fatal); found {
+			return nil, fmt.Errorf("rule %d of status policy %s classifies status %d more than once", i+1, file, code)
+		}
+
+		if definition.Retries != nil {
+			rule.retries = *definition.Retries
+		}
+		if definition.Delay != "" {
+			rule.delay, err = time.ParseDuration(definition.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d of status policy %s has an invalid delay: %s", i+1, file, err)
+			}
+		}
+
+		policy.rules = append(policy.rules, rule)
+	}
+
+	return policy, nil
+}
+
+// onlyDeletes checks if the operations of a rule are restricted to deletions
+func onlyDeletes(operations []string) bool {
+
+	if len(operations) == 0 {
+		return false
+	}
+	for _, operation := range operations {
+		if operation != "delete" {
+			return false
+		}
+	}
+	return true
+}
+
+func duplicateStatus(lists ...[]int) (int, bool) {
+
+	seen := make(map[int]bool)
+	for _, list := range lists {
+		for _, code := range list {
+			if seen[code] {
+				return code, true
+			}
+			seen[code] = true
+		}
+	}
+
+	return 0, false
+}
+
+// classify returns how the status code of a response to the request is classified, and the rule doing so
+func (p *StatusPolicy) classify(request *http.Request, status int) (statusClass, statusRule) {
+
+	if p == nil {
+		return unclassified, statusRule{}
+	}
+
+	return p.classifyOperation(p.apiOf(request.URL.Path), request.Method, status)
+}
+
+func (p *StatusPolicy) classifyOperation(apiId string, method string, status int) (statusClass, statusRule) {
+
+	if p == nil {
+		return unclassified, statusRule{}
+	}
+
+	for _, rule := range p.rules {
+
+		if !rule.appliesTo(apiId, method) {
+			continue
+		}
+
+		switch {
+		case containsStatus(rule.retry, status):
+			return retried, rule
+		case containsStatus(rule.success, status):
+			return successful, rule
+		case containsStatus(rule.fatal, status):
+			return fatal, rule
+		}
+	}
+
+	return unclassified, statusRule{}
+}
+
+// isFatal checks if the status code of a response to the operation on the api must not be retried
+func (p *StatusPolicy) isFatal(apiId string, method string, status int) bool {
+	class, _ := p.classifyOperation(apiId, method, status)
+	return class == fatal
+}
+
+// apiOf returns the id of the api the path belongs to, or an empty string for paths of no config api
+func (p *StatusPolicy) apiOf(path string) string {
+
+	id := ""
+	for apiId, apiPath := range p.apiPaths {
+		if strings.Contains(path, apiPath) && len(apiPath) > len(p.apiPaths[id]) {
+			id = apiId
+		}
+	}
+
+	return id
+}
+
+func (r statusRule) appliesTo(apiId string, method string) bool {
+
+	if len(r.apis) > 0 && !containsApi(r.apis, apiId) {
+		return false
+	}
+
+	if len(r.operations) == 0 {
+		return true
+	}
+	for _, operation := range r.operations {
+		if operations[operation] == method {
+			return true
+		}
+	}
+	return false
+}
+
+func containsApi(apis []string, apiId string) bool {
+	for _, a := range apis {
+		if a == apiId {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(codes []int, status int) bool {
+	for _, code := range codes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
+
+// policyTransport applies a status policy to the responses of its base transport
+type policyTransport struct {
+	base   http.RoundTripper
+	policy *StatusPolicy
+}
+
+func (t *policyTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	for attempt := 1; ; attempt++ {
+
+		resp, err := t.base.RoundTrip(request)
+		if err != nil {
+			return resp, err
+		}
+
+		class, rule := t.policy.classify(request, resp.StatusCode)
+
+		switch class {
+		case successful:
+			util.Log.Debug("\t\t%s request to %s returned %d, which the status policy treats as success", request.Method, request.URL.Path, resp.StatusCode)
+			return asSuccess(request, resp), nil
+		case retried:
+			if attempt > rule.retries || (request.Body != nil && request.GetBody == nil) {
+				return resp, nil
+			}
+		default:
+			return resp, nil
+		}
+
+		resp.Body.Close()
+
+		util.Log.Debug("\t\t%s request to %s returned %d, retrying in %s as defined by the status policy (%d/%d)", request.Method, request.URL.Path, resp.StatusCode, rule.delay, attempt, rule.retries)
+		policySleep(rule.delay)
+
+		request, err = rewind(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// asSuccess replaces the response to a deletion by an empty successful one
+func asSuccess(request *http.Request, resp *http.Response) *http.Response {
+
+	resp.Body.Close()
+
+	status := http.StatusNoContent
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         resp.Proto,
+		ProtoMajor:    resp.ProtoMajor,
+		ProtoMinor:    resp.ProtoMinor,
+		Header:        http.Header{requestIdHeader: resp.Header.Values(requestIdHeader)},
+		Body:          ioutil.NopCloser(bytes.NewReader(nil)),
+		ContentLength: 0,
+		Request:       request,
+	}
+}
@@ -0,0 +1,184 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+const testStatusPolicy = `
+rules:
+  - apis: [dashboard]
+    operations: [delete]
+    success: [404]
+  - operations: [create, update]
+    retry: [502]
+    retries: 2
+    delay: 5s
+  - apis: [management-zone]
+    fatal: [409, 429]
+`
+
+func loadTestStatusPolicy(t *testing.T, content string) (*StatusPolicy, error) {
+
+	file := filepath.Join(t.TempDir(), "status-policy.yaml")
+	assert.NilError(t, ioutil.WriteFile(file, []byte(content), 0664))
+
+	return LoadStatusPolicy(file, util.NewFileReader())
+}
+
+func withoutPolicySleep(t *testing.T) *[]time.Duration {
+
+	var waits []time.Duration
+	original := policySleep
+	policySleep = func(wait time.Duration) { waits = append(waits, wait) }
+	t.Cleanup(func() { policySleep = original })
+
+	return &waits
+}
+
+// newTestPolicyClient creates a client for a server answering requests with the given status codes in turn,
+// and records the requests
+func newTestPolicyClient(t *testing.T, policy *StatusPolicy, statusCodes ...int) (*dynatraceClientImpl, *[]string) {
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		status := statusCodes[len(statusCodes)-1]
+		if len(requests) <= len(statusCodes) {
+			status = statusCodes[len(requests)-1]
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"id": "42", "name": "zone"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	options := DefaultClientOptions()
+	options.StatusPolicy = policy
+
+	client, err := NewDynatraceClientWithOptions(server.URL, "token", options)
+	assert.NilError(t, err)
+
+	return client.(*dynatraceClientImpl), &requests
+}
+
+func TestStatusPolicyTreatsStatusAsSuccess(t *testing.T) {
+
+	policy, err := loadTestStatusPolicy(t, testStatusPolicy)
+	assert.NilError(t, err)
+
+	client, requests := newTestPolicyClient(t, policy, 404)
+
+	assert.NilError(t, client.DeleteById(api.NewApis()["dashboard"], "abc"))
+	assert.Assert(t, client.DeleteById(api.NewApis()["alerting-profile"], "abc") != nil)
+	assert.Equal(t, len(*requests), 2)
+}
+
+func TestStatusPolicyRetriesStatus(t *testing.T) {
+
+	waits := withoutPolicySleep(t)
+
+	policy, err := loadTestStatusPolicy(t, testStatusPolicy)
+	assert.NilError(t, err)
+
+	client, requests := newTestPolicyClient(t, policy, 502, 502, 201)
+
+	resp := post(client.client, client.environmentUrl+"/api/config/v1/alertingProfiles", `{"name": "profile"}`, "token")
+	assert.Equal(t, resp.StatusCode, 201)
+	assert.Equal(t, len(*requests), 3)
+	assert.DeepEqual(t, *waits, []time.Duration{5 * time.Second, 5 * time.Second})
+}
+
+func TestStatusPolicyGivesUpAfterRetries(t *testing.T) {
+
+	withoutPolicySleep(t)
+
+	policy, err := loadTestStatusPolicy(t, testStatusPolicy)
+	assert.NilError(t, err)
+
+	client, requests := newTestPolicyClient(t, policy, 502)
+
+	resp := put(client.client, client.environmentUrl+"/api/config/v1/alertingProfiles/1", `{"name": "profile"}`, "token")
+	assert.Equal(t, resp.StatusCode, 502)
+	assert.Equal(t, len(*requests), 3)
+}
+
+func TestStatusPolicyDisablesBuiltInRetriesOfFatalStatus(t *testing.T) {
+
+	withoutThrottleSleep(t)
+
+	policy, err := loadTestStatusPolicy(t, testStatusPolicy)
+	assert.NilError(t, err)
+
+	client, requests := newTestPolicyClient(t, policy, 429)
+
+	resp := get(client.client, client.environmentUrl+"/api/config/v1/managementZones", "token")
+	assert.Equal(t, resp.StatusCode, 429)
+	assert.Equal(t, len(*requests), 1)
+
+	assert.Equal(t, client.conflictRetries(api.NewApis()["management-zone"]), 0)
+	assert.Equal(t, client.conflictRetries(api.NewApis()["dashboard"]), 3)
+}
+
+func TestStatusPolicyAppliesToApisOfEnvironmentsWithPathPrefix(t *testing.T) {
+
+	policy, err := loadTestStatusPolicy(t, testStatusPolicy)
+	assert.NilError(t, err)
+
+	assert.Equal(t, policy.apiOf("/e/environment-id/api/config/v1/service/customServices/java/1"), "custom-service-java")
+	assert.Equal(t, policy.apiOf("/e/environment-id/api/v2/entities"), "")
+}
+
+func TestLoadStatusPolicyValidatesRules(t *testing.T) {
+
+	_, err := loadTestStatusPolicy(t, "rules:\n  - apis: [dashboards]\n    success: [404]\n")
+	assert.ErrorContains(t, err, "unknown api dashboards")
+
+	_, err = loadTestStatusPolicy(t, "rules:\n  - operations: [patch]\n    success: [404]\n")
+	assert.ErrorContains(t, err, "unknown operation patch")
+
+	_, err = loadTestStatusPolicy(t, "rules:\n  - success: [404]\n")
+	assert.ErrorContains(t, err, "treats status codes as success for operations other than delete")
+
+	_, err = loadTestStatusPolicy(t, "rules:\n  - operations: [delete, update]\n    success: [404]\n")
+	assert.ErrorContains(t, err, "treats status codes as success for operations other than delete")
+
+	_, err = loadTestStatusPolicy(t, "rules:\n  - retry: [500]\n    fatal: [500]\n")
+	assert.ErrorContains(t, err, "classifies status 500 more than once")
+
+	_, err = loadTestStatusPolicy(t, "rules:\n  - retry: [500]\n    delay: soon\n")
+	assert.ErrorContains(t, err, "invalid delay")
+}
+
+func TestLoadStatusPolicyOfNoFileIsNil(t *testing.T) {
+
+	policy, err := LoadStatusPolicy("", util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Assert(t, policy == nil)
+}
@@ -0,0 +1,178 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// maxThrottleRetries is the number of times a request is retried after the server throttled it (HTTP 429)
+const maxThrottleRetries = 5
+
+// maxThrottleWait caps the time waited before retrying a throttled request, even if the server asks for longer
+const maxThrottleWait = time.Minute
+
+// throttleSleep waits before retrying a throttled request, it is replaced in tests
+var throttleSleep = time.Sleep
+
+// adaptiveLimiter limits the number of concurrent requests to an environment. The limit is halved whenever the
+// server throttles a request and raised by one after as many requests succeeded in a row as the limit allows, so
+// the requests adapt to the actual rate limit of the environment
+type adaptiveLimiter struct {
+	lock      sync.Mutex
+	available *sync.Cond
+
+	limit     int
+	max       int
+	inFlight  int
+	successes int
+}
+
+func newAdaptiveLimiter(max int) *adaptiveLimiter {
+
+	if max < 1 {
+		max = maxConcurrentListRequests
+	}
+
+	limiter := &adaptiveLimiter{limit: max, max: max}
+	limiter.available = sync.NewCond(&limiter.lock)
+
+	return limiter
+}
+
+// acquire blocks until another request can be sent
+func (l *adaptiveLimiter) acquire() {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for l.inFlight >= l.limit {
+		l.available.Wait()
+	}
+	l.inFlight++
+}
+
+// release frees the slot of a finished request and adapts the limit to whether the request was throttled
+func (l *adaptiveLimiter) release(throttled bool) {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.inFlight--
+
+	if throttled {
+		if l.limit > 1 {
+			l.limit /= 2
+			util.Log.Debug("\t\tRequests are throttled, reducing concurrent requests to %d", l.limit)
+		}
+		l.successes = 0
+	} else if l.limit < l.max {
+		l.successes++
+		if l.successes >= l.limit {
+			l.limit++
+			l.successes = 0
+		}
+	}
+
+	l.available.Broadcast()
+}
+
+// currentLimit returns the number of requests currently allowed to be sent concurrently
+func (l *adaptiveLimiter) currentLimit() int {
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	return l.limit
+}
+
+// throttlingTransport sends requests within the limit of its limiter and retries throttled requests after the
+// time the server asks for, unless the status policy classifies throttling as fatal
+type throttlingTransport struct {
+	base    http.RoundTripper
+	limiter *adaptiveLimiter
+	policy  *StatusPolicy
+}
+
+func (t *throttlingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+
+	for attempt := 1; ; attempt++ {
+
+		t.limiter.acquire()
+		resp, err := t.base.RoundTrip(request)
+
+		throttled := err == nil && resp.StatusCode == http.StatusTooManyRequests
+		t.limiter.release(throttled)
+
+		if !throttled || attempt > maxThrottleRetries || (request.Body != nil && request.GetBody == nil) {
+			return resp, err
+		}
+		if class, _ := t.policy.classify(request, resp.StatusCode); class == fatal {
+			return resp, err
+		}
+
+		wait := retryAfter(resp, attempt)
+		resp.Body.Close()
+
+		util.Log.Debug("\t\tRequest to %s was throttled, retrying in %s", request.URL.Path, wait)
+		throttleSleep(wait)
+
+		request, err = rewind(request)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter returns the time to wait before retrying a throttled request, as given by the Retry-After header.
+// Without the header, the time grows with every attempt
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+
+	wait := time.Duration(attempt) * time.Second
+
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	if wait > maxThrottleWait {
+		return maxThrottleWait
+	}
+	return wait
+}
+
+// rewind returns a copy of the request with a fresh body, as the body of the previous attempt was consumed
+func rewind(request *http.Request) (*http.Request, error) {
+
+	if request.GetBody == nil {
+		return request, nil
+	}
+
+	body, err := request.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	rewound := request.Clone(request.Context())
+	rewound.Body = body
+
+	return rewound, nil
+}
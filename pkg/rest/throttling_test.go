@@ -0,0 +1,126 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func withoutThrottleSleep(t *testing.T) *[]time.Duration {
+
+	var waits []time.Duration
+	original := throttleSleep
+	throttleSleep = func(wait time.Duration) { waits = append(waits, wait) }
+	t.Cleanup(func() { throttleSleep = original })
+
+	return &waits
+}
+
+func TestAdaptiveLimiterHalvesLimitWhenThrottledAndRampsUp(t *testing.T) {
+
+	limiter := newAdaptiveLimiter(8)
+
+	limiter.acquire()
+	limiter.release(true)
+	assert.Equal(t, limiter.currentLimit(), 4)
+
+	limiter.acquire()
+	limiter.release(true)
+	limiter.acquire()
+	limiter.release(true)
+	limiter.acquire()
+	limiter.release(true)
+	assert.Equal(t, limiter.currentLimit(), 1, "the limit never drops below one request")
+
+	limiter.acquire()
+	limiter.release(false)
+	assert.Equal(t, limiter.currentLimit(), 2)
+
+	for i := 0; i < 2+3+4+5+6+7; i++ {
+		limiter.acquire()
+		limiter.release(false)
+	}
+	assert.Equal(t, limiter.currentLimit(), 8)
+
+	limiter.acquire()
+	limiter.release(false)
+	assert.Equal(t, limiter.currentLimit(), 8, "the limit never exceeds the maximum")
+}
+
+func TestAdaptiveLimiterDefaultsMaximum(t *testing.T) {
+	assert.Equal(t, newAdaptiveLimiter(0).currentLimit(), maxConcurrentListRequests)
+}
+
+func TestThrottledRequestsAreRetried(t *testing.T) {
+
+	waits := withoutThrottleSleep(t)
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if len(bodies) < 3 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.UpsertById(testManagementZoneApi, "42", "zone", []byte(`{"name": "zone"}`))
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(bodies), 3)
+	assert.Assert(t, bodies[0] != "", "the payload is sent")
+	assert.Equal(t, bodies[1], bodies[0], "retries send the payload again")
+	assert.Equal(t, bodies[2], bodies[0], "retries send the payload again")
+	assert.DeepEqual(t, *waits, []time.Duration{7 * time.Second, 7 * time.Second})
+}
+
+func TestThrottledRequestsFailAfterRetries(t *testing.T) {
+
+	waits := withoutThrottleSleep(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client, err := NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	_, err = client.List(testManagementZoneApi)
+	assert.ErrorContains(t, err, "429")
+
+	assert.Equal(t, len(*waits), maxThrottleRetries)
+	assert.Equal(t, (*waits)[0], time.Second, "without Retry-After the wait grows with every attempt")
+	assert.Equal(t, (*waits)[1], 2*time.Second)
+	assert.Equal(t, client.(*dynatraceClientImpl).client.Transport.(*throttlingTransport).limiter.currentLimit(), 1)
+}
@@ -0,0 +1,146 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rewrite
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+)
+
+// urlRemainder matches the path, query and fragment following the url of an environment
+var urlRemainder = regexp.MustCompile(`^[/#?][^\s"'\\)\]<>]*`)
+
+// idToken matches the parts of a url which may be object ids, e.g. the id of a dashboard in #dashboard;id=...
+var idToken = regexp.MustCompile(`[^;=&/#?,]+`)
+
+// Rewriter rewrites the urls of other environments embedded in payloads, e.g. links in markdown tiles of dashboards,
+// to the url of the environment a payload is deployed to. Ids of objects contained in those urls are replaced by the ids of the same objects in the target environment,
+// as recorded in the state
+type Rewriter struct {
+	environments []environment.Environment
+	state        *state.State
+}
+
+// NewRewriter creates a Rewriter for urls of the given environments. deployState may be nil, in which case
+// only the urls but not the ids they contain are rewritten
+func NewRewriter(environments map[string]environment.Environment, deployState *state.State) *Rewriter {
+
+	sorted := make([]environment.Environment, 0, len(environments))
+	for _, environment := range environments {
+		sorted = append(sorted, environment)
+	}
+
+	// longer urls first, so that urls of managed environments (https://host/e/id) win over the url of their cluster
+	sort.Slice(sorted, func(i, j int) bool {
+		first, second := baseUrl(sorted[i]), baseUrl(sorted[j])
+		if len(first) != len(second) {
+			return len(first) > len(second)
+		}
+		return first < second
+	})
+
+	return &Rewriter{
+		environments: sorted,
+		state:        deployState,
+	}
+}
+
+// Rewrite replaces the urls of other environments within payload by the url of target and returns the
+// rewritten payload and the number of rewritten urls
+func (r *Rewriter) Rewrite(payload string, target environment.Environment) (rewritten string, count int) {
+
+	targetUrl := baseUrl(target)
+
+	for _, source := range r.environments {
+
+		sourceUrl := baseUrl(source)
+		if source.GetId() == target.GetId() || sourceUrl == "" || sourceUrl == targetUrl {
+			continue
+		}
+
+		ids := r.mappedIds(source, target)
+
+		var result strings.Builder
+		remaining := payload
+
+		for {
+			index := strings.Index(remaining, sourceUrl)
+			if index < 0 {
+				break
+			}
+
+			end := index + len(sourceUrl)
+			if end < len(remaining) && !isUrlBoundary(remaining[end]) {
+				result.WriteString(remaining[:end])
+				remaining = remaining[end:]
+				continue
+			}
+
+			rest := urlRemainder.FindString(remaining[end:])
+
+			result.WriteString(remaining[:index])
+			result.WriteString(targetUrl)
+			result.WriteString(idToken.ReplaceAllStringFunc(rest, func(token string) string {
+				if id, found := ids[token]; found {
+					return id
+				}
+				return token
+			}))
+
+			remaining = remaining[end+len(rest):]
+			count++
+		}
+
+		result.WriteString(remaining)
+		payload = result.String()
+	}
+
+	return payload, count
+}
+
+// mappedIds maps the ids of objects in the source environment to the ids of the same objects in target
+func (r *Rewriter) mappedIds(source environment.Environment, target environment.Environment) map[string]string {
+
+	ids := make(map[string]string)
+	if r.state == nil {
+		return ids
+	}
+
+	targetIds := r.state.Ids(target.GetId())
+	for key, sourceId := range r.state.Ids(source.GetId()) {
+		if targetId, found := targetIds[key]; found {
+			ids[sourceId] = targetId
+		}
+	}
+
+	return ids
+}
+
+// isUrlBoundary checks if the character following an environment url ends its host or environment path,
+// so that e.g. https://abc.live.dynatrace.com does not match https://abc.live.dynatrace.com.example.com
+func isUrlBoundary(character byte) bool {
+	return !(character >= 'a' && character <= 'z' || character >= 'A' && character <= 'Z' ||
+		character >= '0' && character <= '9' || strings.IndexByte(".-_:", character) >= 0)
+}
+
+func baseUrl(environment environment.Environment) string {
+	return strings.TrimSuffix(environment.GetEnvironmentUrl(), "/")
+}
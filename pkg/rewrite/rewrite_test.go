@@ -0,0 +1,73 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rewrite
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/environment"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/state"
+	"gotest.tools/assert"
+)
+
+var development = environment.NewEnvironment("development", "Dev", "", "https://dev.live.dynatrace.com/", "DEV")
+var production = environment.NewEnvironment("production", "Prod", "", "https://prod.live.dynatrace.com", "PROD")
+var managed = environment.NewEnvironment("managed", "Managed", "", "https://dev.live.dynatrace.com/e/managed", "MANAGED")
+
+func TestRewriteReplacesUrlsAndIdsOfOtherEnvironments(t *testing.T) {
+
+	deployState := state.NewState()
+	deployState.Set("development", "dashboard", "overview", "dev-dashboard-id")
+	deployState.Set("production", "dashboard", "overview", "prod-dashboard-id")
+	deployState.Set("development", "dashboard", "only-in-dev", "other-id")
+
+	rewriter := NewRewriter(map[string]environment.Environment{"development": development, "production": production}, deployState)
+
+	payload := `{"markdown": "[Overview](https://dev.live.dynatrace.com/#dashboard;id=dev-dashboard-id) ` +
+		`[Other](https://dev.live.dynatrace.com/#dashboard;id=other-id) [Home](https://dev.live.dynatrace.com)"}`
+
+	rewritten, count := rewriter.Rewrite(payload, production)
+	assert.Equal(t, count, 3)
+	assert.Equal(t, rewritten, `{"markdown": "[Overview](https://prod.live.dynatrace.com/#dashboard;id=prod-dashboard-id) `+
+		`[Other](https://prod.live.dynatrace.com/#dashboard;id=other-id) [Home](https://prod.live.dynatrace.com)"}`)
+
+	rewritten, count = rewriter.Rewrite(payload, development)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, rewritten, payload)
+}
+
+func TestRewriteKeepsUrlsOfOtherHosts(t *testing.T) {
+
+	rewriter := NewRewriter(map[string]environment.Environment{"development": development}, nil)
+
+	payload := `{"url": "https://dev.live.dynatrace.com.example.com/#dashboard;id=abc", "other": "https://dev.live.dynatrace.com:8443/"}`
+
+	rewritten, count := rewriter.Rewrite(payload, production)
+	assert.Equal(t, count, 0)
+	assert.Equal(t, rewritten, payload)
+}
+
+func TestRewritePrefersUrlsOfManagedEnvironments(t *testing.T) {
+
+	rewriter := NewRewriter(map[string]environment.Environment{"development": development, "managed": managed}, nil)
+
+	rewritten, count := rewriter.Rewrite(`"https://dev.live.dynatrace.com/e/managed/#dashboards"`, production)
+	assert.Equal(t, count, 1)
+	assert.Equal(t, rewritten, `"https://prod.live.dynatrace.com/#dashboards"`)
+}
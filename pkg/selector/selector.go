@@ -0,0 +1,205 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// criterionNesting criteria contain further criteria instead of a value, e.g. fromRelationships.runsOn(type(HOST))
+var criterionNesting = []string{"not", "fromRelationships.", "toRelationships."}
+
+// Criterion is a single criterion of an entity selector, e.g. tag("team:a") or
+// fromRelationships.runsOn(type(HOST),tag(a)) with its nested criteria
+type Criterion struct {
+	Name     string
+	Value    string
+	Criteria []Criterion
+}
+
+// Validate checks the syntax of an entity selector: criteria have to be separated by commas, names must be
+// identifiers, parentheses and quotes must be balanced and the selector has to define the type of the selected
+// entities with exactly one type criterion, unless entities are selected by their id
+func Validate(selector string) error {
+
+	criteria, err := Parse(selector)
+	if err != nil {
+		return err
+	}
+
+	types, ids := 0, 0
+	for _, criterion := range criteria {
+		switch criterion.Name {
+		case "type":
+			types++
+		case "entityId":
+			ids++
+		}
+	}
+
+	if ids == 0 && types != 1 {
+		return fmt.Errorf("entity selector %s must contain exactly one type criterion, but contains %d", selector, types)
+	}
+
+	return nil
+}
+
+// Parse splits the entity selector into its criteria
+func Parse(selector string) ([]Criterion, error) {
+
+	p := &parser{input: selector}
+
+	criteria, err := p.criteria()
+	if err != nil {
+		return nil, fmt.Errorf("entity selector %s is invalid: %s", selector, err)
+	}
+
+	if p.position < len(p.input) {
+		return nil, fmt.Errorf("entity selector %s is invalid: unexpected ')' at position %d", selector, p.position+1)
+	}
+
+	return criteria, nil
+}
+
+type parser struct {
+	input    string
+	position int
+}
+
+// criteria parses a comma separated list of criteria, which ends at the end of the input or at a closing parenthesis
+func (p *parser) criteria() ([]Criterion, error) {
+
+	criteria := make([]Criterion, 0)
+
+	for {
+		p.skipSpaces()
+
+		criterion, err := p.criterion()
+		if err != nil {
+			return nil, err
+		}
+		criteria = append(criteria, criterion)
+
+		p.skipSpaces()
+		if p.position >= len(p.input) || p.input[p.position] == ')' {
+			return criteria, nil
+		}
+		if p.input[p.position] != ',' {
+			return nil, fmt.Errorf("expected ',' at position %d", p.position+1)
+		}
+		p.position++
+	}
+}
+
+func (p *parser) criterion() (criterion Criterion, err error) {
+
+	start := p.position
+	for p.position < len(p.input) && isNameCharacter(p.input[p.position]) {
+		p.position++
+	}
+	criterion.Name = p.input[start:p.position]
+
+	if !isName(criterion.Name) {
+		return criterion, fmt.Errorf("expected criterion name at position %d", start+1)
+	}
+
+	if p.position >= len(p.input) || p.input[p.position] != '(' {
+		return criterion, fmt.Errorf("expected '(' after %s at position %d", criterion.Name, p.position+1)
+	}
+	p.position++
+
+	if isNesting(criterion.Name) {
+		criterion.Criteria, err = p.criteria()
+	} else {
+		criterion.Value, err = p.value()
+	}
+	if err != nil {
+		return criterion, err
+	}
+
+	if p.position >= len(p.input) {
+		return criterion, fmt.Errorf("missing ')' of %s", criterion.Name)
+	}
+	p.position++
+
+	return criterion, nil
+}
+
+// value reads the value of a criterion until the closing parenthesis. Quoted values may contain any character,
+// ~ escapes the next character within quotes
+func (p *parser) value() (string, error) {
+
+	start := p.position
+	quoted := false
+
+	for ; p.position < len(p.input); p.position++ {
+
+		c := p.input[p.position]
+
+		switch {
+		case quoted && c == '~':
+			p.position++
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == '(':
+			return "", fmt.Errorf("unexpected '(' at position %d, values containing parentheses have to be quoted", p.position+1)
+		case !quoted && c == ')':
+			return strings.TrimSpace(p.input[start:p.position]), nil
+		}
+	}
+
+	if quoted {
+		return "", fmt.Errorf("missing closing quote of value at position %d", start+1)
+	}
+	return "", fmt.Errorf("missing ')' of value at position %d", start+1)
+}
+
+func (p *parser) skipSpaces() {
+	for p.position < len(p.input) && p.input[p.position] == ' ' {
+		p.position++
+	}
+}
+
+func isNesting(name string) bool {
+	for _, nesting := range criterionNesting {
+		if name == nesting || (strings.HasSuffix(nesting, ".") && strings.HasPrefix(name, nesting)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isName checks that the name consists of identifiers separated by dots, e.g. entityName.startsWith
+func isName(name string) bool {
+
+	if name == "" {
+		return false
+	}
+
+	for _, part := range strings.Split(name, ".") {
+		if part == "" || (part[0] >= '0' && part[0] <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isNameCharacter(c byte) bool {
+	return c == '.' || c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,79 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package selector
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestValidateAcceptsValidSelectors(t *testing.T) {
+
+	selectors := []string{
+		`type(HOST)`,
+		`type(SERVICE),tag("team:payments")`,
+		`type(PROCESS_GROUP), fromRelationships.runsOn(type(HOST),tag(linux))`,
+		`type(HOST),not(tag("stage:dev"))`,
+		`entityId("HOST-1234","HOST-5678")`,
+		`type(SERVICE),entityName.startsWith("payment (eu)")`,
+		`type(HOST),tag("key:value~)")`,
+	}
+
+	for _, selector := range selectors {
+		assert.NilError(t, Validate(selector), selector)
+	}
+}
+
+func TestValidateRejectsInvalidSelectors(t *testing.T) {
+
+	tests := []struct {
+		selector string
+		err      string
+	}{
+		{``, "expected criterion name at position 1"},
+		{`tag(a)`, "must contain exactly one type criterion, but contains 0"},
+		{`type(HOST),type(SERVICE)`, "must contain exactly one type criterion, but contains 2"},
+		{`type(HOST`, "missing ')' of value"},
+		{`type(HOST))`, "unexpected ')' at position 11"},
+		{`type(HOST) tag(a)`, "expected ',' at position 12"},
+		{`type(HOST),tag("a)`, "missing closing quote"},
+		{`type(HOST),tag(a(b))`, "values containing parentheses have to be quoted"},
+		{`type(HOST),fromRelationships.runsOn()`, "expected criterion name"},
+		{`type(HOST),.tag(a)`, "expected criterion name at position 12"},
+	}
+
+	for _, test := range tests {
+		assert.ErrorContains(t, Validate(test.selector), test.err, test.selector)
+	}
+}
+
+func TestParseNestedCriteria(t *testing.T) {
+
+	criteria, err := Parse(`type(PROCESS_GROUP),fromRelationships.runsOn(type(HOST),tag("linux"))`)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, criteria, []Criterion{
+		{Name: "type", Value: "PROCESS_GROUP"},
+		{Name: "fromRelationships.runsOn", Criteria: []Criterion{
+			{Name: "type", Value: "HOST"},
+			{Name: "tag", Value: `"linux"`},
+		}},
+	})
+}
@@ -0,0 +1,161 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// FieldMigration describes a field of a schema which was renamed or removed with a schema version
+type FieldMigration struct {
+	SchemaId string `yaml:"schemaId"`
+
+	// Version is the first schema version without the field
+	Version string `yaml:"version"`
+
+	// Field is the name of the field in earlier schema versions
+	Field string `yaml:"field"`
+
+	// RenamedTo is the name of the field since Version. The field was removed if it is empty
+	RenamedTo string `yaml:"renamedTo"`
+}
+
+type fieldMigrationsFile struct {
+	Migrations []FieldMigration `yaml:"migrations"`
+}
+
+// LoadFieldMigrations reads the migrations applied to settings configs written for older schema versions from a yaml
+// file. Returns no migrations if no file is given
+func LoadFieldMigrations(file string, fileReader util.FileReader) ([]FieldMigration, error) {
+
+	if file == "" {
+		return nil, nil
+	}
+
+	content, err := fileReader.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("settings migrations %s could not be read: %s", file, err)
+	}
+
+	var parsed fieldMigrationsFile
+	err = yaml.UnmarshalStrict(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("settings migrations %s are invalid: %s", file, err)
+	}
+
+	for i, migration := range parsed.Migrations {
+		if migration.SchemaId == "" || migration.Version == "" || migration.Field == "" {
+			return nil, fmt.Errorf("migration %d of %s needs a schemaId, version and field", i+1, file)
+		}
+	}
+
+	return parsed.Migrations, nil
+}
+
+// MigrationResult describes the changes applied to a settings value
+type MigrationResult struct {
+
+	// Value is the migrated value
+	Value json.RawMessage
+
+	// Applied lists the applied migrations in a human readable form
+	Applied []string
+
+	// Unmigratable lists the fields of the value, which are not part of the current schema
+	Unmigratable []string
+}
+
+// Migrate applies all migrations of the schema between the version the value was written for and the current
+// version of the schema, and reports fields which are still unknown to the schema afterwards
+func Migrate(value json.RawMessage, version string, schema rest.SettingsSchema, migrations []FieldMigration) (result MigrationResult, err error) {
+
+	var fields map[string]interface{}
+	err = json.Unmarshal(value, &fields)
+	if err != nil {
+		return result, fmt.Errorf("value of %s is no json object: %s", schema.SchemaId, err)
+	}
+
+	applicable := make([]FieldMigration, 0)
+	for _, migration := range migrations {
+		if migration.SchemaId == schema.SchemaId &&
+			CompareVersions(version, migration.Version) < 0 &&
+			CompareVersions(migration.Version, schema.Version) <= 0 {
+			applicable = append(applicable, migration)
+		}
+	}
+	sort.SliceStable(applicable, func(i, j int) bool {
+		return CompareVersions(applicable[i].Version, applicable[j].Version) < 0
+	})
+
+	for _, migration := range applicable {
+		fieldValue, found := fields[migration.Field]
+		if !found {
+			continue
+		}
+
+		delete(fields, migration.Field)
+		if migration.RenamedTo == "" {
+			result.Applied = append(result.Applied, fmt.Sprintf("removed %s (%s)", migration.Field, migration.Version))
+		} else {
+			fields[migration.RenamedTo] = fieldValue
+			result.Applied = append(result.Applied, fmt.Sprintf("renamed %s to %s (%s)", migration.Field, migration.RenamedTo, migration.Version))
+		}
+	}
+
+	for field := range fields {
+		if _, known := schema.Properties[field]; !known {
+			result.Unmigratable = append(result.Unmigratable, field)
+		}
+	}
+	sort.Strings(result.Unmigratable)
+
+	result.Value, err = json.Marshal(fields)
+	return result, err
+}
+
+// CompareVersions compares two dot separated schema versions (e.g. 1.4.2) numerically.
+// Returns a negative number if a is older than b, 0 if both are equal and a positive number otherwise
+func CompareVersions(a string, b string) int {
+
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		diff := versionPart(aParts, i) - versionPart(bParts, i)
+		if diff != 0 {
+			return diff
+		}
+	}
+
+	return 0
+}
+
+func versionPart(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	number, _ := strconv.Atoi(parts[i])
+	return number
+}
@@ -0,0 +1,120 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settings
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+var testSchema = rest.SettingsSchema{
+	SchemaId: "builtin:test",
+	Version:  "1.10",
+	Properties: map[string]json.RawMessage{
+		"enabled":   json.RawMessage(`{}`),
+		"threshold": json.RawMessage(`{}`),
+	},
+}
+
+var testMigrations = []FieldMigration{
+	{SchemaId: "builtin:test", Version: "1.2", Field: "limit", RenamedTo: "threshold"},
+	{SchemaId: "builtin:test", Version: "1.9", Field: "legacyMode"},
+	{SchemaId: "builtin:other", Version: "1.2", Field: "enabled"},
+	{SchemaId: "builtin:test", Version: "2.0", Field: "enabled"},
+}
+
+func TestMigrateAppliesMigrationsBetweenVersions(t *testing.T) {
+
+	result, err := Migrate(json.RawMessage(`{"enabled": true, "limit": 5, "legacyMode": true}`), "1.1", testSchema, testMigrations)
+	assert.NilError(t, err)
+
+	assert.Equal(t, string(result.Value), `{"enabled":true,"threshold":5}`)
+	assert.DeepEqual(t, result.Applied, []string{"renamed limit to threshold (1.2)", "removed legacyMode (1.9)"})
+	assert.Equal(t, 0, len(result.Unmigratable))
+}
+
+func TestMigrateSkipsMigrationsOfOlderVersions(t *testing.T) {
+
+	result, err := Migrate(json.RawMessage(`{"enabled": true, "limit": 5}`), "1.5", testSchema, testMigrations)
+	assert.NilError(t, err)
+
+	assert.Equal(t, 0, len(result.Applied))
+	assert.DeepEqual(t, result.Unmigratable, []string{"limit"})
+}
+
+func writeTestMigrations(t *testing.T, content string) string {
+
+	file, err := ioutil.TempFile("", "monaco-migrations-*.yaml")
+	assert.NilError(t, err)
+	defer file.Close()
+
+	_, err = file.WriteString(content)
+	assert.NilError(t, err)
+
+	return file.Name()
+}
+
+func TestLoadFieldMigrations(t *testing.T) {
+
+	file := writeTestMigrations(t, `
+migrations:
+  - schemaId: builtin:test
+    version: "1.2"
+    field: limit
+    renamedTo: threshold
+  - schemaId: builtin:test
+    version: "1.9"
+    field: legacyMode
+`)
+	defer os.Remove(file)
+
+	migrations, err := LoadFieldMigrations(file, util.NewFileReader())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, migrations, testMigrations[:2])
+
+	migrations, err = LoadFieldMigrations("", util.NewFileReader())
+	assert.NilError(t, err)
+	assert.Equal(t, 0, len(migrations))
+}
+
+func TestLoadFieldMigrationsFailsOnIncompleteMigrations(t *testing.T) {
+
+	file := writeTestMigrations(t, `
+migrations:
+  - schemaId: builtin:test
+    field: limit
+`)
+	defer os.Remove(file)
+
+	_, err := LoadFieldMigrations(file, util.NewFileReader())
+	assert.ErrorContains(t, err, "migration 1 of "+file+" needs a schemaId, version and field")
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Assert(t, CompareVersions("1.9", "1.10") < 0)
+	assert.Assert(t, CompareVersions("1.10", "1.9") > 0)
+	assert.Equal(t, CompareVersions("1.2", "1.2.0"), 0)
+	assert.Assert(t, CompareVersions("1.2", "1.2.1") < 0)
+}
@@ -0,0 +1,497 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Successor is the Settings 2.0 schema which succeeds a classic config api
+type Successor struct {
+	ApiId    string
+	SchemaId string
+
+	// namePath is the path of the name of the object in values of the schema
+	namePath []string
+
+	// convert converts a classic payload to a value of the schema and lists the fields it could not convert
+	convert func(classic map[string]interface{}) (value map[string]interface{}, unconvertible []string, err error)
+}
+
+// ConversionResult is a classic payload converted to a value of the successor schema
+type ConversionResult struct {
+
+	// Value is the value of the settings object
+	Value json.RawMessage
+
+	// Unconvertible lists the fields of the classic payload which have no equivalent in the schema
+	Unconvertible []string
+}
+
+// Successors are the Settings 2.0 schemas succeeding classic config apis, by the id of the classic api
+var Successors = map[string]Successor{
+	"maintenance-window": {ApiId: "maintenance-window", SchemaId: "builtin:alerting.maintenance-window", namePath: []string{"generalProperties", "name"}, convert: convertMaintenanceWindow},
+	"alerting-profile":   {ApiId: "alerting-profile", SchemaId: "builtin:alerting.profile", namePath: []string{"name"}, convert: convertAlertingProfile},
+}
+
+// classicOnlyFields are fields of classic payloads which the server manages, they are dropped by conversions
+var classicOnlyFields = []string{"id", "metadata"}
+
+// SuccessorOf returns the schema succeeding the classic api, if there is one
+func SuccessorOf(apiId string) (Successor, bool) {
+	successor, found := Successors[apiId]
+	return successor, found
+}
+
+// NameOf returns the name of the object with the given value of the schema, or an empty string if it has none
+func (s Successor) NameOf(value json.RawMessage) string {
+
+	var content interface{}
+	if err := json.Unmarshal(value, &content); err != nil {
+		return ""
+	}
+
+	for _, field := range s.namePath {
+		object, ok := content.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		content = object[field]
+	}
+
+	name, _ := content.(string)
+	return name
+}
+
+// Convert converts the payload of the classic api to a value of the successor schema
+func (s Successor) Convert(payload []byte) (result ConversionResult, err error) {
+
+	// numbers are kept as they are, ids of management zones exceed the precision of floats
+	var classic map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	err = decoder.Decode(&classic)
+	if err != nil {
+		return result, fmt.Errorf("payload of %s is no json object: %s", s.ApiId, err)
+	}
+
+	for _, field := range classicOnlyFields {
+		delete(classic, field)
+	}
+
+	value, unconvertible, err := s.convert(classic)
+	if err != nil {
+		return result, fmt.Errorf("payload of %s can not be converted to %s: %s", s.ApiId, s.SchemaId, err)
+	}
+
+	result.Value, err = json.Marshal(value)
+	if err != nil {
+		return result, err
+	}
+
+	sort.Strings(unconvertible)
+	result.Unconvertible = unconvertible
+	return result, nil
+}
+
+// classicFields tracks which fields of a classic payload were converted, the remaining ones are unconvertible
+type classicFields struct {
+	fields    map[string]interface{}
+	prefix    string
+	converted map[string]bool
+}
+
+func newClassicFields(fields map[string]interface{}, prefix string) *classicFields {
+	return &classicFields{fields: fields, prefix: prefix, converted: make(map[string]bool)}
+}
+
+// take returns the field and marks it converted
+func (c *classicFields) take(name string) (interface{}, bool) {
+	c.converted[name] = true
+	value, found := c.fields[name]
+	return value, found && value != nil
+}
+
+// remaining returns the fields which were not converted, together with their prefix
+func (c *classicFields) remaining() []string {
+
+	remaining := make([]string, 0)
+	for name := range c.fields {
+		if !c.converted[name] {
+			remaining = append(remaining, c.prefix+name)
+		}
+	}
+	return remaining
+}
+
+func convertMaintenanceWindow(classic map[string]interface{}) (map[string]interface{}, []string, error) {
+
+	fields := newClassicFields(classic, "")
+
+	general := map[string]interface{}{"disableSyntheticMonitorExecution": false}
+	if name, found := fields.take("name"); found {
+		general["name"] = name
+	}
+	if description, found := fields.take("description"); found && description != "" {
+		general["description"] = description
+	}
+	if maintenanceType, found := fields.take("type"); found {
+		general["maintenanceType"] = maintenanceType
+	}
+	if suppression, found := fields.take("suppression"); found {
+		general["suppression"] = suppression
+	}
+	if suppressSynthetics, found := fields.take("suppressSyntheticMonitorsExecution"); found {
+		general["disableSyntheticMonitorExecution"] = suppressSynthetics
+	}
+
+	filters := make([]interface{}, 0)
+	unconvertible := make([]string, 0)
+	if scope, found := fields.take("scope"); found {
+		scopeFilters, unconvertibleScope, err := convertMaintenanceScope(scope)
+		if err != nil {
+			return nil, nil, err
+		}
+		filters = scopeFilters
+		unconvertible = append(unconvertible, unconvertibleScope...)
+	}
+
+	schedule, found := fields.take("schedule")
+	if !found {
+		return nil, nil, fmt.Errorf("no schedule defined")
+	}
+	convertedSchedule, unconvertibleSchedule, err := convertMaintenanceSchedule(schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+	unconvertible = append(unconvertible, unconvertibleSchedule...)
+
+	value := map[string]interface{}{
+		"enabled":           true,
+		"generalProperties": general,
+		"schedule":          convertedSchedule,
+		"filters":           filters,
+	}
+
+	return value, append(unconvertible, fields.remaining()...), nil
+}
+
+// convertMaintenanceScope converts the entities and matches of a classic scope to filters, conditions within a filter
+// all need to match. Matches requiring any of their tags are split into a filter per tag
+func convertMaintenanceScope(scope interface{}) ([]interface{}, []string, error) {
+
+	scopeFields, ok := scope.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("scope is no json object")
+	}
+	fields := newClassicFields(scopeFields, "scope.")
+
+	filters := make([]interface{}, 0)
+
+	if entities, found := fields.take("entities"); found {
+		list, ok := entities.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("scope.entities is no list")
+		}
+		for _, entity := range list {
+			filters = append(filters, map[string]interface{}{"entityId": entity, "entityTags": []string{}, "managementZones": []string{}})
+		}
+	}
+
+	unconvertible := make([]string, 0)
+	if matches, found := fields.take("matches"); found {
+		list, ok := matches.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("scope.matches is no list")
+		}
+		for i, match := range list {
+			matchFields, ok := match.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("scope.matches[%d] is no json object", i)
+			}
+			matchFilters, unconvertibleMatch, err := convertMaintenanceMatch(newClassicFields(matchFields, fmt.Sprintf("scope.matches[%d].", i)))
+			if err != nil {
+				return nil, nil, err
+			}
+			filters = append(filters, matchFilters...)
+			unconvertible = append(unconvertible, unconvertibleMatch...)
+		}
+	}
+
+	return filters, append(unconvertible, fields.remaining()...), nil
+}
+
+func convertMaintenanceMatch(fields *classicFields) ([]interface{}, []string, error) {
+
+	filter := map[string]interface{}{}
+	if entityType, found := fields.take("type"); found {
+		filter["entityType"] = entityType
+	}
+
+	managementZones := []string{}
+	if zone, found := fields.take("managementZoneId"); found {
+		managementZones = append(managementZones, fmt.Sprint(zone))
+	}
+	filter["managementZones"] = managementZones
+
+	tags := []string{}
+	if classicTags, found := fields.take("tags"); found {
+		converted, err := convertTags(classicTags)
+		if err != nil {
+			return nil, nil, err
+		}
+		tags = converted
+	}
+
+	combination, _ := fields.take("tagCombination")
+	if combination != "OR" || len(tags) <= 1 {
+		filter["entityTags"] = tags
+		return []interface{}{filter}, fields.remaining(), nil
+	}
+
+	filters := make([]interface{}, 0, len(tags))
+	for _, tag := range tags {
+		tagFilter := map[string]interface{}{"entityTags": []string{tag}, "managementZones": managementZones}
+		if entityType, found := filter["entityType"]; found {
+			tagFilter["entityType"] = entityType
+		}
+		filters = append(filters, tagFilter)
+	}
+	return filters, fields.remaining(), nil
+}
+
+// classicDateTimeLayout is the layout of the start and end of classic maintenance windows, e.g. 2021-05-01 10:00
+const classicDateTimeLayout = "2006-01-02 15:04"
+
+// classicTimeLayout is the layout of the start time of recurrences of classic maintenance windows, e.g. 10:00
+const classicTimeLayout = "15:04"
+
+// recurrences are the fields of the schedule holding the recurrence, by the recurrence type
+var recurrences = map[string]string{
+	"DAILY":   "dailyRecurrence",
+	"WEEKLY":  "weeklyRecurrence",
+	"MONTHLY": "monthlyRecurrence",
+}
+
+func convertMaintenanceSchedule(schedule interface{}) (map[string]interface{}, []string, error) {
+
+	scheduleFields, ok := schedule.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("schedule is no json object")
+	}
+	fields := newClassicFields(scheduleFields, "schedule.")
+
+	recurrenceType, _ := fields.take("recurrenceType")
+	zone, _ := fields.take("zoneId")
+
+	start, err := parseClassicDateTime(fields, "start")
+	if err != nil {
+		return nil, nil, err
+	}
+	end, err := parseClassicDateTime(fields, "end")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if recurrenceType == "ONCE" {
+		converted := map[string]interface{}{
+			"scheduleType": "ONCE",
+			"onceRecurrence": map[string]interface{}{
+				"startTime": start.Format("2006-01-02T15:04:05"),
+				"endTime":   end.Format("2006-01-02T15:04:05"),
+				"timeZone":  zone,
+			},
+		}
+		return converted, fields.remaining(), nil
+	}
+
+	recurrenceField, found := recurrences[fmt.Sprint(recurrenceType)]
+	if !found {
+		return nil, nil, fmt.Errorf("unknown recurrence type %v", recurrenceType)
+	}
+
+	classicRecurrence, _ := fields.take("recurrence")
+	recurrenceFields, ok := classicRecurrence.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("schedule.recurrence is no json object")
+	}
+	recurrence := newClassicFields(recurrenceFields, "schedule.recurrence.")
+
+	startTime, _ := recurrence.take("startTime")
+	windowStart, err := time.Parse(classicTimeLayout, fmt.Sprint(startTime))
+	if err != nil {
+		return nil, nil, fmt.Errorf("schedule.recurrence.startTime %v is no time like 10:00", startTime)
+	}
+	duration, _ := recurrence.take("durationMinutes")
+	minutes, err := strconv.Atoi(fmt.Sprint(duration))
+	if err != nil || minutes <= 0 {
+		return nil, nil, fmt.Errorf("schedule.recurrence.durationMinutes %v is no positive number", duration)
+	}
+
+	unconvertible := make([]string, 0)
+	windowEnd := windowStart.Add(time.Duration(minutes) * time.Minute)
+	if windowEnd.Day() != windowStart.Day() {
+		// time windows of the schema end on the day they start
+		unconvertible = append(unconvertible, "schedule.recurrence.durationMinutes")
+	}
+
+	converted := map[string]interface{}{
+		"timeWindow": map[string]interface{}{
+			"startTime": windowStart.Format("15:04:05"),
+			"endTime":   windowEnd.Format("15:04:05"),
+			"timeZone":  zone,
+		},
+		"recurrenceRange": map[string]interface{}{
+			"scheduleStartDate": start.Format("2006-01-02"),
+			"scheduleEndDate":   end.Format("2006-01-02"),
+		},
+	}
+	if dayOfWeek, found := recurrence.take("dayOfWeek"); found && recurrenceType == "WEEKLY" {
+		converted["dayOfWeek"] = dayOfWeek
+	}
+	if dayOfMonth, found := recurrence.take("dayOfMonth"); found && recurrenceType == "MONTHLY" {
+		converted["dayOfMonth"] = dayOfMonth
+	}
+
+	unconvertible = append(unconvertible, recurrence.remaining()...)
+	return map[string]interface{}{"scheduleType": recurrenceType, recurrenceField: converted}, append(unconvertible, fields.remaining()...), nil
+}
+
+func parseClassicDateTime(fields *classicFields, name string) (time.Time, error) {
+
+	value, _ := fields.take(name)
+	parsed, err := time.Parse(classicDateTimeLayout, fmt.Sprint(value))
+	if err != nil {
+		return parsed, fmt.Errorf("%s%s %v is no date like 2021-05-01 10:00", fields.prefix, name, value)
+	}
+	return parsed, nil
+}
+
+func convertAlertingProfile(classic map[string]interface{}) (map[string]interface{}, []string, error) {
+
+	fields := newClassicFields(classic, "")
+	value := map[string]interface{}{"eventFilters": []interface{}{}}
+
+	if name, found := fields.take("displayName"); found {
+		value["name"] = name
+	}
+	if zone, found := fields.take("managementZoneId"); found {
+		value["managementZone"] = fmt.Sprint(zone)
+	}
+
+	unconvertible := make([]string, 0)
+
+	// event filters of the schema are structured differently and need to be written by hand
+	if filters, found := fields.take("eventTypeFilters"); found {
+		if list, ok := filters.([]interface{}); !ok || len(list) > 0 {
+			unconvertible = append(unconvertible, "eventTypeFilters")
+		}
+	}
+
+	severityRules := make([]interface{}, 0)
+	if rules, found := fields.take("rules"); found {
+		list, ok := rules.([]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("rules is no list")
+		}
+		for i, rule := range list {
+			ruleFields, ok := rule.(map[string]interface{})
+			if !ok {
+				return nil, nil, fmt.Errorf("rules[%d] is no json object", i)
+			}
+			severityRule, unconvertibleRule, err := convertAlertingRule(newClassicFields(ruleFields, fmt.Sprintf("rules[%d].", i)))
+			if err != nil {
+				return nil, nil, err
+			}
+			severityRules = append(severityRules, severityRule)
+			unconvertible = append(unconvertible, unconvertibleRule...)
+		}
+	}
+	value["severityRules"] = severityRules
+
+	return value, append(unconvertible, fields.remaining()...), nil
+}
+
+func convertAlertingRule(fields *classicFields) (map[string]interface{}, []string, error) {
+
+	rule := map[string]interface{}{"delayInMinutes": 0, "tagFilterIncludeMode": "NONE", "tagFilter": []string{}}
+
+	if severity, found := fields.take("severityLevel"); found {
+		rule["severityLevel"] = severity
+	}
+	if delay, found := fields.take("delayInMinutes"); found {
+		rule["delayInMinutes"] = delay
+	}
+
+	tagFilter, found := fields.take("tagFilter")
+	if !found {
+		return rule, fields.remaining(), nil
+	}
+	filterFields, ok := tagFilter.(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("%stagFilter is no json object", fields.prefix)
+	}
+	filter := newClassicFields(filterFields, fields.prefix+"tagFilter.")
+
+	if mode, found := filter.take("includeMode"); found {
+		rule["tagFilterIncludeMode"] = mode
+	}
+	if tags, found := filter.take("tagFilters"); found {
+		converted, err := convertTags(tags)
+		if err != nil {
+			return nil, nil, err
+		}
+		rule["tagFilter"] = converted
+	}
+
+	return rule, append(filter.remaining(), fields.remaining()...), nil
+}
+
+// convertTags converts classic tags to the tags of settings, e.g. [AWS]environment:production or team
+func convertTags(tags interface{}) ([]string, error) {
+
+	list, ok := tags.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("tags are no list")
+	}
+
+	converted := make([]string, 0, len(list))
+	for _, tag := range list {
+		fields, ok := tag.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("tag %v is no json object", tag)
+		}
+
+		var converting strings.Builder
+		if context, found := fields["context"]; found && context != nil && context != "CONTEXTLESS" {
+			converting.WriteString("[" + fmt.Sprint(context) + "]")
+		}
+		converting.WriteString(fmt.Sprint(fields["key"]))
+		if value, found := fields["value"]; found && value != nil && value != "" {
+			converting.WriteString(":" + fmt.Sprint(value))
+		}
+		converted = append(converted, converting.String())
+	}
+
+	return converted, nil
+}
@@ -0,0 +1,142 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package settings
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func convertForTest(t *testing.T, apiId string, classic string) ConversionResult {
+
+	successor, found := SuccessorOf(apiId)
+	assert.Assert(t, found, apiId)
+
+	result, err := successor.Convert([]byte(classic))
+	assert.NilError(t, err)
+	return result
+}
+
+func TestConvertOnceMaintenanceWindow(t *testing.T) {
+
+	result := convertForTest(t, "maintenance-window", `{
+		"id": "42",
+		"name": "Patching",
+		"description": "",
+		"type": "PLANNED",
+		"suppression": "DONT_DETECT_PROBLEMS",
+		"suppressSyntheticMonitorsExecution": true,
+		"scope": {
+			"entities": ["HOST-1"],
+			"matches": [{"type": "HOST", "managementZoneId": 4611686018427387905, "tags": [{"context": "CONTEXTLESS", "key": "team", "value": "a"}, {"context": "AWS", "key": "stage"}], "tagCombination": "OR"}]
+		},
+		"schedule": {"recurrenceType": "ONCE", "start": "2021-05-01 10:00", "end": "2021-05-01 12:30", "zoneId": "Europe/Vienna"}
+	}`)
+
+	assert.DeepEqual(t, result.Unconvertible, []string{})
+	assert.Equal(t, string(result.Value), `{"enabled":true,`+
+		`"filters":[{"entityId":"HOST-1","entityTags":[],"managementZones":[]},`+
+		`{"entityTags":["team:a"],"entityType":"HOST","managementZones":["4611686018427387905"]},`+
+		`{"entityTags":["[AWS]stage"],"entityType":"HOST","managementZones":["4611686018427387905"]}],`+
+		`"generalProperties":{"disableSyntheticMonitorExecution":true,"maintenanceType":"PLANNED","name":"Patching","suppression":"DONT_DETECT_PROBLEMS"},`+
+		`"schedule":{"onceRecurrence":{"endTime":"2021-05-01T12:30:00","startTime":"2021-05-01T10:00:00","timeZone":"Europe/Vienna"},"scheduleType":"ONCE"}}`)
+}
+
+func TestConvertWeeklyMaintenanceWindow(t *testing.T) {
+
+	result := convertForTest(t, "maintenance-window", `{
+		"name": "Weekly",
+		"type": "PLANNED",
+		"suppression": "DETECT_PROBLEMS_AND_ALERT",
+		"schedule": {
+			"recurrenceType": "WEEKLY",
+			"recurrence": {"dayOfWeek": "MONDAY", "startTime": "23:00", "durationMinutes": 90},
+			"start": "2021-05-01 00:00",
+			"end": "2022-05-01 00:00",
+			"zoneId": "UTC"
+		},
+		"owner": "ops"
+	}`)
+
+	// the window of the schema can not pass midnight
+	assert.DeepEqual(t, result.Unconvertible, []string{"owner", "schedule.recurrence.durationMinutes"})
+	assert.Equal(t, string(result.Value), `{"enabled":true,"filters":[],`+
+		`"generalProperties":{"disableSyntheticMonitorExecution":false,"maintenanceType":"PLANNED","name":"Weekly","suppression":"DETECT_PROBLEMS_AND_ALERT"},`+
+		`"schedule":{"scheduleType":"WEEKLY","weeklyRecurrence":{"dayOfWeek":"MONDAY",`+
+		`"recurrenceRange":{"scheduleEndDate":"2022-05-01","scheduleStartDate":"2021-05-01"},`+
+		`"timeWindow":{"endTime":"00:30:00","startTime":"23:00:00","timeZone":"UTC"}}}}`)
+}
+
+func TestConvertMaintenanceWindowFailsForInvalidSchedules(t *testing.T) {
+
+	successor, _ := SuccessorOf("maintenance-window")
+
+	_, err := successor.Convert([]byte(`{"name": "window"}`))
+	assert.ErrorContains(t, err, "no schedule defined")
+
+	_, err = successor.Convert([]byte(`{"schedule": {"recurrenceType": "ONCE", "start": "tomorrow", "end": "2021-05-01 00:00"}}`))
+	assert.ErrorContains(t, err, "schedule.start tomorrow is no date like 2021-05-01 10:00")
+
+	_, err = successor.Convert([]byte(`{"schedule": {"recurrenceType": "YEARLY", "start": "2021-05-01 00:00", "end": "2021-05-01 00:00"}}`))
+	assert.ErrorContains(t, err, "unknown recurrence type YEARLY")
+}
+
+func TestConvertAlertingProfile(t *testing.T) {
+
+	result := convertForTest(t, "alerting-profile", `{
+		"displayName": "Team A",
+		"managementZoneId": 4611686018427387905,
+		"rules": [
+			{"severityLevel": "AVAILABILITY", "delayInMinutes": 5, "tagFilter": {"includeMode": "INCLUDE_ALL", "tagFilters": [{"context": "CONTEXTLESS", "key": "team", "value": "a"}]}},
+			{"severityLevel": "ERROR", "delayInMinutes": 0, "tagFilter": {"includeMode": "NONE", "tagFilters": []}}
+		],
+		"eventTypeFilters": []
+	}`)
+
+	assert.DeepEqual(t, result.Unconvertible, []string{})
+	assert.Equal(t, string(result.Value), `{"eventFilters":[],"managementZone":"4611686018427387905","name":"Team A","severityRules":[`+
+		`{"delayInMinutes":5,"severityLevel":"AVAILABILITY","tagFilter":["team:a"],"tagFilterIncludeMode":"INCLUDE_ALL"},`+
+		`{"delayInMinutes":0,"severityLevel":"ERROR","tagFilter":[],"tagFilterIncludeMode":"NONE"}]}`)
+}
+
+func TestConvertAlertingProfileReportsEventTypeFilters(t *testing.T) {
+
+	result := convertForTest(t, "alerting-profile", `{"displayName": "Team A", "rules": [], "eventTypeFilters": [{"predefinedEventFilter": {"eventType": "OSI_HIGH_CPU"}}]}`)
+	assert.DeepEqual(t, result.Unconvertible, []string{"eventTypeFilters"})
+}
+
+func TestNameOfReadsNameOfConvertedValues(t *testing.T) {
+
+	profile := convertForTest(t, "alerting-profile", `{"displayName": "Team A", "rules": []}`)
+	assert.Equal(t, Successors["alerting-profile"].NameOf(profile.Value), "Team A")
+
+	window := convertForTest(t, "maintenance-window", `{"name": "Patching", "type": "PLANNED", "suppression": "DETECT_PROBLEMS_AND_ALERT",
+		"schedule": {"recurrenceType": "ONCE", "start": "2021-05-01 10:00", "end": "2021-05-01 12:00", "zoneId": "UTC"}}`)
+	assert.Equal(t, Successors["maintenance-window"].NameOf(window.Value), "Patching")
+
+	assert.Equal(t, Successors["alerting-profile"].NameOf([]byte(`{"name": 1}`)), "")
+	assert.Equal(t, Successors["maintenance-window"].NameOf([]byte(`[]`)), "")
+}
+
+func TestApisWithoutSuccessor(t *testing.T) {
+
+	_, found := SuccessorOf("management-zone")
+	assert.Assert(t, !found)
+}
@@ -0,0 +1,110 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// ErrConflict is returned by a backend if the stored state was modified since it was read
+var ErrConflict = errors.New("state was modified concurrently")
+
+// Backend stores the content of a state file. Writes are optimistically locked: they only succeed if the stored
+// state still has the version it had when it was read, so that runners sharing a state do not overwrite each other
+type Backend interface {
+
+	// Read returns the stored content and its version. The content is nil if no state is stored yet
+	Read() (content []byte, version string, err error)
+
+	// Write stores the content if the stored state still has the given version, an empty version meaning
+	// that no state is stored yet. Returns ErrConflict otherwise
+	Write(content []byte, version string) error
+
+	String() string
+}
+
+// NewBackend creates the backend for the given location. Supported are s3://bucket/key, gs://bucket/object and
+// azblob://account/container/blob. Any other location is a local file
+func NewBackend(location string, fileReader util.FileReader) (Backend, error) {
+
+	scheme := ""
+	path := location
+	if i := strings.Index(location, "://"); i >= 0 {
+		scheme = location[:i]
+		path = location[i+3:]
+	}
+
+	switch scheme {
+	case "":
+		return &fileBackend{file: location, fileReader: fileReader}, nil
+	case "s3":
+		return newS3Store(path)
+	case "gs":
+		return newGcsStore(path)
+	case "azblob":
+		return newAzureStore(path)
+	default:
+		return nil, fmt.Errorf("unknown state backend %s, supported are s3://, gs:// and azblob://", scheme)
+	}
+}
+
+// fileBackend stores the state in a local file. Its version is the checksum of the content
+type fileBackend struct {
+	file       string
+	fileReader util.FileReader
+}
+
+func (b *fileBackend) Read() ([]byte, string, error) {
+
+	content, err := b.fileReader.ReadFile(b.file)
+	if os.IsNotExist(err) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, checksum(content), nil
+}
+
+func (b *fileBackend) Write(content []byte, version string) error {
+
+	stored, err := ioutil.ReadFile(b.file)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if (err == nil && checksum(stored) != version) || (err != nil && version != "") {
+		return ErrConflict
+	}
+
+	return ioutil.WriteFile(b.file, content, 0664)
+}
+
+func (b *fileBackend) String() string {
+	return b.file
+}
+
+func checksum(content []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(content))
+}
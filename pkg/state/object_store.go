@@ -0,0 +1,360 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// objectStore is a backend storing the state as an object of a cloud storage service, which offers conditional
+// writes on the version of an object for optimistic locking.
+type objectStore struct {
+	name   string
+	url    string
+	client *http.Client
+
+	// versionHeader is the response header holding the version of the object
+	versionHeader string
+
+	// condition makes a write request conditional on the version of the object. An empty version means
+	// that the object must not exist yet
+	condition func(req *http.Request, version string)
+
+	// authorize adds the credentials to a request
+	authorize func(req *http.Request, content []byte) error
+}
+
+func (s *objectStore) Read() ([]byte, string, error) {
+
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	resp, content, err := s.send(req, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("reading state %s failed with status %d: %s", s.name, resp.StatusCode, content)
+	}
+
+	return content, resp.Header.Get(s.versionHeader), nil
+}
+
+func (s *objectStore) Write(content []byte, version string) error {
+
+	req, err := http.NewRequest(http.MethodPut, s.url, bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.condition(req, version)
+
+	resp, body, err := s.send(req, content)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusPreconditionFailed {
+		return ErrConflict
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("writing state %s failed with status %d: %s", s.name, resp.StatusCode, body)
+	}
+
+	return nil
+}
+
+func (s *objectStore) send(req *http.Request, content []byte) (*http.Response, []byte, error) {
+
+	err := s.authorize(req, content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return resp, body, nil
+}
+
+func (s *objectStore) String() string {
+	return s.name
+}
+
+func newHttpClient() *http.Client {
+	return &http.Client{Timeout: 30 * time.Second}
+}
+
+// splitLocation splits the path of a backend location into the given number of parts, the last one being
+// the name of the object
+func splitLocation(location string, path string, parts int) ([]string, error) {
+
+	split := strings.SplitN(path, "/", parts)
+	for _, part := range split {
+		if part == "" {
+			split = nil
+		}
+	}
+	if len(split) != parts {
+		return nil, fmt.Errorf("invalid state backend %s", location)
+	}
+
+	return split, nil
+}
+
+// newS3Store creates a backend for s3://bucket/key. Credentials are taken from AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, the region from AWS_REGION
+func newS3Store(path string) (*objectStore, error) {
+
+	split, err := splitLocation("s3://"+path, path, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := awsCredentials{
+		accessKeyId:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		region:          os.Getenv("AWS_REGION"),
+	}
+	if credentials.accessKeyId == "" || credentials.secretAccessKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for state backend s3://%s", path)
+	}
+	if credentials.region == "" {
+		credentials.region = "us-east-1"
+	}
+
+	url := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", split[0], credentials.region, split[1])
+	return s3Store("s3://"+path, url, credentials), nil
+}
+
+func s3Store(name string, url string, credentials awsCredentials) *objectStore {
+	return &objectStore{
+		name:          name,
+		url:           url,
+		client:        newHttpClient(),
+		versionHeader: "ETag",
+		condition:     ifMatch,
+		authorize: func(req *http.Request, content []byte) error {
+			credentials.sign(req, content, time.Now().UTC())
+			return nil
+		},
+	}
+}
+
+// newGcsStore creates a backend for gs://bucket/object, authorized by the token in GOOGLE_OAUTH_ACCESS_TOKEN
+func newGcsStore(path string) (*objectStore, error) {
+
+	_, err := splitLocation("gs://"+path, path, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("GOOGLE_OAUTH_ACCESS_TOKEN is required for state backend gs://%s", path)
+	}
+
+	return gcsStore("gs://"+path, "https://storage.googleapis.com/"+path, token), nil
+}
+
+func gcsStore(name string, url string, token string) *objectStore {
+	return &objectStore{
+		name:          name,
+		url:           url,
+		client:        newHttpClient(),
+		versionHeader: "x-goog-generation",
+		condition: func(req *http.Request, version string) {
+			if version == "" {
+				version = "0"
+			}
+			req.Header.Set("x-goog-if-generation-match", version)
+		},
+		authorize: func(req *http.Request, content []byte) error {
+			req.Header.Set("Authorization", "Bearer "+token)
+			return nil
+		},
+	}
+}
+
+// newAzureStore creates a backend for azblob://account/container/blob, authorized by the shared access signature
+// in AZURE_STORAGE_SAS_TOKEN
+func newAzureStore(path string) (*objectStore, error) {
+
+	split, err := splitLocation("azblob://"+path, path, 3)
+	if err != nil {
+		return nil, err
+	}
+
+	token := strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?")
+	if token == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_SAS_TOKEN is required for state backend azblob://%s", path)
+	}
+
+	url := fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s?%s", split[0], split[1], split[2], token)
+	return azureStore("azblob://"+path, url), nil
+}
+
+func azureStore(name string, url string) *objectStore {
+	return &objectStore{
+		name:          name,
+		url:           url,
+		client:        newHttpClient(),
+		versionHeader: "ETag",
+		condition: func(req *http.Request, version string) {
+			req.Header.Set("x-ms-blob-type", "BlockBlob")
+			ifMatch(req, version)
+		},
+		authorize: func(req *http.Request, content []byte) error {
+			return nil
+		},
+	}
+}
+
+// ifMatch makes a request conditional on the ETag of an object
+func ifMatch(req *http.Request, version string) {
+	if version == "" {
+		req.Header.Set("If-None-Match", "*")
+	} else {
+		req.Header.Set("If-Match", version)
+	}
+}
+
+// awsCredentials sign requests with AWS signature version 4
+type awsCredentials struct {
+	accessKeyId     string
+	secretAccessKey string
+	sessionToken    string
+	region          string
+}
+
+// sign adds the signature of the request, covering all headers set and the host, as Authorization header
+func (c awsCredentials) sign(req *http.Request, content []byte, now time.Time) {
+
+	payloadHash := sha256.Sum256(content)
+	amzDate := now.Format("20060102T150405Z")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", hex.EncodeToString(payloadHash[:]))
+	if c.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", c.sessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	canonicalHeaders := ""
+	for _, name := range names {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	req.URL.RawPath = awsEscape(req.URL.Path)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.RawPath,
+		canonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := now.Format("20060102") + "/" + c.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := []byte("AWS4" + c.secretAccessKey)
+	for _, part := range []string{now.Format("20060102"), c.region, "s3", "aws4_request"} {
+		key = hmacSha256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKeyId, scope, signedHeaders, signature))
+}
+
+func canonicalQuery(req *http.Request) string {
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, awsEscape(key)+"="+awsEscape(value))
+		}
+	}
+
+	return strings.ReplaceAll(strings.Join(parts, "&"), "/", "%2F")
+}
+
+// awsEscape percent encodes all but the unreserved characters and slashes, as required by AWS signatures
+func awsEscape(value string) string {
+
+	var escaped strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || strings.IndexByte("-_.~/", b) >= 0 {
+			escaped.WriteByte(b)
+		} else {
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+
+	return escaped.String()
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,248 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// maxCommitAttempts limits how often committing a state is retried after it was modified concurrently
+const maxCommitAttempts = 5
+
+// State remembers the ids of the objects monaco deployed, per environment, api and object name.
+// It allows to identify the object a config was deployed to, even if other objects with the same name exist
+type State struct {
+	lock sync.Mutex
+	ids  map[string]map[string]string
+
+	// backend the state was loaded from, with the version it had, and the changes made since
+	backend Backend
+	version string
+	changes []change
+}
+
+// change is a modification of a state, which is replayed on the latest state if the state was modified concurrently
+type change struct {
+	environment string
+	key         string
+	id          string
+	removed     bool
+}
+
+// stateFile is the json representation of a State
+type stateFile struct {
+	Environments map[string]map[string]string `json:"environments"`
+}
+
+// NewState creates an empty state
+func NewState() *State {
+	return &State{ids: make(map[string]map[string]string)}
+}
+
+// LoadState reads the state from the given file. Returns an empty state if the file does not exist yet
+func LoadState(file string, fileReader util.FileReader) (*State, error) {
+	return Load(&fileBackend{file: file, fileReader: fileReader})
+}
+
+// Load reads the state from the given backend, to which it is committed afterwards. Returns an empty state if
+// the backend does not store a state yet
+func Load(backend Backend) (*State, error) {
+
+	state := NewState()
+	state.backend = backend
+
+	content, version, err := backend.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	state.ids, err = parse(content, backend)
+	if err != nil {
+		return nil, err
+	}
+	state.version = version
+
+	return state, nil
+}
+
+// parse reads the ids of a state file. Empty content is an empty state
+func parse(content []byte, backend Backend) (map[string]map[string]string, error) {
+
+	ids := make(map[string]map[string]string)
+	if content == nil {
+		return ids, nil
+	}
+
+	var parsed stateFile
+	err := json.Unmarshal(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("state file %s is invalid: %s", backend, err)
+	}
+
+	for environment, environmentIds := range parsed.Environments {
+		ids[environment] = environmentIds
+	}
+
+	return ids, nil
+}
+
+// Get returns the id the object with the given name was deployed to
+func (s *State) Get(environment string, apiId string, name string) (id string, found bool) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id, found = s.ids[environment][key(apiId, name)]
+	return id, found
+}
+
+// Set records the id the object with the given name was deployed to
+func (s *State) Set(environment string, apiId string, name string, id string) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.set(environment, key(apiId, name), id)
+	s.changes = append(s.changes, change{environment: environment, key: key(apiId, name), id: id})
+}
+
+func (s *State) set(environment string, key string, id string) {
+
+	if s.ids[environment] == nil {
+		s.ids[environment] = make(map[string]string)
+	}
+	s.ids[environment][key] = id
+}
+
+// Ids returns a copy of the ids of all objects deployed to the environment by api and object name, joined by "/"
+func (s *State) Ids(environment string) map[string]string {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ids := make(map[string]string, len(s.ids[environment]))
+	for key, id := range s.ids[environment] {
+		ids[key] = id
+	}
+	return ids
+}
+
+// Remove forgets the id of the object with the given name, e.g. as it was deleted
+func (s *State) Remove(environment string, apiId string, name string) {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.ids[environment], key(apiId, name))
+	s.changes = append(s.changes, change{environment: environment, key: key(apiId, name), removed: true})
+}
+
+// Save writes the state to the given file
+func (s *State) Save(file string) error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	content, err := s.marshal()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(file, content, 0664)
+}
+
+// Commit writes the state to the backend it was loaded from. If the state was modified concurrently, e.g. by
+// another pipeline deploying to a different environment, the changes are replayed on the latest state and the
+// commit is retried, so that no deployment loses the ids recorded by another one
+func (s *State) Commit() error {
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.backend == nil {
+		return fmt.Errorf("state was not loaded from a backend")
+	}
+
+	for attempt := 1; ; attempt++ {
+
+		content, err := s.marshal()
+		if err != nil {
+			return err
+		}
+
+		err = s.backend.Write(content, s.version)
+		if err == nil {
+			s.changes = nil
+			return nil
+		}
+		if !errors.Is(err, ErrConflict) || attempt >= maxCommitAttempts {
+			return err
+		}
+
+		util.Log.Debug("State %s was modified concurrently, replaying %d changes on the latest state", s.backend, len(s.changes))
+
+		err = s.rebase()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// rebase replaces the ids by the latest ones of the backend and replays the changes made since loading on them
+func (s *State) rebase() error {
+
+	content, version, err := s.backend.Read()
+	if err != nil {
+		return err
+	}
+
+	s.ids, err = parse(content, s.backend)
+	if err != nil {
+		return err
+	}
+	s.version = version
+
+	for _, c := range s.changes {
+		if c.removed {
+			delete(s.ids[c.environment], c.key)
+		} else {
+			s.set(c.environment, c.key, c.id)
+		}
+	}
+
+	return nil
+}
+
+func (s *State) marshal() ([]byte, error) {
+
+	content, err := json.MarshalIndent(stateFile{Environments: s.ids}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(content, '\n'), nil
+}
+
+func key(apiId string, name string) string {
+	return apiId + "/" + name
+}
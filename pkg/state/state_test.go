@@ -0,0 +1,107 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package state
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+	"gotest.tools/assert"
+)
+
+func TestLoadStateOfMissingFileIsEmpty(t *testing.T) {
+
+	state, err := LoadState(filepath.Join(t.TempDir(), "state.json"), util.NewFileReader())
+	assert.NilError(t, err)
+
+	_, found := state.Get("dev", "dashboard", "my dashboard")
+	assert.Equal(t, false, found)
+}
+
+func TestSavedStateCanBeLoaded(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	state := NewState()
+	state.Set("dev", "dashboard", "my dashboard", "42")
+	state.Set("prod", "dashboard", "my dashboard", "43")
+	assert.NilError(t, state.Save(file))
+
+	loaded, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	id, found := loaded.Get("dev", "dashboard", "my dashboard")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "42", id)
+
+	id, found = loaded.Get("prod", "dashboard", "my dashboard")
+	assert.Equal(t, true, found)
+	assert.Equal(t, "43", id)
+}
+
+func TestCommitReplaysChangesOnConcurrentlyModifiedState(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	first, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+	second, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	first.Set("dev", "dashboard", "my dashboard", "42")
+	second.Set("prod", "dashboard", "my dashboard", "43")
+	assert.NilError(t, first.Commit())
+	assert.NilError(t, second.Commit())
+
+	loaded, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	id, _ := loaded.Get("dev", "dashboard", "my dashboard")
+	assert.Equal(t, "42", id)
+	id, _ = loaded.Get("prod", "dashboard", "my dashboard")
+	assert.Equal(t, "43", id)
+}
+
+func TestCommitReplaysRemovals(t *testing.T) {
+
+	file := filepath.Join(t.TempDir(), "state.json")
+
+	initial := NewState()
+	initial.Set("dev", "dashboard", "my dashboard", "42")
+	assert.NilError(t, initial.Save(file))
+
+	first, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+	second, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	first.Set("dev", "dashboard", "other dashboard", "43")
+	second.Remove("dev", "dashboard", "my dashboard")
+	assert.NilError(t, first.Commit())
+	assert.NilError(t, second.Commit())
+
+	loaded, err := LoadState(file, util.NewFileReader())
+	assert.NilError(t, err)
+
+	_, found := loaded.Get("dev", "dashboard", "my dashboard")
+	assert.Equal(t, false, found)
+	id, _ := loaded.Get("dev", "dashboard", "other dashboard")
+	assert.Equal(t, "43", id)
+}
@@ -0,0 +1,265 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/util"
+)
+
+// resourceTypes maps the resource types of the Dynatrace Terraform provider to monaco apis
+var resourceTypes = map[string]string{
+	"dynatrace_alerting_profile":           "alerting-profile",
+	"dynatrace_management_zone":            "management-zone",
+	"dynatrace_autotag":                    "auto-tag",
+	"dynatrace_dashboard":                  "dashboard",
+	"dynatrace_notification":               "notification",
+	"dynatrace_custom_service":             "custom-service-java",
+	"dynatrace_custom_anomalies":           "anomaly-detection-metrics",
+	"dynatrace_synthetic_location":         "synthetic-location",
+	"dynatrace_http_monitor":               "synthetic-monitor",
+	"dynatrace_browser_monitor":            "synthetic-monitor",
+	"dynatrace_web_application":            "application",
+	"dynatrace_application_detection_rule": "app-detection-rule",
+	"dynatrace_aws_credentials":            "aws-credentials",
+	"dynatrace_k8s_credentials":            "kubernetes-credentials",
+	"dynatrace_azure_credentials":          "azure-credentials",
+	"dynatrace_request_attribute":          "request-attributes",
+	"dynatrace_calculated_service_metric":  "calculated-metrics-service",
+	"dynatrace_processgroup_naming":        "conditional-naming-processgroup",
+	"dynatrace_host_naming":                "conditional-naming-host",
+	"dynatrace_service_naming":             "conditional-naming-service",
+	"dynatrace_maintenance_window":         "maintenance-window",
+	"dynatrace_request_naming":             "request-naming-service",
+	"dynatrace_calculated_metrics_log":     "calculated-metrics-log",
+}
+
+// ignoredAttributes are attributes managed by Terraform or the provider, which are not part of the api payload
+var ignoredAttributes = map[string]bool{
+	"id":       true,
+	"unknowns": true,
+	"metadata": true,
+}
+
+// state is the subset of the Terraform state file format (version 4) needed to read resources
+type state struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// stateClient is a read-only rest.DynatraceClient serving the resources of a Terraform state file.
+// Resource attributes are converted to api payloads by converting attribute names from snake_case to camelCase.
+// Terraform stores single nested blocks as lists with one element, which are unwrapped for the fields the api schema
+// defines as objects. Blocks of other fields are kept as lists. As the provider schema does not match all api payloads, imported configs should always be validated
+type stateClient struct {
+	configs  map[string][]stateConfig
+	matching rest.NameMatching
+}
+
+type stateConfig struct {
+	value   api.Value
+	payload map[string]interface{}
+}
+
+// NewStateClient parses the content of a Terraform state file. Configs are looked up by name with the given name matching
+func NewStateClient(content []byte, matching rest.NameMatching) (rest.DynatraceClient, error) {
+
+	var parsed state
+
+	err := json.Unmarshal(content, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("terraform state could not be parsed: %s", err)
+	}
+
+	if parsed.Version != 4 {
+		return nil, fmt.Errorf("terraform state version %d is not supported, only version 4 is", parsed.Version)
+	}
+
+	configs := make(map[string][]stateConfig)
+
+	for _, resource := range parsed.Resources {
+
+		if resource.Mode != "managed" || !strings.HasPrefix(resource.Type, "dynatrace_") {
+			continue
+		}
+
+		apiId, supported := resourceTypes[resource.Type]
+		if !supported {
+			util.Log.Warn("\tSkipping %s.%s, resource type %s is not supported", resource.Type, resource.Name, resource.Type)
+			continue
+		}
+
+		for i, instance := range resource.Instances {
+
+			payload := convertAttributes(apiId, "", instance.Attributes)
+
+			name := nameOf(payload)
+			if name == "" {
+				name = resource.Name
+			}
+
+			configs[apiId] = append(configs[apiId], stateConfig{
+				value:   api.Value{Id: fmt.Sprintf("%s.%s[%d]", resource.Type, resource.Name, i), Name: name},
+				payload: payload,
+			})
+		}
+	}
+
+	for _, resources := range configs {
+		sort.Slice(resources, func(i, j int) bool {
+			return resources[i].value.Id < resources[j].value.Id
+		})
+	}
+
+	return &stateClient{configs: configs, matching: matching}, nil
+}
+
+func (c *stateClient) List(a api.Api) (values []api.Value, err error) {
+
+	for _, config := range c.configs[a.GetId()] {
+		values = append(values, config.value)
+	}
+
+	return values, nil
+}
+
+func (c *stateClient) ReadById(a api.Api, id string) ([]byte, error) {
+
+	for _, config := range c.configs[a.GetId()] {
+		if config.value.Id == id {
+			return json.Marshal(config.payload)
+		}
+	}
+
+	return nil, fmt.Errorf("resource %s of %s not found in terraform state", id, a.GetId())
+}
+
+func (c *stateClient) UpsertByName(a api.Api, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+	return entity, rest.ErrReadOnly
+}
+
+func (c *stateClient) UpsertById(a api.Api, id string, name string, payload []byte) (entity api.DynatraceEntity, err error) {
+	return entity, rest.ErrReadOnly
+}
+
+func (c *stateClient) DeleteByName(a api.Api, name string) error {
+	return rest.ErrReadOnly
+}
+
+func (c *stateClient) DeleteById(a api.Api, id string) error {
+	return rest.ErrReadOnly
+}
+
+func (c *stateClient) ExistsByName(a api.Api, name string) (exists bool, id string, err error) {
+
+	values, err := c.List(a)
+	if err != nil {
+		return false, "", err
+	}
+
+	return rest.FindByName(a, values, name, c.matching)
+}
+
+func nameOf(payload map[string]interface{}) string {
+
+	for _, field := range []string{"name", "displayName"} {
+		if name, ok := payload[field].(string); ok && name != "" {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// convertAttributes converts the Terraform attributes at path to an api payload. Attribute names are converted to
+// camelCase, null values and empty lists are dropped
+func convertAttributes(apiId string, path string, attributes map[string]interface{}) map[string]interface{} {
+
+	result := make(map[string]interface{})
+
+	for key, value := range attributes {
+
+		if ignoredAttributes[key] {
+			continue
+		}
+
+		field := toCamelCase(key)
+		if path != "" {
+			field = path + "." + field
+		}
+
+		converted, keep := convertValue(apiId, field, value)
+		if keep {
+			result[toCamelCase(key)] = converted
+		}
+	}
+
+	return result
+}
+
+func convertValue(apiId string, field string, value interface{}) (converted interface{}, keep bool) {
+
+	switch typed := value.(type) {
+	case nil:
+		return nil, false
+	case map[string]interface{}:
+		return convertAttributes(apiId, field, typed), true
+	case []interface{}:
+		if len(typed) == 0 {
+			return nil, false
+		}
+		if len(typed) == 1 && api.IsObjectField(apiId, field) {
+			if block, isBlock := typed[0].(map[string]interface{}); isBlock {
+				return convertAttributes(apiId, field, block), true
+			}
+		}
+		list := make([]interface{}, 0, len(typed))
+		for _, element := range typed {
+			if convertedElement, keepElement := convertValue(apiId, field, element); keepElement {
+				list = append(list, convertedElement)
+			}
+		}
+		return list, true
+	default:
+		return typed, true
+	}
+}
+
+func toCamelCase(name string) string {
+
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,129 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package terraform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+var testManagementZoneApi = api.NewApi("management-zone", "/api/config/v1/managementZones")
+
+const testState = `{
+  "version": 4,
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "dynatrace_management_zone",
+      "name": "zone",
+      "instances": [
+        {
+          "attributes": {
+            "id": "1234",
+            "name": "Production",
+            "unknowns": null,
+            "description": null,
+            "rules": [
+              {
+                "type": "SERVICE",
+                "enabled": true,
+                "propagation_types": [],
+                "conditions": [
+                  {
+                    "key": [{"attribute": "SERVICE_TAGS"}]
+                  }
+                ]
+              }
+            ]
+          }
+        }
+      ]
+    },
+    {
+      "mode": "managed",
+      "type": "dynatrace_unsupported_thing",
+      "name": "thing",
+      "instances": [{"attributes": {"name": "thing"}}]
+    },
+    {
+      "mode": "data",
+      "type": "dynatrace_management_zone",
+      "name": "lookup",
+      "instances": [{"attributes": {"name": "lookup"}}]
+    }
+  ]
+}`
+
+func TestStateClientConvertsResources(t *testing.T) {
+
+	client, err := NewStateClient([]byte(testState), rest.NameMatching{})
+	assert.NilError(t, err)
+
+	values, err := client.List(testManagementZoneApi)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, values, []api.Value{{Id: "dynatrace_management_zone.zone[0]", Name: "Production"}})
+
+	payload, err := client.ReadById(testManagementZoneApi, values[0].Id)
+	assert.NilError(t, err)
+
+	var content map[string]interface{}
+	assert.NilError(t, json.Unmarshal(payload, &content))
+	assert.DeepEqual(t, content, map[string]interface{}{
+		"name": "Production",
+		"rules": []interface{}{
+			map[string]interface{}{
+				"type":    "SERVICE",
+				"enabled": true,
+				"conditions": []interface{}{
+					map[string]interface{}{"key": map[string]interface{}{"attribute": "SERVICE_TAGS"}},
+				},
+			},
+		},
+	})
+}
+
+func TestStateClientKeepsBlocksOfFieldsWhichAreNoObjectsAsLists(t *testing.T) {
+
+	converted := convertAttributes("alerting-profile", "", map[string]interface{}{
+		"display_name": "profile",
+		"rules":        []interface{}{map[string]interface{}{"severity_level": "AVAILABILITY"}},
+	})
+
+	assert.DeepEqual(t, converted, map[string]interface{}{
+		"displayName": "profile",
+		"rules":       []interface{}{map[string]interface{}{"severityLevel": "AVAILABILITY"}},
+	})
+}
+
+func TestStateClientFailsOnUnsupportedVersion(t *testing.T) {
+
+	_, err := NewStateClient([]byte(`{"version": 3, "resources": []}`), rest.NameMatching{})
+	assert.ErrorContains(t, err, "version 3 is not supported")
+}
+
+func TestToCamelCase(t *testing.T) {
+
+	assert.Equal(t, toCamelCase("propagation_types"), "propagationTypes")
+	assert.Equal(t, toCamelCase("name"), "name")
+	assert.Equal(t, toCamelCase("entity_selector_based_rule"), "entitySelectorBasedRule")
+}
@@ -0,0 +1,122 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Command gen generates the typed List and Get functions of all apis of the api catalog
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/format"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+)
+
+// types holds the apis whose objects are decoded into other types than Object
+var types = map[string]string{
+	"dashboard": "Dashboard",
+}
+
+// skipped holds the apis whose objects can not be read by id alone
+var skipped = map[string]bool{
+	"settings": true,
+}
+
+type generatedApi struct {
+	Id       string
+	Type     string
+	Singular string
+	Plural   string
+}
+
+var generated = template.Must(template.New("apis").Parse(`// Code generated by pkg/typed/gen. DO NOT EDIT.
+
+package typed
+
+import (
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+{{ range . }}
+// List{{ .Plural }} reads all objects of the {{ .Id }} api
+func List{{ .Plural }}(client rest.DynatraceClient) ([]{{ .Type }}, error) {
+	return list{{ .Type }}s(client, "{{ .Id }}")
+}
+
+// Get{{ .Singular }} reads the object of the {{ .Id }} api with the given id
+func Get{{ .Singular }}(client rest.DynatraceClient, id string) ({{ .Type }}, error) {
+	return get{{ .Type }}(client, "{{ .Id }}", id)
+}
+{{ end }}`))
+
+func main() {
+
+	output := flag.String("output", "apis_gen.go", "File the functions are written to.")
+	flag.Parse()
+
+	apis := make([]generatedApi, 0)
+	for id := range api.NewApis() {
+		if skipped[id] {
+			continue
+		}
+
+		typeName, found := types[id]
+		if !found {
+			typeName = "Object"
+		}
+
+		singular := camelCase(id)
+		plural := singular
+		if !strings.HasSuffix(plural, "s") {
+			plural += "s"
+		}
+
+		apis = append(apis, generatedApi{Id: id, Type: typeName, Singular: singular, Plural: plural})
+	}
+	sort.Slice(apis, func(i, j int) bool { return apis[i].Id < apis[j].Id })
+
+	var content bytes.Buffer
+	err := generated.Execute(&content, apis)
+	if err != nil {
+		log.Fatalf("generating functions failed: %s", err)
+	}
+
+	source, err := format.Source(content.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated functions failed: %s", err)
+	}
+
+	err = ioutil.WriteFile(*output, source, 0644)
+	if err != nil {
+		log.Fatalf("writing %s failed: %s", *output, err)
+	}
+}
+
+// camelCase converts an api id like management-zone to ManagementZone
+func camelCase(id string) string {
+
+	var result strings.Builder
+	for _, part := range strings.Split(id, "-") {
+		if part != "" {
+			result.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,187 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package typed decodes the objects of the configuration apis into structs, for programs using the DynatraceClient
+// which need more than the raw json payloads. The List and Get functions per api are generated from the api catalog
+package typed
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+)
+
+//go:generate go run ./gen -output apis_gen.go
+
+// maxConcurrentReads limits the number of objects of an api read at the same time
+const maxConcurrentReads = 8
+
+// catalog holds the apis the functions are generated for
+var catalog = api.NewApis()
+
+// Metadata is the metadata the server returns with the objects of most configuration apis
+type Metadata struct {
+	ClusterVersion               string   `json:"clusterVersion"`
+	ConfigurationVersions        []int64  `json:"configurationVersions"`
+	CurrentConfigurationVersions []string `json:"currentConfigurationVersions"`
+}
+
+// Object holds the fields common to the objects of the configuration apis. Fields an api does not return are empty,
+// Raw holds the complete payload for everything else
+type Object struct {
+	Id          string          `json:"id"`
+	Name        string          `json:"name"`
+	DisplayName string          `json:"displayName"`
+	Description string          `json:"description"`
+	Enabled     *bool           `json:"enabled"`
+	Metadata    *Metadata       `json:"metadata"`
+	Raw         json.RawMessage `json:"-"`
+}
+
+// Dashboard is an object of the dashboard api, whose name and owner are part of its dashboard metadata
+type Dashboard struct {
+	Id                string            `json:"id"`
+	DashboardMetadata DashboardMetadata `json:"dashboardMetadata"`
+	Metadata          *Metadata         `json:"metadata"`
+	Raw               json.RawMessage   `json:"-"`
+}
+
+// DashboardMetadata describes a dashboard
+type DashboardMetadata struct {
+	Name   string   `json:"name"`
+	Owner  string   `json:"owner"`
+	Shared bool     `json:"shared"`
+	Preset bool     `json:"preset"`
+	Tags   []string `json:"tags"`
+}
+
+func decodeObject(data []byte) (Object, error) {
+	var object Object
+	err := json.Unmarshal(data, &object)
+	object.Raw = data
+	return object, err
+}
+
+func decodeDashboard(data []byte) (Dashboard, error) {
+	var dashboard Dashboard
+	err := json.Unmarshal(data, &dashboard)
+	dashboard.Raw = data
+	return dashboard, err
+}
+
+// getObject reads the object with the given id of the api
+func getObject(client rest.DynatraceClient, apiId string, id string) (Object, error) {
+
+	var object Object
+	err := read(client, apiId, []api.Value{{Id: id}}, func(_ int, data []byte) (err error) {
+		object, err = decodeObject(data)
+		return err
+	})
+	return object, err
+}
+
+// listObjects reads all objects of the api, in the order the api lists them
+func listObjects(client rest.DynatraceClient, apiId string) ([]Object, error) {
+
+	values, err := list(client, apiId)
+	if err != nil {
+		return nil, err
+	}
+
+	objects := make([]Object, len(values))
+	err = read(client, apiId, values, func(i int, data []byte) (err error) {
+		objects[i], err = decodeObject(data)
+		return err
+	})
+	return objects, err
+}
+
+// getDashboard reads the dashboard with the given id
+func getDashboard(client rest.DynatraceClient, apiId string, id string) (Dashboard, error) {
+
+	var dashboard Dashboard
+	err := read(client, apiId, []api.Value{{Id: id}}, func(_ int, data []byte) (err error) {
+		dashboard, err = decodeDashboard(data)
+		return err
+	})
+	return dashboard, err
+}
+
+// listDashboards reads all dashboards, in the order the api lists them
+func listDashboards(client rest.DynatraceClient, apiId string) ([]Dashboard, error) {
+
+	values, err := list(client, apiId)
+	if err != nil {
+		return nil, err
+	}
+
+	dashboards := make([]Dashboard, len(values))
+	err = read(client, apiId, values, func(i int, data []byte) (err error) {
+		dashboards[i], err = decodeDashboard(data)
+		return err
+	})
+	return dashboards, err
+}
+
+func list(client rest.DynatraceClient, apiId string) ([]api.Value, error) {
+
+	values, err := client.List(catalog[apiId])
+	if err != nil {
+		return nil, fmt.Errorf("listing of %s failed: %s", apiId, err)
+	}
+	return values, nil
+}
+
+// read reads the objects of the values concurrently and passes their payloads to decode with the index of the value.
+// If reading or decoding fails for several objects, the error of the one listed first is returned
+func read(client rest.DynatraceClient, apiId string, values []api.Value, decode func(i int, data []byte) error) error {
+
+	a := catalog[apiId]
+
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentReads)
+	errs := make([]error, len(values))
+
+	for i, value := range values {
+		wg.Add(1)
+
+		go func(i int, id string) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			data, err := client.ReadById(a, id)
+			<-limit
+
+			if err != nil {
+				errs[i] = fmt.Errorf("reading %s %s failed: %s", apiId, id, err)
+			} else if err = decode(i, data); err != nil {
+				errs[i] = fmt.Errorf("decoding %s %s failed: %s", apiId, id, err)
+			}
+		}(i, value.Id)
+	}
+
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package typed
+
+import (
+	"testing"
+
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/api"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/fake"
+	"github.com/dynatrace-oss/dynatrace-monitoring-as-code/pkg/rest"
+	"gotest.tools/assert"
+)
+
+func TestListAndGetDecodeObjects(t *testing.T) {
+
+	apis := api.NewApis()
+	server := fake.NewServer(apis, 0)
+	defer server.Close()
+
+	client, err := rest.NewDynatraceClient(server.URL, "token")
+	assert.NilError(t, err)
+
+	for _, name := range []string{"zone-a", "zone-b"} {
+		_, err = client.UpsertByName(apis["management-zone"], name, []byte(`{"name": "`+name+`", "description": "managed", "metadata": {"clusterVersion": "1.214"}}`))
+		assert.NilError(t, err)
+	}
+	_, err = client.UpsertById(apis["dashboard"], "dashboard-id", "overview", []byte(`{"dashboardMetadata": {"name": "overview", "owner": "jane", "tags": ["ops"]}}`))
+	assert.NilError(t, err)
+
+	zones, err := ListManagementZones(client)
+	assert.NilError(t, err)
+	assert.Equal(t, len(zones), 2)
+
+	names := map[string]bool{}
+	for _, zone := range zones {
+		names[zone.Name] = true
+		assert.Equal(t, zone.Description, "managed")
+		assert.Equal(t, zone.Metadata.ClusterVersion, "1.214")
+		assert.Assert(t, len(zone.Raw) > 0)
+	}
+	assert.DeepEqual(t, names, map[string]bool{"zone-a": true, "zone-b": true})
+
+	dashboards, err := ListDashboards(client)
+	assert.NilError(t, err)
+	assert.Equal(t, len(dashboards), 1)
+	assert.Equal(t, dashboards[0].DashboardMetadata.Owner, "jane")
+	assert.DeepEqual(t, dashboards[0].DashboardMetadata.Tags, []string{"ops"})
+
+	dashboard, err := GetDashboard(client, "dashboard-id")
+	assert.NilError(t, err)
+	assert.Equal(t, dashboard.DashboardMetadata.Name, "overview")
+
+	_, err = GetAutoTag(client, "missing")
+	assert.ErrorContains(t, err, "reading auto-tag missing failed")
+}
+
+func TestDecodingFailsOnInvalidPayloads(t *testing.T) {
+
+	_, err := decodeObject([]byte(`{"name": 42}`))
+	assert.ErrorContains(t, err, "cannot unmarshal number")
+}
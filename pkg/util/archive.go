@@ -0,0 +1,169 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ZipFolder writes all files contained in folder (recursively) into the zip archive zipFile.
+// Paths within the archive are relative to folder
+func ZipFolder(folder string, zipFile string) (err error) {
+
+	out, err := os.Create(zipFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	zipWriter := zip.NewWriter(out)
+
+	err = filepath.Walk(folder, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+
+		relativePath, err := filepath.Rel(folder, path)
+		if err != nil {
+			return err
+		}
+
+		writer, err := zipWriter.Create(filepath.ToSlash(relativePath))
+		if err != nil {
+			return err
+		}
+
+		return copyFileTo(path, writer)
+	})
+	if err != nil {
+		return err
+	}
+
+	return zipWriter.Close()
+}
+
+// UnzipToFolder extracts all files of the zip archive zipFile into folder
+func UnzipToFolder(zipFile string, folder string) error {
+
+	reader, err := zip.OpenReader(zipFile)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+
+		target := filepath.Join(folder, filepath.FromSlash(file.Name))
+		if !strings.HasPrefix(target, filepath.Clean(folder)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive %s contains invalid path %s", zipFile, file.Name)
+		}
+
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		err = extractFile(file, target)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UntarToFolder extracts all regular files of the tar stream into folder
+func UntarToFolder(reader io.Reader, folder string) error {
+
+	archive := tar.NewReader(reader)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		target := filepath.Join(folder, filepath.FromSlash(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(folder)+string(os.PathSeparator)) {
+			return fmt.Errorf("archive contains invalid path %s", header.Name)
+		}
+
+		err = writeFile(archive, target)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(in io.Reader, target string) error {
+
+	err := os.MkdirAll(filepath.Dir(target), 0777)
+	if err != nil {
+		return err
+	}
+
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+func extractFile(file *zip.File, target string) error {
+
+	in, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return writeFile(in, target)
+}
+
+func copyFileTo(path string, writer io.Writer) error {
+
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	_, err = io.Copy(writer, in)
+	return err
+}
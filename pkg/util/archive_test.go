@@ -0,0 +1,92 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestZipAndUnzipFolder(t *testing.T) {
+
+	source := t.TempDir()
+	err := os.MkdirAll(filepath.Join(source, "project", "dashboard"), 0777)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(filepath.Join(source, "project", "dashboard", "dashboard.json"), []byte("{}"), 0664)
+	assert.NilError(t, err)
+
+	archive := filepath.Join(t.TempDir(), "archive.zip")
+	err = ZipFolder(source, archive)
+	assert.NilError(t, err)
+
+	target := t.TempDir()
+	err = UnzipToFolder(archive, target)
+	assert.NilError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(target, "project", "dashboard", "dashboard.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "{}")
+}
+
+func TestUnzipNonExistingArchiveFails(t *testing.T) {
+
+	err := UnzipToFolder(filepath.Join(t.TempDir(), "missing.zip"), t.TempDir())
+	assert.Assert(t, err != nil)
+}
+
+func TestUntarToFolder(t *testing.T) {
+
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	writeTarEntry(t, writer, "project/dashboard/dashboard.json", "{}")
+	assert.NilError(t, writer.Close())
+
+	target := t.TempDir()
+	err := UntarToFolder(&buffer, target)
+	assert.NilError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(target, "project", "dashboard", "dashboard.json"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(content), "{}")
+}
+
+func TestUntarToFolderRejectsPathsOutsideOfFolder(t *testing.T) {
+
+	var buffer bytes.Buffer
+	writer := tar.NewWriter(&buffer)
+	writeTarEntry(t, writer, "../outside.json", "{}")
+	assert.NilError(t, writer.Close())
+
+	err := UntarToFolder(&buffer, t.TempDir())
+	assert.ErrorContains(t, err, "invalid path")
+}
+
+func writeTarEntry(t *testing.T, writer *tar.Writer, name string, content string) {
+
+	err := writer.WriteHeader(&tar.Header{Name: name, Mode: 0664, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	assert.NilError(t, err)
+	_, err = writer.Write([]byte(content))
+	assert.NilError(t, err)
+}
@@ -0,0 +1,67 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var clockLock sync.Mutex
+
+// frozenTime is the time returned by Now, the current time is returned if it is zero
+var frozenTime time.Time
+
+// Now returns the current time, unless the clock was frozen with FreezeTime. Everything derived from the time of a
+// run, e.g. rendered templates, generated names or the timestamps of downloads, uses it, so that runs are
+// reproducible byte for byte. Decisions on the safety of a run, like freeze windows, use time.Now instead
+func Now() time.Time {
+
+	clockLock.Lock()
+	defer clockLock.Unlock()
+
+	if frozenTime.IsZero() {
+		return time.Now()
+	}
+	return frozenTime
+}
+
+// FreezeTime makes Now return the given time from now on. Passing the zero time unfreezes the clock
+func FreezeTime(timestamp time.Time) {
+
+	clockLock.Lock()
+	defer clockLock.Unlock()
+
+	frozenTime = timestamp
+}
+
+// ParseTimestamp parses a timestamp given as RFC 3339 date time, e.g. 2021-03-01T12:00:00Z, or as seconds since the
+// unix epoch
+func ParseTimestamp(value string) (time.Time, error) {
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %s, expected a RFC 3339 date time like 2021-03-01T12:00:00Z or unix seconds", value)
+	}
+	return timestamp, nil
+}
@@ -0,0 +1,52 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestFreezeTime(t *testing.T) {
+
+	frozen := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	FreezeTime(frozen)
+	assert.Equal(t, Now(), frozen)
+
+	FreezeTime(time.Time{})
+	assert.Assert(t, Now().After(frozen))
+}
+
+func TestParseTimestamp(t *testing.T) {
+
+	expected := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	timestamp, err := ParseTimestamp("2021-03-01T12:00:00Z")
+	assert.NilError(t, err)
+	assert.Assert(t, timestamp.Equal(expected))
+
+	timestamp, err = ParseTimestamp("1614600000")
+	assert.NilError(t, err)
+	assert.Equal(t, timestamp, expected)
+
+	_, err = ParseTimestamp("yesterday")
+	assert.ErrorContains(t, err, "invalid timestamp yesterday")
+}
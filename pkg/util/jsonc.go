@@ -0,0 +1,83 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// jsoncExtension is the extension of templates written in JSON with comments
+const jsoncExtension = ".jsonc"
+
+// isJsonc checks if the file is written in JSON with comments, judged by its extension
+func isJsonc(fileName string) bool {
+	return strings.EqualFold(filepath.Ext(fileName), jsoncExtension)
+}
+
+// StripJsonComments removes the line (//) and block (/* */) comments of JSON with comments, leaving strings unchanged.
+// Line breaks within comments are kept, so errors of the remaining JSON point to the lines of the original content
+func StripJsonComments(content string) string {
+
+	var stripped strings.Builder
+	stripped.Grow(len(content))
+
+	inString := false
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+
+		if inString {
+			stripped.WriteByte(c)
+			if c == '\\' && i+1 < len(content) {
+				i++
+				stripped.WriteByte(content[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if c == '/' && i+1 < len(content) && content[i+1] == '/' {
+			for i < len(content) && content[i] != '\n' {
+				i++
+			}
+			if i < len(content) {
+				stripped.WriteByte('\n')
+			}
+			continue
+		}
+
+		if c == '/' && i+1 < len(content) && content[i+1] == '*' {
+			i += 2
+			for i < len(content) && !(content[i] == '*' && i+1 < len(content) && content[i+1] == '/') {
+				if content[i] == '\n' {
+					stripped.WriteByte('\n')
+				}
+				i++
+			}
+			i++
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		}
+		stripped.WriteByte(c)
+	}
+
+	return stripped.String()
+}
@@ -0,0 +1,84 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestStripJsonComments(t *testing.T) {
+
+	jsonc := `{
+  // the name is shown in the UI
+  "name": "zone", /* inline */ "url": "https://example.com/path//with/*slashes*/",
+  /*
+   * rules of the zone
+   */
+  "rules": [], // trailing
+  "escaped": "quote \" // not a comment"
+}`
+
+	expected := "{\n" +
+		"  \n" +
+		`  "name": "zone",  "url": "https://example.com/path//with/*slashes*/",` + "\n" +
+		"  \n" +
+		"\n" +
+		"\n" +
+		`  "rules": [], ` + "\n" +
+		`  "escaped": "quote \" // not a comment"` + "\n" +
+		"}"
+
+	assert.Equal(t, StripJsonComments(jsonc), expected)
+	assert.Equal(t, StripJsonComments(`{"a": 1} // end`), `{"a": 1} `)
+	assert.Equal(t, StripJsonComments(`{"a": 1} /* unterminated`), `{"a": 1} `)
+	assert.Equal(t, StripJsonComments(`{"a": "/"}`), `{"a": "/"}`)
+}
+
+func TestJsoncTemplatesAreRenderedWithoutComments(t *testing.T) {
+
+	folder := t.TempDir()
+	files := map[string]string{
+		"dashboard.jsonc": "{\n  // owner of the dashboard\n  \"owner\": \"{{ .owner }}\",\n  \"tiles\": {{ include \"tiles.jsonc\" . }}\n}\n",
+		"tiles.jsonc":     "[ /* markdown tile */ {\"name\": \"{{ .owner }}\"} ]",
+		"plain.json":      "{\"url\": \"{{ .owner }}\" // kept\n}",
+	}
+	for name, content := range files {
+		assert.NilError(t, ioutil.WriteFile(filepath.Join(folder, name), []byte(content), 0664))
+	}
+
+	templ, err := NewTemplate(filepath.Join(folder, "dashboard.jsonc"))
+	assert.NilError(t, err)
+
+	rendered, err := templ.ExecuteTemplate(map[string]string{"owner": "jane"})
+	assert.NilError(t, err)
+	assert.Assert(t, json.Valid([]byte(rendered)), rendered)
+	assert.Equal(t, rendered, "{\n  \n  \"owner\": \"jane\",\n  \"tiles\": [  {\"name\": \"jane\"} ]\n}\n")
+
+	templ, err = NewTemplate(filepath.Join(folder, "plain.json"))
+	assert.NilError(t, err)
+
+	rendered, err = templ.ExecuteTemplate(map[string]string{"owner": "jane"})
+	assert.NilError(t, err)
+	assert.Equal(t, rendered, "{\"url\": \"jane\" // kept\n}")
+}
@@ -27,8 +27,17 @@ var Log lumber.Logger = lumber.NewConsoleLogger(lumber.INFO)
 
 // SetupLogging is used to initialize the shared file Logger once the necessary setup config is available
 func SetupLogging(verbose bool) error {
+	return setupLogging(verbose, lumber.NewConsoleLogger(lumber.INFO))
+}
+
+// SetupLoggingToStderr initializes logging like SetupLogging, but logs to stderr instead of stdout, so that stdout
+// only carries the machine readable output of a command
+func SetupLoggingToStderr(verbose bool) error {
+	return setupLogging(verbose, lumber.NewBasicLogger(os.Stderr, lumber.INFO))
+}
+
+func setupLogging(verbose bool, consoleLog *lumber.ConsoleLogger) error {
 	multiLog := lumber.NewMultiLogger()
-	consoleLog := lumber.NewConsoleLogger(lumber.INFO)
 	if verbose {
 		consoleLog.Level(lumber.DEBUG)
 	}
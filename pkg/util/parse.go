@@ -18,6 +18,7 @@ package util
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"strings"
 
@@ -35,25 +36,93 @@ import (
 //
 func UnmarshalYaml(text string, fileName string) (error, map[string]map[string]string) {
 
+	err, typed, _ := UnmarshalYamlWithConfigOrder(text, fileName)
+	return err, typed
+}
+
+// UnmarshalYamlWithConfigOrder works like UnmarshalYaml, but additionally returns the ids of the configs in the order
+// they are listed in the config section, which the returned map does not preserve
+func UnmarshalYamlWithConfigOrder(text string, fileName string) (error, map[string]map[string]string, []string) {
+
+	text = NormalizeLineEndings(text)
+
 	template, err := NewTemplateFromString(fileName, text)
 	if err != nil {
-		return err, make(map[string]map[string]string)
+		return err, make(map[string]map[string]string), nil
 	}
 
 	text, err = template.ExecuteTemplate(make(map[string]string))
 	if err != nil {
-		return err, make(map[string]map[string]string)
+		return err, make(map[string]map[string]string), nil
 	}
 
-	m := make(map[string]interface{})
+	var sections yaml.MapSlice
 
-	err = yaml.Unmarshal([]byte(text), &m)
+	err = yaml.Unmarshal([]byte(text), &sections)
 	FailOnError(err, "Failed to unmarshal yaml\n"+text+"\nerror:")
 
+	m := make(map[string]interface{}, len(sections))
+	var order []string
+	for _, section := range sections {
+		key, ok := section.Key.(string)
+		if !ok {
+			FailOnError(fmt.Errorf("key %v is no string", section.Key), "YAML file "+fileName+" could not be parsed")
+		}
+		m[key] = toMaps(section.Value)
+
+		if key == "config" {
+			order = configOrder(section.Value)
+		}
+	}
+
 	err, typed := convert(m)
 	FailOnError(err, "YAML file "+fileName+" could not be parsed")
 
-	return nil, typed
+	return nil, typed, order
+}
+
+// configOrder returns the ids of the entries of the config section in the order they are listed in
+func configOrder(section interface{}) (ids []string) {
+
+	entries, ok := section.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for _, entry := range entries {
+		configs, ok := entry.(yaml.MapSlice)
+		if !ok {
+			continue
+		}
+		for _, config := range configs {
+			if id, ok := config.Key.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// toMaps replaces the ordered maps of the yaml decoded into a yaml.MapSlice by the maps convert expects
+func toMaps(value interface{}) interface{} {
+
+	switch value := value.(type) {
+	case yaml.MapSlice:
+		m := make(map[interface{}]interface{}, len(value))
+		for _, item := range value {
+			m[item.Key] = toMaps(item.Value)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(value))
+		for i, item := range value {
+			list[i] = toMaps(item)
+		}
+		return list
+	default:
+		return value
+	}
 }
 
 func ReplacePathSeparators(path string) (newPath string) {
@@ -19,6 +19,7 @@
 package util
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/assert"
@@ -68,6 +69,15 @@ retainURLs:
     - url: "https://dynatrace.com/"
 `
 
+func TestUnmarshalYamlWithWindowsLineEndings(t *testing.T) {
+
+	e, result := UnmarshalYaml(strings.ReplaceAll(testYaml, "\n", "\r\n"), "test-yaml-crlf")
+	assert.NilError(t, e)
+
+	assert.Equal(t, result["light"]["Han"], "Solo")
+	assert.Equal(t, result["dark"]["Count"], "Doku")
+}
+
 func TestReplacePathSeparators(t *testing.T) {
 	e, result := UnmarshalYaml(yamlTestPathSeparators, "test-yaml-path-separators")
 	assert.NilError(t, e)
@@ -111,3 +121,25 @@ func TestReplaceEnvVarWhenVarIsNotPresent(t *testing.T) {
 	err, _ := UnmarshalYaml(yamlTestEnvVar, "test-yaml-test-env-var")
 	assert.ErrorContains(t, err, "map has no entry for key \"TEST_ENV_VAR\"")
 }
+
+func TestUnmarshalYamlWithConfigOrder(t *testing.T) {
+
+	err, result, order := UnmarshalYamlWithConfigOrder(`
+config:
+    - zeta: "zeta.json"
+    - alpha: "alpha.json"
+    - mu: "mu.json"
+
+zeta:
+    - name: "Zeta"
+`, "test-yaml-config-order")
+
+	assert.NilError(t, err)
+	assert.DeepEqual(t, order, []string{"zeta", "alpha", "mu"})
+	assert.Equal(t, result["config"]["alpha"], "alpha.json")
+	assert.Equal(t, result["zeta"]["name"], "Zeta")
+
+	err, _, order = UnmarshalYamlWithConfigOrder("config: {{ .unknown }}", "test-yaml-invalid")
+	assert.Check(t, err != nil)
+	assert.Check(t, order == nil)
+}
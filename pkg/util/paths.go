@@ -0,0 +1,65 @@
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// reservedFileNames are device names Windows does not allow as file names, regardless of their extension
+var reservedFileNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true, "COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true, "LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// IsReservedFileName checks if the given file name can not be used on Windows
+func IsReservedFileName(name string) bool {
+
+	base := strings.SplitN(filepath.Base(name), ".", 2)[0]
+	return reservedFileNames[strings.ToUpper(base)]
+}
+
+// NormalizeLineEndings converts Windows line endings (CRLF) to LF, so that files authored on Windows
+// result in the same content on all platforms
+func NormalizeLineEndings(content string) string {
+	return strings.ReplaceAll(content, "\r\n", "\n")
+}
+
+// RelativeToWorkingDir converts an absolute path, e.g. one starting with a Windows drive letter, into a path
+// relative to the working directory. Relative paths are returned as they are
+func RelativeToWorkingDir(path string) (string, error) {
+
+	if !filepath.IsAbs(path) {
+		return path, nil
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return path, err
+	}
+
+	relative, err := filepath.Rel(workingDir, path)
+	if err != nil {
+		return path, fmt.Errorf("path %s must be on the same drive as the working directory %s: %s", path, workingDir, err)
+	}
+
+	return relative, nil
+}
@@ -0,0 +1,58 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestIsReservedFileName(t *testing.T) {
+
+	assert.Assert(t, IsReservedFileName("CON"))
+	assert.Assert(t, IsReservedFileName("nul.json"))
+	assert.Assert(t, IsReservedFileName("Com1.yaml"))
+	assert.Assert(t, !IsReservedFileName("console.json"))
+	assert.Assert(t, !IsReservedFileName("COM10"))
+}
+
+func TestNormalizeLineEndings(t *testing.T) {
+
+	assert.Equal(t, NormalizeLineEndings("a\r\nb\nc\r\n"), "a\nb\nc\n")
+}
+
+func TestRelativeToWorkingDirKeepsRelativePaths(t *testing.T) {
+
+	path, err := RelativeToWorkingDir(filepath.Join("projects", "infrastructure"))
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join("projects", "infrastructure"))
+}
+
+func TestRelativeToWorkingDirConvertsAbsolutePaths(t *testing.T) {
+
+	workingDir, err := os.Getwd()
+	assert.NilError(t, err)
+
+	path, err := RelativeToWorkingDir(filepath.Join(workingDir, "projects", "infrastructure"))
+	assert.NilError(t, err)
+	assert.Equal(t, path, filepath.Join("projects", "infrastructure"))
+}
@@ -18,25 +18,79 @@ package util
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
+	"text/template/parse"
 )
 
 // Template wraps the underlying templating logic and provides a means of setting config values just on one place.
 // It is intended to be language-agnostic, the file type does not matter (yaml, json, ...)
 type Template interface {
 	ExecuteTemplate(data map[string]string) (string, error)
+	ExecuteTemplateWithContext(data map[string]string, context TemplateContext) (string, error)
+	Variables() []string
+	Defaults() map[string]string
 }
 
+// TemplateContext holds the facts about where a template is rendered, which are available to the template
+// besides its properties
+type TemplateContext struct {
+
+	// Environment holds the facts about the environment the template is rendered for, available as .Environment.XXX
+	Environment map[string]interface{}
+
+	// Coordinates identify the config the template is rendered for (project, api and config id relative to the
+	// projects root, e.g. infrastructure/management-zone/zone). The externalId function derives its ids from them
+	// like the deployment does
+	Coordinates string
+}
+
+// SecretsFolderEnvVar names the environment variable holding the folder secrets are read from. Every file of the
+// folder holds one secret named like the file, which templates use via {{ .Secret.NAME }}. This allows using
+// secrets mounted as files, e.g. by kubernetes, without exposing them as environment variables
+const SecretsFolderEnvVar = "MONACO_SECRETS_FOLDER"
+
+// secretsProperty is the name secrets are available under in templates, it can not be used as name of a property
+const secretsProperty = "Secret"
+
+var secretsLock sync.Mutex
+
+// readFolders holds the secrets of every folder read, so that a folder is read once per run instead of for every
+// rendered template
+var readFolders = make(map[string]map[string]string)
+
+// maxIncludeDepth limits how deeply templates can include other templates, which stops templates including themselves
+const maxIncludeDepth = 10
+
 type templateImpl struct {
 	template *template.Template
+
+	// folder is the folder of the template file, files are included relative to it. It is empty for templates
+	// which were not read from a file
+	folder string
+
+	// jsonc is set for templates written in JSON with comments, whose comments are stripped once they are rendered
+	jsonc bool
+}
+
+// templateScope holds what the functions available to a template need to know about its execution
+type templateScope struct {
+	folder       string
+	coordinates  string
+	includeDepth int
 }
 
 // NewTemplateFromString creates a new template for the given string content
 func NewTemplateFromString(name string, content string) (Template, error) {
 
-	templ := template.New(name).Option("missingkey=error")
+	templ := template.New(name).Option("missingkey=error").Funcs(templateFunctions(templateScope{}))
 	templ, err := templ.Parse(content)
 
 	if err != nil {
@@ -46,15 +100,24 @@ func NewTemplateFromString(name string, content string) (Template, error) {
 	return newTemplate(templ), nil
 }
 
-// NewTemplate creates a new template for the given file
+// NewTemplate creates a new template for the given file. Windows line endings are converted, so that
+// the rendered content does not depend on the platform the file was authored on. Comments of .jsonc files are
+// stripped from the rendered content
 func NewTemplate(fileName string) (Template, error) {
 
-	templ, err := template.ParseFiles(fileName)
+	content, err := ioutil.ReadFile(fileName)
 	if err != nil {
 		return nil, err
 	}
 
-	return newTemplate(templ), nil
+	templ, err := NewTemplateFromString(filepath.Base(fileName), NormalizeLineEndings(string(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	templ.(*templateImpl).folder = filepath.Dir(fileName)
+	templ.(*templateImpl).jsonc = isJsonc(fileName)
+	return templ, nil
 }
 
 func newTemplate(templ *template.Template) Template {
@@ -68,21 +131,121 @@ func newTemplate(templ *template.Template) Template {
 }
 
 // ExecuteTemplate executes the given template. It fills the placeholder variables in the template with the strings
-// in the data map. Additionally, it resolves all environment variables present in the template.
+// in the data map. Additionally, it resolves all environment variables and secrets present in the template.
 // Important: if a variable present in the template has no corresponding entry in the data map, this method will throw
 // an error
 func (t *templateImpl) ExecuteTemplate(data map[string]string) (string, error) {
+	return t.ExecuteTemplateWithContext(data, TemplateContext{})
+}
+
+// ExecuteTemplateWithContext executes the template like ExecuteTemplate. Additionally, the facts of the context
+// are available to the template
+func (t *templateImpl) ExecuteTemplateWithContext(data map[string]string, context TemplateContext) (string, error) {
 
 	tpl := bytes.Buffer{}
 
+	if _, found := data[secretsProperty]; found {
+		return "", fmt.Errorf("property %s is reserved for the secrets of %s, rename the property", secretsProperty, SecretsFolderEnvVar)
+	}
+
 	// env vars
 	dataForTemplating := addEnvVars(data)
 
-	err := t.template.Execute(&tpl, dataForTemplating)
+	// properties with a default in the template are optional
+	for variable, fallback := range t.Defaults() {
+		if _, found := dataForTemplating[variable]; !found {
+			dataForTemplating[variable] = fallback
+		}
+	}
+
+	if context.Environment != nil {
+		dataForTemplating["Environment"] = context.Environment
+	}
+
+	secrets, err := Secrets()
+	if err != nil {
+		return "", err
+	}
+	dataForTemplating[secretsProperty] = secrets
+
+	err = t.execute(&tpl, dataForTemplating, templateScope{folder: t.folder, coordinates: context.Coordinates})
 	if CheckError(err, "Could not execute template") {
 		return "", err
 	}
 
+	if t.jsonc {
+		return StripJsonComments(tpl.String()), nil
+	}
+	return tpl.String(), nil
+}
+
+func (t *templateImpl) execute(tpl *bytes.Buffer, data interface{}, scope templateScope) error {
+
+	// functions are bound to the template, so a copy is used to not affect concurrent executions
+	templ, err := t.template.Clone()
+	if err != nil {
+		return err
+	}
+	templ.Funcs(templateFunctions(scope))
+
+	return templ.Execute(tpl, data)
+}
+
+// templateFunctions returns the functions available to templates executed in the given scope
+func templateFunctions(scope templateScope) template.FuncMap {
+	return template.FuncMap{
+		"externalId": func(parts ...string) (string, error) {
+			if scope.coordinates == "" {
+				return "", errors.New("externalId is only available in config templates")
+			}
+			return DeterministicUuid(strings.Join(append([]string{scope.coordinates}, parts...), "@")), nil
+		},
+		"include": func(file string, data interface{}) (string, error) {
+			return include(file, data, scope)
+		},
+		"default": defaultValue,
+		"now":     Now,
+	}
+}
+
+// defaultValue returns the value, or the fallback if the value is empty, e.g. {{ .threshold | default "90" }}
+func defaultValue(fallback string, value interface{}) interface{} {
+
+	if value == nil || value == "" {
+		return fallback
+	}
+	return value
+}
+
+// include renders the template file, given relative to the folder of the including template, with the given data
+func include(file string, data interface{}, scope templateScope) (string, error) {
+
+	if scope.folder == "" {
+		return "", errors.New("include is only available in template files")
+	}
+	if scope.includeDepth >= maxIncludeDepth {
+		return "", fmt.Errorf("include of %s exceeds the maximum depth of %d includes", file, maxIncludeDepth)
+	}
+
+	included, err := NewTemplate(filepath.Join(scope.folder, filepath.FromSlash(file)))
+	if err != nil {
+		return "", fmt.Errorf("include of %s failed: %s", file, err)
+	}
+	includedImpl := included.(*templateImpl)
+
+	tpl := bytes.Buffer{}
+	err = includedImpl.execute(&tpl, data, templateScope{
+		folder:       includedImpl.folder,
+		coordinates:  scope.coordinates,
+		includeDepth: scope.includeDepth + 1,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if includedImpl.jsonc {
+		return StripJsonComments(tpl.String()), nil
+	}
 	return tpl.String(), nil
 }
 
@@ -112,3 +275,228 @@ func addEnvVars(properties map[string]string) map[string]interface{} {
 
 	return data
 }
+
+// Secrets returns the secrets of the folder named by SecretsFolderEnvVar. The folder is read on first use only, so
+// secrets added to it later in a run are not available. The returned secrets must not be modified
+func Secrets() (map[string]string, error) {
+
+	folder := os.Getenv(SecretsFolderEnvVar)
+
+	secretsLock.Lock()
+	defer secretsLock.Unlock()
+
+	if secrets, found := readFolders[folder]; found {
+		return secrets, nil
+	}
+
+	secrets, err := readSecrets(folder)
+	if err != nil {
+		return nil, err
+	}
+	readFolders[folder] = secrets
+
+	return secrets, nil
+}
+
+// readSecrets reads all secrets of the folder. Hidden files (like the symlinks kubernetes creates) are skipped and
+// trailing line breaks are removed. No secrets are available if no folder is given
+func readSecrets(folder string) (map[string]string, error) {
+
+	secrets := make(map[string]string)
+	if folder == "" {
+		return secrets, nil
+	}
+
+	files, err := ioutil.ReadDir(folder)
+	if err != nil {
+		return nil, fmt.Errorf("secrets could not be read from %s: %s", folder, err)
+	}
+
+	for _, file := range files {
+
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(folder, file.Name())
+
+		// symlinks are resolved, as mounted secrets usually are links
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s could not be read: %s", file.Name(), err)
+		}
+		secrets[file.Name()] = strings.TrimRight(string(content), "\r\n")
+	}
+
+	return secrets, nil
+}
+
+// Variables returns the sorted names of all properties referenced by the template. Environment variables
+// (.Env.XXX), secrets (.Secret.XXX) and environment facts (.Environment.XXX) are not included
+func (t *templateImpl) Variables() []string {
+
+	found := make(map[string]bool)
+	for _, templ := range t.template.Templates() {
+		if templ.Tree != nil {
+			collectVariables(templ.Tree.Root, found)
+		}
+	}
+	delete(found, "Env")
+	delete(found, "Secret")
+	delete(found, "Environment")
+
+	variables := make([]string, 0, len(found))
+	for variable := range found {
+		variables = append(variables, variable)
+	}
+	sort.Strings(variables)
+
+	return variables
+}
+
+func collectVariables(node parse.Node, found map[string]bool) {
+
+	switch typed := node.(type) {
+	case *parse.ListNode:
+		if typed == nil {
+			return
+		}
+		for _, child := range typed.Nodes {
+			collectVariables(child, found)
+		}
+	case *parse.ActionNode:
+		collectVariables(typed.Pipe, found)
+	case *parse.PipeNode:
+		if typed == nil {
+			return
+		}
+		for _, command := range typed.Cmds {
+			collectVariables(command, found)
+		}
+	case *parse.CommandNode:
+		for _, argument := range typed.Args {
+			collectVariables(argument, found)
+		}
+	case *parse.FieldNode:
+		found[typed.Ident[0]] = true
+	case *parse.IfNode:
+		collectBranchVariables(&typed.BranchNode, found)
+	case *parse.RangeNode:
+		collectBranchVariables(&typed.BranchNode, found)
+	case *parse.WithNode:
+		collectBranchVariables(&typed.BranchNode, found)
+	}
+}
+
+func collectBranchVariables(branch *parse.BranchNode, found map[string]bool) {
+	collectVariables(branch.Pipe, found)
+	collectVariables(branch.List, found)
+	collectVariables(branch.ElseList, found)
+}
+
+// Defaults returns the defaults the template declares for properties using the default function, by property,
+// e.g. 90 for {{ .threshold | default "90" }} or {{ default "90" .threshold }}
+func (t *templateImpl) Defaults() map[string]string {
+
+	found := make(map[string]string)
+	for _, templ := range t.template.Templates() {
+		if templ.Tree != nil {
+			walkPipes(templ.Tree.Root, func(pipe *parse.PipeNode) {
+				if variable, fallback, ok := declaredDefault(pipe); ok {
+					found[variable] = fallback
+				}
+			})
+		}
+	}
+
+	return found
+}
+
+// declaredDefault returns the property and the default of a pipe applying the default function to a property
+func declaredDefault(pipe *parse.PipeNode) (variable string, fallback string, ok bool) {
+
+	isDefault := func(args []parse.Node) (string, bool) {
+		if len(args) < 2 {
+			return "", false
+		}
+		identifier, isIdentifier := args[0].(*parse.IdentifierNode)
+		text, isString := args[1].(*parse.StringNode)
+		if !isIdentifier || identifier.Ident != "default" || !isString {
+			return "", false
+		}
+		return text.Text, true
+	}
+
+	property := func(node parse.Node) (string, bool) {
+		field, isField := node.(*parse.FieldNode)
+		if !isField || len(field.Ident) != 1 {
+			return "", false
+		}
+		return field.Ident[0], true
+	}
+
+	cmds := pipe.Cmds
+
+	// {{ default "90" .threshold }}
+	if len(cmds) > 0 && len(cmds[0].Args) == 3 {
+		if fallback, ok := isDefault(cmds[0].Args); ok {
+			if variable, ok := property(cmds[0].Args[2]); ok {
+				return variable, fallback, true
+			}
+		}
+	}
+
+	// {{ .threshold | default "90" }}
+	if len(cmds) > 1 && len(cmds[0].Args) == 1 && len(cmds[1].Args) == 2 {
+		if fallback, ok := isDefault(cmds[1].Args); ok {
+			if variable, ok := property(cmds[0].Args[0]); ok {
+				return variable, fallback, true
+			}
+		}
+	}
+
+	return "", "", false
+}
+
+// walkPipes calls visit for every pipe of the node and its children
+func walkPipes(node parse.Node, visit func(pipe *parse.PipeNode)) {
+
+	switch typed := node.(type) {
+	case *parse.ListNode:
+		if typed == nil {
+			return
+		}
+		for _, child := range typed.Nodes {
+			walkPipes(child, visit)
+		}
+	case *parse.ActionNode:
+		walkPipes(typed.Pipe, visit)
+	case *parse.PipeNode:
+		if typed == nil {
+			return
+		}
+		visit(typed)
+		for _, command := range typed.Cmds {
+			for _, argument := range command.Args {
+				walkPipes(argument, visit)
+			}
+		}
+	case *parse.IfNode:
+		walkBranchPipes(&typed.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranchPipes(&typed.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranchPipes(&typed.BranchNode, visit)
+	}
+}
+
+func walkBranchPipes(branch *parse.BranchNode, visit func(pipe *parse.PipeNode)) {
+	walkPipes(branch.Pipe, visit)
+	walkPipes(branch.List, visit)
+	walkPipes(branch.ElseList, visit)
+}
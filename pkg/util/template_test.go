@@ -19,13 +19,32 @@
 package util
 
 import (
-	"gotest.tools/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"gotest.tools/assert"
 )
 
 const testMatrixTemplateWithEnvVar = "Follow the {{.color}} {{ .Env.ANIMAL }}"
 const testMatrixTemplateWithProperty = "Follow the {{.color}} {{ .ANIMAL }}"
 
+func TestNewTemplateConvertsWindowsLineEndings(t *testing.T) {
+
+	fileName := filepath.Join(t.TempDir(), "template.json")
+	err := ioutil.WriteFile(fileName, []byte("{\r\n  \"name\": \"{{.color}}\"\r\n}\r\n"), 0664)
+	assert.NilError(t, err)
+
+	template, err := NewTemplate(fileName)
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplate(getTemplateTestProperties())
+	assert.NilError(t, err)
+	assert.Equal(t, result, "{\n  \"name\": \"white\"\n}\n")
+}
+
 func TestGetStringWithEnvVar(t *testing.T) {
 
 	template, err := NewTemplateFromString("template_test", testMatrixTemplateWithEnvVar)
@@ -94,3 +113,152 @@ func getTemplateTestPropertiesClashingWithEnvVars() map[string]string {
 
 	return m
 }
+
+func TestVariables(t *testing.T) {
+
+	template, err := NewTemplateFromString("template_test", `{"name": "{{ .name }}", {{ if .enabled }}"zone": "{{.zoneId}}"{{ end }}, "key": "{{ .Env.KEY }}", "secret": "{{ .Secret.KEY }}", "other": "{{ .name }}"}`)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, template.Variables(), []string{"enabled", "name", "zoneId"})
+}
+
+func TestGetStringWithSecret(t *testing.T) {
+
+	folder := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(folder, "webhook-token"), []byte("s3cr3t\n"), 0600)
+	assert.NilError(t, err)
+	err = ioutil.WriteFile(filepath.Join(folder, ".hidden"), []byte("hidden"), 0600)
+	assert.NilError(t, err)
+
+	SetEnv(t, SecretsFolderEnvVar, folder)
+	defer UnsetEnv(t, SecretsFolderEnvVar)
+
+	template, err := NewTemplateFromString("template_test", `{"token": "{{ index .Secret "webhook-token" }}"}`)
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplate(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"token": "s3cr3t"}`)
+
+	template, err = NewTemplateFromString("template_test", `{"token": "{{ .Secret.missing }}"}`)
+	assert.NilError(t, err)
+
+	_, err = template.ExecuteTemplate(map[string]string{})
+	assert.ErrorContains(t, err, "missing")
+}
+
+func TestSecretsAreReadOncePerFolder(t *testing.T) {
+
+	folder := t.TempDir()
+	err := ioutil.WriteFile(filepath.Join(folder, "token"), []byte("first"), 0600)
+	assert.NilError(t, err)
+
+	SetEnv(t, SecretsFolderEnvVar, folder)
+	defer UnsetEnv(t, SecretsFolderEnvVar)
+
+	template, err := NewTemplateFromString("template_test", `{{ .Secret.token }}`)
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplate(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, result, "first")
+
+	err = ioutil.WriteFile(filepath.Join(folder, "token"), []byte("second"), 0600)
+	assert.NilError(t, err)
+
+	result, err = template.ExecuteTemplate(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, result, "first")
+}
+
+func TestPropertyNamedSecretIsRejected(t *testing.T) {
+
+	template, err := NewTemplateFromString("template_test", `{{ .Secret }}`)
+	assert.NilError(t, err)
+
+	_, err = template.ExecuteTemplate(map[string]string{"Secret": "value"})
+	assert.ErrorContains(t, err, "property Secret is reserved")
+}
+
+func TestExternalIdIsDerivedFromCoordinates(t *testing.T) {
+
+	template, err := NewTemplateFromString("template_test", `{"externalId": "{{ externalId }}", "rule": "{{ externalId "rule" }}"}`)
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplateWithContext(map[string]string{}, TemplateContext{Coordinates: "project/dashboard/overview"})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"externalId": "`+DeterministicUuid("project/dashboard/overview")+`", "rule": "`+DeterministicUuid("project/dashboard/overview@rule")+`"}`)
+
+	_, err = template.ExecuteTemplate(map[string]string{})
+	assert.ErrorContains(t, err, "externalId is only available in config templates")
+}
+
+func TestIncludeRendersFilesRelativeToTemplate(t *testing.T) {
+
+	folder := t.TempDir()
+	err := os.MkdirAll(filepath.Join(folder, "tiles", "nested"), 0777)
+	assert.NilError(t, err)
+
+	files := map[string]string{
+		"dashboard.json":           `{"tiles": [{{ include "tiles/first.json" . }}]}`,
+		"tiles/first.json":         `{"name": "{{ .name }}", "nested": {{ include "nested/second.json" . }}}`,
+		"tiles/nested/second.json": `"{{ externalId }}"`,
+		"loop.json":                `{{ include "loop.json" . }}`,
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(folder, filepath.FromSlash(name)), []byte(content), 0664)
+		assert.NilError(t, err)
+	}
+
+	template, err := NewTemplate(filepath.Join(folder, "dashboard.json"))
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplateWithContext(map[string]string{"name": "tile"}, TemplateContext{Coordinates: "project"})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"tiles": [{"name": "tile", "nested": "`+DeterministicUuid("project")+`"}]}`)
+
+	template, err = NewTemplate(filepath.Join(folder, "loop.json"))
+	assert.NilError(t, err)
+
+	_, err = template.ExecuteTemplate(map[string]string{})
+	assert.ErrorContains(t, err, "maximum depth")
+
+	template, err = NewTemplateFromString("template_test", `{{ include "tiles/first.json" . }}`)
+	assert.NilError(t, err)
+
+	_, err = template.ExecuteTemplate(map[string]string{})
+	assert.ErrorContains(t, err, "include is only available in template files")
+}
+
+func TestDefaultsAreUsedForUndefinedProperties(t *testing.T) {
+
+	template, err := NewTemplateFromString("test", `{"threshold": {{ .threshold | default "90" }}, "window": {{ default "5" .window }}, "name": "{{ .name }}"}`)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, template.Defaults(), map[string]string{"threshold": "90", "window": "5"})
+
+	result, err := template.ExecuteTemplate(map[string]string{"name": "alert", "window": "10"})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"threshold": 90, "window": 10, "name": "alert"}`)
+
+	result, err = template.ExecuteTemplate(map[string]string{"name": "alert", "threshold": ""})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"threshold": 90, "window": 5, "name": "alert"}`)
+
+	// properties without defaults are still required
+	_, err = template.ExecuteTemplate(map[string]string{})
+	assert.ErrorContains(t, err, "name")
+}
+
+func TestNowIsTheFrozenTime(t *testing.T) {
+
+	FreezeTime(time.Date(2021, 3, 1, 12, 30, 0, 0, time.UTC))
+	defer FreezeTime(time.Time{})
+
+	template, err := NewTemplateFromString("test", `{"start": "{{ (now.AddDate 0 0 1).Format "2006-01-02 15:04" }}", "epoch": {{ now.Unix }}}`)
+	assert.NilError(t, err)
+
+	result, err := template.ExecuteTemplate(map[string]string{})
+	assert.NilError(t, err)
+	assert.Equal(t, result, `{"start": "2021-03-02 12:30", "epoch": 1614601800}`)
+}
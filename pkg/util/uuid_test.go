@@ -0,0 +1,35 @@
+// +build unit
+
+/**
+ * @license
+ * Copyright 2020 Dynatrace LLC
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"regexp"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDeterministicUuid(t *testing.T) {
+
+	uuid := DeterministicUuid("project/dashboard/overview")
+
+	assert.Equal(t, uuid, DeterministicUuid("project/dashboard/overview"))
+	assert.Assert(t, uuid != DeterministicUuid("project/dashboard/other"))
+	assert.Assert(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(uuid), uuid)
+}